@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+// budgetNotifyFlags are the flags of the SLOs remaining error budget threshold crossing webhooks.
+type budgetNotifyFlags struct {
+	budgetWebhookURL        string
+	budgetThresholdPercents []float64
+	budgetStateFile         string
+}
+
+// register registers the budget webhooks flags on the command.
+func (b *budgetNotifyFlags) register(cmd *kingpin.CmdClause) {
+	cmd.Flag("budget-webhook-url", "If set, it will send a CloudEvent to this webhook URL when an SLO remaining error budget crosses down a budget threshold (non paging notifications).").StringVar(&b.budgetWebhookURL)
+	cmd.Flag("budget-threshold", "The remaining error budget percent thresholds of the budget webhook (can be repeated).").Default("50", "25", "10").Float64ListVar(&b.budgetThresholdPercents)
+	cmd.Flag("budget-state-file", "File where the SLOs last remaining error budget is stored, to detect the budget threshold crossings between executions.").StringVar(&b.budgetStateFile)
+}
+
+// budgetNotifier notifies the budget threshold crossings of the observed SLO reports.
+type budgetNotifier struct {
+	tracker   *prometheus.BudgetThresholdTracker
+	notifier  *prometheus.BudgetWebhookNotifier
+	stateFile string
+}
+
+// newBudgetNotifier returns the budget notifier of the flags, nil if the budget webhook is not set.
+func (b budgetNotifyFlags) newBudgetNotifier(httpClient *http.Client) (*budgetNotifier, error) {
+	if b.budgetWebhookURL == "" {
+		return nil, nil
+	}
+
+	thresholds := make([]float64, 0, len(b.budgetThresholdPercents))
+	for _, t := range b.budgetThresholdPercents {
+		thresholds = append(thresholds, t/100)
+	}
+
+	// Load the previous observations.
+	var state map[string]prometheus.BudgetThresholdState
+	if b.budgetStateFile != "" {
+		data, err := os.ReadFile(b.budgetStateFile)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("could not read budget state file: %w", err)
+		default:
+			err := json.Unmarshal(data, &state)
+			if err != nil {
+				return nil, fmt.Errorf("could not load budget state file: %w", err)
+			}
+		}
+	}
+
+	tracker, err := prometheus.NewBudgetThresholdTracker(thresholds, state)
+	if err != nil {
+		return nil, fmt.Errorf("invalid budget thresholds: %w", err)
+	}
+
+	notifier, err := prometheus.NewBudgetWebhookNotifier(prometheus.BudgetWebhookNotifierConfig{
+		URL:        b.budgetWebhookURL,
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &budgetNotifier{
+		tracker:   tracker,
+		notifier:  notifier,
+		stateFile: b.budgetStateFile,
+	}, nil
+}
+
+// notify observes the SLO reports, notifies the budget threshold crossings and stores the observations
+// on the state file. The crossings that could not be notified are pending on the state, so they are
+// notified again (with the same event) on the next observation.
+func (b *budgetNotifier) notify(ctx context.Context, logger log.Logger, slos []prometheus.SLO, reports []prometheus.SLOReport, ts time.Time) error {
+	crossings := b.tracker.Observe(slos, reports, ts)
+	notified, notifyErr := b.notifier.Notify(ctx, crossings)
+	for _, c := range notified {
+		b.tracker.Notified(c)
+	}
+
+	if b.stateFile != "" {
+		data, err := json.Marshal(b.tracker.State())
+		if err != nil {
+			return fmt.Errorf("could not marshal budget state: %w", err)
+		}
+		err = os.WriteFile(b.stateFile, data, 0644)
+		if err != nil {
+			return fmt.Errorf("could not write budget state file: %w", err)
+		}
+	}
+
+	if len(notified) > 0 {
+		logger.WithValues(log.Kv{"crossings": len(notified)}).Infof("Budget threshold crossings notified")
+	}
+
+	if notifyErr != nil {
+		return fmt.Errorf("could not notify budget threshold crossings: %w", notifyErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+type checkQueriesCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	prometheusURL    string
+	window           time.Duration
+	queryRange       time.Duration
+	queryStep        time.Duration
+	failOnEmpty      bool
+}
+
+// NewCheckQueriesCommand returns the check queries command.
+func NewCheckQueriesCommand(app *kingpin.Application) Command {
+	c := &checkQueriesCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("check-queries", "Executes the SLI queries of the SLO manifests against a live Prometheus, reporting the query errors, empty results and missing metrics.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("prometheus-url", "The Prometheus API URL where the SLI queries are executed.").Default("http://127.0.0.1:9090").StringVar(&c.prometheusURL)
+	cmd.Flag("window", "The window used to render the SLI queries.").Default("5m").DurationVar(&c.window)
+	cmd.Flag("range", "The time range of the range queries, until now.").Default("1h").DurationVar(&c.queryRange)
+	cmd.Flag("step", "The resolution step of the range queries.").Default("1m").DurationVar(&c.queryStep)
+	cmd.Flag("fail-on-empty", "Fails when a query doesn't return data, by default only the query errors and missing metrics fail (error queries are usually empty without errors).").BoolVar(&c.failOnEmpty)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (c checkQueriesCommand) Name() string { return "check-queries" }
+func (c checkQueriesCommand) Run(ctx context.Context, config RootConfig) error {
+	// Set up files discovery filter regex.
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(c.slosExcludeRegex, c.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, c.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := c.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	fileSLOs, err := c.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+	slos := make([]prometheus.SLO, 0, len(fileSLOs))
+	for _, s := range fileSLOs {
+		slos = append(slos, s.SLO)
+	}
+
+	api, err := newPrometheusAPI(c.prometheusURL, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+	querier := promAPISeriesQuerier{api: api}
+
+	checks := prometheus.CheckSLIQueries(ctx, querier, slos, prometheus.SLIQueryCheckConfig{
+		Window: c.window,
+		Time:   time.Now(),
+		Range:  c.queryRange,
+		Step:   c.queryStep,
+	})
+
+	w := tabwriter.NewWriter(config.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SLO\tQUERY\tSTATUS\tINSTANT SERIES\tRANGE SERIES\tDETAILS")
+	failed := 0
+	for _, check := range checks {
+		details := ""
+		switch {
+		case check.Err != nil:
+			details = check.Err.Error()
+		case len(check.MissingMetrics) > 0:
+			details = "missing metrics: " + strings.Join(check.MissingMetrics, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", check.SLOID, check.Query, check.Status, check.InstantSeries, check.RangeSeries, details)
+
+		if check.Failed() || (c.failOnEmpty && check.Status == prometheus.SLIQueryCheckStatusEmpty) {
+			failed++
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write queries check result: %w", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d SLI queries failed the check", failed)
+	}
+	config.Logger.WithValues(log.Kv{"slos": len(slos), "queries": len(checks)}).Infof("SLI queries checked")
+
+	return nil
+}
+
+// promAPISeriesQuerier executes the Prometheus queries using the Prometheus HTTP API.
+type promAPISeriesQuerier struct {
+	api promv1.API
+}
+
+func (p promAPISeriesQuerier) QuerySeries(ctx context.Context, query string, ts time.Time) (int, error) {
+	value, _, err := p.api.Query(ctx, query, ts)
+	if err != nil {
+		return 0, err
+	}
+
+	return valueSeries(value)
+}
+
+func (p promAPISeriesQuerier) QueryRangeSeries(ctx context.Context, query string, start, end time.Time, step time.Duration) (int, error) {
+	value, _, err := p.api.QueryRange(ctx, query, promv1.Range{Start: start, End: end, Step: step})
+	if err != nil {
+		return 0, err
+	}
+
+	return valueSeries(value)
+}
+
+func valueSeries(value prommodel.Value) (int, error) {
+	switch v := value.(type) {
+	case prommodel.Vector:
+		return len(v), nil
+	case prommodel.Matrix:
+		return len(v), nil
+	case *prommodel.Scalar:
+		return 1, nil
+	}
+
+	return 0, fmt.Errorf("unsupported %T query result", value)
+}
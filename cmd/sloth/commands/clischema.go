@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	cliSchemaFormatJSON = "json"
+	cliSchemaFormatMan  = "man"
+)
+
+type cliSchemaCommand struct {
+	app    *kingpin.Application
+	format string
+}
+
+// NewCLISchemaCommand returns the CLI schema command.
+func NewCLISchemaCommand(app *kingpin.Application) Command {
+	c := &cliSchemaCommand{app: app}
+	cmd := app.Command("cli-schema", "Prints the description of all the commands, flags and arguments of the CLI, so wrapper tools can be kept in sync.")
+	cmd.Flag("format", "The output format, json for a machine readable schema or man for a man page.").Default(cliSchemaFormatJSON).EnumVar(&c.format, cliSchemaFormatJSON, cliSchemaFormatMan)
+
+	return c
+}
+
+func (c cliSchemaCommand) Name() string { return "cli-schema" }
+func (c cliSchemaCommand) Run(ctx context.Context, config RootConfig) error {
+	switch c.format {
+	case cliSchemaFormatMan:
+		pctx, err := c.app.ParseContext(nil)
+		if err != nil {
+			return fmt.Errorf("could not get CLI context: %w", err)
+		}
+
+		c.app.UsageWriter(config.Stdout)
+		err = c.app.UsageForContextWithTemplate(pctx, 2, kingpin.ManPageTemplate)
+		if err != nil {
+			return fmt.Errorf("could not render man page: %w", err)
+		}
+
+	default:
+		model := c.app.Model()
+		schema := cliSchema{
+			Name:     model.Name,
+			Help:     model.Help,
+			Flags:    newCLISchemaFlags(model.FlagGroupModel),
+			Commands: newCLISchemaCommands(model.CmdGroupModel),
+		}
+
+		enc := json.NewEncoder(config.Stdout)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(schema)
+		if err != nil {
+			return fmt.Errorf("could not encode CLI schema: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type cliSchema struct {
+	Name     string          `json:"name"`
+	Help     string          `json:"help"`
+	Flags    []cliSchemaFlag `json:"flags"`
+	Commands []cliSchemaCmd  `json:"commands"`
+}
+
+type cliSchemaCmd struct {
+	Name        string          `json:"name"`
+	FullCommand string          `json:"fullCommand"`
+	Help        string          `json:"help"`
+	Aliases     []string        `json:"aliases,omitempty"`
+	Flags       []cliSchemaFlag `json:"flags"`
+	Args        []cliSchemaArg  `json:"args"`
+	Commands    []cliSchemaCmd  `json:"commands,omitempty"`
+}
+
+type cliSchemaFlag struct {
+	Name        string   `json:"name"`
+	Short       string   `json:"short,omitempty"`
+	Help        string   `json:"help"`
+	Default     []string `json:"default,omitempty"`
+	Envar       string   `json:"envar,omitempty"`
+	PlaceHolder string   `json:"placeHolder,omitempty"`
+	Required    bool     `json:"required"`
+	Bool        bool     `json:"bool"`
+	Repeatable  bool     `json:"repeatable"`
+}
+
+type cliSchemaArg struct {
+	Name       string   `json:"name"`
+	Help       string   `json:"help"`
+	Default    []string `json:"default,omitempty"`
+	Envar      string   `json:"envar,omitempty"`
+	Required   bool     `json:"required"`
+	Repeatable bool     `json:"repeatable"`
+}
+
+// cumulativeValue is implemented by the kingpin values that can be repeated.
+type cumulativeValue interface {
+	IsCumulative() bool
+}
+
+func isCumulative(v kingpin.Value) bool {
+	c, ok := v.(cumulativeValue)
+	return ok && c.IsCumulative()
+}
+
+func newCLISchemaFlags(group *kingpin.FlagGroupModel) []cliSchemaFlag {
+	flags := []cliSchemaFlag{}
+	if group == nil {
+		return flags
+	}
+
+	for _, f := range group.Flags {
+		if f.Hidden {
+			continue
+		}
+
+		short := ""
+		if f.Short != 0 {
+			short = string(f.Short)
+		}
+		flags = append(flags, cliSchemaFlag{
+			Name:        f.Name,
+			Short:       short,
+			Help:        f.Help,
+			Default:     f.Default,
+			Envar:       f.Envar,
+			PlaceHolder: f.PlaceHolder,
+			Required:    f.Required,
+			Bool:        f.IsBoolFlag(),
+			Repeatable:  isCumulative(f.Value),
+		})
+	}
+
+	return flags
+}
+
+func newCLISchemaArgs(group *kingpin.ArgGroupModel) []cliSchemaArg {
+	args := []cliSchemaArg{}
+	if group == nil {
+		return args
+	}
+
+	for _, a := range group.Args {
+		args = append(args, cliSchemaArg{
+			Name:       a.Name,
+			Help:       a.Help,
+			Default:    a.Default,
+			Envar:      a.Envar,
+			Required:   a.Required,
+			Repeatable: isCumulative(a.Value),
+		})
+	}
+
+	return args
+}
+
+func newCLISchemaCommands(group *kingpin.CmdGroupModel) []cliSchemaCmd {
+	cmds := []cliSchemaCmd{}
+	if group == nil {
+		return cmds
+	}
+
+	for _, c := range group.Commands {
+		if c.Hidden {
+			continue
+		}
+
+		cmds = append(cmds, cliSchemaCmd{
+			Name:        c.Name,
+			FullCommand: c.FullCommand,
+			Help:        c.Help,
+			Aliases:     c.Aliases,
+			Flags:       newCLISchemaFlags(c.FlagGroupModel),
+			Args:        newCLISchemaArgs(c.ArgGroupModel),
+			Commands:    newCLISchemaCommands(c.CmdGroupModel),
+		})
+	}
+
+	return cmds
+}
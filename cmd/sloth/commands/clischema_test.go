@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func newTestCLISchemaApp() (*kingpin.Application, Command) {
+	app := kingpin.New("test-app", "Test application.")
+	app.Flag("debug", "Enable debug mode.").Short('d').Bool()
+	app.Flag("hidden", "Hidden flag.").Hidden().String()
+
+	gen := app.Command("generate", "Generates things.").Alias("gen")
+	gen.Flag("input", "Input file.").Short('i').Required().String()
+	gen.Flag("label", "Extra labels.").StringMap()
+	gen.Flag("mode", "Generation mode.").Default("fast").Envar("TEST_MODE").PlaceHolder("MODE").Enum("fast", "slow")
+	gen.Arg("files", "Files to generate.").Strings()
+
+	plugins := app.Command("plugins", "Manages plugins.")
+	list := plugins.Command("list", "Lists plugins.")
+	list.Arg("filter", "Plugins filter.").Default("all").String()
+
+	app.Command("internal", "Internal command.").Hidden()
+
+	schema := NewCLISchemaCommand(app)
+
+	return app, schema
+}
+
+func TestCLISchemaJSON(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, cmd := newTestCLISchemaApp()
+
+	var out bytes.Buffer
+	err := cmd.Run(context.TODO(), RootConfig{Stdout: &out})
+	require.NoError(err)
+
+	expOut, err := os.ReadFile("testdata/cli-schema.json")
+	require.NoError(err)
+	assert.Equal(string(expOut), out.String())
+}
+
+func TestCLISchemaMan(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	app, _ := newTestCLISchemaApp()
+	c := &cliSchemaCommand{app: app, format: cliSchemaFormatMan}
+
+	var out bytes.Buffer
+	err := c.Run(context.TODO(), RootConfig{Stdout: &out})
+	require.NoError(err)
+
+	gotOut := out.String()
+	assert.Contains(gotOut, `.TH test-app 1`)
+	assert.Contains(gotOut, `\fBgenerate --input=INPUT [<flags>] [<files>...]\fR`)
+	assert.Contains(gotOut, `\fBplugins list [<filter*>]\fR`)
+	assert.NotContains(gotOut, "internal")
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"io"
+	"net/http"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 
@@ -32,11 +33,18 @@ type RootConfig struct {
 	NoColor    bool
 	LoggerType string
 
+	// HTTP integrations global flags.
+	HTTPProxy    string
+	HTTPCAFile   string
+	HTTPCertFile string
+	HTTPKeyFile  string
+
 	// Global instances.
-	Stdin  io.Reader
-	Stdout io.Writer
-	Stderr io.Writer
-	Logger log.Logger
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+	Logger     log.Logger
+	HTTPClient *http.Client
 }
 
 // NewRootConfig initializes the main root configuration.
@@ -48,6 +56,10 @@ func NewRootConfig(app *kingpin.Application) *RootConfig {
 	app.Flag("no-log", "Disable logger.").BoolVar(&c.NoLog)
 	app.Flag("no-color", "Disable logger color.").BoolVar(&c.NoColor)
 	app.Flag("logger", "Selects the logger type.").Default(LoggerTypeDefault).EnumVar(&c.LoggerType, LoggerTypeDefault, LoggerTypeJSON)
+	app.Flag("http-proxy", "The proxy URL used by the HTTP integrations, if not set it will use the HTTP_PROXY, HTTPS_PROXY and NO_PROXY env vars.").StringVar(&c.HTTPProxy)
+	app.Flag("http-ca-file", "A PEM CA bundle file used by the HTTP integrations to verify the servers, in addition to the system CAs.").StringVar(&c.HTTPCAFile)
+	app.Flag("http-cert-file", "A PEM client certificate file used by the HTTP integrations for mTLS.").StringVar(&c.HTTPCertFile)
+	app.Flag("http-key-file", "A PEM client certificate key file used by the HTTP integrations for mTLS.").StringVar(&c.HTTPKeyFile)
 
 	return c
 }
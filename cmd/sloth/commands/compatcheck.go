@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+const compatCheckFailOnNone = "none"
+
+type compatCheckCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	oldRulesPath      string
+	slosInput         string
+	slosExcludeRegex  string
+	slosIncludeRegex  string
+	extraLabels       map[string]string
+	disableRecordings bool
+	disableAlerts     bool
+	minimal           bool
+	sliZeroTotalGuard bool
+	failOn            string
+}
+
+// NewCompatCheckCommand returns the compat check command.
+func NewCompatCheckCommand(app *kingpin.Application) Command {
+	c := &compatCheckCommand{specLoadFlags: newSpecLoadFlags(), extraLabels: map[string]string{}}
+	cmd := app.Command("compat-check", "Compares the rules generated by this Sloth version with the rules generated by a previous version, classifying the changes as cosmetic, threshold or structural.")
+	cmd.Arg("old-rules", "The rules file generated by the previous Sloth version (Prometheus rules or Kubernetes rule objects).").Required().StringVar(&c.oldRulesPath)
+	cmd.Flag("input", "SLO spec discovery path of the old rules SLOs, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("extra-labels", "Extra labels that will be added to all the generated Prometheus rules ('key=value' form, can be repeated), the same as the old rules generation.").Short('l').StringMapVar(&c.extraLabels)
+	cmd.Flag("disable-recordings", "Disables recording rules generation, the same as the old rules generation.").BoolVar(&c.disableRecordings)
+	cmd.Flag("disable-alerts", "Disables alert rules generation, the same as the old rules generation.").BoolVar(&c.disableAlerts)
+	cmd.Flag("minimal", "Generates only the rules required by the alerts, the same as the old rules generation.").BoolVar(&c.minimal)
+	cmd.Flag("sli-zero-total-guard", "Guards the events SLI recording rules against zero totals, the same as the old rules generation.").BoolVar(&c.sliZeroTotalGuard)
+	cmd.Flag("fail-on", "Fails when there are changes of this kind or a more severe one (cosmetic < threshold < structural).").Default(string(prometheus.RulesChangeKindStructural)).EnumVar(&c.failOn, string(prometheus.RulesChangeKindCosmetic), string(prometheus.RulesChangeKindThreshold), string(prometheus.RulesChangeKindStructural), compatCheckFailOnNone)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (c compatCheckCommand) Name() string { return "compat-check" }
+func (c compatCheckCommand) Run(ctx context.Context, config RootConfig) error {
+	oldRules, err := os.ReadFile(c.oldRulesPath)
+	if err != nil {
+		return fmt.Errorf("could not read old rules file: %w", err)
+	}
+
+	// Set up files discovery filter regex.
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(c.slosExcludeRegex, c.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, c.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := c.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	fileSLOs, err := c.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+	slos := make([]prometheus.SLO, 0, len(fileSLOs))
+	for _, s := range fileSLOs {
+		slos = append(slos, s.SLO)
+	}
+
+	// Generate the rules with the current version.
+	var newRules bytes.Buffer
+	_, err = generatePrometheus(ctx, config.Logger, generateOptions{
+		disableRecordings: c.disableRecordings,
+		disableAlerts:     c.disableAlerts,
+		minimal:           c.minimal,
+		sliZeroTotalGuard: c.sliZeroTotalGuard,
+		extraLabels:       c.extraLabels,
+	}, prometheus.SLOGroup{SLOs: slos}, &newRules)
+	if err != nil {
+		return fmt.Errorf("could not generate Prometheus format rules: %w", err)
+	}
+
+	changes, err := prometheus.CompareRules(oldRules, newRules.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// Print the changes.
+	failOn := len(prometheus.RulesChangeKinds)
+	for i, k := range prometheus.RulesChangeKinds {
+		if string(k) == c.failOn {
+			failOn = i
+		}
+	}
+	kindCounts := map[prometheus.RulesChangeKind]int{}
+	failed := 0
+	w := tabwriter.NewWriter(config.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tGROUP\tRULE\tDETAILS")
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Kind, change.Group, change.Rule, change.Message)
+		kindCounts[change.Kind]++
+		for i, k := range prometheus.RulesChangeKinds {
+			if k == change.Kind && i >= failOn {
+				failed++
+			}
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write compatibility check result: %w", err)
+	}
+
+	logger := config.Logger.WithValues(log.Kv{
+		"cosmetic":   kindCounts[prometheus.RulesChangeKindCosmetic],
+		"threshold":  kindCounts[prometheus.RulesChangeKindThreshold],
+		"structural": kindCounts[prometheus.RulesChangeKindStructural],
+	})
+	if failed > 0 {
+		return fmt.Errorf("%d generated rules changes are %s or more severe", failed, c.failOn)
+	}
+	logger.Infof("Generated rules compatibility checked")
+
+	return nil
+}
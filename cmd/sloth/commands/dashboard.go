@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+const (
+	dashboardPerService = "service"
+	dashboardPerSLO     = "slo"
+)
+
+type dashboardCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	outDir           string
+	per              string
+}
+
+// NewDashboardCommand returns the dashboard command.
+func NewDashboardCommand(app *kingpin.Application) Command {
+	c := &dashboardCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("dashboard", "Generates Grafana dashboards with the SLI, error budget and burn rate panels of the SLOs, based on the generated recording rules.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("out-dir", "The directory path where the Grafana dashboards JSON files will be generated.").Short('o').Required().StringVar(&c.outDir)
+	cmd.Flag("per", "Generates a dashboard per service or per SLO.").Default(dashboardPerService).EnumVar(&c.per, dashboardPerService, dashboardPerSLO)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (d dashboardCommand) Name() string { return "dashboard" }
+func (d dashboardCommand) Run(ctx context.Context, config RootConfig) error {
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(d.slosExcludeRegex, d.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, d.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := d.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	slos, err := d.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+
+	// Group the SLOs by dashboard.
+	dashboards := map[string][]prometheus.StorageSLO{}
+	for _, s := range slos {
+		key := s.SLO.Service
+		if d.per == dashboardPerSLO {
+			key = s.SLO.ID
+		}
+		dashboards[key] = append(dashboards[key], prometheus.StorageSLO{SLO: s.SLO, Source: s.Path})
+	}
+
+	keys := make([]string, 0, len(dashboards))
+	for k := range dashboards {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	err = os.MkdirAll(d.outDir, 0755)
+	if err != nil {
+		return fmt.Errorf("could not create out directory: %w", err)
+	}
+
+	for _, k := range keys {
+		err := d.storeDashboard(ctx, config.Logger, k, dashboards[k])
+		if err != nil {
+			return fmt.Errorf("could not generate %q dashboard: %w", k, err)
+		}
+	}
+
+	config.Logger.WithValues(log.Kv{"dashboards": len(keys), "slos": len(slos)}).Infof("Grafana dashboards generated")
+
+	return nil
+}
+
+func (d dashboardCommand) storeDashboard(ctx context.Context, logger log.Logger, key string, slos []prometheus.StorageSLO) error {
+	f, err := os.Create(filepath.Join(d.outDir, key+".json"))
+	if err != nil {
+		return fmt.Errorf("could not create out file: %w", err)
+	}
+	defer f.Close()
+
+	meta := prometheus.GrafanaDashboardMeta{
+		Title: fmt.Sprintf("SLOs / %s", key),
+		Tags:  []string{"sloth", "slo"},
+	}
+	repo, err := prometheus.NewIOWriterGrafanaDashboardJSONRepo(f, meta, logger)
+	if err != nil {
+		return err
+	}
+
+	return repo.StoreSLOs(ctx, slos)
+}
@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoringclientset "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
+	"gopkg.in/alecthomas/kingpin.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+
+	"github.com/slok/sloth/internal/diff"
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/k8sprometheus"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+	kubernetesv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	slothclientset "github.com/slok/sloth/pkg/kubernetes/gen/clientset/versioned"
+)
+
+type diffCommand struct {
+	generateFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	slosOut          string
+	kube             bool
+	kubeConfig       string
+	kubeContext      string
+}
+
+// NewDiffCommand returns the diff command.
+func NewDiffCommand(app *kingpin.Application) Command {
+	c := &diffCommand{generateFlags: newGenerateFlags()}
+	cmd := app.Command("diff", "Generates the Prometheus SLOs rules in memory and shows the diff with the existing generated rules, failing when they have changes.")
+	cmd.Flag("input", "SLO spec input file path. If `-` it will use stdin, if a directory it will discover recursively all YAML files.").Short('i').Default("-").StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths, only used with a directory input.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference, only used with a directory input.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("out", "The existing generated rules file path that will be compared with the generated rules.").Short('o').StringVar(&c.slosOut)
+	cmd.Flag("kube", "Compares the generated rules of the Kubernetes SLO specs with the live PrometheusRule objects, instead of with the out file.").BoolVar(&c.kube)
+	kubeHome := filepath.Join(homedir.HomeDir(), ".kube", "config")
+	cmd.Flag("kube-config", "kubernetes configuration path, only used when comparing with the live PrometheusRule objects.").Default(kubeHome).StringVar(&c.kubeConfig)
+	cmd.Flag("kube-context", "kubernetes context, only used when comparing with the live PrometheusRule objects.").StringVar(&c.kubeContext)
+	c.generateFlags.register(cmd)
+
+	return c
+}
+
+func (d diffCommand) Name() string { return "diff" }
+func (d diffCommand) Run(ctx context.Context, config RootConfig) error {
+	if d.slosOut == "" && !d.kube {
+		return fmt.Errorf("an out file or the Kubernetes live rules comparison is required")
+	}
+	if d.slosOut != "" && d.kube {
+		return fmt.Errorf("the out file can't be used when comparing with the Kubernetes live rules")
+	}
+
+	// Only the Kubernetes specs can be compared with the live rules.
+	flags := d.generateFlags
+	if d.kube {
+		if flags.inputFormat != "" && flags.inputFormat != inputFormatK8sV1 {
+			return fmt.Errorf("only Kubernetes specs can be compared with the live rules")
+		}
+		flags.inputFormat = inputFormatK8sV1
+	}
+
+	// Generate the rules the same way the generate command does.
+	pipeline, err := flags.newPipeline(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := generateCommand{
+		generateFlags:    d.generateFlags,
+		slosInput:        d.slosInput,
+		slosExcludeRegex: d.slosExcludeRegex,
+		slosIncludeRegex: d.slosIncludeRegex,
+	}.loadInputs(config)
+	if err != nil {
+		return err
+	}
+
+	var diffs []string
+	if d.kube {
+		diffs, err = d.diffKubernetes(ctx, config.Logger, *pipeline, inputs)
+	} else {
+		diffs, err = d.diffOutFile(ctx, config.Logger, *pipeline, inputs)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(diffs) == 0 {
+		config.Logger.Infof("Generated rules don't have changes")
+		return nil
+	}
+
+	for _, res := range diffs {
+		if !config.NoColor {
+			res = diff.Colorize(res)
+		}
+		_, err := io.WriteString(config.Stdout, res)
+		if err != nil {
+			return fmt.Errorf("could not write diff: %w", err)
+		}
+	}
+
+	return fmt.Errorf("generated rules have changes")
+}
+
+// diffOutFile generates the rules of all the specs like the generate command does on a single
+// out file, and returns the diff with the out file (without the generated stamp).
+func (d diffCommand) diffOutFile(ctx context.Context, logger log.Logger, pipeline generatePipeline, inputs []generateInput) ([]string, error) {
+	var generated bytes.Buffer
+	for _, input := range inputs {
+		for _, data := range input.specs {
+			spec, err := pipeline.loadSpec(ctx, logger, data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q spec: %w", input.source, err)
+			}
+
+			_, err = generateSpec(ctx, logger, pipeline.opts, *spec, &generated)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// A missing out file is the same as an empty one, all the generated rules are new.
+	existing, err := os.ReadFile(d.slosOut)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("could not read %q out file: %w", d.slosOut, err)
+	}
+
+	res := diff.Unified(d.slosOut, d.slosOut+" (generated)", prometheus.TrimGeneratedStamp(existing), generated.Bytes())
+	if res == "" {
+		return nil, nil
+	}
+
+	return []string{res}, nil
+}
+
+// diffKubernetes generates the PrometheusRule objects of the Kubernetes specs and returns the
+// diff of every object with the live one. Only the rule groups are compared, the objects metadata
+// depends on the Kubernetes controller options.
+func (d diffCommand) diffKubernetes(ctx context.Context, logger log.Logger, pipeline generatePipeline, inputs []generateInput) ([]string, error) {
+	ksvc, err := d.newKubernetesService(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []string{}
+	for _, input := range inputs {
+		for _, data := range input.specs {
+			spec, err := pipeline.loadSpec(ctx, logger, data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q spec, only Kubernetes specs can be compared with the live rules: %w", input.source, err)
+			}
+			sloGroup := k8sprometheus.SLOGroup{K8sMeta: *spec.k8sMeta, SLOGroup: spec.sloGroup}
+
+			generated, err := generatePrometheusRule(ctx, logger, pipeline.opts, sloGroup)
+			if err != nil {
+				return nil, err
+			}
+
+			live, err := ksvc.GetPrometheusRule(ctx, sloGroup.K8sMeta.Namespace, sloGroup.K8sMeta.Name)
+			if err != nil {
+				return nil, fmt.Errorf("could not get %s/%s PrometheusRule: %w", sloGroup.K8sMeta.Namespace, sloGroup.K8sMeta.Name, err)
+			}
+
+			generatedData, err := prometheusRuleGroupsYAML(generated)
+			if err != nil {
+				return nil, err
+			}
+			liveData, err := prometheusRuleGroupsYAML(live)
+			if err != nil {
+				return nil, err
+			}
+
+			name := fmt.Sprintf("%s/%s", sloGroup.K8sMeta.Namespace, sloGroup.K8sMeta.Name)
+			if res := diff.Unified(name+" (live)", name+" (generated)", liveData, generatedData); res != "" {
+				diffs = append(diffs, res)
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+// generatePrometheusRule generates the PrometheusRule object of a Kubernetes spec, like the
+// Kubernetes controller does, nil if the SLOs don't have rules.
+func generatePrometheusRule(ctx context.Context, logger log.Logger, opts generateOptions, sloGroup k8sprometheus.SLOGroup) (*monitoringv1.PrometheusRule, error) {
+	info := info.Info{
+		Version: info.Version,
+		Mode:    info.ModeControllerGenKubernetes,
+		Spec:    fmt.Sprintf("%s/%s", kubernetesv1.SchemeGroupVersion.Group, kubernetesv1.SchemeGroupVersion.Version),
+	}
+	result, err := generateRules(ctx, logger, info, opts, sloGroup.SLOGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	storageSLOs := make([]k8sprometheus.StorageSLO, 0, len(result.PrometheusSLOs))
+	for _, s := range result.PrometheusSLOs {
+		storageSLOs = append(storageSLOs, k8sprometheus.StorageSLO{SLO: s.SLO, Rules: s.SLORules})
+	}
+
+	ensurer := &capturePrometheusRulesEnsurer{}
+	repo := k8sprometheus.NewPrometheusOperatorCRDRepo(ensurer, opts.k8sRuleMeta, logger)
+	err = repo.StoreSLOs(ctx, sloGroup.K8sMeta, storageSLOs)
+	if err != nil && !errors.Is(err, k8sprometheus.ErrNoSLORules) {
+		return nil, fmt.Errorf("could not generate %q PrometheusRule: %w", sloGroup.K8sMeta.Name, err)
+	}
+
+	return ensurer.rule, nil
+}
+
+func (d diffCommand) newKubernetesService(logger log.Logger) (*k8sprometheus.KubernetesService, error) {
+	kcfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: d.kubeConfig},
+		&clientcmd.ConfigOverrides{CurrentContext: d.kubeContext},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load configuration: %w", err)
+	}
+
+	kSlothCli, err := slothclientset.NewForConfig(kcfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Kubernetes sloth client: %w", err)
+	}
+
+	kMonitoringCli, err := monitoringclientset.NewForConfig(kcfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Kubernetes monitoring (prometheus-operator) client: %w", err)
+	}
+
+	ksvc := k8sprometheus.NewKubernetesService(kSlothCli, kMonitoringCli, logger)
+	return &ksvc, nil
+}
+
+// capturePrometheusRulesEnsurer captures the ensured PrometheusRule object instead of storing it.
+type capturePrometheusRulesEnsurer struct {
+	rule *monitoringv1.PrometheusRule
+}
+
+func (c *capturePrometheusRulesEnsurer) EnsurePrometheusRule(ctx context.Context, pr *monitoringv1.PrometheusRule) error {
+	c.rule = pr
+	return nil
+}
+
+// prometheusRuleGroupsYAML returns the YAML of the PrometheusRule object rule groups, empty if the
+// object is nil.
+func prometheusRuleGroupsYAML(pr *monitoringv1.PrometheusRule) ([]byte, error) {
+	if pr == nil {
+		return nil, nil
+	}
+
+	obj := &monitoringv1.PrometheusRule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pr.Name,
+			Namespace: pr.Namespace,
+		},
+		Spec: pr.Spec,
+	}
+
+	var b bytes.Buffer
+	err := json.NewYAMLSerializer(json.DefaultMetaFactory, nil, nil).Encode(obj, &b)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode %s/%s PrometheusRule: %w", pr.Namespace, pr.Name, err)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,300 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/alecthomas/kingpin.v2"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/pluginsource"
+)
+
+const (
+	doctorStatusOK   = "OK"
+	doctorStatusWarn = "WARN"
+	doctorStatusFail = "FAIL"
+	doctorStatusSkip = "SKIP"
+)
+
+// doctorCheck is the result of a doctor diagnostic check.
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+}
+
+type doctorCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	slosInput         string
+	slosExcludeRegex  string
+	slosIncludeRegex  string
+	prometheusAddress string
+	kubernetes        bool
+	kubeConfig        string
+	kubeContext       string
+	namespace         string
+	timeout           time.Duration
+}
+
+// NewDoctorCommand returns the doctor command.
+func NewDoctorCommand(app *kingpin.Application) Command {
+	c := &doctorCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("doctor", "Checks the Sloth environment (SLI plugins, SLO specs discovery, Prometheus and Kubernetes) and prints a diagnostic report.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files, if not set the discovery check will be skipped.").Short('i').StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("prometheus-address", "The Prometheus API address where the SLO recording rules are evaluated, if not set the Prometheus checks will be skipped.").StringVar(&c.prometheusAddress)
+	cmd.Flag("kubernetes", "Enables the Kubernetes connectivity and permissions checks of the Kubernetes controller mode.").BoolVar(&c.kubernetes)
+	kubeHome := filepath.Join(homedir.HomeDir(), ".kube", "config")
+	cmd.Flag("kube-config", "kubernetes configuration path.").Default(kubeHome).StringVar(&c.kubeConfig)
+	cmd.Flag("kube-context", "kubernetes context.").StringVar(&c.kubeContext)
+	cmd.Flag("namespace", "The namespace used on the Kubernetes permissions checks, by default all.").StringVar(&c.namespace)
+	cmd.Flag("timeout", "The maximum duration of every remote check.").Default("10s").DurationVar(&c.timeout)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (d doctorCommand) Name() string { return "doctor" }
+func (d doctorCommand) Run(ctx context.Context, config RootConfig) error {
+	checks := []doctorCheck{d.checkVersion()}
+	checks = append(checks, d.checkSLIPlugins(ctx, config.Logger, config.HTTPClient)...)
+	checks = append(checks, d.checkSpecDiscovery(ctx, config.Logger, config.HTTPClient))
+	checks = append(checks, d.checkPrometheus(ctx, config)...)
+	checks = append(checks, d.checkKubernetes(ctx)...)
+
+	w := tabwriter.NewWriter(config.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+	failed := 0
+	for _, c := range checks {
+		if c.status == doctorStatusFail {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.name, c.status, c.detail)
+	}
+	err := w.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write doctor report: %w", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d doctor checks failed", failed)
+	}
+
+	return nil
+}
+
+func (d doctorCommand) checkVersion() doctorCheck {
+	if info.Version == "dev" {
+		return doctorCheck{name: "version", status: doctorStatusWarn, detail: "development build, not a released Sloth version"}
+	}
+
+	return doctorCheck{name: "version", status: doctorStatusOK, detail: fmt.Sprintf("sloth %s", info.Version)}
+}
+
+func (d doctorCommand) checkSLIPlugins(ctx context.Context, logger log.Logger, httpClient *http.Client) []doctorCheck {
+	checks := []doctorCheck{}
+	for _, path := range d.sliPluginsPaths {
+		// Remote plugin sources are checked when fetched by the plugins loader.
+		if pluginsource.IsRemote(path) {
+			checks = append(checks, doctorCheck{name: "sli-plugins-path", status: doctorStatusOK, detail: path + " (remote)"})
+			continue
+		}
+
+		_, err := os.Stat(path)
+		if err != nil {
+			checks = append(checks, doctorCheck{name: "sli-plugins-path", status: doctorStatusFail, detail: fmt.Sprintf("%s: %s", path, err)})
+			continue
+		}
+		checks = append(checks, doctorCheck{name: "sli-plugins-path", status: doctorStatusOK, detail: path})
+	}
+
+	pluginRepo, err := d.createPluginLoader(ctx, logger, httpClient)
+	if err != nil {
+		return append(checks, doctorCheck{name: "sli-plugins", status: doctorStatusFail, detail: err.Error()})
+	}
+
+	plugins, err := pluginRepo.ListSLIPlugins(ctx)
+	if err != nil {
+		return append(checks, doctorCheck{name: "sli-plugins", status: doctorStatusFail, detail: err.Error()})
+	}
+
+	return append(checks, doctorCheck{name: "sli-plugins", status: doctorStatusOK, detail: fmt.Sprintf("%d plugins loaded", len(plugins))})
+}
+
+func (d doctorCommand) checkSpecDiscovery(ctx context.Context, logger log.Logger, httpClient *http.Client) doctorCheck {
+	const name = "spec-discovery"
+
+	if d.slosInput == "" {
+		return doctorCheck{name: name, status: doctorStatusSkip, detail: "input not set"}
+	}
+
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(d.slosExcludeRegex, d.slosIncludeRegex)
+	if err != nil {
+		return doctorCheck{name: name, status: doctorStatusFail, detail: err.Error()}
+	}
+
+	sloPaths, err := discoverSLOManifests(logger, excludeRegex, includeRegex, d.slosInput)
+	if err != nil {
+		return doctorCheck{name: name, status: doctorStatusFail, detail: fmt.Sprintf("could not discover files: %s", err)}
+	}
+	if len(sloPaths) == 0 {
+		return doctorCheck{name: name, status: doctorStatusWarn, detail: "0 slo specs have been discovered"}
+	}
+
+	pluginRepo, err := d.createPluginLoader(ctx, logger, httpClient)
+	if err != nil {
+		return doctorCheck{name: name, status: doctorStatusFail, detail: err.Error()}
+	}
+
+	slos, err := d.loadSLOs(ctx, logger, pluginRepo, sloPaths)
+	if err != nil {
+		return doctorCheck{name: name, status: doctorStatusFail, detail: err.Error()}
+	}
+
+	return doctorCheck{name: name, status: doctorStatusOK, detail: fmt.Sprintf("%d spec files, %d SLOs", len(sloPaths), len(slos))}
+}
+
+func (d doctorCommand) checkPrometheus(ctx context.Context, config RootConfig) []doctorCheck {
+	if d.prometheusAddress == "" {
+		return []doctorCheck{{name: "prometheus", status: doctorStatusSkip, detail: "prometheus address not set"}}
+	}
+
+	client, err := promapi.NewClient(promapi.Config{
+		Address:      d.prometheusAddress,
+		RoundTripper: config.HTTPClient.Transport,
+	})
+	if err != nil {
+		return []doctorCheck{{name: "prometheus", status: doctorStatusFail, detail: fmt.Sprintf("could not create Prometheus API client: %s", err)}}
+	}
+	api := promv1.NewAPI(client)
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	buildInfo, err := api.Buildinfo(ctx)
+	if err != nil {
+		return []doctorCheck{{name: "prometheus", status: doctorStatusFail, detail: fmt.Sprintf("could not reach Prometheus API: %s", err)}}
+	}
+	checks := []doctorCheck{{name: "prometheus", status: doctorStatusOK, detail: fmt.Sprintf("%s reachable (version %s)", d.prometheusAddress, buildInfo.Version)}}
+
+	// Check the Sloth generated rules are being evaluated.
+	const sloInfoQuery = `count(sloth_slo_info)`
+	result, _, err := api.Query(ctx, sloInfoQuery, time.Now())
+	if err != nil {
+		return append(checks, doctorCheck{name: "prometheus-slos", status: doctorStatusFail, detail: fmt.Sprintf("could not query SLOs: %s", err)})
+	}
+	vector, ok := result.(prommodel.Vector)
+	if !ok || len(vector) == 0 {
+		return append(checks, doctorCheck{name: "prometheus-slos", status: doctorStatusWarn, detail: "0 SLOs, Sloth metadata recording rules are not being evaluated"})
+	}
+
+	return append(checks, doctorCheck{name: "prometheus-slos", status: doctorStatusOK, detail: fmt.Sprintf("%v SLOs evaluated", vector[0].Value)})
+}
+
+func (d doctorCommand) checkKubernetes(ctx context.Context) []doctorCheck {
+	if !d.kubernetes {
+		return []doctorCheck{{name: "kubernetes", status: doctorStatusSkip, detail: "kubernetes checks not enabled"}}
+	}
+
+	kcfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: d.kubeConfig},
+		&clientcmd.ConfigOverrides{CurrentContext: d.kubeContext},
+	).ClientConfig()
+	if err != nil {
+		return []doctorCheck{{name: "kubernetes", status: doctorStatusFail, detail: fmt.Sprintf("could not load configuration: %s", err)}}
+	}
+	kcfg.Timeout = d.timeout
+
+	kcli, err := kubernetes.NewForConfig(kcfg)
+	if err != nil {
+		return []doctorCheck{{name: "kubernetes", status: doctorStatusFail, detail: fmt.Sprintf("could not create Kubernetes client: %s", err)}}
+	}
+
+	version, err := kcli.Discovery().ServerVersion()
+	if err != nil {
+		return []doctorCheck{{name: "kubernetes", status: doctorStatusFail, detail: fmt.Sprintf("could not reach Kubernetes API: %s", err)}}
+	}
+	checks := []doctorCheck{{name: "kubernetes", status: doctorStatusOK, detail: fmt.Sprintf("%s reachable (version %s)", kcfg.Host, version.GitVersion)}}
+
+	// Check the required resources are served by the API.
+	resources := []struct {
+		groupVersion string
+		resource     string
+	}{
+		{groupVersion: "sloth.slok.dev/v1", resource: "prometheusservicelevels"},
+		{groupVersion: "monitoring.coreos.com/v1", resource: "prometheusrules"},
+	}
+	for _, r := range resources {
+		name := fmt.Sprintf("kubernetes-resource %s", r.resource)
+		list, err := kcli.Discovery().ServerResourcesForGroupVersion(r.groupVersion)
+		if err != nil {
+			checks = append(checks, doctorCheck{name: name, status: doctorStatusFail, detail: fmt.Sprintf("%s not served: %s", r.groupVersion, err)})
+			continue
+		}
+
+		found := false
+		for _, apiResource := range list.APIResources {
+			if apiResource.Name == r.resource {
+				found = true
+				break
+			}
+		}
+		if !found {
+			checks = append(checks, doctorCheck{name: name, status: doctorStatusFail, detail: fmt.Sprintf("%s not served on %s, check the CRD is installed", r.resource, r.groupVersion)})
+			continue
+		}
+		checks = append(checks, doctorCheck{name: name, status: doctorStatusOK, detail: fmt.Sprintf("served on %s", r.groupVersion)})
+	}
+
+	// Check the permissions required by the Kubernetes controller.
+	permissions := []authorizationv1.ResourceAttributes{
+		{Group: "sloth.slok.dev", Resource: "prometheusservicelevels", Verb: "list"},
+		{Group: "sloth.slok.dev", Resource: "prometheusservicelevels", Verb: "watch"},
+		{Group: "sloth.slok.dev", Resource: "prometheusservicelevels", Subresource: "status", Verb: "update"},
+		{Group: "monitoring.coreos.com", Resource: "prometheusrules", Verb: "get"},
+		{Group: "monitoring.coreos.com", Resource: "prometheusrules", Verb: "create"},
+		{Group: "monitoring.coreos.com", Resource: "prometheusrules", Verb: "update"},
+	}
+	for _, p := range permissions {
+		p.Namespace = d.namespace
+		resource := p.Resource
+		if p.Subresource != "" {
+			resource = resource + "/" + p.Subresource
+		}
+		name := fmt.Sprintf("kubernetes-permission %s %s", p.Verb, resource)
+
+		review, err := kcli.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &p},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			checks = append(checks, doctorCheck{name: name, status: doctorStatusFail, detail: fmt.Sprintf("could not review access: %s", err)})
+			continue
+		}
+		if !review.Status.Allowed {
+			checks = append(checks, doctorCheck{name: name, status: doctorStatusFail, detail: strings.TrimSpace("not allowed " + review.Status.Reason)})
+			continue
+		}
+		checks = append(checks, doctorCheck{name: name, status: doctorStatusOK, detail: "allowed"})
+	}
+
+	return checks
+}
@@ -0,0 +1,400 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/log"
+)
+
+const testDoctorSpec = `
+version: "prometheus/v1"
+service: "myservice"
+slos:
+  - name: "requests-availability"
+    objective: 99.9
+    sli:
+      events:
+        error_query: sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))
+        total_query: sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`
+
+// newTestDoctorCommand returns the doctor command of the command line arguments, with the
+// flags defaults.
+func newTestDoctorCommand(t *testing.T, args ...string) doctorCommand {
+	app := kingpin.New("test-app", "Test application.")
+	cmd := NewDoctorCommand(app)
+	_, err := app.Parse(append([]string{"doctor"}, args...))
+	require.NoError(t, err)
+
+	return *cmd.(*doctorCommand)
+}
+
+// doctorCheckStatuses returns the `name=status` of the checks.
+func doctorCheckStatuses(checks []doctorCheck) []string {
+	res := []string{}
+	for _, c := range checks {
+		res = append(res, c.name+"="+c.status)
+	}
+	return res
+}
+
+func TestDoctorCheckVersion(t *testing.T) {
+	tests := map[string]struct {
+		version   string
+		expStatus string
+	}{
+		"A development build should warn.": {
+			version:   "dev",
+			expStatus: doctorStatusWarn,
+		},
+
+		"A released version should be OK.": {
+			version:   "v0.11.0",
+			expStatus: doctorStatusOK,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func(v string) { info.Version = v }(info.Version)
+			info.Version = test.version
+
+			check := newTestDoctorCommand(t).checkVersion()
+			assert.Equal(t, test.expStatus, check.status)
+		})
+	}
+}
+
+func TestDoctorCheckSLIPlugins(t *testing.T) {
+	tests := map[string]struct {
+		args      func(dir string) []string
+		expChecks []string
+	}{
+		"Existing SLI plugins paths should be OK.": {
+			args:      func(dir string) []string { return []string{"--sli-plugins-path", dir} },
+			expChecks: []string{"sli-plugins-path=OK", "sli-plugins=OK"},
+		},
+
+		"A missing SLI plugins path should fail.": {
+			args: func(dir string) []string {
+				return []string{"--sli-plugins-path", dir, "--sli-plugins-path", filepath.Join(dir, "missing")}
+			},
+			expChecks: []string{"sli-plugins-path=OK", "sli-plugins-path=FAIL", "sli-plugins=FAIL"},
+		},
+
+		"An invalid SLI plugin should fail.": {
+			args: func(dir string) []string {
+				pluginDir := filepath.Join(dir, "invalid")
+				require.NoError(t, os.MkdirAll(pluginDir, 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "plugin.go"), []byte("package invalid\n\nfunc {"), 0644))
+				return []string{"--sli-plugins-path", dir}
+			},
+			expChecks: []string{"sli-plugins-path=OK", "sli-plugins=FAIL"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := newTestDoctorCommand(t, test.args(t.TempDir())...)
+			checks := cmd.checkSLIPlugins(context.TODO(), log.Noop, http.DefaultClient)
+			assert.Equal(t, test.expChecks, doctorCheckStatuses(checks))
+		})
+	}
+}
+
+func TestDoctorCheckSpecDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		files     map[string]string
+		args      func(dir string) []string
+		expStatus string
+		expDetail string
+	}{
+		"Without input the check should be skipped.": {
+			args:      func(dir string) []string { return []string{"--sli-plugins-path", dir} },
+			expStatus: doctorStatusSkip,
+		},
+
+		"Valid specs should be OK.": {
+			files: map[string]string{
+				"slos/a.yaml": testDoctorSpec,
+				"slos/b.yaml": testDoctorSpec + "---\n" + strings.ReplaceAll(testDoctorSpec, "myservice", "other"),
+			},
+			args: func(dir string) []string {
+				return []string{"-i", filepath.Join(dir, "slos"), "--sli-plugins-path", dir}
+			},
+			expStatus: doctorStatusOK,
+			expDetail: "2 spec files, 3 SLOs",
+		},
+
+		"An input without specs should warn.": {
+			files: map[string]string{"slos/README.md": "# SLOs\n"},
+			args: func(dir string) []string {
+				return []string{"-i", filepath.Join(dir, "slos"), "--sli-plugins-path", dir}
+			},
+			expStatus: doctorStatusWarn,
+		},
+
+		"A missing input should fail.": {
+			args: func(dir string) []string {
+				return []string{"-i", filepath.Join(dir, "missing"), "--sli-plugins-path", dir}
+			},
+			expStatus: doctorStatusFail,
+		},
+
+		"An invalid discovery regex should fail.": {
+			files: map[string]string{"slos/a.yaml": testDoctorSpec},
+			args: func(dir string) []string {
+				return []string{"-i", filepath.Join(dir, "slos"), "--fs-exclude", "(", "--sli-plugins-path", dir}
+			},
+			expStatus: doctorStatusFail,
+		},
+
+		"An invalid spec should fail.": {
+			files: map[string]string{"slos/a.yaml": strings.ReplaceAll(testDoctorSpec, "prometheus/v1", "unknown/v1")},
+			args: func(dir string) []string {
+				return []string{"-i", filepath.Join(dir, "slos"), "--sli-plugins-path", dir}
+			},
+			expStatus: doctorStatusFail,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			dir := t.TempDir()
+			for path, data := range test.files {
+				path = filepath.Join(dir, path)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+			}
+
+			cmd := newTestDoctorCommand(t, test.args(dir)...)
+			check := cmd.checkSpecDiscovery(context.TODO(), log.Noop, http.DefaultClient)
+
+			assert.Equal("spec-discovery", check.name)
+			assert.Equal(test.expStatus, check.status, check.detail)
+			if test.expDetail != "" {
+				assert.Equal(test.expDetail, check.detail)
+			}
+		})
+	}
+}
+
+func TestDoctorCheckPrometheus(t *testing.T) {
+	const buildInfo = `{"status":"success","data":{"version":"2.45.0","revision":"","branch":"","buildUser":"","buildDate":"","goVersion":""}}`
+
+	tests := map[string]struct {
+		handler   http.HandlerFunc
+		address   func(srv *httptest.Server) string
+		expChecks []string
+		expDetail string
+	}{
+		"Without Prometheus address the checks should be skipped.": {
+			address:   func(srv *httptest.Server) string { return "" },
+			expChecks: []string{"prometheus=SKIP"},
+		},
+
+		"An unreachable Prometheus should fail.": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			address:   func(srv *httptest.Server) string { return srv.URL },
+			expChecks: []string{"prometheus=FAIL"},
+		},
+
+		"A Prometheus that fails the SLOs query should fail.": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/status/buildinfo" {
+					fmt.Fprint(w, buildInfo)
+					return
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"invalid query"}`)
+			},
+			address:   func(srv *httptest.Server) string { return srv.URL },
+			expChecks: []string{"prometheus=OK", "prometheus-slos=FAIL"},
+		},
+
+		"A Prometheus without SLOs should warn.": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/status/buildinfo" {
+					fmt.Fprint(w, buildInfo)
+					return
+				}
+				fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+			},
+			address:   func(srv *httptest.Server) string { return srv.URL },
+			expChecks: []string{"prometheus=OK", "prometheus-slos=WARN"},
+		},
+
+		"A Prometheus evaluating the SLOs should be OK.": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/status/buildinfo" {
+					fmt.Fprint(w, buildInfo)
+					return
+				}
+				fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1622548800,"3"]}]}}`)
+			},
+			address:   func(srv *httptest.Server) string { return srv.URL },
+			expChecks: []string{"prometheus=OK", "prometheus-slos=OK"},
+			expDetail: "3 SLOs evaluated",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			srv := httptest.NewServer(test.handler)
+			defer srv.Close()
+
+			cmd := newTestDoctorCommand(t, "--prometheus-address", test.address(srv))
+			checks := cmd.checkPrometheus(context.TODO(), RootConfig{HTTPClient: &http.Client{Transport: http.DefaultTransport}})
+
+			assert.Equal(test.expChecks, doctorCheckStatuses(checks))
+			if test.expDetail != "" {
+				assert.Equal(test.expDetail, checks[len(checks)-1].detail)
+			}
+		})
+	}
+}
+
+func TestDoctorCheckKubernetes(t *testing.T) {
+	// newKubeAPI returns a fake Kubernetes API, serving the resources of the group versions and
+	// allowing the access reviews.
+	newKubeAPI := func(groupVersions map[string]string, allowed bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/version":
+				fmt.Fprint(w, `{"major":"1","minor":"27","gitVersion":"v1.27.0"}`)
+			case r.URL.Path == "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews":
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"kind":"SelfSubjectAccessReview","apiVersion":"authorization.k8s.io/v1","status":{"allowed":%t}}`, allowed)
+			case strings.HasPrefix(r.URL.Path, "/apis/"):
+				gv := strings.TrimPrefix(r.URL.Path, "/apis/")
+				resource, ok := groupVersions[gv]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+					return
+				}
+				fmt.Fprintf(w, `{"kind":"APIResourceList","apiVersion":"v1","groupVersion":%q,"resources":[{"name":%q,"namespaced":true,"kind":"Test","verbs":["list"]}]}`, gv, resource)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+	}
+	allGroupVersions := map[string]string{
+		"sloth.slok.dev/v1":        "prometheusservicelevels",
+		"monitoring.coreos.com/v1": "prometheusrules",
+	}
+	permissionChecks := func(status string) []string {
+		return []string{
+			"kubernetes-permission list prometheusservicelevels=" + status,
+			"kubernetes-permission watch prometheusservicelevels=" + status,
+			"kubernetes-permission update prometheusservicelevels/status=" + status,
+			"kubernetes-permission get prometheusrules=" + status,
+			"kubernetes-permission create prometheusrules=" + status,
+			"kubernetes-permission update prometheusrules=" + status,
+		}
+	}
+
+	tests := map[string]struct {
+		handler    http.HandlerFunc
+		args       func(kubeConfig string) []string
+		expChecks  []string
+		noKubeConf bool
+	}{
+		"Without the Kubernetes checks enabled they should be skipped.": {
+			handler:   newKubeAPI(allGroupVersions, true),
+			args:      func(kubeConfig string) []string { return []string{"--kube-config", kubeConfig} },
+			expChecks: []string{"kubernetes=SKIP"},
+		},
+
+		"A missing Kubernetes configuration should fail.": {
+			handler:    newKubeAPI(allGroupVersions, true),
+			args:       func(kubeConfig string) []string { return []string{"--kubernetes", "--kube-config", kubeConfig} },
+			noKubeConf: true,
+			expChecks:  []string{"kubernetes=FAIL"},
+		},
+
+		"An unreachable Kubernetes API should fail.": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			args:      func(kubeConfig string) []string { return []string{"--kubernetes", "--kube-config", kubeConfig} },
+			expChecks: []string{"kubernetes=FAIL"},
+		},
+
+		"A Kubernetes API with the resources and the permissions should be OK.": {
+			handler: newKubeAPI(allGroupVersions, true),
+			args:    func(kubeConfig string) []string { return []string{"--kubernetes", "--kube-config", kubeConfig} },
+			expChecks: append([]string{
+				"kubernetes=OK",
+				"kubernetes-resource prometheusservicelevels=OK",
+				"kubernetes-resource prometheusrules=OK",
+			}, permissionChecks("OK")...),
+		},
+
+		"A Kubernetes API without the resources and the permissions should fail.": {
+			handler: newKubeAPI(map[string]string{"sloth.slok.dev/v1": "other"}, false),
+			args:    func(kubeConfig string) []string { return []string{"--kubernetes", "--kube-config", kubeConfig} },
+			expChecks: append([]string{
+				"kubernetes=OK",
+				"kubernetes-resource prometheusservicelevels=FAIL",
+				"kubernetes-resource prometheusrules=FAIL",
+			}, permissionChecks("FAIL")...),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(test.handler)
+			defer srv.Close()
+
+			kubeConfig := filepath.Join(t.TempDir(), "kubeconfig")
+			if !test.noKubeConf {
+				data := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`, srv.URL)
+				require.NoError(t, os.WriteFile(kubeConfig, []byte(data), 0644))
+			}
+
+			cmd := newTestDoctorCommand(t, test.args(kubeConfig)...)
+			checks := cmd.checkKubernetes(context.TODO())
+			assert.Equal(t, test.expChecks, doctorCheckStatuses(checks))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+type exportMetricsCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	out              string
+	listenAddr       string
+	metricsPath      string
+}
+
+// NewExportMetricsCommand returns the export metrics command.
+func NewExportMetricsCommand(app *kingpin.Application) Command {
+	c := &exportMetricsCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("export-metrics", "Exports the SLOs definitions (objectives, windows, alerts and labels) as OpenMetrics, on a file or an HTTP endpoint, so external inventory collectors can ingest the SLOs without parsing the specs.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("out", "OpenMetrics output file path. If `-` it will use stdout.").Short('o').Default("-").StringVar(&c.out)
+	cmd.Flag("listen-addr", "If set, instead of writing the out file, it will serve the OpenMetrics on this HTTP listen address, discovering the SLO specs on every request.").StringVar(&c.listenAddr)
+	cmd.Flag("metrics-path", "The HTTP path of the OpenMetrics, only used with the listen address.").Default("/metrics").StringVar(&c.metricsPath)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (e exportMetricsCommand) Name() string { return "export-metrics" }
+func (e exportMetricsCommand) Run(ctx context.Context, config RootConfig) error {
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(e.slosExcludeRegex, e.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	pluginRepo, err := e.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	export := func(ctx context.Context, out io.Writer) error {
+		sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, e.slosInput)
+		if err != nil {
+			return fmt.Errorf("could not discover files: %w", err)
+		}
+		if len(sloPaths) == 0 {
+			return fmt.Errorf("0 slo specs have been discovered")
+		}
+
+		slos, err := e.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+		if err != nil {
+			return err
+		}
+
+		storageSLOs := make([]prometheus.StorageSLO, 0, len(slos))
+		for _, s := range slos {
+			storageSLOs = append(storageSLOs, prometheus.StorageSLO{SLO: s.SLO, Source: s.Path})
+		}
+
+		return prometheus.NewIOWriterSLOMetricsOpenMetricsRepo(out, config.Logger).StoreSLOs(ctx, storageSLOs)
+	}
+
+	if e.listenAddr != "" {
+		return e.serve(ctx, config.Logger, export)
+	}
+
+	var out io.Writer = config.Stdout
+	if e.out != "-" {
+		f, err := os.Create(e.out)
+		if err != nil {
+			return fmt.Errorf("could not create out file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return export(ctx, out)
+}
+
+func (e exportMetricsCommand) serve(ctx context.Context, logger log.Logger, export func(ctx context.Context, out io.Writer) error) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(e.metricsPath, func(w http.ResponseWriter, r *http.Request) {
+		var b bytes.Buffer
+		err := export(r.Context(), &b)
+		if err != nil {
+			logger.Errorf("Could not export SLO metrics: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		_, _ = w.Write(b.Bytes())
+	})
+
+	server := &http.Server{Addr: e.listenAddr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	errC := make(chan error, 1)
+	go func() {
+		logger.WithValues(log.Kv{"addr": e.listenAddr, "path": e.metricsPath}).Infof("SLO metrics HTTP server listening")
+		errC <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errC:
+		return fmt.Errorf("SLO metrics HTTP server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+type fmtCommand struct {
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	check            bool
+}
+
+// NewFmtCommand returns the fmt command.
+func NewFmtCommand(app *kingpin.Application) Command {
+	c := &fmtCommand{}
+	cmd := app.Command("fmt", "Canonicalizes the human-friendly values of the SLO spec files (e.g `30 days` durations to `30d` and `three nines` objectives to `99.9`).")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("check", "Reports the changes without modifying the SLO spec files, and fails if any of them is not canonical (e.g for CI).").BoolVar(&c.check)
+
+	return c
+}
+
+func (f fmtCommand) Name() string { return "fmt" }
+func (f fmtCommand) Run(ctx context.Context, config RootConfig) error {
+	// Set up files discovery filter regex.
+	var excludeRegex *regexp.Regexp
+	var includeRegex *regexp.Regexp
+	if f.slosExcludeRegex != "" {
+		re, err := regexp.Compile(f.slosExcludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid exclude regex: %w", err)
+		}
+		excludeRegex = re
+	}
+	if f.slosIncludeRegex != "" {
+		re, err := regexp.Compile(f.slosIncludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid include regex: %w", err)
+		}
+		includeRegex = re
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, f.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+
+	// Format all the files before writing, so we don't leave a partial format on errors.
+	formattedPaths := []string{}
+	formattedFiles := map[string][]byte{}
+	totalChanges := 0
+	for _, path := range sloPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %q SLOs spec file data: %w", path, err)
+		}
+
+		newData, changes, err := prometheus.FmtSpecYAML(data)
+		if err != nil {
+			return fmt.Errorf("could not format %q SLOs spec file: %w", path, err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		formattedPaths = append(formattedPaths, path)
+		formattedFiles[path] = newData
+		totalChanges += len(changes)
+
+		// Report the changes.
+		for _, c := range changes {
+			fmt.Fprintf(config.Stdout, "%s:%d: %s: %q -> %q\n", path, c.Line, c.Path, c.OldValue, c.NewValue)
+		}
+	}
+
+	logger := config.Logger.WithValues(log.Kv{"files": len(formattedPaths), "changes": totalChanges})
+	if f.check {
+		if len(formattedPaths) > 0 {
+			return fmt.Errorf("%d SLO spec files are not canonical", len(formattedPaths))
+		}
+		logger.Infof("SLO spec files are canonical")
+		return nil
+	}
+
+	for _, path := range formattedPaths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("could not stat %q SLOs spec file: %w", path, err)
+		}
+
+		err = os.WriteFile(path, formattedFiles[path], info.Mode())
+		if err != nil {
+			return fmt.Errorf("could not write %q SLOs spec file: %w", path, err)
+		}
+	}
+
+	logger.Infof("SLO spec files formatted")
+
+	return nil
+}
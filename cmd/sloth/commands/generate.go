@@ -1,10 +1,17 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 
@@ -13,111 +20,1054 @@ import (
 	"github.com/slok/sloth/internal/info"
 	"github.com/slok/sloth/internal/k8sprometheus"
 	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/newrelic"
+	"github.com/slok/sloth/internal/openslo"
 	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/internal/usage"
 	kubernetesv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
 	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+const (
+	inputFormatPrometheusV1 = "prometheus/v1"
+	inputFormatK8sV1        = "k8s/v1"
+	inputFormatOpenSLOV1    = "openslo/v1"
 )
 
 type generateCommand struct {
-	slosInput         string
-	slosOut           string
-	disableRecordings bool
-	disableAlerts     bool
-	extraLabels       map[string]string
-	sliPluginsPaths   []string
+	generateFlags
+	slosInput             string
+	slosExcludeRegex      string
+	slosIncludeRegex      string
+	slosOut               string
+	slosOutDir            string
+	outFileTemplate       string
+	progress              string
+	inhibitRulesOut       string
+	timeIntervalsOut      string
+	indexOut              string
+	usageReportOut        string
+	grafanaAlertRulesOut  string
+	grafanaDatasourceUID  string
+	grafanaFolder         string
+	newRelicConditionsOut string
+	costLabelsAllowlist   string
+	labelRegistryURL      string
+	labelRegistryLabels   []string
+	labelRegistryCacheTTL time.Duration
+	existingRules         string
+	refuseUnmanaged       bool
 }
 
 // NewGenerateCommand returns the generate command.
 func NewGenerateCommand(app *kingpin.Application) Command {
-	c := &generateCommand{extraLabels: map[string]string{}}
+	c := &generateCommand{generateFlags: newGenerateFlags()}
 	cmd := app.Command("generate", "Generates Prometheus SLOs.")
-	cmd.Flag("input", "SLO spec input file path.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("input", "SLO spec input file path. If `-` it will use stdin, if a directory or a .tar.gz, .tgz, .tar or .zip archive it will discover recursively all YAML files.").Short('i').Default("-").StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths, only used with a directory input.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference, only used with a directory input.").Short('n').StringVar(&c.slosIncludeRegex)
 	cmd.Flag("out", "Generated rules output file path. If `-` it will use stdout.").Short('o').Default("-").StringVar(&c.slosOut)
-	cmd.Flag("extra-labels", "Extra labels that will be added to all the generated Prometheus rules ('key=value' form, can be repeated).").Short('l').StringMapVar(&c.extraLabels)
-	cmd.Flag("disable-recordings", "Disables recording rules generation.").BoolVar(&c.disableRecordings)
-	cmd.Flag("disable-alerts", "Disables alert rules generation.").BoolVar(&c.disableAlerts)
-	cmd.Flag("sli-plugins-path", "The path to SLI plugins (can be repeated), if not set it disable plugins support.").Short('p').StringsVar(&c.sliPluginsPaths)
+	cmd.Flag("out-dir", "If set, the rules of every SLO spec input file will be generated on its own file on this directory path (with the same relative path as the input), instead of on the out file.").StringVar(&c.slosOutDir)
+	cmd.Flag("out-file-template", "The Go template of the out dir rules file paths, to generate a file per service or SLO instead of per input file (e.g '{{ .Service }}/{{ .SLOName }}.yaml'). The SLOs rendered to the same path share the file. Available fields: Source (input relative path), Service, SLOName and SLOID.").StringVar(&c.outFileTemplate)
+	c.generateFlags.register(cmd)
+	cmd.Flag("progress", "Shows the progress of the run on stderr, as a bar (for TTYs) or as periodic JSON events (for CI logs).").Default(progressNone).EnumVar(&c.progress, progressNone, progressBar, progressJSON)
+	cmd.Flag("alertmanager-inhibit-rules-out", "If set, it will generate the SLO alerts Alertmanager inhibition rules config fragment on this file path.").StringVar(&c.inhibitRulesOut)
+	cmd.Flag("alertmanager-time-intervals-out", "If set, it will generate the SLO maintenance windows Alertmanager time intervals config fragment, with the SLO alerts mute time intervals to set on your routes, on this file path.").StringVar(&c.timeIntervalsOut)
+	cmd.Flag("index-out", "If set, it will generate a JSON index that maps the generated rules to their source file, service and SLO on this file path.").StringVar(&c.indexOut)
+	cmd.Flag("usage-report-out", "If set (opt-in), the anonymous usage stats of the run (e.g the SLI types and SLI plugins counts) will be appended as a JSON line on this file path, to know the features adoption. The stats don't have any SLO spec data.").StringVar(&c.usageReportOut)
+	cmd.Flag("grafana-alert-rules-out", "If set, the SLO alerts will be generated as Grafana managed alert rules provisioning file on this file path, instead of as Prometheus alert rules.").StringVar(&c.grafanaAlertRulesOut)
+	cmd.Flag("grafana-datasource-uid", "The UID of the Grafana Prometheus datasource that will evaluate the Grafana alert rules.").StringVar(&c.grafanaDatasourceUID)
+	cmd.Flag("grafana-folder", "The Grafana folder of the Grafana alert rules.").Default("SLOs").StringVar(&c.grafanaFolder)
+	cmd.Flag("newrelic-nrql-conditions-out", "If set, the SLO alerts will be generated as New Relic NRQL alert conditions JSON on this file path, instead of as Prometheus alert rules.").StringVar(&c.newRelicConditionsOut)
+	cmd.Flag("cost-labels-allowlist", "If set, the SLOs cost labels will be validated against the allowed labels and values of this YAML file path.").StringVar(&c.costLabelsAllowlist)
+	cmd.Flag("label-values-registry-url", "If set, the SLOs label values of the registry labels will be validated against this HTTP allowlist service ('GET <url>?label=<label>&value=<value>', 200 exists and 404 doesn't exist).").StringVar(&c.labelRegistryURL)
+	cmd.Flag("label-values-registry-label", "The SLO labels (e.g team, product) validated against the label values registry (can be repeated).").StringsVar(&c.labelRegistryLabels)
+	cmd.Flag("label-values-registry-cache-ttl", "The time the label values registry responses are cached.").Default("5m").DurationVar(&c.labelRegistryCacheTTL)
+	cmd.Flag("existing-rules", "If set, the generated recording rules and alerts names will be checked against the non Sloth rules of this Prometheus rules file path, failing on collisions.").StringVar(&c.existingRules)
+	cmd.Flag("refuse-overwrite-unmanaged", "Refuses to overwrite the rules out files that are not generated by Sloth or that have been edited, based on the checksum stamp of the generated rules.").BoolVar(&c.refuseUnmanaged)
 
 	return c
 }
 
+// generateFlags are the SLO specs loading and rules generation flags of the generate command,
+// shared by the commands that generate the rules like generate does.
+type generateFlags struct {
+	specLoadFlags
+	sliPluginFlags
+	inputFormat             string
+	disableRecordings       bool
+	disableAlerts           bool
+	alertsOnly              bool
+	minimal                 bool
+	sliZeroTotalGuard       bool
+	errorBudgetForecast     bool
+	extraLabels             map[string]string
+	defaultAnnotations      map[string]string
+	groupLabels             map[string]string
+	alertForJitterMin       time.Duration
+	alertForJitterMax       time.Duration
+	targetPlatform          string
+	partialResponseStrategy string
+	ruleGroupInterval       time.Duration
+	sliRuleGroupInterval    time.Duration
+	metaRuleGroupInterval   time.Duration
+	alertRuleGroupInterval  time.Duration
+	sliWindowRuleGroups     bool
+	sliWindowGroupIntervals map[string]string
+	strictFields            bool
+	k8sRuleFormat           string
+}
+
+func newGenerateFlags() generateFlags {
+	return generateFlags{specLoadFlags: newSpecLoadFlags(), extraLabels: map[string]string{}, defaultAnnotations: map[string]string{}, groupLabels: map[string]string{}, sliWindowGroupIntervals: map[string]string{}}
+}
+
+// register registers the generate flags on the command.
+func (g *generateFlags) register(cmd *kingpin.CmdClause) {
+	cmd.Flag("input-format", "Forces the SLO spec input format instead of trying all the supported ones.").EnumVar(&g.inputFormat, inputFormatPrometheusV1, inputFormatK8sV1, inputFormatOpenSLOV1)
+	cmd.Flag("extra-labels", "Extra labels that will be added to all the generated Prometheus rules ('key=value' form, can be repeated).").Short('l').StringMapVar(&g.extraLabels)
+	cmd.Flag("default-extra-annotations", "Default annotations that will be added to all the generated alert rules ('key=value' form, can be repeated), the SLO spec annotations have preference over them.").StringMapVar(&g.defaultAnnotations)
+	cmd.Flag("group-labels", "Labels that will be added to all the generated rule groups, supported by rulers like Mimir and Loki ('key=value' form, can be repeated). Only used with Prometheus spec inputs.").StringMapVar(&g.groupLabels)
+	cmd.Flag("disable-recordings", "Disables recording rules generation.").BoolVar(&g.disableRecordings)
+	cmd.Flag("disable-alerts", "Disables alert rules generation.").BoolVar(&g.disableAlerts)
+	cmd.Flag("alerts-only", "Generates only the alert rules, assuming the SLI recording rules already exist with the standard Sloth names (SLOs without SLI will use them).").BoolVar(&g.alertsOnly)
+	cmd.Flag("minimal", "Generates only the rules required by the alerts, without the optional metadata recording rules, for Prometheus instances that only need paging.").BoolVar(&g.minimal)
+	cmd.Flag("sli-zero-total-guard", "Guards the events SLI recording rules against zero totals, the windows without events have a 0 error ratio instead of NaN series that break the error budget calculations.").BoolVar(&g.sliZeroTotalGuard)
+	cmd.Flag("error-budget-forecast", "Generates the error budget exhaustion forecast recording rules, the seconds left until the SLO period error budget is exhausted at the current burn rate trend.").BoolVar(&g.errorBudgetForecast)
+	g.sliPluginFlags.register(cmd)
+	cmd.Flag("alert-for-jitter-min", "The minimum jitter of the generated alerts for duration.").Default("0s").DurationVar(&g.alertForJitterMin)
+	cmd.Flag("alert-for-jitter-max", "If set, the generated alerts will have a deterministic per SLO for duration between the jitter min and max, to stagger the pages of SLOs sharing a failing dependency.").Default("0s").DurationVar(&g.alertForJitterMax)
+	cmd.Flag("target-platform", "The platform that will evaluate the generated rules, Thanos ruler enables the Thanos rule groups extensions.").Default(targetPlatformPrometheus).EnumVar(&g.targetPlatform, targetPlatformPrometheus, targetPlatformThanos)
+	cmd.Flag("thanos-partial-response-strategy", "The Thanos ruler partial response strategy of the generated rule groups, only used with Thanos target platform or Thanos ruler Kubernetes rule format.").Default(thanosPartialResponseAbort).EnumVar(&g.partialResponseStrategy, thanosPartialResponseAbort, thanosPartialResponseWarn)
+	cmd.Flag("rule-group-interval", "The evaluation interval of the generated rule groups, if 0 the rule groups use the ruler global evaluation interval. The SLO spec rule group intervals have priority.").Default("0s").DurationVar(&g.ruleGroupInterval)
+	cmd.Flag("sli-rule-group-interval", "The evaluation interval of the generated SLI recording rule groups, if 0 it will use the rule group interval.").Default("0s").DurationVar(&g.sliRuleGroupInterval)
+	cmd.Flag("meta-rule-group-interval", "The evaluation interval of the generated metadata recording rule groups, if 0 it will use the rule group interval.").Default("0s").DurationVar(&g.metaRuleGroupInterval)
+	cmd.Flag("alert-rule-group-interval", "The evaluation interval of the generated alert rule groups, if 0 it will use the rule group interval.").Default("0s").DurationVar(&g.alertRuleGroupInterval)
+	cmd.Flag("sli-window-rule-groups", "Groups the generated SLI recording rules of all the SLOs in rule groups shared per SLI window (e.g all the 5m rules together and all the 30d rules together), so the long windows can be evaluated less often.").BoolVar(&g.sliWindowRuleGroups)
+	cmd.Flag("sli-window-rule-group-interval", "The evaluation interval of the SLI window rule groups of a window ('window=interval' form, e.g '30d=5m', can be repeated), the windows without interval use the SLI rule group interval.").StringMapVar(&g.sliWindowGroupIntervals)
+	g.specLoadFlags.register(cmd)
+	cmd.Flag("strict-fields", "Fails on the SLO spec fields that are unknown to the spec (e.g a typo in a field name), instead of ignoring them. OpenSLO specs are not checked.").BoolVar(&g.strictFields)
+	cmd.Flag("k8s-rule-format", "The Kubernetes rule objects format of the Kubernetes spec inputs, Thanos ruler format sets the Thanos partial response strategy on the rule groups.").Default(string(k8sprometheus.RuleFormatPrometheusOperator)).EnumVar(&g.k8sRuleFormat, string(k8sprometheus.RuleFormatPrometheusOperator), string(k8sprometheus.RuleFormatVictoriaMetrics), string(k8sprometheus.RuleFormatThanosRuler))
+}
+
+// generatePipeline loads the SLO specs and prepares them for the rules generation with the
+// generate options, shared by the commands that generate the rules like generate does.
+type generatePipeline struct {
+	specLoaders        []specLoader
+	costAllowlist      prometheus.CostLabelsAllowlist
+	labelRegistry      *prometheus.LabelValuesRegistry
+	defaultAnnotations map[string]string
+	alertsOnly         bool
+	opts               generateOptions
+}
+
+// newPipeline returns the generate pipeline of the generate flags.
+func (g generateFlags) newPipeline(ctx context.Context, config RootConfig) (*generatePipeline, error) {
+	if g.alertsOnly && g.disableAlerts {
+		return nil, fmt.Errorf("alerts only mode can't be used with the alerts disabled")
+	}
+
+	partialResponseStrategy := partialResponseStrategyFor(g.targetPlatform, g.partialResponseStrategy)
+	ruleGroupIntervals := ruleGroupIntervalsFor(g.ruleGroupInterval, g.sliRuleGroupInterval, g.metaRuleGroupInterval, g.alertRuleGroupInterval)
+	sliWindowGroups, err := sliWindowGroupsFor(g.sliWindowRuleGroups, g.sliWindowGroupIntervals)
+	if err != nil {
+		return nil, err
+	}
+	k8sRuleMeta := k8sprometheus.PrometheusRuleMeta{PartialResponseStrategy: partialResponseStrategy, Intervals: ruleGroupIntervals, SLIWindowGroups: sliWindowGroups, Format: k8sprometheus.RuleFormat(g.k8sRuleFormat)}
+	if k8sRuleMeta.Format == k8sprometheus.RuleFormatThanosRuler {
+		k8sRuleMeta.PartialResponseStrategy = g.partialResponseStrategy
+	}
+	alertForJitter := prometheus.AlertForJitter{Min: g.alertForJitterMin, Max: g.alertForJitterMax}
+	err = alertForJitter.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	pluginRepo, err := g.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	specLoaders, err := g.specLoaders(config.Logger, pluginRepo, g.inputFormat, g.strictFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &generatePipeline{
+		specLoaders:        specLoaders,
+		defaultAnnotations: g.defaultAnnotations,
+		alertsOnly:         g.alertsOnly,
+		opts: generateOptions{
+			disableRecordings:   g.disableRecordings || g.alertsOnly,
+			disableAlerts:       g.disableAlerts,
+			minimal:             g.minimal,
+			sliZeroTotalGuard:   g.sliZeroTotalGuard,
+			errorBudgetForecast: g.errorBudgetForecast,
+			extraLabels:         g.extraLabels,
+			alertForJitter:      alertForJitter,
+			ruleGroupsMeta:      prometheus.RuleGroupsMeta{PartialResponseStrategy: partialResponseStrategy, Labels: g.groupLabels, Intervals: ruleGroupIntervals, SLIWindowGroups: sliWindowGroups},
+			k8sRuleMeta:         k8sRuleMeta,
+		},
+	}, nil
+}
+
+// loadSpec loads an SLO spec and prepares its SLOs for the rules generation.
+func (p generatePipeline) loadSpec(ctx context.Context, logger log.Logger, data []byte) (*loadedSpec, error) {
+	spec, err := loadSpec(ctx, p.specLoaders, data)
+	if err != nil {
+		logSpecLoadErrors(logger, err)
+		return nil, err
+	}
+	slos := spec.sloGroup.SLOs
+
+	err = validateSLOLabels(ctx, p.costAllowlist, p.labelRegistry, slos)
+	if err != nil {
+		return nil, err
+	}
+	addDefaultAnnotations(slos, p.defaultAnnotations)
+	if p.alertsOnly {
+		useExistingSLIRecordings(slos)
+	}
+	if spec.k8sMeta != nil && len(p.opts.ruleGroupsMeta.Labels) > 0 {
+		logger.Warningf("Rule group labels are not supported by the Prometheus operator rules, ignoring them")
+	}
+
+	return spec, nil
+}
+
 func (g generateCommand) Name() string { return "generate" }
 func (g generateCommand) Run(ctx context.Context, config RootConfig) error {
 	ctx = config.Logger.SetValuesOnCtx(ctx, log.Kv{
 		"out": g.slosOut,
 	})
 
-	// Get SLO spec data.
-	// TODO(slok): stdin.
-	f, err := os.Open(g.slosInput)
+	pipeline, err := g.newPipeline(ctx, config)
 	if err != nil {
-		return fmt.Errorf("could not open SLOs spec file: %w", err)
+		return err
+	}
+
+	// If the alerts are evaluated by other backends, Prometheus only needs the recording rules.
+	alertsBackends, err := g.alertsBackends()
+	if err != nil {
+		return err
+	}
+	if len(alertsBackends) > 0 {
+		if g.disableAlerts || g.alertsOnly {
+			return fmt.Errorf("alerts backends can't be used with the alerts disabled or in alerts only mode")
+		}
+		pipeline.opts.disableAlerts = true
+	}
+
+	// Get SLO specs data.
+	if g.slosOutDir != "" && g.slosInput == "-" {
+		return fmt.Errorf("out dir can't be used with stdin input")
+	}
+	inputs, err := g.loadInputs(config)
+	if err != nil {
+		return err
+	}
+	outFileTpl, err := g.parseOutFileTemplate()
+	if err != nil {
+		return err
+	}
+
+	costAllowlist, err := loadCostLabelsAllowlist(g.costLabelsAllowlist)
+	if err != nil {
+		return err
 	}
-	defer f.Close()
 
-	slxData, err := io.ReadAll(f)
+	labelRegistry, err := newLabelValuesRegistry(config.HTTPClient, g.labelRegistryURL, g.labelRegistryLabels, g.labelRegistryCacheTTL)
 	if err != nil {
-		return fmt.Errorf("could not read SLOs spec file data: %w", err)
+		return err
 	}
 
-	// Load plugins
-	pluginRepo, err := createPluginLoader(ctx, config.Logger, g.sliPluginsPaths)
+	existingRules, err := loadExistingRules(g.existingRules)
 	if err != nil {
 		return err
 	}
 
-	// Create Spec loaders.
-	promYAMLLoader := prometheus.NewYAMLSpecLoader(pluginRepo)
-	kubeYAMLLoader := k8sprometheus.NewYAMLSpecLoader(pluginRepo)
+	pipeline.costAllowlist = costAllowlist
+	pipeline.labelRegistry = labelRegistry
+	pipeline.opts.existingRules = existingRules
 
-	// Prepare store output.
-	var out io.Writer = config.Stdout
-	if g.slosOut != "-" {
-		f, err := os.Create(g.slosOut)
-		if err != nil {
-			return fmt.Errorf("could not create out file: %w", err)
-		}
-		defer f.Close()
-		out = f
+	// Prepare store outputs, the outputs are written with their checksum stamp once all the
+	// SLOs have been generated.
+	outs := newGeneratedOuts(config.Stdout, g.refuseUnmanaged)
+
+	// All the generated SLOs, used by the generators that need the whole SLO set.
+	allSLOs := []prometheus.SLO{}
+	// All the generated results, used to index the generated rules.
+	allResults := []generate.SLOResult{}
+	// The spec source of every generated SLO, used to index the generated rules.
+	sloSources := map[string]string{}
+
+	// The usage of the specs, only if enabled.
+	var usageRecorder *usage.Recorder
+	if g.usageReportOut != "" {
+		usageRecorder = usage.NewRecorder(g.Name())
+	}
+
+	totalSpecs := 0
+	for _, input := range inputs {
+		totalSpecs += len(input.specs)
 	}
+	progress := newProgressReporter(g.progress, config.Stderr, "specs", totalSpecs)
+	defer progress.Finish()
 
-	// Split YAMLs in case we have multiple yaml files in a single file.
-	splittedSLOsData := splitYAML(slxData)
+	for _, input := range inputs {
+		for i, data := range input.specs {
+			progress.Step(fmt.Sprintf("%s#%d", input.source, i))
 
-	for _, data := range splittedSLOsData {
-		// Try loading spec with all the generators possible:
-		// 1 - Raw Prometheus generator.
-		slos, promErr := promYAMLLoader.LoadSpec(ctx, []byte(data))
-		if promErr == nil {
-			err := generatePrometheus(ctx, config.Logger, g.disableRecordings, g.disableAlerts, g.extraLabels, *slos, out)
+			spec, err := pipeline.loadSpec(ctx, config.Logger, data)
 			if err != nil {
-				return fmt.Errorf("could not generate Prometheus format rules: %w", err)
+				return err
 			}
-			continue
+			slos := spec.sloGroup.SLOs
+			sloOuts, err := g.splitSLOsByOut(outs, outFileTpl, input, slos)
+			if err != nil {
+				return err
+			}
+
+			if spec.k8sMeta != nil {
+				// The Kubernetes rules are a single object per spec, they can't be split.
+				if len(sloOuts) > 1 {
+					return fmt.Errorf("the SLOs of the %q Kubernetes spec can't be generated on multiple out files", spec.k8sMeta.Name)
+				}
+				result, err := generateSpec(ctx, config.Logger, pipeline.opts, *spec, sloOuts[0].out)
+				if err != nil {
+					return err
+				}
+				allResults = append(allResults, result.PrometheusSLOs...)
+			} else {
+				for _, so := range sloOuts {
+					result, err := generatePrometheus(ctx, config.Logger, pipeline.opts, prometheus.SLOGroup{SLOs: so.slos}, so.out)
+					if err != nil {
+						return fmt.Errorf("could not generate Prometheus format rules: %w", err)
+					}
+					allResults = append(allResults, result.PrometheusSLOs...)
+				}
+			}
+
+			allSLOs = append(allSLOs, slos...)
+			addSLOSources(sloSources, input.source, slos)
+			err = recordSpecUsage(usageRecorder, spec.format, data, slos)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = outs.Flush()
+	if err != nil {
+		return err
+	}
+
+	// Generate Alertmanager inhibition rules if required.
+	if g.inhibitRulesOut != "" {
+		if g.disableAlerts {
+			return fmt.Errorf("alertmanager inhibit rules can't be generated with the alerts disabled")
+		}
+
+		err := generateInhibitRules(ctx, config.Logger, allSLOs, g.inhibitRulesOut)
+		if err != nil {
+			return fmt.Errorf("could not generate Alertmanager inhibit rules: %w", err)
+		}
+	}
+
+	// Generate Alertmanager time intervals if required.
+	if g.timeIntervalsOut != "" {
+		if g.disableAlerts {
+			return fmt.Errorf("alertmanager time intervals can't be generated with the alerts disabled")
+		}
+
+		err := generateTimeIntervals(ctx, config.Logger, allSLOs, g.timeIntervalsOut)
+		if err != nil {
+			return fmt.Errorf("could not generate Alertmanager time intervals: %w", err)
+		}
+	}
+
+	// Generate alerts on the alerts backends if required.
+	if len(alertsBackends) > 0 {
+		results, err := generateAlertsBackends(ctx, config.Logger, g.extraLabels, pipeline.opts.alertForJitter, allSLOs, alertsBackends)
+		if err != nil {
+			return fmt.Errorf("could not generate alerts backends: %w", err)
+		}
+		allResults = append(allResults, results...)
+	}
+
+	// Generate rules index if required.
+	if g.indexOut != "" {
+		err := generateRulesIndex(ctx, config.Logger, g.slosInput, sloSources, allResults, g.indexOut)
+		if err != nil {
+			return fmt.Errorf("could not generate rules index: %w", err)
+		}
+	}
+
+	// Append usage report if required.
+	if usageRecorder != nil {
+		err := appendUsageReport(config.Logger, usageRecorder.Report(time.Now()), g.usageReportOut)
+		if err != nil {
+			return fmt.Errorf("could not append usage report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addDefaultAnnotations adds the default annotations to the SLOs, the SLO spec annotations
+// have preference over them.
+func addDefaultAnnotations(slos []prometheus.SLO, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+
+	for i, slo := range slos {
+		merged := make(map[string]string, len(annotations)+len(slo.Annotations))
+		for k, v := range annotations {
+			merged[k] = v
+		}
+		for k, v := range slo.Annotations {
+			merged[k] = v
+		}
+		slos[i].Annotations = merged
+	}
+}
+
+// useExistingSLIRecordings sets the already existing SLI recording rules as the SLI of the
+// SLOs that don't have an SLI.
+func useExistingSLIRecordings(slos []prometheus.SLO) {
+	for i, slo := range slos {
+		if slo.SLI.Raw == nil && slo.SLI.Events == nil {
+			slos[i].SLI = slo.GetExistingSLIRecordingsSLI()
+		}
+	}
+}
+
+// specLoader loads the SLO specs of a spec format.
+type specLoader struct {
+	format string
+	// name is the spec format name used on the load errors.
+	name string
+	load func(ctx context.Context, data []byte) (*loadedSpec, error)
+}
+
+// loadedSpec is a loaded SLO spec, the Kubernetes specs have the Kubernetes metadata.
+type loadedSpec struct {
+	format   string
+	sloGroup prometheus.SLOGroup
+	k8sMeta  *k8sprometheus.K8sMeta
+}
+
+// newSpecLoaders returns the spec loaders of all the supported spec formats in loading
+// order, or only the forced input format spec loader.
+func newSpecLoaders(inputFormat string, promYAMLLoader prometheus.YAMLSpecLoader, kubeYAMLLoader k8sprometheus.YAMLSpecLoader, openSLOYAMLLoader openslo.YAMLSpecLoader) ([]specLoader, error) {
+	loaders := []specLoader{
+		{
+			format: inputFormatPrometheusV1,
+			name:   "raw prometheus",
+			load: func(ctx context.Context, data []byte) (*loadedSpec, error) {
+				slos, err := promYAMLLoader.LoadSpec(ctx, data)
+				if err != nil {
+					return nil, err
+				}
+				return &loadedSpec{format: inputFormatPrometheusV1, sloGroup: *slos}, nil
+			},
+		},
+		{
+			format: inputFormatK8sV1,
+			name:   "Kubernetes prometheus",
+			load: func(ctx context.Context, data []byte) (*loadedSpec, error) {
+				sloGroup, err := kubeYAMLLoader.LoadSpec(ctx, data)
+				if err != nil {
+					return nil, err
+				}
+				return &loadedSpec{format: inputFormatK8sV1, sloGroup: sloGroup.SLOGroup, k8sMeta: &sloGroup.K8sMeta}, nil
+			},
+		},
+		{
+			format: inputFormatOpenSLOV1,
+			name:   "OpenSLO",
+			load: func(ctx context.Context, data []byte) (*loadedSpec, error) {
+				slos, err := openSLOYAMLLoader.LoadSpec(ctx, data)
+				if err != nil {
+					return nil, err
+				}
+				return &loadedSpec{format: inputFormatOpenSLOV1, sloGroup: *slos}, nil
+			},
+		},
+	}
+
+	if inputFormat == "" {
+		return loaders, nil
+	}
+
+	for _, l := range loaders {
+		if l.format == inputFormat {
+			return []specLoader{l}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown %q input format", inputFormat)
+}
+
+// specLoadErrors are the errors of every spec loader when a spec can't be loaded with any
+// of the supported spec types.
+type specLoadErrors []error
+
+func (s specLoadErrors) Error() string {
+	return "invalid spec, could not load with any of the supported spec types"
+}
+
+// loadSpec loads the SLO spec with the first spec loader that can load it, if none of them
+// can, it returns the specLoadErrors.
+func loadSpec(ctx context.Context, loaders []specLoader, data []byte) (*loadedSpec, error) {
+	errs := make(specLoadErrors, 0, len(loaders))
+	for _, l := range loaders {
+		spec, err := l.load(ctx, data)
+		if err == nil {
+			return spec, nil
+		}
+
+		// A forced spec format doesn't try the others.
+		if len(loaders) == 1 {
+			return nil, fmt.Errorf("could not load %s SLOs spec: %w", l.name, err)
+		}
+		errs = append(errs, fmt.Errorf("Tried loading %s SLOs spec, it couldn't: %w", l.name, err))
+	}
+
+	return nil, errs
+}
+
+// logSpecLoadErrors logs the errors of every spec loader if the spec couldn't be loaded with
+// any of the supported spec types.
+func logSpecLoadErrors(logger log.Logger, err error) {
+	var loadErrs specLoadErrors
+	if !errors.As(err, &loadErrs) {
+		return
+	}
+
+	for _, err := range loadErrs {
+		logger.Errorf("%s", err)
+	}
+}
+
+// generateSpec generates the rules of a loaded spec on the out, the Kubernetes specs are
+// generated as a single Kubernetes rules object.
+func generateSpec(ctx context.Context, logger log.Logger, opts generateOptions, spec loadedSpec, out io.Writer) (*generate.Response, error) {
+	if spec.k8sMeta != nil {
+		result, err := generateKubernetes(ctx, logger, opts, k8sprometheus.SLOGroup{K8sMeta: *spec.k8sMeta, SLOGroup: spec.sloGroup}, out)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate Kubernetes format rules: %w", err)
+		}
+		return result, nil
+	}
+
+	result, err := generatePrometheus(ctx, logger, opts, spec.sloGroup, out)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate Prometheus format rules: %w", err)
+	}
+
+	return result, nil
+}
+
+// generateInput is an SLO spec input of the generate command.
+type generateInput struct {
+	// source is the input file path, `-` for stdin. The archive input files path is
+	// the archive path joined with the file path inside the archive.
+	source string
+	// relPath is the input file path relative to the input directory, used as
+	// the path of the input rules on the out directory.
+	relPath string
+	// specs are the input SLO specs, a file can have multiple YAML specs.
+	specs [][]byte
+}
+
+// loadInputs loads the SLO spec inputs from stdin, a file or discovering the files
+// of a directory or an archive, with the spec templates rendered.
+func (g generateCommand) loadInputs(config RootConfig) ([]generateInput, error) {
+	tplData := newSpecTemplateData(g.vars)
+	if g.slosInput == "-" {
+		data, err := io.ReadAll(config.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("could not read SLOs spec stdin data: %w", err)
+		}
+
+		data, err = specloader.RenderTemplates(data, tplData)
+		if err != nil {
+			return nil, fmt.Errorf("could not render SLOs spec templates: %w", err)
+		}
+
+		specs, err := specloader.ReadAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("could not split SLOs spec file data: %w", err)
+		}
+
+		return []generateInput{{source: g.slosInput, relPath: g.slosInput, specs: specs}}, nil
+	}
+
+	stat, err := os.Stat(g.slosInput)
+	if err != nil {
+		return nil, fmt.Errorf("could not open SLOs spec file: %w", err)
+	}
+
+	paths := []string{g.slosInput}
+	baseDir := filepath.Dir(g.slosInput)
+	readFile := os.ReadFile
+	switch {
+	case stat.IsDir():
+		if g.slosOutDir != "" && filepath.Clean(g.slosOutDir) == filepath.Clean(g.slosInput) {
+			return nil, fmt.Errorf("out dir can't be the input directory")
+		}
+
+		excludeRegex, includeRegex, err := compileDiscoveryRegexes(g.slosExcludeRegex, g.slosIncludeRegex)
+		if err != nil {
+			return nil, err
+		}
+
+		paths, err = discoverSLOManifests(config.Logger, excludeRegex, includeRegex, g.slosInput)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("0 slo specs have been discovered")
+		}
+		baseDir = g.slosInput
+	case specloader.IsArchive(g.slosInput):
+		excludeRegex, includeRegex, err := compileDiscoveryRegexes(g.slosExcludeRegex, g.slosIncludeRegex)
+		if err != nil {
+			return nil, err
+		}
+
+		var manifests archiveSLOManifests
+		paths, manifests, err = discoverArchiveSLOManifests(config.Logger, excludeRegex, includeRegex, g.slosInput)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("0 slo specs have been discovered")
+		}
+		baseDir = g.slosInput
+		readFile = manifests.ReadFile
+	}
+
+	inputs := make([]generateInput, 0, len(paths))
+	for _, path := range paths {
+		data, err := readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read SLOs spec file data: %w", err)
+		}
+
+		data, err = specloader.RenderTemplates(data, tplData)
+		if err != nil {
+			return nil, fmt.Errorf("could not render %q SLOs spec templates: %w", path, err)
 		}
 
-		// 2 - Kubernetes Prometheus operator generator.
-		sloGroup, k8sErr := kubeYAMLLoader.LoadSpec(ctx, []byte(data))
-		if k8sErr == nil {
-			err := generateKubernetes(ctx, config.Logger, g.disableRecordings, g.disableAlerts, g.extraLabels, *sloGroup, out)
+		specs, err := specloader.ReadAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("could not split %q SLOs spec file data: %w", path, err)
+		}
+
+		relPath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return nil, fmt.Errorf("could not get %q SLOs spec file relative path: %w", path, err)
+		}
+
+		inputs = append(inputs, generateInput{source: path, relPath: relPath, specs: specs})
+	}
+
+	return inputs, nil
+}
+
+// generatedOut is a generated rules output, the rules are buffered so the out files can be
+// stamped with their checksum when flushed (stdout is not stamped).
+type generatedOut struct {
+	bytes.Buffer
+	// path is the out file path, `-` for stdout.
+	path   string
+	stdout io.Writer
+}
+
+// newGeneratedOut returns a generated rules output, if refuse unmanaged is set, it will fail
+// when the out file exists and is not a Sloth generated output or it has been edited.
+func newGeneratedOut(stdout io.Writer, path string, refuseUnmanaged bool) (*generatedOut, error) {
+	if path != "-" && refuseUnmanaged {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not read %q out file: %w", path, err)
+		}
+
+		if len(data) > 0 {
+			err := prometheus.VerifyGeneratedStamp(data)
 			if err != nil {
-				return fmt.Errorf("could not generate Kubernetes format rules: %w", err)
+				return nil, fmt.Errorf("refusing to overwrite %q out file: %w", path, err)
 			}
+		}
+	}
+
+	return &generatedOut{path: path, stdout: stdout}, nil
+}
+
+// Flush writes the rules on the output.
+func (g *generatedOut) Flush() error {
+	if g.path == "-" {
+		_, err := g.stdout.Write(g.Bytes())
+		if err != nil {
+			return fmt.Errorf("could not write out: %w", err)
+		}
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(g.path), 0755)
+	if err != nil {
+		return fmt.Errorf("could not create out directory: %w", err)
+	}
+
+	err = os.WriteFile(g.path, prometheus.StampGenerated(g.Bytes()), 0666)
+	if err != nil {
+		return fmt.Errorf("could not write out file: %w", err)
+	}
+
+	return nil
+}
+
+// generatedOuts are the generated rules outputs by their path, the rules of multiple specs
+// can be generated on the same output.
+type generatedOuts struct {
+	stdout          io.Writer
+	refuseUnmanaged bool
+	outs            []*generatedOut
+	byPath          map[string]*generatedOut
+}
+
+func newGeneratedOuts(stdout io.Writer, refuseUnmanaged bool) *generatedOuts {
+	return &generatedOuts{stdout: stdout, refuseUnmanaged: refuseUnmanaged, byPath: map[string]*generatedOut{}}
+}
+
+// Get returns the output of the path, creating it if it doesn't exist.
+func (g *generatedOuts) Get(path string) (*generatedOut, error) {
+	if o, ok := g.byPath[path]; ok {
+		return o, nil
+	}
+
+	o, err := newGeneratedOut(g.stdout, path, g.refuseUnmanaged)
+	if err != nil {
+		return nil, err
+	}
+	g.outs = append(g.outs, o)
+	g.byPath[path] = o
+
+	return o, nil
+}
+
+// Flush writes the rules on all the outputs.
+func (g *generatedOuts) Flush() error {
+	for _, o := range g.outs {
+		err := o.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// outFileTemplateData is the data of the out file path template.
+type outFileTemplateData struct {
+	Source  string
+	Service string
+	SLOName string
+	SLOID   string
+}
+
+func (g generateCommand) parseOutFileTemplate() (*template.Template, error) {
+	if g.outFileTemplate == "" {
+		return nil, nil
+	}
+
+	if g.slosOutDir == "" {
+		return nil, fmt.Errorf("out file template can't be used without out dir")
+	}
+
+	tpl, err := template.New("outFile").Option("missingkey=error").Parse(g.outFileTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid out file template: %w", err)
+	}
+
+	return tpl, nil
+}
+
+// outSLOs are the SLOs of a spec that are generated on the same output.
+type outSLOs struct {
+	out  io.Writer
+	slos []prometheus.SLO
+}
+
+// splitSLOsByOut groups the SLOs of an input spec by their output, keeping the SLOs order.
+func (g generateCommand) splitSLOsByOut(outs *generatedOuts, outFileTpl *template.Template, input generateInput, slos []prometheus.SLO) ([]outSLOs, error) {
+	res := []outSLOs{}
+	idxs := map[string]int{}
+	for _, slo := range slos {
+		path, err := g.outPath(outFileTpl, input, slo)
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := idxs[path]; ok {
+			res[i].slos = append(res[i].slos, slo)
 			continue
 		}
 
-		// If we reached here means that we could not use any of the available spec types.
-		config.Logger.Errorf("Tried loading raw prometheus SLOs spec, it couldn't: %s", promErr)
-		config.Logger.Errorf("Tried loading Kubernetes prometheus SLOs spec, it couldn't: %s", k8sErr)
-		return fmt.Errorf("invalid spec, could not load with any of the supported spec types")
+		out, err := outs.Get(path)
+		if err != nil {
+			return nil, err
+		}
+		idxs[path] = len(res)
+		res = append(res, outSLOs{out: out, slos: []prometheus.SLO{slo}})
+	}
+
+	return res, nil
+}
+
+// outPath returns the out path of an SLO: the out file, the input relative path on the out
+// dir or the rendered out file template path on the out dir.
+func (g generateCommand) outPath(outFileTpl *template.Template, input generateInput, slo prometheus.SLO) (string, error) {
+	switch {
+	case g.slosOutDir == "":
+		return g.slosOut, nil
+	case outFileTpl == nil:
+		return filepath.Join(g.slosOutDir, input.relPath), nil
+	}
+
+	var b bytes.Buffer
+	err := outFileTpl.Execute(&b, outFileTemplateData{
+		Source:  input.relPath,
+		Service: slo.Service,
+		SLOName: slo.Name,
+		SLOID:   slo.ID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not render %q SLO out file template: %w", slo.ID, err)
+	}
+
+	// The rendered paths can't escape the out dir.
+	path := filepath.Clean(strings.TrimSpace(b.String()))
+	if path == "." || filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid %q SLO out file path %q, it must be a file path relative to the out dir", slo.ID, b.String())
+	}
+
+	return filepath.Join(g.slosOutDir, path), nil
+}
+
+// addSLOSources sets the spec source of the SLOs.
+func addSLOSources(sloSources map[string]string, source string, slos []prometheus.SLO) {
+	for _, slo := range slos {
+		sloSources[slo.ID] = source
+	}
+}
+
+// alertsBackend is a storage backend that evaluates the SLO alerts instead of Prometheus.
+type alertsBackend struct {
+	name      string
+	out       string
+	newStorer func(w io.Writer, logger log.Logger) (prometheus.SLOsStorer, error)
+}
+
+// alertsBackends returns the alerts backends enabled by the command flags.
+func (g generateCommand) alertsBackends() ([]alertsBackend, error) {
+	backends := []alertsBackend{}
+
+	if g.grafanaAlertRulesOut != "" {
+		if g.grafanaDatasourceUID == "" {
+			return nil, fmt.Errorf("grafana datasource UID is required to generate Grafana alert rules")
+		}
+
+		meta := prometheus.GrafanaAlertRulesMeta{
+			DatasourceUID: g.grafanaDatasourceUID,
+			Folder:        g.grafanaFolder,
+		}
+		backends = append(backends, alertsBackend{
+			name: "Grafana",
+			out:  g.grafanaAlertRulesOut,
+			newStorer: func(w io.Writer, logger log.Logger) (prometheus.SLOsStorer, error) {
+				return prometheus.NewIOWriterGrafanaAlertRulesYAMLRepo(w, meta, logger)
+			},
+		})
+	}
+
+	if g.newRelicConditionsOut != "" {
+		backends = append(backends, alertsBackend{
+			name: "New Relic",
+			out:  g.newRelicConditionsOut,
+			newStorer: func(w io.Writer, logger log.Logger) (prometheus.SLOsStorer, error) {
+				return newrelic.NewIOWriterNRQLConditionsJSONRepo(w, logger), nil
+			},
+		})
+	}
+
+	return backends, nil
+}
+
+// generateAlertsBackends generates the SLOs alerts and stores them on the alerts backends.
+func generateAlertsBackends(ctx context.Context, logger log.Logger, extraLabels map[string]string, alertForJitter prometheus.AlertForJitter, slos []prometheus.SLO, backends []alertsBackend) ([]generate.SLOResult, error) {
+	info := info.Info{
+		Version: info.Version,
+		Mode:    info.ModeCLIGenPrometheus,
+		Spec:    prometheusv1.Version,
+	}
+
+	// The recording rules are already generated for Prometheus, only the alerts are required.
+	result, err := generateRules(ctx, logger, info, generateOptions{disableRecordings: true, extraLabels: extraLabels, alertForJitter: alertForJitter}, prometheus.SLOGroup{SLOs: slos})
+	if err != nil {
+		return nil, err
+	}
+
+	storageSLOs := make([]prometheus.StorageSLO, 0, len(result.PrometheusSLOs))
+	for _, s := range result.PrometheusSLOs {
+		storageSLOs = append(storageSLOs, prometheus.StorageSLO{
+			SLO:    s.SLO,
+			Rules:  s.SLORules,
+			Alerts: s.Alerts,
+		})
+	}
+
+	for _, backend := range backends {
+		logger.Infof("Generating %s alerts", backend.name)
+
+		err := storeAlertsBackend(ctx, logger, backend, storageSLOs)
+		if err != nil {
+			return nil, fmt.Errorf("could not store %s alerts: %w", backend.name, err)
+		}
+	}
+
+	return result.PrometheusSLOs, nil
+}
+
+func storeAlertsBackend(ctx context.Context, logger log.Logger, backend alertsBackend, slos []prometheus.StorageSLO) error {
+	f, err := os.Create(backend.out)
+	if err != nil {
+		return fmt.Errorf("could not create out file: %w", err)
+	}
+	defer f.Close()
+
+	storer, err := backend.newStorer(f, logger)
+	if err != nil {
+		return fmt.Errorf("could not create storage: %w", err)
+	}
+
+	return storer.StoreSLOs(ctx, slos)
+}
+
+// generateRulesIndex generates the reverse lookup index of the generated rules and stores it
+// as JSON on the path.
+func generateRulesIndex(ctx context.Context, logger log.Logger, source string, sloSources map[string]string, results []generate.SLOResult, path string) error {
+	logger.Infof("Generating rules index")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create rules index out file: %w", err)
+	}
+	defer f.Close()
+
+	storageSLOs := make([]prometheus.StorageSLO, 0, len(results))
+	for _, r := range results {
+		storageSLOs = append(storageSLOs, prometheus.StorageSLO{
+			SLO:    r.SLO,
+			Rules:  r.SLORules,
+			Alerts: r.Alerts,
+			Source: sloSources[r.SLO.ID],
+		})
+	}
+
+	repo := prometheus.NewIOWriterRulesIndexJSONRepo(f, logger)
+	err = repo.StoreRulesIndex(ctx, source, storageSLOs)
+	if err != nil {
+		return fmt.Errorf("could not store rules index: %w", err)
 	}
 
 	return nil
 }
 
+// generateInhibitRules generates the Alertmanager inhibition rules of the SLOs and stores them
+// as an Alertmanager configuration fragment on the path.
+func generateInhibitRules(ctx context.Context, logger log.Logger, slos []prometheus.SLO, path string) error {
+	logger.Infof("Generating Alertmanager inhibit rules")
+
+	unknownDeps := prometheus.UnknownDependencies(slos)
+	ids := make([]string, 0, len(unknownDeps))
+	for id := range unknownDeps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		logger.WithValues(log.Kv{"slo": id, "depends-on": strings.Join(unknownDeps[id], ",")}).Warningf("SLO depends on unknown SLOs")
+	}
+
+	rules, err := prometheus.InhibitRulesGenerator.GenerateInhibitRules(ctx, slos)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create inhibit rules out file: %w", err)
+	}
+	defer f.Close()
+
+	repo := prometheus.NewIOWriterInhibitRulesYAMLRepo(f, logger)
+	err = repo.StoreInhibitRules(ctx, rules)
+	if err != nil {
+		return fmt.Errorf("could not store inhibit rules: %w", err)
+	}
+
+	return nil
+}
+
+// generateTimeIntervals generates the Alertmanager time intervals and the mute time intervals of the SLOs
+// maintenance windows and stores them as an Alertmanager configuration fragment on the path.
+func generateTimeIntervals(ctx context.Context, logger log.Logger, slos []prometheus.SLO, path string) error {
+	logger.Infof("Generating Alertmanager time intervals")
+
+	intervals, err := prometheus.TimeIntervalsGenerator.GenerateTimeIntervals(ctx, slos)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create time intervals out file: %w", err)
+	}
+	defer f.Close()
+
+	repo := prometheus.NewIOWriterTimeIntervalsYAMLRepo(f, logger)
+	err = repo.StoreTimeIntervals(ctx, *intervals)
+	if err != nil {
+		return fmt.Errorf("could not store time intervals: %w", err)
+	}
+
+	return nil
+}
+
+// generateOptions are the rules generation options shared by the commands that generate rules.
+type generateOptions struct {
+	disableRecordings   bool
+	disableAlerts       bool
+	minimal             bool
+	sliZeroTotalGuard   bool
+	errorBudgetForecast bool
+	extraLabels         map[string]string
+	alertForJitter      prometheus.AlertForJitter
+	// ruleGroupsMeta is the rule groups metadata of the Prometheus rules.
+	ruleGroupsMeta prometheus.RuleGroupsMeta
+	// k8sRuleMeta is the rule objects metadata of the Kubernetes rules.
+	k8sRuleMeta k8sprometheus.PrometheusRuleMeta
+	// existingRules are the existing rules checked for collisions, if nil it will not check them.
+	existingRules *prometheus.ExistingRules
+}
+
 // generatePrometheus generates the SLOs based on a raw regular Prometheus spec format input and
 // outs a Prometheus raw yaml.
-func generatePrometheus(ctx context.Context, logger log.Logger, disableRecs, disableAlerts bool, extraLabels map[string]string, slos prometheus.SLOGroup, out io.Writer) error {
+func generatePrometheus(ctx context.Context, logger log.Logger, opts generateOptions, slos prometheus.SLOGroup, out io.Writer) (*generate.Response, error) {
 	logger.Infof("Generating from Prometheus spec")
 	info := info.Info{
 		Version: info.Version,
@@ -125,31 +1075,37 @@ func generatePrometheus(ctx context.Context, logger log.Logger, disableRecs, dis
 		Spec:    prometheusv1.Version,
 	}
 
-	result, err := generateRules(ctx, logger, info, disableRecs, disableAlerts, extraLabels, slos)
+	result, err := generateRules(ctx, logger, info, opts, slos)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	repo := prometheus.NewIOWriterGroupedRulesYAMLRepo(out, logger)
+	repo := prometheus.NewIOWriterGroupedRulesYAMLRepo(out, opts.ruleGroupsMeta, logger)
 	storageSLOs := make([]prometheus.StorageSLO, 0, len(result.PrometheusSLOs))
 	for _, s := range result.PrometheusSLOs {
 		storageSLOs = append(storageSLOs, prometheus.StorageSLO{
-			SLO:   s.SLO,
-			Rules: s.SLORules,
+			SLO:    s.SLO,
+			Rules:  s.SLORules,
+			Alerts: s.Alerts,
 		})
 	}
 
+	err = opts.existingRules.Check(storageSLOs)
+	if err != nil {
+		return nil, err
+	}
+
 	err = repo.StoreSLOs(ctx, storageSLOs)
 	if err != nil {
-		return fmt.Errorf("could not store SLOS: %w", err)
+		return nil, fmt.Errorf("could not store SLOS: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
 
 // generateKubernetes generates the SLOs based on a Kuberentes spec format input and
 // outs a Kubernetes prometheus operator CRD yaml.
-func generateKubernetes(ctx context.Context, logger log.Logger, disableRecs, disableAlerts bool, extraLabels map[string]string, sloGroup k8sprometheus.SLOGroup, out io.Writer) error {
+func generateKubernetes(ctx context.Context, logger log.Logger, opts generateOptions, sloGroup k8sprometheus.SLOGroup, out io.Writer) (*generate.Response, error) {
 	logger.Infof("Generating from Kubernetes Prometheus spec")
 
 	info := info.Info{
@@ -157,12 +1113,21 @@ func generateKubernetes(ctx context.Context, logger log.Logger, disableRecs, dis
 		Mode:    info.ModeCLIGenKubernetes,
 		Spec:    fmt.Sprintf("%s/%s", kubernetesv1.SchemeGroupVersion.Group, kubernetesv1.SchemeGroupVersion.Version),
 	}
-	result, err := generateRules(ctx, logger, info, disableRecs, disableAlerts, extraLabels, sloGroup.SLOGroup)
+	result, err := generateRules(ctx, logger, info, opts, sloGroup.SLOGroup)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	repo := k8sprometheus.NewIOWriterPrometheusOperatorYAMLRepo(out, logger)
+	checkSLOs := make([]prometheus.StorageSLO, 0, len(result.PrometheusSLOs))
+	for _, s := range result.PrometheusSLOs {
+		checkSLOs = append(checkSLOs, prometheus.StorageSLO{SLO: s.SLO, Rules: s.SLORules})
+	}
+	err = opts.existingRules.Check(checkSLOs)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := k8sprometheus.NewIOWriterPrometheusOperatorYAMLRepo(out, opts.k8sRuleMeta, logger)
 	storageSLOs := make([]k8sprometheus.StorageSLO, 0, len(result.PrometheusSLOs))
 	for _, s := range result.PrometheusSLOs {
 		storageSLOs = append(storageSLOs, k8sprometheus.StorageSLO{
@@ -173,27 +1138,39 @@ func generateKubernetes(ctx context.Context, logger log.Logger, disableRecs, dis
 
 	err = repo.StoreSLOs(ctx, sloGroup.K8sMeta, storageSLOs)
 	if err != nil {
-		return fmt.Errorf("could not store SLOS: %w", err)
+		return nil, fmt.Errorf("could not store SLOS: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
 
 // generate is the main generator logic that all the spec types and storers share. Mainly
 // has the logic of the generate app service.
-func generateRules(ctx context.Context, logger log.Logger, info info.Info, disableRecs, disableAlerts bool, extraLabels map[string]string, slos prometheus.SLOGroup) (*generate.Response, error) {
+func generateRules(ctx context.Context, logger log.Logger, info info.Info, opts generateOptions, slos prometheus.SLOGroup) (*generate.Response, error) {
 	// Disable recording rules if required.
 	var sliRuleGen generate.SLIRecordingRulesGenerator = generate.NoopSLIRecordingRulesGenerator
 	var metaRuleGen generate.MetadataRecordingRulesGenerator = generate.NoopMetadataRecordingRulesGenerator
-	if !disableRecs {
-		sliRuleGen = prometheus.SLIRecordingRulesGenerator
-		metaRuleGen = prometheus.MetadataRecordingRulesGenerator
+	sliRecordingRulesGen := prometheus.SLIRecordingRulesGenerator
+	if opts.sliZeroTotalGuard {
+		sliRecordingRulesGen = sliRecordingRulesGen.WithZeroTotalGuard()
+	}
+	metaRecordingRulesGen := prometheus.MetadataRecordingRulesGenerator
+	if opts.errorBudgetForecast {
+		metaRecordingRulesGen = metaRecordingRulesGen.WithErrorBudgetForecast()
+	}
+	switch {
+	case !opts.disableRecordings && opts.minimal:
+		// Only the recording rules required by the alerts.
+		sliRuleGen = sliRecordingRulesGen.WithAlertWindowsOnly()
+	case !opts.disableRecordings:
+		sliRuleGen = sliRecordingRulesGen
+		metaRuleGen = metaRecordingRulesGen
 	}
 
 	// Disable alert rules if required.
 	var alertRuleGen generate.SLOAlertRulesGenerator = generate.NoopSLOAlertRulesGenerator
-	if !disableAlerts {
-		alertRuleGen = prometheus.SLOAlertRulesGenerator
+	if !opts.disableAlerts {
+		alertRuleGen = prometheus.SLOAlertRulesGenerator.WithForJitter(opts.alertForJitter)
 	}
 
 	// Generate.
@@ -209,7 +1186,7 @@ func generateRules(ctx context.Context, logger log.Logger, info info.Info, disab
 	}
 
 	result, err := controller.Generate(ctx, generate.Request{
-		ExtraLabels: extraLabels,
+		ExtraLabels: opts.extraLabels,
 		Info:        info,
 		SLOGroup:    slos,
 	})
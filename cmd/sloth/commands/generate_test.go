@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+const testGeneratePrometheusSpec = `
+version: "prometheus/v1"
+service: "myservice"
+slos:
+  - name: "requests-availability"
+    objective: 99.9
+    sli:
+      events:
+        error_query: sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))
+        total_query: sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`
+
+const testGenerateK8sSpec = `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: sloth-slo-my-service
+  namespace: monitoring
+spec:
+  service: "myservice"
+  slos:
+    - name: "requests-availability"
+      objective: 99.9
+      sli:
+        events:
+          errorQuery: sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))
+          totalQuery: sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))
+      alerting:
+        pageAlert:
+          disable: true
+        ticketAlert:
+          disable: true
+`
+
+const testGenerateOpenSLOSpec = `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: requests-availability
+spec:
+  service: myservice
+  indicator:
+    spec:
+      ratioMetric:
+        bad:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))'}}
+        total:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total[{{.window}}]))'}}
+  timeWindow:
+    - duration: 30d
+      isRolling: true
+  objectives:
+    - target: 0.999
+`
+
+func TestGenerateOutPath(t *testing.T) {
+	input := generateInput{source: "slos/team-a/api.yaml", relPath: "team-a/api.yaml"}
+	slo := prometheus.SLO{ID: "api-availability", Service: "api", Name: "availability"}
+
+	tests := map[string]struct {
+		cmd     generateCommand
+		expPath string
+		expErr  bool
+	}{
+		"Without out dir, the out file should be used.": {
+			cmd:     generateCommand{slosOut: "rules.yaml"},
+			expPath: "rules.yaml",
+		},
+
+		"Without out file template, the input relative path on the out dir should be used.": {
+			cmd:     generateCommand{slosOutDir: "rules"},
+			expPath: filepath.Join("rules", "team-a", "api.yaml"),
+		},
+
+		"An out file template should be rendered on the out dir.": {
+			cmd:     generateCommand{slosOutDir: "rules", outFileTemplate: "{{ .Service }}/{{ .SLOName }}.yaml"},
+			expPath: filepath.Join("rules", "api", "availability.yaml"),
+		},
+
+		"An out file template with the source and the SLO ID should be rendered on the out dir.": {
+			cmd:     generateCommand{slosOutDir: "rules", outFileTemplate: "{{ .Source }}/{{ .SLOID }}.yaml"},
+			expPath: filepath.Join("rules", "team-a", "api.yaml", "api-availability.yaml"),
+		},
+
+		"An out file template path inside the out dir after cleaning it should be rendered on the out dir.": {
+			cmd:     generateCommand{slosOutDir: "rules", outFileTemplate: "{{ .Service }}/../{{ .SLOID }}.yaml"},
+			expPath: filepath.Join("rules", "api-availability.yaml"),
+		},
+
+		"An out file template without out dir should fail.": {
+			cmd:    generateCommand{slosOut: "rules.yaml", outFileTemplate: "{{ .Service }}.yaml"},
+			expErr: true,
+		},
+
+		"An invalid out file template should fail.": {
+			cmd:    generateCommand{slosOutDir: "rules", outFileTemplate: "{{ .Service }.yaml"},
+			expErr: true,
+		},
+
+		"An out file template with a missing key should fail.": {
+			cmd:    generateCommand{slosOutDir: "rules", outFileTemplate: "{{ .Team }}.yaml"},
+			expErr: true,
+		},
+
+		"An out file template path outside the out dir should fail.": {
+			cmd:    generateCommand{slosOutDir: "rules", outFileTemplate: "../{{ .Service }}.yaml"},
+			expErr: true,
+		},
+
+		"An out file template path that escapes the out dir after cleaning it should fail.": {
+			cmd:    generateCommand{slosOutDir: "rules", outFileTemplate: "{{ .Service }}/../../{{ .SLOID }}.yaml"},
+			expErr: true,
+		},
+
+		"An out file template absolute path should fail.": {
+			cmd:    generateCommand{slosOutDir: "rules", outFileTemplate: "/tmp/{{ .Service }}.yaml"},
+			expErr: true,
+		},
+
+		"An out file template that renders an empty path should fail.": {
+			cmd:    generateCommand{slosOutDir: "rules", outFileTemplate: "{{ if false }}{{ .Service }}{{ end }}"},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			tpl, err := test.cmd.parseOutFileTemplate()
+			var gotPath string
+			if err == nil {
+				gotPath, err = test.cmd.outPath(tpl, input, slo)
+			}
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expPath, gotPath)
+			}
+		})
+	}
+}
+
+func TestGenerateSplitSLOsByOut(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	input := generateInput{source: "slos/team-a/api.yaml", relPath: "team-a/api.yaml"}
+	slos := []prometheus.SLO{
+		{ID: "api-availability", Service: "api", Name: "availability"},
+		{ID: "web-latency", Service: "web", Name: "latency"},
+		{ID: "api-latency", Service: "api", Name: "latency"},
+	}
+	cmd := generateCommand{slosOutDir: "rules", outFileTemplate: "{{ .Service }}.yaml"}
+	tpl, err := cmd.parseOutFileTemplate()
+	require.NoError(err)
+
+	outs := newGeneratedOuts(&bytes.Buffer{}, false)
+	gotOuts, err := cmd.splitSLOsByOut(outs, tpl, input, slos)
+	require.NoError(err)
+
+	// The SLOs rendered to the same path should share the output, keeping their order.
+	apiOut, err := outs.Get(filepath.Join("rules", "api.yaml"))
+	require.NoError(err)
+	webOut, err := outs.Get(filepath.Join("rules", "web.yaml"))
+	require.NoError(err)
+	if assert.Len(gotOuts, 2) {
+		assert.Same(apiOut, gotOuts[0].out)
+		assert.Equal([]prometheus.SLO{slos[0], slos[2]}, gotOuts[0].slos)
+		assert.Same(webOut, gotOuts[1].out)
+		assert.Equal([]prometheus.SLO{slos[1]}, gotOuts[1].slos)
+	}
+	assert.Len(outs.outs, 2)
+}
+
+func TestGenerateSpecLoaders(t *testing.T) {
+	tests := map[string]struct {
+		inputFormat string
+		spec        string
+		expFormat   string
+		expErr      bool
+		expLoadErr  bool
+	}{
+		"Without input format, a Prometheus spec should be loaded.": {
+			spec:      testGeneratePrometheusSpec,
+			expFormat: inputFormatPrometheusV1,
+		},
+
+		"Without input format, a Kubernetes spec should be loaded.": {
+			spec:      testGenerateK8sSpec,
+			expFormat: inputFormatK8sV1,
+		},
+
+		"Without input format, an OpenSLO spec should be loaded.": {
+			spec:      testGenerateOpenSLOSpec,
+			expFormat: inputFormatOpenSLOV1,
+		},
+
+		"Forcing the Prometheus input format, a Prometheus spec should be loaded.": {
+			inputFormat: inputFormatPrometheusV1,
+			spec:        testGeneratePrometheusSpec,
+			expFormat:   inputFormatPrometheusV1,
+		},
+
+		"Forcing the Kubernetes input format, a Kubernetes spec should be loaded.": {
+			inputFormat: inputFormatK8sV1,
+			spec:        testGenerateK8sSpec,
+			expFormat:   inputFormatK8sV1,
+		},
+
+		"Forcing the OpenSLO input format, an OpenSLO spec should be loaded.": {
+			inputFormat: inputFormatOpenSLOV1,
+			spec:        testGenerateOpenSLOSpec,
+			expFormat:   inputFormatOpenSLOV1,
+		},
+
+		"Forcing the Prometheus input format, a Kubernetes spec should fail.": {
+			inputFormat: inputFormatPrometheusV1,
+			spec:        testGenerateK8sSpec,
+			expLoadErr:  true,
+		},
+
+		"Forcing the Kubernetes input format, an OpenSLO spec should fail.": {
+			inputFormat: inputFormatK8sV1,
+			spec:        testGenerateOpenSLOSpec,
+			expLoadErr:  true,
+		},
+
+		"Forcing the OpenSLO input format, a Prometheus spec should fail.": {
+			inputFormat: inputFormatOpenSLOV1,
+			spec:        testGeneratePrometheusSpec,
+			expLoadErr:  true,
+		},
+
+		"An unknown input format should fail.": {
+			inputFormat: "unknown/v1",
+			spec:        testGeneratePrometheusSpec,
+			expErr:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			flags := newSpecLoadFlags()
+			flags.defaultSLOPeriod = "30d"
+			loaders, err := flags.specLoaders(log.Noop, nil, test.inputFormat, false)
+			if test.expErr {
+				assert.EqualError(err, `unknown "unknown/v1" input format`)
+				return
+			}
+			require.NoError(err)
+
+			spec, err := loadSpec(context.TODO(), loaders, []byte(test.spec))
+			if test.expLoadErr {
+				assert.Error(err)
+				return
+			}
+			if assert.NoError(err) {
+				assert.Equal(test.expFormat, spec.format)
+				assert.Equal("myservice", spec.sloGroup.SLOs[0].Service)
+			}
+		})
+	}
+}
+
+func TestGenerateLoadInputsStdin(t *testing.T) {
+	tests := map[string]struct {
+		stdin     string
+		vars      map[string]string
+		expLoaded []string
+		expErr    bool
+	}{
+		"A single spec from stdin should be loaded.": {
+			stdin:     testGeneratePrometheusSpec,
+			expLoaded: []string{inputFormatPrometheusV1},
+		},
+
+		"A multi document spec from stdin should load every spec.": {
+			stdin:     testGeneratePrometheusSpec + "---\n" + testGenerateK8sSpec + "---\n" + testGenerateOpenSLOSpec,
+			expLoaded: []string{inputFormatPrometheusV1, inputFormatK8sV1, inputFormatOpenSLOV1},
+		},
+
+		"A multi document spec from stdin with empty documents should ignore them.": {
+			stdin:     "---\n" + testGeneratePrometheusSpec + "---\n# Nothing.\n---\n" + testGenerateOpenSLOSpec + "---\n",
+			expLoaded: []string{inputFormatPrometheusV1, inputFormatOpenSLOV1},
+		},
+
+		"A spec from stdin should have the spec templates rendered.": {
+			stdin:     strings.ReplaceAll(testGeneratePrometheusSpec, `service: "myservice"`, `service: "{{ .Vars.service }}"`),
+			vars:      map[string]string{"service": "myservice"},
+			expLoaded: []string{inputFormatPrometheusV1},
+		},
+
+		"A spec from stdin with missing template variables should fail.": {
+			stdin:  strings.ReplaceAll(testGeneratePrometheusSpec, `service: "myservice"`, `service: "{{ .Vars.service }}"`),
+			expErr: true,
+		},
+
+		"An invalid YAML from stdin should fail.": {
+			stdin:  "service: [myservice\n",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			cmd := generateCommand{slosInput: "-"}
+			cmd.vars = test.vars
+			inputs, err := cmd.loadInputs(RootConfig{Logger: log.Noop, Stdin: strings.NewReader(test.stdin)})
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+			require.Len(inputs, 1)
+			assert.Equal("-", inputs[0].source)
+
+			flags := newSpecLoadFlags()
+			flags.defaultSLOPeriod = "30d"
+			loaders, err := flags.specLoaders(log.Noop, nil, "", false)
+			require.NoError(err)
+			gotLoaded := []string{}
+			for _, spec := range inputs[0].specs {
+				loaded, err := loadSpec(context.TODO(), loaders, spec)
+				require.NoError(err)
+				gotLoaded = append(gotLoaded, loaded.format)
+			}
+			assert.Equal(test.expLoaded, gotLoaded)
+		})
+	}
+}
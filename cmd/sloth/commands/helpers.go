@@ -4,44 +4,109 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
+	"net/http"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/k8sprometheus"
 	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/openslo"
+	"github.com/slok/sloth/internal/pluginsource"
 	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/pkg/specloader"
 )
 
-var (
-	splitMarkRe  = regexp.MustCompile("(?m)^---")
-	rmCommentsRe = regexp.MustCompile("(?m)^#.*$")
+const (
+	targetPlatformPrometheus = "prometheus"
+	targetPlatformThanos     = "thanos"
+
+	thanosPartialResponseAbort = "abort"
+	thanosPartialResponseWarn  = "warn"
 )
 
-func splitYAML(data []byte) []string {
-	// Santize.
-	data = bytes.TrimSpace(data)
-	data = rmCommentsRe.ReplaceAll(data, []byte(""))
+// partialResponseStrategyFor returns the rule groups partial response strategy of the
+// target platform, only Thanos ruler supports it.
+func partialResponseStrategyFor(targetPlatform, strategy string) string {
+	if targetPlatform != targetPlatformThanos {
+		return ""
+	}
+
+	return strategy
+}
+
+// ruleGroupIntervalsFor returns the rule group intervals of the rule group interval flags,
+// the rule groups without their own interval use the default interval.
+func ruleGroupIntervalsFor(defaultInterval, sliRecordings, metaRecordings, alerts time.Duration) prometheus.RuleGroupIntervals {
+	intervals := prometheus.RuleGroupIntervals{SLIRecordings: sliRecordings, MetaRecordings: metaRecordings, Alerts: alerts}
+	return intervals.WithDefaults(prometheus.RuleGroupIntervals{SLIRecordings: defaultInterval, MetaRecordings: defaultInterval, Alerts: defaultInterval})
+}
+
+// sliWindowGroupsFor returns the SLI window groups of the SLI window rule groups flags, the
+// window intervals are in 'window=interval' form (e.g '30d=5m').
+func sliWindowGroupsFor(enabled bool, windowIntervals map[string]string) (prometheus.SLIWindowGroups, error) {
+	if !enabled {
+		if len(windowIntervals) > 0 {
+			return prometheus.SLIWindowGroups{}, fmt.Errorf("SLI window rule group intervals require the SLI window rule groups")
+		}
+		return prometheus.SLIWindowGroups{}, nil
+	}
 
-	// Split (YAML can declare multiple files in the same file using `---`).
-	dataSplit := splitMarkRe.Split(string(data), -1)
+	intervals := map[time.Duration]time.Duration{}
+	for w, i := range windowIntervals {
+		window, err := prometheus.ParseDuration(w)
+		if err != nil {
+			return prometheus.SLIWindowGroups{}, fmt.Errorf("invalid %q SLI window: %w", w, err)
+		}
 
-	// Remove empty splits.
-	nonEmptyData := []string{}
-	for _, d := range dataSplit {
-		d = strings.TrimSpace(d)
-		if d != "" {
-			nonEmptyData = append(nonEmptyData, d)
+		interval, err := prometheus.ParseDuration(i)
+		if err != nil {
+			return prometheus.SLIWindowGroups{}, fmt.Errorf("invalid %q SLI window rule group interval %q: %w", w, i, err)
 		}
+		intervals[window] = interval
 	}
 
-	return nonEmptyData
+	return prometheus.SLIWindowGroups{Enabled: true, Intervals: intervals}, nil
+}
+
+// sliPluginFlags are the SLI plugins flags, shared by all the commands that load the SLI plugins.
+type sliPluginFlags struct {
+	sliPluginsPaths          []string
+	sliPluginsTimeout        time.Duration
+	sliPluginsAllowedImports []string
+	sliPluginsAllowExec      bool
 }
 
-func createPluginLoader(ctx context.Context, logger log.Logger, paths []string) (*prometheus.FileSLIPluginRepo, error) {
+// register registers the SLI plugins flags on the command.
+func (s *sliPluginFlags) register(cmd *kingpin.CmdClause) {
+	cmd.Flag("sli-plugins-path", "The path to SLI plugins (can be repeated), a local path or a remote source (git::<repo>, HTTP(S) tarball or oci://<artifact>), if not set it disable plugins support.").Short('p').StringsVar(&s.sliPluginsPaths)
+	cmd.Flag("sli-plugins-timeout", "The maximum duration of an SLI plugin load or execution, if 0 it will not have timeout.").Default("10s").DurationVar(&s.sliPluginsTimeout)
+	cmd.Flag("sli-plugins-allowed-imports", "Go standard library packages that SLI plugins can import (can be repeated), if not set it will use a safe default set.").StringsVar(&s.sliPluginsAllowedImports)
+	cmd.Flag("sli-plugins-allow-executables", "Enables the compiled SLI plugins (`sloth-sli-plugin` executables), these run outside the Go plugins sandbox.").BoolVar(&s.sliPluginsAllowExec)
+}
+
+// createPluginLoader loads the SLI plugins of the flags.
+func (s sliPluginFlags) createPluginLoader(ctx context.Context, logger log.Logger, httpClient *http.Client) (*prometheus.FileSLIPluginRepo, error) {
+	paths, err := s.pluginPaths(ctx, logger, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
 	config := prometheus.FileSLIPluginRepoConfig{
-		Paths:  paths,
-		Logger: logger,
+		Paths:                  paths,
+		PluginTimeout:          s.sliPluginsTimeout,
+		PluginAllowedImports:   s.sliPluginsAllowedImports,
+		PluginAllowExecutables: s.sliPluginsAllowExec,
+		Logger:                 logger,
 	}
 	sliPluginRepo, err := prometheus.NewFileSLIPluginRepo(config)
 	if err != nil {
@@ -51,6 +116,121 @@ func createPluginLoader(ctx context.Context, logger log.Logger, paths []string)
 	return sliPluginRepo, nil
 }
 
+// pluginPaths returns the local SLI plugins paths of the flags, the installed plugins if the
+// flags don't have plugin paths, and the remote plugin sources fetched into the local cache.
+func (s sliPluginFlags) pluginPaths(ctx context.Context, logger log.Logger, httpClient *http.Client) ([]string, error) {
+	paths := s.sliPluginsPaths
+
+	// Without plugin paths, use the installed plugins.
+	if len(paths) == 0 {
+		cacheDir, err := defaultSLIPluginsCacheDir()
+		if err == nil {
+			if _, err := os.Stat(cacheDir); err == nil {
+				logger.WithValues(log.Kv{"path": cacheDir}).Debugf("Using installed SLI plugins")
+				paths = []string{cacheDir}
+			}
+		}
+	}
+
+	// Fetch the remote plugin sources into the local cache.
+	return fetchRemoteSLIPlugins(ctx, logger, httpClient, paths)
+}
+
+// defaultSLIPluginsCacheDir returns the default SLI plugins cache directory, where the
+// plugins are installed.
+func defaultSLIPluginsCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "sloth", "plugins"), nil
+}
+
+// fetchRemoteSLIPlugins fetches the remote SLI plugin sources (git, HTTP(S) tarballs and OCI
+// artifacts) of the plugins paths, and replaces them with their local cache directory.
+func fetchRemoteSLIPlugins(ctx context.Context, logger log.Logger, httpClient *http.Client, paths []string) ([]string, error) {
+	var fetcher *pluginsource.Fetcher
+	localPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !pluginsource.IsRemote(path) {
+			localPaths = append(localPaths, path)
+			continue
+		}
+
+		if fetcher == nil {
+			dir, err := os.UserCacheDir()
+			if err != nil {
+				return nil, fmt.Errorf("could not get user cache directory: %w", err)
+			}
+
+			fetcher, err = pluginsource.NewFetcher(pluginsource.FetcherConfig{
+				CacheDir:   filepath.Join(dir, "sloth", "remote-plugins"),
+				HTTPClient: httpClient,
+				Logger:     logger,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not create remote SLI plugins fetcher: %w", err)
+			}
+		}
+
+		dir, err := fetcher.Fetch(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		localPaths = append(localPaths, dir)
+	}
+
+	return localPaths, nil
+}
+
+// readLocation reads the data of a local file path or an HTTP(S) URL location.
+func readLocation(ctx context.Context, httpClient *http.Client, location string) ([]byte, error) {
+	if !isHTTPLocation(location) {
+		return os.ReadFile(location)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func isHTTPLocation(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// compileDiscoveryRegexes compiles the SLO manifests discovery exclude and include
+// filter regexes, empty regexes are not compiled.
+func compileDiscoveryRegexes(exclude, include string) (excludeRegex, includeRegex *regexp.Regexp, err error) {
+	if exclude != "" {
+		excludeRegex, err = regexp.Compile(exclude)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid exclude regex: %w", err)
+		}
+	}
+	if include != "" {
+		includeRegex, err = regexp.Compile(include)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid include regex: %w", err)
+		}
+	}
+
+	return excludeRegex, includeRegex, nil
+}
+
 func discoverSLOManifests(logger log.Logger, exclude, include *regexp.Regexp, path string) ([]string, error) {
 	logger = logger.WithValues(log.Kv{"svc": "SLODiscovery"})
 
@@ -70,18 +250,10 @@ func discoverSLOManifests(logger log.Logger, exclude, include *regexp.Regexp, pa
 			return nil
 		}
 
-		// Filter by exclude or include (exclude has preference).
-		if exclude != nil && exclude.MatchString(path) {
-			logger.Debugf("Excluding path due to exclude filter %s", path)
-			return nil
-		}
-		if include != nil && !include.MatchString(path) {
-			logger.Debugf("Excluding path due to include filter %s", path)
-			return nil
-		}
-
 		// If we reach here, path discovered.
-		paths = append(paths, path)
+		if matchDiscoveryFilters(logger, exclude, include, path) {
+			paths = append(paths, path)
+		}
 
 		return nil
 	})
@@ -92,3 +264,283 @@ func discoverSLOManifests(logger log.Logger, exclude, include *regexp.Regexp, pa
 
 	return paths, nil
 }
+
+// matchDiscoveryFilters returns true if the discovered path is not filtered by exclude or
+// include (exclude has preference).
+func matchDiscoveryFilters(logger log.Logger, exclude, include *regexp.Regexp, path string) bool {
+	if exclude != nil && exclude.MatchString(path) {
+		logger.Debugf("Excluding path due to exclude filter %s", path)
+		return false
+	}
+	if include != nil && !include.MatchString(path) {
+		logger.Debugf("Excluding path due to include filter %s", path)
+		return false
+	}
+
+	return true
+}
+
+// archiveSLOManifests are the SLO spec manifests of an archive input, indexed by their path
+// (the archive path joined with the archive file path).
+type archiveSLOManifests map[string][]byte
+
+// ReadFile returns the data of an archive SLO spec manifest, it can be used instead of
+// `os.ReadFile` for the discovered archive paths.
+func (a archiveSLOManifests) ReadFile(path string) ([]byte, error) {
+	data, ok := a[path]
+	if !ok {
+		return nil, fmt.Errorf("%q missing on archive: %w", path, os.ErrNotExist)
+	}
+	return data, nil
+}
+
+// discoverArchiveSLOManifests expands an archive input (`.tar.gz`, `.tgz`, `.tar` or `.zip`)
+// in memory and discovers its YAML files with the same filters as the directory inputs.
+func discoverArchiveSLOManifests(logger log.Logger, exclude, include *regexp.Regexp, path string) ([]string, archiveSLOManifests, error) {
+	logger = logger.WithValues(log.Kv{"svc": "SLODiscovery", "archive": path})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read archive: %w", err)
+	}
+
+	files, err := specloader.ReadArchive(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read %q archive: %w", path, err)
+	}
+
+	paths := []string{}
+	manifests := archiveSLOManifests{}
+	for _, f := range files {
+		filePath := filepath.Join(path, filepath.FromSlash(f.Path))
+		if matchDiscoveryFilters(logger, exclude, include, filePath) {
+			paths = append(paths, filePath)
+			manifests[filePath] = f.Data
+		}
+	}
+
+	return paths, manifests, nil
+}
+
+// newSpecTemplateData returns the SLO spec templates data with the environment variables
+// and the user variables.
+func newSpecTemplateData(vars map[string]string) specloader.TemplateData {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if i := strings.Index(kv, "="); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	return specloader.TemplateData{Env: env, Vars: vars}
+}
+
+// loadAlertWindowsCatalog loads the alert windows catalog of a YAML file or the YAML files of
+// a directory, if the path is empty it will return nil so the default alert windows are used.
+func loadAlertWindowsCatalog(logger log.Logger, path string) (*alert.WindowsCatalog, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	datas, err := readAlertWindowsCatalog(logger, path)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := alert.NewWindowsCatalogFromYAML(datas...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert windows catalog: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// readAlertWindowsCatalog reads the YAML files data of the alert windows catalog file or directory.
+func readAlertWindowsCatalog(logger log.Logger, path string) ([][]byte, error) {
+	paths, err := discoverSLOManifests(logger, nil, nil, path)
+	if err != nil {
+		return nil, fmt.Errorf("could not discover alert windows files: %w", err)
+	}
+
+	datas := make([][]byte, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %q alert windows file: %w", p, err)
+		}
+		datas = append(datas, data)
+	}
+
+	return datas, nil
+}
+
+// loadCostLabelsAllowlist loads the SLO cost labels allowlist file, if the path is
+// empty it returns a nil allowlist that allows any cost label.
+func loadCostLabelsAllowlist(path string) (prometheus.CostLabelsAllowlist, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read cost labels allowlist file: %w", err)
+	}
+
+	allowlist, err := prometheus.NewCostLabelsAllowlistFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not load cost labels allowlist: %w", err)
+	}
+
+	return allowlist, nil
+}
+
+// newLabelValuesRegistry returns the SLO label values registry, if the URL is empty it
+// returns a nil registry that allows any label value.
+func newLabelValuesRegistry(httpClient *http.Client, url string, labels []string, cacheTTL time.Duration) (*prometheus.LabelValuesRegistry, error) {
+	if url == "" {
+		return nil, nil
+	}
+
+	return prometheus.NewLabelValuesRegistry(prometheus.LabelValuesRegistryConfig{
+		URL:        url,
+		Labels:     labels,
+		CacheTTL:   cacheTTL,
+		HTTPClient: httpClient,
+	})
+}
+
+// validateSLOLabels validates the SLOs cost labels and label values.
+func validateSLOLabels(ctx context.Context, costAllowlist prometheus.CostLabelsAllowlist, labelRegistry *prometheus.LabelValuesRegistry, slos []prometheus.SLO) error {
+	err := costAllowlist.Validate(slos)
+	if err != nil {
+		return fmt.Errorf("invalid SLOs cost labels: %w", err)
+	}
+
+	err = labelRegistry.Validate(ctx, slos)
+	if err != nil {
+		return fmt.Errorf("invalid SLOs label values: %w", err)
+	}
+
+	return nil
+}
+
+// loadExistingRules loads the existing Prometheus rules file to check the generated
+// rules name collisions, if the path is empty it returns nil existing rules that
+// don't check anything.
+func loadExistingRules(path string) (*prometheus.ExistingRules, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read existing rules file: %w", err)
+	}
+
+	existingRules, err := prometheus.NewExistingRulesFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not load existing rules: %w", err)
+	}
+
+	return existingRules, nil
+}
+
+// fileSLO is an SLO loaded from an SLO spec file.
+type fileSLO struct {
+	Path string
+	SLO  prometheus.SLO
+}
+
+// specLoadFlags are the SLO specs loading flags, shared by all the commands that load the SLO specs.
+type specLoadFlags struct {
+	defaultSLOPeriod     string
+	environment          string
+	vars                 map[string]string
+	sloPeriodWindowsPath string
+}
+
+func newSpecLoadFlags() specLoadFlags {
+	return specLoadFlags{vars: map[string]string{}}
+}
+
+// register registers the SLO specs loading flags on the command.
+func (s *specLoadFlags) register(cmd *kingpin.CmdClause) {
+	cmd.Flag("default-slo-period", "The SLO period (time window) of the SLO specs that don't set one (e.g 7d, 28d, 30d, 90d), the alert windows are scaled to it.").Default("30d").StringVar(&s.defaultSLOPeriod)
+	cmd.Flag("env", "The environment (e.g staging) of the SLO specs environment overrides, if not set the SLOs don't use the overrides.").StringVar(&s.environment)
+	cmd.Flag("var", "Variables of the SLO specs templates, used as '{{ .Vars.key }}' ('key=value' form, can be repeated). The environment variables are used as '{{ .Env.KEY }}'.").StringMapVar(&s.vars)
+	cmd.Flag("slo-period-windows-path", "The alert windows catalog YAML file or directory, the SLOs select the alert windows by name, by service tier or by SLO period, if not set it will use the Google SRE workbook alert windows.").StringVar(&s.sloPeriodWindowsPath)
+}
+
+// specLoaders returns the loaders of the supported SLO spec types, configured with the SLO specs
+// loading flags.
+func (s specLoadFlags) specLoaders(logger log.Logger, pluginRepo *prometheus.FileSLIPluginRepo, inputFormat string, strict bool) ([]specLoader, error) {
+	defaultSLOPeriod, err := prometheus.ParseDuration(s.defaultSLOPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default SLO period: %w", err)
+	}
+
+	alertWindows, err := loadAlertWindowsCatalog(logger, s.sloPeriodWindowsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSpecLoaders(inputFormat,
+		prometheus.NewYAMLSpecLoader(pluginRepo).WithDefaultSLOPeriod(defaultSLOPeriod).WithAlertWindows(alertWindows).WithStrict(strict).WithEnvironment(s.environment),
+		k8sprometheus.NewYAMLSpecLoader(pluginRepo).WithDefaultSLOPeriod(defaultSLOPeriod).WithAlertWindows(alertWindows).WithStrict(strict).WithEnvironment(s.environment),
+		openslo.NewYAMLSpecLoader().WithDefaultSLOPeriod(defaultSLOPeriod).WithAlertWindows(alertWindows),
+	)
+}
+
+// loadSLOs loads the SLOs of the SLO spec files trying all the supported spec types.
+func (s specLoadFlags) loadSLOs(ctx context.Context, logger log.Logger, pluginRepo *prometheus.FileSLIPluginRepo, paths []string) ([]fileSLO, error) {
+	specLoaders, err := s.specLoaders(logger, pluginRepo, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []fileSLO{}
+	tplData := newSpecTemplateData(s.vars)
+	for _, path := range paths {
+		slxData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read SLOs spec file data: %w", err)
+		}
+
+		// Render the spec templates and split YAMLs in case we have multiple yaml files in a single file.
+		slxData, err = specloader.RenderTemplates(slxData, tplData)
+		if err != nil {
+			return nil, fmt.Errorf("could not render %q SLOs spec templates: %w", path, err)
+		}
+		splittedSLOsData, err := specloader.ReadAll(bytes.NewReader(slxData))
+		if err != nil {
+			return nil, fmt.Errorf("could not split %q SLOs spec file data: %w", path, err)
+		}
+
+		for _, data := range splittedSLOsData {
+			spec, err := loadSpec(ctx, specLoaders, data)
+			if err != nil {
+				logSpecLoadErrors(logger, err)
+				return nil, fmt.Errorf("invalid %q spec, could not load with any of the supported spec types", path)
+			}
+
+			for _, slo := range spec.sloGroup.SLOs {
+				res = append(res, fileSLO{Path: path, SLO: slo})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// newPrometheusAPI returns a Prometheus HTTP API client of the Prometheus URL.
+func newPrometheusAPI(url string, httpClient *http.Client) (promv1.API, error) {
+	client, err := promapi.NewClient(promapi.Config{
+		Address:      url,
+		RoundTripper: httpClient.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create Prometheus API client: %w", err)
+	}
+
+	return promv1.NewAPI(client), nil
+}
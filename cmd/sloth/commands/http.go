@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// NewHTTPClient returns the HTTP client used by all the commands remote integrations, configured
+// with the root HTTP flags (proxy, custom CA bundle and mTLS client certificate).
+func NewHTTPClient(config RootConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// By default use the proxy from the environment (HTTP_PROXY, HTTPS_PROXY and NO_PROXY).
+	if config.HTTPProxy != "" {
+		proxyURL, err := url.Parse(config.HTTPProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTP proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	// Custom CAs are added to the system ones.
+	if config.HTTPCAFile != "" {
+		caData, err := os.ReadFile(config.HTTPCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read HTTP CA file: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("HTTP CA file doesn't have valid PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (config.HTTPCertFile == "") != (config.HTTPKeyFile == "") {
+		return nil, fmt.Errorf("HTTP client certificate and key files are required together")
+	}
+	if config.HTTPCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.HTTPCertFile, config.HTTPKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load HTTP client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
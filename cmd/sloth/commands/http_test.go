@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertFiles writes the test server certificate and key as PEM files, they are used as the
+// custom CA and as the client certificate.
+func writeTestCertFiles(t *testing.T, dir string, srv *httptest.Server) (certFile, keyFile string) {
+	cert := srv.TLS.Certificates[0]
+	keyData, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyData}), 0644))
+
+	return certFile, keyFile
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	// The server responds with the requested host and the number of client certificates.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		certs := 0
+		if r.TLS != nil {
+			certs = len(r.TLS.PeerCertificates)
+		}
+		fmt.Fprintf(w, "host=%s certs=%d", r.Host, certs)
+	})
+	newTLSServer := func(clientAuth tls.ClientAuthType) *httptest.Server {
+		srv := httptest.NewUnstartedServer(handler)
+		srv.TLS = &tls.Config{ClientAuth: clientAuth}
+		srv.StartTLS()
+		return srv
+	}
+
+	tests := map[string]struct {
+		server    func() *httptest.Server
+		config    func(t *testing.T, dir string, srv *httptest.Server) RootConfig
+		url       func(srv *httptest.Server) string
+		expErr    bool
+		expReqErr bool
+		expBody   string
+	}{
+		"An invalid proxy URL should fail.": {
+			server: func() *httptest.Server { return httptest.NewServer(handler) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				return RootConfig{HTTPProxy: "://proxy"}
+			},
+			expErr: true,
+		},
+
+		"A missing CA file should fail.": {
+			server: func() *httptest.Server { return httptest.NewServer(handler) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				return RootConfig{HTTPCAFile: filepath.Join(dir, "missing.pem")}
+			},
+			expErr: true,
+		},
+
+		"A CA file without PEM certificates should fail.": {
+			server: func() *httptest.Server { return httptest.NewServer(handler) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				caFile := filepath.Join(dir, "ca.pem")
+				require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0644))
+				return RootConfig{HTTPCAFile: caFile}
+			},
+			expErr: true,
+		},
+
+		"A client certificate without key should fail.": {
+			server: func() *httptest.Server { return newTLSServer(tls.NoClientCert) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				certFile, _ := writeTestCertFiles(t, dir, srv)
+				return RootConfig{HTTPCertFile: certFile}
+			},
+			expErr: true,
+		},
+
+		"An invalid client certificate should fail.": {
+			server: func() *httptest.Server { return newTLSServer(tls.NoClientCert) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				_, keyFile := writeTestCertFiles(t, dir, srv)
+				return RootConfig{HTTPCertFile: keyFile, HTTPKeyFile: keyFile}
+			},
+			expErr: true,
+		},
+
+		"A TLS server with a certificate of an unknown CA should fail the requests.": {
+			server: func() *httptest.Server { return newTLSServer(tls.NoClientCert) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				return RootConfig{}
+			},
+			url:       func(srv *httptest.Server) string { return srv.URL },
+			expReqErr: true,
+		},
+
+		"A TLS server with a certificate of the custom CA should be trusted.": {
+			server: func() *httptest.Server { return newTLSServer(tls.NoClientCert) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				caFile, _ := writeTestCertFiles(t, dir, srv)
+				return RootConfig{HTTPCAFile: caFile}
+			},
+			url:     func(srv *httptest.Server) string { return srv.URL },
+			expBody: "certs=0",
+		},
+
+		"A TLS server that requires a client certificate should receive the client certificate.": {
+			server: func() *httptest.Server { return newTLSServer(tls.RequireAnyClientCert) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				certFile, keyFile := writeTestCertFiles(t, dir, srv)
+				return RootConfig{HTTPCAFile: certFile, HTTPCertFile: certFile, HTTPKeyFile: keyFile}
+			},
+			url:     func(srv *httptest.Server) string { return srv.URL },
+			expBody: "certs=1",
+		},
+
+		"The proxy URL should be used for the requests.": {
+			server: func() *httptest.Server { return httptest.NewServer(handler) },
+			config: func(t *testing.T, dir string, srv *httptest.Server) RootConfig {
+				return RootConfig{HTTPProxy: srv.URL}
+			},
+			url:     func(srv *httptest.Server) string { return "http://prometheus.sloth.test:9090/api" },
+			expBody: "host=prometheus.sloth.test:9090 certs=0",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			srv := test.server()
+			defer srv.Close()
+
+			client, err := NewHTTPClient(test.config(t, t.TempDir(), srv))
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+
+			resp, err := client.Get(test.url(srv))
+			if test.expReqErr {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(err)
+
+			assert.Contains(string(body), test.expBody)
+		})
+	}
+}
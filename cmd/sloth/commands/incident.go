@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"text/tabwriter"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/pkg/budget"
+	"github.com/slok/sloth/pkg/durationfmt"
+)
+
+type incidentCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	alertName           string
+	slosInput           string
+	slosExcludeRegex    string
+	slosIncludeRegex    string
+	prometheusAddress   string
+	alertmanagerAddress string
+	silenceDuration     time.Duration
+	silenceComment      string
+	locale              string
+}
+
+// NewIncidentCommand returns the incident command.
+func NewIncidentCommand(app *kingpin.Application) Command {
+	c := &incidentCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("incident", "Prints the current error budget burn of the SLOs of a firing SLO alert, the projected error budget exhaustion and the commands to silence the alert.")
+	cmd.Arg("alert", "The firing SLO alert name.").Required().StringVar(&c.alertName)
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("prometheus-address", "The Prometheus API address where the SLO recording rules are evaluated.").Default("http://127.0.0.1:9090").StringVar(&c.prometheusAddress)
+	cmd.Flag("alertmanager-address", "The Alertmanager address used on the silence commands.").Default("http://127.0.0.1:9093").StringVar(&c.alertmanagerAddress)
+	cmd.Flag("silence-duration", "The duration used on the silence commands.").Default("1h").DurationVar(&c.silenceDuration)
+	cmd.Flag("silence-comment", "The comment used on the silence commands.").Default("SLO incident").StringVar(&c.silenceComment)
+	cmd.Flag("locale", "If set, the report durations will be human-readable on this locale language (en, es, fr, de or pt), e.g 1 hour and 30 minutes.").StringVar(&c.locale)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (i incidentCommand) Name() string { return "incident" }
+func (i incidentCommand) Run(ctx context.Context, config RootConfig) error {
+	// Set up files discovery filter regex.
+	var excludeRegex *regexp.Regexp
+	var includeRegex *regexp.Regexp
+	if i.slosExcludeRegex != "" {
+		r, err := regexp.Compile(i.slosExcludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid exclude regex: %w", err)
+		}
+		excludeRegex = r
+	}
+	if i.slosIncludeRegex != "" {
+		r, err := regexp.Compile(i.slosIncludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid include regex: %w", err)
+		}
+		includeRegex = r
+	}
+
+	// Set up the report durations format.
+	formatDuration := func(d time.Duration) string { return prommodel.Duration(d).String() }
+	if i.locale != "" {
+		locale, err := durationfmt.ParseLocale(i.locale)
+		if err != nil {
+			return fmt.Errorf("invalid locale: %w", err)
+		}
+		formatter, err := durationfmt.NewFormatter(locale)
+		if err != nil {
+			return err
+		}
+		formatDuration = formatter.Format
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, i.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := i.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	slos, err := i.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+
+	// Get the SLOs of the alert.
+	alertSLOs := []prometheus.SLO{}
+	for _, s := range slos {
+		if (!s.SLO.PageAlertMeta.Disable && s.SLO.PageAlertMeta.Name == i.alertName) ||
+			(!s.SLO.TicketAlertMeta.Disable && s.SLO.TicketAlertMeta.Name == i.alertName) {
+			alertSLOs = append(alertSLOs, s.SLO)
+		}
+	}
+	if len(alertSLOs) == 0 {
+		return fmt.Errorf("0 SLOs with the %q alert have been discovered", i.alertName)
+	}
+
+	client, err := promapi.NewClient(promapi.Config{
+		Address:      i.prometheusAddress,
+		RoundTripper: config.HTTPClient.Transport,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create Prometheus API client: %w", err)
+	}
+	querier := promAPISampleQuerier{api: promv1.NewAPI(client)}
+
+	for _, slo := range alertSLOs {
+		alerts, err := alert.AlertGenerator.GenerateMWMBAlerts(ctx, alert.SLO{
+			ID:         slo.ID,
+			TimeWindow: slo.TimeWindow,
+			Objective:  slo.Objective,
+			Windows:    slo.AlertWindows,
+		})
+		if err != nil {
+			return fmt.Errorf("could not generate %q SLO alerts: %w", slo.ID, err)
+		}
+
+		report, err := prometheus.NewIncidentReport(ctx, querier, slo, *alerts)
+		if err != nil {
+			return fmt.Errorf("could not get %q SLO incident report: %w", slo.ID, err)
+		}
+
+		err = i.printReport(config.Stdout, formatDuration, *report)
+		if err != nil {
+			return fmt.Errorf("could not write %q SLO incident report: %w", slo.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (i incidentCommand) printReport(out io.Writer, formatDuration func(time.Duration) string, report prometheus.IncidentReport) error {
+	slo := report.SLO
+	fmt.Fprintf(out, "SLO %s (service: %s, objective: %v%%, time window: %s)\n\n", slo.ID, slo.Service, slo.Objective, formatDuration(slo.TimeWindow))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WINDOW\tERROR RATIO\tBURN RATE")
+	for _, b := range report.Windows {
+		fmt.Fprintf(w, "%s\t%.5f\t%.2fx\n", formatDuration(b.Window), b.ErrorRatio, b.BurnRate)
+	}
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nCurrent burn rate: %.2fx\n", report.CurrentBurnRate)
+	fmt.Fprintf(out, "Error budget remaining: %.2f%%\n", report.ErrorBudgetRemaining*100)
+	errorBudget := budget.AllowedDowntime(slo.Objective, slo.TimeWindow)
+	if report.ErrorBudgetRemaining > 0 {
+		remaining := time.Duration(float64(errorBudget) * report.ErrorBudgetRemaining)
+		fmt.Fprintf(out, "Error budget downtime remaining: %s of %s\n", formatDuration(remaining.Round(time.Second)), formatDuration(errorBudget.Round(time.Second)))
+	}
+	switch {
+	case report.ErrorBudgetRemaining <= 0:
+		fmt.Fprintf(out, "Projected error budget exhaustion: already exhausted\n")
+	case report.Exhaustion == 0:
+		fmt.Fprintf(out, "Projected error budget exhaustion: not burning\n")
+	default:
+		fmt.Fprintf(out, "Projected error budget exhaustion: in %s (%s)\n", formatDuration(report.Exhaustion), time.Now().Add(report.Exhaustion).UTC().Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(out, "\nSilence:\n  amtool silence add --alertmanager.url=%q --duration=%q --comment=%q 'alertname=%q' 'sloth_id=%q'\n\n",
+		i.alertmanagerAddress, prommodel.Duration(i.silenceDuration), i.silenceComment, i.alertName, slo.ID)
+
+	return nil
+}
+
+// promAPISampleQuerier gets the Prometheus instant query samples using the Prometheus HTTP API.
+type promAPISampleQuerier struct {
+	api promv1.API
+}
+
+func (p promAPISampleQuerier) QuerySample(ctx context.Context, query string) (float64, error) {
+	value, _, err := p.api.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	vector, ok := value.(prommodel.Vector)
+	if !ok {
+		return 0, fmt.Errorf("query result is not a vector")
+	}
+	if len(vector) != 1 {
+		return 0, fmt.Errorf("query returned %d samples, expected 1", len(vector))
+	}
+
+	return float64(vector[0].Value), nil
+}
@@ -34,23 +34,48 @@ import (
 )
 
 type kubeControllerCommand struct {
-	extraLabels       map[string]string
-	workers           int
-	kubeConfig        string
-	kubeContext       string
-	resyncInterval    time.Duration
-	namespace         string
-	development       bool
-	metricsPath       string
-	hotReloadPath     string
-	hotReloadAddr     string
-	metricsListenAddr string
-	sliPluginsPaths   []string
+	sliPluginFlags
+	extraLabels             map[string]string
+	ruleLabels              map[string]string
+	ruleAnnotations         map[string]string
+	noRuleDefLabels         bool
+	sliZeroTotalGuard       bool
+	errorBudgetForecast     bool
+	workers                 int
+	processingRetries       int
+	kubeAPIQPS              float64
+	kubeAPIBurst            int
+	kubeConfig              string
+	kubeContext             string
+	resyncInterval          time.Duration
+	namespace               string
+	development             bool
+	metricsPath             string
+	hotReloadPath           string
+	hotReloadAddr           string
+	metricsListenAddr       string
+	shard                   string
+	targetPlatform          string
+	partialResponseStrategy string
+	ruleGroupInterval       time.Duration
+	sliRuleGroupInterval    time.Duration
+	metaRuleGroupInterval   time.Duration
+	alertRuleGroupInterval  time.Duration
+	sliWindowRuleGroups     bool
+	sliWindowGroupIntervals map[string]string
+	defaultSLOPeriod        string
+	environment             string
+	sloPeriodWindowsPath    string
 }
 
 // NewKubeControllerCommand returns the Kubernetes controller command.
 func NewKubeControllerCommand(app *kingpin.Application) Command {
-	c := &kubeControllerCommand{extraLabels: map[string]string{}}
+	c := &kubeControllerCommand{
+		extraLabels:             map[string]string{},
+		ruleLabels:              map[string]string{},
+		ruleAnnotations:         map[string]string{},
+		sliWindowGroupIntervals: map[string]string{},
+	}
 	cmd := app.Command("kubernetes-controller", "Runs Sloth in Kubernetes controller/operator mode.")
 	cmd.Alias("controller")
 	cmd.Alias("k8s-controller")
@@ -61,20 +86,71 @@ func NewKubeControllerCommand(app *kingpin.Application) Command {
 	cmd.Flag("kube-context", "kubernetes context, only used when development mode enabled.").StringVar(&c.kubeContext)
 	cmd.Flag("workers", "Concurrent processing workers for each kubernetes controller.").Default("5").IntVar(&c.workers)
 	cmd.Flag("resync-interval", "The duration between all resources resync.").Default("15m").DurationVar(&c.resyncInterval)
+	cmd.Flag("processing-retries", "The number of times a resource processing will be retried before giving up (it will be processed again on the next resync).").Default("2").IntVar(&c.processingRetries)
+	cmd.Flag("kube-api-qps", "The Kubernetes API client rate limiter QPS.").Default("100").Float64Var(&c.kubeAPIQPS)
+	cmd.Flag("kube-api-burst", "The Kubernetes API client rate limiter burst.").Default("100").IntVar(&c.kubeAPIBurst)
 	cmd.Flag("namespace", "Run the controller targeting specific namespace, by default all.").StringVar(&c.namespace)
+	cmd.Flag("shard", "Run the controller handling only a shard of the PrometheusServiceLevels partitioned by namespace/name hash, in `{index}/{total}` form (e.g 0/3), by default all.").StringVar(&c.shard)
 	cmd.Flag("metrics-path", "The path for Prometheus metrics.").Default("/metrics").StringVar(&c.metricsPath)
 	cmd.Flag("metrics-listen-addr", "The listen address for Prometheus metrics and pprof.").Default(":8081").StringVar(&c.metricsListenAddr)
 	cmd.Flag("hot-reload-addr", "The listen address for hot-reloading components that allow it.").Default(":8082").StringVar(&c.hotReloadAddr)
 	cmd.Flag("hot-reload-path", "The webhook path for hot-reloading components that allow it.").Default("/-/reload").StringVar(&c.hotReloadPath)
 	cmd.Flag("extra-labels", "Extra labels that will be added to all the generated Prometheus rules ('key=value' form, can be repeated).").Short('l').StringMapVar(&c.extraLabels)
-	cmd.Flag("sli-plugins-path", "The path to SLI plugins (can be repeated), if not set it disable plugins support.").Short('p').StringsVar(&c.sliPluginsPaths)
+	c.sliPluginFlags.register(cmd)
+	cmd.Flag("prometheus-rule-labels", "Labels that will be set on the generated PrometheusRule objects, useful to match Prometheus operator `ruleSelector` ('key=value' form, can be repeated).").StringMapVar(&c.ruleLabels)
+	cmd.Flag("prometheus-rule-annotations", "Annotations that will be set on the generated PrometheusRule objects ('key=value' form, can be repeated).").StringMapVar(&c.ruleAnnotations)
+	cmd.Flag("disable-prometheus-rule-default-labels", "Disables the default labels (component and managed-by) set on the generated PrometheusRule objects.").BoolVar(&c.noRuleDefLabels)
+	cmd.Flag("target-platform", "The platform that will evaluate the generated rules, Thanos ruler enables the Thanos rule groups extensions.").Default(targetPlatformPrometheus).EnumVar(&c.targetPlatform, targetPlatformPrometheus, targetPlatformThanos)
+	cmd.Flag("thanos-partial-response-strategy", "The Thanos ruler partial response strategy of the generated rule groups, only used with Thanos target platform.").Default(thanosPartialResponseAbort).EnumVar(&c.partialResponseStrategy, thanosPartialResponseAbort, thanosPartialResponseWarn)
+	cmd.Flag("sli-zero-total-guard", "Guards the events SLI recording rules against zero totals, the windows without events have a 0 error ratio instead of NaN series that break the error budget calculations.").BoolVar(&c.sliZeroTotalGuard)
+	cmd.Flag("error-budget-forecast", "Generates the error budget exhaustion forecast recording rules, the seconds left until the SLO period error budget is exhausted at the current burn rate trend.").BoolVar(&c.errorBudgetForecast)
+	cmd.Flag("rule-group-interval", "The evaluation interval of the generated rule groups, if 0 the rule groups use the ruler global evaluation interval. The SLO spec rule group intervals have priority.").Default("0s").DurationVar(&c.ruleGroupInterval)
+	cmd.Flag("sli-rule-group-interval", "The evaluation interval of the generated SLI recording rule groups, if 0 it will use the rule group interval.").Default("0s").DurationVar(&c.sliRuleGroupInterval)
+	cmd.Flag("meta-rule-group-interval", "The evaluation interval of the generated metadata recording rule groups, if 0 it will use the rule group interval.").Default("0s").DurationVar(&c.metaRuleGroupInterval)
+	cmd.Flag("alert-rule-group-interval", "The evaluation interval of the generated alert rule groups, if 0 it will use the rule group interval.").Default("0s").DurationVar(&c.alertRuleGroupInterval)
+	cmd.Flag("sli-window-rule-groups", "Groups the generated SLI recording rules of all the SLOs in rule groups shared per SLI window (e.g all the 5m rules together and all the 30d rules together), so the long windows can be evaluated less often.").BoolVar(&c.sliWindowRuleGroups)
+	cmd.Flag("sli-window-rule-group-interval", "The evaluation interval of the SLI window rule groups of a window ('window=interval' form, e.g '30d=5m', can be repeated), the windows without interval use the SLI rule group interval.").StringMapVar(&c.sliWindowGroupIntervals)
+	cmd.Flag("default-slo-period", "The SLO period (time window) of the SLO specs that don't set one (e.g 7d, 28d, 30d, 90d), the alert windows are scaled to it.").Default("30d").StringVar(&c.defaultSLOPeriod)
+	cmd.Flag("env", "The environment (e.g staging) of the SLO specs environment overrides, if not set the SLOs don't use the overrides.").StringVar(&c.environment)
+	cmd.Flag("slo-period-windows-path", "The alert windows catalog YAML file or directory, the SLOs select the alert windows by name, by service tier or by SLO period, if not set it will use the Google SRE workbook alert windows.").StringVar(&c.sloPeriodWindowsPath)
 
 	return c
 }
 
 func (k kubeControllerCommand) Name() string { return "kubernetes-controller" }
 func (k kubeControllerCommand) Run(ctx context.Context, config RootConfig) error {
-	pluginRepo, err := createPluginLoader(ctx, config.Logger, k.sliPluginsPaths)
+	if k.workers < 1 {
+		return fmt.Errorf("at least 1 worker is required")
+	}
+	if k.processingRetries < 0 {
+		return fmt.Errorf("processing retries can't be negative")
+	}
+
+	shard := kubecontroller.Shard{}
+	if k.shard != "" {
+		s, err := kubecontroller.ParseShard(k.shard)
+		if err != nil {
+			return err
+		}
+		shard = s
+	}
+
+	defaultSLOPeriod, err := prometheus.ParseDuration(k.defaultSLOPeriod)
+	if err != nil {
+		return fmt.Errorf("invalid default SLO period: %w", err)
+	}
+
+	sliWindowGroups, err := sliWindowGroupsFor(k.sliWindowRuleGroups, k.sliWindowGroupIntervals)
+	if err != nil {
+		return err
+	}
+
+	alertWindows, err := loadAlertWindowsCatalog(config.Logger, k.sloPeriodWindowsPath)
+	if err != nil {
+		return err
+	}
+
+	pluginRepo, err := k.createPluginLoader(ctx, config.Logger, config.HTTPClient)
 	if err != nil {
 		return err
 	}
@@ -254,10 +330,18 @@ func (k kubeControllerCommand) Run(ctx context.Context, config RootConfig) error
 		defer cancel()
 
 		// Create the generate app service (the one that the CLIs use).
+		sliRecordingRulesGen := prometheus.SLIRecordingRulesGenerator
+		if k.sliZeroTotalGuard {
+			sliRecordingRulesGen = sliRecordingRulesGen.WithZeroTotalGuard()
+		}
+		metaRecordingRulesGen := prometheus.MetadataRecordingRulesGenerator
+		if k.errorBudgetForecast {
+			metaRecordingRulesGen = metaRecordingRulesGen.WithErrorBudgetForecast()
+		}
 		generator, err := generate.NewService(generate.ServiceConfig{
 			AlertGenerator:              alert.AlertGenerator,
-			SLIRecordingRulesGenerator:  prometheus.SLIRecordingRulesGenerator,
-			MetaRecordingRulesGenerator: prometheus.MetadataRecordingRulesGenerator,
+			SLIRecordingRulesGenerator:  sliRecordingRulesGen,
+			MetaRecordingRulesGenerator: metaRecordingRulesGen,
 			SLOAlertRulesGenerator:      prometheus.SLOAlertRulesGenerator,
 			Logger:                      generatorLogger{Logger: config.Logger},
 		})
@@ -268,8 +352,8 @@ func (k kubeControllerCommand) Run(ctx context.Context, config RootConfig) error
 		// Create handler.
 		config := kubecontroller.HandlerConfig{
 			Generator:        generator,
-			SpecLoader:       k8sprometheus.NewCRSpecLoader(pluginRepo),
-			Repository:       k8sprometheus.NewPrometheusOperatorCRDRepo(ksvc, config.Logger),
+			SpecLoader:       k8sprometheus.NewCRSpecLoader(pluginRepo).WithDefaultSLOPeriod(defaultSLOPeriod).WithAlertWindows(alertWindows).WithEnvironment(k.environment),
+			Repository:       k8sprometheus.NewPrometheusOperatorCRDRepo(ksvc, k.ruleMeta(sliWindowGroups), config.Logger),
 			KubeStatusStorer: ksvc,
 			ExtraLabels:      k.extraLabels,
 			Logger:           config.Logger,
@@ -280,7 +364,7 @@ func (k kubeControllerCommand) Run(ctx context.Context, config RootConfig) error
 		}
 
 		// Create retriever.
-		ret := kubecontroller.NewPrometheusServiceLevelsRetriver(k.namespace, ksvc)
+		ret := kubecontroller.NewPrometheusServiceLevelsRetriver(k.namespace, shard, ksvc)
 
 		ctrl, err := koopercontroller.New(&koopercontroller.Config{
 			Handler:              handler,
@@ -288,7 +372,7 @@ func (k kubeControllerCommand) Run(ctx context.Context, config RootConfig) error
 			Logger:               kooperlogger{Logger: config.Logger.WithValues(log.Kv{"lib": "kooper"})},
 			Name:                 "sloth",
 			ConcurrentWorkers:    k.workers,
-			ProcessingJobRetries: 2,
+			ProcessingJobRetries: k.processingRetries,
 			ResyncInterval:       k.resyncInterval,
 			MetricsRecorder:      kooperprometheus.New(kooperprometheus.Config{}),
 		})
@@ -311,6 +395,18 @@ func (k kubeControllerCommand) Run(ctx context.Context, config RootConfig) error
 	return g.Run()
 }
 
+// ruleMeta returns the metadata scheme that will be set on the generated PrometheusRules.
+func (k kubeControllerCommand) ruleMeta(sliWindowGroups prometheus.SLIWindowGroups) k8sprometheus.PrometheusRuleMeta {
+	return k8sprometheus.PrometheusRuleMeta{
+		Labels:                  k.ruleLabels,
+		Annotations:             k.ruleAnnotations,
+		DisableDefaultLabels:    k.noRuleDefLabels,
+		PartialResponseStrategy: partialResponseStrategyFor(k.targetPlatform, k.partialResponseStrategy),
+		Intervals:               ruleGroupIntervalsFor(k.ruleGroupInterval, k.sliRuleGroupInterval, k.metaRuleGroupInterval, k.alertRuleGroupInterval),
+		SLIWindowGroups:         sliWindowGroups,
+	}
+}
+
 // loadKubernetesConfig loads kubernetes configuration based on flags.
 func (k kubeControllerCommand) loadKubernetesConfig() (*rest.Config, error) {
 	var cfg *rest.Config
@@ -338,8 +434,8 @@ func (k kubeControllerCommand) loadKubernetesConfig() (*rest.Config, error) {
 	}
 
 	// Set better cli rate limiter.
-	cfg.QPS = 100
-	cfg.Burst = 100
+	cfg.QPS = float32(k.kubeAPIQPS)
+	cfg.Burst = k.kubeAPIBurst
 
 	return cfg, nil
 }
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/httpmiddleware"
+	"github.com/slok/sloth/internal/k8sprometheus"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+type kubeWebhookCommand struct {
+	sliPluginFlags
+	listenAddr           string
+	path                 string
+	tlsCertFile          string
+	tlsKeyFile           string
+	maxRequestSize       int64
+	defaultSLOPeriod     string
+	environment          string
+	sloPeriodWindowsPath string
+}
+
+// NewKubeWebhookCommand returns the Kubernetes admission webhook command.
+func NewKubeWebhookCommand(app *kingpin.Application) Command {
+	c := &kubeWebhookCommand{}
+	cmd := app.Command("kubernetes-webhook", "Runs a Kubernetes validating admission webhook server that rejects the invalid PrometheusServiceLevel CRs (e.g objective out of bounds or invalid SLI queries) before the controller processes them.")
+	cmd.Alias("k8s-webhook")
+
+	cmd.Flag("listen-addr", "The HTTPS listen address of the webhook server.").Default(":8443").StringVar(&c.listenAddr)
+	cmd.Flag("path", "The HTTP path of the validating webhook, the same as the ValidatingWebhookConfiguration service path.").Default("/validate").StringVar(&c.path)
+	cmd.Flag("tls-cert-file", "The PEM TLS certificate file of the webhook server, Kubernetes apiserver only calls webhooks over HTTPS.").Required().StringVar(&c.tlsCertFile)
+	cmd.Flag("tls-key-file", "The PEM TLS key file of the webhook server.").Required().StringVar(&c.tlsKeyFile)
+	cmd.Flag("max-request-size", "The maximum request body size in bytes.").Default("3145728").Int64Var(&c.maxRequestSize)
+	c.sliPluginFlags.register(cmd)
+	cmd.Flag("default-slo-period", "The SLO period (time window) of the SLO specs that don't set one (e.g 7d, 28d, 30d, 90d), the alert windows are scaled to it.").Default("30d").StringVar(&c.defaultSLOPeriod)
+	cmd.Flag("env", "The environment (e.g staging) of the SLO specs environment overrides, if not set the SLOs don't use the overrides.").StringVar(&c.environment)
+	cmd.Flag("slo-period-windows-path", "The alert windows catalog YAML file or directory, the SLOs select the alert windows by name, by service tier or by SLO period, if not set it will use the Google SRE workbook alert windows.").StringVar(&c.sloPeriodWindowsPath)
+
+	return c
+}
+
+func (k kubeWebhookCommand) Name() string { return "kubernetes-webhook" }
+func (k kubeWebhookCommand) Run(ctx context.Context, config RootConfig) error {
+	if k.maxRequestSize <= 0 {
+		return fmt.Errorf("max request size must be greater than 0")
+	}
+
+	// Load the TLS certificate on start, so misconfigurations fail fast.
+	cert, err := tls.LoadX509KeyPair(k.tlsCertFile, k.tlsKeyFile)
+	if err != nil {
+		return fmt.Errorf("could not load TLS certificate: %w", err)
+	}
+
+	defaultSLOPeriod, err := prometheus.ParseDuration(k.defaultSLOPeriod)
+	if err != nil {
+		return fmt.Errorf("invalid default SLO period: %w", err)
+	}
+
+	alertWindows, err := loadAlertWindowsCatalog(config.Logger, k.sloPeriodWindowsPath)
+	if err != nil {
+		return err
+	}
+
+	// The SLOs are validated with the same plugins and settings as the controller.
+	pluginRepo, err := k.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+	loader := k8sprometheus.NewCRSpecLoader(pluginRepo).WithDefaultSLOPeriod(defaultSLOPeriod).WithAlertWindows(alertWindows).WithEnvironment(k.environment)
+	validator := k8sprometheus.NewCRSpecValidator(loader)
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.Handle(k.path, httpmiddleware.MaxBodySize(k.maxRequestSize, k8sprometheus.NewAdmissionWebhookHandler(validator, config.Logger)))
+	server := &http.Server{
+		Addr:              k.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}},
+	}
+
+	errC := make(chan error, 1)
+	go func() {
+		config.Logger.WithValues(log.Kv{"addr": k.listenAddr, "path": k.path}).Infof("Kubernetes admission webhook HTTPS server listening")
+		errC <- server.ListenAndServeTLS("", "")
+	}()
+
+	select {
+	case err := <-errC:
+		return fmt.Errorf("kubernetes admission webhook HTTPS server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+	"gopkg.in/yaml.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+type mergeCommand struct {
+	slosInputs []string
+	slosOut    string
+}
+
+// NewMergeCommand returns the merge command.
+func NewMergeCommand(app *kingpin.Application) Command {
+	c := &mergeCommand{}
+	cmd := app.Command("merge", "Merges multiple Prometheus SLO spec files of the same service into a single spec.")
+	cmd.Flag("input", "SLO spec input file path (can be repeated).").Short('i').Required().StringsVar(&c.slosInputs)
+	cmd.Flag("out", "Merged spec output file path. If `-` it will use stdout.").Short('o').Default("-").StringVar(&c.slosOut)
+
+	return c
+}
+
+func (m mergeCommand) Name() string { return "merge" }
+func (m mergeCommand) Run(ctx context.Context, config RootConfig) error {
+	// Load all the specs.
+	specs := []prometheusv1.Spec{}
+	for _, input := range m.slosInputs {
+		slxData, err := os.ReadFile(input)
+		if err != nil {
+			return fmt.Errorf("could not read SLOs spec file data: %w", err)
+		}
+
+		// Split YAMLs in case we have multiple yaml files in a single file.
+		splittedSLOsData, err := specloader.ReadAll(bytes.NewReader(slxData))
+		if err != nil {
+			return fmt.Errorf("could not split %q SLOs spec file data: %w", input, err)
+		}
+
+		for _, data := range splittedSLOsData {
+			spec, err := specloader.LoadPrometheusV1(data)
+			if err != nil {
+				return fmt.Errorf("could not load %q spec: %w", input, err)
+			}
+			specs = append(specs, *spec)
+		}
+	}
+
+	// Merge.
+	spec, err := prometheus.MergeSpecs(specs)
+	if err != nil {
+		return fmt.Errorf("could not merge specs: %w", err)
+	}
+
+	specData, err := yaml.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("could not format merged spec: %w", err)
+	}
+
+	// Store.
+	var out io.Writer = config.Stdout
+	if m.slosOut != "-" {
+		f, err := os.Create(m.slosOut)
+		if err != nil {
+			return fmt.Errorf("could not create out file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	_, err = out.Write(specData)
+	if err != nil {
+		return fmt.Errorf("could not write merged spec: %w", err)
+	}
+
+	config.Logger.WithValues(log.Kv{"specs": len(specs), "slos": len(spec.SLOs)}).Infof("Specs merged")
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/pluginindex"
+)
+
+type pluginsInstallCommand struct {
+	index    string
+	cacheDir string
+	plugins  []string
+}
+
+// NewPluginsInstallCommand returns the plugins install command.
+func NewPluginsInstallCommand(app *kingpin.Application) Command {
+	c := &pluginsInstallCommand{}
+	cmd := app.Command("plugins", "Manages the SLI plugins.")
+	install := cmd.Command("install", "Installs SLI plugins from a plugins index after verifying their checksums, the installed plugins are used by default when the SLI plugins path is not set.")
+	install.Flag("index", "The plugins index location, a local file path or an HTTP(S) URL.").Required().StringVar(&c.index)
+	install.Flag("cache-dir", "The directory where the plugins will be installed, if not set it will use the user cache directory.").StringVar(&c.cacheDir)
+	install.Arg("plugins", "The plugins to install in name or name@version form, without version it will install the last version of the index.").Required().StringsVar(&c.plugins)
+
+	return c
+}
+
+func (p pluginsInstallCommand) Name() string { return "plugins install" }
+func (p pluginsInstallCommand) Run(ctx context.Context, config RootConfig) error {
+	cacheDir := p.cacheDir
+	if cacheDir == "" {
+		dir, err := defaultSLIPluginsCacheDir()
+		if err != nil {
+			return err
+		}
+		cacheDir = dir
+	}
+
+	indexData, err := readLocation(ctx, config.HTTPClient, p.index)
+	if err != nil {
+		return fmt.Errorf("could not get plugins index: %w", err)
+	}
+	index, err := pluginindex.NewIndexFromYAML(indexData)
+	if err != nil {
+		return fmt.Errorf("invalid plugins index: %w", err)
+	}
+
+	// Get and verify all the plugins before installing any of them.
+	type verifiedPlugin struct {
+		plugin *pluginindex.Plugin
+		data   []byte
+	}
+	plugins := []verifiedPlugin{}
+	for _, ref := range p.plugins {
+		plugin, err := index.Get(pluginindex.ParseRef(ref))
+		if err != nil {
+			return err
+		}
+
+		source, err := resolvePluginSource(p.index, plugin.Source)
+		if err != nil {
+			return err
+		}
+
+		data, err := readLocation(ctx, config.HTTPClient, source)
+		if err != nil {
+			return fmt.Errorf("could not get %q plugin: %w", plugin.Name, err)
+		}
+
+		err = plugin.Verify(data)
+		if err != nil {
+			return fmt.Errorf("could not verify plugin: %w", err)
+		}
+
+		plugins = append(plugins, verifiedPlugin{plugin: plugin, data: data})
+	}
+
+	for _, vp := range plugins {
+		path := vp.plugin.InstallPath(cacheDir)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return fmt.Errorf("could not create %q plugin directory: %w", vp.plugin.Name, err)
+		}
+
+		err = os.WriteFile(path, vp.data, 0644)
+		if err != nil {
+			return fmt.Errorf("could not write %q plugin: %w", vp.plugin.Name, err)
+		}
+
+		config.Logger.WithValues(log.Kv{"plugin": vp.plugin.Name, "version": vp.plugin.Version, "path": path}).Infof("SLI plugin installed")
+	}
+
+	return nil
+}
+
+// resolvePluginSource resolves the plugin source location relative to the index location.
+func resolvePluginSource(index, source string) (string, error) {
+	if isHTTPLocation(source) || filepath.IsAbs(source) {
+		return source, nil
+	}
+
+	if !isHTTPLocation(index) {
+		return filepath.Join(filepath.Dir(index), filepath.FromSlash(source)), nil
+	}
+
+	indexURL, err := url.Parse(index)
+	if err != nil {
+		return "", fmt.Errorf("invalid plugins index URL: %w", err)
+	}
+	sourceURL, err := url.Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid %q plugin source: %w", source, err)
+	}
+
+	return indexURL.ResolveReference(sourceURL).String(), nil
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+type previewAlertCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	sloID            string
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	severity         string
+	burnRate         float64
+}
+
+// NewPreviewAlertCommand returns the preview alert command.
+func NewPreviewAlertCommand(app *kingpin.Application) Command {
+	c := &previewAlertCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("preview-alert", "Prints how a firing SLO alert would look (labels and rendered annotations) with a synthetic error budget burn rate, to review the alert content before it pages.")
+	cmd.Arg("slo", "The SLO ID of the alert.").Required().StringVar(&c.sloID)
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("severity", "The SLO alert severity.").Default(alert.PageAlertSeverity.String()).EnumVar(&c.severity, alert.PageAlertSeverity.String(), alert.TicketAlertSeverity.String())
+	cmd.Flag("burn-rate", "The synthetic error budget burn rate of the firing alert (e.g 14.4), if 0 it will use the quick alert burn rate.").Float64Var(&c.burnRate)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (p previewAlertCommand) Name() string { return "preview-alert" }
+func (p previewAlertCommand) Run(ctx context.Context, config RootConfig) error {
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(p.slosExcludeRegex, p.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, p.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := p.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	slos, err := p.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+
+	var slo *prometheus.SLO
+	for _, s := range slos {
+		if s.SLO.ID == p.sloID {
+			s := s
+			slo = &s.SLO
+			break
+		}
+	}
+	if slo == nil {
+		return fmt.Errorf("%q SLO has not been discovered", p.sloID)
+	}
+
+	severity := alert.PageAlertSeverity
+	if p.severity == alert.TicketAlertSeverity.String() {
+		severity = alert.TicketAlertSeverity
+	}
+
+	// By default fire the alert with the quick alert burn rate.
+	burnRate := p.burnRate
+	if burnRate == 0 {
+		alerts, err := alert.AlertGenerator.GenerateMWMBAlerts(ctx, alert.SLO{
+			ID:         slo.ID,
+			TimeWindow: slo.TimeWindow,
+			Objective:  slo.Objective,
+			Windows:    slo.AlertWindows,
+		})
+		if err != nil {
+			return fmt.Errorf("could not generate %q SLO alerts: %w", slo.ID, err)
+		}
+
+		burnRate = alerts.PageQuick.BurnRateFactor
+		if severity == alert.TicketAlertSeverity {
+			burnRate = alerts.TicketQuick.BurnRateFactor
+		}
+	}
+
+	preview, err := prometheus.PreviewSLOAlert(ctx, *slo, severity, burnRate)
+	if err != nil {
+		return err
+	}
+
+	p.printPreview(config.Stdout, burnRate, *preview)
+
+	return nil
+}
+
+func (p previewAlertCommand) printPreview(out io.Writer, burnRate float64, preview prometheus.AlertPreview) {
+	fmt.Fprintf(out, "Alert %s (severity: %s, burn rate: %gx, value: %g)\n", preview.Name, preview.Severity, burnRate, preview.Value)
+
+	printSorted := func(title string, kvs map[string]string) {
+		keys := make([]string, 0, len(kvs))
+		for k := range kvs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintf(out, "\n%s:\n", title)
+		for _, k := range keys {
+			fmt.Fprintf(out, "  %s: %s\n", k, kvs[k])
+		}
+	}
+	printSorted("Labels", preview.Labels)
+	printSorted("Annotations", preview.Annotations)
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+type previewRoutesCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	alertmanagerConfig string
+	slosInput          string
+	slosExcludeRegex   string
+	slosIncludeRegex   string
+	failOnDefaultRoute bool
+}
+
+// NewPreviewRoutesCommand returns the preview routes command.
+func NewPreviewRoutesCommand(app *kingpin.Application) Command {
+	c := &previewRoutesCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("preview-routes", "Prints the Alertmanager receivers where the generated SLO alerts would be routed, simulating the Alertmanager configuration routing tree.")
+	cmd.Flag("alertmanager-config", "The Alertmanager configuration YAML file path.").Short('c').Required().StringVar(&c.alertmanagerConfig)
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("fail-on-default-route", "Fails if any SLO alert doesn't match any route and is routed to the root route default receiver.").BoolVar(&c.failOnDefaultRoute)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (p previewRoutesCommand) Name() string { return "preview-routes" }
+func (p previewRoutesCommand) Run(ctx context.Context, config RootConfig) error {
+	amConfigData, err := os.ReadFile(p.alertmanagerConfig)
+	if err != nil {
+		return fmt.Errorf("could not read Alertmanager configuration: %w", err)
+	}
+
+	route, err := prometheus.LoadAlertmanagerRoute(amConfigData)
+	if err != nil {
+		return fmt.Errorf("invalid Alertmanager configuration: %w", err)
+	}
+
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(p.slosExcludeRegex, p.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, p.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := p.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	slos, err := p.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(config.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SLO\tALERT\tSEVERITY\tRECEIVERS")
+
+	routed := 0
+	defaultRouted := 0
+	for _, s := range slos {
+		slo := s.SLO
+		alerts, err := alert.AlertGenerator.GenerateMWMBAlerts(ctx, alert.SLO{
+			ID:         slo.ID,
+			TimeWindow: slo.TimeWindow,
+			Objective:  slo.Objective,
+			Windows:    slo.AlertWindows,
+		})
+		if err != nil {
+			return fmt.Errorf("could not generate %q SLO alerts: %w", slo.ID, err)
+		}
+
+		// Fire the enabled alerts with the quick alert burn rate.
+		severities := []struct {
+			severity alert.Severity
+			disabled bool
+			burnRate float64
+		}{
+			{severity: alert.PageAlertSeverity, disabled: slo.PageAlertMeta.Disable, burnRate: alerts.PageQuick.BurnRateFactor},
+			{severity: alert.TicketAlertSeverity, disabled: slo.TicketAlertMeta.Disable, burnRate: alerts.TicketQuick.BurnRateFactor},
+		}
+		for _, sev := range severities {
+			if sev.disabled {
+				continue
+			}
+
+			preview, err := prometheus.PreviewSLOAlert(ctx, slo, sev.severity, sev.burnRate)
+			if err != nil {
+				return fmt.Errorf("could not preview %q SLO %s alert: %w", slo.ID, sev.severity, err)
+			}
+
+			chains := route.Route(preview.Labels)
+			receivers := make([]string, 0, len(chains))
+			for _, c := range chains {
+				receivers = append(receivers, strings.Join(c, " > "))
+				if len(c) == 1 {
+					defaultRouted++
+				}
+			}
+
+			routed++
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", slo.ID, preview.Name, sev.severity, strings.Join(receivers, ", "))
+		}
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write alert routes: %w", err)
+	}
+
+	config.Logger.WithValues(log.Kv{"alerts": routed, "default-routed": defaultRouted}).Infof("Alert routes previewed")
+
+	if p.failOnDefaultRoute && defaultRouted > 0 {
+		return fmt.Errorf("%d SLO alerts are routed to the root route default receiver", defaultRouted)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	progressNone = "none"
+	progressBar  = "bar"
+	progressJSON = "json"
+)
+
+const (
+	progressBarWidth     = 30
+	progressJSONInterval = 2 * time.Second
+)
+
+// progressReporter knows how to report the progress of a long run.
+type progressReporter interface {
+	// Step reports that a new item is being processed.
+	Step(item string)
+	// Finish ends the progress report.
+	Finish()
+}
+
+// newProgressReporter returns a progress reporter of the required mode that will report
+// the processing of `total` items to the writer.
+func newProgressReporter(mode string, out io.Writer, unit string, total int) progressReporter {
+	switch mode {
+	case progressBar:
+		return &barProgressReporter{out: out, unit: unit, total: total}
+	case progressJSON:
+		return &jsonProgressReporter{out: out, unit: unit, total: total, start: time.Now()}
+	default:
+		return noopProgressReporter{}
+	}
+}
+
+type noopProgressReporter struct{}
+
+func (noopProgressReporter) Step(item string) {}
+func (noopProgressReporter) Finish()          {}
+
+// barProgressReporter renders a progress bar redrawing the same line, meant to be used on TTYs.
+type barProgressReporter struct {
+	out   io.Writer
+	unit  string
+	total int
+	done  int
+}
+
+func (b *barProgressReporter) Step(item string) {
+	b.done++
+
+	filled := progressBarWidth
+	percent := 100
+	if b.total > 0 {
+		filled = progressBarWidth * b.done / b.total
+		percent = 100 * b.done / b.total
+	}
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	// Clear the line before redrawing, the item can be shorter than the previous one.
+	fmt.Fprintf(b.out, "\r\033[K[%s] %3d%% %d/%d %s (%s)", bar, percent, b.done, b.total, b.unit, item)
+}
+
+func (b *barProgressReporter) Finish() {
+	fmt.Fprintln(b.out)
+}
+
+// jsonProgressEvent is the progress event written by the JSON progress reporter.
+type jsonProgressEvent struct {
+	Unit           string  `json:"unit"`
+	Done           int     `json:"done"`
+	Total          int     `json:"total"`
+	Percent        int     `json:"percent"`
+	Item           string  `json:"item,omitempty"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	Finished       bool    `json:"finished"`
+}
+
+// jsonProgressReporter writes periodic JSON progress events (one per line), meant to be
+// used on CI logs.
+type jsonProgressReporter struct {
+	out      io.Writer
+	unit     string
+	total    int
+	done     int
+	start    time.Time
+	lastSent time.Time
+}
+
+func (j *jsonProgressReporter) Step(item string) {
+	j.done++
+
+	// Don't flood the logs, only report periodically.
+	if time.Since(j.lastSent) < progressJSONInterval {
+		return
+	}
+	j.send(item, false)
+}
+
+func (j *jsonProgressReporter) Finish() {
+	j.send("", true)
+}
+
+func (j *jsonProgressReporter) send(item string, finished bool) {
+	percent := 100
+	if j.total > 0 {
+		percent = 100 * j.done / j.total
+	}
+
+	data, err := json.Marshal(jsonProgressEvent{
+		Unit:           j.unit,
+		Done:           j.done,
+		Total:          j.total,
+		Percent:        percent,
+		Item:           item,
+		ElapsedSeconds: time.Since(j.start).Seconds(),
+		Finished:       finished,
+	})
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(j.out, string(data))
+	j.lastSent = time.Now()
+}
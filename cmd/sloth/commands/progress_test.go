@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressReporter(t *testing.T) {
+	tests := map[string]struct {
+		mode   string
+		total  int
+		items  []string
+		expOut string
+	}{
+		"None mode shouldn't report anything.": {
+			mode:   progressNone,
+			total:  2,
+			items:  []string{"a.yaml", "b.yaml"},
+			expOut: "",
+		},
+
+		"Unknown mode shouldn't report anything.": {
+			mode:   "unknown",
+			total:  2,
+			items:  []string{"a.yaml", "b.yaml"},
+			expOut: "",
+		},
+
+		"Bar mode should redraw the bar on every step and end the line on finish.": {
+			mode:  progressBar,
+			total: 2,
+			items: []string{"a.yaml", "b.yaml"},
+			expOut: "\r\033[K[" + strings.Repeat("=", 15) + strings.Repeat(" ", 15) + "]  50% 1/2 files (a.yaml)" +
+				"\r\033[K[" + strings.Repeat("=", 30) + "] 100% 2/2 files (b.yaml)\n",
+		},
+
+		"Bar mode without total should render a full bar.": {
+			mode:   progressBar,
+			total:  0,
+			items:  []string{"a.yaml"},
+			expOut: "\r\033[K[" + strings.Repeat("=", 30) + "] 100% 1/0 files (a.yaml)\n",
+		},
+
+		"Bar mode with more steps than the total shouldn't overflow the bar.": {
+			mode:   progressBar,
+			total:  1,
+			items:  []string{"a.yaml", "b.yaml"},
+			expOut: "\r\033[K[" + strings.Repeat("=", 30) + "] 100% 1/1 files (a.yaml)\r\033[K[" + strings.Repeat("=", 30) + "] 200% 2/1 files (b.yaml)\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := newProgressReporter(test.mode, &out, "files", test.total)
+			for _, item := range test.items {
+				p.Step(item)
+			}
+			p.Finish()
+
+			assert.Equal(t, test.expOut, out.String())
+		})
+	}
+}
+
+func TestJSONProgressReporter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var out bytes.Buffer
+	p := newProgressReporter(progressJSON, &out, "files", 4)
+	p.Step("a.yaml")
+	// These are inside the report interval, they shouldn't be reported.
+	p.Step("b.yaml")
+	p.Step("c.yaml")
+	p.Finish()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(lines, 2)
+
+	gotEvents := []jsonProgressEvent{}
+	for _, l := range lines {
+		var ev jsonProgressEvent
+		require.NoError(json.Unmarshal([]byte(l), &ev))
+		assert.GreaterOrEqual(ev.ElapsedSeconds, 0.0)
+		ev.ElapsedSeconds = 0
+		gotEvents = append(gotEvents, ev)
+	}
+
+	expEvents := []jsonProgressEvent{
+		{Unit: "files", Done: 1, Total: 4, Percent: 25, Item: "a.yaml"},
+		{Unit: "files", Done: 3, Total: 4, Percent: 75, Finished: true},
+	}
+	assert.Equal(expEvents, gotEvents)
+}
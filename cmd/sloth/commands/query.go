@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"text/tabwriter"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+type queryCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	query            string
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+}
+
+// NewQueryCommand returns the query command.
+func NewQueryCommand(app *kingpin.Application) Command {
+	c := &queryCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("query", "Prints the SLOs of the discovered SLO manifests that match a query (e.g `labels.owner == team-x and page_alert.disabled == true`).")
+	cmd.Arg("query", "The query, conditions in `{field} {operator} {value}` form combined with `and`/`or`.").Required().StringVar(&c.query)
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (q queryCommand) Name() string { return "query" }
+func (q queryCommand) Run(ctx context.Context, config RootConfig) error {
+	query, err := prometheus.ParseSLOQuery(q.query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	// Set up files discovery filter regex.
+	var excludeRegex *regexp.Regexp
+	var includeRegex *regexp.Regexp
+	if q.slosExcludeRegex != "" {
+		r, err := regexp.Compile(q.slosExcludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid exclude regex: %w", err)
+		}
+		excludeRegex = r
+	}
+	if q.slosIncludeRegex != "" {
+		r, err := regexp.Compile(q.slosIncludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid include regex: %w", err)
+		}
+		includeRegex = r
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, q.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := q.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	slos, err := q.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(config.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tSLO\tOBJECTIVE\tFILE")
+
+	matched := 0
+	for _, s := range slos {
+		if !query.Match(s.SLO) {
+			continue
+		}
+
+		matched++
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", s.SLO.Service, s.SLO.Name, s.SLO.Objective, s.Path)
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write query result: %w", err)
+	}
+
+	config.Logger.WithValues(log.Kv{"slos": len(slos), "matched": matched}).Infof("Query executed")
+
+	return nil
+}
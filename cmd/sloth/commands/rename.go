@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+type renameServiceCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	service          string
+	newService       string
+	slo              string
+	newSLO           string
+	dryRun           bool
+	bridgeRulesOut   string
+}
+
+// NewRenameServiceCommand returns the rename service command.
+func NewRenameServiceCommand(app *kingpin.Application) Command {
+	c := &renameServiceCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("rename-service", "Renames a service or an SLO across the SLO spec files and reports the generated rules changes.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("service", "The service to rename.").Required().StringVar(&c.service)
+	cmd.Flag("to", "The new service name, if not set the service will not be renamed.").StringVar(&c.newService)
+	cmd.Flag("slo", "The service SLO to rename, if not set all the service SLOs are affected by the service rename.").StringVar(&c.slo)
+	cmd.Flag("slo-to", "The new SLO name.").StringVar(&c.newSLO)
+	cmd.Flag("dry-run", "Reports the changes without modifying the SLO spec files.").BoolVar(&c.dryRun)
+	cmd.Flag("bridge-rules-out", "If set, it will generate the Prometheus rules that keep recording the renamed SLOs SLI error series with the old labels on this file path, for continuity of the historical error budget data.").StringVar(&c.bridgeRulesOut)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (r renameServiceCommand) Name() string { return "rename-service" }
+func (r renameServiceCommand) Run(ctx context.Context, config RootConfig) error {
+	rename := prometheus.SpecRename{
+		Service:    r.service,
+		NewService: r.newService,
+		SLO:        r.slo,
+		NewSLO:     r.newSLO,
+	}
+	err := rename.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid rename: %w", err)
+	}
+
+	// Set up files discovery filter regex.
+	var excludeRegex *regexp.Regexp
+	var includeRegex *regexp.Regexp
+	if r.slosExcludeRegex != "" {
+		re, err := regexp.Compile(r.slosExcludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid exclude regex: %w", err)
+		}
+		excludeRegex = re
+	}
+	if r.slosIncludeRegex != "" {
+		re, err := regexp.Compile(r.slosIncludeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid include regex: %w", err)
+		}
+		includeRegex = re
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, r.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+
+	// Rename all the files before writing, so we don't leave a partial rename on errors.
+	renamedPaths := []string{}
+	renamedFiles := map[string][]byte{}
+	renamedSLOs := []prometheus.RenamedSLO{}
+	for _, path := range sloPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %q SLOs spec file data: %w", path, err)
+		}
+
+		newData, renamed, err := prometheus.RenameSpecYAML(data, rename)
+		if err != nil {
+			return fmt.Errorf("could not rename %q SLOs spec file: %w", path, err)
+		}
+		if len(renamed) == 0 {
+			continue
+		}
+		renamedPaths = append(renamedPaths, path)
+		renamedFiles[path] = newData
+		renamedSLOs = append(renamedSLOs, renamed...)
+
+		// Report the changes.
+		fmt.Fprintf(config.Stdout, "%s:\n", path)
+		for _, slo := range renamed {
+			fmt.Fprintf(config.Stdout, "  %s -> %s\n", slo.OldID(), slo.NewID())
+			for _, c := range slo.Changes() {
+				fmt.Fprintf(config.Stdout, "    %s -> %s\n", c[0], c[1])
+			}
+		}
+	}
+
+	if len(renamedSLOs) == 0 {
+		return fmt.Errorf("0 SLOs of %q service have been found", r.service)
+	}
+
+	if r.bridgeRulesOut != "" {
+		err := r.generateBridgeRules(ctx, config.Logger, config.HTTPClient, renamedPaths, renamedFiles, renamedSLOs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.dryRun {
+		config.Logger.WithValues(log.Kv{"files": len(renamedPaths), "slos": len(renamedSLOs)}).Infof("Dry run, SLO spec files not modified")
+		return nil
+	}
+
+	for _, path := range renamedPaths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("could not stat %q SLOs spec file: %w", path, err)
+		}
+
+		err = os.WriteFile(path, renamedFiles[path], info.Mode())
+		if err != nil {
+			return fmt.Errorf("could not write %q SLOs spec file: %w", path, err)
+		}
+	}
+
+	config.Logger.WithValues(log.Kv{"files": len(renamedPaths), "slos": len(renamedSLOs)}).Infof("SLOs renamed")
+
+	return nil
+}
+
+// generateBridgeRules generates the renamed SLOs bridge rules based on the SLI recording rules
+// of the renamed SLO specs.
+func (r renameServiceCommand) generateBridgeRules(ctx context.Context, logger log.Logger, httpClient *http.Client, renamedPaths []string, renamedFiles map[string][]byte, renamedSLOs []prometheus.RenamedSLO) error {
+	pluginRepo, err := r.createPluginLoader(ctx, logger, httpClient)
+	if err != nil {
+		return err
+	}
+
+	// Load the renamed SLOs.
+	slos, err := r.loadModifiedSpecsSLOs(ctx, logger, pluginRepo, renamedPaths, renamedFiles)
+	if err != nil {
+		return err
+	}
+
+	renamedByID := map[string]prometheus.RenamedSLO{}
+	for _, slo := range renamedSLOs {
+		renamedByID[slo.NewID()] = slo
+	}
+	renamedSpecSLOs := []prometheus.SLO{}
+	for _, slo := range slos {
+		if _, ok := renamedByID[slo.ID]; ok {
+			renamedSpecSLOs = append(renamedSpecSLOs, slo)
+		}
+	}
+	if len(renamedSpecSLOs) == 0 {
+		return fmt.Errorf("could not load the renamed SLOs to generate the bridge rules")
+	}
+
+	// Generate the bridge rules from the renamed SLOs SLI recording rules.
+	info := info.Info{
+		Version: info.Version,
+		Mode:    info.ModeCLIGenPrometheus,
+		Spec:    prometheusv1.Version,
+	}
+	result, err := generateRules(ctx, logger, info, generateOptions{disableAlerts: true}, prometheus.SLOGroup{SLOs: renamedSpecSLOs})
+	if err != nil {
+		return err
+	}
+
+	storageSLOs := make([]prometheus.StorageSLO, 0, len(result.PrometheusSLOs))
+	for _, s := range result.PrometheusSLOs {
+		renamed := renamedByID[s.SLO.ID]
+		storageSLOs = append(storageSLOs, prometheus.StorageSLO{
+			// The bridge rules are the SLI recording rules of the old SLO.
+			SLO:   prometheus.SLO{ID: renamed.OldID()},
+			Rules: prometheus.SLORules{SLIErrorRecRules: prometheus.GenerateRenameBridgeRules(renamed, s.SLORules.SLIErrorRecRules)},
+		})
+	}
+
+	f, err := os.Create(r.bridgeRulesOut)
+	if err != nil {
+		return fmt.Errorf("could not create bridge rules out file: %w", err)
+	}
+	defer f.Close()
+
+	repo := prometheus.NewIOWriterGroupedRulesYAMLRepo(f, prometheus.RuleGroupsMeta{}, logger)
+	err = repo.StoreSLOs(ctx, storageSLOs)
+	if err != nil {
+		return fmt.Errorf("could not store bridge rules: %w", err)
+	}
+
+	return nil
+}
+
+// loadModifiedSpecsSLOs loads the SLOs of the modified (not written yet) SLO spec files data, the
+// specs that can't be loaded are ignored.
+func (s specLoadFlags) loadModifiedSpecsSLOs(ctx context.Context, logger log.Logger, pluginRepo *prometheus.FileSLIPluginRepo, paths []string, files map[string][]byte) ([]prometheus.SLO, error) {
+	specLoaders, err := s.specLoaders(logger, pluginRepo, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	slos := []prometheus.SLO{}
+	tplData := newSpecTemplateData(s.vars)
+	for _, path := range paths {
+		slxData, err := specloader.RenderTemplates(files[path], tplData)
+		if err != nil {
+			return nil, fmt.Errorf("could not render %q SLOs spec templates: %w", path, err)
+		}
+
+		splittedSLOsData, err := specloader.ReadAll(bytes.NewReader(slxData))
+		if err != nil {
+			return nil, fmt.Errorf("could not split %q SLOs spec file data: %w", path, err)
+		}
+
+		for _, data := range splittedSLOsData {
+			if spec, err := loadSpec(ctx, specLoaders, data); err == nil {
+				slos = append(slos, spec.sloGroup.SLOs...)
+			}
+		}
+	}
+
+	return slos, nil
+}
@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+type renameLabelCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	label            string
+	newLabel         string
+	dryRun           bool
+	bridgeRulesOut   string
+}
+
+// NewRenameLabelCommand returns the rename label command.
+func NewRenameLabelCommand(app *kingpin.Application) Command {
+	c := &renameLabelCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("rename-label", "Renames a label (e.g team to owner) across the SLO spec files labels, cost labels and alerting labels.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("label", "The label to rename.").Required().StringVar(&c.label)
+	cmd.Flag("to", "The new label name.").Required().StringVar(&c.newLabel)
+	cmd.Flag("dry-run", "Reports the changes without modifying the SLO spec files.").BoolVar(&c.dryRun)
+	cmd.Flag("bridge-rules-out", "If set, it will generate the transitional Prometheus rules that keep recording the relabeled SLOs SLI error series with the old label on this file path, so the dashboards and alert routes of the old label keep working during the migration.").StringVar(&c.bridgeRulesOut)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+
+	return c
+}
+
+func (r renameLabelCommand) Name() string { return "rename-label" }
+func (r renameLabelCommand) Run(ctx context.Context, config RootConfig) error {
+	relabel := prometheus.SpecRelabel{
+		Label:    r.label,
+		NewLabel: r.newLabel,
+	}
+	err := relabel.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid relabel: %w", err)
+	}
+
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(r.slosExcludeRegex, r.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, r.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+
+	// Relabel all the files before writing, so we don't leave a partial relabel on errors.
+	relabeledPaths := []string{}
+	relabeledFiles := map[string][]byte{}
+	relabeledSLOs := []prometheus.RelabeledSLO{}
+	for _, path := range sloPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %q SLOs spec file data: %w", path, err)
+		}
+
+		newData, relabeled, err := prometheus.RelabelSpecYAML(data, relabel)
+		if err != nil {
+			return fmt.Errorf("could not relabel %q SLOs spec file: %w", path, err)
+		}
+		if len(relabeled) == 0 {
+			continue
+		}
+		relabeledPaths = append(relabeledPaths, path)
+		relabeledFiles[path] = newData
+		relabeledSLOs = append(relabeledSLOs, relabeled...)
+
+		// Report the changes.
+		fmt.Fprintf(config.Stdout, "%s:\n", path)
+		for _, slo := range relabeled {
+			fmt.Fprintf(config.Stdout, "  %s: %s -> %s\n", slo.ID(), relabel.Label, relabel.NewLabel)
+			if slo.AlertLabel {
+				fmt.Fprintf(config.Stdout, "    The alerting labels changed, the Alertmanager routes of the %q label need to be updated.\n", relabel.Label)
+			}
+		}
+	}
+
+	if len(relabeledSLOs) == 0 {
+		return fmt.Errorf("0 SLOs with %q label have been found", r.label)
+	}
+
+	if r.bridgeRulesOut != "" {
+		err := r.generateBridgeRules(ctx, config.Logger, config.HTTPClient, relabel, relabeledPaths, relabeledFiles, relabeledSLOs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.dryRun {
+		config.Logger.WithValues(log.Kv{"files": len(relabeledPaths), "slos": len(relabeledSLOs)}).Infof("Dry run, SLO spec files not modified")
+		return nil
+	}
+
+	for _, path := range relabeledPaths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("could not stat %q SLOs spec file: %w", path, err)
+		}
+
+		err = os.WriteFile(path, relabeledFiles[path], info.Mode())
+		if err != nil {
+			return fmt.Errorf("could not write %q SLOs spec file: %w", path, err)
+		}
+	}
+
+	config.Logger.WithValues(log.Kv{"files": len(relabeledPaths), "slos": len(relabeledSLOs)}).Infof("SLOs relabeled")
+
+	return nil
+}
+
+// generateBridgeRules generates the relabeled SLOs bridge rules based on the SLI recording rules
+// of the relabeled SLO specs.
+func (r renameLabelCommand) generateBridgeRules(ctx context.Context, logger log.Logger, httpClient *http.Client, relabel prometheus.SpecRelabel, relabeledPaths []string, relabeledFiles map[string][]byte, relabeledSLOs []prometheus.RelabeledSLO) error {
+	pluginRepo, err := r.createPluginLoader(ctx, logger, httpClient)
+	if err != nil {
+		return err
+	}
+
+	// Load the relabeled SLOs.
+	slos, err := r.loadModifiedSpecsSLOs(ctx, logger, pluginRepo, relabeledPaths, relabeledFiles)
+	if err != nil {
+		return err
+	}
+
+	// Only the SLOs with the label on their series need bridge rules.
+	seriesRelabeled := map[string]struct{}{}
+	for _, slo := range relabeledSLOs {
+		if slo.SeriesLabel {
+			seriesRelabeled[slo.ID()] = struct{}{}
+		}
+	}
+	relabeledSpecSLOs := []prometheus.SLO{}
+	for _, slo := range slos {
+		if _, ok := seriesRelabeled[slo.ID]; ok {
+			relabeledSpecSLOs = append(relabeledSpecSLOs, slo)
+		}
+	}
+	if len(relabeledSpecSLOs) == 0 {
+		return fmt.Errorf("could not load the relabeled SLOs with the label on their series to generate the bridge rules")
+	}
+
+	// Generate the bridge rules from the relabeled SLOs SLI recording rules.
+	info := info.Info{
+		Version: info.Version,
+		Mode:    info.ModeCLIGenPrometheus,
+		Spec:    prometheusv1.Version,
+	}
+	result, err := generateRules(ctx, logger, info, generateOptions{disableAlerts: true}, prometheus.SLOGroup{SLOs: relabeledSpecSLOs})
+	if err != nil {
+		return err
+	}
+
+	storageSLOs := make([]prometheus.StorageSLO, 0, len(result.PrometheusSLOs))
+	for _, s := range result.PrometheusSLOs {
+		storageSLOs = append(storageSLOs, prometheus.StorageSLO{
+			SLO:   prometheus.SLO{ID: fmt.Sprintf("%s-%s", s.SLO.ID, relabel.Label)},
+			Rules: prometheus.SLORules{SLIErrorRecRules: prometheus.GenerateRelabelBridgeRules(relabel, s.SLO.ID, s.SLORules.SLIErrorRecRules)},
+		})
+	}
+
+	f, err := os.Create(r.bridgeRulesOut)
+	if err != nil {
+		return fmt.Errorf("could not create bridge rules out file: %w", err)
+	}
+	defer f.Close()
+
+	repo := prometheus.NewIOWriterGroupedRulesYAMLRepo(f, prometheus.RuleGroupsMeta{}, logger)
+	err = repo.StoreSLOs(ctx, storageSLOs)
+	if err != nil {
+		return fmt.Errorf("could not store bridge rules: %w", err)
+	}
+
+	return nil
+}
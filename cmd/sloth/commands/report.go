@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+const (
+	sloReportFormatTable    = "table"
+	sloReportFormatJSON     = "json"
+	sloReportFormatMarkdown = "markdown"
+)
+
+type reportCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	budgetNotifyFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	prometheusURL    string
+	format           string
+}
+
+// NewReportCommand returns the report command.
+func NewReportCommand(app *kingpin.Application) Command {
+	c := &reportCommand{specLoadFlags: newSpecLoadFlags()}
+	cmd := app.Command("report", "Reports the current SLI, remaining error budget and burn rate of the SLO manifests SLOs, querying the generated recording rules on a live Prometheus. Optionally, it notifies the remaining error budget threshold crossings since the previous report to a webhook.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("prometheus-url", "The Prometheus API URL that evaluates the generated recording rules.").Default("http://127.0.0.1:9090").StringVar(&c.prometheusURL)
+	cmd.Flag("format", "The report output format.").Default(sloReportFormatTable).EnumVar(&c.format, sloReportFormatTable, sloReportFormatJSON, sloReportFormatMarkdown)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+	c.budgetNotifyFlags.register(cmd)
+
+	return c
+}
+
+func (r reportCommand) Name() string { return "report" }
+func (r reportCommand) Run(ctx context.Context, config RootConfig) error {
+	// A scheduled report needs the previous execution observations to detect the budget threshold crossings.
+	if r.budgetWebhookURL != "" && r.budgetStateFile == "" {
+		return fmt.Errorf("budget state file is required by the budget webhook")
+	}
+	budgetNotifier, err := r.newBudgetNotifier(config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	// Set up files discovery filter regex.
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(r.slosExcludeRegex, r.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, r.slosInput)
+	if err != nil {
+		return fmt.Errorf("could not discover files: %w", err)
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
+	}
+
+	// Load plugins.
+	pluginRepo, err := r.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	fileSLOs, err := r.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+	if err != nil {
+		return err
+	}
+	slos := make([]prometheus.SLO, 0, len(fileSLOs))
+	for _, s := range fileSLOs {
+		slos = append(slos, s.SLO)
+	}
+
+	api, err := newPrometheusAPI(r.prometheusURL, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reports, err := prometheus.ReportSLOs(ctx, promAPIValueQuerier{api: api}, slos, now)
+	if err != nil {
+		return fmt.Errorf("could not report SLOs: %w", err)
+	}
+
+	if budgetNotifier != nil {
+		err := budgetNotifier.notify(ctx, config.Logger, slos, reports, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch r.format {
+	case sloReportFormatJSON:
+		err = writeJSONSLOReport(config.Stdout, reports)
+	case sloReportFormatMarkdown:
+		err = writeMarkdownSLOReport(config.Stdout, reports)
+	default:
+		err = writeTableSLOReport(config.Stdout, reports)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write SLOs report: %w", err)
+	}
+	config.Logger.WithValues(log.Kv{"slos": len(reports)}).Infof("SLOs reported")
+
+	return nil
+}
+
+func writeTableSLOReport(out io.Writer, reports []prometheus.SLOReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tSLO\tOBJECTIVE\tSLI\tERROR BUDGET REMAINING\tBURN RATE")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Service, r.SLOName, fmtReportPercent(r.Objective), fmtReportPercent(r.SLI), fmtReportPercent(r.ErrorBudgetRemaining), fmtReportBurnRate(r.BurnRate))
+	}
+
+	return w.Flush()
+}
+
+func writeMarkdownSLOReport(out io.Writer, reports []prometheus.SLOReport) error {
+	_, err := fmt.Fprintln(out, "| Service | SLO | Objective | SLI | Error budget remaining | Burn rate |\n| --- | --- | --- | --- | --- | --- |")
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		_, err := fmt.Fprintf(out, "| %s | %s | %s | %s | %s | %s |\n", r.Service, r.SLOName, fmtReportPercent(r.Objective), fmtReportPercent(r.SLI), fmtReportPercent(r.ErrorBudgetRemaining), fmtReportBurnRate(r.BurnRate))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type jsonSLOReport struct {
+	ID                   string   `json:"id"`
+	Service              string   `json:"service"`
+	SLO                  string   `json:"slo"`
+	Objective            *float64 `json:"objective"`
+	SLI                  *float64 `json:"sli"`
+	ErrorBudgetRemaining *float64 `json:"errorBudgetRemaining"`
+	BurnRate             *float64 `json:"burnRate"`
+}
+
+func writeJSONSLOReport(out io.Writer, reports []prometheus.SLOReport) error {
+	jsonReports := make([]jsonSLOReport, 0, len(reports))
+	for _, r := range reports {
+		jsonReports = append(jsonReports, jsonSLOReport{
+			ID:                   r.SLOID,
+			Service:              r.Service,
+			SLO:                  r.SLOName,
+			Objective:            r.Objective,
+			SLI:                  r.SLI,
+			ErrorBudgetRemaining: r.ErrorBudgetRemaining,
+			BurnRate:             r.BurnRate,
+		})
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonReports)
+}
+
+// fmtReportPercent formats a report ratio as a percent, `-` if there is no data.
+func fmtReportPercent(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f%%", *v*100)
+}
+
+// fmtReportBurnRate formats a report burn rate, `-` if there is no data.
+func fmtReportBurnRate(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", *v)
+}
+
+// promAPIValueQuerier executes the Prometheus instant queries using the Prometheus HTTP API.
+type promAPIValueQuerier struct {
+	api promv1.API
+}
+
+func (p promAPIValueQuerier) QueryValue(ctx context.Context, query string, ts time.Time) (float64, bool, error) {
+	value, _, err := p.api.Query(ctx, query, ts)
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch v := value.(type) {
+	case prommodel.Vector:
+		switch len(v) {
+		case 0:
+			return 0, false, nil
+		case 1:
+			return float64(v[0].Value), true, nil
+		}
+		return 0, false, fmt.Errorf("query returned %d series instead of one", len(v))
+	case *prommodel.Scalar:
+		return float64(v.Value), true, nil
+	}
+
+	return 0, false, fmt.Errorf("unsupported %T query result", value)
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/release"
+)
+
+type selfUpdateCommand struct {
+	source         string
+	publicKey      string
+	dryRun         bool
+	allowDowngrade bool
+}
+
+// NewSelfUpdateCommand returns the self-update command.
+func NewSelfUpdateCommand(app *kingpin.Application) Command {
+	c := &selfUpdateCommand{}
+	cmd := app.Command("self-update", "Replaces the running binary with the release binary of the platform, after verifying the signed release checksums.")
+	cmd.Flag("source", "The release artifacts location, a local directory (e.g air-gapped environments) or an HTTP(S) base URL.").Required().StringVar(&c.source)
+	cmd.Flag("public-key", "The PEM Ed25519 public key file used to verify the release checksums signature.").Required().StringVar(&c.publicKey)
+	cmd.Flag("dry-run", "Only verifies the release artifacts, the binary will not be replaced.").BoolVar(&c.dryRun)
+	cmd.Flag("allow-downgrade", "Allows updating to a release that is not newer than the running version (e.g rollbacks, development builds).").BoolVar(&c.allowDowngrade)
+
+	return c
+}
+
+func (s selfUpdateCommand) Name() string { return "self-update" }
+func (s selfUpdateCommand) Run(ctx context.Context, config RootConfig) error {
+	artifactName, err := release.ArtifactName(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	logger := config.Logger.WithValues(log.Kv{"source": s.source, "artifact": artifactName, "current-version": info.Version})
+
+	pubKeyData, err := os.ReadFile(s.publicKey)
+	if err != nil {
+		return fmt.Errorf("could not read public key file: %w", err)
+	}
+	pubKey, err := release.ParsePublicKey(pubKeyData)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	// Get the release artifacts and verify them.
+	getter := releaseArtifactGetter{source: s.source, httpClient: config.HTTPClient}
+	checksums, err := getter.Get(ctx, release.ChecksumsFileName)
+	if err != nil {
+		return err
+	}
+	signature, err := getter.Get(ctx, release.ChecksumsSignatureFileName)
+	if err != nil {
+		return err
+	}
+	verifier, err := release.NewVerifier(pubKey, checksums, signature)
+	if err != nil {
+		return fmt.Errorf("could not verify release checksums: %w", err)
+	}
+
+	// Old signed releases could be served to downgrade the binary to a vulnerable version.
+	logger = logger.WithValues(log.Kv{"new-version": verifier.Version()})
+	cmp, err := release.CompareVersions(verifier.Version(), info.Version)
+	switch {
+	case err != nil && !s.allowDowngrade:
+		return fmt.Errorf("could not compare %q release version with %q running version, use --allow-downgrade to update: %w", verifier.Version(), info.Version, err)
+	case err == nil && cmp <= 0 && !s.allowDowngrade:
+		return fmt.Errorf("%q release version is not newer than %q running version, use --allow-downgrade to update", verifier.Version(), info.Version)
+	case err != nil || cmp <= 0:
+		logger.Warningf("Release version is not newer than the running version, updating due to allowed downgrades")
+	}
+
+	binary, err := getter.Get(ctx, artifactName)
+	if err != nil {
+		return err
+	}
+	checksum, err := verifier.Verify(artifactName, binary)
+	if err != nil {
+		return fmt.Errorf("could not verify release artifact: %w", err)
+	}
+
+	logger = logger.WithValues(log.Kv{"sha256": checksum})
+	if s.dryRun {
+		logger.Infof("Release artifact verified, binary not replaced due to dry run")
+		return nil
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not get the binary path: %w", err)
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("could not get the binary path: %w", err)
+	}
+
+	err = replaceBinary(path, binary)
+	if err != nil {
+		return fmt.Errorf("could not replace %q binary: %w", path, err)
+	}
+
+	logger.WithValues(log.Kv{"path": path}).Infof("Binary replaced with the verified release artifact")
+
+	return nil
+}
+
+// replaceBinary replaces the binary on the same directory, so the final rename is atomic.
+func replaceBinary(path string, data []byte) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	newPath := path + ".new"
+	err = os.WriteFile(newPath, data, fi.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("could not write new binary: %w", err)
+	}
+
+	// Running binaries can't be overwritten on Windows, but they can be renamed.
+	oldPath := path + ".old"
+	err = os.Rename(path, oldPath)
+	if err != nil {
+		_ = os.Remove(newPath)
+		return fmt.Errorf("could not move current binary: %w", err)
+	}
+
+	err = os.Rename(newPath, path)
+	if err != nil {
+		// Try restoring the current binary.
+		_ = os.Rename(oldPath, path)
+		_ = os.Remove(newPath)
+		return fmt.Errorf("could not move new binary: %w", err)
+	}
+
+	// On Windows the running binary can't be removed, it will be replaced on the next update.
+	_ = os.Remove(oldPath)
+
+	return nil
+}
+
+// releaseArtifactGetter gets the release artifacts from a local directory or an HTTP(S) URL.
+type releaseArtifactGetter struct {
+	source     string
+	httpClient *http.Client
+}
+
+func (r releaseArtifactGetter) Get(ctx context.Context, name string) ([]byte, error) {
+	location := filepath.Join(r.source, name)
+	if isHTTPLocation(r.source) {
+		location = strings.TrimSuffix(r.source, "/") + "/" + name
+	}
+
+	data, err := readLocation(ctx, r.httpClient, location)
+	if err != nil {
+		return nil, fmt.Errorf("could not get %q release artifact: %w", name, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/release"
+)
+
+func TestSelfUpdateVersion(t *testing.T) {
+	tests := map[string]struct {
+		runningVersion string
+		releaseVersion string
+		allowDowngrade bool
+		expErr         bool
+	}{
+		"A newer release should be verified.": {
+			runningVersion: "v0.7.0",
+			releaseVersion: "v0.8.0",
+		},
+
+		"The same release should fail.": {
+			runningVersion: "v0.7.0",
+			releaseVersion: "v0.7.0",
+			expErr:         true,
+		},
+
+		"An older release should fail.": {
+			runningVersion: "v0.7.0",
+			releaseVersion: "v0.6.0",
+			expErr:         true,
+		},
+
+		"An older release with allowed downgrades should be verified.": {
+			runningVersion: "v0.7.0",
+			releaseVersion: "v0.6.0",
+			allowDowngrade: true,
+		},
+
+		"A release on a development build should fail.": {
+			runningVersion: "dev",
+			releaseVersion: "v0.7.0",
+			expErr:         true,
+		},
+
+		"A release on a development build with allowed downgrades should be verified.": {
+			runningVersion: "dev",
+			releaseVersion: "v0.7.0",
+			allowDowngrade: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			// Create the signed release.
+			artifactName, err := release.ArtifactName(runtime.GOOS, runtime.GOARCH)
+			require.NoError(err)
+			privKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+			pubKeyData, err := x509.MarshalPKIXPublicKey(privKey.Public())
+			require.NoError(err)
+
+			dir := t.TempDir()
+			binary := []byte("sloth binary")
+			checksums := []byte(fmt.Sprintf("# version: %s\n%x  %s\n", test.releaseVersion, sha256.Sum256(binary), artifactName))
+			require.NoError(os.WriteFile(filepath.Join(dir, artifactName), binary, 0644))
+			require.NoError(os.WriteFile(filepath.Join(dir, release.ChecksumsFileName), checksums, 0644))
+			require.NoError(os.WriteFile(filepath.Join(dir, release.ChecksumsSignatureFileName), ed25519.Sign(privKey, checksums), 0644))
+			pubKeyPath := filepath.Join(dir, "sloth.pub")
+			require.NoError(os.WriteFile(pubKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKeyData}), 0644))
+
+			defer func(v string) { info.Version = v }(info.Version)
+			info.Version = test.runningVersion
+
+			cmd := selfUpdateCommand{
+				source:         dir,
+				publicKey:      pubKeyPath,
+				dryRun:         true,
+				allowDowngrade: test.allowDowngrade,
+			}
+			err = cmd.Run(context.TODO(), RootConfig{Logger: log.Noop})
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,501 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"google.golang.org/grpc"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/httpmiddleware"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+const (
+	serveSLOsDefaultLimit = 100
+	serveSLOsMaxLimit     = 1000
+)
+
+type serveCommand struct {
+	specLoadFlags
+	sliPluginFlags
+	budgetNotifyFlags
+	slosInput        string
+	slosExcludeRegex string
+	slosIncludeRegex string
+	listenAddr       string
+	grpcListenAddr   string
+	sliPluginBundles map[string]string
+	reloadInterval   time.Duration
+	reloadTokenFile  string
+	apiKeysFile      string
+	rateLimit        float64
+	rateLimitBurst   int
+	maxRequestSize   int64
+	requestTimeout   time.Duration
+	teamLabel        string
+	prometheusURL    string
+	budgetInterval   time.Duration
+}
+
+// NewServeCommand returns the serve command.
+func NewServeCommand(app *kingpin.Application) Command {
+	c := &serveCommand{specLoadFlags: newSpecLoadFlags(), sliPluginBundles: map[string]string{}}
+	cmd := app.Command("serve", "Serves a read only HTTP API with the SLOs inventory of the discovered SLO manifests (GET /v1/slos), so UIs can browse the SLOs without parsing the specs. Optionally, it serves a gRPC API with the SLOs inventory, the SLO specs validation and the rules generation, and it notifies the SLOs remaining error budget threshold crossings to a webhook.")
+	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
+	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
+	cmd.Flag("listen-addr", "The HTTP listen address of the API.").Default(":8080").StringVar(&c.listenAddr)
+	cmd.Flag("grpc-listen-addr", "The listen address of the gRPC API, if not set the gRPC API is disabled.").StringVar(&c.grpcListenAddr)
+	cmd.Flag("sli-plugins-bundle", "A named SLI plugins bundle of the gRPC API validation and generation requests, selected by name on the requests instead of the default SLI plugins ('name=path' form, the path is a local path or a remote source, can be repeated).").StringMapVar(&c.sliPluginBundles)
+	cmd.Flag("reload-interval", "The interval to discover and load the SLO specs again, if 0 they are only loaded on start.").Default("1m").DurationVar(&c.reloadInterval)
+	cmd.Flag("reload-token-file", "File with the bearer token required by the reload endpoint (POST /v1/reload), if not set the endpoint is disabled.").StringVar(&c.reloadTokenFile)
+	cmd.Flag("api-keys-file", "File with the API keys of the clients, one 'client=key' per line, if set the SLOs API requests require an Authorization Bearer API key.").StringVar(&c.apiKeysFile)
+	cmd.Flag("rate-limit", "The maximum requests per second of every remote host and API key client, if 0 the requests are not rate limited.").Default("0").Float64Var(&c.rateLimit)
+	cmd.Flag("rate-limit-burst", "The maximum requests burst of every client when rate limited.").Default("10").IntVar(&c.rateLimitBurst)
+	cmd.Flag("max-request-size", "The maximum request body size in bytes.").Default("1048576").Int64Var(&c.maxRequestSize)
+	cmd.Flag("request-timeout", "The maximum duration of a request, if 0 it will not have timeout.").Default("30s").DurationVar(&c.requestTimeout)
+	cmd.Flag("team-label", "The SLO label that has the SLO owner team, used by the team filter.").Default(prometheus.DefaultSLOInventoryTeamLabel).StringVar(&c.teamLabel)
+	cmd.Flag("prometheus-url", "The Prometheus API URL that evaluates the generated recording rules, used by the budget webhook.").Default("http://127.0.0.1:9090").StringVar(&c.prometheusURL)
+	cmd.Flag("budget-interval", "The interval to query the SLOs remaining error budget of the budget webhook.").Default("1m").DurationVar(&c.budgetInterval)
+	c.sliPluginFlags.register(cmd)
+	c.specLoadFlags.register(cmd)
+	c.budgetNotifyFlags.register(cmd)
+
+	return c
+}
+
+func (s serveCommand) Name() string { return "serve" }
+func (s serveCommand) Run(ctx context.Context, config RootConfig) error {
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(s.slosExcludeRegex, s.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Load the reload endpoint token.
+	var reloadToken []byte
+	if s.reloadTokenFile != "" {
+		token, err := os.ReadFile(s.reloadTokenFile)
+		if err != nil {
+			return fmt.Errorf("could not read reload token file: %w", err)
+		}
+		reloadToken = bytes.TrimSpace(token)
+		if len(reloadToken) == 0 {
+			return fmt.Errorf("reload token file is empty")
+		}
+	}
+
+	// Load the API clients keys.
+	var apiKeys httpmiddleware.APIKeys
+	if s.apiKeysFile != "" {
+		f, err := os.Open(s.apiKeysFile)
+		if err != nil {
+			return fmt.Errorf("could not open API keys file: %w", err)
+		}
+		apiKeys, err = httpmiddleware.ParseAPIKeys(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("could not load API keys file: %w", err)
+		}
+	}
+
+	// The requests are rate limited by remote host before the authentication, so the failed
+	// authentications are also limited, and by authenticated client after it.
+	var hostRateLimiter, clientRateLimiter *httpmiddleware.RateLimiter
+	if s.rateLimit > 0 {
+		hostRateLimiter, err = httpmiddleware.NewRateLimiter(s.rateLimit, s.rateLimitBurst)
+		if err != nil {
+			return err
+		}
+		if apiKeys != nil {
+			clientRateLimiter, err = httpmiddleware.NewRateLimiter(s.rateLimit, s.rateLimitBurst)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(s.sliPluginBundles) > 0 && s.grpcListenAddr == "" {
+		return fmt.Errorf("SLI plugins bundles require the gRPC API listen address")
+	}
+
+	if s.maxRequestSize <= 0 {
+		return fmt.Errorf("max request size must be greater than 0")
+	}
+
+	budgetNotifier, err := s.newBudgetNotifier(config.HTTPClient)
+	if err != nil {
+		return err
+	}
+	if budgetNotifier != nil && s.budgetInterval <= 0 {
+		return fmt.Errorf("budget interval must be greater than 0")
+	}
+
+	pluginRepo, err := s.createPluginLoader(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	load := func(ctx context.Context) ([]prometheus.StorageSLO, error) {
+		sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, s.slosInput)
+		if err != nil {
+			return nil, fmt.Errorf("could not discover files: %w", err)
+		}
+
+		slos, err := s.loadSLOs(ctx, config.Logger, pluginRepo, sloPaths)
+		if err != nil {
+			return nil, err
+		}
+
+		storageSLOs := make([]prometheus.StorageSLO, 0, len(slos))
+		for _, s := range slos {
+			storageSLOs = append(storageSLOs, prometheus.StorageSLO{SLO: s.SLO, Source: s.Path})
+		}
+
+		return storageSLOs, nil
+	}
+
+	inventory := &sloInventory{load: load}
+	loaded, err := inventory.reload(ctx)
+	if err != nil {
+		return err
+	}
+	config.Logger.WithValues(log.Kv{"slos": loaded}).Infof("SLOs loaded")
+
+	var grpcServer *grpc.Server
+	if s.grpcListenAddr != "" {
+		grpcServer, err = s.newGRPCServer(ctx, config, inventory, apiKeys, hostRateLimiter, clientRateLimiter)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// Reload the SLOs in the background, keeping the previous ones on errors.
+	if s.reloadInterval > 0 {
+		go func() {
+			t := time.NewTicker(s.reloadInterval)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					loaded, err := inventory.reload(ctx)
+					if err != nil {
+						config.Logger.Errorf("Could not reload SLOs: %s", err)
+						continue
+					}
+					config.Logger.WithValues(log.Kv{"slos": loaded}).Debugf("SLOs reloaded")
+				}
+			}
+		}()
+	}
+
+	// Notify the budget threshold crossings of the loaded SLOs in the background.
+	if budgetNotifier != nil {
+		api, err := newPrometheusAPI(s.prometheusURL, config.HTTPClient)
+		if err != nil {
+			return err
+		}
+		querier := promAPIValueQuerier{api: api}
+
+		go func() {
+			notify := func() {
+				storageSLOs := inventory.get()
+				slos := make([]prometheus.SLO, 0, len(storageSLOs))
+				for _, s := range storageSLOs {
+					slos = append(slos, s.SLO)
+				}
+
+				now := time.Now()
+				reports, err := prometheus.ReportSLOs(ctx, querier, slos, now)
+				if err != nil {
+					config.Logger.Errorf("Could not report SLOs budget: %s", err)
+					return
+				}
+				err = budgetNotifier.notify(ctx, config.Logger, slos, reports, now)
+				if err != nil {
+					config.Logger.Errorf("Could not notify SLOs budget: %s", err)
+				}
+			}
+
+			notify()
+			t := time.NewTicker(s.budgetInterval)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					notify()
+				}
+			}
+		}()
+	}
+
+	// Protect the API endpoints, the reload endpoint has its own token so it doesn't use
+	// the API keys, and its clients are rate limited by remote host.
+	protect := func(h http.Handler, apiKeyAuth bool) http.Handler {
+		if apiKeyAuth && apiKeys != nil {
+			if clientRateLimiter != nil {
+				h = clientRateLimiter.Handler(h)
+			}
+			h = httpmiddleware.APIKeyAuth(apiKeys, h)
+		}
+		if hostRateLimiter != nil {
+			h = hostRateLimiter.Handler(h)
+		}
+		return h
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/slos", protect(s.listSLOsHandler(config.Logger, inventory), true))
+	if len(reloadToken) > 0 {
+		mux.Handle("/v1/reload", protect(s.reloadHandler(config.Logger, inventory, reloadToken), false))
+	}
+
+	var handler http.Handler = httpmiddleware.MaxBodySize(s.maxRequestSize, mux)
+	if s.requestTimeout > 0 {
+		handler = http.TimeoutHandler(handler, s.requestTimeout, "request timeout")
+	}
+	server := &http.Server{Addr: s.listenAddr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	var grpcListener net.Listener
+	if grpcServer != nil {
+		grpcListener, err = net.Listen("tcp", s.grpcListenAddr)
+		if err != nil {
+			return fmt.Errorf("could not listen on gRPC API address: %w", err)
+		}
+	}
+
+	errC := make(chan error, 2)
+	go func() {
+		config.Logger.WithValues(log.Kv{"addr": s.listenAddr}).Infof("SLOs API HTTP server listening")
+		errC <- fmt.Errorf("SLOs API HTTP server failed: %w", server.ListenAndServe())
+	}()
+
+	if grpcServer != nil {
+		go func() {
+			config.Logger.WithValues(log.Kv{"addr": s.grpcListenAddr}).Infof("SLOs API gRPC server listening")
+			errC <- fmt.Errorf("SLOs API gRPC server failed: %w", grpcServer.Serve(grpcListener))
+		}()
+	}
+
+	select {
+	case err := <-errC:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Stop the gRPC server without waiting for the running streams after the shutdown timeout.
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+
+	return server.Shutdown(shutdownCtx)
+}
+
+// sloInventory has the loaded SLOs, safe to be used concurrently.
+type sloInventory struct {
+	load     func(ctx context.Context) ([]prometheus.StorageSLO, error)
+	slos     []prometheus.StorageSLO
+	mu       sync.RWMutex
+	reloadMu sync.Mutex
+}
+
+func (s *sloInventory) get() []prometheus.StorageSLO {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.slos
+}
+
+// reload loads the SLOs again and returns the number of loaded SLOs, on errors it keeps
+// the previous SLOs. The reloads are serialized.
+func (s *sloInventory) reload(ctx context.Context) (int, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	slos, err := s.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.slos = slos
+	s.mu.Unlock()
+
+	return len(slos), nil
+}
+
+type jsonSLOInventory struct {
+	SLOs   []jsonSLOInventorySLO `json:"slos"`
+	Total  int                   `json:"total"`
+	Offset int                   `json:"offset"`
+	Limit  int                   `json:"limit"`
+}
+
+type jsonSLOInventorySLO struct {
+	ID          string            `json:"id"`
+	Service     string            `json:"service"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Objective   float64           `json:"objective"`
+	SLOPeriod   string            `json:"sloPeriod"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Source      string            `json:"source"`
+}
+
+// listSLOsHandler lists the SLOs using the `service`, `team`, `min_objective` and
+// `max_objective` query params filters, paginated with the `offset` and `limit` query params.
+func (s serveCommand) listSLOsHandler(logger log.Logger, inventory *sloInventory) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		params := r.URL.Query()
+		filter := prometheus.SLOInventoryFilter{
+			Service:   params.Get("service"),
+			Team:      params.Get("team"),
+			TeamLabel: s.teamLabel,
+		}
+
+		parseFloat := func(name string) (*float64, error) {
+			v := params.Get(name)
+			if v == "" {
+				return nil, nil
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", name, v)
+			}
+			return &f, nil
+		}
+		parseInt := func(name string, def int) (int, error) {
+			v := params.Get(name)
+			if v == "" {
+				return def, nil
+			}
+			i, err := strconv.Atoi(v)
+			if err != nil || i < 0 {
+				return 0, fmt.Errorf("invalid %s %q", name, v)
+			}
+			return i, nil
+		}
+
+		var err error
+		filter.MinObjective, err = parseFloat("min_objective")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.MaxObjective, err = parseFloat("max_objective")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset, err := parseInt("offset", 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit, err := parseInt("limit", serveSLOsDefaultLimit)
+		if err != nil || limit == 0 || limit > serveSLOsMaxLimit {
+			http.Error(w, fmt.Sprintf("invalid limit, must be between 1 and %d", serveSLOsMaxLimit), http.StatusBadRequest)
+			return
+		}
+
+		page, total := prometheus.ListSLOInventory(inventory.get(), filter, offset, limit)
+
+		res := jsonSLOInventory{
+			SLOs:   make([]jsonSLOInventorySLO, 0, len(page)),
+			Total:  total,
+			Offset: offset,
+			Limit:  limit,
+		}
+		for _, slo := range page {
+			res.SLOs = append(res.SLOs, jsonSLOInventorySLO{
+				ID:          slo.SLO.ID,
+				Service:     slo.SLO.Service,
+				Name:        slo.SLO.Name,
+				Description: slo.SLO.Description,
+				Objective:   slo.SLO.Objective,
+				SLOPeriod:   prommodel.Duration(slo.SLO.TimeWindow).String(),
+				Labels:      slo.SLO.Labels,
+				Annotations: slo.SLO.Annotations,
+				Source:      slo.Source,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			logger.Errorf("Could not write SLOs API response: %s", err)
+		}
+	})
+}
+
+// reloadHandler reloads the SLOs immediately (e.g from a CI webhook after a merge), the
+// requests are authenticated with the `Authorization: Bearer {token}` header.
+func (s serveCommand) reloadHandler(logger log.Logger, inventory *sloInventory, token []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		reqToken := []byte(strings.TrimPrefix(auth, "Bearer "))
+		if !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare(reqToken, token) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		loaded, err := inventory.reload(r.Context())
+		if err != nil {
+			logger.Errorf("Could not reload SLOs: %s", err)
+			http.Error(w, fmt.Sprintf("could not reload SLOs: %s", err), http.StatusInternalServerError)
+			return
+		}
+		logger.WithValues(log.Kv{"slos": loaded}).Infof("SLOs reloaded by request")
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(struct {
+			SLOs int `json:"slos"`
+		}{SLOs: loaded})
+		if err != nil {
+			logger.Errorf("Could not write reload API response: %s", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestServeReloadHandler(t *testing.T) {
+	previousSLOs := []prometheus.StorageSLO{
+		{SLO: prometheus.SLO{ID: "svc1-slo1"}},
+	}
+	reloadedSLOs := []prometheus.StorageSLO{
+		{SLO: prometheus.SLO{ID: "svc1-slo1"}},
+		{SLO: prometheus.SLO{ID: "svc1-slo2"}},
+	}
+
+	tests := map[string]struct {
+		method     string
+		authHeader string
+		loadErr    error
+		expCode    int
+		expBody    string
+		expLoads   int
+		expSLOs    []prometheus.StorageSLO
+	}{
+		"A request without token should be unauthorized.": {
+			method:  http.MethodPost,
+			expCode: http.StatusUnauthorized,
+			expBody: "unauthorized\n",
+			expSLOs: previousSLOs,
+		},
+
+		"A request with a wrong token should be unauthorized.": {
+			method:     http.MethodPost,
+			authHeader: "Bearer wrong-token",
+			expCode:    http.StatusUnauthorized,
+			expBody:    "unauthorized\n",
+			expSLOs:    previousSLOs,
+		},
+
+		"A request with the token without bearer should be unauthorized.": {
+			method:     http.MethodPost,
+			authHeader: "reload-token",
+			expCode:    http.StatusUnauthorized,
+			expBody:    "unauthorized\n",
+			expSLOs:    previousSLOs,
+		},
+
+		"A non POST request should not be allowed.": {
+			method:     http.MethodGet,
+			authHeader: "Bearer reload-token",
+			expCode:    http.StatusMethodNotAllowed,
+			expBody:    "method not allowed\n",
+			expSLOs:    previousSLOs,
+		},
+
+		"A request with the token should reload the SLOs.": {
+			method:     http.MethodPost,
+			authHeader: "Bearer reload-token",
+			expCode:    http.StatusOK,
+			expBody:    `{"slos":2}` + "\n",
+			expLoads:   1,
+			expSLOs:    reloadedSLOs,
+		},
+
+		"A failed reload should keep the previous SLOs.": {
+			method:     http.MethodPost,
+			authHeader: "Bearer reload-token",
+			loadErr:    errors.New("invalid spec"),
+			expCode:    http.StatusInternalServerError,
+			expBody:    "could not reload SLOs: invalid spec\n",
+			expLoads:   1,
+			expSLOs:    previousSLOs,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			loads := 0
+			inventory := &sloInventory{
+				slos: previousSLOs,
+				load: func(ctx context.Context) ([]prometheus.StorageSLO, error) {
+					loads++
+					if test.loadErr != nil {
+						return nil, test.loadErr
+					}
+					return reloadedSLOs, nil
+				},
+			}
+			h := serveCommand{}.reloadHandler(log.Noop, inventory, []byte("reload-token"))
+
+			r := httptest.NewRequest(test.method, "/v1/reload", nil)
+			if test.authHeader != "" {
+				r.Header.Set("Authorization", test.authHeader)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			assert.Equal(test.expCode, w.Code)
+			assert.Equal(test.expBody, w.Body.String())
+			assert.Equal(test.expLoads, loads)
+			assert.Equal(test.expSLOs, inventory.get())
+		})
+	}
+}
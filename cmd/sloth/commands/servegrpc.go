@@ -0,0 +1,305 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/slok/sloth/internal/grpcmiddleware"
+	"github.com/slok/sloth/internal/httpmiddleware"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/pkg/generate"
+	slothv1 "github.com/slok/sloth/pkg/grpc/api/v1"
+)
+
+// grpcRulesChunkSize is the maximum size of the generated rules chunks streamed by GenerateRules.
+const grpcRulesChunkSize = 32 * 1024
+
+// newGRPCServer returns the gRPC API (`sloth.v1.SlothService`) server, the specs of the
+// validation and generation requests are loaded with the same SLI plugins and SLO specs
+// loading flags of the served SLO specs, or with the SLI plugins bundle selected by the
+// request.
+func (s serveCommand) newGRPCServer(ctx context.Context, config RootConfig, inventory *sloInventory, apiKeys httpmiddleware.APIKeys, hostRateLimiter, clientRateLimiter *httpmiddleware.RateLimiter) (*grpc.Server, error) {
+	defaultSLOPeriod, err := prometheus.ParseDuration(s.defaultSLOPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default SLO period: %w", err)
+	}
+
+	var alertWindows [][]byte
+	if s.sloPeriodWindowsPath != "" {
+		alertWindows, err = readAlertWindowsCatalog(config.Logger, s.sloPeriodWindowsPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newSpecLoader := func(pluginPaths []string) (generate.SpecLoader, error) {
+		return generate.NewSpecLoader(generate.SpecLoaderConfig{
+			SLIPluginsPaths:            pluginPaths,
+			SLIPluginsAllowExecutables: s.sliPluginsAllowExec,
+			SLIPluginsTimeout:          s.sliPluginsTimeout,
+			SLIPluginsAllowedImports:   s.sliPluginsAllowedImports,
+			DefaultSLOPeriod:           defaultSLOPeriod,
+			Environment:                s.environment,
+			AlertWindowsCatalog:        alertWindows,
+		})
+	}
+
+	// The default spec loader uses the default SLI plugins.
+	pluginPaths, err := s.pluginPaths(ctx, config.Logger, config.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	defaultSpecLoader, err := newSpecLoader(pluginPaths)
+	if err != nil {
+		return nil, fmt.Errorf("could not create gRPC API spec loader: %w", err)
+	}
+	specLoaders := map[string]generate.SpecLoader{"": defaultSpecLoader}
+
+	// Every bundle has its own SLI plugins, so the same plugin ID can have a different version
+	// on every bundle.
+	for name, path := range s.sliPluginBundles {
+		if name == "" || path == "" {
+			return nil, fmt.Errorf("invalid %q SLI plugins bundle, must be in 'name=path' form", name+"="+path)
+		}
+
+		pluginPaths, err := fetchRemoteSLIPlugins(ctx, config.Logger, config.HTTPClient, []string{path})
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch %q SLI plugins bundle: %w", name, err)
+		}
+		specLoader, err := newSpecLoader(pluginPaths)
+		if err != nil {
+			return nil, fmt.Errorf("could not create %q SLI plugins bundle spec loader: %w", name, err)
+		}
+		specLoaders[name] = specLoader
+		config.Logger.WithValues(log.Kv{"bundle": name, "path": path}).Infof("SLI plugins bundle loaded")
+	}
+
+	svc := &grpcSlothService{
+		inventory:   inventory,
+		teamLabel:   s.teamLabel,
+		specLoaders: specLoaders,
+	}
+
+	return newGRPCSlothServer(svc, grpcServerConfig{
+		apiKeys:           apiKeys,
+		hostRateLimiter:   hostRateLimiter,
+		clientRateLimiter: clientRateLimiter,
+		maxRecvMsgSize:    int(s.maxRequestSize),
+		timeout:           s.requestTimeout,
+	}), nil
+}
+
+// grpcServerConfig are the protections of the gRPC API server.
+type grpcServerConfig struct {
+	apiKeys           httpmiddleware.APIKeys
+	hostRateLimiter   *httpmiddleware.RateLimiter
+	clientRateLimiter *httpmiddleware.RateLimiter
+	maxRecvMsgSize    int
+	timeout           time.Duration
+}
+
+// newGRPCSlothServer returns a gRPC server with the Sloth service, the requests are rate limited
+// by peer host, authenticated, rate limited by client and time limited with interceptors, in
+// that order.
+func newGRPCSlothServer(svc slothv1.SlothServiceServer, config grpcServerConfig) *grpc.Server {
+	interceptors := []grpcmiddleware.Interceptor{}
+	if config.hostRateLimiter != nil {
+		interceptors = append(interceptors, grpcmiddleware.RateLimit(config.hostRateLimiter))
+	}
+	if config.apiKeys != nil {
+		interceptors = append(interceptors, grpcmiddleware.APIKeyAuth(config.apiKeys))
+		if config.clientRateLimiter != nil {
+			interceptors = append(interceptors, grpcmiddleware.RateLimit(config.clientRateLimiter))
+		}
+	}
+	if config.timeout > 0 {
+		interceptors = append(interceptors, grpcmiddleware.Timeout(config.timeout))
+	}
+
+	opts := grpcmiddleware.ServerOptions(interceptors...)
+	if config.maxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(config.maxRecvMsgSize))
+	}
+
+	server := grpc.NewServer(opts...)
+	slothv1.RegisterSlothServiceServer(server, svc)
+
+	return server
+}
+
+// grpcSlothService implements the gRPC API (`sloth.v1.SlothService`) methods.
+type grpcSlothService struct {
+	slothv1.UnimplementedSlothServiceServer
+
+	inventory *sloInventory
+	teamLabel string
+	// specLoaders are the spec loaders of the SLI plugins bundles by name, the default
+	// SLI plugins spec loader has an empty name.
+	specLoaders map[string]generate.SpecLoader
+}
+
+// specLoader returns the spec loader of the requested SLI plugins bundle.
+func (g *grpcSlothService) specLoader(bundle string) (generate.SpecLoader, error) {
+	loader, ok := g.specLoaders[bundle]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown %q SLI plugins bundle", bundle)
+	}
+
+	return loader, nil
+}
+
+// ListSLOs lists the served SLOs, with the same filters and pagination of the HTTP API.
+func (g *grpcSlothService) ListSLOs(ctx context.Context, req *slothv1.ListSLOsRequest) (*slothv1.ListSLOsResponse, error) {
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = serveSLOsDefaultLimit
+	}
+	if limit < 0 || limit > serveSLOsMaxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit, must be between 1 and %d", serveSLOsMaxLimit)
+	}
+	if req.Offset < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid offset %d", req.Offset)
+	}
+
+	filter := prometheus.SLOInventoryFilter{
+		Service:      req.Service,
+		Team:         req.Team,
+		TeamLabel:    g.teamLabel,
+		MinObjective: req.MinObjective,
+		MaxObjective: req.MaxObjective,
+	}
+	page, total := prometheus.ListSLOInventory(g.inventory.get(), filter, int(req.Offset), limit)
+
+	res := &slothv1.ListSLOsResponse{
+		Slos:   make([]*slothv1.SLO, 0, len(page)),
+		Total:  int32(total),
+		Offset: req.Offset,
+		Limit:  int32(limit),
+	}
+	for _, slo := range page {
+		res.Slos = append(res.Slos, &slothv1.SLO{
+			Id:          slo.SLO.ID,
+			Service:     slo.SLO.Service,
+			Name:        slo.SLO.Name,
+			Description: slo.SLO.Description,
+			Objective:   slo.SLO.Objective,
+			SloPeriod:   prommodel.Duration(slo.SLO.TimeWindow).String(),
+			Labels:      slo.SLO.Labels,
+			Annotations: slo.SLO.Annotations,
+			Source:      slo.Source,
+		})
+	}
+
+	return res, nil
+}
+
+// ValidateSpec validates an SLO spec, the invalid specs are not an error of the request.
+func (g *grpcSlothService) ValidateSpec(ctx context.Context, req *slothv1.ValidateSpecRequest) (*slothv1.ValidateSpecResponse, error) {
+	if len(req.Spec) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "spec is required")
+	}
+
+	specLoader, err := g.specLoader(req.SliPluginsBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	gen, err := generate.NewGenerator(generate.Config{SpecLoader: specLoader, Out: io.Discard})
+	if err != nil {
+		return nil, err
+	}
+
+	slos, err := gen.LoadSpec(ctx, req.Spec)
+	if err != nil {
+		return &slothv1.ValidateSpecResponse{Errors: []string{err.Error()}}, nil
+	}
+
+	// The SLOs are validated by the generation.
+	_, err = gen.Generate(ctx, *slos)
+	if err != nil {
+		return &slothv1.ValidateSpecResponse{Errors: []string{err.Error()}}, nil
+	}
+
+	res := &slothv1.ValidateSpecResponse{
+		Valid:       true,
+		SpecVersion: slos.Spec,
+		Slos:        make([]*slothv1.SLO, 0, len(slos.SLOs)),
+	}
+	for _, slo := range slos.SLOs {
+		res.Slos = append(res.Slos, &slothv1.SLO{
+			Id:          slo.ID,
+			Service:     slo.Service,
+			Name:        slo.Name,
+			Description: slo.Description,
+			Objective:   slo.Objective,
+			SloPeriod:   prommodel.Duration(slo.TimeWindow).String(),
+			Labels:      slo.Labels,
+		})
+	}
+
+	return res, nil
+}
+
+// GenerateRules generates the Prometheus rules of an SLO spec and streams them in chunks.
+func (g *grpcSlothService) GenerateRules(req *slothv1.GenerateRulesRequest, stream slothv1.SlothService_GenerateRulesServer) error {
+	ctx := stream.Context()
+	if len(req.Spec) == 0 {
+		return status.Errorf(codes.InvalidArgument, "spec is required")
+	}
+
+	specLoader, err := g.specLoader(req.SliPluginsBundle)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	gen, err := generate.NewGenerator(generate.Config{
+		SpecLoader:        specLoader,
+		Out:               &out,
+		ExtraLabels:       req.ExtraLabels,
+		DisableRecordings: req.DisableRecordings,
+		DisableAlerts:     req.DisableAlerts,
+	})
+	if err != nil {
+		return err
+	}
+
+	slos, err := gen.LoadSpec(ctx, req.Spec)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	results, err := gen.Generate(ctx, *slos)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	err = gen.Store(ctx, results)
+	if err != nil {
+		return err
+	}
+
+	rules := out.Bytes()
+	for len(rules) > 0 {
+		n := grpcRulesChunkSize
+		if n > len(rules) {
+			n = len(rules)
+		}
+		err := stream.Send(&slothv1.GenerateRulesResponse{Rules: rules[:n]})
+		if err != nil {
+			return err
+		}
+		rules = rules[n:]
+	}
+
+	return nil
+}
@@ -0,0 +1,427 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/slok/sloth/internal/httpmiddleware"
+	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/pkg/generate"
+	slothv1 "github.com/slok/sloth/pkg/grpc/api/v1"
+)
+
+const testGRPCSpec = `
+version: "prometheus/v1"
+service: "svc1"
+slos:
+  - name: "slo1"
+    objective: 99.9
+    sli:
+      events:
+        error_query: sum(rate(http_request_duration_seconds_count{code=~"5.."}[{{.window}}]))
+        total_query: sum(rate(http_request_duration_seconds_count[{{.window}}]))
+    alerting:
+      name: Svc1SLO1HighErrorRate
+`
+
+const testGRPCPluginSpec = `
+version: "prometheus/v1"
+service: "svc1"
+slos:
+  - name: "slo1"
+    objective: 99.9
+    sli:
+      plugin:
+        id: "test_availability"
+    alerting:
+      name: Svc1SLO1HighErrorRate
+`
+
+func newTestGRPCSlothService(t *testing.T) *grpcSlothService {
+	specLoaders := map[string]generate.SpecLoader{}
+	bundles := map[string][]string{
+		"":   nil,
+		"v1": {"testdata/sli-plugins-bundles/v1"},
+		"v2": {"testdata/sli-plugins-bundles/v2"},
+	}
+	for name, paths := range bundles {
+		specLoader, err := generate.NewSpecLoader(generate.SpecLoaderConfig{SLIPluginsPaths: paths})
+		require.NoError(t, err)
+		specLoaders[name] = specLoader
+	}
+
+	inventory := &sloInventory{slos: []prometheus.StorageSLO{
+		{Source: "slos/svc1.yaml", SLO: prometheus.SLO{ID: "svc1-slo1", Service: "svc1", Name: "slo1", Objective: 99.9, TimeWindow: 30 * 24 * time.Hour, Labels: map[string]string{"team": "a"}}},
+		{Source: "slos/svc1.yaml", SLO: prometheus.SLO{ID: "svc1-slo2", Service: "svc1", Name: "slo2", Objective: 99, TimeWindow: 30 * 24 * time.Hour, Labels: map[string]string{"team": "b"}}},
+		{Source: "slos/svc2.yaml", SLO: prometheus.SLO{ID: "svc2-slo1", Service: "svc2", Name: "slo1", Objective: 95, TimeWindow: 7 * 24 * time.Hour}},
+	}}
+
+	return &grpcSlothService{
+		inventory:   inventory,
+		teamLabel:   "team",
+		specLoaders: specLoaders,
+	}
+}
+
+// newTestGRPCSlothClient serves the Sloth service with a gRPC server on memory and returns
+// a client of the server.
+func newTestGRPCSlothClient(t *testing.T, config grpcServerConfig) slothv1.SlothServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+	server := newGRPCSlothServer(newTestGRPCSlothService(t), config)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return slothv1.NewSlothServiceClient(conn)
+}
+
+func assertProtoEqual(t *testing.T, exp, got proto.Message) {
+	assert.True(t, proto.Equal(exp, got), "expected: %v\nactual: %v", exp, got)
+}
+
+func TestGRPCSlothServiceListSLOs(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	tests := map[string]struct {
+		req     *slothv1.ListSLOsRequest
+		expRes  *slothv1.ListSLOsResponse
+		expCode codes.Code
+	}{
+		"Listing without filters should return all the SLOs with the default limit.": {
+			req: &slothv1.ListSLOsRequest{},
+			expRes: &slothv1.ListSLOsResponse{
+				Slos: []*slothv1.SLO{
+					{Id: "svc1-slo1", Service: "svc1", Name: "slo1", Objective: 99.9, SloPeriod: "30d", Labels: map[string]string{"team": "a"}, Source: "slos/svc1.yaml"},
+					{Id: "svc1-slo2", Service: "svc1", Name: "slo2", Objective: 99, SloPeriod: "30d", Labels: map[string]string{"team": "b"}, Source: "slos/svc1.yaml"},
+					{Id: "svc2-slo1", Service: "svc2", Name: "slo1", Objective: 95, SloPeriod: "1w", Source: "slos/svc2.yaml"},
+				},
+				Total: 3,
+				Limit: 100,
+			},
+		},
+
+		"Listing with filters and pagination should return the matched SLOs page.": {
+			req: &slothv1.ListSLOsRequest{Service: "svc1", MinObjective: f(99), Offset: 1, Limit: 1},
+			expRes: &slothv1.ListSLOsResponse{
+				Slos: []*slothv1.SLO{
+					{Id: "svc1-slo2", Service: "svc1", Name: "slo2", Objective: 99, SloPeriod: "30d", Labels: map[string]string{"team": "b"}, Source: "slos/svc1.yaml"},
+				},
+				Total:  2,
+				Offset: 1,
+				Limit:  1,
+			},
+		},
+
+		"Listing with the team filter should use the team label.": {
+			req: &slothv1.ListSLOsRequest{Team: "a"},
+			expRes: &slothv1.ListSLOsResponse{
+				Slos: []*slothv1.SLO{
+					{Id: "svc1-slo1", Service: "svc1", Name: "slo1", Objective: 99.9, SloPeriod: "30d", Labels: map[string]string{"team": "a"}, Source: "slos/svc1.yaml"},
+				},
+				Total: 1,
+				Limit: 100,
+			},
+		},
+
+		"Listing with a limit greater than the max should fail.": {
+			req:     &slothv1.ListSLOsRequest{Limit: 1001},
+			expCode: codes.InvalidArgument,
+		},
+
+		"Listing with a negative offset should fail.": {
+			req:     &slothv1.ListSLOsRequest{Offset: -1},
+			expCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := newTestGRPCSlothClient(t, grpcServerConfig{})
+			gotRes, err := client.ListSLOs(context.TODO(), test.req)
+
+			if test.expCode != codes.OK {
+				assert.Equal(test.expCode, status.Code(err))
+			} else if assert.NoError(err) {
+				assertProtoEqual(t, test.expRes, gotRes)
+			}
+		})
+	}
+}
+
+func TestGRPCSlothServiceValidateSpec(t *testing.T) {
+	tests := map[string]struct {
+		spec    string
+		bundle  string
+		expRes  *slothv1.ValidateSpecResponse
+		expCode codes.Code
+	}{
+		"A valid spec should return its SLOs.": {
+			spec: testGRPCSpec,
+			expRes: &slothv1.ValidateSpecResponse{
+				Valid:       true,
+				SpecVersion: "prometheus/v1",
+				Slos: []*slothv1.SLO{
+					{Id: "svc1-slo1", Service: "svc1", Name: "slo1", Objective: 99.9, SloPeriod: "30d", Labels: map[string]string{}},
+				},
+			},
+		},
+
+		"An invalid spec should return the validation errors.": {
+			spec: strings.Replace(testGRPCSpec, "99.9", "101", 1),
+			expRes: &slothv1.ValidateSpecResponse{
+				Errors: []string{`could not generate SLO rules: invalid SLO group: Key: 'SLOGroup.SLOs[0].Objective' Error:Field validation for 'Objective' failed on the 'lt' tag`},
+			},
+		},
+
+		"A spec with an SLI plugin of the selected plugins bundle should be valid.": {
+			spec:   testGRPCPluginSpec,
+			bundle: "v1",
+			expRes: &slothv1.ValidateSpecResponse{
+				Valid:       true,
+				SpecVersion: "prometheus/v1",
+				Slos: []*slothv1.SLO{
+					{Id: "svc1-slo1", Service: "svc1", Name: "slo1", Objective: 99.9, SloPeriod: "30d", Labels: map[string]string{}},
+				},
+			},
+		},
+
+		"An empty spec should fail.": {
+			spec:    "",
+			expCode: codes.InvalidArgument,
+		},
+
+		"An unknown plugins bundle should fail.": {
+			spec:    testGRPCPluginSpec,
+			bundle:  "v3",
+			expCode: codes.NotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := newTestGRPCSlothClient(t, grpcServerConfig{})
+			gotRes, err := client.ValidateSpec(context.TODO(), &slothv1.ValidateSpecRequest{Spec: []byte(test.spec), SliPluginsBundle: test.bundle})
+
+			if test.expCode != codes.OK {
+				assert.Equal(test.expCode, status.Code(err))
+			} else if assert.NoError(err) {
+				assertProtoEqual(t, test.expRes, gotRes)
+			}
+		})
+	}
+}
+
+func TestGRPCSlothServiceGenerateRules(t *testing.T) {
+	tests := map[string]struct {
+		req         *slothv1.GenerateRulesRequest
+		expContains []string
+		expMissing  []string
+		expCode     codes.Code
+	}{
+		"A spec should stream the generated Prometheus rules.": {
+			req: &slothv1.GenerateRulesRequest{
+				Spec:        []byte(testGRPCSpec),
+				ExtraLabels: map[string]string{"cluster": "c1"},
+			},
+			expContains: []string{
+				"name: sloth-slo-sli-recordings-svc1-slo1",
+				"record: slo:sli_error:ratio_rate5m",
+				"cluster: c1",
+			},
+		},
+
+		"A spec with the recordings disabled should not stream the recording rules.": {
+			req: &slothv1.GenerateRulesRequest{
+				Spec:              []byte(testGRPCSpec),
+				DisableRecordings: true,
+			},
+			expContains: []string{"alert: Svc1SLO1HighErrorRate"},
+			expMissing:  []string{"record: "},
+		},
+
+		"A spec with an SLI plugin should use the plugin version of the selected plugins bundle.": {
+			req: &slothv1.GenerateRulesRequest{
+				Spec:             []byte(testGRPCPluginSpec),
+				SliPluginsBundle: "v1",
+			},
+			expContains: []string{`http_requests_total{code=~"5.."}`},
+			expMissing:  []string{`http_requests_total{code=~"(5..|429)"}`},
+		},
+
+		"A spec with an SLI plugin should use the plugin version of other selected plugins bundle.": {
+			req: &slothv1.GenerateRulesRequest{
+				Spec:             []byte(testGRPCPluginSpec),
+				SliPluginsBundle: "v2",
+			},
+			expContains: []string{`http_requests_total{code=~"(5..|429)"}`},
+			expMissing:  []string{`http_requests_total{code=~"5.."}`},
+		},
+
+		"A spec with an SLI plugin missing on the default SLI plugins should fail with an invalid argument.": {
+			req:     &slothv1.GenerateRulesRequest{Spec: []byte(testGRPCPluginSpec)},
+			expCode: codes.InvalidArgument,
+		},
+
+		"An unknown plugins bundle should fail with a not found.": {
+			req: &slothv1.GenerateRulesRequest{
+				Spec:             []byte(testGRPCPluginSpec),
+				SliPluginsBundle: "v3",
+			},
+			expCode: codes.NotFound,
+		},
+
+		"An invalid spec should fail with an invalid argument.": {
+			req:     &slothv1.GenerateRulesRequest{Spec: []byte("version: unknown")},
+			expCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := newTestGRPCSlothClient(t, grpcServerConfig{})
+			stream, err := client.GenerateRules(context.TODO(), test.req)
+			require.NoError(t, err)
+
+			var rules strings.Builder
+			chunks := 0
+			for {
+				var res *slothv1.GenerateRulesResponse
+				res, err = stream.Recv()
+				if err != nil {
+					break
+				}
+				assert.LessOrEqual(len(res.Rules), grpcRulesChunkSize)
+				rules.Write(res.Rules)
+				chunks++
+			}
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+
+			if test.expCode != codes.OK {
+				assert.Equal(test.expCode, status.Code(err))
+				assert.Zero(chunks)
+				return
+			}
+
+			if assert.NoError(err) {
+				for _, s := range test.expContains {
+					assert.Contains(rules.String(), s)
+				}
+				for _, s := range test.expMissing {
+					assert.NotContains(rules.String(), s)
+				}
+			}
+		})
+	}
+}
+
+func TestGRPCSlothServerProtections(t *testing.T) {
+	// A low rate so the buckets are not refilled during the test.
+	newRateLimiter := func() *httpmiddleware.RateLimiter {
+		l, err := httpmiddleware.NewRateLimiter(0.001, 1)
+		require.NoError(t, err)
+		return l
+	}
+	apiKeys := httpmiddleware.APIKeys{"team-a": "key-a"}
+
+	tests := map[string]struct {
+		config   grpcServerConfig
+		apiKey   string
+		req      *slothv1.ValidateSpecRequest
+		expCodes []codes.Code
+	}{
+		"A request without API key should be unauthenticated.": {
+			config:   grpcServerConfig{apiKeys: apiKeys},
+			req:      &slothv1.ValidateSpecRequest{Spec: []byte(testGRPCSpec)},
+			expCodes: []codes.Code{codes.Unauthenticated},
+		},
+
+		"A request with a wrong API key should be unauthenticated.": {
+			config:   grpcServerConfig{apiKeys: apiKeys},
+			apiKey:   "key-b",
+			req:      &slothv1.ValidateSpecRequest{Spec: []byte(testGRPCSpec)},
+			expCodes: []codes.Code{codes.Unauthenticated},
+		},
+
+		"A request with a valid API key should be served.": {
+			config:   grpcServerConfig{apiKeys: apiKeys},
+			apiKey:   "key-a",
+			req:      &slothv1.ValidateSpecRequest{Spec: []byte(testGRPCSpec)},
+			expCodes: []codes.Code{codes.OK},
+		},
+
+		"The requests over the host rate limit should be limited.": {
+			config:   grpcServerConfig{hostRateLimiter: newRateLimiter()},
+			req:      &slothv1.ValidateSpecRequest{Spec: []byte(testGRPCSpec)},
+			expCodes: []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+
+		"The unauthenticated requests over the host rate limit should be limited.": {
+			config:   grpcServerConfig{apiKeys: apiKeys, hostRateLimiter: newRateLimiter(), clientRateLimiter: newRateLimiter()},
+			apiKey:   "key-b",
+			req:      &slothv1.ValidateSpecRequest{Spec: []byte(testGRPCSpec)},
+			expCodes: []codes.Code{codes.Unauthenticated, codes.ResourceExhausted},
+		},
+
+		"The authenticated requests over the client rate limit should be limited.": {
+			config:   grpcServerConfig{apiKeys: apiKeys, clientRateLimiter: newRateLimiter()},
+			apiKey:   "key-a",
+			req:      &slothv1.ValidateSpecRequest{Spec: []byte(testGRPCSpec)},
+			expCodes: []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+
+		"A request message larger than the max size should fail.": {
+			config:   grpcServerConfig{maxRecvMsgSize: 64},
+			req:      &slothv1.ValidateSpecRequest{Spec: []byte(testGRPCSpec)},
+			expCodes: []codes.Code{codes.ResourceExhausted},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := newTestGRPCSlothClient(t, test.config)
+			ctx := context.TODO()
+			if test.apiKey != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+test.apiKey)
+			}
+
+			gotCodes := []codes.Code{}
+			for range test.expCodes {
+				_, err := client.ValidateSpec(ctx, test.req)
+				gotCodes = append(gotCodes, status.Code(err))
+			}
+
+			assert.Equal(test.expCodes, gotCodes)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package availability
+
+import "context"
+
+const (
+	SLIPluginVersion = "prometheus/v1"
+	SLIPluginID      = "test_availability"
+)
+
+// SLIPlugin is the v2 test plugin, it counts the 5xx and 429 responses as errors.
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	return `sum(rate(http_requests_total{code=~"(5..|429)"}[{{.window}}])) / sum(rate(http_requests_total[{{.window}}]))`, nil
+}
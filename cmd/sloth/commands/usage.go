@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/internal/usage"
+	kubernetesv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	k8sspecloader "github.com/slok/sloth/pkg/kubernetes/specloader"
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+// recordSpecUsage records the usage of a loaded SLO spec of the input format, if the
+// usage recorder is enabled.
+func recordSpecUsage(recorder *usage.Recorder, format string, data []byte, slos []prometheus.SLO) error {
+	if recorder == nil {
+		return nil
+	}
+
+	var slosUsage []usage.SLO
+	switch format {
+	case inputFormatPrometheusV1:
+		spec, err := specloader.LoadPrometheusV1(data)
+		if err != nil {
+			return fmt.Errorf("could not load spec usage: %w", err)
+		}
+		slosUsage = prometheusV1SpecUsage(*spec)
+	case inputFormatK8sV1:
+		kslo, err := k8sspecloader.LoadPrometheusServiceLevelV1(data)
+		if err != nil {
+			return fmt.Errorf("could not load spec usage: %w", err)
+		}
+		slosUsage = kubernetesV1SpecUsage(kslo.Spec)
+	default:
+		slosUsage = modelSLOsUsage(slos)
+	}
+
+	recorder.AddSpec(format, slosUsage)
+	return nil
+}
+
+func prometheusV1SpecUsage(spec prometheusv1.Spec) []usage.SLO {
+	res := make([]usage.SLO, 0, len(spec.SLOs))
+	for _, slo := range spec.SLOs {
+		var plugins []string
+		if slo.SLI.Plugin != nil {
+			plugins = append(plugins, slo.SLI.Plugin.ID)
+			for _, p := range slo.SLI.Plugin.Chain {
+				plugins = append(plugins, p.ID)
+			}
+		}
+
+		res = append(res, newSLOUsage(slo.SLI.Events != nil, slo.SLI.Raw != nil, slo.SLI.Latency != nil, plugins, map[string]bool{
+			"sli_offset":           slo.SLI.Offset != "",
+			"sli_examples":         len(slo.SLI.Examples) > 0,
+			"alert_windows":        slo.Alerting.Windows != "",
+			"alert_depends_on":     len(slo.Alerting.DependsOn) > 0,
+			"alert_guard":          slo.Alerting.Guard != "",
+			"group_labels":         len(slo.GroupLabels) > 0,
+			"rule_group_intervals": slo.RuleGroupIntervals != nil,
+			"transition":           slo.Transition != nil,
+			"reporting_windows":    len(slo.ReportingWindows) > 0,
+			"timeslice":            slo.Timeslice != nil,
+			"environments":         len(slo.Environments) > 0,
+		}))
+	}
+
+	return res
+}
+
+func kubernetesV1SpecUsage(spec kubernetesv1.PrometheusServiceLevelSpec) []usage.SLO {
+	res := make([]usage.SLO, 0, len(spec.SLOs))
+	for _, slo := range spec.SLOs {
+		var plugins []string
+		if slo.SLI.Plugin != nil {
+			plugins = append(plugins, slo.SLI.Plugin.ID)
+			for _, p := range slo.SLI.Plugin.Chain {
+				plugins = append(plugins, p.ID)
+			}
+		}
+
+		res = append(res, newSLOUsage(slo.SLI.Events != nil, slo.SLI.Raw != nil, slo.SLI.Latency != nil, plugins, map[string]bool{
+			"sli_offset":           slo.SLI.Offset != "",
+			"sli_examples":         len(slo.SLI.Examples) > 0,
+			"alert_windows":        slo.Alerting.Windows != "",
+			"alert_depends_on":     len(slo.Alerting.DependsOn) > 0,
+			"alert_guard":          slo.Alerting.Guard != "",
+			"rule_group_intervals": slo.RuleGroupIntervals != nil,
+			"transition":           slo.Transition != nil,
+			"reporting_windows":    len(slo.ReportingWindows) > 0,
+			"timeslice":            slo.Timeslice != nil,
+			"environments":         len(slo.Environments) > 0,
+		}))
+	}
+
+	return res
+}
+
+// modelSLOsUsage returns the usage of the SLOs of the specs that are only available as
+// models (e.g OpenSLO).
+func modelSLOsUsage(slos []prometheus.SLO) []usage.SLO {
+	res := make([]usage.SLO, 0, len(slos))
+	for _, slo := range slos {
+		res = append(res, newSLOUsage(slo.SLI.Events != nil, slo.SLI.Raw != nil, false, nil, nil))
+	}
+
+	return res
+}
+
+func newSLOUsage(events, raw, latency bool, plugins []string, features map[string]bool) usage.SLO {
+	slo := usage.SLO{SLIPlugins: plugins}
+
+	// The latency and plugin SLIs have priority, these are loaded as events or raw SLIs.
+	switch {
+	case len(plugins) > 0:
+		slo.SLIType = usage.SLITypePlugin
+	case latency:
+		slo.SLIType = usage.SLITypeLatency
+	case events:
+		slo.SLIType = usage.SLITypeEvents
+	case raw:
+		slo.SLIType = usage.SLITypeRaw
+	}
+
+	for f, used := range features {
+		if used {
+			slo.Features = append(slo.Features, f)
+		}
+	}
+	sort.Strings(slo.Features)
+
+	return slo
+}
+
+// appendUsageReport appends the usage report as a JSON line on the path, creating the file
+// if it doesn't exist.
+func appendUsageReport(logger log.Logger, report usage.Report, path string) error {
+	logger.Infof("Appending usage report")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open usage report out file: %w", err)
+	}
+	defer f.Close()
+
+	return report.WriteJSONLine(f)
+}
@@ -1,35 +1,78 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"regexp"
+	"path/filepath"
+	"sort"
+	"time"
 
-	"github.com/slok/sloth/internal/k8sprometheus"
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/app/generate"
+	"github.com/slok/sloth/internal/gitchanges"
 	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/pluginsource"
 	"github.com/slok/sloth/internal/prometheus"
-	"gopkg.in/alecthomas/kingpin.v2"
+	"github.com/slok/sloth/pkg/specloader"
 )
 
 type validateCommand struct {
-	slosInput        string
-	slosExcludeRegex string
-	slosIncludeRegex string
-	extraLabels      map[string]string
-	sliPluginsPaths  []string
+	specLoadFlags
+	sliPluginFlags
+	slosInput             string
+	slosExcludeRegex      string
+	slosIncludeRegex      string
+	extraLabels           map[string]string
+	defaultAnnotations    map[string]string
+	progress              string
+	costLabelsAllowlist   string
+	labelRegistryURL      string
+	labelRegistryLabels   []string
+	labelRegistryCacheTTL time.Duration
+	metricsRetention      string
+	maxWarnings           int
+	scanSecrets           bool
+	secretsAllowlist      []string
+	strictFields          bool
+	reportFormat          string
+	reportOut             string
+	sliLintDisabledRules  []string
+	examples              bool
+	gitSince              string
 }
 
 // NewValidateCommand returns the validate command.
 func NewValidateCommand(app *kingpin.Application) Command {
-	c := &validateCommand{extraLabels: map[string]string{}}
+	c := &validateCommand{specLoadFlags: newSpecLoadFlags(), extraLabels: map[string]string{}, defaultAnnotations: map[string]string{}}
 	cmd := app.Command("validate", "Validates the SLO manifests and generation of Prometheus SLOs.")
-	cmd.Flag("input", "SLO spec discovery path, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
+	cmd.Flag("input", "SLO spec discovery path, a directory or a .tar.gz, .tgz, .tar or .zip archive, will discover recursively all YAML files.").Short('i').Required().StringVar(&c.slosInput)
 	cmd.Flag("fs-exclude", "Filter regex to ignore matched discovered SLO file paths.").Short('e').StringVar(&c.slosExcludeRegex)
 	cmd.Flag("fs-include", "Filter regex to include matched discovered SLO file paths, everything else will be ignored. Exclude has preference.").Short('n').StringVar(&c.slosIncludeRegex)
 	cmd.Flag("extra-labels", "Extra labels that will be added to all the generated Prometheus rules ('key=value' form, can be repeated).").Short('l').StringMapVar(&c.extraLabels)
-	cmd.Flag("sli-plugins-path", "The path to SLI plugins (can be repeated), if not set it disable plugins support.").Short('p').StringsVar(&c.sliPluginsPaths)
+	cmd.Flag("default-extra-annotations", "Default annotations that will be added to all the generated alert rules ('key=value' form, can be repeated), the SLO spec annotations have preference over them.").StringMapVar(&c.defaultAnnotations)
+	c.sliPluginFlags.register(cmd)
+	cmd.Flag("progress", "Shows the progress of the run on stderr, as a bar (for TTYs) or as periodic JSON events (for CI logs).").Default(progressNone).EnumVar(&c.progress, progressNone, progressBar, progressJSON)
+	cmd.Flag("cost-labels-allowlist", "If set, the SLOs cost labels will be validated against the allowed labels and values of this YAML file path.").StringVar(&c.costLabelsAllowlist)
+	cmd.Flag("label-values-registry-url", "If set, the SLOs label values of the registry labels will be validated against this HTTP allowlist service ('GET <url>?label=<label>&value=<value>', 200 exists and 404 doesn't exist).").StringVar(&c.labelRegistryURL)
+	cmd.Flag("label-values-registry-label", "The SLO labels (e.g team, product) validated against the label values registry (can be repeated).").StringsVar(&c.labelRegistryLabels)
+	cmd.Flag("label-values-registry-cache-ttl", "The time the label values registry responses are cached.").Default("5m").DurationVar(&c.labelRegistryCacheTTL)
+	cmd.Flag("metrics-retention", "If set, it will warn about the SLO windows that exceed the Prometheus metrics retention (e.g 15d), because they can't be evaluated correctly.").StringVar(&c.metricsRetention)
+	cmd.Flag("max-warnings", "If set, the validation will fail when the number of warnings exceeds this maximum, -1 allows any number of warnings.").Default("-1").IntVar(&c.maxWarnings)
+	cmd.Flag("scan-secrets", "Scans the SLI queries, labels and annotations for probable credentials and internal hostnames, failing the validation if any is found.").BoolVar(&c.scanSecrets)
+	cmd.Flag("secrets-allowlist", "Regex of the scanned content that will not be reported as a probable secret (can be repeated).").StringsVar(&c.secretsAllowlist)
+	c.specLoadFlags.register(cmd)
+	cmd.Flag("strict-fields", "Fails on the SLO spec fields that are unknown to the spec (e.g a typo in a field name), instead of ignoring them. OpenSLO specs are not checked.").BoolVar(&c.strictFields)
+	cmd.Flag("report-format", "If set, writes a structured validation report with the errors and warnings of every file and SLO spec, for CI systems (e.g PR annotations).").EnumVar(&c.reportFormat, reportFormatJSON, reportFormatSARIF, reportFormatJUnit)
+	cmd.Flag("report-out", "The validation report output file path. If `-` it will use stdout.").Default("-").StringVar(&c.reportOut)
+	cmd.Flag("disable-sli-lint-rule", "Disables an SLI query lint rule warning by its ID (can be repeated).").EnumsVar(&c.sliLintDisabledRules, prometheus.SLILintRules...)
+	cmd.Flag("examples", "Evaluates the SLIs with the SLI examples series values of the SLO specs, failing the validation when an SLI error ratio is not the expected one.").BoolVar(&c.examples)
+	cmd.Flag("git-since", "If set, only the SLO spec files changed since this git reference (e.g origin/main) are validated, including the uncommitted files. All the files are validated when the shared inputs change (alert windows catalog, cost labels allowlist or local SLI plugins).").StringVar(&c.gitSince)
 
 	return c
 }
@@ -37,110 +80,393 @@ func NewValidateCommand(app *kingpin.Application) Command {
 func (v validateCommand) Name() string { return "validate" }
 func (v validateCommand) Run(ctx context.Context, config RootConfig) error {
 	// Set up files discovery filter regex.
-	var excludeRegex *regexp.Regexp
-	var includeRegex *regexp.Regexp
-	if v.slosExcludeRegex != "" {
-		r, err := regexp.Compile(v.slosExcludeRegex)
+	excludeRegex, includeRegex, err := compileDiscoveryRegexes(v.slosExcludeRegex, v.slosIncludeRegex)
+	if err != nil {
+		return err
+	}
+
+	// Discover SLOs.
+	var sloPaths []string
+	readFile := os.ReadFile
+	if specloader.IsArchive(v.slosInput) {
+		var manifests archiveSLOManifests
+		sloPaths, manifests, err = discoverArchiveSLOManifests(config.Logger, excludeRegex, includeRegex, v.slosInput)
 		if err != nil {
-			return fmt.Errorf("invalid exclude regex: %w", err)
+			return fmt.Errorf("could not discover files: %w", err)
 		}
-		excludeRegex = r
+		readFile = manifests.ReadFile
+	} else {
+		sloPaths, err = discoverSLOManifests(config.Logger, excludeRegex, includeRegex, v.slosInput)
+		if err != nil {
+			return fmt.Errorf("could not discover files: %w", err)
+		}
+	}
+	if len(sloPaths) == 0 {
+		return fmt.Errorf("0 slo specs have been discovered")
 	}
-	if v.slosIncludeRegex != "" {
-		r, err := regexp.Compile(v.slosIncludeRegex)
+
+	// Only validate the changed SLOs if required.
+	if v.gitSince != "" {
+		sloPaths, err = v.changedSLOManifests(ctx, config.Logger, sloPaths)
 		if err != nil {
-			return fmt.Errorf("invalid include regex: %w", err)
+			return err
 		}
-		includeRegex = r
 	}
 
-	// Discover SLOs.
-	sloPaths, err := discoverSLOManifests(config.Logger, excludeRegex, includeRegex, v.slosInput)
+	// Load plugins.
+	pluginRepo, err := v.createPluginLoader(ctx, config.Logger, config.HTTPClient)
 	if err != nil {
-		return fmt.Errorf("could not discover files: %w", err)
+		return err
 	}
-	if len(sloPaths) == 0 {
-		return fmt.Errorf("0 slo specs have been discovered")
+
+	costAllowlist, err := loadCostLabelsAllowlist(v.costLabelsAllowlist)
+	if err != nil {
+		return err
 	}
 
-	// Load plugins.
-	pluginRepo, err := createPluginLoader(ctx, config.Logger, v.sliPluginsPaths)
+	labelRegistry, err := newLabelValuesRegistry(config.HTTPClient, v.labelRegistryURL, v.labelRegistryLabels, v.labelRegistryCacheTTL)
 	if err != nil {
 		return err
 	}
 
+	var secretsScanner *prometheus.SecretsScanner
+	if v.scanSecrets {
+		secretsScanner, err = prometheus.NewSecretsScanner(v.secretsAllowlist)
+		if err != nil {
+			return fmt.Errorf("invalid secrets allowlist: %w", err)
+		}
+	}
+
+	var metricsRetention time.Duration
+	if v.metricsRetention != "" {
+		r, err := prommodel.ParseDuration(v.metricsRetention)
+		if err != nil {
+			return fmt.Errorf("invalid metrics retention: %w", err)
+		}
+		metricsRetention = time.Duration(r)
+	}
+
 	// Create Spec loaders.
-	promYAMLLoader := prometheus.NewYAMLSpecLoader(pluginRepo)
-	kubeYAMLLoader := k8sprometheus.NewYAMLSpecLoader(pluginRepo)
+	specLoaders, err := v.specLoaders(config.Logger, pluginRepo, "", v.strictFields)
+	if err != nil {
+		return err
+	}
 
 	// For every file load the data and start the validation process:
 	validations := []*fileValidation{}
+	summaries := serviceValidationSummaries{}
 	totalValidations := 0
+	tplData := newSpecTemplateData(v.vars)
+	progress := newProgressReporter(v.progress, config.Stderr, "files", len(sloPaths))
 	for _, input := range sloPaths {
+		progress.Step(input)
+
 		// Get SLO spec data.
-		slxData, err := os.ReadFile(input)
+		slxData, err := readFile(input)
 		if err != nil {
 			return fmt.Errorf("could not read SLOs spec file data: %w", err)
 		}
 
-		// Split YAMLs in case we have multiple yaml files in a single file.
-		splittedSLOsData := splitYAML(slxData)
+		// Render the spec templates and split YAMLs in case we have multiple yaml files in a single file.
+		var splittedSLOsData [][]byte
+		slxData, readErr := specloader.RenderTemplates(slxData, tplData)
+		if readErr != nil {
+			readErr = fmt.Errorf("could not render SLOs spec templates: %w", readErr)
+		} else {
+			splittedSLOsData, readErr = specloader.ReadAll(bytes.NewReader(slxData))
+			if readErr != nil {
+				readErr = fmt.Errorf("could not split SLOs spec file data: %w", readErr)
+			}
+		}
 
 		// Prepare file validation result and start validation result for every SLO in the file.
-		// TODO(slok): Add service meta to validation.
 		validation := &fileValidation{File: input}
 		validations = append(validations, validation)
+		if readErr != nil {
+			validation.FileErrs = []error{readErr}
+			summaries.add(specValidation{Service: unknownService, Errs: validation.FileErrs})
+		}
 		for _, data := range splittedSLOsData {
 			totalValidations++
 
-			// Try loading spec with all the generators possible:
-			// 1 - Raw Prometheus generator.
-			slos, promErr := promYAMLLoader.LoadSpec(ctx, []byte(data))
-			if promErr == nil {
-				err := generatePrometheus(ctx, log.Noop, false, false, v.extraLabels, *slos, io.Discard)
-				if err != nil {
-					validation.Errs = []error{fmt.Errorf("could not generate Prometheus format rules: %w", err)}
-				}
-				continue
-			}
-
-			// 2 - Kubernetes Prometheus operator generator.
-			sloGroup, k8sErr := kubeYAMLLoader.LoadSpec(ctx, []byte(data))
-			if k8sErr == nil {
-				err := generateKubernetes(ctx, log.Noop, false, false, v.extraLabels, *sloGroup, io.Discard)
-				if err != nil {
-					validation.Errs = []error{fmt.Errorf("could not generate Kubernetes format rules: %w", err)}
-				}
-				continue
-			}
-
-			// If we reached here means that we could not use any of the available spec types.
-			validation.Errs = []error{
-				fmt.Errorf("Tried loading raw prometheus SLOs spec, it couldn't: %w", promErr),
-				fmt.Errorf("Tried loading Kubernetes prometheus SLOs spec, it couldn't: %w", k8sErr),
-			}
+			specValidation := v.validateSpec(ctx, specLoaders, costAllowlist, labelRegistry, secretsScanner, metricsRetention, data)
+			validation.Specs = append(validation.Specs, specValidation)
+			summaries.add(specValidation)
 		}
 
 		// Don't wait until the end to show validation per file.
 		logger := config.Logger.WithValues(log.Kv{"file": validation.File})
 		logger.Debugf("File validated")
-		for _, err := range validation.Errs {
+		for _, w := range validation.warnings() {
+			logger.Warningf("%s", w)
+		}
+		for _, err := range validation.errs() {
 			logger.Errorf("%s", err)
 		}
 	}
+	progress.Finish()
+
+	// Show the validation rollup per service.
+	totalWarnings := 0
+	for _, s := range summaries.sorted() {
+		totalWarnings += s.Warnings
+		config.Logger.WithValues(log.Kv{"service": s.Service, "slos": s.SLOs, "errors": s.Errors, "warnings": s.Warnings}).Infof("Service validation summary")
+	}
+
+	if v.reportFormat != "" {
+		err := writeValidationReport(v.reportFormat, v.reportOut, config.Stdout, validations)
+		if err != nil {
+			return fmt.Errorf("could not write validation report: %w", err)
+		}
+	}
 
 	// Check if we need to return an error.
 	for _, v := range validations {
-		if len(v.Errs) != 0 {
+		if len(v.errs()) != 0 {
 			return fmt.Errorf("validation failed")
 		}
 	}
 
+	if v.maxWarnings >= 0 && totalWarnings > v.maxWarnings {
+		return fmt.Errorf("validation failed, %d warnings exceed the %d maximum warnings", totalWarnings, v.maxWarnings)
+	}
+
 	config.Logger.WithValues(log.Kv{"slo-specs": totalValidations}).Infof("Validation succeeded")
 	return nil
 }
 
+// changedSLOManifests returns the SLO spec paths that changed since the git reference, or all
+// of them if any of the inputs shared by all the SLO specs changed.
+func (v validateCommand) changedSLOManifests(ctx context.Context, logger log.Logger, paths []string) ([]string, error) {
+	dir := v.slosInput
+	if stat, err := os.Stat(dir); err == nil && !stat.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	changes, err := gitchanges.Since(ctx, dir, v.gitSince)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the changed files since %q: %w", v.gitSince, err)
+	}
+
+	shared := []string{v.sloPeriodWindowsPath, v.costLabelsAllowlist}
+	for _, p := range v.sliPluginsPaths {
+		if !pluginsource.IsRemote(p) {
+			shared = append(shared, p)
+		}
+	}
+	// The archive files are only known by the archive changes.
+	if specloader.IsArchive(v.slosInput) {
+		shared = append(shared, v.slosInput)
+	}
+
+	logger = logger.WithValues(log.Kv{"git-since": v.gitSince})
+	if changes.AnyChanged(shared...) {
+		logger.Infof("Shared SLO spec inputs changed, validating all the SLO specs")
+		return paths, nil
+	}
+
+	changed := []string{}
+	for _, p := range paths {
+		if changes.Changed(p) {
+			changed = append(changed, p)
+		}
+	}
+	logger.WithValues(log.Kv{"changed": len(changed), "total": len(paths)}).Infof("Validating only the changed SLO specs")
+
+	return changed, nil
+}
+
+// validateSpec validates an SLO spec trying all the supported spec types.
+func (v validateCommand) validateSpec(ctx context.Context, specLoaders []specLoader, costAllowlist prometheus.CostLabelsAllowlist, labelRegistry *prometheus.LabelValuesRegistry, secretsScanner *prometheus.SecretsScanner, metricsRetention time.Duration, data []byte) specValidation {
+	spec, err := loadSpec(ctx, specLoaders, data)
+	if err != nil {
+		var loadErrs specLoadErrors
+		if !errors.As(err, &loadErrs) {
+			loadErrs = specLoadErrors{err}
+		}
+		return specValidation{Service: unknownService, Errs: loadErrs}
+	}
+	slos := spec.sloGroup.SLOs
+
+	validation := newSpecValidation(slos)
+	err = validateSLOLabels(ctx, costAllowlist, labelRegistry, slos)
+	if err != nil {
+		validation.Errs = []error{err}
+		return validation
+	}
+	addDefaultAnnotations(slos, v.defaultAnnotations)
+	if errs := secretsErrs(secretsScanner, slos); len(errs) > 0 {
+		validation.Errs = errs
+		return validation
+	}
+	if v.examples {
+		if errs := examplesErrs(slos); len(errs) > 0 {
+			validation.Errs = errs
+			return validation
+		}
+	}
+	validation.Warnings = retentionWarnings(slos, metricsRetention)
+	validation.Warnings = append(validation.Warnings, overlapWarnings(slos)...)
+	validation.Warnings = append(validation.Warnings, sliLintWarnings(slos, v.sliLintDisabledRules)...)
+	result, err := generateSpec(ctx, log.Noop, generateOptions{extraLabels: v.extraLabels}, *spec, io.Discard)
+	if err != nil {
+		validation.Errs = []error{err}
+		return validation
+	}
+	validation.Errs = rulesLintErrs(result)
+
+	return validation
+}
+
+// unknownService is the service used on the validation summary for the specs that couldn't be loaded.
+const unknownService = "unknown"
+
+// specValidation is the validation result of an SLO spec.
+type specValidation struct {
+	Service  string
+	SLOs     int
+	SLOIDs   []string
+	Errs     []error
+	Warnings []string
+}
+
+func newSpecValidation(slos []prometheus.SLO) specValidation {
+	// All the SLOs of a spec have the same service.
+	service := unknownService
+	if len(slos) > 0 {
+		service = slos[0].Service
+	}
+
+	ids := make([]string, 0, len(slos))
+	for _, slo := range slos {
+		ids = append(ids, slo.ID)
+	}
+
+	return specValidation{Service: service, SLOs: len(slos), SLOIDs: ids}
+}
+
+// serviceValidationSummary is the validation rollup of a service.
+type serviceValidationSummary struct {
+	Service  string
+	SLOs     int
+	Errors   int
+	Warnings int
+}
+
+type serviceValidationSummaries map[string]*serviceValidationSummary
+
+func (s serviceValidationSummaries) add(v specValidation) {
+	summary, ok := s[v.Service]
+	if !ok {
+		summary = &serviceValidationSummary{Service: v.Service}
+		s[v.Service] = summary
+	}
+
+	summary.SLOs += v.SLOs
+	summary.Errors += len(v.Errs)
+	summary.Warnings += len(v.Warnings)
+}
+
+func (s serviceValidationSummaries) sorted() []serviceValidationSummary {
+	res := make([]serviceValidationSummary, 0, len(s))
+	for _, summary := range s {
+		res = append(res, *summary)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Service < res[j].Service })
+
+	return res
+}
+
 type fileValidation struct {
 	File string
-	Errs []error
+	// FileErrs are the errors of the file that are not from any SLO spec (e.g split errors).
+	FileErrs []error
+	Specs    []specValidation
+}
+
+// errs returns all the file errors, the file errors and the SLO specs errors.
+func (f fileValidation) errs() []error {
+	errs := append([]error{}, f.FileErrs...)
+	for _, s := range f.Specs {
+		errs = append(errs, s.Errs...)
+	}
+
+	return errs
+}
+
+// warnings returns all the SLO specs warnings of the file.
+func (f fileValidation) warnings() []string {
+	warnings := []string{}
+	for _, s := range f.Specs {
+		warnings = append(warnings, s.Warnings...)
+	}
+
+	return warnings
+}
+
+// secretsErrs returns the errors of the probable secrets found on the SLOs.
+func secretsErrs(scanner *prometheus.SecretsScanner, slos []prometheus.SLO) []error {
+	var errs []error
+	for _, f := range scanner.Scan(slos) {
+		errs = append(errs, fmt.Errorf("probable secret found: %s", f))
+	}
+
+	return errs
+}
+
+// examplesErrs returns the errors of the SLI examples that don't get the expected SLI error ratio.
+func examplesErrs(slos []prometheus.SLO) []error {
+	var errs []error
+	for _, slo := range slos {
+		errs = append(errs, prometheus.ValidateSLIExamples(slo)...)
+	}
+
+	return errs
+}
+
+// rulesLintErrs returns the errors of the generated rules that Prometheus would reject
+// when loading them (e.g invalid PromQL SLI queries).
+func rulesLintErrs(result *generate.Response) []error {
+	storageSLOs := make([]prometheus.StorageSLO, 0, len(result.PrometheusSLOs))
+	for _, s := range result.PrometheusSLOs {
+		storageSLOs = append(storageSLOs, prometheus.StorageSLO{SLO: s.SLO, Rules: s.SLORules})
+	}
+
+	var errs []error
+	for _, err := range prometheus.LintRules(storageSLOs) {
+		errs = append(errs, fmt.Errorf("invalid generated rule: %w", err))
+	}
+
+	return errs
+}
+
+// overlapWarnings returns the warnings of the SLOs that measure the same SLI.
+func overlapWarnings(slos []prometheus.SLO) []string {
+	warnings := []string{}
+	for _, o := range prometheus.FindOverlappingSLOs(slos) {
+		warnings = append(warnings, o.String())
+	}
+
+	return warnings
+}
+
+// sliLintWarnings returns the warnings of the SLI queries anti-patterns, with the lint rule IDs.
+func sliLintWarnings(slos []prometheus.SLO, disabledRules []string) []string {
+	warnings := []string{}
+	for _, f := range prometheus.LintSLIs(slos, disabledRules) {
+		warnings = append(warnings, f.String())
+	}
+
+	return warnings
+}
+
+// retentionWarnings returns the warnings of the SLOs with windows that exceed the metrics
+// retention. A 0 retention doesn't check anything.
+func retentionWarnings(slos []prometheus.SLO, retention time.Duration) []string {
+	warnings := []string{}
+	for _, r := range prometheus.FindRetentionExceeded(slos, retention) {
+		warnings = append(warnings, r.String())
+	}
+
+	return warnings
 }
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/slok/sloth/internal/log"
+)
+
+// testValidateWarningsSpec has 2 SLOs with a 30d time window, so they have a warning each with
+// a 15d metrics retention.
+const testValidateWarningsSpec = `
+version: "prometheus/v1"
+service: "myservice"
+slos:
+  - name: "requests-availability"
+    objective: 99.9
+    sli:
+      events:
+        error_query: sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))
+        total_query: sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+  - name: "requests-latency"
+    objective: 99
+    sli:
+      events:
+        error_query: sum(rate(http_request_duration_seconds_count{job="other",code=~"(5..|429)"}[{{.window}}]))
+        total_query: sum(rate(http_request_duration_seconds_count{job="other"}[{{.window}}]))
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`
+
+func TestValidateMaxWarnings(t *testing.T) {
+	tests := map[string]struct {
+		args   []string
+		expErr bool
+	}{
+		"By default any number of warnings should be allowed.": {
+			args: []string{},
+		},
+
+		"Allowing any number of warnings should not fail.": {
+			args: []string{"--max-warnings=-1"},
+		},
+
+		"Having less warnings than the maximum should not fail.": {
+			args: []string{"--max-warnings", "3"},
+		},
+
+		"Having the maximum warnings should not fail.": {
+			args: []string{"--max-warnings", "2"},
+		},
+
+		"Having more warnings than the maximum should fail.": {
+			args:   []string{"--max-warnings", "1"},
+			expErr: true,
+		},
+
+		"Having warnings when no warnings are allowed should fail.": {
+			args:   []string{"--max-warnings", "0"},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			dir := t.TempDir()
+			require.NoError(os.WriteFile(filepath.Join(dir, "slos.yaml"), []byte(testValidateWarningsSpec), 0644))
+
+			app := kingpin.New("test-app", "Test application.")
+			cmd := NewValidateCommand(app)
+			args := append([]string{"validate", "-i", dir, "--metrics-retention", "15d", "--sli-plugins-path", dir}, test.args...)
+			_, err := app.Parse(args)
+			require.NoError(err)
+
+			err = cmd.Run(context.TODO(), RootConfig{Logger: log.Noop})
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slok/sloth/internal/info"
+)
+
+const (
+	reportFormatJSON  = "json"
+	reportFormatSARIF = "sarif"
+	reportFormatJUnit = "junit"
+)
+
+// writeValidationReport writes the validation report of the files in the report format.
+func writeValidationReport(format, outPath string, stdout io.Writer, validations []*fileValidation) error {
+	var out io.Writer = stdout
+	if outPath != "-" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("could not create out file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch format {
+	case reportFormatJSON:
+		return writeJSONValidationReport(out, validations)
+	case reportFormatSARIF:
+		return writeSARIFValidationReport(out, validations)
+	case reportFormatJUnit:
+		return writeJUnitValidationReport(out, validations)
+	}
+
+	return fmt.Errorf("unknown %q report format", format)
+}
+
+// specValidationName returns the name of a spec validation on the reports, the spec index
+// on the file and the service.
+func specValidationName(i int, v specValidation) string {
+	return fmt.Sprintf("#%d %s", i, v.Service)
+}
+
+func errorMessages(errs []error) []string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return msgs
+}
+
+type jsonValidationReport struct {
+	Valid    bool                 `json:"valid"`
+	Errors   int                  `json:"errors"`
+	Warnings int                  `json:"warnings"`
+	Files    []jsonFileValidation `json:"files"`
+}
+
+type jsonFileValidation struct {
+	File   string               `json:"file"`
+	Valid  bool                 `json:"valid"`
+	Errors []string             `json:"errors"`
+	Specs  []jsonSpecValidation `json:"specs"`
+}
+
+type jsonSpecValidation struct {
+	Service  string   `json:"service"`
+	SLOs     []string `json:"slos"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+func writeJSONValidationReport(out io.Writer, validations []*fileValidation) error {
+	report := jsonValidationReport{Valid: true, Files: []jsonFileValidation{}}
+	for _, v := range validations {
+		errs := v.errs()
+		file := jsonFileValidation{File: v.File, Valid: len(errs) == 0, Errors: errorMessages(v.FileErrs), Specs: []jsonSpecValidation{}}
+		for _, s := range v.Specs {
+			spec := jsonSpecValidation{
+				Service:  s.Service,
+				SLOs:     append([]string{}, s.SLOIDs...),
+				Errors:   errorMessages(s.Errs),
+				Warnings: append([]string{}, s.Warnings...),
+			}
+			file.Specs = append(file.Specs, spec)
+		}
+
+		report.Valid = report.Valid && file.Valid
+		report.Errors += len(errs)
+		report.Warnings += len(v.warnings())
+		report.Files = append(report.Files, file)
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(report)
+}
+
+// SARIF 2.1.0 report, only the required properties are used.
+// Spec: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+const (
+	sarifVersion       = "2.1.0"
+	sarifSchema        = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifRuleIDError   = "sloth-validation-error"
+	sarifRuleIDWarning = "sloth-validation-warning"
+)
+
+type sarifReport struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func writeSARIFValidationReport(out io.Writer, validations []*fileValidation) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "sloth",
+			Version:        info.Version,
+			InformationURI: "https://github.com/slok/sloth",
+			Rules: []sarifRule{
+				{ID: sarifRuleIDError, ShortDescription: sarifMessage{Text: "Invalid SLO spec"}},
+				{ID: sarifRuleIDWarning, ShortDescription: sarifMessage{Text: "SLO spec warning"}},
+			},
+		}},
+		Results: []sarifResult{},
+	}
+
+	for _, v := range validations {
+		locations := []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(v.File)},
+		}}}
+		for _, err := range v.errs() {
+			run.Results = append(run.Results, sarifResult{RuleID: sarifRuleIDError, Level: "error", Message: sarifMessage{Text: err.Error()}, Locations: locations})
+		}
+		for _, w := range v.warnings() {
+			run.Results = append(run.Results, sarifResult{RuleID: sarifRuleIDWarning, Level: "warning", Message: sarifMessage{Text: w}, Locations: locations})
+		}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifReport{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// JUnit XML report, every file is a test suite and every SLO spec a test case.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnitValidationReport(out io.Writer, validations []*fileValidation) error {
+	report := junitTestSuites{Name: "sloth validate"}
+	for _, v := range validations {
+		suite := junitTestSuite{Name: v.File}
+
+		if len(v.FileErrs) > 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "file",
+				ClassName: v.File,
+				Failure:   &junitFailure{Message: "invalid file", Text: strings.Join(errorMessages(v.FileErrs), "\n")},
+			})
+		}
+
+		for i, s := range v.Specs {
+			c := junitTestCase{
+				Name:      specValidationName(i, s),
+				ClassName: v.File,
+				SystemOut: strings.Join(s.Warnings, "\n"),
+			}
+			if len(s.Errs) > 0 {
+				c.Failure = &junitFailure{Message: "invalid SLO spec", Text: strings.Join(errorMessages(s.Errs), "\n")}
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+
+		for _, c := range suite.Cases {
+			suite.Tests++
+			if c.Failure != nil {
+				suite.Failures++
+			}
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	_, err := io.WriteString(out, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	err = enc.Encode(report)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, "\n")
+	return err
+}
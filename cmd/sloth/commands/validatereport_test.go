@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValidations() []*fileValidation {
+	return []*fileValidation{
+		{
+			File: "slos/valid.yaml",
+			Specs: []specValidation{
+				{Service: "svc1", SLOs: 2, SLOIDs: []string{"svc1-slo1", "svc1-slo2"}, Warnings: []string{`"svc1-slo1" SLO 30d window is longer than the 15d metrics retention`}},
+			},
+		},
+		{
+			File:     "slos/invalid.yaml",
+			FileErrs: []error{errors.New("could not split multi spec file: invalid YAML")},
+			Specs: []specValidation{
+				{Service: "svc2", SLOs: 1, SLOIDs: []string{"svc2-slo1"}},
+				{Service: "svc3", SLOs: 1, SLOIDs: []string{"svc3-slo1"}, Errs: []error{errors.New("invalid objective"), errors.New("missing SLI")}},
+			},
+		},
+	}
+}
+
+func TestWriteValidationReport(t *testing.T) {
+	tests := map[string]struct {
+		format     string
+		expOutFile string
+	}{
+		"A JSON validation report should have the errors and warnings keyed by file and SLO spec.": {
+			format:     reportFormatJSON,
+			expOutFile: "testdata/validate-report.json",
+		},
+
+		"A SARIF validation report should have the errors and warnings as results of the files.": {
+			format:     reportFormatSARIF,
+			expOutFile: "testdata/validate-report.sarif",
+		},
+
+		"A JUnit validation report should have a test suite per file and a test case per SLO spec.": {
+			format:     reportFormatJUnit,
+			expOutFile: "testdata/validate-report.xml",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var out bytes.Buffer
+			err := writeValidationReport(test.format, "-", &out, newTestValidations())
+			require.NoError(err)
+
+			expOut, err := os.ReadFile(test.expOutFile)
+			require.NoError(err)
+			assert.Equal(string(expOut), out.String())
+		})
+	}
+}
@@ -22,16 +22,56 @@ func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.
 	config := commands.NewRootConfig(app)
 
 	// Setup commands (registers flags).
+	checkQueriesCmd := commands.NewCheckQueriesCommand(app)
+	cliSchemaCmd := commands.NewCLISchemaCommand(app)
+	compatCheckCmd := commands.NewCompatCheckCommand(app)
+	dashboardCmd := commands.NewDashboardCommand(app)
+	diffCmd := commands.NewDiffCommand(app)
+	doctorCmd := commands.NewDoctorCommand(app)
+	exportMetricsCmd := commands.NewExportMetricsCommand(app)
+	fmtCmd := commands.NewFmtCommand(app)
 	generateCmd := commands.NewGenerateCommand(app)
+	incidentCmd := commands.NewIncidentCommand(app)
 	kubeCtrlCmd := commands.NewKubeControllerCommand(app)
+	kubeWebhookCmd := commands.NewKubeWebhookCommand(app)
+	mergeCmd := commands.NewMergeCommand(app)
+	pluginsInstallCmd := commands.NewPluginsInstallCommand(app)
+	previewAlertCmd := commands.NewPreviewAlertCommand(app)
+	previewRoutesCmd := commands.NewPreviewRoutesCommand(app)
+	queryCmd := commands.NewQueryCommand(app)
+	renameLabelCmd := commands.NewRenameLabelCommand(app)
+	renameServiceCmd := commands.NewRenameServiceCommand(app)
+	reportCmd := commands.NewReportCommand(app)
+	selfUpdateCmd := commands.NewSelfUpdateCommand(app)
+	serveCmd := commands.NewServeCommand(app)
 	validateCmd := commands.NewValidateCommand(app)
 	versionCmd := commands.NewVersionCommand(app)
 
 	cmds := map[string]commands.Command{
-		generateCmd.Name(): generateCmd,
-		kubeCtrlCmd.Name(): kubeCtrlCmd,
-		validateCmd.Name(): validateCmd,
-		versionCmd.Name():  versionCmd,
+		checkQueriesCmd.Name():   checkQueriesCmd,
+		cliSchemaCmd.Name():      cliSchemaCmd,
+		compatCheckCmd.Name():    compatCheckCmd,
+		dashboardCmd.Name():      dashboardCmd,
+		diffCmd.Name():           diffCmd,
+		doctorCmd.Name():         doctorCmd,
+		exportMetricsCmd.Name():  exportMetricsCmd,
+		fmtCmd.Name():            fmtCmd,
+		generateCmd.Name():       generateCmd,
+		incidentCmd.Name():       incidentCmd,
+		kubeCtrlCmd.Name():       kubeCtrlCmd,
+		kubeWebhookCmd.Name():    kubeWebhookCmd,
+		mergeCmd.Name():          mergeCmd,
+		pluginsInstallCmd.Name(): pluginsInstallCmd,
+		previewAlertCmd.Name():   previewAlertCmd,
+		previewRoutesCmd.Name():  previewRoutesCmd,
+		queryCmd.Name():          queryCmd,
+		renameLabelCmd.Name():    renameLabelCmd,
+		renameServiceCmd.Name():  renameServiceCmd,
+		reportCmd.Name():         reportCmd,
+		selfUpdateCmd.Name():     selfUpdateCmd,
+		serveCmd.Name():          serveCmd,
+		validateCmd.Name():       validateCmd,
+		versionCmd.Name():        versionCmd,
 	}
 
 	// Parse commandline.
@@ -45,6 +85,10 @@ func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.
 	config.Stdout = stdout
 	config.Stderr = stderr
 	config.Logger = getLogger(*config)
+	config.HTTPClient, err = commands.NewHTTPClient(*config)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP configuration: %w", err)
+	}
 
 	// Execute command.
 	err = cmds[cmdName].Run(ctx, *config)
@@ -3,6 +3,7 @@ module github.com/slok/sloth
 go 1.16
 
 require (
+	cloud.google.com/go/compute v1.19.3 // indirect
 	github.com/go-playground/validator/v10 v10.6.1
 	github.com/oklog/run v1.1.0
 	github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring v0.48.1
@@ -13,10 +14,19 @@ require (
 	github.com/sirupsen/logrus v1.8.1
 	github.com/slok/reload v0.0.0-20210626084015-0a501536aad9
 	github.com/spotahome/kooper/v2 v2.0.0-rc.2
-	github.com/stretchr/testify v1.7.0
+	github.com/stretchr/objx v0.5.1 // indirect
+	github.com/stretchr/testify v1.8.2
 	github.com/traefik/yaegi v0.9.19
+	golang.org/x/net v0.11.0 // indirect
+	golang.org/x/sync v0.2.0 // indirect
+	golang.org/x/term v0.10.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/grpc v1.55.0
+	google.golang.org/protobuf v1.30.0
 	gopkg.in/alecthomas/kingpin.v2 v2.2.6
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/api v0.21.1
 	k8s.io/apimachinery v0.21.1
 	k8s.io/client-go v0.21.1
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/slok/sloth/pkg/budget"
 )
 
 // Severity is the type of alert.
@@ -34,6 +36,7 @@ type MWMBAlert struct {
 	BurnRateFactor float64
 	ErrorBudget    float64
 	Severity       Severity
+	For            time.Duration
 }
 
 // MWMBAlertGroup what represents all the alerts of an SLO.
@@ -59,48 +62,43 @@ type SLO struct {
 	ID         string
 	TimeWindow time.Duration
 	Objective  float64
+	// Windows are the alert windows of the SLO, if not set it will use the default windows.
+	Windows *Windows
 }
 
+// GenerateMWMBAlerts generates the SLO alerts. The alert windows are based on the alert
+// windows SLO period (30 day by default), with other time windows the alert windows are
+// scaled to the same proportion of the SLO time window, so they consume the same error
+// budget percent.
 func (g generator) GenerateMWMBAlerts(ctx context.Context, slo SLO) (*MWMBAlertGroup, error) {
-	if slo.TimeWindow != 30*24*time.Hour {
-		return nil, fmt.Errorf("only 30 day SLO time window is supported")
+	if slo.TimeWindow < minTimeWindow {
+		return nil, fmt.Errorf("SLO time window must be at least %s", minTimeWindow)
+	}
+
+	windows := DefaultWindows
+	if slo.Windows != nil {
+		windows = *slo.Windows
 	}
 
 	errorBudget := 100 - slo.Objective
+	newAlert := func(id string, severity Severity, sw SeverityWindows, w Window) MWMBAlert {
+		longWindow := budget.ScaleWindow(w.LongWindow, windows.SLOPeriod, slo.TimeWindow)
+		return MWMBAlert{
+			ID:             fmt.Sprintf("%s-%s", slo.ID, id),
+			ShortWindow:    budget.ScaleWindow(w.ShortWindow, windows.SLOPeriod, slo.TimeWindow),
+			LongWindow:     longWindow,
+			BurnRateFactor: budget.BurnFactor(slo.TimeWindow, longWindow, w.ErrorBudgetPercent/100),
+			ErrorBudget:    errorBudget,
+			Severity:       severity,
+			For:            sw.For,
+		}
+	}
 
 	group := MWMBAlertGroup{
-		PageQuick: MWMBAlert{
-			ID:             fmt.Sprintf("%s-page-quick", slo.ID),
-			ShortWindow:    windowPageQuickShort,
-			LongWindow:     windowPageQuickLong,
-			BurnRateFactor: speedPageQuick,
-			ErrorBudget:    errorBudget,
-			Severity:       PageAlertSeverity,
-		},
-		PageSlow: MWMBAlert{
-			ID:             fmt.Sprintf("%s-page-slow", slo.ID),
-			ShortWindow:    windowPageSlowShort,
-			LongWindow:     windowPageSlowLong,
-			BurnRateFactor: speedPageSlow,
-			ErrorBudget:    errorBudget,
-			Severity:       PageAlertSeverity,
-		},
-		TicketQuick: MWMBAlert{
-			ID:             fmt.Sprintf("%s-ticket-quick", slo.ID),
-			ShortWindow:    windowTicketQuickShort,
-			LongWindow:     windowTicketQuickLong,
-			BurnRateFactor: speedTicketQuick,
-			ErrorBudget:    errorBudget,
-			Severity:       TicketAlertSeverity,
-		},
-		TicketSlow: MWMBAlert{
-			ID:             fmt.Sprintf("%s-ticket-slow", slo.ID),
-			ShortWindow:    windowTicketSlowShort,
-			LongWindow:     windowTicketSlowLong,
-			BurnRateFactor: speedTicketSlow,
-			ErrorBudget:    errorBudget,
-			Severity:       TicketAlertSeverity,
-		},
+		PageQuick:   newAlert("page-quick", PageAlertSeverity, windows.Page, windows.Page.Quick),
+		PageSlow:    newAlert("page-slow", PageAlertSeverity, windows.Page, windows.Page.Slow),
+		TicketQuick: newAlert("ticket-quick", TicketAlertSeverity, windows.Ticket, windows.Ticket.Quick),
+		TicketSlow:  newAlert("ticket-slow", TicketAlertSeverity, windows.Ticket, windows.Ticket.Slow),
 	}
 
 	return &group, nil
@@ -118,6 +116,9 @@ const (
 	windowTicketSlowShort  = 6 * time.Hour
 	windowTicketSlowLong   = 3 * 24 * time.Hour
 
+	// minTimeWindow is the minimum supported SLO time window.
+	minTimeWindow = 24 * time.Hour
+
 	// Error budget percents for 30 day time window.
 	ErrBudgetPercentPageQuick30D   = 2
 	ErrBudgetPercentPageSlow30D    = 5
@@ -125,27 +126,6 @@ const (
 	ErrBudgetPercentTicketSlow30D  = 10
 )
 
-var (
-	// Error budget speeds based on a 30 day window, however once we have the factor (speed)
-	// the value can be used with any time window, that's why we calculate here.
-	// We could hardcode the factors but this way we know how are generated and we use it
-	// as as documention.
-	baseWindow       = 30 * 24 * time.Hour
-	speedPageQuick   = getBurnRateFactor(baseWindow, ErrBudgetPercentPageQuick30D, windowPageQuickLong)     // Speed: 14.4.
-	speedPageSlow    = getBurnRateFactor(baseWindow, ErrBudgetPercentPageSlow30D, windowPageSlowLong)       // Speed: 6.
-	speedTicketQuick = getBurnRateFactor(baseWindow, ErrBudgetPercentTicketQuick30D, windowTicketQuickLong) // Speed: 3.
-	speedTicketSlow  = getBurnRateFactor(baseWindow, ErrBudgetPercentTicketSlow30D, windowTicketSlowLong)   // Speed: 1.
-)
-
-// getBurnRateFactor calculates the burnRateFactor (speed) needed to consume all the error budget available percent
-// in a specific time window taking into account the total time window.
-func getBurnRateFactor(totalWindow time.Duration, errorBudgetPercent float64, consumptionWindow time.Duration) float64 {
-	// First get the total hours required to consume the % of the error budget in the total window.
-	hoursRequiredConsumption := errorBudgetPercent * totalWindow.Hours() / 100
-
-	// Now calculate how much is the factor required for the hours consumption, in case we would need to use
-	// a different time window (e.g: hours required: 36h, if we want to do it in 6h: would be `x6`).
-	speed := hoursRequiredConsumption / consumptionWindow.Hours()
-
-	return speed
-}
+// baseWindow is the SLO time window of the default alert windows and error budget percents,
+// the resulting burn rate factors (speeds) are 14.4, 6, 3 and 1.
+const baseWindow = 30 * 24 * time.Hour
@@ -16,10 +16,10 @@ func TestGenerateMWMBAlerts(t *testing.T) {
 		expAlerts *alert.MWMBAlertGroup
 		expErr    bool
 	}{
-		"Generating alerts different to 30 day time window should fail.": {
+		"Generating alerts with a time window less than 1 day should fail.": {
 			slo: alert.SLO{
 				ID:         "test",
-				TimeWindow: 31 * 24 * time.Hour,
+				TimeWindow: 12 * time.Hour,
 				Objective:  99.9,
 			},
 			expErr: true,
@@ -67,6 +67,149 @@ func TestGenerateMWMBAlerts(t *testing.T) {
 				},
 			},
 		},
+
+		"Generating a 7 day time window alerts should generate the alerts with the windows scaled to the time window.": {
+			slo: alert.SLO{
+				ID:         "test",
+				TimeWindow: 7 * 24 * time.Hour,
+				Objective:  99.9,
+			},
+			expAlerts: &alert.MWMBAlertGroup{
+				PageQuick: alert.MWMBAlert{
+					ID:             "test-page-quick",
+					ShortWindow:    1 * time.Minute,
+					LongWindow:     14 * time.Minute,
+					BurnRateFactor: 14.4,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.PageAlertSeverity,
+				},
+				PageSlow: alert.MWMBAlert{
+					ID:             "test-page-slow",
+					ShortWindow:    7 * time.Minute,
+					LongWindow:     84 * time.Minute,
+					BurnRateFactor: 6,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.PageAlertSeverity,
+				},
+
+				TicketQuick: alert.MWMBAlert{
+					ID:             "test-ticket-quick",
+					ShortWindow:    28 * time.Minute,
+					LongWindow:     336 * time.Minute,
+					BurnRateFactor: 3,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.TicketAlertSeverity,
+				},
+				TicketSlow: alert.MWMBAlert{
+					ID:             "test-ticket-slow",
+					ShortWindow:    84 * time.Minute,
+					LongWindow:     1008 * time.Minute,
+					BurnRateFactor: 1,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.TicketAlertSeverity,
+				},
+			},
+		},
+
+		"Generating a 90 day time window alerts should generate the alerts with the windows scaled to the time window.": {
+			slo: alert.SLO{
+				ID:         "test",
+				TimeWindow: 90 * 24 * time.Hour,
+				Objective:  99.9,
+			},
+			expAlerts: &alert.MWMBAlertGroup{
+				PageQuick: alert.MWMBAlert{
+					ID:             "test-page-quick",
+					ShortWindow:    15 * time.Minute,
+					LongWindow:     3 * time.Hour,
+					BurnRateFactor: 14.4,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.PageAlertSeverity,
+				},
+				PageSlow: alert.MWMBAlert{
+					ID:             "test-page-slow",
+					ShortWindow:    90 * time.Minute,
+					LongWindow:     18 * time.Hour,
+					BurnRateFactor: 6,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.PageAlertSeverity,
+				},
+
+				TicketQuick: alert.MWMBAlert{
+					ID:             "test-ticket-quick",
+					ShortWindow:    6 * time.Hour,
+					LongWindow:     3 * 24 * time.Hour,
+					BurnRateFactor: 3,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.TicketAlertSeverity,
+				},
+				TicketSlow: alert.MWMBAlert{
+					ID:             "test-ticket-slow",
+					ShortWindow:    18 * time.Hour,
+					LongWindow:     9 * 24 * time.Hour,
+					BurnRateFactor: 1,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.TicketAlertSeverity,
+				},
+			},
+		},
+		"Generating alerts with custom alert windows should generate the alerts with the alert windows.": {
+			slo: alert.SLO{
+				ID:         "test",
+				TimeWindow: 28 * 24 * time.Hour,
+				Objective:  99.9,
+				Windows: &alert.Windows{
+					Name:      "test",
+					SLOPeriod: 28 * 24 * time.Hour,
+					Page: alert.SeverityWindows{
+						For:   2 * time.Minute,
+						Quick: alert.Window{ErrorBudgetPercent: 2, ShortWindow: 5 * time.Minute, LongWindow: 1 * time.Hour},
+						Slow:  alert.Window{ErrorBudgetPercent: 5, ShortWindow: 30 * time.Minute, LongWindow: 6 * time.Hour},
+					},
+					Ticket: alert.SeverityWindows{
+						Quick: alert.Window{ErrorBudgetPercent: 10, ShortWindow: 2 * time.Hour, LongWindow: 1 * 24 * time.Hour},
+						Slow:  alert.Window{ErrorBudgetPercent: 10, ShortWindow: 6 * time.Hour, LongWindow: 3 * 24 * time.Hour},
+					},
+				},
+			},
+			expAlerts: &alert.MWMBAlertGroup{
+				PageQuick: alert.MWMBAlert{
+					ID:             "test-page-quick",
+					ShortWindow:    5 * time.Minute,
+					LongWindow:     1 * time.Hour,
+					BurnRateFactor: 13.44,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.PageAlertSeverity,
+					For:            2 * time.Minute,
+				},
+				PageSlow: alert.MWMBAlert{
+					ID:             "test-page-slow",
+					ShortWindow:    30 * time.Minute,
+					LongWindow:     6 * time.Hour,
+					BurnRateFactor: 5.6,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.PageAlertSeverity,
+					For:            2 * time.Minute,
+				},
+
+				TicketQuick: alert.MWMBAlert{
+					ID:             "test-ticket-quick",
+					ShortWindow:    2 * time.Hour,
+					LongWindow:     1 * 24 * time.Hour,
+					BurnRateFactor: 2.8,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.TicketAlertSeverity,
+				},
+				TicketSlow: alert.MWMBAlert{
+					ID:             "test-ticket-slow",
+					ShortWindow:    6 * time.Hour,
+					LongWindow:     3 * 24 * time.Hour,
+					BurnRateFactor: 0.9333,
+					ErrorBudget:    0.09999999999999432,
+					Severity:       alert.TicketAlertSeverity,
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
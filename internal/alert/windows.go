@@ -0,0 +1,364 @@
+package alert
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+)
+
+// Windows are the multiwindow, multi-burn rate alert windows of an SLO period. The SLOs
+// with a different time window scale the alert windows to their time window.
+type Windows struct {
+	Name      string
+	SLOPeriod time.Duration
+	Page      SeverityWindows
+	Ticket    SeverityWindows
+}
+
+// SeverityWindows are the alert windows of an alert severity.
+type SeverityWindows struct {
+	// For is the `for` duration of the severity alert, it's not scaled.
+	For   time.Duration
+	Quick Window
+	Slow  Window
+}
+
+// Window is a multiwindow alert, it fires when the error budget percent of the SLO
+// period is consumed on the long and short windows.
+type Window struct {
+	ErrorBudgetPercent float64
+	ShortWindow        time.Duration
+	LongWindow         time.Duration
+}
+
+// DefaultWindows are the 30 day SLO period alert windows recommended by the Google SRE
+// workbook, used when an SLO doesn't select any.
+var DefaultWindows = Windows{
+	Name:      "default",
+	SLOPeriod: baseWindow,
+	Page: SeverityWindows{
+		Quick: Window{ErrorBudgetPercent: ErrBudgetPercentPageQuick30D, ShortWindow: windowPageQuickShort, LongWindow: windowPageQuickLong},
+		Slow:  Window{ErrorBudgetPercent: ErrBudgetPercentPageSlow30D, ShortWindow: windowPageSlowShort, LongWindow: windowPageSlowLong},
+	},
+	Ticket: SeverityWindows{
+		Quick: Window{ErrorBudgetPercent: ErrBudgetPercentTicketQuick30D, ShortWindow: windowTicketQuickShort, LongWindow: windowTicketQuickLong},
+		Slow:  Window{ErrorBudgetPercent: ErrBudgetPercentTicketSlow30D, ShortWindow: windowTicketSlowShort, LongWindow: windowTicketSlowLong},
+	},
+}
+
+var windowsNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_.]*$`)
+
+// Validate validates the alert windows.
+func (w Windows) Validate() error {
+	if !windowsNameRegexp.MatchString(w.Name) {
+		return fmt.Errorf("invalid %q alert windows name", w.Name)
+	}
+
+	if w.SLOPeriod < minTimeWindow {
+		return fmt.Errorf("%q alert windows SLO period must be at least %s", w.Name, minTimeWindow)
+	}
+
+	for _, sw := range []struct {
+		id string
+		w  SeverityWindows
+	}{{id: "page", w: w.Page}, {id: "ticket", w: w.Ticket}} {
+		if sw.w.For < 0 {
+			return fmt.Errorf("%q alert windows %s for can't be negative", w.Name, sw.id)
+		}
+
+		for _, aw := range []struct {
+			id string
+			w  Window
+		}{{id: sw.id + " quick", w: sw.w.Quick}, {id: sw.id + " slow", w: sw.w.Slow}} {
+			switch {
+			case aw.w.ErrorBudgetPercent <= 0 || aw.w.ErrorBudgetPercent > 100:
+				return fmt.Errorf("%q alert windows %s error budget percent must be greater than 0 and less or equal than 100", w.Name, aw.id)
+			case aw.w.ShortWindow <= 0:
+				return fmt.Errorf("%q alert windows %s short window is required", w.Name, aw.id)
+			case aw.w.LongWindow <= aw.w.ShortWindow:
+				return fmt.Errorf("%q alert windows %s long window must be greater than the short window", w.Name, aw.id)
+			case aw.w.LongWindow > w.SLOPeriod:
+				return fmt.Errorf("%q alert windows %s long window can't be greater than the SLO period", w.Name, aw.id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WindowsCatalog is a catalog of alert windows.
+type WindowsCatalog struct {
+	windows []Windows
+	tiers   WindowsTiers
+}
+
+// WindowsTiers select the alert windows of the SLOs by their service tier label (e.g
+// `tier: tier1`), so the alerts paging aggressiveness scales with the service criticality.
+type WindowsTiers struct {
+	// Label is the SLO label with the service tier (e.g `tier`).
+	Label string
+	// Windows are the alert windows names by service tier (e.g `tier1`).
+	Windows map[string]string
+}
+
+// NewWindowsCatalog returns a new alert windows catalog, the windows names and SLO periods
+// can't be repeated.
+func NewWindowsCatalog(windows ...Windows) (*WindowsCatalog, error) {
+	return NewTieredWindowsCatalog(WindowsTiers{}, windows...)
+}
+
+// NewTieredWindowsCatalog returns a new alert windows catalog that selects the alert windows
+// of the SLOs by their service tier. The tiers alert windows are only selected by tier or by
+// name, so these can have the same SLO period as other alert windows.
+func NewTieredWindowsCatalog(tiers WindowsTiers, windows ...Windows) (*WindowsCatalog, error) {
+	if len(tiers.Windows) > 0 && tiers.Label == "" {
+		return nil, fmt.Errorf("alert windows tiers label is required")
+	}
+
+	names := map[string]struct{}{}
+	periods := map[time.Duration]string{}
+	for _, w := range windows {
+		err := w.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := names[w.Name]; ok {
+			return nil, fmt.Errorf("%q alert windows are repeated", w.Name)
+		}
+		names[w.Name] = struct{}{}
+
+		if tiers.isTierWindows(w.Name) {
+			continue
+		}
+
+		if name, ok := periods[w.SLOPeriod]; ok {
+			return nil, fmt.Errorf("%q and %q alert windows have the same %s SLO period", name, w.Name, prommodel.Duration(w.SLOPeriod))
+		}
+		periods[w.SLOPeriod] = w.Name
+	}
+
+	for tier, name := range tiers.Windows {
+		if _, ok := names[name]; !ok {
+			return nil, fmt.Errorf("%q alert windows of %q tier are missing on the alert windows catalog", name, tier)
+		}
+	}
+
+	return &WindowsCatalog{windows: windows, tiers: tiers}, nil
+}
+
+func (t WindowsTiers) isTierWindows(name string) bool {
+	for _, n := range t.Windows {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get returns the alert windows of the catalog by name.
+func (w *WindowsCatalog) Get(name string) (*Windows, error) {
+	if w != nil {
+		for i, ws := range w.windows {
+			if ws.Name == name {
+				return &w.windows[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%q alert windows are missing on the alert windows catalog", name)
+}
+
+// GetForSLOPeriod returns the alert windows of the catalog for an SLO period, if there
+// are none it will return nil. The tiers alert windows are not selected by SLO period.
+func (w *WindowsCatalog) GetForSLOPeriod(period time.Duration) *Windows {
+	if w == nil {
+		return nil
+	}
+
+	for i, ws := range w.windows {
+		if ws.SLOPeriod == period && !w.tiers.isTierWindows(ws.Name) {
+			return &w.windows[i]
+		}
+	}
+
+	return nil
+}
+
+// GetForLabels returns the alert windows of the catalog for the service tier of the SLO
+// labels, if the SLO doesn't have a tier with alert windows it will return nil.
+func (w *WindowsCatalog) GetForLabels(labels map[string]string) *Windows {
+	if w == nil || w.tiers.Label == "" {
+		return nil
+	}
+
+	tier, ok := labels[w.tiers.Label]
+	if !ok {
+		return nil
+	}
+
+	name, ok := w.tiers.Windows[tier]
+	if !ok {
+		return nil
+	}
+
+	// The tiers alert windows are on the catalog, checked when the catalog is created.
+	windows, _ := w.Get(name)
+	return windows
+}
+
+// NewWindowsCatalogFromYAML loads an alert windows catalog from YAML files data.
+//
+// Example YAML catalog:
+//
+//	alert_windows:
+//	  - name: google-28d
+//	    slo_period: 28d
+//	    page:
+//	      for: 2m
+//	      quick: {error_budget_percent: 2, short_window: 5m, long_window: 1h}
+//	      slow: {error_budget_percent: 5, short_window: 30m, long_window: 6h}
+//	    ticket:
+//	      quick: {error_budget_percent: 10, short_window: 2h, long_window: 1d}
+//	      slow: {error_budget_percent: 10, short_window: 6h, long_window: 3d}
+//	tiers:
+//	  label: tier
+//	  windows:
+//	    tier1: google-28d
+func NewWindowsCatalogFromYAML(datas ...[]byte) (*WindowsCatalog, error) {
+	windows := []Windows{}
+	tiers := WindowsTiers{Windows: map[string]string{}}
+	for _, data := range datas {
+		catalog := yamlWindowsCatalog{}
+		err := yaml.UnmarshalStrict(data, &catalog)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal YAML alert windows catalog: %w", err)
+		}
+
+		if catalog.Tiers != nil {
+			if tiers.Label != "" && tiers.Label != catalog.Tiers.Label {
+				return nil, fmt.Errorf("alert windows tiers have different labels: %q and %q", tiers.Label, catalog.Tiers.Label)
+			}
+			tiers.Label = catalog.Tiers.Label
+
+			for tier, name := range catalog.Tiers.Windows {
+				if _, ok := tiers.Windows[tier]; ok {
+					return nil, fmt.Errorf("%q tier alert windows are repeated", tier)
+				}
+				tiers.Windows[tier] = name
+			}
+		}
+
+		for _, yw := range catalog.AlertWindows {
+			w, err := yw.toWindows()
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q alert windows: %w", yw.Name, err)
+			}
+			windows = append(windows, *w)
+		}
+	}
+
+	return NewTieredWindowsCatalog(tiers, windows...)
+}
+
+type yamlWindowsCatalog struct {
+	AlertWindows []yamlWindows     `yaml:"alert_windows"`
+	Tiers        *yamlWindowsTiers `yaml:"tiers,omitempty"`
+}
+
+type yamlWindowsTiers struct {
+	Label   string            `yaml:"label"`
+	Windows map[string]string `yaml:"windows"`
+}
+
+type yamlWindows struct {
+	Name      string              `yaml:"name"`
+	SLOPeriod string              `yaml:"slo_period"`
+	Page      yamlSeverityWindows `yaml:"page"`
+	Ticket    yamlSeverityWindows `yaml:"ticket"`
+}
+
+type yamlSeverityWindows struct {
+	For   string     `yaml:"for,omitempty"`
+	Quick yamlWindow `yaml:"quick"`
+	Slow  yamlWindow `yaml:"slow"`
+}
+
+type yamlWindow struct {
+	ErrorBudgetPercent float64 `yaml:"error_budget_percent"`
+	ShortWindow        string  `yaml:"short_window"`
+	LongWindow         string  `yaml:"long_window"`
+}
+
+func (y yamlWindows) toWindows() (*Windows, error) {
+	period, err := prommodel.ParseDuration(y.SLOPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLO period: %w", err)
+	}
+
+	page, err := y.Page.toSeverityWindows()
+	if err != nil {
+		return nil, fmt.Errorf("invalid page windows: %w", err)
+	}
+
+	ticket, err := y.Ticket.toSeverityWindows()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket windows: %w", err)
+	}
+
+	return &Windows{
+		Name:      y.Name,
+		SLOPeriod: time.Duration(period),
+		Page:      *page,
+		Ticket:    *ticket,
+	}, nil
+}
+
+func (y yamlSeverityWindows) toSeverityWindows() (*SeverityWindows, error) {
+	var forDuration prommodel.Duration
+	if y.For != "" {
+		d, err := prommodel.ParseDuration(y.For)
+		if err != nil {
+			return nil, fmt.Errorf("invalid for: %w", err)
+		}
+		forDuration = d
+	}
+
+	quick, err := y.Quick.toWindow()
+	if err != nil {
+		return nil, fmt.Errorf("invalid quick window: %w", err)
+	}
+
+	slow, err := y.Slow.toWindow()
+	if err != nil {
+		return nil, fmt.Errorf("invalid slow window: %w", err)
+	}
+
+	return &SeverityWindows{
+		For:   time.Duration(forDuration),
+		Quick: *quick,
+		Slow:  *slow,
+	}, nil
+}
+
+func (y yamlWindow) toWindow() (*Window, error) {
+	short, err := prommodel.ParseDuration(y.ShortWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid short window: %w", err)
+	}
+
+	long, err := prommodel.ParseDuration(y.LongWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid long window: %w", err)
+	}
+
+	return &Window{
+		ErrorBudgetPercent: y.ErrorBudgetPercent,
+		ShortWindow:        time.Duration(short),
+		LongWindow:         time.Duration(long),
+	}, nil
+}
@@ -0,0 +1,255 @@
+package alert_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/alert"
+)
+
+func TestNewWindowsCatalogFromYAML(t *testing.T) {
+	const testCatalog = `
+alert_windows:
+  - name: test-28d
+    slo_period: 28d
+    page:
+      for: 2m
+      quick: {error_budget_percent: 2, short_window: 5m, long_window: 1h}
+      slow: {error_budget_percent: 5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 10, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 10, short_window: 6h, long_window: 3d}
+`
+	const testCatalog2 = `
+alert_windows:
+  - name: test-7d
+    slo_period: 7d
+    page:
+      quick: {error_budget_percent: 8, short_window: 5m, long_window: 1h}
+      slow: {error_budget_percent: 12.5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 20, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 42, short_window: 6h, long_window: 3d}
+`
+
+	tests := map[string]struct {
+		catalogs []string
+		expErr   bool
+	}{
+		"Invalid YAML should fail.": {
+			catalogs: []string{`alert_windows: {`},
+			expErr:   true,
+		},
+
+		"Unknown fields should fail.": {
+			catalogs: []string{testCatalog + "    speed: 14.4\n"},
+			expErr:   true,
+		},
+
+		"Invalid durations should fail.": {
+			catalogs: []string{`
+alert_windows:
+  - name: test
+    slo_period: 28d
+    page:
+      quick: {error_budget_percent: 2, short_window: 5 minutes, long_window: 1h}
+`},
+			expErr: true,
+		},
+
+		"Short windows greater than long windows should fail.": {
+			catalogs: []string{`
+alert_windows:
+  - name: test
+    slo_period: 28d
+    page:
+      quick: {error_budget_percent: 2, short_window: 1h, long_window: 5m}
+      slow: {error_budget_percent: 5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 10, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 10, short_window: 6h, long_window: 3d}
+`},
+			expErr: true,
+		},
+
+		"Invalid error budget percents should fail.": {
+			catalogs: []string{`
+alert_windows:
+  - name: test
+    slo_period: 28d
+    page:
+      quick: {error_budget_percent: 0, short_window: 5m, long_window: 1h}
+      slow: {error_budget_percent: 5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 10, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 10, short_window: 6h, long_window: 3d}
+`},
+			expErr: true,
+		},
+
+		"Repeated alert windows on different files should fail.": {
+			catalogs: []string{testCatalog, testCatalog},
+			expErr:   true,
+		},
+
+		"Valid catalogs should load the alert windows.": {
+			catalogs: []string{testCatalog, testCatalog2},
+		},
+
+		"Tiers with missing alert windows should fail.": {
+			catalogs: []string{testCatalog, `
+tiers:
+  label: tier
+  windows:
+    tier1: test-30d
+`},
+			expErr: true,
+		},
+
+		"Tiers without label should fail.": {
+			catalogs: []string{testCatalog, `
+tiers:
+  windows:
+    tier1: test-28d
+`},
+			expErr: true,
+		},
+
+		"Tiers with different labels on different files should fail.": {
+			catalogs: []string{testCatalog, testCatalog2, `
+tiers:
+  label: tier
+  windows:
+    tier1: test-28d
+`, `
+tiers:
+  label: criticality
+  windows:
+    tier2: test-7d
+`},
+			expErr: true,
+		},
+
+		"Tiers alert windows should be able to have the same SLO period as other alert windows.": {
+			catalogs: []string{testCatalog, strings.ReplaceAll(testCatalog, "test-28d", "test-tier1-28d"), `
+tiers:
+  label: tier
+  windows:
+    tier1: test-tier1-28d
+`},
+		},
+
+		"Alert windows with the same SLO period should fail.": {
+			catalogs: []string{testCatalog, strings.ReplaceAll(testCatalog, "test-28d", "test-other-28d")},
+			expErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			datas := [][]byte{}
+			for _, c := range test.catalogs {
+				datas = append(datas, []byte(c))
+			}
+			_, err := alert.NewWindowsCatalogFromYAML(datas...)
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestWindowsCatalogGet(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	catalog, err := alert.NewWindowsCatalogFromYAML([]byte(`
+alert_windows:
+  - name: test-28d
+    slo_period: 28d
+    page:
+      for: 2m
+      quick: {error_budget_percent: 2, short_window: 5m, long_window: 1h}
+      slow: {error_budget_percent: 5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 10, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 10, short_window: 6h, long_window: 3d}
+`))
+	require.NoError(err)
+
+	expWindows := &alert.Windows{
+		Name:      "test-28d",
+		SLOPeriod: 28 * 24 * time.Hour,
+		Page: alert.SeverityWindows{
+			For:   2 * time.Minute,
+			Quick: alert.Window{ErrorBudgetPercent: 2, ShortWindow: 5 * time.Minute, LongWindow: 1 * time.Hour},
+			Slow:  alert.Window{ErrorBudgetPercent: 5, ShortWindow: 30 * time.Minute, LongWindow: 6 * time.Hour},
+		},
+		Ticket: alert.SeverityWindows{
+			Quick: alert.Window{ErrorBudgetPercent: 10, ShortWindow: 2 * time.Hour, LongWindow: 24 * time.Hour},
+			Slow:  alert.Window{ErrorBudgetPercent: 10, ShortWindow: 6 * time.Hour, LongWindow: 3 * 24 * time.Hour},
+		},
+	}
+
+	gotWindows, err := catalog.Get("test-28d")
+	require.NoError(err)
+	assert.Equal(expWindows, gotWindows)
+
+	_, err = catalog.Get("test-30d")
+	assert.Error(err)
+
+	assert.Equal(expWindows, catalog.GetForSLOPeriod(28*24*time.Hour))
+	assert.Nil(catalog.GetForSLOPeriod(30 * 24 * time.Hour))
+}
+
+func TestWindowsCatalogGetForLabels(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	catalog, err := alert.NewWindowsCatalogFromYAML([]byte(`
+alert_windows:
+  - name: test-28d
+    slo_period: 28d
+    page:
+      quick: {error_budget_percent: 2, short_window: 5m, long_window: 1h}
+      slow: {error_budget_percent: 5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 10, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 10, short_window: 6h, long_window: 3d}
+  - name: test-tier1-28d
+    slo_period: 28d
+    page:
+      for: 1m
+      quick: {error_budget_percent: 1, short_window: 5m, long_window: 1h}
+      slow: {error_budget_percent: 2.5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 5, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 5, short_window: 6h, long_window: 3d}
+tiers:
+  label: tier
+  windows:
+    tier1: test-tier1-28d
+`))
+	require.NoError(err)
+
+	// The SLOs of a tier should use the tier alert windows.
+	gotWindows := catalog.GetForLabels(map[string]string{"tier": "tier1", "team": "sre"})
+	require.NotNil(gotWindows)
+	assert.Equal("test-tier1-28d", gotWindows.Name)
+
+	// The SLOs without tier or with tiers without alert windows should not have tier alert windows.
+	assert.Nil(catalog.GetForLabels(map[string]string{"tier": "tier2"}))
+	assert.Nil(catalog.GetForLabels(map[string]string{"team": "sre"}))
+
+	// The tiers alert windows should not be selected by SLO period.
+	assert.Equal("test-28d", catalog.GetForSLOPeriod(28*24*time.Hour).Name)
+}
@@ -143,6 +143,7 @@ func (s Service) generateSLO(ctx context.Context, info info.Info, slo prometheus
 	alertSLO := alert.SLO{
 		ID:         slo.ID,
 		Objective:  slo.Objective,
+		Windows:    slo.AlertWindows,
 		TimeWindow: slo.TimeWindow,
 	}
 	as, err := s.alertGen.GenerateMWMBAlerts(ctx, alertSLO)
@@ -172,14 +173,20 @@ func (s Service) generateSLO(ctx context.Context, info info.Info, slo prometheus
 	}
 	logger.WithValues(log.Kv{"rules": len(alertRules)}).Infof("SLO alert rules generated")
 
+	rules := prometheus.SLORules{
+		SLIErrorRecRules: sliRecordingRules,
+		MetadataRecRules: metaRecordingRules,
+		AlertRules:       alertRules,
+	}
+	err = rules.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Prometheus rules: %w", err)
+	}
+
 	return &SLOResult{
-		SLO:    slo,
-		Alerts: *as,
-		SLORules: prometheus.SLORules{
-			SLIErrorRecRules: sliRecordingRules,
-			MetadataRecRules: metaRecordingRules,
-			AlertRules:       alertRules,
-		},
+		SLO:      slo,
+		Alerts:   *as,
+		SLORules: rules,
 	}, nil
 }
 
@@ -1,7 +1,9 @@
 package generate_test
 
 import (
+	"bytes"
 	"context"
+	"os"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/slok/sloth/internal/alert"
 	"github.com/slok/sloth/internal/app/generate"
 	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/log"
 	"github.com/slok/sloth/internal/prometheus"
 )
 
@@ -349,9 +352,10 @@ or ignoring (sloth_window)
 										"sloth_severity": "page",
 									},
 									Annotations: map[string]string{
-										"p_alert_annot": "p_label_an_1",
-										"summary":       "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
-										"title":         "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+										"allowed_downtime": "43m12s in 30d",
+										"p_alert_annot":    "p_label_an_1",
+										"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+										"title":            "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
 									},
 								},
 								{
@@ -373,9 +377,10 @@ or ignoring (sloth_window)
 										"sloth_severity": "ticket",
 									},
 									Annotations: map[string]string{
-										"t_alert_annot": "t_label_an_1",
-										"summary":       "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
-										"title":         "(ticket) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+										"allowed_downtime": "43m12s in 30d",
+										"t_alert_annot":    "t_label_an_1",
+										"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+										"title":            "(ticket) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
 									},
 								},
 							},
@@ -404,3 +409,62 @@ or ignoring (sloth_window)
 		})
 	}
 }
+
+func TestIntegrationAppServiceGenerateExistingRulesRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		specFile          string
+		existingRulesFile string
+	}{
+		"Using the generated rules as existing rules, shouldn't collide.": {
+			specFile: "../../../examples/getting-started.yml",
+		},
+
+		"Using the committed generated rules as existing rules, shouldn't collide.": {
+			specFile:          "../../../examples/getting-started.yml",
+			existingRulesFile: "../../../examples/_gen/getting-started.yml",
+		},
+
+		"Using the committed generated rules of a multi SLO spec as existing rules, shouldn't collide.": {
+			specFile:          "../../../examples/home-wifi.yml",
+			existingRulesFile: "../../../examples/_gen/home-wifi.yml",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			spec, err := os.ReadFile(test.specFile)
+			require.NoError(err)
+			sloGroup, err := prometheus.NewYAMLSpecLoader(nil).LoadSpec(context.TODO(), spec)
+			require.NoError(err)
+
+			svc, err := generate.NewService(generate.ServiceConfig{})
+			require.NoError(err)
+			resp, err := svc.Generate(context.TODO(), generate.Request{
+				Info:     info.Info{Version: "test-ver", Mode: info.ModeTest, Spec: "prometheus/v1"},
+				SLOGroup: *sloGroup,
+			})
+			require.NoError(err)
+
+			storageSLOs := []prometheus.StorageSLO{}
+			for _, s := range resp.PrometheusSLOs {
+				storageSLOs = append(storageSLOs, prometheus.StorageSLO{SLO: s.SLO, Rules: s.SLORules})
+			}
+
+			// Feed the generated rules back as the existing rules.
+			existingRulesData, err := os.ReadFile(test.existingRulesFile)
+			if test.existingRulesFile == "" {
+				var b bytes.Buffer
+				repo := prometheus.NewIOWriterGroupedRulesYAMLRepo(&b, prometheus.RuleGroupsMeta{}, log.Noop)
+				err = repo.StoreSLOs(context.TODO(), storageSLOs)
+				existingRulesData = b.Bytes()
+			}
+			require.NoError(err)
+
+			existing, err := prometheus.NewExistingRulesFromYAML(existingRulesData)
+			require.NoError(err)
+			assert.NoError(t, existing.Check(storageSLOs))
+		})
+	}
+}
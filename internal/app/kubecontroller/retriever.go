@@ -18,14 +18,39 @@ type RetrieverKubernetesRepository interface {
 	WatchPrometheusServiceLevels(ctx context.Context, ns string, labelSelector map[string]string) (watch.Interface, error)
 }
 
-// NewPrometheusServiceLevelsRetriver returns the retriever for Prometheus service levels events.
-func NewPrometheusServiceLevelsRetriver(ns string, repo RetrieverKubernetesRepository) controller.Retriever {
+// NewPrometheusServiceLevelsRetriver returns the retriever for Prometheus service levels events,
+// only the Prometheus service levels of the shard will be retrieved.
+func NewPrometheusServiceLevelsRetriver(ns string, shard Shard, repo RetrieverKubernetesRepository) controller.Retriever {
 	return controller.MustRetrieverFromListerWatcher(&cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-			return repo.ListPrometheusServiceLevels(context.TODO(), ns, map[string]string{})
+			list, err := repo.ListPrometheusServiceLevels(context.TODO(), ns, map[string]string{})
+			if err != nil {
+				return nil, err
+			}
+
+			items := make([]slothv1.PrometheusServiceLevel, 0, len(list.Items))
+			for _, item := range list.Items {
+				if shard.Contains(item.Namespace, item.Name) {
+					items = append(items, item)
+				}
+			}
+			list.Items = items
+
+			return list, nil
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-			return repo.WatchPrometheusServiceLevels(context.TODO(), ns, map[string]string{})
+			w, err := repo.WatchPrometheusServiceLevels(context.TODO(), ns, map[string]string{})
+			if err != nil {
+				return nil, err
+			}
+
+			return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+				slo, ok := e.Object.(*slothv1.PrometheusServiceLevel)
+				if !ok {
+					return e, true // Let other events (e.g errors) pass.
+				}
+				return e, shard.Contains(slo.Namespace, slo.Name)
+			}), nil
 		},
 	})
 }
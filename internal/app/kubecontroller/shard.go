@@ -0,0 +1,54 @@
+package kubecontroller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Shard is the partition of the Prometheus service levels handled by a controller
+// instance, when running multiple controller instances. The zero value is a single
+// shard that handles all the Prometheus service levels.
+type Shard struct {
+	// Index is the shard index (0 based).
+	Index int
+	// Total is the number of shards.
+	Total int
+}
+
+// ParseShard parses a shard in `{index}/{total}` form (e.g `0/3`).
+func ParseShard(s string) (Shard, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return Shard{}, fmt.Errorf("invalid shard %q, should be in `{index}/{total}` form", s)
+	}
+
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard %q index: %w", s, err)
+	}
+	total, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard %q total: %w", s, err)
+	}
+
+	if total < 1 || index < 0 || index >= total {
+		return Shard{}, fmt.Errorf("invalid shard %q, index should be between 0 and total-1", s)
+	}
+
+	return Shard{Index: index, Total: total}, nil
+}
+
+// Contains returns true if the Prometheus service level belongs to the shard, these are
+// partitioned using the hash of their namespace and name.
+func (s Shard) Contains(ns, name string) bool {
+	if s.Total <= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ns + "/" + name))
+
+	return int(h.Sum32()%uint32(s.Total)) == s.Index
+}
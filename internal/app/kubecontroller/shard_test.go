@@ -0,0 +1,85 @@
+package kubecontroller_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/app/kubecontroller"
+)
+
+func TestParseShard(t *testing.T) {
+	tests := map[string]struct {
+		shard    string
+		expShard kubecontroller.Shard
+		expErr   bool
+	}{
+		"Invalid format should fail.": {
+			shard:  "1",
+			expErr: true,
+		},
+
+		"Invalid index should fail.": {
+			shard:  "a/3",
+			expErr: true,
+		},
+
+		"Index out of the total should fail.": {
+			shard:  "3/3",
+			expErr: true,
+		},
+
+		"Zero total should fail.": {
+			shard:  "0/0",
+			expErr: true,
+		},
+
+		"Valid shard should be parsed.": {
+			shard:    "1/3",
+			expShard: kubecontroller.Shard{Index: 1, Total: 3},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotShard, err := kubecontroller.ParseShard(test.shard)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expShard, gotShard)
+			}
+		})
+	}
+}
+
+func TestShardContains(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	// Every object should belong to a single shard.
+	const total = 3
+	perShard := map[int]int{}
+	for i := 0; i < 100; i++ {
+		ns, name := fmt.Sprintf("ns-%d", i%7), fmt.Sprintf("slo-%d", i)
+
+		shards := 0
+		for idx := 0; idx < total; idx++ {
+			if (kubecontroller.Shard{Index: idx, Total: total}).Contains(ns, name) {
+				shards++
+				perShard[idx]++
+			}
+		}
+		require.Equal(1, shards, "%s/%s should belong to a single shard", ns, name)
+
+		// No sharding should contain everything.
+		assert.True(kubecontroller.Shard{}.Contains(ns, name))
+	}
+
+	// All shards should have objects.
+	assert.Len(perShard, total)
+}
@@ -0,0 +1,206 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines are the unchanged lines shown around the changes of the unified diff hunks.
+const contextLines = 3
+
+const (
+	opEqual  = ' '
+	opDelete = '-'
+	opInsert = '+'
+)
+
+type edit struct {
+	op   byte
+	line string
+}
+
+// Unified returns the unified diff of the from and to texts lines, empty if they are equal.
+func Unified(fromName, toName string, from, to []byte) string {
+	edits := diffLines(splitLines(string(from)), splitLines(string(to)))
+
+	// Get the from and to lines position of every edit, used on the hunk headers.
+	fromPos := make([]int, len(edits)+1)
+	toPos := make([]int, len(edits)+1)
+	changed := false
+	for i, e := range edits {
+		fromPos[i+1], toPos[i+1] = fromPos[i], toPos[i]
+		if e.op != opInsert {
+			fromPos[i+1]++
+		}
+		if e.op != opDelete {
+			toPos[i+1]++
+		}
+		if e.op != opEqual {
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(edits); {
+		if edits[i].op == opEqual {
+			i++
+			continue
+		}
+
+		// Group the changes that have less unchanged lines between them than the context of both.
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(edits) && j-end <= 2*contextLines+1; j++ {
+			if edits[j].op != opEqual {
+				end = j
+			}
+		}
+		stop := end + contextLines + 1
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+
+		fromCount, toCount := fromPos[stop]-fromPos[start], toPos[stop]-toPos[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(fromPos[start], fromCount), hunkRange(toPos[start], toCount))
+		for _, e := range edits[start:stop] {
+			fmt.Fprintf(&b, "%c%s\n", e.op, e.line)
+		}
+
+		i = stop
+	}
+
+	return b.String()
+}
+
+// hunkRange returns the hunk header range of a file, the empty ranges start on the line
+// before the hunk.
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", pos+1)
+	}
+
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// Colorize colors the lines of a unified diff for terminals.
+func Colorize(diff string) string {
+	lines := splitLines(diff)
+	for i, l := range lines {
+		color := ""
+		switch {
+		case strings.HasPrefix(l, "--- ") || strings.HasPrefix(l, "+++ "):
+			color = colorBold
+		case strings.HasPrefix(l, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(l, "-"):
+			color = colorRed
+		case strings.HasPrefix(l, "+"):
+			color = colorGreen
+		}
+		if color != "" {
+			lines[i] = color + l + colorReset
+		}
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script of a into b using the Myers diff algorithm.
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+
+	// trace has the furthest reaching paths of every diagonal (from -d to d) of every d step.
+	trace := [][]int{}
+search:
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+				break search
+			}
+		}
+		trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+	}
+
+	// Backtrack the path from the end.
+	edits := []edit{}
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{op: opEqual, line: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			edits = append(edits, edit{op: opInsert, line: b[y-1]})
+		} else {
+			edits = append(edits, edit{op: opDelete, line: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		edits = append(edits, edit{op: opEqual, line: a[x-1]})
+		x--
+		y--
+	}
+
+	// Reverse to get the edits in order.
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
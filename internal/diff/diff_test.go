@@ -0,0 +1,139 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/diff"
+)
+
+func TestUnified(t *testing.T) {
+	tests := map[string]struct {
+		from    string
+		to      string
+		expDiff string
+	}{
+		"Equal texts should not have diff.": {
+			from:    "a\nb\nc\n",
+			to:      "a\nb\nc\n",
+			expDiff: "",
+		},
+
+		"Empty texts should not have diff.": {
+			expDiff: "",
+		},
+
+		"A new text should be all added lines.": {
+			to: "a\nb\n",
+			expDiff: `--- from
++++ to
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+
+		"A removed text should be all removed lines.": {
+			from: "a\nb\n",
+			expDiff: `--- from
++++ to
+@@ -1,2 +0,0 @@
+-a
+-b
+`,
+		},
+
+		"A changed line should be shown with its context lines.": {
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:   "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expDiff: `--- from
++++ to
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+
+		"Changes far from each other should be on different hunks.": {
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			expDiff: `--- from
++++ to
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -8,3 +8,4 @@
+ 8
+ 9
+ 10
++11
+`,
+		},
+
+		"Changes close to each other should be on the same hunk.": {
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\neight\n",
+			expDiff: `--- from
++++ to
+@@ -1,8 +1,8 @@
+-1
++one
+ 2
+ 3
+ 4
+ 5
+ 6
+ 7
+-8
++eight
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotDiff := diff.Unified("from", "to", []byte(test.from), []byte(test.to))
+
+			assert.Equal(test.expDiff, gotDiff)
+		})
+	}
+}
+
+func TestColorize(t *testing.T) {
+	tests := map[string]struct {
+		diff    string
+		expDiff string
+	}{
+		"An empty diff should be empty.": {
+			diff:    "",
+			expDiff: "",
+		},
+
+		"A diff should color the headers, the hunks and the changed lines.": {
+			diff:    "--- from\n+++ to\n@@ -1 +1 @@\n-a\n+b\n c\n",
+			expDiff: "\033[1m--- from\033[0m\n\033[1m+++ to\033[0m\n\033[36m@@ -1 +1 @@\033[0m\n\033[31m-a\033[0m\n\033[32m+b\033[0m\n c\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotDiff := diff.Colorize(test.diff)
+
+			assert.Equal(test.expDiff, gotDiff)
+		})
+	}
+}
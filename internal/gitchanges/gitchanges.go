@@ -0,0 +1,120 @@
+// Package gitchanges knows the files changed on a git repository since a git reference,
+// so the commands can process only the changed SLO specs (e.g on a PR validation).
+package gitchanges
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Changes are the absolute paths of the changed files of a git repository.
+type Changes map[string]struct{}
+
+// Changed returns true if the path changed, a directory path has changed if any of its
+// files changed. Relative paths are relative to the working directory.
+func (c Changes) Changed(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	// Git returns the repository paths with the symlinks resolved.
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+
+	if _, ok := c[path]; ok {
+		return true
+	}
+
+	dir := path + string(filepath.Separator)
+	for f := range c {
+		if strings.HasPrefix(f, dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AnyChanged returns true if any of the paths changed.
+func (c Changes) AnyChanged(paths ...string) bool {
+	for _, p := range paths {
+		if c.Changed(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Since returns the files of the git repository of the directory that changed since the git
+// reference (e.g `origin/main`), including the uncommitted and untracked files. The deleted
+// files are not included.
+//
+// Like `git diff ref...HEAD`, the changes are the ones since the merge base of the reference
+// and HEAD, so the changes made only on the reference (e.g the target branch of a PR) are
+// not included.
+func Since(ctx context.Context, dir, ref string) (Changes, error) {
+	// Don't allow passing options to git as the reference.
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid %q git reference", ref)
+	}
+
+	root, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	base, err := git(ctx, root, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	base = strings.TrimSpace(base)
+
+	// The paths are relative to the repository root, NUL separated and not quoted, so they can
+	// have any character.
+	changed, err := git(ctx, root, "diff", "--name-only", "-z", "--no-renames", "--diff-filter=d", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(ctx, root, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	changes := Changes{}
+	for _, f := range strings.Split(changed+"\x00"+untracked, "\x00") {
+		if f == "" {
+			continue
+		}
+		changes[filepath.Join(root, filepath.FromSlash(f))] = struct{}{}
+	}
+
+	return changes, nil
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,169 @@
+package gitchanges_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/gitchanges"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@test.test", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func writeFile(t *testing.T, path, data string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+}
+
+func TestSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required")
+	}
+
+	// Prepare the repository.
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	writeFile(t, filepath.Join(dir, "slos", "team-a", "a.yaml"), "service: a\n")
+	writeFile(t, filepath.Join(dir, "slos", "team-b", "b.yaml"), "service: b\n")
+	writeFile(t, filepath.Join(dir, "slos", "team-b", "c.yaml"), "service: c\n")
+	writeFile(t, filepath.Join(dir, "windows", "windows.yaml"), "windows: {}\n")
+	writeFile(t, filepath.Join(dir, ".gitignore"), "*.log\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "base")
+
+	// Committed, uncommitted, untracked, ignored and deleted changes.
+	writeFile(t, filepath.Join(dir, "slos", "team-a", "a.yaml"), "service: a2\n")
+	runGit(t, dir, "commit", "-q", "-am", "change a")
+	writeFile(t, filepath.Join(dir, "slos", "team-b", "b.yaml"), "service: b2\n")
+	writeFile(t, filepath.Join(dir, "slos", "team-c", "d.yaml"), "service: d\n")
+	writeFile(t, filepath.Join(dir, "slos", "team-c", "d.log"), "log\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "slos", "team-b", "c.yaml")))
+
+	changes, err := gitchanges.Since(context.TODO(), filepath.Join(dir, "slos"), "HEAD~1")
+	require.NoError(t, err)
+
+	assert := assert.New(t)
+	assert.True(changes.Changed(filepath.Join(dir, "slos", "team-a", "a.yaml")))
+	assert.True(changes.Changed(filepath.Join(dir, "slos", "team-b", "b.yaml")))
+	assert.True(changes.Changed(filepath.Join(dir, "slos", "team-c", "d.yaml")))
+	assert.True(changes.Changed(filepath.Join(dir, "slos", "team-c")))
+	assert.False(changes.Changed(filepath.Join(dir, "slos", "team-b", "c.yaml")))
+	assert.False(changes.Changed(filepath.Join(dir, "slos", "team-c", "d.log")))
+	assert.False(changes.Changed(filepath.Join(dir, "windows")))
+	assert.False(changes.Changed(""))
+	assert.True(changes.AnyChanged(filepath.Join(dir, "windows"), filepath.Join(dir, "slos", "team-a")))
+	assert.False(changes.AnyChanged(filepath.Join(dir, "windows", "windows.yaml")))
+}
+
+func TestSinceDivergedRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required")
+	}
+
+	// Prepare the repository with a target branch that diverged from the working branch.
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	writeFile(t, filepath.Join(dir, "slos", "a.yaml"), "service: a\n")
+	writeFile(t, filepath.Join(dir, "slos", "b.yaml"), "service: b\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "base")
+	runGit(t, dir, "branch", "target")
+
+	writeFile(t, filepath.Join(dir, "slos", "a.yaml"), "service: a2\n")
+	runGit(t, dir, "commit", "-q", "-am", "change a")
+
+	runGit(t, dir, "checkout", "-q", "target")
+	writeFile(t, filepath.Join(dir, "slos", "b.yaml"), "service: b2\n")
+	runGit(t, dir, "commit", "-q", "-am", "change b on target")
+	runGit(t, dir, "checkout", "-q", "-")
+
+	changes, err := gitchanges.Since(context.TODO(), dir, "target")
+	require.NoError(t, err)
+
+	assert := assert.New(t)
+	assert.True(changes.Changed(filepath.Join(dir, "slos", "a.yaml")))
+	assert.False(changes.Changed(filepath.Join(dir, "slos", "b.yaml")))
+}
+
+func TestSinceSpecialPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required")
+	}
+
+	// Prepare the repository.
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	writeFile(t, filepath.Join(dir, "slos", "a.yaml"), "service: a\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "base")
+
+	// Committed and untracked paths with spaces, quotes, newlines and non ASCII characters.
+	paths := []string{
+		filepath.Join(dir, "slos", " team a ", "a.yaml"),
+		filepath.Join(dir, "slos", "\"quoted\".yaml"),
+		filepath.Join(dir, "slos", "new\nline.yaml"),
+		filepath.Join(dir, "slos", "café.yaml"),
+	}
+	writeFile(t, paths[0], "service: a\n")
+	writeFile(t, paths[1], "service: b\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "change")
+	writeFile(t, paths[2], "service: c\n")
+	writeFile(t, paths[3], "service: d\n")
+
+	changes, err := gitchanges.Since(context.TODO(), dir, "HEAD~1")
+	require.NoError(t, err)
+
+	assert := assert.New(t)
+	for _, p := range paths {
+		assert.True(changes.Changed(p), p)
+	}
+	assert.False(changes.Changed(filepath.Join(dir, "slos", "a.yaml")))
+}
+
+func TestSinceInvalidRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required")
+	}
+
+	tests := map[string]struct {
+		ref string
+	}{
+		"A missing reference should fail.": {
+			ref: "missing-ref",
+		},
+
+		"An empty reference should fail.": {
+			ref: "",
+		},
+
+		"A reference starting with a dash should fail.": {
+			ref: "--output=x",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			runGit(t, dir, "init", "-q")
+			writeFile(t, filepath.Join(dir, "slos", "a.yaml"), "service: a\n")
+			runGit(t, dir, "add", "-A")
+			runGit(t, dir, "commit", "-q", "-m", "base")
+
+			_, err := gitchanges.Since(context.TODO(), dir, test.ref)
+			assert.Error(t, err)
+			assert.NoFileExists(t, filepath.Join(dir, "x"))
+		})
+	}
+}
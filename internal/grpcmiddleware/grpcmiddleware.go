@@ -0,0 +1,128 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/slok/sloth/internal/httpmiddleware"
+)
+
+// Interceptor intercepts the unary and the stream requests before the method handler, it returns
+// the context of the method handler or an error status to fail the request. The returned
+// cancel function (if any) is called when the method handler finishes.
+type Interceptor func(ctx context.Context) (context.Context, context.CancelFunc, error)
+
+// ServerOptions returns the gRPC server options that chain the interceptors (in order) on the
+// unary and the stream requests.
+func ServerOptions(interceptors ...Interceptor) []grpc.ServerOption {
+	unary := make([]grpc.UnaryServerInterceptor, 0, len(interceptors))
+	stream := make([]grpc.StreamServerInterceptor, 0, len(interceptors))
+	for _, i := range interceptors {
+		i := i
+		unary = append(unary, func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, cancel, err := i(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if cancel != nil {
+				defer cancel()
+			}
+			return handler(ctx, req)
+		})
+		stream = append(stream, func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, cancel, err := i(ss.Context())
+			if err != nil {
+				return err
+			}
+			if cancel != nil {
+				defer cancel()
+			}
+			return handler(srv, contextServerStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+// contextServerStream is a server stream with a different context.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c contextServerStream) Context() context.Context { return c.ctx }
+
+// APIKeyAuth authenticates the requests with the `authorization: Bearer {key}` metadata API key
+// of a client, the same as the HTTP API. The authenticated client can be obtained with
+// httpmiddleware.ClientID.
+func APIKeyAuth(keys httpmiddleware.APIKeys) Interceptor {
+	return func(ctx context.Context) (context.Context, context.CancelFunc, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		auth := md.Get("authorization")
+		if len(auth) != 1 || !strings.HasPrefix(auth[0], "Bearer ") {
+			return nil, nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+
+		clientID := keys.Authenticate(strings.TrimPrefix(auth[0], "Bearer "))
+		if clientID == "" {
+			return nil, nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+
+		return httpmiddleware.ContextWithClientID(ctx, clientID), nil, nil
+	}
+}
+
+// RateLimit limits the requests rate of every client, the clients are the authenticated ones
+// (see httpmiddleware.ClientID) or the request peer host. The limited requests have a resource
+// exhausted status and a `retry-after` (seconds) header.
+func RateLimit(l *httpmiddleware.RateLimiter) Interceptor {
+	return func(ctx context.Context) (context.Context, context.CancelFunc, error) {
+		client := httpmiddleware.ClientID(ctx)
+		if client == "" {
+			client = peerHost(ctx)
+		}
+
+		allowed, retryAfter := l.Allow(client)
+		if !allowed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds())))))
+			return nil, nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+
+		return ctx, nil, nil
+	}
+}
+
+// Timeout limits the duration of the requests, the request deadline can only reduce it.
+func Timeout(timeout time.Duration) Interceptor {
+	return func(ctx context.Context) (context.Context, context.CancelFunc, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, cancel, nil
+	}
+}
+
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
@@ -0,0 +1,124 @@
+package grpcmiddleware_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/slok/sloth/internal/grpcmiddleware"
+	"github.com/slok/sloth/internal/httpmiddleware"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	keys := httpmiddleware.APIKeys{"team-a": "key-a", "team-b": "key-b"}
+
+	tests := map[string]struct {
+		md          metadata.MD
+		expCode     codes.Code
+		expClientID string
+	}{
+		"A request without metadata should be unauthenticated.": {
+			expCode: codes.Unauthenticated,
+		},
+
+		"A request without a bearer API key should be unauthenticated.": {
+			md:      metadata.Pairs("authorization", "Basic key-a"),
+			expCode: codes.Unauthenticated,
+		},
+
+		"A request with an unknown API key should be unauthenticated.": {
+			md:      metadata.Pairs("authorization", "Bearer key-c"),
+			expCode: codes.Unauthenticated,
+		},
+
+		"A request with a valid API key should be authenticated with its client.": {
+			md:          metadata.Pairs("authorization", "Bearer key-b"),
+			expCode:     codes.OK,
+			expClientID: "team-b",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			ctx := metadata.NewIncomingContext(context.TODO(), test.md)
+			gotCtx, _, err := grpcmiddleware.APIKeyAuth(keys)(ctx)
+
+			assert.Equal(test.expCode, status.Code(err))
+			if err == nil {
+				assert.Equal(test.expClientID, httpmiddleware.ClientID(gotCtx))
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	tests := map[string]struct {
+		clients  []string
+		expCodes []codes.Code
+	}{
+		"The requests over the burst should be rate limited.": {
+			clients:  []string{"10.0.0.1:1000", "10.0.0.1:1001"},
+			expCodes: []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+
+		"Every peer host should have its own rate limit.": {
+			clients:  []string{"10.0.0.1:1000", "10.0.0.2:1000", "10.0.0.1:1001"},
+			expCodes: []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+
+		"Every authenticated client should have its own rate limit.": {
+			clients:  []string{"team-a", "team-b", "team-a"},
+			expCodes: []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			// A low rate so the buckets are not refilled during the test.
+			limiter, err := httpmiddleware.NewRateLimiter(0.001, 1)
+			require.NoError(err)
+			interceptor := grpcmiddleware.RateLimit(limiter)
+
+			gotCodes := []codes.Code{}
+			for _, client := range test.clients {
+				// Same peer address for all the authenticated clients.
+				ctx := peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.9"), Port: 1000}})
+				if addr, err := net.ResolveTCPAddr("tcp", client); err == nil {
+					ctx = peer.NewContext(context.TODO(), &peer.Peer{Addr: addr})
+				} else {
+					ctx = httpmiddleware.ContextWithClientID(ctx, client)
+				}
+
+				_, _, err := interceptor(ctx)
+				gotCodes = append(gotCodes, status.Code(err))
+			}
+
+			assert.Equal(test.expCodes, gotCodes)
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel, err := grpcmiddleware.Timeout(time.Minute)(context.TODO())
+	assert.NoError(err)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(ok)
+	assert.WithinDuration(time.Now().Add(time.Minute), deadline, 5*time.Second)
+}
@@ -0,0 +1,227 @@
+package httpmiddleware
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type contextKey int
+
+const clientIDContextKey contextKey = iota
+
+// ClientID returns the client identified by the API key authentication of the request
+// context, empty if the request was not authenticated.
+func ClientID(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDContextKey).(string)
+	return id
+}
+
+// ContextWithClientID returns a new context with the authenticated client, see ClientID.
+func ContextWithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDContextKey, clientID)
+}
+
+// APIKeys are the API keys of the clients, indexed by client ID.
+type APIKeys map[string]string
+
+// Authenticate returns the client of the API key, empty if the key is not valid.
+func (k APIKeys) Authenticate(key string) string {
+	// Check all the keys so the response time doesn't depend on the matched client.
+	clientID := ""
+	for client, ck := range k {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(ck)) == 1 {
+			clientID = client
+		}
+	}
+
+	return clientID
+}
+
+// ParseAPIKeys parses the API keys of the clients, one `client=key` per line. The empty
+// lines and the lines starting with `#` are ignored.
+//
+// Example:
+//
+//	# Platform team CI.
+//	platform-ci=0a3b4c5d6e7f...
+//	team-a=8e9f0a1b2c3d...
+func ParseAPIKeys(r io.Reader) (APIKeys, error) {
+	keys := APIKeys{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		kv := strings.SplitN(l, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid API key on line %d, must be in 'client=key' form", line)
+		}
+		client, key := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if client == "" || key == "" {
+			return nil, fmt.Errorf("invalid API key on line %d, client and key are required", line)
+		}
+		if _, ok := keys[client]; ok {
+			return nil, fmt.Errorf("duplicated API key client %q on line %d", client, line)
+		}
+		keys[client] = key
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read API keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("API keys are required")
+	}
+
+	return keys, nil
+}
+
+// APIKeyAuth authenticates the requests with the `Authorization: Bearer {key}` header
+// API key of a client. The authenticated client can be obtained with ClientID.
+func APIKeyAuth(keys APIKeys, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		clientID := keys.Authenticate(strings.TrimPrefix(auth, "Bearer "))
+		if clientID == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithClientID(r.Context(), clientID)))
+	})
+}
+
+// MaxBodySize rejects the requests with a body bigger than maxBytes.
+func MaxBodySize(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, fmt.Sprintf("request body too large, the maximum is %d bytes", maxBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxRateLimitClients is the number of clients tracked by the rate limiter, when reached
+// the least recently seen client is forgotten.
+const maxRateLimitClients = 10000
+
+// RateLimiter limits the requests rate of every client with a token bucket, the clients
+// are the authenticated ones (see ClientID) or the request remote host.
+type RateLimiter struct {
+	rate    float64
+	burst   float64
+	buckets map[string]*list.Element
+	// lru has the client buckets from the most to the least recently seen.
+	lru *list.List
+	mu  sync.Mutex
+}
+
+type tokenBucket struct {
+	client string
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a new rate limiter that allows rate requests per second per client,
+// with bursts of up to burst requests.
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("rate limit must be greater than 0")
+	}
+
+	if burst < 1 {
+		return nil, fmt.Errorf("rate limit burst must be greater than 0")
+	}
+
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: map[string]*list.Element{},
+		lru:     list.New(),
+	}, nil
+}
+
+// Handler returns the rate limited handler, the limited requests have a 429 status code.
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := ClientID(r.Context())
+		if client == "" {
+			client = r.RemoteAddr
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				client = host
+			}
+		}
+
+		allowed, retryAfter := l.Allow(client)
+		if !allowed {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allow consumes a request of the client, if the client is rate limited it returns the
+// time until the client can make a request again.
+func (l *RateLimiter) Allow(client string) (allowed bool, retryAfter time.Duration) {
+	return l.allow(client, time.Now())
+}
+
+func (l *RateLimiter) allow(client string, now time.Time) (allowed bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b *tokenBucket
+	if e, ok := l.buckets[client]; ok {
+		l.lru.MoveToFront(e)
+		b = e.Value.(*tokenBucket)
+	} else {
+		// Forget the least recently seen client to track the new one.
+		if l.lru.Len() >= maxRateLimitClients {
+			oldest := l.lru.Back()
+			l.lru.Remove(oldest)
+			delete(l.buckets, oldest.Value.(*tokenBucket).client)
+		}
+		b = &tokenBucket{client: client, tokens: l.burst, last: now}
+		l.buckets[client] = l.lru.PushFront(b)
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+
+	return true, 0
+}
+
+// TrackedClients returns the number of clients tracked by the rate limiter.
+func (l *RateLimiter) TrackedClients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Len()
+}
@@ -0,0 +1,258 @@
+package httpmiddleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/httpmiddleware"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := map[string]struct {
+		keys    string
+		expKeys httpmiddleware.APIKeys
+		expErr  bool
+	}{
+		"Empty API keys should fail.": {
+			keys:   "\n# Nothing.\n",
+			expErr: true,
+		},
+
+		"API keys without client should fail.": {
+			keys:   "=key1",
+			expErr: true,
+		},
+
+		"API keys in an invalid form should fail.": {
+			keys:   "client1 key1",
+			expErr: true,
+		},
+
+		"Duplicated API key clients should fail.": {
+			keys:   "client1=key1\nclient1=key2",
+			expErr: true,
+		},
+
+		"API keys should be parsed ignoring the empty lines and the comments.": {
+			keys: `
+# Platform CI.
+client1=key1
+
+ client2 = key2=
+`,
+			expKeys: httpmiddleware.APIKeys{
+				"client1": "key1",
+				"client2": "key2=",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotKeys, err := httpmiddleware.ParseAPIKeys(strings.NewReader(test.keys))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expKeys, gotKeys)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	tests := map[string]struct {
+		authHeader string
+		expCode    int
+		expBody    string
+	}{
+		"A request without authorization should be unauthorized.": {
+			expCode: http.StatusUnauthorized,
+			expBody: "unauthorized\n",
+		},
+
+		"A request with an invalid API key should be unauthorized.": {
+			authHeader: "Bearer key3",
+			expCode:    http.StatusUnauthorized,
+			expBody:    "unauthorized\n",
+		},
+
+		"A request without bearer authorization should be unauthorized.": {
+			authHeader: "key1",
+			expCode:    http.StatusUnauthorized,
+			expBody:    "unauthorized\n",
+		},
+
+		"A request with a valid API key should be authenticated as the API key client.": {
+			authHeader: "Bearer key2",
+			expCode:    http.StatusOK,
+			expBody:    "client2",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			keys := httpmiddleware.APIKeys{"client1": "key1", "client2": "key2"}
+			h := httpmiddleware.APIKeyAuth(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, httpmiddleware.ClientID(r.Context()))
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.authHeader != "" {
+				r.Header.Set("Authorization", test.authHeader)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			assert.Equal(test.expCode, w.Code)
+			assert.Equal(test.expBody, w.Body.String())
+		})
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		expCode int
+	}{
+		"A request with a body smaller than the maximum should be handled.": {
+			body:    "12345",
+			expCode: http.StatusOK,
+		},
+
+		"A request with a body bigger than the maximum should be rejected.": {
+			body:    "123456",
+			expCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			h := httpmiddleware.MaxBodySize(5, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body)))
+
+			assert.Equal(test.expCode, w.Code)
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	tests := map[string]struct {
+		burst    int
+		requests []string
+		expCodes []int
+	}{
+		"The requests over the burst should be rate limited.": {
+			burst:    2,
+			requests: []string{"10.0.0.1:1000", "10.0.0.1:1001", "10.0.0.1:1002"},
+			expCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+
+		"Every client should have its own rate limit.": {
+			burst:    1,
+			requests: []string{"10.0.0.1:1000", "10.0.0.2:1000", "10.0.0.1:1001", "10.0.0.2:1001"},
+			expCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			// A low rate so the buckets are not refilled during the test.
+			limiter, err := httpmiddleware.NewRateLimiter(0.001, test.burst)
+			require.NoError(err)
+			h := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			gotCodes := []int{}
+			for _, remoteAddr := range test.requests {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = remoteAddr
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				gotCodes = append(gotCodes, w.Code)
+			}
+
+			assert.Equal(test.expCodes, gotCodes)
+		})
+	}
+}
+
+func TestRateLimiterBeforeAPIKeyAuth(t *testing.T) {
+	tests := map[string]struct {
+		authHeaders []string
+		expCodes    []int
+	}{
+		"The failed authentications over the rate limit should be rate limited.": {
+			authHeaders: []string{"Bearer key2", "Bearer key3"},
+			expCodes:    []int{http.StatusUnauthorized, http.StatusTooManyRequests},
+		},
+
+		"The authenticated requests over the rate limit should be rate limited.": {
+			authHeaders: []string{"Bearer key1", "Bearer key1"},
+			expCodes:    []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			// A low rate so the buckets are not refilled during the test.
+			limiter, err := httpmiddleware.NewRateLimiter(0.001, 1)
+			require.NoError(err)
+			keys := httpmiddleware.APIKeys{"client1": "key1"}
+			h := limiter.Handler(httpmiddleware.APIKeyAuth(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+			gotCodes := []int{}
+			for _, authHeader := range test.authHeaders {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = "10.0.0.1:1000"
+				r.Header.Set("Authorization", authHeader)
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				gotCodes = append(gotCodes, w.Code)
+			}
+
+			assert.Equal(test.expCodes, gotCodes)
+		})
+	}
+}
+
+func TestRateLimiterTrackedClients(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// A low rate so the buckets are not refilled during the test.
+	limiter, err := httpmiddleware.NewRateLimiter(0.001, 1)
+	require.NoError(err)
+
+	for i := 0; i < 10001; i++ {
+		allowed, _ := limiter.Allow(fmt.Sprintf("client%d", i))
+		require.True(allowed)
+	}
+
+	// The least recently seen client should be forgotten, and the recent ones still limited.
+	assert.Equal(10000, limiter.TrackedClients())
+	allowed, _ := limiter.Allow("client10000")
+	assert.False(allowed)
+	allowed, _ = limiter.Allow("client0")
+	assert.True(allowed)
+	assert.Equal(10000, limiter.TrackedClients())
+}
@@ -12,6 +12,7 @@ const (
 	ModeCLIGenPrometheus        = "cli-gen-prom"
 	ModeCLIGenKubernetes        = "cli-gen-k8s"
 	ModeControllerGenKubernetes = "ctrl-gen-k8s"
+	ModeLibGenPrometheus        = "lib-gen-prom"
 )
 
 // Info is the information of the app and request based for SLO generators.
@@ -43,6 +43,19 @@ func (k KubernetesService) WatchPrometheusServiceLevels(ctx context.Context, ns
 	})
 }
 
+// GetPrometheusRule returns the Prometheus operator rule object, nil if it doesn't exist.
+func (k KubernetesService) GetPrometheusRule(ctx context.Context, ns, name string) (*monitoringv1.PrometheusRule, error) {
+	pr, err := k.monitoringCli.MonitoringV1().PrometheusRules(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return pr, nil
+}
+
 func (k KubernetesService) EnsurePrometheusRule(ctx context.Context, pr *monitoringv1.PrometheusRule) error {
 	logger := k.logger.WithCtxValues(ctx)
 	pr = pr.DeepCopy()
@@ -5,11 +5,10 @@ import (
 	"fmt"
 	"time"
 
-	"k8s.io/apimachinery/pkg/runtime"
-
+	"github.com/slok/sloth/internal/alert"
 	"github.com/slok/sloth/internal/prometheus"
 	k8sprometheusv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
-	"github.com/slok/sloth/pkg/kubernetes/gen/clientset/versioned/scheme"
+	k8sspecloader "github.com/slok/sloth/pkg/kubernetes/specloader"
 	prometheuspluginv1 "github.com/slok/sloth/pkg/prometheus/plugin/v1"
 )
 
@@ -19,39 +18,60 @@ type SLIPluginRepo interface {
 
 // YAMLSpecLoader knows how to load Kubernetes ServiceLevel YAML specs and converts them to a model.
 type YAMLSpecLoader struct {
-	pluginsRepo SLIPluginRepo
-	decoder     runtime.Decoder
+	pluginsRepo      SLIPluginRepo
+	defaultSLOPeriod time.Duration
+	alertWindows     *alert.WindowsCatalog
+	strict           bool
+	environment      string
 }
 
 // NewYAMLSpecLoader returns a YAML spec loader.
 func NewYAMLSpecLoader(pluginsRepo SLIPluginRepo) YAMLSpecLoader {
 	return YAMLSpecLoader{
-		pluginsRepo: pluginsRepo,
-		decoder:     scheme.Codecs.UniversalDeserializer(),
+		pluginsRepo:      pluginsRepo,
+		defaultSLOPeriod: prometheus.DefaultSLOPeriod,
 	}
 }
 
-func (y YAMLSpecLoader) LoadSpec(ctx context.Context, data []byte) (*SLOGroup, error) {
-	if len(data) == 0 {
-		return nil, fmt.Errorf("spec is required")
-	}
+// WithDefaultSLOPeriod returns a copy of the loader that uses the SLO period as the
+// SLOs time window of the specs without SLO period.
+func (y YAMLSpecLoader) WithDefaultSLOPeriod(period time.Duration) YAMLSpecLoader {
+	y.defaultSLOPeriod = period
+	return y
+}
 
-	obj, _, err := y.decoder.Decode([]byte(data), nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("could not decode kubernetes object %w", err)
-	}
+// WithAlertWindows returns a copy of the loader that selects the SLOs alert windows from
+// the alert windows catalog.
+func (y YAMLSpecLoader) WithAlertWindows(catalog *alert.WindowsCatalog) YAMLSpecLoader {
+	y.alertWindows = catalog
+	return y
+}
 
-	kslo, ok := obj.(*k8sprometheusv1.PrometheusServiceLevel)
-	if !ok {
-		return nil, fmt.Errorf("can't type assert runtime.Object to v1.PrometheusServiceLeve")
-	}
+// WithStrict returns a copy of the loader that fails on the spec fields that are unknown
+// (e.g a typo'd `objetive`), instead of ignoring them.
+func (y YAMLSpecLoader) WithStrict(strict bool) YAMLSpecLoader {
+	y.strict = strict
+	return y
+}
 
-	// Check at least we have one SLO.
-	if len(kslo.Spec.SLOs) == 0 {
-		return nil, fmt.Errorf("at least one SLO is required")
+// WithEnvironment returns a copy of the loader that applies the SLO overrides of the
+// environment.
+func (y YAMLSpecLoader) WithEnvironment(env string) YAMLSpecLoader {
+	y.environment = env
+	return y
+}
+
+func (y YAMLSpecLoader) LoadSpec(ctx context.Context, data []byte) (*SLOGroup, error) {
+	load := k8sspecloader.LoadPrometheusServiceLevelV1
+	if y.strict {
+		load = k8sspecloader.LoadPrometheusServiceLevelV1Strict
+	}
+	kslo, err := load(data)
+	if err != nil {
+		return nil, err
 	}
 
-	m, err := mapSpecToModel(ctx, y.pluginsRepo, kslo)
+	m, err := mapSpecToModel(ctx, y.pluginsRepo, y.defaultSLOPeriod, y.alertWindows, y.environment, kslo)
 	if err != nil {
 		return nil, fmt.Errorf("could not map to model: %w", err)
 	}
@@ -60,38 +80,117 @@ func (y YAMLSpecLoader) LoadSpec(ctx context.Context, data []byte) (*SLOGroup, e
 }
 
 type CRSpecLoader struct {
-	pluginsRepo SLIPluginRepo
+	pluginsRepo      SLIPluginRepo
+	defaultSLOPeriod time.Duration
+	alertWindows     *alert.WindowsCatalog
+	environment      string
 }
 
 // CRSpecLoader knows how to load Kubernetes CRD specs and converts them to a model.
 
 func NewCRSpecLoader(pluginsRepo SLIPluginRepo) CRSpecLoader {
 	return CRSpecLoader{
-		pluginsRepo: pluginsRepo,
+		pluginsRepo:      pluginsRepo,
+		defaultSLOPeriod: prometheus.DefaultSLOPeriod,
 	}
 }
 
+// WithDefaultSLOPeriod returns a copy of the loader that uses the SLO period as the
+// SLOs time window of the specs without SLO period.
+func (c CRSpecLoader) WithDefaultSLOPeriod(period time.Duration) CRSpecLoader {
+	c.defaultSLOPeriod = period
+	return c
+}
+
+// WithAlertWindows returns a copy of the loader that selects the SLOs alert windows from
+// the alert windows catalog.
+func (c CRSpecLoader) WithAlertWindows(catalog *alert.WindowsCatalog) CRSpecLoader {
+	c.alertWindows = catalog
+	return c
+}
+
+// WithEnvironment returns a copy of the loader that applies the SLO overrides of the
+// environment.
+func (c CRSpecLoader) WithEnvironment(env string) CRSpecLoader {
+	c.environment = env
+	return c
+}
+
 func (c CRSpecLoader) LoadSpec(ctx context.Context, spec *k8sprometheusv1.PrometheusServiceLevel) (*SLOGroup, error) {
-	return mapSpecToModel(ctx, c.pluginsRepo, spec)
+	return mapSpecToModel(ctx, c.pluginsRepo, c.defaultSLOPeriod, c.alertWindows, c.environment, spec)
 }
 
-func mapSpecToModel(ctx context.Context, pluginsRepo SLIPluginRepo, kspec *k8sprometheusv1.PrometheusServiceLevel) (*SLOGroup, error) {
+func mapSpecToModel(ctx context.Context, pluginsRepo SLIPluginRepo, defaultSLOPeriod time.Duration, alertWindows *alert.WindowsCatalog, environment string, kspec *k8sprometheusv1.PrometheusServiceLevel) (*SLOGroup, error) {
+	timeWindow, err := prometheus.GetSLOPeriod(kspec.Spec.SLOPeriod, defaultSLOPeriod)
+	if err != nil {
+		return nil, err
+	}
+
 	slos := make([]prometheus.SLO, 0, len(kspec.Spec.SLOs))
 	spec := kspec.Spec
 	for _, specSLO := range kspec.Spec.SLOs {
+		var costLabels map[string]string
+		if spec.Cost != nil {
+			costLabels = spec.Cost.Labels
+		}
+		var defaultAnnotations map[string]string
+		if spec.AlertingDefaults != nil {
+			defaultAnnotations = spec.AlertingDefaults.Annotations
+		}
+
+		// Set environment overrides.
+		objective, sloTimeWindow := specSLO.Objective, timeWindow
+		if env, ok := specSLO.Environments[environment]; ok && environment != "" {
+			if env.Objective != 0 {
+				objective = env.Objective
+			}
+			sloTimeWindow, err = prometheus.GetSLOPeriod(env.SLOPeriod, timeWindow)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q environment: %w", environment, err)
+			}
+		}
+
 		slo := prometheus.SLO{
 			ID:              fmt.Sprintf("%s-%s", spec.Service, specSLO.Name),
 			Name:            specSLO.Name,
 			Description:     specSLO.Description,
 			Service:         spec.Service,
-			TimeWindow:      30 * 24 * time.Hour, // Default and for now the only one supported.
-			Objective:       specSLO.Objective,
-			Labels:          mergeLabels(spec.Labels, specSLO.Labels),
+			TimeWindow:      sloTimeWindow,
+			Objective:       objective,
+			Labels:          mergeLabels(spec.Labels, specSLO.Labels, costLabels),
+			Annotations:     mergeLabels(defaultAnnotations, spec.Annotations, specSLO.Annotations),
+			CostLabels:      costLabels,
 			PageAlertMeta:   prometheus.AlertMeta{Disable: true},
 			TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+			DependsOn:       specSLO.Alerting.DependsOn,
+			AlertGuard:      specSLO.Alerting.Guard,
+		}
+
+		// Set rule group intervals.
+		if i := specSLO.RuleGroupIntervals; i != nil {
+			intervals, err := prometheus.GetRuleGroupIntervals(i.Default, i.SLIRecordings, i.MetaRecordings, i.Alerts)
+			if err != nil {
+				return nil, err
+			}
+			slo.RuleGroupIntervals = intervals
 		}
 
+		// Set alert windows.
+		sloAlertWindows, err := prometheus.GetAlertWindows(alertWindows, specSLO.Alerting.Windows, slo.Labels, sloTimeWindow)
+		if err != nil {
+			return nil, err
+		}
+		slo.AlertWindows = sloAlertWindows
+
 		// Set SLIs.
+		if specSLO.SLI.Offset != "" {
+			offset, err := prometheus.ParseDuration(specSLO.SLI.Offset)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SLI offset %q: %w", specSLO.SLI.Offset, err)
+			}
+			slo.SLI.Offset = offset
+		}
+
 		if specSLO.SLI.Events != nil {
 			slo.SLI.Events = &prometheus.SLIEvents{
 				ErrorQuery: specSLO.SLI.Events.ErrorQuery,
@@ -105,21 +204,36 @@ func mapSpecToModel(ctx context.Context, pluginsRepo SLIPluginRepo, kspec *k8spr
 			}
 		}
 
-		if specSLO.SLI.Plugin != nil {
-			plugin, err := pluginsRepo.GetSLIPlugin(ctx, specSLO.SLI.Plugin.ID)
+		if specSLO.SLI.Latency != nil {
+			if slo.SLI.Events != nil {
+				return nil, fmt.Errorf("only one SLI type can be set")
+			}
+			threshold, err := prometheus.ParseDuration(specSLO.SLI.Latency.Threshold)
 			if err != nil {
-				return nil, fmt.Errorf("could not get plugin: %w", err)
+				return nil, fmt.Errorf("invalid latency SLI threshold %q: %w", specSLO.SLI.Latency.Threshold, err)
 			}
+			events, err := prometheus.LatencySLIEvents(specSLO.SLI.Latency.HistogramMetric, threshold, specSLO.SLI.Latency.Selector, specSLO.SLI.Latency.NativeHistogram)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency SLI: %w", err)
+			}
+			slo.SLI.Events = events
+		}
 
+		if specSLO.SLI.Plugin != nil {
 			meta := map[string]string{
 				prometheuspluginv1.SLIPluginMetaService:   spec.Service,
 				prometheuspluginv1.SLIPluginMetaSLO:       specSLO.Name,
-				prometheuspluginv1.SLIPluginMetaObjective: fmt.Sprintf("%f", specSLO.Objective),
+				prometheuspluginv1.SLIPluginMetaObjective: fmt.Sprintf("%f", objective),
+			}
+
+			chain := []prometheus.SLIPluginExecution{{ID: specSLO.SLI.Plugin.ID, Options: specSLO.SLI.Plugin.Options}}
+			for _, p := range specSLO.SLI.Plugin.Chain {
+				chain = append(chain, prometheus.SLIPluginExecution{ID: p.ID, Options: p.Options})
 			}
 
-			rawQuery, err := plugin.Func(ctx, meta, spec.Labels, specSLO.SLI.Plugin.Options)
+			rawQuery, err := prometheus.ExecuteSLIPluginChain(ctx, pluginsRepo, meta, spec.Labels, chain)
 			if err != nil {
-				return nil, fmt.Errorf("plugin %q execution error: %w", specSLO.SLI.Plugin.ID, err)
+				return nil, err
 			}
 
 			slo.SLI.Raw = &prometheus.SLIRaw{
@@ -127,6 +241,14 @@ func mapSpecToModel(ctx context.Context, pluginsRepo SLIPluginRepo, kspec *k8spr
 			}
 		}
 
+		for _, e := range specSLO.SLI.Examples {
+			slo.SLI.Examples = append(slo.SLI.Examples, prometheus.SLIExample{
+				Name:       e.Name,
+				Series:     e.Series,
+				ErrorRatio: e.ErrorRatio,
+			})
+		}
+
 		// Set alerts.
 		if !specSLO.Alerting.PageAlert.Disable {
 			slo.PageAlertMeta = prometheus.AlertMeta{
@@ -134,6 +256,13 @@ func mapSpecToModel(ctx context.Context, pluginsRepo SLIPluginRepo, kspec *k8spr
 				Labels:      mergeLabels(specSLO.Alerting.Labels, specSLO.Alerting.PageAlert.Labels),
 				Annotations: mergeLabels(specSLO.Alerting.Annotations, specSLO.Alerting.PageAlert.Annotations),
 			}
+			if specSLO.Alerting.PageAlert.For != "" {
+				alertFor, err := prometheus.ParseDuration(specSLO.Alerting.PageAlert.For)
+				if err != nil {
+					return nil, fmt.Errorf("invalid page alert for %q: %w", specSLO.Alerting.PageAlert.For, err)
+				}
+				slo.PageAlertMeta.For = alertFor
+			}
 		}
 
 		if !specSLO.Alerting.TicketAlert.Disable {
@@ -142,6 +271,65 @@ func mapSpecToModel(ctx context.Context, pluginsRepo SLIPluginRepo, kspec *k8spr
 				Labels:      mergeLabels(specSLO.Alerting.Labels, specSLO.Alerting.TicketAlert.Labels),
 				Annotations: mergeLabels(specSLO.Alerting.Annotations, specSLO.Alerting.TicketAlert.Annotations),
 			}
+			if specSLO.Alerting.TicketAlert.For != "" {
+				alertFor, err := prometheus.ParseDuration(specSLO.Alerting.TicketAlert.For)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ticket alert for %q: %w", specSLO.Alerting.TicketAlert.For, err)
+				}
+				slo.TicketAlertMeta.For = alertFor
+			}
+		}
+
+		// Set transition.
+		if specSLO.Transition != nil {
+			slo.Transition = &prometheus.SLOTransition{
+				PreviousObjective:  slo.Objective,
+				PreviousTimeWindow: slo.TimeWindow,
+				ChangedAt:          specSLO.Transition.ChangedAt,
+			}
+			if specSLO.Transition.PreviousObjective != 0 {
+				slo.Transition.PreviousObjective = specSLO.Transition.PreviousObjective
+			}
+			if specSLO.Transition.PreviousTimeWindow != "" {
+				window, err := prometheus.ParseDuration(specSLO.Transition.PreviousTimeWindow)
+				if err != nil {
+					return nil, fmt.Errorf("invalid transition previous time window %q: %w", specSLO.Transition.PreviousTimeWindow, err)
+				}
+				slo.Transition.PreviousTimeWindow = window
+			}
+		}
+
+		// Set reporting windows.
+		for _, w := range specSLO.ReportingWindows {
+			window, err := prometheus.ParseDuration(w)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reporting window %q: %w", w, err)
+			}
+			slo.ReportingWindows = append(slo.ReportingWindows, window)
+		}
+
+		// Set timeslice.
+		if specSLO.Timeslice != nil {
+			window, err := prometheus.ParseDuration(specSLO.Timeslice.Window)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeslice window %q: %w", specSLO.Timeslice.Window, err)
+			}
+			slo.Timeslice = &prometheus.SLOTimeslice{
+				Window:              window,
+				ErrorRatioThreshold: specSLO.Timeslice.ErrorRatioThreshold,
+			}
+		}
+
+		// Set maintenance windows.
+		for _, w := range specSLO.Alerting.MaintenanceWindows {
+			slo.MaintenanceWindows = append(slo.MaintenanceWindows, prometheus.MaintenanceWindow{
+				Name:        w.Name,
+				Times:       w.Times,
+				Weekdays:    w.Weekdays,
+				DaysOfMonth: w.DaysOfMonth,
+				Months:      w.Months,
+				Location:    w.Location,
+			})
 		}
 
 		slos = append(slos, slo)
@@ -24,10 +24,11 @@ func (t testMemPluginsRepo) GetSLIPlugin(ctx context.Context, id string) (*prome
 
 func TestYAMLoadSpec(t *testing.T) {
 	tests := map[string]struct {
-		specYaml string
-		plugins  map[string]prometheus.SLIPlugin
-		expModel *k8sprometheus.SLOGroup
-		expErr   bool
+		specYaml    string
+		plugins     map[string]prometheus.SLIPlugin
+		environment string
+		expModel    *k8sprometheus.SLOGroup
+		expErr      bool
 	}{
 		"Empty spec should fail.": {
 			specYaml: ``,
@@ -163,11 +164,12 @@ spec:
 				},
 				SLOGroup: prometheus.SLOGroup{SLOs: []prometheus.SLO{
 					{
-						ID:         "test-svc-slo-test",
-						Name:       "slo-test",
-						Service:    "test-svc",
-						TimeWindow: 30 * 24 * time.Hour,
-						Labels:     map[string]string{"gk1": "gv1"},
+						ID:          "test-svc-slo-test",
+						Name:        "slo-test",
+						Service:     "test-svc",
+						TimeWindow:  30 * 24 * time.Hour,
+						Labels:      map[string]string{"gk1": "gv1"},
+						Annotations: map[string]string{},
 						SLI: prometheus.SLI{
 							Raw: &prometheus.SLIRaw{
 								ErrorRatioQuery: `plugin_raw_expr{service="test-svc",slo="slo-test",objective="99.000000",gk1="gv1",k1="v1",k2="true"}`,
@@ -181,6 +183,341 @@ spec:
 			},
 		},
 
+		"Spec with environments should apply the selected environment overrides.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: k8s-test-svc
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: "slo-test"
+      objective: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+      alerting:
+        pageAlert:
+          disable: true
+        ticketAlert:
+          disable: true
+      environments:
+        staging:
+          objective: 99
+          sloPeriod: 7d
+`,
+			environment: "staging",
+			expModel: &k8sprometheus.SLOGroup{
+				K8sMeta: k8sprometheus.K8sMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+					UID:        "",
+					Name:       "k8s-test-svc",
+					Namespace:  "test-ns",
+				},
+				SLOGroup: prometheus.SLOGroup{SLOs: []prometheus.SLO{
+					{
+						ID:              "test-svc-slo-test",
+						Name:            "slo-test",
+						Service:         "test-svc",
+						TimeWindow:      7 * 24 * time.Hour,
+						Labels:          map[string]string{},
+						Annotations:     map[string]string{},
+						SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+						Objective:       99,
+						PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					},
+				}},
+			},
+		},
+
+		"Spec with a latency SLI should load the histogram events SLI correctly.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: k8s-test-svc
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: "slo-test"
+      objective: 99.9
+      sli:
+        latency:
+          histogramMetric: http_request_duration_seconds
+          threshold: 300ms
+          selector: job="api"
+      alerting:
+        pageAlert:
+          disable: true
+        ticketAlert:
+          disable: true
+`,
+			expModel: &k8sprometheus.SLOGroup{
+				K8sMeta: k8sprometheus.K8sMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+					UID:        "",
+					Name:       "k8s-test-svc",
+					Namespace:  "test-ns",
+				},
+				SLOGroup: prometheus.SLOGroup{SLOs: []prometheus.SLO{
+					{
+						ID:          "test-svc-slo-test",
+						Name:        "slo-test",
+						Service:     "test-svc",
+						TimeWindow:  30 * 24 * time.Hour,
+						Labels:      map[string]string{},
+						Annotations: map[string]string{},
+						SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+							ErrorQuery: "(sum(rate(http_request_duration_seconds_count{job=\"api\"}[{{.window}}])))\n-\n(sum(rate(http_request_duration_seconds_bucket{job=\"api\", le=\"0.3\"}[{{.window}}])))",
+							TotalQuery: `sum(rate(http_request_duration_seconds_count{job="api"}[{{.window}}]))`,
+						}},
+						Objective:       99.9,
+						PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					},
+				}},
+			},
+		},
+
+		"Spec with alert for durations should load the alerts for durations correctly.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: k8s-test-svc
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: "slo-test"
+      objective: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+      alerting:
+        name: testAlert
+        pageAlert:
+          for: 5m
+        ticketAlert:
+          disable: true
+`,
+			expModel: &k8sprometheus.SLOGroup{
+				K8sMeta: k8sprometheus.K8sMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+					UID:        "",
+					Name:       "k8s-test-svc",
+					Namespace:  "test-ns",
+				},
+				SLOGroup: prometheus.SLOGroup{SLOs: []prometheus.SLO{
+					{
+						ID:          "test-svc-slo-test",
+						Name:        "slo-test",
+						Service:     "test-svc",
+						TimeWindow:  30 * 24 * time.Hour,
+						Labels:      map[string]string{},
+						Annotations: map[string]string{},
+						SLI:         prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+						Objective:   99.9,
+						PageAlertMeta: prometheus.AlertMeta{
+							Name:        "testAlert",
+							Labels:      map[string]string{},
+							Annotations: map[string]string{},
+							For:         5 * time.Minute,
+						},
+						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					},
+				}},
+			},
+		},
+
+		"Spec with an invalid alert for duration should fail.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: k8s-test-svc
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: "slo-test"
+      objective: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+      alerting:
+        name: testAlert
+        pageAlert:
+          disable: true
+        ticketAlert:
+          for: "a while"
+`,
+			expErr: true,
+		},
+
+		"Spec with timeslice should load the timeslice correctly.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: k8s-test-svc
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: "slo-test"
+      objective: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+      alerting:
+        pageAlert:
+          disable: true
+        ticketAlert:
+          disable: true
+      timeslice:
+        window: 1m
+        errorRatioThreshold: 0.05
+`,
+			expModel: &k8sprometheus.SLOGroup{
+				K8sMeta: k8sprometheus.K8sMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+					UID:        "",
+					Name:       "k8s-test-svc",
+					Namespace:  "test-ns",
+				},
+				SLOGroup: prometheus.SLOGroup{SLOs: []prometheus.SLO{
+					{
+						ID:              "test-svc-slo-test",
+						Name:            "slo-test",
+						Service:         "test-svc",
+						TimeWindow:      30 * 24 * time.Hour,
+						Labels:          map[string]string{},
+						Annotations:     map[string]string{},
+						SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+						Objective:       99.9,
+						PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+						Timeslice:       &prometheus.SLOTimeslice{Window: time.Minute, ErrorRatioThreshold: 0.05},
+					},
+				}},
+			},
+		},
+
+		"Spec with rule group intervals should load the rule group intervals correctly.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: k8s-test-svc
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: "slo-test"
+      objective: 99.9
+      ruleGroupIntervals:
+        default: 2m
+        sliRecordings: 1m
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+      alerting:
+        pageAlert:
+          disable: true
+        ticketAlert:
+          disable: true
+`,
+			expModel: &k8sprometheus.SLOGroup{
+				K8sMeta: k8sprometheus.K8sMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+					UID:        "",
+					Name:       "k8s-test-svc",
+					Namespace:  "test-ns",
+				},
+				SLOGroup: prometheus.SLOGroup{SLOs: []prometheus.SLO{
+					{
+						ID:                 "test-svc-slo-test",
+						Name:               "slo-test",
+						Service:            "test-svc",
+						TimeWindow:         30 * 24 * time.Hour,
+						Labels:             map[string]string{},
+						Annotations:        map[string]string{},
+						SLI:                prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+						Objective:          99.9,
+						PageAlertMeta:      prometheus.AlertMeta{Disable: true},
+						TicketAlertMeta:    prometheus.AlertMeta{Disable: true},
+						RuleGroupIntervals: prometheus.RuleGroupIntervals{SLIRecordings: time.Minute, MetaRecordings: 2 * time.Minute, Alerts: 2 * time.Minute},
+					},
+				}},
+			},
+		},
+
+		"Spec with SLI examples should load the examples correctly.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: k8s-test-svc
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: "slo-test"
+      objective: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+        examples:
+          - name: all-errors
+            series:
+              test_expr_ratio: 1
+            errorRatio: 1
+      alerting:
+        pageAlert:
+          disable: true
+        ticketAlert:
+          disable: true
+`,
+			expModel: &k8sprometheus.SLOGroup{
+				K8sMeta: k8sprometheus.K8sMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+					UID:        "",
+					Name:       "k8s-test-svc",
+					Namespace:  "test-ns",
+				},
+				SLOGroup: prometheus.SLOGroup{SLOs: []prometheus.SLO{
+					{
+						ID:          "test-svc-slo-test",
+						Name:        "slo-test",
+						Service:     "test-svc",
+						TimeWindow:  30 * 24 * time.Hour,
+						Labels:      map[string]string{},
+						Annotations: map[string]string{},
+						SLI: prometheus.SLI{
+							Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+							Examples: []prometheus.SLIExample{
+								{Name: "all-errors", Series: map[string]float64{"test_expr_ratio": 1}, ErrorRatio: 1},
+							},
+						},
+						Objective:       99.9,
+						PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					},
+				}},
+			},
+		},
+
 		"An spec with SLI plugin that returns an error should use the plugin correctly and fail.": {
 			plugins: map[string]prometheus.SLIPlugin{
 				"test_plugin": {
@@ -230,10 +567,14 @@ spec:
   service: "test-svc"
   labels:
     owner: "myteam"
+  annotations:
+    team: "My team"
   slos:
     - name: "slo1"
       labels:
         category: test
+      annotations:
+        dashboard: http://dashboard.com
       objective: 99.99999
       description: "This is a test."
       sli:
@@ -299,6 +640,10 @@ spec:
 							"owner":    "myteam",
 							"category": "test",
 						},
+						Annotations: map[string]string{
+							"team":      "My team",
+							"dashboard": "http://dashboard.com",
+						},
 						PageAlertMeta: prometheus.AlertMeta{
 							Disable: false,
 							Name:    "testAlert",
@@ -341,6 +686,9 @@ spec:
 							"owner":    "myteam",
 							"category": "test2",
 						},
+						Annotations: map[string]string{
+							"team": "My team",
+						},
 						PageAlertMeta:   prometheus.AlertMeta{Disable: true},
 						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
 					},
@@ -354,7 +702,7 @@ spec:
 		t.Run(name, func(t *testing.T) {
 			assert := assert.New(t)
 
-			loader := k8sprometheus.NewYAMLSpecLoader(testMemPluginsRepo(test.plugins))
+			loader := k8sprometheus.NewYAMLSpecLoader(testMemPluginsRepo(test.plugins)).WithEnvironment(test.environment)
 			gotModel, err := loader.LoadSpec(context.TODO(), []byte(test.specYaml))
 
 			if test.expErr {
@@ -365,3 +713,91 @@ spec:
 		})
 	}
 }
+
+func TestYAMLoadSpecStrict(t *testing.T) {
+	tests := map[string]struct {
+		specYaml      string
+		strict        bool
+		expErrMessage string
+	}{
+		"A spec with unknown fields should load in lenient mode.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: test
+spec:
+  service: "test-svc"
+  slos:
+    - name: "slo1"
+      objetive: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+`,
+		},
+
+		"A spec with unknown fields should fail in strict mode with the unknown fields path.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: test
+  labelz:
+    app: test
+spec:
+  service: "test-svc"
+  slos:
+    - name: "slo1"
+      objetive: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+`,
+			strict:        true,
+			expErrMessage: "unknown spec fields: $.metadata.labelz, $.spec.slos[0].objetive",
+		},
+
+		"A spec without unknown fields should load in strict mode.": {
+			specYaml: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: test
+  namespace: test-ns
+  labels:
+    app: test
+  creationTimestamp: "2021-01-01T00:00:00Z"
+spec:
+  service: "test-svc"
+  slos:
+    - name: "slo1"
+      objective: 99.9
+      sli:
+        raw:
+          errorRatioQuery: test_expr_ratio
+      alerting:
+        pageAlert:
+          disable: true
+        ticketAlert:
+          disable: true
+`,
+			strict: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			loader := k8sprometheus.NewYAMLSpecLoader(testMemPluginsRepo(nil)).WithStrict(test.strict)
+			_, err := loader.LoadSpec(context.TODO(), []byte(test.specYaml))
+
+			if test.expErrMessage != "" {
+				assert.EqualError(err, test.expErrMessage)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@ package k8sprometheus
 import (
 	"bytes"
 	"context"
+	gojson "encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	prommodel "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -19,26 +23,71 @@ import (
 	"github.com/slok/sloth/internal/prometheus"
 )
 
+// maxPrometheusRuleSize is the maximum size (in bytes) of a generated Prometheus operator rule object.
+// Kubernetes objects are stored in etcd that has a default request size limit of 1.5MiB, we use a
+// lower limit to leave room for the metadata set by the apiserver (e.g managed fields).
+const maxPrometheusRuleSize = 1024 * 1024
+
 var (
 	// ErrNoSLORules will be used when there are no rules to store. The upper layer
 	// could ignore or handle the error in cases where there wasn't an output.
 	ErrNoSLORules = fmt.Errorf("0 SLO Prometheus rules generated")
 )
 
-func NewIOWriterPrometheusOperatorYAMLRepo(writer io.Writer, logger log.Logger) IOWriterPrometheusOperatorYAMLRepo {
+// PrometheusRuleMeta is the metadata scheme that will be stamped on the generated Prometheus
+// operator rule objects, this way the objects can match the `ruleSelector` of each Prometheus
+// operator setup.
+type PrometheusRuleMeta struct {
+	// Labels are the labels added to the generated objects, these have priority over
+	// the default labels and the SLO spec labels.
+	Labels map[string]string
+	// Annotations are the annotations added to the generated objects, these have priority over
+	// the SLO spec annotations.
+	Annotations map[string]string
+	// DisableDefaultLabels will not set the default Sloth labels (component and managed-by).
+	DisableDefaultLabels bool
+	// PartialResponseStrategy is the Thanos ruler partial response strategy (`warn` or `abort`)
+	// of the rule groups, if empty it will not be set (e.g Prometheus).
+	PartialResponseStrategy string
+	// Intervals are the evaluation intervals of the rule groups, the SLO rule group
+	// intervals have priority over these.
+	Intervals prometheus.RuleGroupIntervals
+	// SLIWindowGroups partitions the SLI recording rules rule groups by SLI window.
+	SLIWindowGroups prometheus.SLIWindowGroups
+	// Format is the Kubernetes rule object format, if empty it will use the Prometheus
+	// operator format.
+	Format RuleFormat
+}
+
+// RuleFormat is the format of the generated Kubernetes rule objects.
+type RuleFormat string
+
+const (
+	// RuleFormatPrometheusOperator is the Prometheus operator `PrometheusRule` format.
+	RuleFormatPrometheusOperator RuleFormat = "prometheus-operator"
+	// RuleFormatVictoriaMetrics is the VictoriaMetrics operator `VMRule` format.
+	RuleFormatVictoriaMetrics RuleFormat = "victoriametrics"
+	// RuleFormatThanosRuler is the Prometheus operator `PrometheusRule` format, with the Thanos
+	// ruler rule groups partial response strategy, selected by the `ThanosRuler` objects.
+	RuleFormatThanosRuler RuleFormat = "thanos-ruler"
+)
+
+func NewIOWriterPrometheusOperatorYAMLRepo(writer io.Writer, ruleMeta PrometheusRuleMeta, logger log.Logger) IOWriterPrometheusOperatorYAMLRepo {
 	return IOWriterPrometheusOperatorYAMLRepo{
-		writer:  writer,
-		encoder: json.NewYAMLSerializer(json.DefaultMetaFactory, nil, nil),
-		logger:  logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "k8s-prometheus-operator"}),
+		writer:   writer,
+		ruleMeta: ruleMeta,
+		encoder:  json.NewYAMLSerializer(json.DefaultMetaFactory, nil, nil),
+		logger:   logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "k8s-prometheus-operator"}),
 	}
 }
 
 // IOWriterPrometheusOperatorYAMLRepo knows to store all the SLO rules (recordings and alerts)
 // grouped in an IOWriter in Kubernetes prometheus operator YAML format.
 type IOWriterPrometheusOperatorYAMLRepo struct {
-	writer  io.Writer
-	encoder runtime.Encoder
-	logger  log.Logger
+	writer   io.Writer
+	ruleMeta PrometheusRuleMeta
+	encoder  runtime.Encoder
+	logger   log.Logger
 }
 
 type StorageSLO struct {
@@ -47,15 +96,24 @@ type StorageSLO struct {
 }
 
 func (i IOWriterPrometheusOperatorYAMLRepo) StoreSLOs(ctx context.Context, kmeta K8sMeta, slos []StorageSLO) error {
-	rule, err := mapModelToPrometheusOperator(ctx, kmeta, slos)
+	rule, err := mapModelToPrometheusOperator(ctx, i.ruleMeta, kmeta, slos)
 	if err != nil {
 		return fmt.Errorf("could not map model to Prometheus operator CR: %w", err)
 	}
 
 	var b bytes.Buffer
-	err = i.encoder.Encode(rule, &b)
-	if err != nil {
-		return fmt.Errorf("could encode prometheus operator object: %w", err)
+	switch i.ruleMeta.Format {
+	case RuleFormatVictoriaMetrics:
+		data, err := yaml.Marshal(mapPrometheusOperatorToVMRule(rule))
+		if err != nil {
+			return fmt.Errorf("could encode victoriametrics operator object: %w", err)
+		}
+		b.Write(data)
+	default:
+		err = i.encoder.Encode(rule, &b)
+		if err != nil {
+			return fmt.Errorf("could encode prometheus operator object: %w", err)
+		}
 	}
 
 	rulesYaml := writeTopDisclaimer(b.Bytes())
@@ -67,14 +125,18 @@ func (i IOWriterPrometheusOperatorYAMLRepo) StoreSLOs(ctx context.Context, kmeta
 	return nil
 }
 
-func mapModelToPrometheusOperator(ctx context.Context, kmeta K8sMeta, slos []StorageSLO) (*monitoringv1.PrometheusRule, error) {
+func mapModelToPrometheusOperator(ctx context.Context, ruleMeta PrometheusRuleMeta, kmeta K8sMeta, slos []StorageSLO) (*monitoringv1.PrometheusRule, error) {
 	// Add extra labels.
-	labels := map[string]string{
-		"app.kubernetes.io/component":  "SLO",
-		"app.kubernetes.io/managed-by": "sloth",
+	labels := map[string]string{}
+	if !ruleMeta.DisableDefaultLabels {
+		labels["app.kubernetes.io/component"] = "SLO"
+		labels["app.kubernetes.io/managed-by"] = "sloth"
 	}
-	for k, v := range kmeta.Labels {
-		labels[k] = v
+	labels = mergeLabels(labels, kmeta.Labels, ruleMeta.Labels)
+
+	annotations := kmeta.Annotations
+	if len(ruleMeta.Annotations) > 0 {
+		annotations = mergeLabels(kmeta.Annotations, ruleMeta.Annotations)
 	}
 
 	rule := &monitoringv1.PrometheusRule{
@@ -86,7 +148,7 @@ func mapModelToPrometheusOperator(ctx context.Context, kmeta K8sMeta, slos []Sto
 			Name:        kmeta.Name,
 			Namespace:   kmeta.Namespace,
 			Labels:      labels,
-			Annotations: kmeta.Annotations,
+			Annotations: annotations,
 		},
 	}
 
@@ -94,38 +156,143 @@ func mapModelToPrometheusOperator(ctx context.Context, kmeta K8sMeta, slos []Sto
 		return nil, fmt.Errorf("slo rules required")
 	}
 
+	sliRecordings := make([]prometheus.SLOSLIRecordings, 0, len(slos))
 	for _, slo := range slos {
-		if len(slo.Rules.SLIErrorRecRules) > 0 {
+		intervals := slo.SLO.RuleGroupIntervals.WithDefaults(ruleMeta.Intervals)
+		sloSLIRecordings := prometheus.SLOSLIRecordings{
+			SLOID:    slo.SLO.ID,
+			Rules:    slo.Rules.SLIErrorRecRules,
+			Interval: intervals.SLIRecordings,
+		}
+		sliRecordings = append(sliRecordings, sloSLIRecordings)
+
+		if g := ruleMeta.SLIWindowGroups.SLORuleGroup(sloSLIRecordings); g != nil {
 			rule.Spec.Groups = append(rule.Spec.Groups, monitoringv1.RuleGroup{
-				Name:  fmt.Sprintf("sloth-slo-sli-recordings-%s", slo.SLO.ID),
-				Rules: promRulesToKubeRules(slo.Rules.SLIErrorRecRules),
+				Name:                    g.Name,
+				Interval:                ruleGroupInterval(g.Interval),
+				Rules:                   promRulesToKubeRules(g.Rules),
+				PartialResponseStrategy: ruleMeta.PartialResponseStrategy,
 			})
 		}
 
 		if len(slo.Rules.MetadataRecRules) > 0 {
 			rule.Spec.Groups = append(rule.Spec.Groups, monitoringv1.RuleGroup{
-				Name:  fmt.Sprintf("sloth-slo-meta-recordings-%s", slo.SLO.ID),
-				Rules: promRulesToKubeRules(slo.Rules.MetadataRecRules),
+				Name:                    fmt.Sprintf("sloth-slo-meta-recordings-%s", slo.SLO.ID),
+				Interval:                ruleGroupInterval(intervals.MetaRecordings),
+				Rules:                   promRulesToKubeRules(slo.Rules.MetadataRecRules),
+				PartialResponseStrategy: ruleMeta.PartialResponseStrategy,
 			})
 		}
 
 		if len(slo.Rules.AlertRules) > 0 {
 			rule.Spec.Groups = append(rule.Spec.Groups, monitoringv1.RuleGroup{
-				Name:  fmt.Sprintf("sloth-slo-alerts-%s", slo.SLO.ID),
-				Rules: promRulesToKubeRules(slo.Rules.AlertRules),
+				Name:                    fmt.Sprintf("sloth-slo-alerts-%s", slo.SLO.ID),
+				Interval:                ruleGroupInterval(intervals.Alerts),
+				Rules:                   promRulesToKubeRules(slo.Rules.AlertRules),
+				PartialResponseStrategy: ruleMeta.PartialResponseStrategy,
 			})
 		}
 	}
 
+	// The SLI recording rules of the SLOs shared per window rule groups.
+	sliWindowGroups, err := ruleMeta.SLIWindowGroups.WindowRuleGroups(sliRecordings)
+	if err != nil {
+		return nil, fmt.Errorf("could not group the SLI recording rules by window: %w", err)
+	}
+	for _, g := range sliWindowGroups {
+		rule.Spec.Groups = append(rule.Spec.Groups, monitoringv1.RuleGroup{
+			Name:                    g.Name,
+			Interval:                ruleGroupInterval(g.Interval),
+			Rules:                   promRulesToKubeRules(g.Rules),
+			PartialResponseStrategy: ruleMeta.PartialResponseStrategy,
+		})
+	}
+
 	// If we don't have anything to store, error so we can increase the reliability
 	// because maybe this was due to an unintended error (typos, misconfig, too many disable...).
 	if len(rule.Spec.Groups) == 0 {
 		return nil, ErrNoSLORules
 	}
 
+	// Check the object can be stored on Kubernetes.
+	ruleData, err := gojson.Marshal(rule)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal Prometheus operator rule: %w", err)
+	}
+	if len(ruleData) > maxPrometheusRuleSize {
+		return nil, fmt.Errorf("prometheus operator rule size is %d bytes and the maximum is %d bytes, split the SLOs in multiple specs", len(ruleData), maxPrometheusRuleSize)
+	}
+
 	return rule, nil
 }
 
+// mapPrometheusOperatorToVMRule maps a Prometheus operator rule to a VictoriaMetrics operator
+// rule, both have the same rule groups format.
+func mapPrometheusOperatorToVMRule(rule *monitoringv1.PrometheusRule) vmRuleYAML {
+	vmRule := vmRuleYAML{
+		APIVersion: "operator.victoriametrics.com/v1beta1",
+		Kind:       "VMRule",
+		Metadata: vmRuleMetadataYAML{
+			Annotations: rule.Annotations,
+			Labels:      rule.Labels,
+			Name:        rule.Name,
+			Namespace:   rule.Namespace,
+		},
+	}
+
+	for _, g := range rule.Spec.Groups {
+		group := vmRuleGroupYAML{Interval: g.Interval, Name: g.Name}
+		for _, r := range g.Rules {
+			group.Rules = append(group.Rules, vmRuleRuleYAML{
+				Alert:       r.Alert,
+				Annotations: r.Annotations,
+				Expr:        r.Expr.String(),
+				For:         r.For,
+				Labels:      r.Labels,
+				Record:      r.Record,
+			})
+		}
+		vmRule.Spec.Groups = append(vmRule.Spec.Groups, group)
+	}
+
+	return vmRule
+}
+
+// VictoriaMetrics operator `VMRule` types, the keys are sorted like the Kubernetes
+// YAML serializer.
+type vmRuleYAML struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   vmRuleMetadataYAML `yaml:"metadata"`
+	Spec       vmRuleSpecYAML     `yaml:"spec"`
+}
+
+type vmRuleMetadataYAML struct {
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+}
+
+type vmRuleSpecYAML struct {
+	Groups []vmRuleGroupYAML `yaml:"groups"`
+}
+
+type vmRuleGroupYAML struct {
+	Interval string           `yaml:"interval,omitempty"`
+	Name     string           `yaml:"name"`
+	Rules    []vmRuleRuleYAML `yaml:"rules"`
+}
+
+type vmRuleRuleYAML struct {
+	Alert       string            `yaml:"alert,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Record      string            `yaml:"record,omitempty"`
+}
+
 func promRulesToKubeRules(rules []rulefmt.Rule) []monitoringv1.Rule {
 	res := make([]monitoringv1.Rule, 0, len(rules))
 	for _, r := range rules {
@@ -146,6 +313,16 @@ func promRulesToKubeRules(rules []rulefmt.Rule) []monitoringv1.Rule {
 	return res
 }
 
+// ruleGroupInterval returns the Prometheus duration of a rule group interval, empty if
+// not set so the group uses the global evaluation interval.
+func ruleGroupInterval(interval time.Duration) string {
+	if interval == 0 {
+		return ""
+	}
+
+	return prommodel.Duration(interval).String()
+}
+
 func writeTopDisclaimer(bs []byte) []byte {
 	return append([]byte(disclaimer), bs...)
 }
@@ -157,18 +334,20 @@ var disclaimer = fmt.Sprintf(`
 
 `, info.Version)
 
-func NewPrometheusOperatorCRDRepo(ensurer PrometheusRulesEnsurer, logger log.Logger) PrometheusOperatorCRDRepo {
+func NewPrometheusOperatorCRDRepo(ensurer PrometheusRulesEnsurer, ruleMeta PrometheusRuleMeta, logger log.Logger) PrometheusOperatorCRDRepo {
 	return PrometheusOperatorCRDRepo{
-		ensurer: ensurer,
-		logger:  logger.WithValues(log.Kv{"svc": "storage.PrometheusOperatorCRDAPIServer", "format": "k8s-prometheus-operator"}),
+		ensurer:  ensurer,
+		ruleMeta: ruleMeta,
+		logger:   logger.WithValues(log.Kv{"svc": "storage.PrometheusOperatorCRDAPIServer", "format": "k8s-prometheus-operator"}),
 	}
 }
 
 // PrometheusOperatorCRDRepo knows to store all the SLO rules (recordings and alerts)
 // grouped as a Kubernetes prometheus operator CR using Kubernetes API server.
 type PrometheusOperatorCRDRepo struct {
-	logger  log.Logger
-	ensurer PrometheusRulesEnsurer
+	logger   log.Logger
+	ensurer  PrometheusRulesEnsurer
+	ruleMeta PrometheusRuleMeta
 }
 
 type PrometheusRulesEnsurer interface {
@@ -179,7 +358,7 @@ type PrometheusRulesEnsurer interface {
 
 func (p PrometheusOperatorCRDRepo) StoreSLOs(ctx context.Context, kmeta K8sMeta, slos []StorageSLO) error {
 	// Map to the Prometheus operator CRD.
-	rule, err := mapModelToPrometheusOperator(ctx, kmeta, slos)
+	rule, err := mapModelToPrometheusOperator(ctx, p.ruleMeta, kmeta, slos)
 	if err != nil {
 		return fmt.Errorf("could not map model to Prometheus operator CR: %w", err)
 	}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	prommodel "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/rulefmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,10 +25,11 @@ import (
 
 func TestIOWriterPrometheusOperatorYAMLRepo(t *testing.T) {
 	tests := map[string]struct {
-		k8sMeta k8sprometheus.K8sMeta
-		slos    []k8sprometheus.StorageSLO
-		expYAML string
-		expErr  bool
+		ruleMeta k8sprometheus.PrometheusRuleMeta
+		k8sMeta  k8sprometheus.K8sMeta
+		slos     []k8sprometheus.StorageSLO
+		expYAML  string
+		expErr   bool
 	}{
 		"Having 0 SLO rules should fail.": {
 			k8sMeta: k8sprometheus.K8sMeta{},
@@ -41,6 +45,24 @@ func TestIOWriterPrometheusOperatorYAMLRepo(t *testing.T) {
 			expErr: true,
 		},
 
+		"Having SLO rules over the Kubernetes object size limit should fail.": {
+			k8sMeta: k8sprometheus.K8sMeta{Name: "test-name", Namespace: "test-ns"},
+			slos: []k8sprometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   strings.Repeat("a", 2*1024*1024),
+							},
+						},
+					},
+				},
+			},
+			expErr: true,
+		},
+
 		"Having a single SLI recording rule should render correctly.": {
 			k8sMeta: k8sprometheus.K8sMeta{
 				Name:        "test-name",
@@ -90,6 +112,191 @@ spec:
 `,
 		},
 
+		"Having a partial response strategy should render the Thanos ruler rule groups correctly.": {
+			ruleMeta: k8sprometheus.PrometheusRuleMeta{PartialResponseStrategy: "warn"},
+			k8sMeta: k8sprometheus.K8sMeta{
+				Name:      "test-name",
+				Namespace: "test-ns",
+			},
+			slos: []k8sprometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   "test-expr",
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  creationTimestamp: null
+  labels:
+    app.kubernetes.io/component: SLO
+    app.kubernetes.io/managed-by: sloth
+  name: test-name
+  namespace: test-ns
+spec:
+  groups:
+  - name: sloth-slo-sli-recordings-test1
+    partial_response_strategy: warn
+    rules:
+    - expr: test-expr
+      record: test:record
+`,
+		},
+
+		"Having rule group intervals should render the rule groups with the intervals, the SLO ones with preference.": {
+			ruleMeta: k8sprometheus.PrometheusRuleMeta{Intervals: prometheus.RuleGroupIntervals{SLIRecordings: time.Minute, Alerts: time.Minute}},
+			k8sMeta: k8sprometheus.K8sMeta{
+				Name:      "test-name",
+				Namespace: "test-ns",
+			},
+			slos: []k8sprometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1", RuleGroupIntervals: prometheus.RuleGroupIntervals{SLIRecordings: 2 * time.Minute}},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   "test-expr",
+							},
+						},
+						MetadataRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record2",
+								Expr:   "test-expr2",
+							},
+						},
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert: "testAlert",
+								Expr:  "test-expr",
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  creationTimestamp: null
+  labels:
+    app.kubernetes.io/component: SLO
+    app.kubernetes.io/managed-by: sloth
+  name: test-name
+  namespace: test-ns
+spec:
+  groups:
+  - interval: 2m
+    name: sloth-slo-sli-recordings-test1
+    rules:
+    - expr: test-expr
+      record: test:record
+  - name: sloth-slo-meta-recordings-test1
+    rules:
+    - expr: test-expr2
+      record: test:record2
+  - interval: 1m
+    name: sloth-slo-alerts-test1
+    rules:
+    - alert: testAlert
+      expr: test-expr
+`,
+		},
+
+		"Having SLI window groups should render the SLI recording rules of all the SLOs grouped by window with the window intervals.": {
+			ruleMeta: k8sprometheus.PrometheusRuleMeta{
+				SLIWindowGroups: prometheus.SLIWindowGroups{
+					Enabled:   true,
+					Intervals: map[time.Duration]time.Duration{30 * 24 * time.Hour: 5 * time.Minute},
+				},
+			},
+			k8sMeta: k8sprometheus.K8sMeta{
+				Name:      "test-name",
+				Namespace: "test-ns",
+			},
+			slos: []k8sprometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record30d",
+								Expr:   "test-expr30d",
+								Labels: map[string]string{"sloth_window": "30d"},
+							},
+							{
+								Record: "test:record5m",
+								Expr:   "test-expr5m",
+								Labels: map[string]string{"sloth_window": "5m"},
+							},
+						},
+					},
+				},
+				{
+					SLO: prometheus.SLO{ID: "test2"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record5m",
+								Expr:   "test2-expr5m",
+								Labels: map[string]string{"sloth_window": "5m"},
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  creationTimestamp: null
+  labels:
+    app.kubernetes.io/component: SLO
+    app.kubernetes.io/managed-by: sloth
+  name: test-name
+  namespace: test-ns
+spec:
+  groups:
+  - name: sloth-slo-sli-window-recordings-5m
+    rules:
+    - expr: test-expr5m
+      labels:
+        sloth_window: 5m
+      record: test:record5m
+    - expr: test2-expr5m
+      labels:
+        sloth_window: 5m
+      record: test:record5m
+  - interval: 5m
+    name: sloth-slo-sli-window-recordings-30d
+    rules:
+    - expr: test-expr30d
+      labels:
+        sloth_window: 30d
+      record: test:record30d
+`,
+		},
+
 		"Having a single metadata recording rule should render correctly.": {
 			k8sMeta: k8sprometheus.K8sMeta{
 				Name:        "test-name",
@@ -191,6 +398,73 @@ spec:
 `,
 		},
 
+		"Having a VictoriaMetrics rule format should render the VMRule correctly.": {
+			ruleMeta: k8sprometheus.PrometheusRuleMeta{Format: k8sprometheus.RuleFormatVictoriaMetrics},
+			k8sMeta: k8sprometheus.K8sMeta{
+				Name:        "test-name",
+				Namespace:   "test-ns",
+				Labels:      map[string]string{"lk1": "lv1"},
+				Annotations: map[string]string{"ak1": "av1"},
+			},
+			slos: []k8sprometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   "test-expr",
+								Labels: map[string]string{"test-label": "one"},
+							},
+						},
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert:       "testAlert",
+								Expr:        "test-expr",
+								For:         prommodel.Duration(5 * time.Minute),
+								Labels:      map[string]string{"test-label": "one"},
+								Annotations: map[string]string{"test-annot": "one"},
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+apiVersion: operator.victoriametrics.com/v1beta1
+kind: VMRule
+metadata:
+  annotations:
+    ak1: av1
+  labels:
+    app.kubernetes.io/component: SLO
+    app.kubernetes.io/managed-by: sloth
+    lk1: lv1
+  name: test-name
+  namespace: test-ns
+spec:
+  groups:
+  - name: sloth-slo-sli-recordings-test1
+    rules:
+    - expr: test-expr
+      labels:
+        test-label: one
+      record: test:record
+  - name: sloth-slo-alerts-test1
+    rules:
+    - alert: testAlert
+      annotations:
+        test-annot: one
+      expr: test-expr
+      for: 5m
+      labels:
+        test-label: one
+`,
+		},
+
 		"Having a multiple SLO alert and recording rules should render correctly.": {
 			k8sMeta: k8sprometheus.K8sMeta{
 				Name:        "test-name",
@@ -353,7 +627,7 @@ spec:
 			assert := assert.New(t)
 
 			var gotYAML bytes.Buffer
-			repo := k8sprometheus.NewIOWriterPrometheusOperatorYAMLRepo(&gotYAML, log.Noop)
+			repo := k8sprometheus.NewIOWriterPrometheusOperatorYAMLRepo(&gotYAML, test.ruleMeta, log.Noop)
 			err := repo.StoreSLOs(context.TODO(), test.k8sMeta, test.slos)
 
 			if test.expErr {
@@ -367,10 +641,11 @@ spec:
 
 func TestPrometheusOperatorCRDRepo(t *testing.T) {
 	tests := map[string]struct {
-		k8sMeta k8sprometheus.K8sMeta
-		slos    []k8sprometheus.StorageSLO
-		mock    func(m *k8sprometheusmock.PrometheusRulesEnsurer)
-		expErr  bool
+		ruleMeta k8sprometheus.PrometheusRuleMeta
+		k8sMeta  k8sprometheus.K8sMeta
+		slos     []k8sprometheus.StorageSLO
+		mock     func(m *k8sprometheusmock.PrometheusRulesEnsurer)
+		expErr   bool
 	}{
 		"Having 0 SLO rules should fail.": {
 			k8sMeta: k8sprometheus.K8sMeta{},
@@ -598,6 +873,80 @@ func TestPrometheusOperatorCRDRepo(t *testing.T) {
 				m.On("EnsurePrometheusRule", mock.Anything, exp).Once().Return(nil)
 			},
 		},
+		"Having a custom rule meta scheme should set the labels and annotations on the Kubernetes objects.": {
+			ruleMeta: k8sprometheus.PrometheusRuleMeta{
+				Labels:               map[string]string{"lk1": "lv2", "prometheus": "k8s"},
+				Annotations:          map[string]string{"owner": "team-a"},
+				DisableDefaultLabels: true,
+			},
+			k8sMeta: k8sprometheus.K8sMeta{
+				Name:        "test-name",
+				Namespace:   "test-ns",
+				Labels:      map[string]string{"lk1": "lv1", "lk2": "lv2"},
+				Annotations: map[string]string{"ak1": "av1"},
+				Kind:        "test-kind",
+				APIVersion:  "test-apiversion",
+				UID:         "test-uid",
+			},
+			slos: []k8sprometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "testa"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record-a1",
+								Expr:   "test-expr-a1",
+								Labels: map[string]string{"test-label": "a-1"},
+							},
+						},
+					},
+				},
+			},
+			mock: func(m *k8sprometheusmock.PrometheusRulesEnsurer) {
+				exp := &monitoringv1.PrometheusRule{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: "monitoring.coreos.com/v1",
+						Kind:       "PrometheusRule",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-name",
+						Namespace: "test-ns",
+						Labels: map[string]string{
+							"lk1":        "lv2",
+							"lk2":        "lv2",
+							"prometheus": "k8s",
+						},
+						Annotations: map[string]string{
+							"ak1":   "av1",
+							"owner": "team-a",
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								Kind:       "test-kind",
+								APIVersion: "test-apiversion",
+								Name:       "test-name",
+								UID:        types.UID("test-uid"),
+							},
+						},
+					},
+					Spec: monitoringv1.PrometheusRuleSpec{
+						Groups: []monitoringv1.RuleGroup{
+							{
+								Name: "sloth-slo-sli-recordings-testa",
+								Rules: []monitoringv1.Rule{
+									{
+										Record: "test:record-a1",
+										Expr:   intstr.FromString("test-expr-a1"),
+										Labels: map[string]string{"test-label": "a-1"},
+									},
+								},
+							},
+						},
+					},
+				}
+				m.On("EnsurePrometheusRule", mock.Anything, exp).Once().Return(nil)
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -608,7 +957,7 @@ func TestPrometheusOperatorCRDRepo(t *testing.T) {
 			mpre := &k8sprometheusmock.PrometheusRulesEnsurer{}
 			test.mock(mpre)
 
-			repo := k8sprometheus.NewPrometheusOperatorCRDRepo(mpre, log.Noop)
+			repo := k8sprometheus.NewPrometheusOperatorCRDRepo(mpre, test.ruleMeta, log.Noop)
 			err := repo.StoreSLOs(context.TODO(), test.k8sMeta, test.slos)
 
 			if test.expErr {
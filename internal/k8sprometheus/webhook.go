@@ -0,0 +1,113 @@
+package k8sprometheus
+
+import (
+	"context"
+	gojson "encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/slok/sloth/internal/log"
+	k8sprometheusv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+)
+
+// CRSpecValidator knows how to validate a Kubernetes PrometheusServiceLevel CR.
+type CRSpecValidator struct {
+	loader CRSpecLoader
+}
+
+// NewCRSpecValidator returns a new PrometheusServiceLevel CR validator, the CRs are
+// loaded with the loader as the controller would do, so the same SLO specs are valid.
+func NewCRSpecValidator(loader CRSpecLoader) CRSpecValidator {
+	return CRSpecValidator{loader: loader}
+}
+
+// Validate validates the PrometheusServiceLevel CR SLOs (e.g the objective bounds and the
+// SLI PromQL queries).
+func (c CRSpecValidator) Validate(ctx context.Context, spec *k8sprometheusv1.PrometheusServiceLevel) error {
+	slos, err := c.loader.LoadSpec(ctx, spec)
+	if err != nil {
+		return fmt.Errorf("could not load SLOs spec: %w", err)
+	}
+
+	err = slos.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid SLOs: %w", err)
+	}
+
+	return nil
+}
+
+// NewAdmissionWebhookHandler returns a Kubernetes validating admission webhook HTTP handler
+// that rejects the invalid PrometheusServiceLevel CRs on creation and update, before the
+// controller processes them.
+func NewAdmissionWebhookHandler(validator CRSpecValidator, logger log.Logger) http.Handler {
+	logger = logger.WithValues(log.Kv{"svc": "k8sprometheus.AdmissionWebhook"})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		review := admissionv1.AdmissionReview{}
+		err := gojson.NewDecoder(r.Body).Decode(&review)
+		if err != nil || review.Request == nil {
+			http.Error(w, "invalid admission review", http.StatusBadRequest)
+			return
+		}
+
+		review.Response = reviewAdmissionRequest(r.Context(), validator, *review.Request)
+		if !review.Response.Allowed {
+			logger.WithValues(log.Kv{"namespace": review.Request.Namespace, "name": review.Request.Name}).Infof("PrometheusServiceLevel rejected: %s", review.Response.Result.Message)
+		}
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		err = gojson.NewEncoder(w).Encode(review)
+		if err != nil {
+			logger.Errorf("Could not write admission review response: %s", err)
+		}
+	})
+}
+
+func reviewAdmissionRequest(ctx context.Context, validator CRSpecValidator, req admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	// Only the created and updated objects need to be valid.
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	}
+
+	deny := func(code int32, msg string) *admissionv1.AdmissionResponse {
+		return &admissionv1.AdmissionResponse{
+			UID:     req.UID,
+			Allowed: false,
+			Result:  &metav1.Status{Status: metav1.StatusFailure, Code: code, Message: msg},
+		}
+	}
+
+	spec := &k8sprometheusv1.PrometheusServiceLevel{}
+	err := gojson.Unmarshal(req.Object.Raw, spec)
+	if err != nil {
+		return deny(http.StatusBadRequest, fmt.Sprintf("could not decode PrometheusServiceLevel: %s", err))
+	}
+
+	// The created objects with a generated name don't have name yet.
+	if spec.Name == "" {
+		spec.Name = req.Name
+	}
+	if spec.Name == "" {
+		spec.Name = spec.GenerateName
+	}
+	if spec.Namespace == "" {
+		spec.Namespace = req.Namespace
+	}
+
+	err = validator.Validate(ctx, spec)
+	if err != nil {
+		return deny(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	return &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+}
@@ -0,0 +1,138 @@
+package k8sprometheus_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/slok/sloth/internal/k8sprometheus"
+	"github.com/slok/sloth/internal/log"
+)
+
+func TestAdmissionWebhookHandler(t *testing.T) {
+	admissionReview := func(operation, object string) string {
+		return `{
+  "apiVersion": "admission.k8s.io/v1",
+  "kind": "AdmissionReview",
+  "request": {
+    "uid": "test-uid",
+    "name": "test-name",
+    "namespace": "test-ns",
+    "operation": "` + operation + `",
+    "object": ` + object + `
+  }
+}`
+	}
+
+	sloCR := func(objective, errorQuery string) string {
+		return `{
+  "apiVersion": "sloth.slok.dev/v1",
+  "kind": "PrometheusServiceLevel",
+  "metadata": {"name": "test-name", "namespace": "test-ns"},
+  "spec": {
+    "service": "test-svc",
+    "slos": [
+      {
+        "name": "slo1",
+        "objective": ` + objective + `,
+        "sli": {"events": {"errorQuery": "` + errorQuery + `", "totalQuery": "sum(rate(http_request_duration_seconds_count[{{.window}}]))"}},
+        "alerting": {"pageAlert": {"disable": true}, "ticketAlert": {"disable": true}}
+      }
+    ]
+  }
+}`
+	}
+
+	tests := map[string]struct {
+		method     string
+		body       string
+		expCode    int
+		expAllowed bool
+		expMessage string
+	}{
+		"A non POST request should fail.": {
+			method:  http.MethodGet,
+			expCode: http.StatusMethodNotAllowed,
+		},
+
+		"An invalid admission review should fail.": {
+			method:  http.MethodPost,
+			body:    `{"request":`,
+			expCode: http.StatusBadRequest,
+		},
+
+		"An admission review without request should fail.": {
+			method:  http.MethodPost,
+			body:    `{"apiVersion": "admission.k8s.io/v1", "kind": "AdmissionReview"}`,
+			expCode: http.StatusBadRequest,
+		},
+
+		"A valid PrometheusServiceLevel creation should be allowed.": {
+			method:     http.MethodPost,
+			body:       admissionReview("CREATE", sloCR("99.9", `sum(rate(http_request_duration_seconds_count{code=~\"(5..|429)\"}[{{.window}}]))`)),
+			expCode:    http.StatusOK,
+			expAllowed: true,
+		},
+
+		"A PrometheusServiceLevel update with an objective out of bounds should be rejected.": {
+			method:     http.MethodPost,
+			body:       admissionReview("UPDATE", sloCR("100.5", `sum(rate(http_request_duration_seconds_count{code=~\"(5..|429)\"}[{{.window}}]))`)),
+			expCode:    http.StatusOK,
+			expAllowed: false,
+			expMessage: "Objective",
+		},
+
+		"A PrometheusServiceLevel creation with an invalid SLI query should be rejected.": {
+			method:     http.MethodPost,
+			body:       admissionReview("CREATE", sloCR("99.9", `sum(rate(http_request_duration_seconds_count{code=~\"(5..|429)\"}[{{.window}}])`)),
+			expCode:    http.StatusOK,
+			expAllowed: false,
+			expMessage: "ErrorQuery",
+		},
+
+		"A PrometheusServiceLevel deletion should be allowed.": {
+			method:     http.MethodPost,
+			body:       admissionReview("DELETE", `null`),
+			expCode:    http.StatusOK,
+			expAllowed: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			validator := k8sprometheus.NewCRSpecValidator(k8sprometheus.NewCRSpecLoader(testMemPluginsRepo{}))
+			h := k8sprometheus.NewAdmissionWebhookHandler(validator, log.Noop)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(test.method, "/validate", strings.NewReader(test.body)).WithContext(context.TODO())
+			h.ServeHTTP(w, r)
+
+			require.Equal(test.expCode, w.Code)
+			if test.expCode != http.StatusOK {
+				return
+			}
+
+			gotReview := admissionv1.AdmissionReview{}
+			err := json.Unmarshal(w.Body.Bytes(), &gotReview)
+			require.NoError(err)
+			require.NotNil(gotReview.Response)
+			assert.Nil(gotReview.Request)
+			assert.Equal("test-uid", string(gotReview.Response.UID))
+			assert.Equal(test.expAllowed, gotReview.Response.Allowed)
+			if !test.expAllowed {
+				require.NotNil(gotReview.Response.Result)
+				assert.Contains(gotReview.Response.Result.Message, test.expMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package newrelic
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// nrqlAggregateFuncs are the NRQL aggregator functions, these aggregate an attribute.
+var nrqlAggregateFuncs = map[string]bool{
+	"average": true,
+	"count":   true,
+	"latest":  true,
+	"max":     true,
+	"min":     true,
+	"sum":     true,
+}
+
+// nrqlMathFuncs are the NRQL math functions, these apply to a numeric expression.
+var nrqlMathFuncs = map[string]bool{
+	"abs":   true,
+	"ceil":  true,
+	"floor": true,
+	"round": true,
+	"sqrt":  true,
+}
+
+// ValidateNRQL validates a NRQL query using the subset of the NRQL grammar of the generated
+// conditions:
+//
+//	query     = "FROM" attribute "SELECT" expr [ "WHERE" condition { "AND" condition } ]
+//	expr      = term { ( "+" | "-" ) term }
+//	term      = factor { ( "*" | "/" ) factor }
+//	factor    = number | "-" factor | "(" expr ")" | aggregate "(" attribute ")" | math "(" expr ")"
+//	condition = attribute ( "=" | "!=" ) string
+func ValidateNRQL(query string) error {
+	tokens, err := lexNRQL(query)
+	if err != nil {
+		return fmt.Errorf("invalid NRQL: %w", err)
+	}
+
+	p := &nrqlParser{tokens: tokens}
+	err = p.parseQuery()
+	if err != nil {
+		return fmt.Errorf("invalid NRQL: %w", err)
+	}
+
+	return nil
+}
+
+type nrqlTokenType int
+
+const (
+	nrqlTokenIdent nrqlTokenType = iota
+	nrqlTokenNumber
+	nrqlTokenString
+	nrqlTokenSymbol
+)
+
+type nrqlToken struct {
+	typ nrqlTokenType
+	val string
+}
+
+// lexNRQL splits a NRQL query in tokens.
+func lexNRQL(query string) ([]nrqlToken, error) {
+	tokens := []nrqlToken{}
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '`':
+			end := i + 1
+			for end < len(rs) && rs[end] != '`' {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("unterminated quoted attribute at %d", i)
+			}
+			if end == i+1 {
+				return nil, fmt.Errorf("empty quoted attribute at %d", i)
+			}
+			tokens = append(tokens, nrqlToken{typ: nrqlTokenIdent, val: string(rs[i+1 : end])})
+			i = end + 1
+
+		case r == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(rs) && rs[j] != '\''; j++ {
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, nrqlToken{typ: nrqlTokenString, val: b.String()})
+			i = j + 1
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			if strings.Count(string(rs[i:j]), ".") > 1 {
+				return nil, fmt.Errorf("invalid number %q", string(rs[i:j]))
+			}
+			tokens = append(tokens, nrqlToken{typ: nrqlTokenNumber, val: string(rs[i:j])})
+			i = j
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, nrqlToken{typ: nrqlTokenIdent, val: string(rs[i:j])})
+			i = j
+
+		case r == '!' && i+1 < len(rs) && rs[i+1] == '=':
+			tokens = append(tokens, nrqlToken{typ: nrqlTokenSymbol, val: "!="})
+			i += 2
+
+		case strings.ContainsRune("()+-*/=", r):
+			tokens = append(tokens, nrqlToken{typ: nrqlTokenSymbol, val: string(r)})
+			i++
+
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", r, i)
+		}
+	}
+
+	return tokens, nil
+}
+
+// nrqlParser is a recursive descent parser of the NRQL grammar subset.
+type nrqlParser struct {
+	tokens []nrqlToken
+	pos    int
+}
+
+func (p *nrqlParser) peek() (nrqlToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return nrqlToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *nrqlParser) isKeyword(kw string) bool {
+	t, ok := p.peek()
+	return ok && t.typ == nrqlTokenIdent && strings.EqualFold(t.val, kw)
+}
+
+func (p *nrqlParser) isSymbol(s string) bool {
+	t, ok := p.peek()
+	return ok && t.typ == nrqlTokenSymbol && t.val == s
+}
+
+func (p *nrqlParser) expectKeyword(kw string) error {
+	if !p.isKeyword(kw) {
+		return fmt.Errorf("expected %q %s", kw, p.found())
+	}
+	p.pos++
+
+	return nil
+}
+
+func (p *nrqlParser) expectSymbol(s string) error {
+	if !p.isSymbol(s) {
+		return fmt.Errorf("expected %q %s", s, p.found())
+	}
+	p.pos++
+
+	return nil
+}
+
+func (p *nrqlParser) expectType(typ nrqlTokenType, name string) error {
+	t, ok := p.peek()
+	if !ok || t.typ != typ {
+		return fmt.Errorf("expected %s %s", name, p.found())
+	}
+	p.pos++
+
+	return nil
+}
+
+func (p *nrqlParser) found() string {
+	t, ok := p.peek()
+	if !ok {
+		return "at the end of the query"
+	}
+
+	return fmt.Sprintf("found %q", t.val)
+}
+
+func (p *nrqlParser) parseQuery() error {
+	err := p.expectKeyword("FROM")
+	if err != nil {
+		return err
+	}
+	err = p.expectType(nrqlTokenIdent, "event type")
+	if err != nil {
+		return err
+	}
+	err = p.expectKeyword("SELECT")
+	if err != nil {
+		return err
+	}
+	err = p.parseExpr()
+	if err != nil {
+		return err
+	}
+
+	if p.isKeyword("WHERE") {
+		p.pos++
+		for {
+			err := p.parseCondition()
+			if err != nil {
+				return err
+			}
+			if !p.isKeyword("AND") {
+				break
+			}
+			p.pos++
+		}
+	}
+
+	if _, ok := p.peek(); ok {
+		return fmt.Errorf("unexpected %s", p.found())
+	}
+
+	return nil
+}
+
+func (p *nrqlParser) parseExpr() error {
+	err := p.parseTerm()
+	if err != nil {
+		return err
+	}
+	for p.isSymbol("+") || p.isSymbol("-") {
+		p.pos++
+		err := p.parseTerm()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *nrqlParser) parseTerm() error {
+	err := p.parseFactor()
+	if err != nil {
+		return err
+	}
+	for p.isSymbol("*") || p.isSymbol("/") {
+		p.pos++
+		err := p.parseFactor()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *nrqlParser) parseFactor() error {
+	t, ok := p.peek()
+	if !ok {
+		return fmt.Errorf("expected expression %s", p.found())
+	}
+
+	switch {
+	case t.typ == nrqlTokenNumber:
+		p.pos++
+		return nil
+
+	case p.isSymbol("-"):
+		p.pos++
+		return p.parseFactor()
+
+	case p.isSymbol("("):
+		p.pos++
+		err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		return p.expectSymbol(")")
+
+	case t.typ == nrqlTokenIdent && nrqlAggregateFuncs[strings.ToLower(t.val)]:
+		p.pos++
+		err := p.expectSymbol("(")
+		if err != nil {
+			return err
+		}
+		err = p.expectType(nrqlTokenIdent, "attribute")
+		if err != nil {
+			return err
+		}
+		return p.expectSymbol(")")
+
+	case t.typ == nrqlTokenIdent && nrqlMathFuncs[strings.ToLower(t.val)]:
+		p.pos++
+		err := p.expectSymbol("(")
+		if err != nil {
+			return err
+		}
+		err = p.parseExpr()
+		if err != nil {
+			return err
+		}
+		return p.expectSymbol(")")
+	}
+
+	return fmt.Errorf("expected expression %s", p.found())
+}
+
+func (p *nrqlParser) parseCondition() error {
+	err := p.expectType(nrqlTokenIdent, "attribute")
+	if err != nil {
+		return err
+	}
+	if !p.isSymbol("=") && !p.isSymbol("!=") {
+		return fmt.Errorf("expected comparison operator %s", p.found())
+	}
+	p.pos++
+
+	return p.expectType(nrqlTokenString, "string")
+}
@@ -0,0 +1,92 @@
+package newrelic_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/newrelic"
+)
+
+func TestValidateNRQL(t *testing.T) {
+	tests := map[string]struct {
+		query  string
+		expErr bool
+	}{
+		"A query with an aggregation should be valid.": {
+			query: "FROM Metric SELECT latest(`slo:sli_error:ratio_rate5m`)",
+		},
+
+		"A query with arithmetic, math functions and filters should be valid.": {
+			query: "FROM Metric SELECT ((latest(`a`) / (14.4 * 0.001)) + (latest(b) / 2) - abs(latest(`a`) - -1)) / 2 WHERE sloth_id = 'svc-slo1' AND team != 'it\\'s'",
+		},
+
+		"Keywords should be case insensitive.": {
+			query: "from Metric select average(duration) where host = 'a'",
+		},
+
+		"A query without FROM should fail.": {
+			query:  "SELECT latest(`a`)",
+			expErr: true,
+		},
+
+		"A query without SELECT expression should fail.": {
+			query:  "FROM Metric SELECT WHERE a = 'b'",
+			expErr: true,
+		},
+
+		"A query with the if function should fail.": {
+			query:  "FROM Metric SELECT if(latest(`a`) > 1, 1, 0)",
+			expErr: true,
+		},
+
+		"A query with comparison operators on the select should fail.": {
+			query:  "FROM Metric SELECT latest(`a`) > 1",
+			expErr: true,
+		},
+
+		"An aggregation of an expression should fail.": {
+			query:  "FROM Metric SELECT latest(1 + 2)",
+			expErr: true,
+		},
+
+		"Unbalanced parentheses should fail.": {
+			query:  "FROM Metric SELECT (latest(`a`) / 2",
+			expErr: true,
+		},
+
+		"An unterminated quoted attribute should fail.": {
+			query:  "FROM Metric SELECT latest(`a)",
+			expErr: true,
+		},
+
+		"An unterminated string should fail.": {
+			query:  "FROM Metric SELECT latest(`a`) WHERE a = 'b",
+			expErr: true,
+		},
+
+		"A filter without string value should fail.": {
+			query:  "FROM Metric SELECT latest(`a`) WHERE a = b",
+			expErr: true,
+		},
+
+		"Trailing tokens should fail.": {
+			query:  "FROM Metric SELECT latest(`a`) LIMIT 10",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := newrelic.ValidateNRQL(test.query)
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package newrelic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+const (
+	priorityCritical = "CRITICAL"
+	priorityWarning  = "WARNING"
+)
+
+func NewIOWriterNRQLConditionsJSONRepo(writer io.Writer, logger log.Logger) IOWriterNRQLConditionsJSONRepo {
+	return IOWriterNRQLConditionsJSONRepo{
+		writer: writer,
+		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "newrelic-nrql-json"}),
+	}
+}
+
+// IOWriterNRQLConditionsJSONRepo knows to store the SLO alerts in an IOWriter as New Relic NRQL
+// static alert conditions in JSON format (NerdGraph `NrqlConditionStaticInput`).
+//
+// The conditions query the SLI error recording rules metrics, so the SLI recording rules
+// need to be evaluated by Prometheus and sent to New Relic (e.g using remote write).
+type IOWriterNRQLConditionsJSONRepo struct {
+	writer io.Writer
+	logger log.Logger
+}
+
+type nrqlConditions struct {
+	Conditions []nrqlCondition `json:"conditions"`
+}
+
+type nrqlCondition struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Enabled     bool                `json:"enabled"`
+	RunbookURL  string              `json:"runbookUrl,omitempty"`
+	NRQL        nrqlConditionQuery  `json:"nrql"`
+	Terms       []nrqlConditionTerm `json:"terms"`
+}
+
+type nrqlConditionQuery struct {
+	Query string `json:"query"`
+}
+
+type nrqlConditionTerm struct {
+	Operator             string  `json:"operator"`
+	Priority             string  `json:"priority"`
+	Threshold            float64 `json:"threshold"`
+	ThresholdDuration    int     `json:"thresholdDuration"`
+	ThresholdOccurrences string  `json:"thresholdOccurrences"`
+}
+
+func (i IOWriterNRQLConditionsJSONRepo) StoreSLOs(ctx context.Context, slos []prometheus.StorageSLO) error {
+	conditions := nrqlConditions{Conditions: []nrqlCondition{}}
+	for _, slo := range slos {
+		if !slo.SLO.PageAlertMeta.Disable {
+			cs, err := mapToNRQLConditions(slo.SLO, slo.SLO.PageAlertMeta, slo.Alerts.PageQuick, slo.Alerts.PageSlow, priorityCritical)
+			if err != nil {
+				return err
+			}
+			conditions.Conditions = append(conditions.Conditions, cs...)
+		}
+
+		if !slo.SLO.TicketAlertMeta.Disable {
+			cs, err := mapToNRQLConditions(slo.SLO, slo.SLO.TicketAlertMeta, slo.Alerts.TicketQuick, slo.Alerts.TicketSlow, priorityWarning)
+			if err != nil {
+				return err
+			}
+			conditions.Conditions = append(conditions.Conditions, cs...)
+		}
+	}
+
+	// If we don't have anything to store, error so we can increase the reliability
+	// because maybe this was due to an unintended error (typos, misconfig, too many disable...).
+	if len(conditions.Conditions) == 0 {
+		return prometheus.ErrNoSLORules
+	}
+
+	// Don't escape HTML, NRQL queries use comparison operators (e.g `>`).
+	enc := json.NewEncoder(i.writer)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(conditions)
+	if err != nil {
+		return fmt.Errorf("could not write NRQL conditions: %w", err)
+	}
+
+	logger := i.logger.WithCtxValues(ctx)
+	logger.WithValues(log.Kv{"conditions": len(conditions.Conditions)}).Infof("New Relic NRQL conditions written")
+
+	return nil
+}
+
+// mapToNRQLConditions maps the quick and slow multiwindow multi-burn alerts to NRQL conditions,
+// a condition per alert, triggering the alert if any of them is triggered.
+func mapToNRQLConditions(slo prometheus.SLO, sloAlert prometheus.AlertMeta, quick, slow alert.MWMBAlert, priority string) ([]nrqlCondition, error) {
+	conditions := []nrqlCondition{}
+	for _, a := range []alert.MWMBAlert{quick, slow} {
+		c, err := mapToNRQLCondition(slo, sloAlert, a, priority)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+
+	return conditions, nil
+}
+
+// mapToNRQLCondition maps a multiwindow multi-burn alert to a NRQL condition. The query returns
+// the SLI error ratio relative to the alert burn rate threshold (e.g 2 means twice the threshold)
+// of the best of the alert short and long windows, so the condition is triggered above 1 when
+// both windows are over the threshold, like the Prometheus alerts.
+//
+// NRQL doesn't have a scalar min function, so it's calculated with `abs()`.
+func mapToNRQLCondition(slo prometheus.SLO, sloAlert prometheus.AlertMeta, a alert.MWMBAlert, priority string) (nrqlCondition, error) {
+	errorBudgetRatio := a.ErrorBudget / 100
+	burnRatio := func(window time.Duration) string {
+		return fmt.Sprintf("latest(`%s`) / (%v * %v)", slo.GetSLIErrorMetric(window), a.BurnRateFactor, errorBudgetRatio)
+	}
+	query := fmt.Sprintf("FROM Metric SELECT %s WHERE %s", nrqlMin(burnRatio(a.ShortWindow), burnRatio(a.LongWindow)), nrqlFilter(slo.GetSLOIDPromLabels()))
+
+	err := ValidateNRQL(query)
+	if err != nil {
+		return nrqlCondition{}, fmt.Errorf("invalid %q SLO NRQL condition query: %w", slo.ID, err)
+	}
+
+	return nrqlCondition{
+		Name:        fmt.Sprintf("%s (%s %s %s/%s)", sloAlert.Name, slo.ID, a.Severity, prommodel.Duration(a.ShortWindow), prommodel.Duration(a.LongWindow)),
+		Description: fmt.Sprintf("%s %s SLO error budget burn rate is over expected.", slo.Service, slo.Name),
+		Enabled:     true,
+		RunbookURL:  sloAlert.Annotations["runbook"],
+		NRQL:        nrqlConditionQuery{Query: query},
+		Terms: []nrqlConditionTerm{
+			{
+				Operator:             "ABOVE",
+				Priority:             priority,
+				Threshold:            1,
+				ThresholdDuration:    60,
+				ThresholdOccurrences: "ALL",
+			},
+		},
+	}, nil
+}
+
+// nrqlMin returns the NRQL expression of the minimum of two NRQL expressions.
+func nrqlMin(a, b string) string {
+	return fmt.Sprintf("((%s) + (%s) - abs((%s) - (%s))) / 2", a, b, a, b)
+}
+
+// nrqlFilter returns a NRQL `WHERE` filter of the labels sorted by key.
+func nrqlFilter(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	filters := make([]string, 0, len(keys))
+	for _, k := range keys {
+		filters = append(filters, fmt.Sprintf("%s = '%s'", k, strings.ReplaceAll(labels[k], "'", `\'`)))
+	}
+
+	return strings.Join(filters, " AND ")
+}
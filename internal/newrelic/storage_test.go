@@ -0,0 +1,165 @@
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/newrelic"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func getAlertGroup() alert.MWMBAlertGroup {
+	return alert.MWMBAlertGroup{
+		PageQuick:   alert.MWMBAlert{ShortWindow: 5 * time.Minute, LongWindow: time.Hour, BurnRateFactor: 14.4, ErrorBudget: 0.1, Severity: alert.PageAlertSeverity},
+		PageSlow:    alert.MWMBAlert{ShortWindow: 30 * time.Minute, LongWindow: 6 * time.Hour, BurnRateFactor: 6, ErrorBudget: 0.1, Severity: alert.PageAlertSeverity},
+		TicketQuick: alert.MWMBAlert{ShortWindow: 2 * time.Hour, LongWindow: 24 * time.Hour, BurnRateFactor: 3, ErrorBudget: 0.1, Severity: alert.TicketAlertSeverity},
+		TicketSlow:  alert.MWMBAlert{ShortWindow: 6 * time.Hour, LongWindow: 3 * 24 * time.Hour, BurnRateFactor: 1, ErrorBudget: 0.1, Severity: alert.TicketAlertSeverity},
+	}
+}
+
+func TestIOWriterNRQLConditionsJSONRepoStore(t *testing.T) {
+	tests := map[string]struct {
+		slos    []prometheus.StorageSLO
+		expJSON string
+		expErr  bool
+	}{
+		"Having 0 SLO alerts should fail.": {
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{
+						ID:              "svc-slo1",
+						PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					},
+					Alerts: getAlertGroup(),
+				},
+			},
+			expErr: true,
+		},
+
+		"Having SLOs with alerts should render the NRQL conditions correctly.": {
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{
+						ID:      "svc-slo1",
+						Name:    "slo1",
+						Service: "svc",
+						PageAlertMeta: prometheus.AlertMeta{
+							Name:        "testAlert",
+							Annotations: map[string]string{"runbook": "http://runbook.com"},
+						},
+						TicketAlertMeta: prometheus.AlertMeta{Name: "testAlert"},
+					},
+					Alerts: getAlertGroup(),
+				},
+				{
+					SLO: prometheus.SLO{
+						ID:              "svc-slo2",
+						Name:            "slo2",
+						Service:         "svc",
+						PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+						TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					},
+					Alerts: getAlertGroup(),
+				},
+			},
+			expJSON: `{
+  "conditions": [
+    {
+      "name": "testAlert (svc-slo1 page 5m/1h)",
+      "description": "svc slo1 SLO error budget burn rate is over expected.",
+      "enabled": true,
+      "runbookUrl": "http://runbook.com",
+      "nrql": {
+        "query": "FROM Metric SELECT ((latest(` + "`slo:sli_error:ratio_rate5m`" + `) / (14.4 * 0.001)) + (latest(` + "`slo:sli_error:ratio_rate1h`" + `) / (14.4 * 0.001)) - abs((latest(` + "`slo:sli_error:ratio_rate5m`" + `) / (14.4 * 0.001)) - (latest(` + "`slo:sli_error:ratio_rate1h`" + `) / (14.4 * 0.001)))) / 2 WHERE sloth_id = 'svc-slo1' AND sloth_service = 'svc' AND sloth_slo = 'slo1'"
+      },
+      "terms": [
+        {
+          "operator": "ABOVE",
+          "priority": "CRITICAL",
+          "threshold": 1,
+          "thresholdDuration": 60,
+          "thresholdOccurrences": "ALL"
+        }
+      ]
+    },
+    {
+      "name": "testAlert (svc-slo1 page 30m/6h)",
+      "description": "svc slo1 SLO error budget burn rate is over expected.",
+      "enabled": true,
+      "runbookUrl": "http://runbook.com",
+      "nrql": {
+        "query": "FROM Metric SELECT ((latest(` + "`slo:sli_error:ratio_rate30m`" + `) / (6 * 0.001)) + (latest(` + "`slo:sli_error:ratio_rate6h`" + `) / (6 * 0.001)) - abs((latest(` + "`slo:sli_error:ratio_rate30m`" + `) / (6 * 0.001)) - (latest(` + "`slo:sli_error:ratio_rate6h`" + `) / (6 * 0.001)))) / 2 WHERE sloth_id = 'svc-slo1' AND sloth_service = 'svc' AND sloth_slo = 'slo1'"
+      },
+      "terms": [
+        {
+          "operator": "ABOVE",
+          "priority": "CRITICAL",
+          "threshold": 1,
+          "thresholdDuration": 60,
+          "thresholdOccurrences": "ALL"
+        }
+      ]
+    },
+    {
+      "name": "testAlert (svc-slo1 ticket 2h/1d)",
+      "description": "svc slo1 SLO error budget burn rate is over expected.",
+      "enabled": true,
+      "nrql": {
+        "query": "FROM Metric SELECT ((latest(` + "`slo:sli_error:ratio_rate2h`" + `) / (3 * 0.001)) + (latest(` + "`slo:sli_error:ratio_rate1d`" + `) / (3 * 0.001)) - abs((latest(` + "`slo:sli_error:ratio_rate2h`" + `) / (3 * 0.001)) - (latest(` + "`slo:sli_error:ratio_rate1d`" + `) / (3 * 0.001)))) / 2 WHERE sloth_id = 'svc-slo1' AND sloth_service = 'svc' AND sloth_slo = 'slo1'"
+      },
+      "terms": [
+        {
+          "operator": "ABOVE",
+          "priority": "WARNING",
+          "threshold": 1,
+          "thresholdDuration": 60,
+          "thresholdOccurrences": "ALL"
+        }
+      ]
+    },
+    {
+      "name": "testAlert (svc-slo1 ticket 6h/3d)",
+      "description": "svc slo1 SLO error budget burn rate is over expected.",
+      "enabled": true,
+      "nrql": {
+        "query": "FROM Metric SELECT ((latest(` + "`slo:sli_error:ratio_rate6h`" + `) / (1 * 0.001)) + (latest(` + "`slo:sli_error:ratio_rate3d`" + `) / (1 * 0.001)) - abs((latest(` + "`slo:sli_error:ratio_rate6h`" + `) / (1 * 0.001)) - (latest(` + "`slo:sli_error:ratio_rate3d`" + `) / (1 * 0.001)))) / 2 WHERE sloth_id = 'svc-slo1' AND sloth_service = 'svc' AND sloth_slo = 'slo1'"
+      },
+      "terms": [
+        {
+          "operator": "ABOVE",
+          "priority": "WARNING",
+          "threshold": 1,
+          "thresholdDuration": 60,
+          "thresholdOccurrences": "ALL"
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var gotJSON bytes.Buffer
+			repo := newrelic.NewIOWriterNRQLConditionsJSONRepo(&gotJSON, log.Noop)
+			err := repo.StoreSLOs(context.TODO(), test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expJSON, gotJSON.String())
+			}
+		})
+	}
+}
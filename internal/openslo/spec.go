@@ -0,0 +1,320 @@
+// Package openslo loads OpenSLO (https://openslo.com) `openslo/v1` SLO specs and converts
+// them to the Sloth Prometheus SLO model.
+package openslo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+const (
+	// APIVersion is the supported OpenSLO spec API version.
+	APIVersion = "openslo/v1"
+	// KindSLO is the supported OpenSLO spec kind.
+	KindSLO = "SLO"
+
+	budgetingMethodOccurrences = "Occurrences"
+	metricSourceTypePrometheus = "prometheus"
+)
+
+// spec is the subset of the OpenSLO v1 SLO spec that Sloth supports.
+type spec struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   metadata `yaml:"metadata"`
+	Spec       sloSpec  `yaml:"spec"`
+}
+
+type metadata struct {
+	Name        string            `yaml:"name"`
+	DisplayName string            `yaml:"displayName"`
+	Labels      labels            `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+type sloSpec struct {
+	Description     string       `yaml:"description"`
+	Service         string       `yaml:"service"`
+	Indicator       *indicator   `yaml:"indicator"`
+	IndicatorRef    string       `yaml:"indicatorRef"`
+	TimeWindow      []timeWindow `yaml:"timeWindow"`
+	BudgetingMethod string       `yaml:"budgetingMethod"`
+	Objectives      []objective  `yaml:"objectives"`
+}
+
+type indicator struct {
+	Metadata metadata      `yaml:"metadata"`
+	Spec     indicatorSpec `yaml:"spec"`
+}
+
+type indicatorSpec struct {
+	RatioMetric *ratioMetric `yaml:"ratioMetric"`
+}
+
+type ratioMetric struct {
+	Good  *metric `yaml:"good"`
+	Bad   *metric `yaml:"bad"`
+	Total *metric `yaml:"total"`
+}
+
+type metric struct {
+	MetricSource metricSource `yaml:"metricSource"`
+}
+
+type metricSource struct {
+	Type string            `yaml:"type"`
+	Spec map[string]string `yaml:"spec"`
+}
+
+type timeWindow struct {
+	Duration  string `yaml:"duration"`
+	IsRolling bool   `yaml:"isRolling"`
+}
+
+type objective struct {
+	Target        float64 `yaml:"target"`
+	TargetPercent float64 `yaml:"targetPercent"`
+}
+
+// labels are the OpenSLO labels, their values can be a single value or a list of values,
+// the list values are joined with commas.
+type labels map[string]string
+
+func (l *labels) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw map[string]interface{}
+	err := unmarshal(&raw)
+	if err != nil {
+		return err
+	}
+
+	res := labels{}
+	for k, v := range raw {
+		switch v := v.(type) {
+		case []interface{}:
+			values := make([]string, 0, len(v))
+			for _, value := range v {
+				values = append(values, fmt.Sprint(value))
+			}
+			res[k] = strings.Join(values, ",")
+		default:
+			res[k] = fmt.Sprint(v)
+		}
+	}
+	*l = res
+
+	return nil
+}
+
+// YAMLSpecLoader knows how to load OpenSLO YAML specs and converts them to a model.
+type YAMLSpecLoader struct {
+	defaultSLOPeriod time.Duration
+	alertWindows     *alert.WindowsCatalog
+}
+
+// NewYAMLSpecLoader returns an OpenSLO YAML spec loader.
+func NewYAMLSpecLoader() YAMLSpecLoader {
+	return YAMLSpecLoader{
+		defaultSLOPeriod: prometheus.DefaultSLOPeriod,
+	}
+}
+
+// WithDefaultSLOPeriod returns a copy of the loader that uses the SLO period as the
+// SLOs time window of the specs without time window.
+func (y YAMLSpecLoader) WithDefaultSLOPeriod(period time.Duration) YAMLSpecLoader {
+	y.defaultSLOPeriod = period
+	return y
+}
+
+// WithAlertWindows returns a copy of the loader that selects the SLOs alert windows from
+// the alert windows catalog, based on the SLO period.
+func (y YAMLSpecLoader) WithAlertWindows(catalog *alert.WindowsCatalog) YAMLSpecLoader {
+	y.alertWindows = catalog
+	return y
+}
+
+// LoadSpec loads an OpenSLO `SLO` kind YAML spec. Every objective of the spec is loaded
+// as a Sloth SLO.
+func (y YAMLSpecLoader) LoadSpec(ctx context.Context, data []byte) (*prometheus.SLOGroup, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("spec is required")
+	}
+
+	s := spec{}
+	err := yaml.Unmarshal(data, &s)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall YAML spec correctly: %w", err)
+	}
+
+	if s.APIVersion != APIVersion {
+		return nil, fmt.Errorf("invalid spec API version, should be %q", APIVersion)
+	}
+
+	if s.Kind != KindSLO {
+		return nil, fmt.Errorf("invalid spec kind, should be %q", KindSLO)
+	}
+
+	if len(s.Spec.Objectives) == 0 {
+		return nil, fmt.Errorf("at least one objective is required")
+	}
+
+	m, err := y.mapSpecToModel(s)
+	if err != nil {
+		return nil, fmt.Errorf("could not map to model: %w", err)
+	}
+
+	return m, nil
+}
+
+func (y YAMLSpecLoader) mapSpecToModel(s spec) (*prometheus.SLOGroup, error) {
+	timeWindow, err := y.getTimeWindow(s.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	alertWindows, err := prometheus.GetAlertWindows(y.alertWindows, "", s.Metadata.Labels, timeWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	sli, err := getSLI(s.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	description := s.Spec.Description
+	if description == "" {
+		description = s.Metadata.DisplayName
+	}
+
+	models := make([]prometheus.SLO, 0, len(s.Spec.Objectives))
+	for i, obj := range s.Spec.Objectives {
+		objective, err := getObjective(obj)
+		if err != nil {
+			return nil, fmt.Errorf("invalid objective %d: %w", i, err)
+		}
+
+		// Only add the objective index when the SLO has multiple objectives, so the
+		// SLO IDs are unique.
+		name := s.Metadata.Name
+		if len(s.Spec.Objectives) > 1 {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+
+		models = append(models, prometheus.SLO{
+			ID:           fmt.Sprintf("%s-%s", s.Spec.Service, name),
+			Name:         name,
+			Description:  description,
+			Service:      s.Spec.Service,
+			SLI:          sli,
+			TimeWindow:   timeWindow,
+			Objective:    objective,
+			Labels:       s.Metadata.Labels,
+			Annotations:  s.Metadata.Annotations,
+			AlertWindows: alertWindows,
+			// OpenSLO alert policies are not supported, Sloth alerts are disabled.
+			PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+			TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+		})
+	}
+
+	return &prometheus.SLOGroup{SLOs: models}, nil
+}
+
+func (y YAMLSpecLoader) getTimeWindow(s sloSpec) (time.Duration, error) {
+	if s.BudgetingMethod != "" && s.BudgetingMethod != budgetingMethodOccurrences {
+		return 0, fmt.Errorf("unsupported %q budgeting method, only %q is supported", s.BudgetingMethod, budgetingMethodOccurrences)
+	}
+
+	if len(s.TimeWindow) == 0 {
+		return y.defaultSLOPeriod, nil
+	}
+	if len(s.TimeWindow) > 1 {
+		return 0, fmt.Errorf("only one time window is supported")
+	}
+
+	tw := s.TimeWindow[0]
+	if !tw.IsRolling {
+		return 0, fmt.Errorf("only rolling time windows are supported")
+	}
+
+	return prometheus.GetSLOPeriod(tw.Duration, y.defaultSLOPeriod)
+}
+
+func getSLI(s sloSpec) (prometheus.SLI, error) {
+	if s.IndicatorRef != "" {
+		return prometheus.SLI{}, fmt.Errorf("indicator references are not supported, use an inline indicator")
+	}
+
+	if s.Indicator == nil {
+		return prometheus.SLI{}, fmt.Errorf("indicator is required")
+	}
+
+	ratio := s.Indicator.Spec.RatioMetric
+	if ratio == nil {
+		return prometheus.SLI{}, fmt.Errorf("only ratio metric indicators are supported")
+	}
+
+	if ratio.Total == nil {
+		return prometheus.SLI{}, fmt.Errorf("ratio metric total is required")
+	}
+	totalQuery, err := getPrometheusQuery(*ratio.Total)
+	if err != nil {
+		return prometheus.SLI{}, fmt.Errorf("invalid ratio metric total: %w", err)
+	}
+
+	switch {
+	case ratio.Bad != nil && ratio.Good == nil:
+		badQuery, err := getPrometheusQuery(*ratio.Bad)
+		if err != nil {
+			return prometheus.SLI{}, fmt.Errorf("invalid ratio metric bad: %w", err)
+		}
+		return prometheus.SLI{Events: &prometheus.SLIEvents{
+			ErrorQuery: badQuery,
+			TotalQuery: totalQuery,
+		}}, nil
+
+	case ratio.Good != nil && ratio.Bad == nil:
+		goodQuery, err := getPrometheusQuery(*ratio.Good)
+		if err != nil {
+			return prometheus.SLI{}, fmt.Errorf("invalid ratio metric good: %w", err)
+		}
+		return prometheus.SLI{Raw: &prometheus.SLIRaw{
+			ErrorRatioQuery: fmt.Sprintf("1 - ((%s) / (%s))", goodQuery, totalQuery),
+		}}, nil
+	}
+
+	return prometheus.SLI{}, fmt.Errorf("ratio metric requires good or bad metric (only one of them)")
+}
+
+func getPrometheusQuery(m metric) (string, error) {
+	if !strings.EqualFold(m.MetricSource.Type, metricSourceTypePrometheus) {
+		return "", fmt.Errorf("unsupported %q metric source type, only %q is supported", m.MetricSource.Type, metricSourceTypePrometheus)
+	}
+
+	query := strings.TrimSpace(m.MetricSource.Spec["query"])
+	if query == "" {
+		return "", fmt.Errorf("metric source query is required")
+	}
+
+	return query, nil
+}
+
+func getObjective(obj objective) (float64, error) {
+	switch {
+	case obj.TargetPercent != 0:
+		return obj.TargetPercent, nil
+	case obj.Target != 0:
+		// Round to avoid float precision errors on the ratio to percent conversion (e.g 0.999).
+		return math.Round(obj.Target*100*1e6) / 1e6, nil
+	}
+
+	return 0, fmt.Errorf("target is required")
+}
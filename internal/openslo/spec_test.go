@@ -0,0 +1,294 @@
+package openslo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/openslo"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestYAMLLoadSpec(t *testing.T) {
+	tests := map[string]struct {
+		specYaml string
+		expModel *prometheus.SLOGroup
+		expErr   bool
+	}{
+		"Empty spec should fail.": {
+			specYaml: ``,
+			expErr:   true,
+		},
+
+		"Wrong spec YAML should fail.": {
+			specYaml: `:`,
+			expErr:   true,
+		},
+
+		"Spec without OpenSLO API version should fail.": {
+			specYaml: `
+version: "prometheus/v1"
+service: test-svc
+slos:
+- name: something
+`,
+			expErr: true,
+		},
+
+		"Spec with a different kind should fail.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: test-svc
+`,
+			expErr: true,
+		},
+
+		"Spec without objectives should fail.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: slo1
+spec:
+  service: test-svc
+  indicator:
+    spec:
+      ratioMetric:
+        bad:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))'}}
+        total:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total[{{.window}}]))'}}
+`,
+			expErr: true,
+		},
+
+		"Spec with an indicator reference should fail.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: slo1
+spec:
+  service: test-svc
+  indicatorRef: my-sli
+  objectives:
+    - target: 0.999
+`,
+			expErr: true,
+		},
+
+		"Spec with a not Prometheus metric source should fail.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: slo1
+spec:
+  service: test-svc
+  indicator:
+    spec:
+      ratioMetric:
+        bad:
+          metricSource: {type: Datadog, spec: {query: 'sum:requests.error{*}'}}
+        total:
+          metricSource: {type: Datadog, spec: {query: 'sum:requests{*}'}}
+  objectives:
+    - target: 0.999
+`,
+			expErr: true,
+		},
+
+		"Spec with good and bad metrics should fail.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: slo1
+spec:
+  service: test-svc
+  indicator:
+    spec:
+      ratioMetric:
+        good:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total{code!~"5.."}[{{.window}}]))'}}
+        bad:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))'}}
+        total:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total[{{.window}}]))'}}
+  objectives:
+    - target: 0.999
+`,
+			expErr: true,
+		},
+
+		"Spec with a calendar time window should fail.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: slo1
+spec:
+  service: test-svc
+  indicator:
+    spec:
+      ratioMetric:
+        bad:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))'}}
+        total:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total[{{.window}}]))'}}
+  timeWindow:
+    - duration: 1M
+      isRolling: false
+  objectives:
+    - target: 0.999
+`,
+			expErr: true,
+		},
+
+		"Spec with a time slices budgeting method should fail.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: slo1
+spec:
+  service: test-svc
+  indicator:
+    spec:
+      ratioMetric:
+        bad:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))'}}
+        total:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total[{{.window}}]))'}}
+  budgetingMethod: Timeslices
+  objectives:
+    - target: 0.999
+`,
+			expErr: true,
+		},
+
+		"Spec with bad and total metrics should load an events SLI SLO.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: requests-availability
+  displayName: Requests availability
+  labels:
+    team: team-a
+    tier: ["1", "2"]
+spec:
+  service: test-svc
+  indicator:
+    metadata:
+      name: http-errors
+    spec:
+      ratioMetric:
+        counter: true
+        bad:
+          metricSource:
+            type: Prometheus
+            spec:
+              query: sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))
+        total:
+          metricSource:
+            type: Prometheus
+            spec:
+              query: sum(rate(http_requests_total[{{.window}}]))
+  timeWindow:
+    - duration: 28d
+      isRolling: true
+  budgetingMethod: Occurrences
+  objectives:
+    - displayName: Availability
+      target: 0.999
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-requests-availability",
+					Name:        "requests-availability",
+					Description: "Requests availability",
+					Service:     "test-svc",
+					TimeWindow:  28 * 24 * time.Hour,
+					Objective:   99.9,
+					SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+						ErrorQuery: `sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))`,
+						TotalQuery: `sum(rate(http_requests_total[{{.window}}]))`,
+					}},
+					Labels:          map[string]string{"team": "team-a", "tier": "1,2"},
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with good and total metrics and multiple objectives should load a raw SLI SLO per objective.": {
+			specYaml: `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: requests-availability
+spec:
+  description: Requests availability.
+  service: test-svc
+  indicator:
+    spec:
+      ratioMetric:
+        good:
+          metricSource: {type: prometheus, spec: {query: 'sum(rate(http_requests_total{code!~"5.."}[{{.window}}]))'}}
+        total:
+          metricSource: {type: prometheus, spec: {query: 'sum(rate(http_requests_total[{{.window}}]))'}}
+  objectives:
+    - target: 0.99
+    - targetPercent: 99.95
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-requests-availability-0",
+					Name:        "requests-availability-0",
+					Description: "Requests availability.",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Objective:   99,
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `1 - ((sum(rate(http_requests_total{code!~"5.."}[{{.window}}]))) / (sum(rate(http_requests_total[{{.window}}]))))`,
+					}},
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+				{
+					ID:          "test-svc-requests-availability-1",
+					Name:        "requests-availability-1",
+					Description: "Requests availability.",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Objective:   99.95,
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `1 - ((sum(rate(http_requests_total{code!~"5.."}[{{.window}}]))) / (sum(rate(http_requests_total[{{.window}}]))))`,
+					}},
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			loader := openslo.NewYAMLSpecLoader()
+			gotModel, err := loader.LoadSpec(context.TODO(), []byte(test.specYaml))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expModel, gotModel)
+			}
+		})
+	}
+}
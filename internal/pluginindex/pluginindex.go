@@ -0,0 +1,127 @@
+package pluginindex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PluginFileName is the file name of the installed SLI plugins, the one discovered
+// by the SLI plugins loader.
+const PluginFileName = "plugin.go"
+
+// Index is an SLI plugins index, it lists the SLI plugins that can be installed.
+//
+// Example YAML index:
+//
+//	plugins:
+//	  - name: sloth-common/http/availability
+//	    version: v1.0.0
+//	    source: https://plugins.example.com/http/availability/v1.0.0/plugin.go
+//	    sha256: 4d9a8d8e1b8a7b4c...
+type Index struct {
+	Plugins []Plugin `yaml:"plugins"`
+}
+
+// Plugin is an SLI plugin version of the index.
+type Plugin struct {
+	// Name is the plugin name, normally the plugin ID.
+	Name string `yaml:"name"`
+	// Version is the plugin version (e.g `v1.0.0`).
+	Version string `yaml:"version"`
+	// Source is the plugin file location, an HTTP(S) URL or a local path.
+	Source string `yaml:"source"`
+	// SHA256 is the plugin file hex encoded SHA256 checksum.
+	SHA256 string `yaml:"sha256"`
+}
+
+var (
+	nameRegexp    = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_./]*[A-Za-z0-9]$`)
+	versionRegexp = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_.+]*$`)
+	sha256Regexp  = regexp.MustCompile(`^[a-f0-9]{64}$`)
+)
+
+// NewIndexFromYAML loads and validates an SLI plugins index from YAML data.
+func NewIndexFromYAML(data []byte) (*Index, error) {
+	index := &Index{}
+	err := yaml.UnmarshalStrict(data, index)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal YAML plugins index: %w", err)
+	}
+
+	versions := map[string]struct{}{}
+	for i, p := range index.Plugins {
+		switch {
+		case !nameRegexp.MatchString(p.Name) || strings.Contains(p.Name, ".."):
+			return nil, fmt.Errorf("plugin %d has an invalid %q name", i, p.Name)
+		case !versionRegexp.MatchString(p.Version):
+			return nil, fmt.Errorf("%q plugin has an invalid %q version", p.Name, p.Version)
+		case p.Source == "":
+			return nil, fmt.Errorf("%q plugin %s version source is required", p.Name, p.Version)
+		case !sha256Regexp.MatchString(p.SHA256):
+			return nil, fmt.Errorf("%q plugin %s version has an invalid SHA256 checksum", p.Name, p.Version)
+		}
+
+		id := p.Name + "@" + p.Version
+		if _, ok := versions[id]; ok {
+			return nil, fmt.Errorf("%q plugin %s version is repeated", p.Name, p.Version)
+		}
+		versions[id] = struct{}{}
+	}
+
+	return index, nil
+}
+
+// Get returns the plugin version of the index, if the version is empty it will return
+// the last version of the plugin listed on the index.
+func (i Index) Get(name, version string) (*Plugin, error) {
+	var plugin *Plugin
+	for j, p := range i.Plugins {
+		if p.Name != name {
+			continue
+		}
+		if version == "" || p.Version == version {
+			plugin = &i.Plugins[j]
+		}
+	}
+
+	if plugin == nil {
+		if version == "" {
+			return nil, fmt.Errorf("%q plugin is missing on the index", name)
+		}
+		return nil, fmt.Errorf("%q plugin %s version is missing on the index", name, version)
+	}
+
+	return plugin, nil
+}
+
+// Verify verifies the plugin file data matches the plugin checksum.
+func (p Plugin) Verify(data []byte) error {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if checksum != p.SHA256 {
+		return fmt.Errorf("%q plugin %s version checksum %s doesn't match the index checksum %s", p.Name, p.Version, checksum, p.SHA256)
+	}
+
+	return nil
+}
+
+// InstallPath returns the path of the installed plugin file on a plugins directory, only
+// one version of a plugin can be installed so the SLI plugin IDs don't collide.
+func (p Plugin) InstallPath(dir string) string {
+	return filepath.Join(dir, filepath.FromSlash(p.Name), PluginFileName)
+}
+
+// ParseRef parses a plugin reference in `name` or `name@version` form.
+func ParseRef(ref string) (name, version string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+
+	return ref, ""
+}
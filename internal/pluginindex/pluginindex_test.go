@@ -0,0 +1,189 @@
+package pluginindex_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/pluginindex"
+)
+
+const testChecksum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestNewIndexFromYAML(t *testing.T) {
+	tests := map[string]struct {
+		index    string
+		expIndex *pluginindex.Index
+		expErr   bool
+	}{
+		"Invalid YAML should fail.": {
+			index:  `plugins: {`,
+			expErr: true,
+		},
+
+		"Unknown fields should fail.": {
+			index: `
+plugins:
+  - name: test/plugin
+    version: v1.0.0
+    source: https://plugins.test/plugin.go
+    sha256: ` + testChecksum + `
+    url: https://plugins.test/plugin.go
+`,
+			expErr: true,
+		},
+
+		"Plugins with invalid names should fail.": {
+			index: `
+plugins:
+  - name: ../plugin
+    version: v1.0.0
+    source: https://plugins.test/plugin.go
+    sha256: ` + testChecksum + `
+`,
+			expErr: true,
+		},
+
+		"Plugins without version should fail.": {
+			index: `
+plugins:
+  - name: test/plugin
+    source: https://plugins.test/plugin.go
+    sha256: ` + testChecksum + `
+`,
+			expErr: true,
+		},
+
+		"Plugins without source should fail.": {
+			index: `
+plugins:
+  - name: test/plugin
+    version: v1.0.0
+    sha256: ` + testChecksum + `
+`,
+			expErr: true,
+		},
+
+		"Plugins with invalid checksums should fail.": {
+			index: `
+plugins:
+  - name: test/plugin
+    version: v1.0.0
+    source: https://plugins.test/plugin.go
+    sha256: 1234
+`,
+			expErr: true,
+		},
+
+		"Repeated plugin versions should fail.": {
+			index: `
+plugins:
+  - name: test/plugin
+    version: v1.0.0
+    source: https://plugins.test/plugin.go
+    sha256: ` + testChecksum + `
+  - name: test/plugin
+    version: v1.0.0
+    source: https://plugins.test/plugin2.go
+    sha256: ` + testChecksum + `
+`,
+			expErr: true,
+		},
+
+		"A valid index should load the plugins.": {
+			index: `
+plugins:
+  - name: test/plugin
+    version: v1.0.0
+    source: https://plugins.test/v1.0.0/plugin.go
+    sha256: ` + testChecksum + `
+  - name: test/plugin
+    version: v1.1.0
+    source: https://plugins.test/v1.1.0/plugin.go
+    sha256: ` + testChecksum + `
+`,
+			expIndex: &pluginindex.Index{Plugins: []pluginindex.Plugin{
+				{Name: "test/plugin", Version: "v1.0.0", Source: "https://plugins.test/v1.0.0/plugin.go", SHA256: testChecksum},
+				{Name: "test/plugin", Version: "v1.1.0", Source: "https://plugins.test/v1.1.0/plugin.go", SHA256: testChecksum},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotIndex, err := pluginindex.NewIndexFromYAML([]byte(test.index))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expIndex, gotIndex)
+			}
+		})
+	}
+}
+
+func TestIndexGet(t *testing.T) {
+	index := pluginindex.Index{Plugins: []pluginindex.Plugin{
+		{Name: "test/plugin", Version: "v1.0.0", Source: "v1.0.0/plugin.go"},
+		{Name: "test/plugin", Version: "v1.1.0", Source: "v1.1.0/plugin.go"},
+		{Name: "test/plugin2", Version: "v0.1.0", Source: "plugin2/plugin.go"},
+	}}
+
+	tests := map[string]struct {
+		ref       string
+		expPlugin *pluginindex.Plugin
+		expErr    bool
+	}{
+		"A missing plugin should fail.": {
+			ref:    "test/plugin3",
+			expErr: true,
+		},
+
+		"A missing plugin version should fail.": {
+			ref:    "test/plugin@v2.0.0",
+			expErr: true,
+		},
+
+		"A plugin without version should return the last plugin version.": {
+			ref:       "test/plugin",
+			expPlugin: &pluginindex.Plugin{Name: "test/plugin", Version: "v1.1.0", Source: "v1.1.0/plugin.go"},
+		},
+
+		"A plugin with version should return the plugin version.": {
+			ref:       "test/plugin@v1.0.0",
+			expPlugin: &pluginindex.Plugin{Name: "test/plugin", Version: "v1.0.0", Source: "v1.0.0/plugin.go"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotPlugin, err := index.Get(pluginindex.ParseRef(test.ref))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expPlugin, gotPlugin)
+			}
+		})
+	}
+}
+
+func TestPluginVerify(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	data := []byte("package testplugin")
+	sum := sha256.Sum256(data)
+	p := pluginindex.Plugin{Name: "test/plugin", Version: "v1.0.0", SHA256: hex.EncodeToString(sum[:])}
+
+	require.NoError(p.Verify(data))
+	assert.Error(p.Verify([]byte("package otherplugin")))
+	assert.Equal("plugins/test/plugin/plugin.go", p.InstallPath("plugins"))
+}
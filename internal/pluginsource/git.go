@@ -0,0 +1,55 @@
+package pluginsource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// fetchGit fetches the git source reference (by default the remote HEAD) without history.
+func (f Fetcher) fetchGit(ctx context.Context, src Source, dst string) error {
+	ref := src.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	cmds := [][]string{
+		{"init", "-q"},
+		{"fetch", "-q", "--depth", "1", "--", src.Location, ref},
+		{"-c", "advice.detachedHead=false", "checkout", "-q", "FETCH_HEAD"},
+	}
+	for _, args := range cmds {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, f.gitCommand, args...)
+		cmd.Dir = dst
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		if err != nil {
+			return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	err := os.RemoveAll(filepath.Join(dst, ".git"))
+	if err != nil {
+		return err
+	}
+
+	// The plugins are discovered following the symlinks, but the checksum can't pin the
+	// symlinks targets (e.g files outside the source), so they are not supported.
+	return filepath.Walk(dst, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			rel, _ := filepath.Rel(dst, path)
+			return fmt.Errorf("%q is a symlink, git sources with symlinks are not supported", filepath.ToSlash(rel))
+		}
+		return nil
+	})
+}
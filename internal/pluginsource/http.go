@@ -0,0 +1,110 @@
+package pluginsource
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fetchHTTP fetches and extracts the HTTP source tarball.
+func (f Fetcher) fetchHTTP(ctx context.Context, src Source, dst string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.Location, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return extractTar(resp.Body, dst)
+}
+
+// extractTar extracts the regular files and directories of a tarball, gzip compressed
+// or not, on a directory. Entries outside the directory are not allowed.
+func extractTar(r io.Reader, dst string) error {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("invalid gzip: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	} else {
+		r = br
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tarball: %w", err)
+		}
+
+		path, err := safeJoin(dst, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+			if err != nil {
+				return fmt.Errorf("could not create directory: %w", err)
+			}
+		case tar.TypeReg:
+			err = writeFile(path, tr, os.FileMode(hdr.Mode).Perm()|0600)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// safeJoin joins an archive entry name to a directory, the entry can't be outside the directory.
+func safeJoin(dir, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid %q entry outside the plugins directory", name)
+	}
+
+	return filepath.Join(dir, clean), nil
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("could not create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("could not create file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return fmt.Errorf("could not write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+package pluginsource
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	ociManifestMediaType          = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType       = "application/vnd.docker.distribution.manifest.v2+json"
+	ociTitleAnnotation            = "org.opencontainers.image.title"
+	ociDefaultTag                 = "latest"
+	ociMaxManifestSize      int64 = 4 << 20
+)
+
+var ociDigestRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// ociReference is an OCI artifact reference.
+type ociReference struct {
+	registry   string
+	repository string
+	// reference is the tag or the digest.
+	reference string
+}
+
+func parseOCIReference(location string) (*ociReference, error) {
+	i := strings.Index(location, "/")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid %q OCI reference, registry is required", location)
+	}
+	ref := &ociReference{registry: location[:i], repository: location[i+1:], reference: ociDefaultTag}
+
+	if j := strings.Index(ref.repository, "@"); j >= 0 {
+		ref.repository, ref.reference = ref.repository[:j], ref.repository[j+1:]
+		if !ociDigestRegexp.MatchString(ref.reference) {
+			return nil, fmt.Errorf("invalid %q OCI reference digest", location)
+		}
+	} else if j := strings.LastIndex(ref.repository, ":"); j >= 0 {
+		ref.repository, ref.reference = ref.repository[:j], ref.repository[j+1:]
+	}
+
+	if ref.repository == "" || ref.reference == "" {
+		return nil, fmt.Errorf("invalid %q OCI reference", location)
+	}
+
+	return ref, nil
+}
+
+// fetchOCI fetches the OCI artifact layers using the registry HTTP API with anonymous
+// access. Tarball layers are extracted and the rest of the layers are written as files
+// using their title annotation (e.g ORAS pushed files).
+func (f Fetcher) fetchOCI(ctx context.Context, src Source, dst string) error {
+	ref, err := parseOCIReference(src.Location)
+	if err != nil {
+		return err
+	}
+	client := &ociClient{httpClient: f.httpClient, ref: *ref}
+
+	manifestData, err := client.get(ctx, "manifests/"+ref.reference, strings.Join([]string{ociManifestMediaType, dockerManifestMediaType}, ", "))
+	if err != nil {
+		return fmt.Errorf("could not get manifest: %w", err)
+	}
+	if ociDigestRegexp.MatchString(ref.reference) {
+		err := verifyDigest(manifestData, ref.reference)
+		if err != nil {
+			return fmt.Errorf("invalid manifest: %w", err)
+		}
+	}
+
+	manifest := ociManifest{}
+	err = json.Unmarshal(manifestData, &manifest)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal manifest: %w", err)
+	}
+	if manifest.MediaType != "" && manifest.MediaType != ociManifestMediaType && manifest.MediaType != dockerManifestMediaType {
+		return fmt.Errorf("unsupported %q manifest media type", manifest.MediaType)
+	}
+	if len(manifest.Layers) == 0 {
+		return fmt.Errorf("artifact without layers")
+	}
+
+	for _, layer := range manifest.Layers {
+		if !ociDigestRegexp.MatchString(layer.Digest) {
+			return fmt.Errorf("unsupported %q layer digest", layer.Digest)
+		}
+
+		data, err := client.get(ctx, "blobs/"+layer.Digest, "")
+		if err != nil {
+			return fmt.Errorf("could not get %q layer: %w", layer.Digest, err)
+		}
+		err = verifyDigest(data, layer.Digest)
+		if err != nil {
+			return fmt.Errorf("invalid %q layer: %w", layer.Digest, err)
+		}
+
+		if strings.Contains(layer.MediaType, ".tar") {
+			err := extractTar(bytes.NewReader(data), dst)
+			if err != nil {
+				return fmt.Errorf("could not extract %q layer: %w", layer.Digest, err)
+			}
+			continue
+		}
+
+		title := layer.Annotations[ociTitleAnnotation]
+		if title == "" {
+			return fmt.Errorf("%q layer is not a tarball and doesn't have a title annotation", layer.Digest)
+		}
+		path, err := safeJoin(dst, title)
+		if err != nil {
+			return err
+		}
+		err = writeFile(path, bytes.NewReader(data), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func verifyDigest(data []byte, digest string) error {
+	sum := sha256.Sum256(data)
+	got := "sha256:" + hex.EncodeToString(sum[:])
+	if got != digest {
+		return fmt.Errorf("digest %s doesn't match %s", got, digest)
+	}
+
+	return nil
+}
+
+// ociClient is a minimal OCI registry HTTP API client that supports anonymous bearer tokens.
+type ociClient struct {
+	httpClient *http.Client
+	ref        ociReference
+	token      string
+}
+
+func (c *ociClient) get(ctx context.Context, path, accept string) ([]byte, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/%s", c.ref.registry, c.ref.repository, path)
+
+	resp, err := c.do(ctx, u, accept)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Get an anonymous token and retry.
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		err := c.authenticate(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return nil, fmt.Errorf("could not authenticate: %w", err)
+		}
+
+		resp, err = c.do(ctx, u, accept)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	if strings.HasPrefix(path, "manifests/") {
+		return io.ReadAll(io.LimitReader(resp.Body, ociMaxManifestSize))
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (c *ociClient) do(ctx context.Context, u, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+var authParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate gets an anonymous token from the `WWW-Authenticate` bearer challenge realm.
+func (c *ociClient) authenticate(ctx context.Context, challenge string) error {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return fmt.Errorf("unsupported %q authentication challenge", challenge)
+	}
+
+	params := map[string]string{}
+	for _, m := range authParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	if params["realm"] == "" {
+		return fmt.Errorf("authentication challenge without realm")
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return fmt.Errorf("invalid authentication realm: %w", err)
+	}
+	q := u.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", c.ref.repository)
+	}
+	q.Set("scope", scope)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected token response: %s", resp.Status)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return fmt.Errorf("could not decode token: %w", err)
+	}
+
+	c.token = token.Token
+	if c.token == "" {
+		c.token = token.AccessToken
+	}
+	if c.token == "" {
+		return fmt.Errorf("empty token")
+	}
+
+	return nil
+}
@@ -0,0 +1,363 @@
+package pluginsource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slok/sloth/internal/log"
+)
+
+// Source kinds.
+const (
+	KindGit   = "git"
+	KindHTTP  = "http"
+	KindOCI   = "oci"
+	gitPrefix = "git::"
+	ociPrefix = "oci://"
+)
+
+// checksumPrefix is the prefix of the pinned checksums, only SHA256 is supported.
+const checksumPrefix = "sha256:"
+
+var checksumRegexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// Source is a remote SLI plugins source.
+//
+// Sources are referenced with these location formats:
+//
+//	git::<repository URL>[//<subdir>][?ref=<ref>][&checksum=sha256:<hex>]
+//	https://<tarball URL>[//<subdir>][?checksum=sha256:<hex>]
+//	oci://<registry>/<repository>(:<tag>|@<digest>)[//<subdir>][?checksum=sha256:<hex>]
+//
+// The checksum pins the SHA256 of the fetched plugins directory, see DirChecksum.
+type Source struct {
+	// Kind is the source kind (git, http or oci).
+	Kind string
+	// Location is the source location without the subdir and the Sloth options.
+	Location string
+	// Ref is the git reference (branch, tag or commit), only for git sources.
+	Ref string
+	// Subdir is the plugins directory inside the fetched source.
+	Subdir string
+	// Checksum is the pinned hex encoded plugins directory SHA256 checksum.
+	Checksum string
+}
+
+// IsRemote returns true if the SLI plugins location is a remote source.
+func IsRemote(location string) bool {
+	return strings.HasPrefix(location, gitPrefix) ||
+		strings.HasPrefix(location, ociPrefix) ||
+		strings.HasPrefix(location, "http://") ||
+		strings.HasPrefix(location, "https://")
+}
+
+// ParseSource parses a remote SLI plugins source location.
+func ParseSource(location string) (*Source, error) {
+	src := &Source{}
+	body := location
+	switch {
+	case strings.HasPrefix(body, gitPrefix):
+		src.Kind = KindGit
+		body = strings.TrimPrefix(body, gitPrefix)
+	case strings.HasPrefix(body, ociPrefix):
+		src.Kind = KindOCI
+		body = strings.TrimPrefix(body, ociPrefix)
+	case strings.HasPrefix(body, "http://"), strings.HasPrefix(body, "https://"):
+		src.Kind = KindHTTP
+	default:
+		return nil, fmt.Errorf("unknown %q plugins source kind", location)
+	}
+
+	// Get the Sloth options from the query, HTTP sources keep the rest of the query.
+	query := ""
+	if i := strings.Index(body, "?"); i >= 0 {
+		body, query = body[:i], body[i+1:]
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q plugins source query: %w", location, err)
+	}
+
+	if checksum := values.Get("checksum"); checksum != "" {
+		if !strings.HasPrefix(checksum, checksumPrefix) || !checksumRegexp.MatchString(strings.TrimPrefix(checksum, checksumPrefix)) {
+			return nil, fmt.Errorf("invalid %q plugins source checksum, it must be in sha256:<hex> form", location)
+		}
+		src.Checksum = strings.TrimPrefix(checksum, checksumPrefix)
+	}
+	values.Del("checksum")
+
+	if src.Kind == KindGit {
+		src.Ref = values.Get("ref")
+		values.Del("ref")
+	}
+
+	if len(values) > 0 && src.Kind != KindHTTP {
+		return nil, fmt.Errorf("unknown %q plugins source options", location)
+	}
+
+	// Split the subdir, the first `//` after the scheme.
+	start := 0
+	if i := strings.Index(body, "://"); i >= 0 {
+		start = i + 3
+	}
+	if i := strings.Index(body[start:], "//"); i >= 0 {
+		src.Subdir = body[start+i+2:]
+		body = body[:start+i]
+	}
+	if src.Subdir != "" {
+		subdir := filepath.Clean(filepath.FromSlash(src.Subdir))
+		if filepath.IsAbs(subdir) || subdir == ".." || strings.HasPrefix(subdir, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("invalid %q plugins source subdir", location)
+		}
+		src.Subdir = subdir
+	}
+
+	if len(values) > 0 {
+		body += "?" + values.Encode()
+	}
+
+	if body == "" {
+		return nil, fmt.Errorf("invalid %q plugins source, location is required", location)
+	}
+
+	// The git source location and reference are git arguments, they can't be options.
+	if src.Kind == KindGit && (strings.HasPrefix(body, "-") || strings.HasPrefix(src.Ref, "-")) {
+		return nil, fmt.Errorf("invalid %q plugins source, the git location and reference can't start with '-'", location)
+	}
+	src.Location = body
+
+	return src, nil
+}
+
+// cacheKey returns the key of the source on the cache, the checksum is not part of it
+// so a pinned source reuses the cached unpinned source when it matches.
+func (s Source) cacheKey() string {
+	sum := sha256.Sum256([]byte(s.Kind + "\n" + s.Location + "\n" + s.Ref + "\n" + s.Subdir))
+	return hex.EncodeToString(sum[:16])
+}
+
+// DirChecksum returns the hex encoded SHA256 checksum of a plugins directory. It's the
+// SHA256 of the sorted `<file SHA256>  <file slash path>` lines of all the regular files
+// of the directory, so it doesn't depend on how the directory was fetched. Directories with
+// symlinks fail, their targets can't be pinned.
+func DirChecksum(dir string) (string, error) {
+	lines := []string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%q is a symlink, symlinks are not supported", path)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		if err != nil {
+			return err
+		}
+
+		lines = append(lines, fmt.Sprintf("%x  %s\n", h.Sum(nil), filepath.ToSlash(rel)))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not walk %q directory: %w", dir, err)
+	}
+
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, l := range lines {
+		_, _ = io.WriteString(h, l)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FetcherConfig is the configuration of the remote SLI plugin sources fetcher.
+type FetcherConfig struct {
+	// CacheDir is the directory where the fetched sources are cached.
+	CacheDir string
+	// CacheTTL is the time the unpinned sources are cached before fetching them again, pinned
+	// sources are reused while they match the checksum. By default 24h.
+	CacheTTL   time.Duration
+	HTTPClient *http.Client
+	// GitCommand is the git binary used to fetch the git sources. By default `git`.
+	GitCommand string
+	Logger     log.Logger
+}
+
+func (c *FetcherConfig) defaults() error {
+	if c.CacheDir == "" {
+		return fmt.Errorf("cache directory is required")
+	}
+
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("cache TTL can't be negative")
+	}
+
+	if c.CacheTTL == 0 {
+		c.CacheTTL = 24 * time.Hour
+	}
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+
+	if c.GitCommand == "" {
+		c.GitCommand = "git"
+	}
+
+	if c.Logger == nil {
+		c.Logger = log.Noop
+	}
+	c.Logger = c.Logger.WithValues(log.Kv{"svc": "pluginsource.Fetcher"})
+
+	return nil
+}
+
+// Fetcher knows how to fetch remote SLI plugin sources into a local cache directory.
+type Fetcher struct {
+	cacheDir   string
+	cacheTTL   time.Duration
+	httpClient *http.Client
+	gitCommand string
+	logger     log.Logger
+}
+
+// NewFetcher returns a new remote SLI plugin sources fetcher.
+func NewFetcher(config FetcherConfig) (*Fetcher, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &Fetcher{
+		cacheDir:   config.CacheDir,
+		cacheTTL:   config.CacheTTL,
+		httpClient: config.HTTPClient,
+		gitCommand: config.GitCommand,
+		logger:     config.Logger,
+	}, nil
+}
+
+// Fetch fetches a remote SLI plugins source location and returns the local plugins directory.
+func (f Fetcher) Fetch(ctx context.Context, location string) (string, error) {
+	src, err := ParseSource(location)
+	if err != nil {
+		return "", err
+	}
+	logger := f.logger.WithValues(log.Kv{"source": src.Location, "kind": src.Kind})
+
+	dir := filepath.Join(f.cacheDir, src.cacheKey())
+	info, statErr := os.Stat(dir)
+	cached := statErr == nil && info.IsDir()
+
+	// Reuse the cached source if it's pinned or fresh.
+	if cached && (src.Checksum != "" || time.Since(info.ModTime()) < f.cacheTTL) {
+		err := verify(dir, src.Checksum)
+		if err == nil {
+			logger.Debugf("Using cached SLI plugins source")
+			return dir, nil
+		}
+		logger.Warningf("Cached SLI plugins source is invalid, fetching again: %s", err)
+	}
+
+	err = os.MkdirAll(f.cacheDir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("could not create cache directory: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(f.cacheDir, ".fetch-")
+	if err != nil {
+		return "", fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	switch src.Kind {
+	case KindGit:
+		err = f.fetchGit(ctx, *src, tmpDir)
+	case KindHTTP:
+		err = f.fetchHTTP(ctx, *src, tmpDir)
+	case KindOCI:
+		err = f.fetchOCI(ctx, *src, tmpDir)
+	}
+	if err != nil {
+		// Fallback to the stale unpinned cached source (e.g offline).
+		if cached && src.Checksum == "" {
+			logger.Warningf("Could not fetch SLI plugins source, using the stale cached source: %s", err)
+			return dir, nil
+		}
+		return "", fmt.Errorf("could not fetch %q plugins source: %w", location, err)
+	}
+
+	pluginsDir := filepath.Join(tmpDir, src.Subdir)
+	info, err = os.Stat(pluginsDir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%q plugins source %q subdir is missing", location, src.Subdir)
+	}
+
+	err = verify(pluginsDir, src.Checksum)
+	if err != nil {
+		return "", fmt.Errorf("could not verify %q plugins source: %w", location, err)
+	}
+
+	// Replace the cached source.
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return "", fmt.Errorf("could not remove cached source: %w", err)
+	}
+	err = os.Rename(pluginsDir, dir)
+	if err != nil {
+		return "", fmt.Errorf("could not cache source: %w", err)
+	}
+	now := time.Now()
+	err = os.Chtimes(dir, now, now)
+	if err != nil {
+		return "", fmt.Errorf("could not cache source: %w", err)
+	}
+
+	logger.Infof("SLI plugins source fetched")
+
+	return dir, nil
+}
+
+// verify verifies the plugins directory matches the checksum, an empty checksum is not verified.
+func verify(dir, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	got, err := DirChecksum(dir)
+	if err != nil {
+		return err
+	}
+
+	if got != checksum {
+		return fmt.Errorf("checksum sha256:%s doesn't match the pinned checksum sha256:%s", got, checksum)
+	}
+
+	return nil
+}
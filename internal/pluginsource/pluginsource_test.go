@@ -0,0 +1,388 @@
+package pluginsource_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/pluginsource"
+)
+
+const testChecksum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestParseSource(t *testing.T) {
+	tests := map[string]struct {
+		location  string
+		expSource *pluginsource.Source
+		expErr    bool
+	}{
+		"Local paths should fail.": {
+			location: "./plugins",
+			expErr:   true,
+		},
+
+		"Invalid checksums should fail.": {
+			location: "https://plugins.test/plugins.tar.gz?checksum=md5:1234",
+			expErr:   true,
+		},
+
+		"Subdirs outside the source should fail.": {
+			location: "git::https://git.test/plugins.git//../x",
+			expErr:   true,
+		},
+
+		"Unknown git source options should fail.": {
+			location: "git::https://git.test/plugins.git?branch=main",
+			expErr:   true,
+		},
+
+		"Git sources with an option as location should fail.": {
+			location: "git::--upload-pack=touch /tmp/pwned",
+			expErr:   true,
+		},
+
+		"Git sources with an option as reference should fail.": {
+			location: "git::https://git.test/plugins.git?ref=--upload-pack=touch",
+			expErr:   true,
+		},
+
+		"Git sources should be parsed.": {
+			location: "git::https://git.test/org/plugins.git//sli/common?ref=v1.0.0&checksum=sha256:" + testChecksum,
+			expSource: &pluginsource.Source{
+				Kind:     pluginsource.KindGit,
+				Location: "https://git.test/org/plugins.git",
+				Ref:      "v1.0.0",
+				Subdir:   filepath.Join("sli", "common"),
+				Checksum: testChecksum,
+			},
+		},
+
+		"Git SSH sources should be parsed.": {
+			location: "git::git@git.test:org/plugins.git//sli",
+			expSource: &pluginsource.Source{
+				Kind:     pluginsource.KindGit,
+				Location: "git@git.test:org/plugins.git",
+				Subdir:   "sli",
+			},
+		},
+
+		"HTTP sources should keep the rest of the query.": {
+			location: "https://plugins.test/plugins.tar.gz?token=abc&checksum=sha256:" + testChecksum,
+			expSource: &pluginsource.Source{
+				Kind:     pluginsource.KindHTTP,
+				Location: "https://plugins.test/plugins.tar.gz?token=abc",
+				Checksum: testChecksum,
+			},
+		},
+
+		"OCI sources should be parsed.": {
+			location: "oci://registry.test/org/plugins:v1.0.0//sli",
+			expSource: &pluginsource.Source{
+				Kind:     pluginsource.KindOCI,
+				Location: "registry.test/org/plugins:v1.0.0",
+				Subdir:   "sli",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotSource, err := pluginsource.ParseSource(test.location)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expSource, gotSource)
+			}
+		})
+	}
+}
+
+var testPluginFiles = map[string]string{
+	"sli/a/plugin.go": "package a",
+	"sli/b/plugin.go": "package b",
+}
+
+func testPluginsChecksum(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range testPluginFiles {
+		path := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, "sli/")))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	checksum, err := pluginsource.DirChecksum(dir)
+	require.NoError(t, err)
+	return checksum
+}
+
+func testTarball(t *testing.T) []byte {
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gw)
+	for name, content := range testPluginFiles {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return b.Bytes()
+}
+
+func assertPlugins(t *testing.T, dir string) {
+	for name, content := range testPluginFiles {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, "sli/"))))
+		if assert.NoError(t, err) {
+			assert.Equal(t, content, string(got))
+		}
+	}
+}
+
+func TestFetcherFetchHTTP(t *testing.T) {
+	tarball := testTarball(t)
+	checksum := testPluginsChecksum(t)
+
+	tests := map[string]struct {
+		path     string
+		checksum string
+		expErr   bool
+	}{
+		"A missing tarball should fail.": {
+			path:   "/missing.tar.gz",
+			expErr: true,
+		},
+
+		"A tarball with a missing subdir should fail.": {
+			path:   "/plugins.tar.gz//missing",
+			expErr: true,
+		},
+
+		"A tarball that doesn't match the pinned checksum should fail.": {
+			path:     "/plugins.tar.gz//sli",
+			checksum: testChecksum,
+			expErr:   true,
+		},
+
+		"A tarball should be fetched.": {
+			path: "/plugins.tar.gz//sli",
+		},
+
+		"A tarball that matches the pinned checksum should be fetched.": {
+			path:     "/plugins.tar.gz//sli",
+			checksum: checksum,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.URL.Path != "/plugins.tar.gz" {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write(tarball)
+			}))
+			defer srv.Close()
+
+			fetcher, err := pluginsource.NewFetcher(pluginsource.FetcherConfig{CacheDir: t.TempDir(), HTTPClient: srv.Client()})
+			require.NoError(err)
+
+			location := srv.URL + test.path
+			if test.checksum != "" {
+				location += "?checksum=sha256:" + test.checksum
+			}
+			dir, err := fetcher.Fetch(context.TODO(), location)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(err)
+			assertPlugins(t, dir)
+
+			// The second fetch should use the cache.
+			dir2, err := fetcher.Fetch(context.TODO(), location)
+			require.NoError(err)
+			assert.Equal(t, dir, dir2)
+			assert.Equal(t, 1, requests)
+		})
+	}
+}
+
+func TestFetcherFetchOCI(t *testing.T) {
+	tarball := testTarball(t)
+	digest := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	fileData := []byte("package c")
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers": []map[string]interface{}{
+			{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": digest(tarball)},
+			{"mediaType": "application/vnd.sloth.plugin", "digest": digest(fileData), "annotations": map[string]string{"org.opencontainers.image.title": "sli/c/plugin.go"}},
+		},
+	})
+	require.NoError(t, err)
+
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "t0k3n"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/org/plugins/manifests/v1.0.0", "/v2/org/plugins/manifests/" + digest(manifest):
+			_, _ = w.Write(manifest)
+		case "/v2/org/plugins/blobs/" + digest(tarball):
+			_, _ = w.Write(tarball)
+		case "/v2/org/plugins/blobs/" + digest(fileData):
+			_, _ = w.Write(fileData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	registry := strings.TrimPrefix(srv.URL, "https://")
+
+	tests := map[string]struct {
+		reference string
+		expErr    bool
+	}{
+		"A missing artifact should fail.": {
+			reference: "org/plugins:v2.0.0",
+			expErr:    true,
+		},
+
+		"An artifact with a digest that doesn't match should fail.": {
+			reference: "org/plugins@sha256:" + testChecksum,
+			expErr:    true,
+		},
+
+		"An artifact should be fetched by tag.": {
+			reference: "org/plugins:v1.0.0",
+		},
+
+		"An artifact should be fetched by digest.": {
+			reference: "org/plugins@" + digest(manifest),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			fetcher, err := pluginsource.NewFetcher(pluginsource.FetcherConfig{CacheDir: t.TempDir(), HTTPClient: srv.Client()})
+			require.NoError(err)
+
+			dir, err := fetcher.Fetch(context.TODO(), "oci://"+registry+"/"+test.reference+"//sli")
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(err)
+			assertPlugins(t, dir)
+			got, err := os.ReadFile(filepath.Join(dir, "c", "plugin.go"))
+			require.NoError(err)
+			assert.Equal(t, string(fileData), string(got))
+		})
+	}
+}
+
+func TestFetcherFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required")
+	}
+
+	// Create the git repository.
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@test", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	for name, content := range testPluginFiles {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1.0.0")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "sli", "a", "plugin.go"), []byte("package changed"), 0644))
+	git("commit", "-q", "-a", "-m", "v2")
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "plugin.go"), []byte("package outside"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(repo, "sli", "c")))
+	git("add", "-A")
+	git("commit", "-q", "-m", "v3")
+	git("tag", "v3.0.0")
+
+	tests := map[string]struct {
+		location string
+		expErr   bool
+	}{
+		"A missing git reference should fail.": {
+			location: "git::file://" + repo + "//sli?ref=v9.9.9",
+			expErr:   true,
+		},
+
+		"A git reference should be fetched.": {
+			location: "git::file://" + repo + "//sli?ref=v1.0.0&checksum=sha256:" + testPluginsChecksum(t),
+		},
+
+		"A git reference with a symlinked plugin should fail.": {
+			location: "git::file://" + repo + "//sli?ref=v3.0.0",
+			expErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			fetcher, err := pluginsource.NewFetcher(pluginsource.FetcherConfig{CacheDir: t.TempDir()})
+			require.NoError(err)
+
+			dir, err := fetcher.Fetch(context.TODO(), test.location)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(err)
+			assertPlugins(t, dir)
+			_, err = os.Stat(filepath.Join(dir, ".git"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
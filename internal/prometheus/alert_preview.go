@@ -0,0 +1,196 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+
+	"github.com/slok/sloth/internal/alert"
+)
+
+// AlertPreview is how a firing SLO alert would be received by Alertmanager.
+type AlertPreview struct {
+	Name        string
+	Severity    alert.Severity
+	Value       float64
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// PreviewSLOAlert previews an SLO alert firing with an error budget burn rate (e.g 14.4),
+// so the alert content can be reviewed before it pages. The alert labels and annotations
+// Prometheus templates are rendered like Prometheus does, with the `$labels` and `$value`
+// variables.
+func PreviewSLOAlert(ctx context.Context, slo SLO, severity alert.Severity, burnRate float64) (*AlertPreview, error) {
+	alerts, err := alert.AlertGenerator.GenerateMWMBAlerts(ctx, alert.SLO{
+		ID:         slo.ID,
+		TimeWindow: slo.TimeWindow,
+		Objective:  slo.Objective,
+		Windows:    slo.AlertWindows,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not generate SLO alerts: %w", err)
+	}
+
+	var sloAlert AlertMeta
+	var quick, slow alert.MWMBAlert
+	switch severity {
+	case alert.PageAlertSeverity:
+		sloAlert, quick, slow = slo.PageAlertMeta, alerts.PageQuick, alerts.PageSlow
+	case alert.TicketAlertSeverity:
+		sloAlert, quick, slow = slo.TicketAlertMeta, alerts.TicketQuick, alerts.TicketSlow
+	default:
+		return nil, fmt.Errorf("unknown %q alert severity", severity)
+	}
+
+	if sloAlert.Disable {
+		return nil, fmt.Errorf("%q SLO %s alert is disabled", slo.ID, severity)
+	}
+	if burnRate < slow.BurnRateFactor {
+		return nil, fmt.Errorf("%g burn rate doesn't fire the %q SLO %s alert, the minimum burn rate is %g", burnRate, slo.ID, severity, slow.BurnRateFactor)
+	}
+
+	rule, err := defaultSLOAlertGenerator(slo, sloAlert, quick, slow)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate %q SLO %s alert rule: %w", slo.ID, severity, err)
+	}
+
+	// The alert series are the SLI error recording rules of the firing alert short window.
+	window := slow.ShortWindow
+	if burnRate >= quick.BurnRateFactor {
+		window = quick.ShortWindow
+	}
+	seriesLabels := mergeLabels(
+		slo.GetSLOIDPromLabels(),
+		map[string]string{sloWindowLabelName: timeDurationToPromStr(window)},
+		slo.Labels,
+	)
+	value := burnRate * quick.ErrorBudget / 100
+
+	// Render the rule templates like Prometheus does.
+	labels := mergeLabels(seriesLabels)
+	for k, v := range rule.Labels {
+		rendered, err := expandPromAlertTemplate(k, v, seriesLabels, value)
+		if err != nil {
+			return nil, fmt.Errorf("could not render %q label: %w", k, err)
+		}
+		labels[k] = rendered
+	}
+	labels["alertname"] = rule.Alert
+
+	annotations := map[string]string{}
+	for k, v := range rule.Annotations {
+		rendered, err := expandPromAlertTemplate(k, v, labels, value)
+		if err != nil {
+			return nil, fmt.Errorf("could not render %q annotation: %w", k, err)
+		}
+		annotations[k] = rendered
+	}
+
+	return &AlertPreview{
+		Name:        rule.Alert,
+		Severity:    severity,
+		Value:       value,
+		Labels:      labels,
+		Annotations: annotations,
+	}, nil
+}
+
+// promAlertTemplateDefs are the variables that Prometheus sets on the alert templates.
+const promAlertTemplateDefs = "{{$labels := .Labels}}{{$externalLabels := .ExternalLabels}}{{$value := .Value}}"
+
+// promAlertTemplateFuncs are the most used Prometheus alert template functions, the
+// ones that don't query Prometheus.
+var promAlertTemplateFuncs = template.FuncMap{
+	"toUpper":            strings.ToUpper,
+	"toLower":            strings.ToLower,
+	"title":              strings.Title,
+	"humanize":           humanizePromValue,
+	"humanizePercentage": func(v float64) string { return fmt.Sprintf("%.4g%%", v*100) },
+	"humanizeDuration":   humanizePromDuration,
+}
+
+func expandPromAlertTemplate(name, text string, labels map[string]string, value float64) (string, error) {
+	tpl, err := template.New(name).Funcs(promAlertTemplateFuncs).Option("missingkey=zero").Parse(promAlertTemplateDefs + text)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Labels         map[string]string
+		ExternalLabels map[string]string
+		Value          float64
+	}{
+		Labels:         labels,
+		ExternalLabels: map[string]string{},
+		Value:          value,
+	}
+
+	var b bytes.Buffer
+	err = tpl.Execute(&b, data)
+	if err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// humanizePromValue formats a value with SI prefixes like the Prometheus `humanize` template function.
+func humanizePromValue(v float64) string {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v)
+	}
+
+	if math.Abs(v) >= 1 {
+		prefix := ""
+		for _, p := range []string{"k", "M", "G", "T", "P", "E", "Z", "Y"} {
+			if math.Abs(v) < 1000 {
+				break
+			}
+			prefix = p
+			v /= 1000
+		}
+		return fmt.Sprintf("%.4g%s", v, prefix)
+	}
+
+	prefix := ""
+	for _, p := range []string{"m", "u", "n", "p", "f", "a", "z", "y"} {
+		if math.Abs(v) >= 1 {
+			break
+		}
+		prefix = p
+		v *= 1000
+	}
+	return fmt.Sprintf("%.4g%s", v, prefix)
+}
+
+// humanizePromDuration formats seconds like the Prometheus `humanizeDuration` template function.
+func humanizePromDuration(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) < 1 {
+		return fmt.Sprintf("%.4gs", v)
+	}
+
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	seconds := int64(v) % 60
+	minutes := (int64(v) / 60) % 60
+	hours := (int64(v) / 60 / 60) % 24
+	days := int64(v) / 60 / 60 / 24
+
+	switch {
+	case days != 0:
+		return fmt.Sprintf("%s%dd %dh %dm %ds", sign, days, hours, minutes, seconds)
+	case hours != 0:
+		return fmt.Sprintf("%s%dh %dm %ds", sign, hours, minutes, seconds)
+	case minutes != 0:
+		return fmt.Sprintf("%s%dm %ds", sign, minutes, seconds)
+	}
+
+	return fmt.Sprintf("%s%.4gs", sign, v)
+}
@@ -0,0 +1,123 @@
+package prometheus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestPreviewSLOAlert(t *testing.T) {
+	getSLO := func() prometheus.SLO {
+		return prometheus.SLO{
+			ID:         "test-svc-test-slo",
+			Name:       "test-slo",
+			Service:    "test-svc",
+			TimeWindow: 30 * 24 * time.Hour,
+			Objective:  99.9,
+			Labels:     map[string]string{"owner": "myteam"},
+			PageAlertMeta: prometheus.AlertMeta{
+				Name:        "TestAlert",
+				Labels:      map[string]string{"routing_key": "{{ $labels.owner | toUpper }}"},
+				Annotations: map[string]string{"description": "Burning {{ $value | humanizePercentage }} errors on {{ $labels.sloth_window }}."},
+			},
+			TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+		}
+	}
+
+	tests := map[string]struct {
+		slo        prometheus.SLO
+		severity   alert.Severity
+		burnRate   float64
+		expPreview *prometheus.AlertPreview
+		expErr     bool
+	}{
+		"A disabled alert should fail.": {
+			slo:      getSLO(),
+			severity: alert.TicketAlertSeverity,
+			burnRate: 3,
+			expErr:   true,
+		},
+
+		"A burn rate that doesn't fire the alert should fail.": {
+			slo:      getSLO(),
+			severity: alert.PageAlertSeverity,
+			burnRate: 5,
+			expErr:   true,
+		},
+
+		"A burn rate that fires the quick alert should render the alert with the quick short window.": {
+			slo:      getSLO(),
+			severity: alert.PageAlertSeverity,
+			burnRate: 20,
+			expPreview: &prometheus.AlertPreview{
+				Name:     "TestAlert",
+				Severity: alert.PageAlertSeverity,
+				Value:    0.02,
+				Labels: map[string]string{
+					"alertname":      "TestAlert",
+					"owner":          "myteam",
+					"routing_key":    "MYTEAM",
+					"sloth_id":       "test-svc-test-slo",
+					"sloth_service":  "test-svc",
+					"sloth_severity": "page",
+					"sloth_slo":      "test-slo",
+					"sloth_window":   "5m",
+				},
+				Annotations: map[string]string{
+					"title":            "(page) test-svc test-slo SLO error budget burn rate is too fast.",
+					"summary":          "test-svc test-slo SLO error budget burn rate is over expected.",
+					"allowed_downtime": "43m12s in 30d",
+					"description":      "Burning 2% errors on 5m.",
+				},
+			},
+		},
+
+		"A burn rate that only fires the slow alert should render the alert with the slow short window.": {
+			slo:      getSLO(),
+			severity: alert.PageAlertSeverity,
+			burnRate: 10,
+			expPreview: &prometheus.AlertPreview{
+				Name:     "TestAlert",
+				Severity: alert.PageAlertSeverity,
+				Value:    0.01,
+				Labels: map[string]string{
+					"alertname":      "TestAlert",
+					"owner":          "myteam",
+					"routing_key":    "MYTEAM",
+					"sloth_id":       "test-svc-test-slo",
+					"sloth_service":  "test-svc",
+					"sloth_severity": "page",
+					"sloth_slo":      "test-slo",
+					"sloth_window":   "30m",
+				},
+				Annotations: map[string]string{
+					"title":            "(page) test-svc test-slo SLO error budget burn rate is too fast.",
+					"summary":          "test-svc test-slo SLO error budget burn rate is over expected.",
+					"allowed_downtime": "43m12s in 30d",
+					"description":      "Burning 1% errors on 30m.",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotPreview, err := prometheus.PreviewSLOAlert(context.TODO(), test.slo, test.severity, test.burnRate)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.InDelta(test.expPreview.Value, gotPreview.Value, 0.000001)
+				gotPreview.Value = test.expPreview.Value
+				assert.Equal(test.expPreview, gotPreview)
+			}
+		})
+	}
+}
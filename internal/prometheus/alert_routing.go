@@ -0,0 +1,178 @@
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AlertmanagerRoute is an Alertmanager configuration route, with the fields required
+// to simulate the alerts routing.
+type AlertmanagerRoute struct {
+	Receiver string              `yaml:"receiver,omitempty"`
+	Continue bool                `yaml:"continue,omitempty"`
+	Match    map[string]string   `yaml:"match,omitempty"`
+	MatchRE  map[string]string   `yaml:"match_re,omitempty"`
+	Matchers []string            `yaml:"matchers,omitempty"`
+	Routes   []AlertmanagerRoute `yaml:"routes,omitempty"`
+
+	matchers []alertmanagerMatcher
+}
+
+// LoadAlertmanagerRoute loads the routing tree of an Alertmanager configuration YAML.
+func LoadAlertmanagerRoute(data []byte) (*AlertmanagerRoute, error) {
+	config := struct {
+		Route *AlertmanagerRoute `yaml:"route"`
+	}{}
+	err := yaml.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal YAML Alertmanager configuration: %w", err)
+	}
+
+	if config.Route == nil {
+		return nil, fmt.Errorf("missing Alertmanager configuration route")
+	}
+	if config.Route.Receiver == "" {
+		return nil, fmt.Errorf("missing Alertmanager configuration root route receiver")
+	}
+	if config.Route.Continue {
+		return nil, fmt.Errorf("root route can't have continue")
+	}
+	if len(config.Route.Match)+len(config.Route.MatchRE)+len(config.Route.Matchers) > 0 {
+		return nil, fmt.Errorf("root route can't have matchers")
+	}
+
+	err = config.Route.compile(config.Route.Receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Route, nil
+}
+
+// compile sets the inherited receivers and compiles the matchers of the route tree.
+func (r *AlertmanagerRoute) compile(parentReceiver string) error {
+	if r.Receiver == "" {
+		r.Receiver = parentReceiver
+	}
+
+	r.matchers = nil
+	for k, v := range r.Match {
+		r.matchers = append(r.matchers, alertmanagerMatcher{name: k, op: "=", value: v})
+	}
+	for k, v := range r.MatchRE {
+		m, err := newAlertmanagerMatcher(k, "=~", v)
+		if err != nil {
+			return err
+		}
+		r.matchers = append(r.matchers, m)
+	}
+	for _, s := range r.Matchers {
+		m, err := parseAlertmanagerMatcher(s)
+		if err != nil {
+			return err
+		}
+		r.matchers = append(r.matchers, m)
+	}
+
+	for i := range r.Routes {
+		err := r.Routes[i].compile(r.Receiver)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Route returns the receivers chains (from the root route receiver to the matched route
+// receiver) of the routes that match the alert labels, using the Alertmanager routing
+// logic: the routes are matched depth first, stopping on the first matched route unless
+// it has `continue`, when no child route matches, the parent route is the matched one.
+func (r AlertmanagerRoute) Route(labels map[string]string) [][]string {
+	for _, m := range r.matchers {
+		if !m.matches(labels[m.name]) {
+			return nil
+		}
+	}
+
+	chains := [][]string{}
+	for _, child := range r.Routes {
+		childChains := child.Route(labels)
+		if childChains == nil {
+			continue
+		}
+
+		for _, c := range childChains {
+			chains = append(chains, append([]string{r.Receiver}, c...))
+		}
+
+		if !child.Continue {
+			break
+		}
+	}
+
+	if len(chains) == 0 {
+		chains = append(chains, []string{r.Receiver})
+	}
+
+	return chains
+}
+
+type alertmanagerMatcher struct {
+	name  string
+	op    string
+	value string
+	regex *regexp.Regexp
+}
+
+var alertmanagerMatcherRegexp = regexp.MustCompile(`^\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+func parseAlertmanagerMatcher(s string) (alertmanagerMatcher, error) {
+	parts := alertmanagerMatcherRegexp.FindStringSubmatch(s)
+	if parts == nil {
+		return alertmanagerMatcher{}, fmt.Errorf("invalid %q Alertmanager matcher", s)
+	}
+
+	value := parts[3]
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		v, err := strconv.Unquote(value)
+		if err != nil {
+			return alertmanagerMatcher{}, fmt.Errorf("invalid %q Alertmanager matcher value: %w", s, err)
+		}
+		value = v
+	}
+
+	return newAlertmanagerMatcher(parts[1], parts[2], value)
+}
+
+func newAlertmanagerMatcher(name, op, value string) (alertmanagerMatcher, error) {
+	m := alertmanagerMatcher{name: name, op: op, value: value}
+	if op == "=~" || op == "!~" {
+		// Alertmanager regexes are anchored.
+		r, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return alertmanagerMatcher{}, fmt.Errorf("invalid %q Alertmanager matcher regex: %w", value, err)
+		}
+		m.regex = r
+	}
+
+	return m, nil
+}
+
+func (a alertmanagerMatcher) matches(value string) bool {
+	switch a.op {
+	case "=":
+		return value == a.value
+	case "!=":
+		return value != a.value
+	case "=~":
+		return a.regex.MatchString(value)
+	case "!~":
+		return !a.regex.MatchString(value)
+	}
+
+	return false
+}
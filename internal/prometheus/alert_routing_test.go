@@ -0,0 +1,140 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestLoadAlertmanagerRoute(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		expErr bool
+	}{
+		"Invalid YAML should fail.": {
+			config: `:`,
+			expErr: true,
+		},
+
+		"Missing route should fail.": {
+			config: `receivers: [{name: default}]`,
+			expErr: true,
+		},
+
+		"Missing root route receiver should fail.": {
+			config: `route: {group_by: [alertname]}`,
+			expErr: true,
+		},
+
+		"Root route with matchers should fail.": {
+			config: `route: {receiver: default, matchers: ['team="a"']}`,
+			expErr: true,
+		},
+
+		"Invalid matchers should fail.": {
+			config: `route: {receiver: default, routes: [{receiver: a, matchers: ['team']}]}`,
+			expErr: true,
+		},
+
+		"Invalid matcher regexes should fail.": {
+			config: `route: {receiver: default, routes: [{receiver: a, match_re: {team: '('}}]}`,
+			expErr: true,
+		},
+
+		"A valid configuration should load.": {
+			config: `route: {receiver: default, routes: [{receiver: a, matchers: ['team=~"a|b"']}]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := prometheus.LoadAlertmanagerRoute([]byte(test.config))
+			if test.expErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAlertmanagerRouteRoute(t *testing.T) {
+	const config = `
+route:
+  receiver: default
+  group_by: [alertname]
+  routes:
+    - matchers: ['sloth_severity="page"']
+      receiver: pager
+      continue: true
+      routes:
+        - match: {team: a}
+          receiver: pager-team-a
+        - match_re: {team: "b|c"}
+          receiver: pager-team-bc
+    - matchers: ['sloth_severity = page', 'team!="a"']
+      receiver: page-audit
+    - matchers: [sloth_severity=~"ticket|info", team!~"x.*"]
+      receiver: tickets
+`
+
+	tests := map[string]struct {
+		labels    map[string]string
+		expChains [][]string
+	}{
+		"Alerts that don't match any route should be routed to the root route.": {
+			labels:    map[string]string{"sloth_severity": "other"},
+			expChains: [][]string{{"default"}},
+		},
+
+		"Alerts should be routed to the deepest matched route.": {
+			labels:    map[string]string{"sloth_severity": "page", "team": "a"},
+			expChains: [][]string{{"default", "pager", "pager-team-a"}},
+		},
+
+		"Alerts should continue matching the sibling routes when the route has continue.": {
+			labels: map[string]string{"sloth_severity": "page", "team": "c"},
+			expChains: [][]string{
+				{"default", "pager", "pager-team-bc"},
+				{"default", "page-audit"},
+			},
+		},
+
+		"Alerts that don't match any child route should be routed to the parent route.": {
+			labels: map[string]string{"sloth_severity": "page"},
+			expChains: [][]string{
+				{"default", "pager"},
+				{"default", "page-audit"},
+			},
+		},
+
+		"Regex matchers should be anchored.": {
+			labels:    map[string]string{"sloth_severity": "ticket-x", "team": "a"},
+			expChains: [][]string{{"default"}},
+		},
+
+		"Negative regex matchers should match the missing labels.": {
+			labels:    map[string]string{"sloth_severity": "ticket"},
+			expChains: [][]string{{"default", "tickets"}},
+		},
+
+		"Negative regex matchers should not match the labels that match the regex.": {
+			labels:    map[string]string{"sloth_severity": "ticket", "team": "xyz"},
+			expChains: [][]string{{"default"}},
+		},
+	}
+
+	route, err := prometheus.LoadAlertmanagerRoute([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotChains := route.Route(test.labels)
+			assert.Equal(t, test.expChains, gotChains)
+		})
+	}
+}
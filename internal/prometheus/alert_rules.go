@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"hash/fnv"
 	"text/template"
+	"time"
 
+	prommodel "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/rulefmt"
 
 	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/pkg/budget"
 )
 
 // genFunc knows how to generate an SLI recording rule for a specific time window.
@@ -16,14 +20,56 @@ type alertGenFunc func(slo SLO, sloAlert AlertMeta, quick, slow alert.MWMBAlert)
 
 type sloAlertRulesGenerator struct {
 	alertGenFunc alertGenFunc
+	forJitter    AlertForJitter
 }
 
 // SLOAlertRulesGenerator knows how to generate the SLO prometheus alert rules
 // from an SLO.
 var SLOAlertRulesGenerator = sloAlertRulesGenerator{alertGenFunc: defaultSLOAlertGenerator}
 
+// AlertForJitter are the bounds of the alerts `for` duration jitter. The jitter staggers
+// the alerts of different SLOs so a shared dependency failure doesn't page with all of
+// them at the same time. A zero max disables the jitter.
+type AlertForJitter struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Validate validates the jitter bounds.
+func (a AlertForJitter) Validate() error {
+	if a.Min < 0 || a.Max < 0 {
+		return fmt.Errorf("alert for jitter bounds can't be negative")
+	}
+
+	if a.Min > a.Max {
+		return fmt.Errorf("alert for jitter min (%s) can't be greater than max (%s)", a.Min, a.Max)
+	}
+
+	return nil
+}
+
+// duration returns the deterministic jitter of an SLO (in seconds) between the bounds.
+func (a AlertForJitter) duration(sloID string) time.Duration {
+	if a.Max == 0 {
+		return 0
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(sloID))
+	rangeSecs := uint32((a.Max-a.Min)/time.Second) + 1
+	return a.Min.Truncate(time.Second) + time.Duration(h.Sum32()%rangeSecs)*time.Second
+}
+
+// WithForJitter returns a copy of the generator that sets the jittered `for` duration
+// on the generated alerts.
+func (s sloAlertRulesGenerator) WithForJitter(jitter AlertForJitter) sloAlertRulesGenerator {
+	s.forJitter = jitter
+	return s
+}
+
 func (s sloAlertRulesGenerator) GenerateSLOAlertRules(ctx context.Context, slo SLO, alerts alert.MWMBAlertGroup) ([]rulefmt.Rule, error) {
 	rules := []rulefmt.Rule{}
+	jitter := s.forJitter.duration(slo.ID)
 
 	// Generate Page alerts.
 	if !slo.PageAlertMeta.Disable {
@@ -31,6 +77,7 @@ func (s sloAlertRulesGenerator) GenerateSLOAlertRules(ctx context.Context, slo S
 		if err != nil {
 			return nil, fmt.Errorf("could not create page alert: %w", err)
 		}
+		rule.For = prommodel.Duration(alertFor(slo.PageAlertMeta, alerts.PageQuick) + jitter)
 
 		rules = append(rules, *rule)
 	}
@@ -41,6 +88,7 @@ func (s sloAlertRulesGenerator) GenerateSLOAlertRules(ctx context.Context, slo S
 		if err != nil {
 			return nil, fmt.Errorf("could not create ticket alert: %w", err)
 		}
+		rule.For = prommodel.Duration(alertFor(slo.TicketAlertMeta, alerts.TicketQuick) + jitter)
 
 		rules = append(rules, *rule)
 	}
@@ -48,6 +96,23 @@ func (s sloAlertRulesGenerator) GenerateSLOAlertRules(ctx context.Context, slo S
 	return rules, nil
 }
 
+// alertFor returns the alert `for` duration, the alert metadata one takes precedence over
+// the alert windows one.
+func alertFor(sloAlert AlertMeta, quick alert.MWMBAlert) time.Duration {
+	if sloAlert.For > 0 {
+		return sloAlert.For
+	}
+
+	return quick.For
+}
+
+// guardAlertExpr guards the alert expression with the user guard expression. The guard
+// is matched with `on()`, so label-less signals (e.g `cluster_maintenance == 0`) can guard
+// the SLO alerts: the alerts only fire when the guard returns any series.
+func guardAlertExpr(expr, guard string) string {
+	return fmt.Sprintf("(\n%s)\nand on()\n(%s)\n", expr, guard)
+}
+
 func defaultSLOAlertGenerator(slo SLO, sloAlert AlertMeta, quick, slow alert.MWMBAlert) (*rulefmt.Rule, error) {
 	// Generate the filter labels based on the SLO ids.
 	metricFilter := labelsToPromFilter(slo.GetSLOIDPromLabels())
@@ -84,11 +149,25 @@ func defaultSLOAlertGenerator(slo SLO, sloAlert AlertMeta, quick, slow alert.MWM
 		return nil, fmt.Errorf("could not render alert expression: %w", err)
 	}
 
+	// Guard the alert with the user expression (e.g maintenance modes).
+	exprStr := expr.String()
+	if slo.AlertGuard != "" {
+		exprStr = guardAlertExpr(exprStr, slo.AlertGuard)
+	}
+
 	// Add specific annotations.
 	severity := quick.Severity.String() // Any(quick or slow) should work because are the same.
+	downtime := budget.AllowedDowntime(slo.Objective, slo.TimeWindow).Round(time.Second)
 	extraAnnotations := map[string]string{
-		"title":   fmt.Sprintf("(%s) {{$labels.%s}} {{$labels.%s}} SLO error budget burn rate is too fast.", severity, sloServiceLabelName, sloNameLabelName),
-		"summary": fmt.Sprintf("{{$labels.%s}} {{$labels.%s}} SLO error budget burn rate is over expected.", sloServiceLabelName, sloNameLabelName),
+		"title":            fmt.Sprintf("(%s) {{$labels.%s}} {{$labels.%s}} SLO error budget burn rate is too fast.", severity, sloServiceLabelName, sloNameLabelName),
+		"summary":          fmt.Sprintf("{{$labels.%s}} {{$labels.%s}} SLO error budget burn rate is over expected.", sloServiceLabelName, sloNameLabelName),
+		"allowed_downtime": fmt.Sprintf("%s in %s", timeDurationToPromStr(downtime), timeDurationToPromStr(slo.TimeWindow)),
+	}
+
+	// Render the user annotation templates.
+	annotations, err := renderAlertAnnotations(mergeLabels(slo.Annotations, sloAlert.Annotations), newAlertTemplateData(slo, sloAlert, quick, slow))
+	if err != nil {
+		return nil, fmt.Errorf("could not render alert annotations: %w", err)
 	}
 
 	// Add specific labels. We don't add the labels from the rules because we will
@@ -99,8 +178,8 @@ func defaultSLOAlertGenerator(slo SLO, sloAlert AlertMeta, quick, slow alert.MWM
 
 	return &rulefmt.Rule{
 		Alert:       sloAlert.Name,
-		Expr:        expr.String(),
-		Annotations: mergeLabels(extraAnnotations, sloAlert.Annotations),
+		Expr:        exprStr,
+		Annotations: mergeLabels(extraAnnotations, annotations),
 		Labels:      mergeLabels(extraLabels, sloAlert.Labels),
 	}, nil
 }
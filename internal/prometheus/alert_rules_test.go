@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	prommodel "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/rulefmt"
 	"github.com/stretchr/testify/assert"
 
@@ -58,9 +59,12 @@ func TestGenerateSLOAlertRules(t *testing.T) {
 	}{
 		"Having and SLO an its page and ticket alerts should create the recording rules.": {
 			slo: prometheus.SLO{
-				ID:      "test-svc-test",
-				Name:    "test",
-				Service: "test-svc",
+				ID:          "test-svc-test",
+				Name:        "test",
+				Service:     "test-svc",
+				TimeWindow:  30 * 24 * time.Hour,
+				Objective:   99.9,
+				Annotations: map[string]string{"custom-annot": "slo", "slo-annot": "test"},
 				PageAlertMeta: prometheus.AlertMeta{
 					Name:        "something1",
 					Labels:      map[string]string{"custom-label": "test1"},
@@ -93,9 +97,11 @@ or ignoring (sloth_window)
 						"sloth_severity": "page",
 					},
 					Annotations: map[string]string{
-						"custom-annot": "test1",
-						"summary":      "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
-						"title":        "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+						"allowed_downtime": "43m12s in 30d",
+						"custom-annot":     "test1",
+						"slo-annot":        "test",
+						"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+						"title":            "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
 					},
 				},
 				{
@@ -117,9 +123,11 @@ or ignoring (sloth_window)
 						"sloth_severity": "ticket",
 					},
 					Annotations: map[string]string{
-						"custom-annot": "test2",
-						"summary":      "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
-						"title":        "(ticket) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+						"allowed_downtime": "43m12s in 30d",
+						"custom-annot":     "test2",
+						"slo-annot":        "test",
+						"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+						"title":            "(ticket) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
 					},
 				},
 			},
@@ -127,9 +135,11 @@ or ignoring (sloth_window)
 
 		"Having and SLO an page and disabled ticket alerts should only create only page alert rules.": {
 			slo: prometheus.SLO{
-				ID:      "test-svc-test",
-				Name:    "test",
-				Service: "test-svc",
+				ID:         "test-svc-test",
+				Name:       "test",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				Objective:  99.9,
 				PageAlertMeta: prometheus.AlertMeta{
 					Name:        "something1",
 					Labels:      map[string]string{"custom-label": "test1"},
@@ -160,18 +170,21 @@ or ignoring (sloth_window)
 						"sloth_severity": "page",
 					},
 					Annotations: map[string]string{
-						"custom-annot": "test1",
-						"summary":      "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
-						"title":        "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+						"allowed_downtime": "43m12s in 30d",
+						"custom-annot":     "test1",
+						"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+						"title":            "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
 					},
 				},
 			},
 		},
 		"Having and SLO an ticker and page alerts disabled should only create ticket alert rules.": {
 			slo: prometheus.SLO{
-				ID:      "test-svc-test",
-				Name:    "test",
-				Service: "test-svc",
+				ID:         "test-svc-test",
+				Name:       "test",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				Objective:  99.9,
 				PageAlertMeta: prometheus.AlertMeta{
 					Disable: true,
 				},
@@ -202,13 +215,177 @@ or ignoring (sloth_window)
 						"sloth_severity": "ticket",
 					},
 					Annotations: map[string]string{
-						"custom-annot": "test2",
-						"summary":      "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
-						"title":        "(ticket) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+						"allowed_downtime": "43m12s in 30d",
+						"custom-annot":     "test2",
+						"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+						"title":            "(ticket) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
 					},
 				},
 			},
 		},
+
+		"Having and SLO with a custom alert for duration should set it on the alert rules.": {
+			slo: prometheus.SLO{
+				ID:         "test-svc-test",
+				Name:       "test",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				Objective:  99.9,
+				PageAlertMeta: prometheus.AlertMeta{
+					Disable: true,
+				},
+				TicketAlertMeta: prometheus.AlertMeta{
+					Name:        "something2",
+					Labels:      map[string]string{"custom-label": "test2"},
+					Annotations: map[string]string{"custom-annot": "test2"},
+					For:         time.Hour,
+				},
+			},
+			alertGroup: getSLOAlertGroup,
+			expRules: []rulefmt.Rule{
+				{
+					Alert: "something2",
+					For:   prommodel.Duration(time.Hour),
+					Expr: `(
+    (slo:sli_error:ratio_rate31m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (33 * 0.01))
+    and ignoring (sloth_window)
+    (slo:sli_error:ratio_rate32m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (33 * 0.01))
+)
+or ignoring (sloth_window)
+(
+    (slo:sli_error:ratio_rate41m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (43 * 0.01))
+    and ignoring (sloth_window)
+    (slo:sli_error:ratio_rate42m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (43 * 0.01))
+)
+`,
+					Labels: map[string]string{
+						"custom-label":   "test2",
+						"sloth_severity": "ticket",
+					},
+					Annotations: map[string]string{
+						"allowed_downtime": "43m12s in 30d",
+						"custom-annot":     "test2",
+						"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+						"title":            "(ticket) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+					},
+				},
+			},
+		},
+
+		"Having and SLO with an alert guard should create the alert rules guarded by the expression.": {
+			slo: prometheus.SLO{
+				ID:         "test-svc-test",
+				Name:       "test",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				Objective:  99.9,
+				PageAlertMeta: prometheus.AlertMeta{
+					Name: "something1",
+				},
+				TicketAlertMeta: prometheus.AlertMeta{
+					Disable: true,
+				},
+				AlertGuard: "cluster_maintenance == 0",
+			},
+			alertGroup: getSLOAlertGroup,
+			expRules: []rulefmt.Rule{
+				{
+					Alert: "something1",
+					Expr: `(
+(
+    (slo:sli_error:ratio_rate11m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (13 * 0.01))
+    and ignoring (sloth_window)
+    (slo:sli_error:ratio_rate12m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (13 * 0.01))
+)
+or ignoring (sloth_window)
+(
+    (slo:sli_error:ratio_rate21m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (23 * 0.01))
+    and ignoring (sloth_window)
+    (slo:sli_error:ratio_rate22m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (23 * 0.01))
+)
+)
+and on()
+(cluster_maintenance == 0)
+`,
+					Labels: map[string]string{
+						"sloth_severity": "page",
+					},
+					Annotations: map[string]string{
+						"allowed_downtime": "43m12s in 30d",
+						"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+						"title":            "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+					},
+				},
+			},
+		},
+
+		"Having and SLO with annotation templates should render the templates with the SLO and alert data.": {
+			slo: prometheus.SLO{
+				ID:          "test-svc-test",
+				Name:        "test",
+				Service:     "test-svc",
+				TimeWindow:  30 * 24 * time.Hour,
+				Objective:   99.9,
+				Labels:      map[string]string{"team": "team-a"},
+				Annotations: map[string]string{"slo-annot": "[[ .SLO.Service ]] [[ .SLO.Objective ]]% ([[ .SLO.ErrorBudget | printf \"%.1f\" ]]% in [[ .SLO.TimeWindow ]]) owned by [[ .SLO.Labels.team ]]."},
+				PageAlertMeta: prometheus.AlertMeta{
+					Name:        "something1",
+					Labels:      map[string]string{"channel": "#a-myteam"},
+					Annotations: map[string]string{"custom-annot": "[[ .Alert.Severity ]] to [[ .Alert.Labels.channel ]]: [[ .Alert.Quick.BurnRateFactor ]]x over [[ .Alert.Quick.LongWindow ]] or [[ .Alert.Slow.BurnRateFactor ]]x over [[ .Alert.Slow.LongWindow ]] ({{$value}})."},
+				},
+				TicketAlertMeta: prometheus.AlertMeta{
+					Disable: true,
+				},
+			},
+			alertGroup: getSLOAlertGroup,
+			expRules: []rulefmt.Rule{
+				{
+					Alert: "something1",
+					Expr: `(
+    (slo:sli_error:ratio_rate11m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (13 * 0.01))
+    and ignoring (sloth_window)
+    (slo:sli_error:ratio_rate12m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (13 * 0.01))
+)
+or ignoring (sloth_window)
+(
+    (slo:sli_error:ratio_rate21m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (23 * 0.01))
+    and ignoring (sloth_window)
+    (slo:sli_error:ratio_rate22m{sloth_id="test-svc-test", sloth_service="test-svc", sloth_slo="test"} > (23 * 0.01))
+)
+`,
+					Labels: map[string]string{
+						"channel":        "#a-myteam",
+						"sloth_severity": "page",
+					},
+					Annotations: map[string]string{
+						"allowed_downtime": "43m12s in 30d",
+						"custom-annot":     "page to #a-myteam: 13x over 12m or 23x over 22m ({{$value}}).",
+						"slo-annot":        "test-svc 99.9% (0.1% in 30d) owned by team-a.",
+						"summary":          "{{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is over expected.",
+						"title":            "(page) {{$labels.sloth_service}} {{$labels.sloth_slo}} SLO error budget burn rate is too fast.",
+					},
+				},
+			},
+		},
+
+		"Having and SLO with annotation templates using missing labels should fail.": {
+			slo: prometheus.SLO{
+				ID:          "test-svc-test",
+				Name:        "test",
+				Service:     "test-svc",
+				TimeWindow:  30 * 24 * time.Hour,
+				Objective:   99.9,
+				Annotations: map[string]string{"slo-annot": "owned by [[ .SLO.Labels.team ]]."},
+				PageAlertMeta: prometheus.AlertMeta{
+					Name: "something1",
+				},
+				TicketAlertMeta: prometheus.AlertMeta{
+					Disable: true,
+				},
+			},
+			alertGroup: getSLOAlertGroup,
+			expErr:     true,
+		},
 	}
 
 	for name, test := range tests {
@@ -225,3 +402,75 @@ or ignoring (sloth_window)
 		})
 	}
 }
+
+func TestGenerateSLOAlertRulesForJitter(t *testing.T) {
+	tests := map[string]struct {
+		jitter prometheus.AlertForJitter
+		expErr bool
+		expMin time.Duration
+		expMax time.Duration
+	}{
+		"Having the jitter disabled should not set the alerts for duration.": {
+			jitter: prometheus.AlertForJitter{},
+		},
+
+		"Having invalid jitter bounds should fail.": {
+			jitter: prometheus.AlertForJitter{Min: 5 * time.Minute, Max: time.Minute},
+			expErr: true,
+		},
+
+		"Having negative jitter bounds should fail.": {
+			jitter: prometheus.AlertForJitter{Min: -time.Minute, Max: time.Minute},
+			expErr: true,
+		},
+
+		"Having the jitter enabled should set the same for duration on the SLO alerts between the bounds.": {
+			jitter: prometheus.AlertForJitter{Min: time.Minute, Max: 5 * time.Minute},
+			expMin: time.Minute,
+			expMax: 5 * time.Minute,
+		},
+
+		"Having the same jitter bounds should set a fixed for duration.": {
+			jitter: prometheus.AlertForJitter{Min: 2 * time.Minute, Max: 2 * time.Minute},
+			expMin: 2 * time.Minute,
+			expMax: 2 * time.Minute,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := test.jitter.Validate()
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			if !assert.NoError(err) {
+				return
+			}
+
+			slo := prometheus.SLO{
+				ID:              "test-svc-test",
+				Objective:       99.9,
+				PageAlertMeta:   prometheus.AlertMeta{Name: "something1"},
+				TicketAlertMeta: prometheus.AlertMeta{Name: "something2"},
+			}
+			gen := prometheus.SLOAlertRulesGenerator.WithForJitter(test.jitter)
+			gotRules, err := gen.GenerateSLOAlertRules(context.TODO(), slo, getSLOAlertGroup())
+			if !assert.NoError(err) || !assert.Len(gotRules, 2) {
+				return
+			}
+
+			// Should be deterministic.
+			gotRules2, err := gen.GenerateSLOAlertRules(context.TODO(), slo, getSLOAlertGroup())
+			assert.NoError(err)
+			assert.Equal(gotRules, gotRules2)
+
+			gotFor := time.Duration(gotRules[0].For)
+			assert.Equal(gotRules[0].For, gotRules[1].For)
+			assert.GreaterOrEqual(int64(gotFor), int64(test.expMin))
+			assert.LessOrEqual(int64(gotFor), int64(test.expMax))
+		})
+	}
+}
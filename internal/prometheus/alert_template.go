@@ -0,0 +1,123 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/slok/sloth/internal/alert"
+)
+
+// Alert annotation templates use different delimiters from the Prometheus alert templates
+// (e.g `{{$labels.sloth_service}}`), so both can be used on the same annotation. Sloth
+// templates are rendered at generation time and Prometheus templates at alert time.
+const (
+	alertTplLeftDelim  = "[["
+	alertTplRightDelim = "]]"
+)
+
+// AlertTemplateData is the data available to the alert annotation templates (e.g
+// `[[ .SLO.Service ]] [[ .SLO.Objective ]]% SLO`).
+type AlertTemplateData struct {
+	// SLO is the SLO of the alert.
+	SLO AlertTemplateSLO
+	// Alert is the alert.
+	Alert AlertTemplateAlert
+}
+
+// AlertTemplateSLO is the SLO data of the alert annotation templates.
+type AlertTemplateSLO struct {
+	ID          string
+	Name        string
+	Service     string
+	Description string
+	// Objective is the SLO objective percent (e.g 99.9).
+	Objective float64
+	// ErrorBudget is the SLO error budget percent (e.g 0.1).
+	ErrorBudget float64
+	// TimeWindow is the SLO time window in Prometheus duration format (e.g 30d).
+	TimeWindow string
+	// Labels are the SLO labels, missing labels fail the rendering.
+	Labels map[string]string
+}
+
+// AlertTemplateAlert is the alert data of the alert annotation templates.
+type AlertTemplateAlert struct {
+	Name string
+	// Severity is the alert severity (`page` or `ticket`).
+	Severity string
+	// Labels are the alert labels, missing labels fail the rendering.
+	Labels map[string]string
+	// Quick is the quick multiwindow multi burn rate alert.
+	Quick AlertTemplateWindows
+	// Slow is the slow multiwindow multi burn rate alert.
+	Slow AlertTemplateWindows
+}
+
+// AlertTemplateWindows are the windows and burn rate factor of a multiwindow multi burn
+// rate alert.
+type AlertTemplateWindows struct {
+	// ShortWindow is the short window in Prometheus duration format (e.g 5m).
+	ShortWindow string
+	// LongWindow is the long window in Prometheus duration format (e.g 1h).
+	LongWindow string
+	// BurnRateFactor is the error budget burn rate factor (e.g 14.4).
+	BurnRateFactor float64
+}
+
+func newAlertTemplateData(slo SLO, sloAlert AlertMeta, quick, slow alert.MWMBAlert) AlertTemplateData {
+	return AlertTemplateData{
+		SLO: AlertTemplateSLO{
+			ID:          slo.ID,
+			Name:        slo.Name,
+			Service:     slo.Service,
+			Description: slo.Description,
+			Objective:   slo.Objective,
+			ErrorBudget: 100 - slo.Objective,
+			TimeWindow:  timeDurationToPromStr(slo.TimeWindow),
+			Labels:      mergeLabels(slo.Labels),
+		},
+		Alert: AlertTemplateAlert{
+			Name:     sloAlert.Name,
+			Severity: quick.Severity.String(), // Any(quick or slow) should work because are the same.
+			Labels:   mergeLabels(sloAlert.Labels),
+			Quick: AlertTemplateWindows{
+				ShortWindow:    timeDurationToPromStr(quick.ShortWindow),
+				LongWindow:     timeDurationToPromStr(quick.LongWindow),
+				BurnRateFactor: quick.BurnRateFactor,
+			},
+			Slow: AlertTemplateWindows{
+				ShortWindow:    timeDurationToPromStr(slow.ShortWindow),
+				LongWindow:     timeDurationToPromStr(slow.LongWindow),
+				BurnRateFactor: slow.BurnRateFactor,
+			},
+		},
+	}
+}
+
+// renderAlertAnnotations renders the alert annotation templates. The annotations
+// without templates are returned as they are.
+func renderAlertAnnotations(annotations map[string]string, data AlertTemplateData) (map[string]string, error) {
+	res := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if !strings.Contains(v, alertTplLeftDelim) {
+			res[k] = v
+			continue
+		}
+
+		tpl, err := template.New(k).Delims(alertTplLeftDelim, alertTplRightDelim).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q annotation template: %w", k, err)
+		}
+
+		var b bytes.Buffer
+		err = tpl.Execute(&b, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not render %q annotation template: %w", k, err)
+		}
+		res[k] = b.String()
+	}
+
+	return res, nil
+}
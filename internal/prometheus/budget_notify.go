@@ -0,0 +1,349 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+)
+
+// DefaultBudgetThresholds are the default remaining error budget ratio thresholds of the budget
+// notifications (50%, 25% and 10% remaining).
+var DefaultBudgetThresholds = []float64{0.5, 0.25, 0.1}
+
+// BudgetThresholdCrossing is an SLO remaining error budget that has crossed down a threshold
+// between two observations.
+type BudgetThresholdCrossing struct {
+	SLO SLO
+	// Threshold is the crossed remaining error budget ratio threshold (0-1).
+	Threshold float64
+	// PreviousErrorBudgetRemaining is the remaining error budget ratio of the previous observation.
+	PreviousErrorBudgetRemaining float64
+	// ErrorBudgetRemaining is the remaining error budget ratio of the observation.
+	ErrorBudgetRemaining float64
+	Time                 time.Time
+}
+
+// BudgetThresholdState is the budget threshold tracker state of an SLO.
+type BudgetThresholdState struct {
+	// ErrorBudgetRemaining is the remaining error budget ratio of the last observation.
+	ErrorBudgetRemaining float64 `json:"errorBudgetRemaining"`
+	// Pending is the threshold crossing that has not been notified yet, if any.
+	Pending *BudgetThresholdPending `json:"pending,omitempty"`
+}
+
+// BudgetThresholdPending is a budget threshold crossing that has not been notified yet.
+type BudgetThresholdPending struct {
+	Threshold                    float64 `json:"threshold"`
+	PreviousErrorBudgetRemaining float64 `json:"previousErrorBudgetRemaining"`
+	ErrorBudgetRemaining         float64 `json:"errorBudgetRemaining"`
+	// Time is the first observation of the crossing, the same on every notification retry.
+	Time time.Time `json:"time"`
+}
+
+// UnmarshalJSON unmarshals the state, including the previous versions state that only had the
+// remaining error budget ratio.
+func (b *BudgetThresholdState) UnmarshalJSON(data []byte) error {
+	var remaining float64
+	if err := json.Unmarshal(data, &remaining); err == nil {
+		*b = BudgetThresholdState{ErrorBudgetRemaining: remaining}
+		return nil
+	}
+
+	type state BudgetThresholdState
+	var st state
+	err := json.Unmarshal(data, &st)
+	if err != nil {
+		return err
+	}
+	*b = BudgetThresholdState(st)
+
+	return nil
+}
+
+// BudgetThresholdTracker detects the SLOs remaining error budget threshold crossings between the
+// SLO report observations (e.g a periodic poller or a scheduled `report`). The crossings are pending
+// until they are notified, so the failed notifications are retried on the next observations with
+// the same crossing. It's not safe to be used concurrently.
+type BudgetThresholdTracker struct {
+	thresholds []float64
+	state      map[string]BudgetThresholdState
+}
+
+// NewBudgetThresholdTracker returns a new budget threshold tracker. The state has the previous
+// observations by SLO ID (e.g persisted between executions), it can be nil.
+func NewBudgetThresholdTracker(thresholds []float64, state map[string]BudgetThresholdState) (*BudgetThresholdTracker, error) {
+	if len(thresholds) == 0 {
+		return nil, fmt.Errorf("at least one threshold is required")
+	}
+
+	// Sort from the highest to the lowest threshold.
+	ts := make([]float64, 0, len(thresholds))
+	seen := map[float64]bool{}
+	for _, t := range thresholds {
+		if t <= 0 || t >= 1 {
+			return nil, fmt.Errorf("invalid %v threshold, must be a remaining error budget ratio between 0 and 1", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			ts = append(ts, t)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(ts)))
+
+	return &BudgetThresholdTracker{thresholds: ts, state: copyBudgetThresholdState(state)}, nil
+}
+
+// Observe observes the SLO reports and returns the threshold crossings since the previous observation,
+// only the lowest crossed threshold of every SLO, and the pending crossings of the previous observations.
+// The first observation of an SLO and the reports without remaining error budget data don't cross any
+// threshold.
+func (b *BudgetThresholdTracker) Observe(slos []SLO, reports []SLOReport, ts time.Time) []BudgetThresholdCrossing {
+	slosByID := map[string]SLO{}
+	for _, slo := range slos {
+		slosByID[slo.ID] = slo
+	}
+
+	crossings := []BudgetThresholdCrossing{}
+	for _, r := range reports {
+		slo, ok := slosByID[r.SLOID]
+		if !ok {
+			continue
+		}
+
+		st, ok := b.state[r.SLOID]
+		if r.ErrorBudgetRemaining != nil {
+			current := *r.ErrorBudgetRemaining
+			previous := st.ErrorBudgetRemaining
+			st.ErrorBudgetRemaining = current
+			if ok {
+				crossed := -1.0
+				for _, t := range b.thresholds {
+					if previous > t && current <= t {
+						crossed = t
+					}
+				}
+
+				// A pending crossing is only replaced by a lower one.
+				if crossed >= 0 && (st.Pending == nil || crossed < st.Pending.Threshold) {
+					st.Pending = &BudgetThresholdPending{
+						Threshold:                    crossed,
+						PreviousErrorBudgetRemaining: previous,
+						ErrorBudgetRemaining:         current,
+						Time:                         ts,
+					}
+				}
+			}
+			b.state[r.SLOID] = st
+		}
+
+		if st.Pending == nil {
+			continue
+		}
+
+		crossings = append(crossings, BudgetThresholdCrossing{
+			SLO:                          slo,
+			Threshold:                    st.Pending.Threshold,
+			PreviousErrorBudgetRemaining: st.Pending.PreviousErrorBudgetRemaining,
+			ErrorBudgetRemaining:         st.Pending.ErrorBudgetRemaining,
+			Time:                         st.Pending.Time,
+		})
+	}
+
+	return crossings
+}
+
+// Notified marks the crossing as notified, so it's not pending anymore.
+func (b *BudgetThresholdTracker) Notified(c BudgetThresholdCrossing) {
+	st, ok := b.state[c.SLO.ID]
+	if !ok || st.Pending == nil || st.Pending.Threshold != c.Threshold || !st.Pending.Time.Equal(c.Time) {
+		return
+	}
+
+	st.Pending = nil
+	b.state[c.SLO.ID] = st
+}
+
+// State returns the last observations and the pending crossings by SLO ID.
+func (b *BudgetThresholdTracker) State() map[string]BudgetThresholdState {
+	return copyBudgetThresholdState(b.state)
+}
+
+func copyBudgetThresholdState(state map[string]BudgetThresholdState) map[string]BudgetThresholdState {
+	c := make(map[string]BudgetThresholdState, len(state))
+	for id, st := range state {
+		if st.Pending != nil {
+			p := *st.Pending
+			st.Pending = &p
+		}
+		c[id] = st
+	}
+
+	return c
+}
+
+// BudgetWebhookNotifierConfig is the configuration of the budget webhook notifier.
+type BudgetWebhookNotifierConfig struct {
+	// URL is the webhook URL where the budget threshold crossing CloudEvents are sent.
+	URL string
+	// Source is the CloudEvents source. If empty, it will use `sloth`.
+	Source     string
+	HTTPClient *http.Client
+}
+
+func (c *BudgetWebhookNotifierConfig) defaults() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %q webhook URL", c.URL)
+	}
+
+	if c.Source == "" {
+		c.Source = "sloth"
+	}
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+
+	return nil
+}
+
+// BudgetThresholdCrossedEventType is the CloudEvents type of the budget threshold crossing events.
+const BudgetThresholdCrossedEventType = "dev.slok.sloth.slo.error_budget.threshold_crossed"
+
+// BudgetWebhookNotifier notifies the budget threshold crossings to a webhook as CloudEvents (JSON
+// structured mode), these are non paging notifications for the SLO owners.
+type BudgetWebhookNotifier struct {
+	url        string
+	source     string
+	httpClient *http.Client
+}
+
+// NewBudgetWebhookNotifier returns a new budget webhook notifier.
+func NewBudgetWebhookNotifier(config BudgetWebhookNotifierConfig) (*BudgetWebhookNotifier, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid budget webhook notifier configuration: %w", err)
+	}
+
+	return &BudgetWebhookNotifier{
+		url:        config.URL,
+		source:     config.Source,
+		httpClient: config.HTTPClient,
+	}, nil
+}
+
+type budgetCloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            budgetEventData `json:"data"`
+}
+
+type budgetEventData struct {
+	SLO                          budgetEventSLO `json:"slo"`
+	Threshold                    float64        `json:"threshold"`
+	PreviousErrorBudgetRemaining float64        `json:"previousErrorBudgetRemaining"`
+	ErrorBudgetRemaining         float64        `json:"errorBudgetRemaining"`
+}
+
+type budgetEventSLO struct {
+	ID          string            `json:"id"`
+	Service     string            `json:"service"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Objective   float64           `json:"objective"`
+	SLOPeriod   string            `json:"sloPeriod"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Notify sends a CloudEvent for every budget threshold crossing and returns the notified ones. A
+// failed notification doesn't stop the notification of the other crossings.
+func (b *BudgetWebhookNotifier) Notify(ctx context.Context, crossings []BudgetThresholdCrossing) ([]BudgetThresholdCrossing, error) {
+	notified := []BudgetThresholdCrossing{}
+	var firstErr error
+	failed := 0
+	for _, c := range crossings {
+		err := b.notify(ctx, c)
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("could not notify %q SLO budget threshold crossing: %w", c.SLO.ID, err)
+			}
+			continue
+		}
+		notified = append(notified, c)
+	}
+
+	if firstErr != nil {
+		return notified, fmt.Errorf("%d of %d notifications failed: %w", failed, len(crossings), firstErr)
+	}
+
+	return notified, nil
+}
+
+func (b *BudgetWebhookNotifier) notify(ctx context.Context, c BudgetThresholdCrossing) error {
+	event := budgetCloudEvent{
+		SpecVersion: "1.0",
+		// The same crossing (e.g retries) has the same ID and first observation time, so the
+		// receivers can deduplicate.
+		ID:              fmt.Sprintf("%s-%s-%d", c.SLO.ID, strconv.FormatFloat(c.Threshold, 'f', -1, 64), c.Time.Unix()),
+		Source:          b.source,
+		Type:            BudgetThresholdCrossedEventType,
+		Subject:         c.SLO.ID,
+		Time:            c.Time.UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		Data: budgetEventData{
+			SLO: budgetEventSLO{
+				ID:          c.SLO.ID,
+				Service:     c.SLO.Service,
+				Name:        c.SLO.Name,
+				Description: c.SLO.Description,
+				Objective:   c.SLO.Objective,
+				SLOPeriod:   prommodel.Duration(c.SLO.TimeWindow).String(),
+				Labels:      c.SLO.Labels,
+				Annotations: c.SLO.Annotations,
+			},
+			Threshold:                    c.Threshold,
+			PreviousErrorBudgetRemaining: c.PreviousErrorBudgetRemaining,
+			ErrorBudgetRemaining:         c.ErrorBudgetRemaining,
+		},
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return nil
+}
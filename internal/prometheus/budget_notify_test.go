@@ -0,0 +1,331 @@
+package prometheus_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestBudgetThresholdTracker(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	// The observation time of every observation.
+	at := func(i int) time.Time { return now.Add(time.Duration(i) * time.Minute) }
+	f := func(v float64) *float64 { return &v }
+	slo := prometheus.SLO{ID: "svc1-slo1", Service: "svc1", Name: "slo1"}
+	report := func(remaining *float64) []prometheus.SLOReport {
+		return []prometheus.SLOReport{{SLOID: slo.ID, ErrorBudgetRemaining: remaining}}
+	}
+
+	tests := map[string]struct {
+		thresholds   []float64
+		state        map[string]prometheus.BudgetThresholdState
+		observations [][]prometheus.SLOReport
+		failNotify   bool
+		expCrossings []prometheus.BudgetThresholdCrossing
+		expState     map[string]prometheus.BudgetThresholdState
+		expErr       bool
+	}{
+		"Not having thresholds should fail.": {
+			thresholds: []float64{},
+			expErr:     true,
+		},
+
+		"Having an invalid threshold should fail.": {
+			thresholds: []float64{0.5, 1.5},
+			expErr:     true,
+		},
+
+		"The first observation of an SLO should not cross any threshold.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			observations: [][]prometheus.SLOReport{report(f(0.05))},
+			expCrossings: []prometheus.BudgetThresholdCrossing{},
+			expState:     map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.05}},
+		},
+
+		"Observations that cross down a threshold should return the crossing.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			observations: [][]prometheus.SLOReport{report(f(0.6)), report(f(0.55)), report(f(0.5)), report(f(0.4))},
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.5, PreviousErrorBudgetRemaining: 0.55, ErrorBudgetRemaining: 0.5, Time: at(2)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.4}},
+		},
+
+		"Observations that cross down multiple thresholds should return only the lowest one.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			observations: [][]prometheus.SLOReport{report(f(0.6)), report(f(-0.2))},
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.1, PreviousErrorBudgetRemaining: 0.6, ErrorBudgetRemaining: -0.2, Time: at(1)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: -0.2}},
+		},
+
+		"Observations that cross up a threshold should not return crossings, but cross down again later.": {
+			thresholds:   []float64{0.5},
+			observations: [][]prometheus.SLOReport{report(f(0.4)), report(f(0.9)), report(f(0.45))},
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.5, PreviousErrorBudgetRemaining: 0.9, ErrorBudgetRemaining: 0.45, Time: at(2)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.45}},
+		},
+
+		"Observations without data should keep the previous observation.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			observations: [][]prometheus.SLOReport{report(f(0.3)), report(nil), report(f(0.2))},
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(2)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.2}},
+		},
+
+		"A previous state should be used as the previous observation.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			state:        map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.3}},
+			observations: [][]prometheus.SLOReport{report(f(0.2))},
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(0)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.2}},
+		},
+
+		"Reports of unknown SLOs should be ignored.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			state:        map[string]prometheus.BudgetThresholdState{"svc1-slo2": {ErrorBudgetRemaining: 0.3}},
+			observations: [][]prometheus.SLOReport{{{SLOID: "svc1-slo2", ErrorBudgetRemaining: f(0.2)}}},
+			expCrossings: []prometheus.BudgetThresholdCrossing{},
+			expState:     map[string]prometheus.BudgetThresholdState{"svc1-slo2": {ErrorBudgetRemaining: 0.3}},
+		},
+
+		"Not notified crossings should be pending and returned again with the first crossing time.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			observations: [][]prometheus.SLOReport{report(f(0.3)), report(f(0.2)), report(f(0.15)), report(nil)},
+			failNotify:   true,
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(1)},
+				{SLO: slo, Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(1)},
+				{SLO: slo, Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(1)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {
+				ErrorBudgetRemaining: 0.15,
+				Pending:              &prometheus.BudgetThresholdPending{Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(1)},
+			}},
+		},
+
+		"Not notified crossings should be replaced by a lower threshold crossing.": {
+			thresholds:   prometheus.DefaultBudgetThresholds,
+			observations: [][]prometheus.SLOReport{report(f(0.3)), report(f(0.2)), report(f(0.05))},
+			failNotify:   true,
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(1)},
+				{SLO: slo, Threshold: 0.1, PreviousErrorBudgetRemaining: 0.2, ErrorBudgetRemaining: 0.05, Time: at(2)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {
+				ErrorBudgetRemaining: 0.05,
+				Pending:              &prometheus.BudgetThresholdPending{Threshold: 0.1, PreviousErrorBudgetRemaining: 0.2, ErrorBudgetRemaining: 0.05, Time: at(2)},
+			}},
+		},
+
+		"A pending crossing of the previous state should be returned with its first crossing time.": {
+			thresholds: prometheus.DefaultBudgetThresholds,
+			state: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {
+				ErrorBudgetRemaining: 0.2,
+				Pending:              &prometheus.BudgetThresholdPending{Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(-10)},
+			}},
+			observations: [][]prometheus.SLOReport{report(f(0.2)), report(f(0.2))},
+			expCrossings: []prometheus.BudgetThresholdCrossing{
+				{SLO: slo, Threshold: 0.25, PreviousErrorBudgetRemaining: 0.3, ErrorBudgetRemaining: 0.2, Time: at(-10)},
+			},
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.2}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			tracker, err := prometheus.NewBudgetThresholdTracker(test.thresholds, test.state)
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			if !assert.NoError(err) {
+				return
+			}
+
+			gotCrossings := []prometheus.BudgetThresholdCrossing{}
+			for i, reports := range test.observations {
+				crossings := tracker.Observe([]prometheus.SLO{slo}, reports, at(i))
+				if !test.failNotify {
+					for _, c := range crossings {
+						tracker.Notified(c)
+					}
+				}
+				gotCrossings = append(gotCrossings, crossings...)
+			}
+
+			assert.Equal(test.expCrossings, gotCrossings)
+			assert.Equal(test.expState, tracker.State())
+		})
+	}
+}
+
+func TestBudgetThresholdStateUnmarshalJSON(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expState map[string]prometheus.BudgetThresholdState
+	}{
+		"A previous versions state with only the remaining error budget should be loaded.": {
+			data:     `{"svc1-slo1": 0.3}`,
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {ErrorBudgetRemaining: 0.3}},
+		},
+
+		"A state with a pending crossing should be loaded.": {
+			data: `{"svc1-slo1": {"errorBudgetRemaining": 0.2, "pending": {"threshold": 0.25, "previousErrorBudgetRemaining": 0.3, "errorBudgetRemaining": 0.2, "time": "2021-06-01T12:00:00Z"}}}`,
+			expState: map[string]prometheus.BudgetThresholdState{"svc1-slo1": {
+				ErrorBudgetRemaining: 0.2,
+				Pending: &prometheus.BudgetThresholdPending{
+					Threshold:                    0.25,
+					PreviousErrorBudgetRemaining: 0.3,
+					ErrorBudgetRemaining:         0.2,
+					Time:                         time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+				},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var gotState map[string]prometheus.BudgetThresholdState
+			err := json.Unmarshal([]byte(test.data), &gotState)
+
+			if assert.NoError(err) {
+				assert.Equal(test.expState, gotState)
+			}
+		})
+	}
+}
+
+func TestBudgetWebhookNotifierNotify(t *testing.T) {
+	crossing := prometheus.BudgetThresholdCrossing{
+		SLO: prometheus.SLO{
+			ID:         "svc1-slo1",
+			Service:    "svc1",
+			Name:       "slo1",
+			Objective:  99.9,
+			TimeWindow: 30 * 24 * time.Hour,
+			Labels:     map[string]string{"team": "payments"},
+		},
+		Threshold:                    0.25,
+		PreviousErrorBudgetRemaining: 0.3,
+		ErrorBudgetRemaining:         0.2,
+		Time:                         time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := map[string]struct {
+		statusCode int
+		expBody    string
+		expErr     bool
+	}{
+		"A budget threshold crossing should be notified as a CloudEvent.": {
+			statusCode: http.StatusAccepted,
+			expBody: `{
+  "specversion": "1.0",
+  "id": "svc1-slo1-0.25-1622548800",
+  "source": "sloth",
+  "type": "dev.slok.sloth.slo.error_budget.threshold_crossed",
+  "subject": "svc1-slo1",
+  "time": "2021-06-01T12:00:00Z",
+  "datacontenttype": "application/json",
+  "data": {
+    "slo": {
+      "id": "svc1-slo1",
+      "service": "svc1",
+      "name": "slo1",
+      "objective": 99.9,
+      "sloPeriod": "30d",
+      "labels": {"team": "payments"}
+    },
+    "threshold": 0.25,
+    "previousErrorBudgetRemaining": 0.3,
+    "errorBudgetRemaining": 0.2
+  }
+}`,
+		},
+
+		"A webhook error response should fail.": {
+			statusCode: http.StatusInternalServerError,
+			expErr:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var gotBody []byte
+			var gotContentType string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotContentType = r.Header.Get("Content-Type")
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(test.statusCode)
+			}))
+			defer srv.Close()
+
+			notifier, err := prometheus.NewBudgetWebhookNotifier(prometheus.BudgetWebhookNotifierConfig{URL: srv.URL})
+			require.NoError(err)
+
+			_, err = notifier.Notify(context.TODO(), []prometheus.BudgetThresholdCrossing{crossing})
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal("application/cloudevents+json", gotContentType)
+				assert.JSONEq(test.expBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestBudgetWebhookNotifierNotifyBatch(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	crossing := func(id string) prometheus.BudgetThresholdCrossing {
+		return prometheus.BudgetThresholdCrossing{SLO: prometheus.SLO{ID: id}, Threshold: 0.25, Time: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)}
+	}
+
+	// The second crossing notification fails.
+	gotIDs := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			ID string `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		gotIDs = append(gotIDs, event.ID)
+		if event.ID == "svc1-slo2-0.25-1622548800" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	notifier, err := prometheus.NewBudgetWebhookNotifier(prometheus.BudgetWebhookNotifierConfig{URL: srv.URL})
+	require.NoError(err)
+
+	notified, err := notifier.Notify(context.TODO(), []prometheus.BudgetThresholdCrossing{crossing("svc1-slo1"), crossing("svc1-slo2"), crossing("svc1-slo3")})
+
+	assert.Error(err)
+	assert.Equal([]string{"svc1-slo1-0.25-1622548800", "svc1-slo2-0.25-1622548800", "svc1-slo3-0.25-1622548800"}, gotIDs)
+	assert.Equal([]prometheus.BudgetThresholdCrossing{crossing("svc1-slo1"), crossing("svc1-slo3")}, notified)
+}
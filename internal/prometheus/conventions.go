@@ -2,6 +2,7 @@ package prometheus
 
 const (
 	sliErrorMetricFmt    = "slo:sli_error:ratio_rate%s"
+	sliTimesliceMetric   = "slo:sli_timeslice_error:bool"
 	sloNameLabelName     = "sloth_slo"
 	sloIDLabelName       = "sloth_id"
 	sloServiceLabelName  = "sloth_service"
@@ -10,4 +11,42 @@ const (
 	sloVersionLabelName  = "sloth_version"
 	sloModeLabelName     = "sloth_mode"
 	sloSpecLabelName     = "sloth_spec"
+
+	sloPreviousObjectiveLabelName  = "sloth_previous_objective"
+	sloPreviousTimeWindowLabelName = "sloth_previous_time_window"
+	sloChangedAtLabelName          = "sloth_changed_at"
+
+	sliRecordingsGroupNameFmt       = "sloth-slo-sli-recordings-%s"
+	sliWindowRecordingsGroupNameFmt = "sloth-slo-sli-window-recordings-%s"
+	metaRecordingsGroupNameFmt      = "sloth-slo-meta-recordings-%s"
+	alertsGroupNameFmt              = "sloth-slo-alerts-%s"
 )
+
+// Metadata recording rules metrics.
+const (
+	metricSLOObjectiveRatio                  = "slo:objective:ratio"
+	metricSLOErrorBudgetRatio                = "slo:error_budget:ratio"
+	metricSLOTimePeriodDays                  = "slo:time_period:days"
+	metricSLOCurrentBurnRateRatio            = "slo:current_burn_rate:ratio"
+	metricSLOPeriodBurnRateRatio             = "slo:period_burn_rate:ratio"
+	metricSLOPeriodErrorBudgetRemainingRatio = "slo:period_error_budget_remaining:ratio"
+	metricSLOErrorBudgetExhaustionSeconds    = "slo:error_budget:exhaustion_seconds"
+	metricSLOInfo                            = "sloth_slo_info"
+)
+
+// reservedLabelNames are the labels set by Sloth on the generated rules, users
+// can't use them because they would break the SLO identification.
+var reservedLabelNames = map[string]struct{}{
+	sloNameLabelName:     {},
+	sloIDLabelName:       {},
+	sloServiceLabelName:  {},
+	sloWindowLabelName:   {},
+	sloSeverityLabelName: {},
+	sloVersionLabelName:  {},
+	sloModeLabelName:     {},
+	sloSpecLabelName:     {},
+
+	sloPreviousObjectiveLabelName:  {},
+	sloPreviousTimeWindowLabelName: {},
+	sloChangedAtLabelName:          {},
+}
@@ -0,0 +1,63 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CostLabelsAllowlist are the allowed SLO cost attribution labels with their allowed
+// values, a label without values allows any value (e.g `cost_center: [cc-1, cc-2]`).
+type CostLabelsAllowlist map[string][]string
+
+// NewCostLabelsAllowlistFromYAML loads a cost labels allowlist from YAML data.
+func NewCostLabelsAllowlistFromYAML(data []byte) (CostLabelsAllowlist, error) {
+	allowlist := CostLabelsAllowlist{}
+	err := yaml.UnmarshalStrict(data, &allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal YAML cost labels allowlist: %w", err)
+	}
+
+	return allowlist, nil
+}
+
+// Validate validates that the cost labels of the SLOs are allowed. A nil allowlist
+// allows any cost label.
+func (c CostLabelsAllowlist) Validate(slos []SLO) error {
+	if c == nil {
+		return nil
+	}
+
+	for _, slo := range slos {
+		// Sort for deterministic errors.
+		keys := make([]string, 0, len(slo.CostLabels))
+		for k := range slo.CostLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			values, ok := c[k]
+			if !ok {
+				return fmt.Errorf("%q SLO cost label %q is not allowed", slo.ID, k)
+			}
+
+			if len(values) > 0 && !stringInSlice(slo.CostLabels[k], values) {
+				return fmt.Errorf("%q SLO cost label %q value %q is not allowed", slo.ID, k, slo.CostLabels[k])
+			}
+		}
+	}
+
+	return nil
+}
+
+func stringInSlice(s string, ss []string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
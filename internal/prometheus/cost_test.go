@@ -0,0 +1,71 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestCostLabelsAllowlistValidate(t *testing.T) {
+	tests := map[string]struct {
+		allowlist string
+		slos      []prometheus.SLO
+		expErr    bool
+	}{
+		"Having an invalid allowlist should fail.": {
+			allowlist: `cost_center: {a: b}`,
+			expErr:    true,
+		},
+
+		"Having SLOs without cost labels should not fail.": {
+			allowlist: `cost_center: [cc-1]`,
+			slos:      []prometheus.SLO{{ID: "slo1"}},
+		},
+
+		"Having SLOs with allowed cost labels should not fail.": {
+			allowlist: `
+cost_center: [cc-1, cc-2]
+product:
+`,
+			slos: []prometheus.SLO{
+				{ID: "slo1", CostLabels: map[string]string{"cost_center": "cc-1", "product": "checkout"}},
+				{ID: "slo2", CostLabels: map[string]string{"cost_center": "cc-2", "product": "payments"}},
+			},
+		},
+
+		"Having SLOs with a not allowed cost label should fail.": {
+			allowlist: `cost_center: [cc-1]`,
+			slos: []prometheus.SLO{
+				{ID: "slo1", CostLabels: map[string]string{"cost_center": "cc-1", "product": "checkout"}},
+			},
+			expErr: true,
+		},
+
+		"Having SLOs with a not allowed cost label value should fail.": {
+			allowlist: `cost_center: [cc-1]`,
+			slos: []prometheus.SLO{
+				{ID: "slo1", CostLabels: map[string]string{"cost_center": "cc-3"}},
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			allowlist, err := prometheus.NewCostLabelsAllowlistFromYAML([]byte(test.allowlist))
+			if err == nil {
+				err = allowlist.Validate(test.slos)
+			}
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+)
+
+var humanDurationUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour, "year": 365 * 24 * time.Hour, "years": 365 * 24 * time.Hour,
+}
+
+var humanDurationPartRegexp = regexp.MustCompile(`^([0-9]+) *([a-z]+)$`)
+
+// ParseDuration parses a Prometheus duration (e.g `5m`, `1h30m`) or a human-friendly
+// duration (e.g `5 minutes`, `30 days`, `1 hour 30 minutes`).
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if d, err := prommodel.ParseDuration(s); err == nil {
+		return time.Duration(d), nil
+	}
+
+	// Human-friendly notation, split the parts (e.g `1 hour, 30 minutes` or `1 hour and 30 minutes`).
+	fields := strings.Fields(strings.NewReplacer(",", " ", " and ", " ").Replace(s))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var d time.Duration
+	for len(fields) > 0 {
+		// Support both `5 minutes` and `5minutes`.
+		part := fields[0]
+		fields = fields[1:]
+		if _, err := strconv.Atoi(part); err == nil && len(fields) > 0 {
+			part += fields[0]
+			fields = fields[1:]
+		}
+
+		m := humanDurationPartRegexp.FindStringSubmatch(part)
+		if m == nil {
+			return 0, fmt.Errorf("invalid duration %q, should be a Prometheus duration (e.g 5m) or human-friendly (e.g 5 minutes)", s)
+		}
+		unit, ok := humanDurationUnits[m[2]]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q, unknown %q unit", s, m[2])
+		}
+		n, _ := strconv.Atoi(m[1])
+		d += time.Duration(n) * unit
+	}
+
+	return d, nil
+}
@@ -0,0 +1,72 @@
+package prometheus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]struct {
+		duration    string
+		expDuration time.Duration
+		expErr      bool
+	}{
+		"An empty duration should fail.": {
+			duration: " ",
+			expErr:   true,
+		},
+
+		"A Prometheus duration should be parsed.": {
+			duration:    "1h30m",
+			expDuration: 90 * time.Minute,
+		},
+
+		"A human-friendly duration should be parsed.": {
+			duration:    "30 days",
+			expDuration: 30 * 24 * time.Hour,
+		},
+
+		"A human-friendly duration without spaces should be parsed.": {
+			duration:    "5minutes",
+			expDuration: 5 * time.Minute,
+		},
+
+		"A human-friendly duration with multiple parts should be parsed.": {
+			duration:    "1 Hour, 30 minutes and 10 secs",
+			expDuration: 90*time.Minute + 10*time.Second,
+		},
+
+		"A human-friendly duration with an unknown unit should fail.": {
+			duration: "5 fortnights",
+			expErr:   true,
+		},
+
+		"A human-friendly duration without unit should fail.": {
+			duration: "5",
+			expErr:   true,
+		},
+
+		"A human-friendly duration with decimals should fail.": {
+			duration: "1.5 hours",
+			expErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotDuration, err := prometheus.ParseDuration(test.duration)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expDuration, gotDuration)
+			}
+		})
+	}
+}
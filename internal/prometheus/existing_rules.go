@@ -0,0 +1,106 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"gopkg.in/yaml.v2"
+)
+
+// ExistingRules are the non Sloth rules of an existing Prometheus rules file, used to
+// detect name collisions before writing the generated rules on shared rule namespaces.
+// Sloth rules are identified by the Sloth rule group names (`sloth-slo-` prefix) or
+// the Sloth labels (`sloth_` prefix), not all the Sloth rules have the `sloth_id` label
+// (e.g the SLI period recording rule or the alerts).
+type ExistingRules struct {
+	// Records are the existing non Sloth recording rule names.
+	Records map[string]bool
+	// Alerts are the existing non Sloth alert rule names.
+	Alerts map[string]bool
+}
+
+const (
+	slothGroupNamePrefix = "sloth-slo-"
+	slothLabelNamePrefix = "sloth_"
+)
+
+// NewExistingRulesFromYAML loads the existing rules from Prometheus rules YAML data.
+func NewExistingRulesFromYAML(data []byte) (*ExistingRules, error) {
+	ruleGroups := ruleGroupsYAMLv2{}
+	err := yaml.Unmarshal(data, &ruleGroups)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal YAML existing rules: %w", err)
+	}
+
+	existing := &ExistingRules{
+		Records: map[string]bool{},
+		Alerts:  map[string]bool{},
+	}
+	for _, group := range ruleGroups.Groups {
+		if strings.HasPrefix(group.Name, slothGroupNamePrefix) {
+			continue
+		}
+
+		for _, rule := range group.Rules {
+			if isSlothRule(rule) {
+				continue
+			}
+
+			if rule.Record != "" {
+				existing.Records[rule.Record] = true
+			}
+			if rule.Alert != "" {
+				existing.Alerts[rule.Alert] = true
+			}
+		}
+	}
+
+	return existing, nil
+}
+
+func isSlothRule(rule rulefmt.Rule) bool {
+	for k := range rule.Labels {
+		if strings.HasPrefix(k, slothLabelNamePrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check checks that the generated SLO rules don't collide with the existing non Sloth
+// rules, a nil existing rules doesn't check anything.
+func (e *ExistingRules) Check(slos []StorageSLO) error {
+	if e == nil {
+		return nil
+	}
+
+	collisions := map[string]bool{}
+	for _, slo := range slos {
+		for _, rules := range [][]rulefmt.Rule{slo.Rules.SLIErrorRecRules, slo.Rules.MetadataRecRules, slo.Rules.AlertRules} {
+			for _, r := range rules {
+				if r.Record != "" && e.Records[r.Record] {
+					collisions[fmt.Sprintf("%q SLO recording rule %q", slo.SLO.ID, r.Record)] = true
+				}
+				if r.Alert != "" && e.Alerts[r.Alert] {
+					collisions[fmt.Sprintf("%q SLO alert rule %q", slo.SLO.ID, r.Alert)] = true
+				}
+			}
+		}
+	}
+
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	// Sort for deterministic errors.
+	msgs := make([]string, 0, len(collisions))
+	for msg := range collisions {
+		msgs = append(msgs, msg)
+	}
+	sort.Strings(msgs)
+
+	return fmt.Errorf("generated rules collide with existing non Sloth rules: %s", strings.Join(msgs, ", "))
+}
@@ -0,0 +1,135 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestExistingRulesCheck(t *testing.T) {
+	existingRules := `
+groups:
+  - name: sloth-slo-sli-recordings-svc01-slo1
+    rules:
+      - record: slo:sli_error:ratio_rate5m
+        expr: vector(1)
+        labels:
+          sloth_id: svc01-slo1
+  - name: sloth-slo-alerts-svc01-slo2
+    rules:
+      - alert: MyServiceDown
+        expr: up == 0
+  - name: sloth-custom-group
+    rules:
+      - alert: MyServiceSLOHighErrorRate
+        expr: vector(1)
+        labels:
+          sloth_severity: page
+  - name: team-rules
+    rules:
+      - record: slo:objective:ratio
+        expr: vector(0.99)
+      - alert: MyServiceHighErrorRate
+        expr: vector(1)
+`
+
+	tests := map[string]struct {
+		existingRules string
+		slos          []prometheus.StorageSLO
+		expErr        bool
+	}{
+		"Having invalid existing rules should fail.": {
+			existingRules: `groups: {a: b}`,
+			expErr:        true,
+		},
+
+		"Having generated rules colliding only with Sloth rules should not fail.": {
+			existingRules: existingRules,
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "svc01-slo1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{{Record: "slo:sli_error:ratio_rate5m"}},
+						AlertRules:       []rulefmt.Rule{{Alert: "OtherAlert"}},
+					},
+				},
+			},
+		},
+
+		"Having generated rules colliding with rules of a Sloth rule group should not fail.": {
+			existingRules: existingRules,
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "svc01-slo2"},
+					Rules: prometheus.SLORules{
+						AlertRules: []rulefmt.Rule{{Alert: "MyServiceDown"}},
+					},
+				},
+			},
+		},
+
+		"Having generated rules colliding with rules that have Sloth labels should not fail.": {
+			existingRules: existingRules,
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "svc01-slo2"},
+					Rules: prometheus.SLORules{
+						AlertRules: []rulefmt.Rule{{Alert: "MyServiceSLOHighErrorRate"}},
+					},
+				},
+			},
+		},
+
+		"Having generated rules colliding with a non Sloth recording rule should fail.": {
+			existingRules: existingRules,
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "svc01-slo1"},
+					Rules: prometheus.SLORules{
+						MetadataRecRules: []rulefmt.Rule{{Record: "slo:objective:ratio"}},
+					},
+				},
+			},
+			expErr: true,
+		},
+
+		"Having generated rules colliding with a non Sloth alert rule should fail.": {
+			existingRules: existingRules,
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "svc01-slo1"},
+					Rules: prometheus.SLORules{
+						AlertRules: []rulefmt.Rule{{Alert: "MyServiceHighErrorRate"}},
+					},
+				},
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			existing, err := prometheus.NewExistingRulesFromYAML([]byte(test.existingRules))
+			if err == nil {
+				err = existing.Check(test.slos)
+			}
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestExistingRulesCheckNil(t *testing.T) {
+	var existing *prometheus.ExistingRules
+	err := existing.Check([]prometheus.StorageSLO{{SLO: prometheus.SLO{ID: "svc01-slo1"}}})
+	assert.NoError(t, err)
+}
@@ -1,10 +1,12 @@
 package prometheus
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
 	prommodel "github.com/prometheus/common/model"
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
 
 	"github.com/slok/sloth/internal/alert"
 )
@@ -29,6 +31,42 @@ func labelsToPromFilter(labels map[string]string) string {
 	return metricFilters.String()
 }
 
+// applyPromExprOffset adds the offset to all the selectors of the Prometheus expression,
+// if the offset is 0 the expression will be returned untouched.
+func applyPromExprOffset(expr string, offset time.Duration) (string, error) {
+	if offset == 0 {
+		return expr, nil
+	}
+
+	ast, err := parsePromExpr(expr)
+	if err != nil {
+		return "", fmt.Errorf("could not parse expression: %w", err)
+	}
+
+	err = promqlparser.Walk(offsetVisitor(offset), ast, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return ast.String(), nil
+}
+
+// offsetVisitor is a Prometheus expression AST visitor that adds an offset to the selectors.
+type offsetVisitor time.Duration
+
+func (o offsetVisitor) Visit(node promqlparser.Node, path []promqlparser.Node) (promqlparser.Visitor, error) {
+	switch n := node.(type) {
+	case *promqlparser.VectorSelector:
+		n.OriginalOffset += time.Duration(o)
+	case *promqlparser.SubqueryExpr:
+		// The subquery offset already moves its inner expression, don't visit it.
+		n.OriginalOffset += time.Duration(o)
+		return nil, nil
+	}
+
+	return o, nil
+}
+
 // Pretty simple durations for prometheus.
 func timeDurationToPromStr(t time.Duration) string {
 	return prommodel.Duration(t).String()
@@ -48,6 +86,39 @@ func getAlertGroupWindows(alerts alert.MWMBAlertGroup) []time.Duration {
 		alerts.TicketSlow.LongWindow.String():   alerts.TicketSlow.LongWindow,
 	}
 
+	return sortedWindows(windows)
+}
+
+// getEnabledAlertsWindows returns the windows of the SLO enabled alerts.
+func getEnabledAlertsWindows(slo SLO, alerts alert.MWMBAlertGroup) []time.Duration {
+	windows := map[string]time.Duration{}
+	if !slo.PageAlertMeta.Disable {
+		for _, a := range []alert.MWMBAlert{alerts.PageQuick, alerts.PageSlow} {
+			windows[a.ShortWindow.String()] = a.ShortWindow
+			windows[a.LongWindow.String()] = a.LongWindow
+		}
+	}
+	if !slo.TicketAlertMeta.Disable {
+		for _, a := range []alert.MWMBAlert{alerts.TicketQuick, alerts.TicketSlow} {
+			windows[a.ShortWindow.String()] = a.ShortWindow
+			windows[a.LongWindow.String()] = a.LongWindow
+		}
+	}
+
+	return sortedWindows(windows)
+}
+
+func containsWindow(windows []time.Duration, window time.Duration) bool {
+	for _, w := range windows {
+		if w == window {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedWindows(windows map[string]time.Duration) []time.Duration {
 	res := make([]time.Duration, 0, len(windows))
 	for _, w := range windows {
 		res = append(res, w)
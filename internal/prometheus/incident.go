@@ -0,0 +1,89 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/slok/sloth/internal/alert"
+	"github.com/slok/sloth/pkg/budget"
+)
+
+// SampleQuerier knows how to get the value of a Prometheus instant query that
+// returns a single sample.
+type SampleQuerier interface {
+	QuerySample(ctx context.Context, query string) (float64, error)
+}
+
+//go:generate mockery --case underscore --output prometheusmock --outpkg prometheusmock --name SampleQuerier
+
+// WindowBurn is the error budget burn of an SLO on a time window.
+type WindowBurn struct {
+	Window     time.Duration
+	ErrorRatio float64
+	// BurnRate is the error budget burn speed, 1 means that the error budget would be
+	// consumed exactly at the end of the SLO time window.
+	BurnRate float64
+}
+
+// IncidentReport is the current error budget burn state of an SLO.
+type IncidentReport struct {
+	SLO     SLO
+	Windows []WindowBurn
+	// CurrentBurnRate is the burn rate of the shortest alert window.
+	CurrentBurnRate float64
+	// ErrorBudgetRemaining is the remaining ratio of the SLO time window error budget.
+	ErrorBudgetRemaining float64
+	// Exhaustion is the projected time until the error budget is exhausted at the current
+	// burn rate, 0 if the error budget is already exhausted or it's not being burned.
+	Exhaustion time.Duration
+}
+
+// NewIncidentReport returns the current error budget burn state of an SLO using the
+// SLI error recording rules of the SLO alert windows.
+func NewIncidentReport(ctx context.Context, querier SampleQuerier, slo SLO, alerts alert.MWMBAlertGroup) (*IncidentReport, error) {
+	if budget.ErrorBudgetRatio(slo.Objective) <= 0 {
+		return nil, fmt.Errorf("SLO %q doesn't have error budget", slo.ID)
+	}
+
+	getBurn := func(window time.Duration) (*WindowBurn, error) {
+		query := slo.GetSLIErrorMetric(window) + labelsToPromFilter(slo.GetSLOIDPromLabels())
+		errorRatio, err := querier.QuerySample(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("could not get %q SLO %s window SLI error ratio: %w", slo.ID, window, err)
+		}
+
+		// Without events the SLI error ratio is not a number (0/0).
+		if math.IsNaN(errorRatio) {
+			errorRatio = 0
+		}
+
+		return &WindowBurn{
+			Window:     window,
+			ErrorRatio: errorRatio,
+			BurnRate:   budget.BurnRate(errorRatio, slo.Objective),
+		}, nil
+	}
+
+	report := &IncidentReport{SLO: slo}
+	for _, window := range getAlertGroupWindows(alerts) {
+		burn, err := getBurn(window)
+		if err != nil {
+			return nil, err
+		}
+		report.Windows = append(report.Windows, *burn)
+	}
+	if len(report.Windows) > 0 {
+		report.CurrentBurnRate = report.Windows[0].BurnRate
+	}
+
+	periodBurn, err := getBurn(slo.TimeWindow)
+	if err != nil {
+		return nil, err
+	}
+	report.ErrorBudgetRemaining = 1 - budget.BudgetConsumed(periodBurn.BurnRate, slo.TimeWindow, slo.TimeWindow)
+	report.Exhaustion = budget.TimeToExhaustion(slo.TimeWindow, report.ErrorBudgetRemaining, report.CurrentBurnRate).Round(time.Minute)
+
+	return report, nil
+}
@@ -0,0 +1,128 @@
+package prometheus_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/internal/prometheus/prometheusmock"
+)
+
+func TestNewIncidentReport(t *testing.T) {
+	slo := prometheus.SLO{
+		ID:         "svc-slo1",
+		Name:       "slo1",
+		Service:    "svc",
+		Objective:  75,
+		TimeWindow: 30 * 24 * time.Hour,
+	}
+
+	// Returns the SLI error ratios by window.
+	sliErrors := func(short, others, period float64) func(ctx context.Context, query string) float64 {
+		return func(ctx context.Context, query string) float64 {
+			switch query {
+			case `slo:sli_error:ratio_rate5m{sloth_id="svc-slo1", sloth_service="svc", sloth_slo="slo1"}`:
+				return short
+			case `slo:sli_error:ratio_rate30d{sloth_id="svc-slo1", sloth_service="svc", sloth_slo="slo1"}`:
+				return period
+			}
+			return others
+		}
+	}
+
+	tests := map[string]struct {
+		mock      func(m *prometheusmock.SampleQuerier)
+		expReport *prometheus.IncidentReport
+		expErr    bool
+	}{
+		"Having an error while querying should fail.": {
+			mock: func(m *prometheusmock.SampleQuerier) {
+				m.On("QuerySample", mock.Anything, mock.Anything).Once().Return(float64(0), fmt.Errorf("something"))
+			},
+			expErr: true,
+		},
+
+		"Having an SLO burning error budget should return the projected error budget exhaustion.": {
+			mock: func(m *prometheusmock.SampleQuerier) {
+				m.On("QuerySample", mock.Anything, mock.Anything).Return(sliErrors(0.5, 0.25, 0.125), nil)
+			},
+			expReport: &prometheus.IncidentReport{
+				SLO: slo,
+				Windows: []prometheus.WindowBurn{
+					{Window: 5 * time.Minute, ErrorRatio: 0.5, BurnRate: 2},
+					{Window: 30 * time.Minute, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 1 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 2 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 6 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 24 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 72 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+				},
+				CurrentBurnRate:      2,
+				ErrorBudgetRemaining: 0.5,
+				Exhaustion:           180 * time.Hour,
+			},
+		},
+
+		"Having an SLO without events, should not project the error budget exhaustion.": {
+			mock: func(m *prometheusmock.SampleQuerier) {
+				m.On("QuerySample", mock.Anything, mock.Anything).Return(sliErrors(math.NaN(), 0, 0), nil)
+			},
+			expReport: &prometheus.IncidentReport{
+				SLO: slo,
+				Windows: []prometheus.WindowBurn{
+					{Window: 5 * time.Minute},
+					{Window: 30 * time.Minute},
+					{Window: 1 * time.Hour},
+					{Window: 2 * time.Hour},
+					{Window: 6 * time.Hour},
+					{Window: 24 * time.Hour},
+					{Window: 72 * time.Hour},
+				},
+				ErrorBudgetRemaining: 1,
+			},
+		},
+
+		"Having an SLO with the error budget exhausted, should not project the error budget exhaustion.": {
+			mock: func(m *prometheusmock.SampleQuerier) {
+				m.On("QuerySample", mock.Anything, mock.Anything).Return(sliErrors(0.5, 0.25, 0.5), nil)
+			},
+			expReport: &prometheus.IncidentReport{
+				SLO: slo,
+				Windows: []prometheus.WindowBurn{
+					{Window: 5 * time.Minute, ErrorRatio: 0.5, BurnRate: 2},
+					{Window: 30 * time.Minute, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 1 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 2 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 6 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 24 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+					{Window: 72 * time.Hour, ErrorRatio: 0.25, BurnRate: 1},
+				},
+				CurrentBurnRate:      2,
+				ErrorBudgetRemaining: -1,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			m := &prometheusmock.SampleQuerier{}
+			test.mock(m)
+
+			gotReport, err := prometheus.NewIncidentReport(context.TODO(), m, slo, getAlertGroup())
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expReport, gotReport)
+			}
+		})
+	}
+}
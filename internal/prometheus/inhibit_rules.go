@@ -0,0 +1,154 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/slok/sloth/internal/alert"
+)
+
+// InhibitRule is an Alertmanager inhibition rule.
+type InhibitRule struct {
+	SourceMatchers []string `yaml:"source_matchers"`
+	TargetMatchers []string `yaml:"target_matchers"`
+	Equal          []string `yaml:"equal,omitempty"`
+}
+
+type inhibitRulesGenerator bool
+
+// InhibitRulesGenerator knows how to generate the Alertmanager inhibition rules
+// from SLOs:
+// - The page alert of an SLO inhibits the ticket alert of the same SLO.
+// - The page alert of a dependency SLO inhibits the page alert of the SLOs that depend on it.
+const InhibitRulesGenerator = inhibitRulesGenerator(false)
+
+func (i inhibitRulesGenerator) GenerateInhibitRules(ctx context.Context, slos []SLO) ([]InhibitRule, error) {
+	// Dependency cycles would inhibit the page alerts of all the SLOs on the cycle.
+	err := checkDependencyCycles(slos)
+	if err != nil {
+		return nil, err
+	}
+
+	page := alert.PageAlertSeverity.String()
+	ticket := alert.TicketAlertSeverity.String()
+
+	rules := []InhibitRule{}
+	for _, slo := range slos {
+		if slo.PageAlertMeta.Disable {
+			continue
+		}
+
+		// Page alert inhibits the ticket alert of the same SLO.
+		if !slo.TicketAlertMeta.Disable {
+			rules = append(rules, InhibitRule{
+				SourceMatchers: inhibitMatchers(slo.ID, page),
+				TargetMatchers: inhibitMatchers(slo.ID, ticket),
+			})
+		}
+
+		// Dependency page alerts inhibit this SLO page alert.
+		deps := append([]string{}, slo.DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			rules = append(rules, InhibitRule{
+				SourceMatchers: inhibitMatchers(dep, page),
+				TargetMatchers: inhibitMatchers(slo.ID, page),
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+// checkDependencyCycles fails if the SLOs dependencies have a cycle.
+func checkDependencyCycles(slos []SLO) error {
+	deps := map[string][]string{}
+	ids := make([]string, 0, len(slos))
+	for _, slo := range slos {
+		for _, dep := range slo.DependsOn {
+			if dep == slo.ID {
+				return fmt.Errorf("%q SLO can't depend on itself", slo.ID)
+			}
+		}
+		d := append([]string{}, slo.DependsOn...)
+		sort.Strings(d)
+		deps[slo.ID] = d
+		ids = append(ids, slo.ID)
+	}
+	sort.Strings(ids)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	path := []string{}
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			// Get the cycle from the path.
+			start := 0
+			for i, p := range path {
+				if p == id {
+					start = i
+				}
+			}
+			return fmt.Errorf("SLOs dependency cycle: %s", strings.Join(append(path[start:], id), " -> "))
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range deps[id] {
+			err := visit(dep)
+			if err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+
+		return nil
+	}
+
+	for _, id := range ids {
+		err := visit(id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnknownDependencies returns the SLOs `depends_on` SLO IDs that are not any of the SLOs, by
+// SLO ID. These dependencies are still inhibiting the SLOs (e.g SLOs generated on other
+// Sloth executions), but they could be typos.
+func UnknownDependencies(slos []SLO) map[string][]string {
+	ids := map[string]bool{}
+	for _, slo := range slos {
+		ids[slo.ID] = true
+	}
+
+	unknown := map[string][]string{}
+	for _, slo := range slos {
+		for _, dep := range slo.DependsOn {
+			if !ids[dep] {
+				unknown[slo.ID] = append(unknown[slo.ID], dep)
+			}
+		}
+	}
+
+	return unknown
+}
+
+func inhibitMatchers(sloID, severity string) []string {
+	return []string{
+		fmt.Sprintf("%s=%q", sloIDLabelName, sloID),
+		fmt.Sprintf("%s=%q", sloSeverityLabelName, severity),
+	}
+}
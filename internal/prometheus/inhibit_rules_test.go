@@ -0,0 +1,160 @@
+package prometheus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestGenerateInhibitRules(t *testing.T) {
+	tests := map[string]struct {
+		slos     []prometheus.SLO
+		expRules []prometheus.InhibitRule
+		expErr   bool
+	}{
+		"Having an SLO with page and ticket alerts, page should inhibit ticket.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1"},
+			},
+			expRules: []prometheus.InhibitRule{
+				{
+					SourceMatchers: []string{`sloth_id="svc-slo1"`, `sloth_severity="page"`},
+					TargetMatchers: []string{`sloth_id="svc-slo1"`, `sloth_severity="ticket"`},
+				},
+			},
+		},
+
+		"Having an SLO with the page alert disabled, shouldn't generate inhibit rules.": {
+			slos: []prometheus.SLO{
+				{
+					ID:            "svc-slo1",
+					PageAlertMeta: prometheus.AlertMeta{Disable: true},
+					DependsOn:     []string{"svc-slo2"},
+				},
+			},
+			expRules: []prometheus.InhibitRule{},
+		},
+
+		"Having an SLO with dependencies, the dependencies page alerts should inhibit the SLO page alert.": {
+			slos: []prometheus.SLO{
+				{
+					ID:              "svc-slo1",
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					DependsOn:       []string{"svc3-slo3", "svc2-slo2"},
+				},
+			},
+			expRules: []prometheus.InhibitRule{
+				{
+					SourceMatchers: []string{`sloth_id="svc2-slo2"`, `sloth_severity="page"`},
+					TargetMatchers: []string{`sloth_id="svc-slo1"`, `sloth_severity="page"`},
+				},
+				{
+					SourceMatchers: []string{`sloth_id="svc3-slo3"`, `sloth_severity="page"`},
+					TargetMatchers: []string{`sloth_id="svc-slo1"`, `sloth_severity="page"`},
+				},
+			},
+		},
+
+		"Having an SLO that depends on itself should fail.": {
+			slos: []prometheus.SLO{
+				{
+					ID:        "svc-slo1",
+					DependsOn: []string{"svc-slo1"},
+				},
+			},
+			expErr: true,
+		},
+
+		"Having SLOs with a dependency cycle should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", DependsOn: []string{"svc-slo2"}},
+				{ID: "svc-slo2", DependsOn: []string{"svc-slo3"}},
+				{ID: "svc-slo3", DependsOn: []string{"svc-slo1"}},
+			},
+			expErr: true,
+		},
+
+		"Having SLOs with a dependency cycle on an SLO with the page alert disabled should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", DependsOn: []string{"svc-slo2"}},
+				{ID: "svc-slo2", DependsOn: []string{"svc-slo1"}, PageAlertMeta: prometheus.AlertMeta{Disable: true}},
+			},
+			expErr: true,
+		},
+
+		"Having SLOs with shared dependencies without cycles, should generate the inhibit rules.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", DependsOn: []string{"svc-slo3"}, TicketAlertMeta: prometheus.AlertMeta{Disable: true}},
+				{ID: "svc-slo2", DependsOn: []string{"svc-slo3"}, TicketAlertMeta: prometheus.AlertMeta{Disable: true}},
+				{ID: "svc-slo3", TicketAlertMeta: prometheus.AlertMeta{Disable: true}},
+			},
+			expRules: []prometheus.InhibitRule{
+				{
+					SourceMatchers: []string{`sloth_id="svc-slo3"`, `sloth_severity="page"`},
+					TargetMatchers: []string{`sloth_id="svc-slo1"`, `sloth_severity="page"`},
+				},
+				{
+					SourceMatchers: []string{`sloth_id="svc-slo3"`, `sloth_severity="page"`},
+					TargetMatchers: []string{`sloth_id="svc-slo2"`, `sloth_severity="page"`},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotRules, err := prometheus.InhibitRulesGenerator.GenerateInhibitRules(context.TODO(), test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expRules, gotRules)
+			}
+		})
+	}
+}
+
+func TestUnknownDependencies(t *testing.T) {
+	tests := map[string]struct {
+		slos    []prometheus.SLO
+		expDeps map[string][]string
+	}{
+		"Having SLOs without dependencies, shouldn't return unknown dependencies.": {
+			slos:    []prometheus.SLO{{ID: "svc-slo1"}},
+			expDeps: map[string][]string{},
+		},
+
+		"Having SLOs that depend on loaded SLOs, shouldn't return unknown dependencies.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", DependsOn: []string{"svc-slo2"}},
+				{ID: "svc-slo2"},
+			},
+			expDeps: map[string][]string{},
+		},
+
+		"Having SLOs that depend on not loaded SLOs, should return the unknown dependencies by SLO.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", DependsOn: []string{"svc-slo2", "svc-slo3"}},
+				{ID: "svc-slo2", DependsOn: []string{"svc-slo4"}},
+			},
+			expDeps: map[string][]string{
+				"svc-slo1": {"svc-slo3"},
+				"svc-slo2": {"svc-slo4"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotDeps := prometheus.UnknownDependencies(test.slos)
+			assert.Equal(test.expDeps, gotDeps)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// LabelValuesRegistryConfig is the configuration of the label values registry.
+type LabelValuesRegistryConfig struct {
+	// URL is the URL of the registry service, the label values are checked with a
+	// `GET <URL>?label=<label>&value=<value>` request, a 200 response means the value
+	// exists and a 404 response means it doesn't exist.
+	URL string
+	// Labels are the SLO labels (e.g `team`, `product`) validated against the registry.
+	Labels []string
+	// CacheTTL is the time the registry responses are cached. If 0, it will use 5m.
+	CacheTTL   time.Duration
+	HTTPClient *http.Client
+}
+
+func (c *LabelValuesRegistryConfig) defaults() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %q registry URL", c.URL)
+	}
+
+	if len(c.Labels) == 0 {
+		return fmt.Errorf("at least one label is required")
+	}
+
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("cache TTL can't be negative")
+	}
+
+	if c.CacheTTL == 0 {
+		c.CacheTTL = 5 * time.Minute
+	}
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+
+	return nil
+}
+
+// LabelValuesRegistry validates the SLO label values (e.g team names) against an external
+// HTTP allowlist service, so the SLOs can't reference nonexistent values that break the
+// alert routing and the ownership reporting.
+type LabelValuesRegistry struct {
+	url        *url.URL
+	labels     []string
+	cacheTTL   time.Duration
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[labelValue]labelValueCacheEntry
+}
+
+type labelValue struct {
+	label string
+	value string
+}
+
+type labelValueCacheEntry struct {
+	exists  bool
+	expires time.Time
+}
+
+// NewLabelValuesRegistry returns a new label values registry.
+func NewLabelValuesRegistry(config LabelValuesRegistryConfig) (*LabelValuesRegistry, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid label values registry configuration: %w", err)
+	}
+
+	u, _ := url.Parse(config.URL)
+	return &LabelValuesRegistry{
+		url:        u,
+		labels:     config.Labels,
+		cacheTTL:   config.CacheTTL,
+		httpClient: config.HTTPClient,
+		cache:      map[labelValue]labelValueCacheEntry{},
+	}, nil
+}
+
+// Validate validates that the registry labels values of the SLOs (labels and cost labels)
+// exist on the registry. A nil registry allows any value.
+func (l *LabelValuesRegistry) Validate(ctx context.Context, slos []SLO) error {
+	if l == nil {
+		return nil
+	}
+
+	for _, slo := range slos {
+		for _, label := range l.labels {
+			for _, labels := range []map[string]string{slo.Labels, slo.CostLabels} {
+				value, ok := labels[label]
+				if !ok {
+					continue
+				}
+
+				exists, err := l.exists(ctx, labelValue{label: label, value: value})
+				if err != nil {
+					return fmt.Errorf("could not check %q SLO label %q value %q: %w", slo.ID, label, value, err)
+				}
+				if !exists {
+					return fmt.Errorf("%q SLO label %q value %q doesn't exist on the registry", slo.ID, label, value)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *LabelValuesRegistry) exists(ctx context.Context, lv labelValue) (bool, error) {
+	l.mu.Lock()
+	entry, ok := l.cache[lv]
+	l.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.exists, nil
+	}
+
+	u := *l.url
+	q := u.Query()
+	q.Set("label", lv.label)
+	q.Set("value", lv.value)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false, fmt.Errorf("could not create request: %w", err)
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var exists bool
+	switch resp.StatusCode {
+	case http.StatusOK:
+		exists = true
+	case http.StatusNotFound:
+		exists = false
+	default:
+		return false, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	l.mu.Lock()
+	l.cache[lv] = labelValueCacheEntry{exists: exists, expires: time.Now().Add(l.cacheTTL)}
+	l.mu.Unlock()
+
+	return exists, nil
+}
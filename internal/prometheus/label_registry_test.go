@@ -0,0 +1,107 @@
+package prometheus_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestLabelValuesRegistryValidate(t *testing.T) {
+	tests := map[string]struct {
+		labels      []string
+		slos        []prometheus.SLO
+		expErr      bool
+		expRequests int32
+	}{
+		"Having SLOs without the registry labels should not fail.": {
+			labels:      []string{"team"},
+			slos:        []prometheus.SLO{{ID: "slo1", Labels: map[string]string{"owner": "nobody"}}},
+			expRequests: 0,
+		},
+
+		"Having SLOs with existing label values should not fail and cache the registry responses.": {
+			labels: []string{"team", "product"},
+			slos: []prometheus.SLO{
+				{ID: "slo1", Labels: map[string]string{"team": "payments"}, CostLabels: map[string]string{"product": "checkout"}},
+				{ID: "slo2", Labels: map[string]string{"team": "payments"}},
+			},
+			expRequests: 2,
+		},
+
+		"Having SLOs with a nonexistent label value should fail.": {
+			labels: []string{"team"},
+			slos: []prometheus.SLO{
+				{ID: "slo1", Labels: map[string]string{"team": "payments"}},
+				{ID: "slo2", Labels: map[string]string{"team": "unknown"}},
+			},
+			expErr:      true,
+			expRequests: 2,
+		},
+
+		"Having a registry error response should fail.": {
+			labels:      []string{"team"},
+			slos:        []prometheus.SLO{{ID: "slo1", Labels: map[string]string{"team": "broken"}}},
+			expErr:      true,
+			expRequests: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				label, value := r.URL.Query().Get("label"), r.URL.Query().Get("value")
+				switch {
+				case label == "team" && value == "payments", label == "product" && value == "checkout":
+					w.WriteHeader(http.StatusOK)
+				case value == "broken":
+					w.WriteHeader(http.StatusInternalServerError)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			registry, err := prometheus.NewLabelValuesRegistry(prometheus.LabelValuesRegistryConfig{
+				URL:    server.URL + "/labels",
+				Labels: test.labels,
+			})
+			require.NoError(err)
+
+			err = registry.Validate(context.TODO(), test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(test.expRequests, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestNewLabelValuesRegistryInvalidConfig(t *testing.T) {
+	tests := map[string]prometheus.LabelValuesRegistryConfig{
+		"Missing URL should fail.":    {Labels: []string{"team"}},
+		"Invalid URL should fail.":    {URL: "registry:8080", Labels: []string{"team"}},
+		"Missing labels should fail.": {URL: "http://registry:8080"},
+	}
+
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := prometheus.NewLabelValuesRegistry(config)
+			assert.Error(t, err)
+		})
+	}
+}
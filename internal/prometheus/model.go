@@ -12,12 +12,27 @@ import (
 	prommodel "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/rulefmt"
 	promqlparser "github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/slok/sloth/internal/alert"
 )
 
 // SLI reprensents an SLI with custom error and total expressions.
 type SLI struct {
 	Raw    *SLIRaw
 	Events *SLIEvents
+	// Offset is applied to all the SLI expression selectors to tolerate late data.
+	Offset time.Duration `validate:"gte=0"`
+	// Examples are series values with their expected SLI error ratio, used to test the
+	// SLI queries logic without a Prometheus.
+	Examples []SLIExample `validate:"dive"`
+}
+
+// SLIExample are the values of the SLI queries series (e.g `http_requests_total{code="500"}`)
+// and the SLI error ratio expected from them.
+type SLIExample struct {
+	Name       string
+	Series     map[string]float64 `validate:"required"`
+	ErrorRatio float64            `validate:"gte=0,lte=1"`
 }
 
 type SLIRaw struct {
@@ -33,8 +48,10 @@ type SLIEvents struct {
 type AlertMeta struct {
 	Disable     bool
 	Name        string            `validate:"required_if_enabled"`
-	Labels      map[string]string `validate:"dive,keys,prom_label_key,endkeys,required,prom_label_value"`
+	Labels      map[string]string `validate:"dive,keys,prom_label_key,non_reserved_label,endkeys,required,prom_label_value"`
 	Annotations map[string]string `validate:"dive,keys,prom_annot_key,endkeys,required"`
+	// For overrides the alert windows `for` duration when set.
+	For time.Duration `validate:"gte=0"`
 }
 
 // SLO represents a service level objective configuration.
@@ -42,13 +59,71 @@ type SLO struct {
 	ID              string `validate:"required,name"`
 	Name            string `validate:"required,name"`
 	Description     string
-	Service         string `validate:"required,name"`
-	SLI             SLI    `validate:"required"`
-	TimeWindow      time.Duration
-	Objective       float64           `validate:"gt=0,lte=100"`
-	Labels          map[string]string `validate:"dive,keys,prom_label_key,endkeys,required,prom_label_value"`
+	Service         string            `validate:"required,name"`
+	SLI             SLI               `validate:"required"`
+	TimeWindow      time.Duration     `validate:"omitempty,gte=24h"`
+	Objective       float64           `validate:"gt=0,lt=100"`
+	Labels          map[string]string `validate:"dive,keys,prom_label_key,non_reserved_label,endkeys,required,prom_label_value"`
+	Annotations     map[string]string `validate:"dive,keys,prom_annot_key,endkeys,required"`
+	CostLabels      map[string]string `validate:"dive,keys,prom_label_key,non_reserved_label,endkeys,required,prom_label_value"`
+	GroupLabels     map[string]string `validate:"dive,keys,prom_label_key,endkeys,required,prom_label_value"`
 	PageAlertMeta   AlertMeta
 	TicketAlertMeta AlertMeta
+	DependsOn       []string `validate:"dive,required"`
+	AlertGuard      string   `validate:"omitempty,prom_alert_guard"`
+	AlertWindows    *alert.Windows
+	Transition      *SLOTransition
+	// ReportingWindows are extra time windows used only to report the SLO (e.g a 7d
+	// operational window on a 30d SLO), the alerts use the SLO time window.
+	ReportingWindows []time.Duration `validate:"dive,gte=24h"`
+	// Timeslice makes the SLO a timeslice SLO, the SLI error ratio of the windows is the
+	// ratio of bad time slices instead of the SLI events ratio.
+	Timeslice *SLOTimeslice
+	// RuleGroupIntervals are the evaluation intervals of the SLO rule groups, these have
+	// priority over the rule groups metadata intervals.
+	RuleGroupIntervals RuleGroupIntervals
+	// MaintenanceWindows are the recurring time windows where the SLO alerts are muted
+	// by Alertmanager.
+	MaintenanceWindows []MaintenanceWindow
+}
+
+// RuleGroupIntervals are the evaluation intervals of the SLI recordings, metadata recordings
+// and alerts rule groups, if 0 the rule groups use the ruler global evaluation interval.
+type RuleGroupIntervals struct {
+	SLIRecordings  time.Duration `validate:"gte=0"`
+	MetaRecordings time.Duration `validate:"gte=0"`
+	Alerts         time.Duration `validate:"gte=0"`
+}
+
+// WithDefaults returns a copy of the intervals that uses the default intervals on the
+// intervals that are not set.
+func (r RuleGroupIntervals) WithDefaults(defaults RuleGroupIntervals) RuleGroupIntervals {
+	if r.SLIRecordings == 0 {
+		r.SLIRecordings = defaults.SLIRecordings
+	}
+	if r.MetaRecordings == 0 {
+		r.MetaRecordings = defaults.MetaRecordings
+	}
+	if r.Alerts == 0 {
+		r.Alerts = defaults.Alerts
+	}
+
+	return r
+}
+
+// SLOTransition is the previous objective and time window of a changed SLO, used to
+// generate the transitional recording rules.
+type SLOTransition struct {
+	PreviousObjective  float64       `validate:"gt=0,lt=100"`
+	PreviousTimeWindow time.Duration `validate:"omitempty,gte=24h"`
+	ChangedAt          string        `validate:"omitempty,datetime=2006-01-02"`
+}
+
+// SLOTimeslice are the time slices of a timeslice SLO, a time slice is bad when its SLI
+// error ratio is greater than the error ratio threshold.
+type SLOTimeslice struct {
+	Window              time.Duration `validate:"gte=1m"`
+	ErrorRatioThreshold float64       `validate:"gte=0,lt=1"`
 }
 
 type SLOGroup struct {
@@ -65,6 +140,18 @@ func (s SLO) GetSLIErrorMetric(window time.Duration) string {
 	return fmt.Sprintf(sliErrorMetricFmt, timeDurationToPromStr(window))
 }
 
+// GetExistingSLIRecordingsSLI returns an SLI that uses the SLO SLI error recording rules with
+// the Sloth standard names, used when the recording rules already exist (e.g centrally managed)
+// and only the alerts are generated.
+func (s SLO) GetExistingSLIRecordingsSLI() SLI {
+	metric := fmt.Sprintf(sliErrorMetricFmt, fmt.Sprintf("{{.%s}}", tplKeyWindow))
+	return SLI{
+		Raw: &SLIRaw{
+			ErrorRatioQuery: metric + labelsToPromFilter(s.GetSLOIDPromLabels()),
+		},
+	}
+}
+
 // GetSLOIDPromLabels returns the ID labels of an SLO, these can be used to identify
 // an SLO recorded metrics and alerts.
 func (s SLO) GetSLOIDPromLabels() map[string]string {
@@ -75,18 +162,35 @@ func (s SLO) GetSLOIDPromLabels() map[string]string {
 	}
 }
 
+// getReportingWindows returns the SLO reporting windows sorted and without duplicates,
+// ignoring the ones that are the SLO time window.
+func (s SLO) getReportingWindows() []time.Duration {
+	windows := map[string]time.Duration{}
+	for _, w := range s.ReportingWindows {
+		if w != s.TimeWindow {
+			windows[w.String()] = w
+		}
+	}
+
+	return sortedWindows(windows)
+}
+
 var modelSpecValidate = func() *validator.Validate {
 	v := validator.New()
 
 	// More information on prometheus validators logic: https://github.com/prometheus/prometheus/blob/df80dc4d3970121f2f76cba79050983ffb3cdbb0/pkg/rulefmt/rulefmt.go#L188-L208
 	mustRegisterValidation(v, "prom_expr", validatePromExpression)
+	mustRegisterValidation(v, "prom_alert_guard", validatePromAlertGuard)
 	mustRegisterValidation(v, "prom_label_key", validatePromLabelKey)
 	mustRegisterValidation(v, "prom_label_value", validatePromLabelValue)
 	mustRegisterValidation(v, "prom_annot_key", validatePromAnnotKey)
+	mustRegisterValidation(v, "non_reserved_label", validateNonReservedLabel)
 	mustRegisterValidation(v, "name", validateName)
 	mustRegisterValidation(v, "required_if_enabled", validateRequiredEnabledAlertName)
 	mustRegisterValidation(v, "template_vars", validateTemplateVars)
 	v.RegisterStructValidation(validateOneSLI, SLI{})
+	v.RegisterStructValidation(validateSLIEvents, SLIEvents{})
+	v.RegisterStructValidation(validateSLOAlertTemplates, SLO{})
 	v.RegisterStructValidation(validateSLOGroup, SLOGroup{})
 	return v
 }()
@@ -132,6 +236,18 @@ func validatePromLabelValue(fl validator.FieldLevel) bool {
 	return prommodel.LabelValue(v).IsValid()
 }
 
+// validateNonReservedLabel implements validator.CustomTypeFunc by validating
+// a label key is not one of the labels reserved by Sloth.
+func validateNonReservedLabel(fl validator.FieldLevel) bool {
+	k, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	_, reserved := reservedLabelNames[k]
+	return !reserved
+}
+
 var promExprTplAllowedFakeData = map[string]string{
 	"window": "1m",
 }
@@ -144,22 +260,39 @@ func validatePromExpression(fl validator.FieldLevel) bool {
 		return false
 	}
 
+	_, err := parseTplPromExpression(expr)
+	return err == nil
+}
+
+// validatePromAlertGuard implements validator.CustomTypeFunc by validating an alert guard
+// prometheus expression, as it's combined with the alert expression.
+func validatePromAlertGuard(fl validator.FieldLevel) bool {
+	guard, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	_, err := parsePromExpr(guardAlertExpr("vector(1)", guard))
+	return err == nil
+}
+
+// parseTplPromExpression parses a prometheus expression that can have templated data.
+func parseTplPromExpression(expr string) (promqlparser.Expr, error) {
 	// The expressions set by users can have some allowed templated data
 	// we are rendering the expression with fake data so prometheus can
 	// have a final expr and check if is correct.
 	tpl, err := template.New("expr").Parse(expr)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
 	var tplB bytes.Buffer
 	err = tpl.Execute(&tplB, promExprTplAllowedFakeData)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	_, err = promqlparser.ParseExpr(tplB.String())
-	return err == nil
+	return parsePromExpr(tplB.String())
 }
 
 // Names must:
@@ -221,7 +354,8 @@ func validateOneSLI(sl validator.StructLevel) {
 	strNumFields := sliType.NumField()
 	for i := 0; i < strNumFields; i++ {
 		f := sliType.Field(i)
-		if f.IsNil() {
+		// SLI types are pointers, ignore the SLI options.
+		if f.Kind() != reflect.Ptr || f.IsNil() {
 			continue
 		}
 		// We already have one SLI type set.
@@ -237,6 +371,73 @@ func validateOneSLI(sl validator.StructLevel) {
 	}
 }
 
+// validateSLIEvents implements validator.CustomTypeFunc by validating the error
+// and total queries return series with the same labels, otherwise the SLI error
+// ratio vector matching fails (many-to-many or empty results) when evaluated.
+// The labels are only checked when they can be known statically (e.g aggregations).
+func validateSLIEvents(sl validator.StructLevel) {
+	sli, ok := sl.Current().Interface().(SLIEvents)
+	if !ok {
+		sl.ReportError(sli, "", "SLIEvents", "not_sli_events", "")
+		return
+	}
+
+	// Invalid expressions are reported by the field validations.
+	errorExpr, err := parseTplPromExpression(sli.ErrorQuery)
+	if err != nil {
+		return
+	}
+	totalExpr, err := parseTplPromExpression(sli.TotalQuery)
+	if err != nil {
+		return
+	}
+
+	errorLabels, ok := getPromExprLabels(errorExpr)
+	if !ok {
+		return
+	}
+	totalLabels, ok := getPromExprLabels(totalExpr)
+	if !ok {
+		return
+	}
+
+	if !errorLabels.equal(totalLabels) {
+		param := fmt.Sprintf("error query labels %v, total query labels %v", errorLabels.sorted(), totalLabels.sorted())
+		sl.ReportError(sli.ErrorQuery, "ErrorQuery", "ErrorQuery", "events_labels_match", param)
+	}
+}
+
+// validateSLOAlertTemplates implements validator.CustomTypeFunc by validating the
+// SLO alerts annotation templates render, so broken templates (e.g missing labels)
+// fail on validation instead of when generating the rules.
+func validateSLOAlertTemplates(sl validator.StructLevel) {
+	slo, ok := sl.Current().Interface().(SLO)
+	if !ok {
+		sl.ReportError(slo, "", "SLO", "not_slo", "")
+		return
+	}
+
+	for _, a := range []struct {
+		name     string
+		meta     AlertMeta
+		severity alert.Severity
+	}{
+		{name: "PageAlertMeta", meta: slo.PageAlertMeta, severity: alert.PageAlertSeverity},
+		{name: "TicketAlertMeta", meta: slo.TicketAlertMeta, severity: alert.TicketAlertSeverity},
+	} {
+		if a.meta.Disable {
+			continue
+		}
+
+		// The alert windows are not known until generation, render with the severity only.
+		mwmbAlert := alert.MWMBAlert{Severity: a.severity}
+		_, err := renderAlertAnnotations(mergeLabels(slo.Annotations, a.meta.Annotations), newAlertTemplateData(slo, a.meta, mwmbAlert, mwmbAlert))
+		if err != nil {
+			sl.ReportError(a.meta.Annotations, a.name, a.name, "alert_annotations_template", err.Error())
+		}
+	}
+}
+
 // validateSLOGroup implements validator.CustomTypeFunc by validating
 // SLO IDs are not repeated.
 func validateSLOGroup(sl validator.StructLevel) {
@@ -267,3 +468,34 @@ type SLORules struct {
 	MetadataRecRules []rulefmt.Rule
 	AlertRules       []rulefmt.Rule
 }
+
+// maxRuleMetaSize is the maximum size (in bytes) of the labels and annotations of a
+// generated rule. Prometheus and Alertmanager don't have a hard limit, but big alerts
+// end being truncated or rejected by the notification receivers and integrations.
+const maxRuleMetaSize = 16 * 1024
+
+// Validate validates the SLO rules are under the practical size limits of Prometheus
+// and Alertmanager.
+func (s SLORules) Validate() error {
+	for _, rules := range [][]rulefmt.Rule{s.SLIErrorRecRules, s.MetadataRecRules, s.AlertRules} {
+		for _, rule := range rules {
+			size := 0
+			for k, v := range rule.Labels {
+				size += len(k) + len(v)
+			}
+			for k, v := range rule.Annotations {
+				size += len(k) + len(v)
+			}
+
+			if size > maxRuleMetaSize {
+				name := rule.Alert
+				if name == "" {
+					name = rule.Record
+				}
+				return fmt.Errorf("%q rule labels and annotations size is %d bytes and the maximum is %d bytes, reduce the size of the SLO labels and annotations", name, size, maxRuleMetaSize)
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,8 +1,11 @@
 package prometheus_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/prometheus/pkg/rulefmt"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/slok/sloth/internal/prometheus"
@@ -203,6 +206,14 @@ func TestModelValidationSpec(t *testing.T) {
 			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.' Error:Field validation for '' failed on the 'sli_type_required' tag",
 		},
 
+		"SLO using the existing SLI recordings as SLI should not fail.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI = s.SLOs[0].GetExistingSLIRecordingsSLI()
+				return s
+			},
+		},
+
 		"SLO with more than one SLI type should fail.": {
 			slo: func() prometheus.SLOGroup {
 				s := getGoodSLOGroup()
@@ -214,6 +225,15 @@ func TestModelValidationSpec(t *testing.T) {
 			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.' Error:Field validation for '' failed on the 'one_sli_type' tag",
 		},
 
+		"SLO SLI offset shouldn't be negative.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI.Offset = -5 * time.Minute
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.Offset' Error:Field validation for 'Offset' failed on the 'gte' tag",
+		},
+
 		"SLO SLI error query should be valid Prometheus expr.": {
 			slo: func() prometheus.SLOGroup {
 				s := getGoodSLOGroup()
@@ -232,6 +252,24 @@ func TestModelValidationSpec(t *testing.T) {
 			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.Events.ErrorQuery' Error:Field validation for 'ErrorQuery' failed on the 'template_vars' tag",
 		},
 
+		"SLO SLI native histogram queries should be valid Prometheus expr.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI.Events.ErrorQuery = "histogram_count(sum(rate(http_request_duration_seconds[{{.window}}]))) * (1 - histogram_fraction(0, 0.3, sum(rate(http_request_duration_seconds[{{.window}}]))))"
+				s.SLOs[0].SLI.Events.TotalQuery = "histogram_count(sum(rate(http_request_duration_seconds[{{.window}}])))"
+				return s
+			},
+		},
+
+		"SLO SLI native histogram queries should have valid function arguments.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI.Events.ErrorQuery = "1 - histogram_fraction(0.3, sum(rate(http_request_duration_seconds[{{.window}}])))"
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.Events.ErrorQuery' Error:Field validation for 'ErrorQuery' failed on the 'prom_expr' tag",
+		},
+
 		"SLO SLI total query should be valid Prometheus expr.": {
 			slo: func() prometheus.SLOGroup {
 				s := getGoodSLOGroup()
@@ -250,6 +288,71 @@ func TestModelValidationSpec(t *testing.T) {
 			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.Events.TotalQuery' Error:Field validation for 'TotalQuery' failed on the 'template_vars' tag",
 		},
 
+		"SLO alert annotation templates should be valid.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].PageAlertMeta.Annotations["message"] = "[[ .SLO.Service is burning budget"
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].PageAlertMeta' Error:Field validation for 'PageAlertMeta' failed on the 'alert_annotations_template' tag",
+		},
+
+		"SLO alert annotation templates should use existing keys.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].TicketAlertMeta.Annotations["message"] = "[[ .SLO.Labels.missing ]] is burning budget"
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].TicketAlertMeta' Error:Field validation for 'TicketAlertMeta' failed on the 'alert_annotations_template' tag",
+		},
+
+		"SLO SLI error and total queries with the same aggregation labels should not fail.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI.Events.ErrorQuery = `sum by (job, cluster) (rate(grpc_server_handled_requests_count{code=~"Internal|Unavailable"}[{{.window}}]))`
+				s.SLOs[0].SLI.Events.TotalQuery = `(sum by (cluster, job) (rate(grpc_server_handled_requests_count[{{.window}}])) * 1)`
+				return s
+			},
+		},
+
+		"SLO SLI error and total queries with unknown labels should not fail.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI.Events.ErrorQuery = `sum by (job) (rate(grpc_server_handled_requests_count{code=~"Internal|Unavailable"}[{{.window}}]))`
+				s.SLOs[0].SLI.Events.TotalQuery = `rate(grpc_server_handled_requests_count[{{.window}}])`
+				return s
+			},
+		},
+
+		"SLO SLI error and total queries with different aggregation labels should fail.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI.Events.ErrorQuery = `sum by (job, code) (rate(grpc_server_handled_requests_count{code=~"Internal|Unavailable"}[{{.window}}]))`
+				s.SLOs[0].SLI.Events.TotalQuery = `sum by (job) (rate(grpc_server_handled_requests_count[{{.window}}]))`
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.Events.ErrorQuery' Error:Field validation for 'ErrorQuery' failed on the 'events_labels_match' tag",
+		},
+
+		"SLO SLI error and total queries with different function result labels should fail.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].SLI.Events.ErrorQuery = `label_replace(sum by (job) (rate(grpc_server_handled_requests_count{code=~"Internal|Unavailable"}[{{.window}}])), "app", "$1", "job", "(.*)")`
+				s.SLOs[0].SLI.Events.TotalQuery = `sum by (job) (rate(grpc_server_handled_requests_count[{{.window}}]))`
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].SLI.Events.ErrorQuery' Error:Field validation for 'ErrorQuery' failed on the 'events_labels_match' tag",
+		},
+
+		"SLO time window shouldn't be less than 1 day.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].TimeWindow = 12 * time.Hour
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].TimeWindow' Error:Field validation for 'TimeWindow' failed on the 'gte' tag",
+		},
+
 		"SLO Objective shouldn't be less than 0.": {
 			slo: func() prometheus.SLOGroup {
 				s := getGoodSLOGroup()
@@ -268,13 +371,22 @@ func TestModelValidationSpec(t *testing.T) {
 			expErrMessage: "Key: 'SLOGroup.SLOs[0].Objective' Error:Field validation for 'Objective' failed on the 'gt' tag",
 		},
 
+		"SLO Objective shouldn't be 100.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Objective = 100
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Objective' Error:Field validation for 'Objective' failed on the 'lt' tag",
+		},
+
 		"SLO Objective shouldn't be greater than 100.": {
 			slo: func() prometheus.SLOGroup {
 				s := getGoodSLOGroup()
 				s.SLOs[0].Objective = 100.0001
 				return s
 			},
-			expErrMessage: "Key: 'SLOGroup.SLOs[0].Objective' Error:Field validation for 'Objective' failed on the 'lte' tag",
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Objective' Error:Field validation for 'Objective' failed on the 'lt' tag",
 		},
 
 		"SLO Labels should be valid prometheus keys.": {
@@ -304,6 +416,96 @@ func TestModelValidationSpec(t *testing.T) {
 			expErrMessage: "Key: 'SLOGroup.SLOs[0].Labels[something]' Error:Field validation for 'Labels[something]' failed on the 'prom_label_value' tag",
 		},
 
+		"SLO Labels shouldn't use Sloth reserved labels.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Labels["sloth_id"] = "something"
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Labels[sloth_id]' Error:Field validation for 'Labels[sloth_id]' failed on the 'non_reserved_label' tag",
+		},
+
+		"SLO cost labels shouldn't use Sloth reserved labels.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].CostLabels = map[string]string{"sloth_service": "something"}
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].CostLabels[sloth_service]' Error:Field validation for 'CostLabels[sloth_service]' failed on the 'non_reserved_label' tag",
+		},
+
+		"SLO alert guard should be valid Prometheus expr.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].AlertGuard = "on( cluster_maintenance == 0"
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].AlertGuard' Error:Field validation for 'AlertGuard' failed on the 'prom_alert_guard' tag",
+		},
+
+		"SLO alert guard should be valid combined with the alert expression.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].AlertGuard = "on() cluster_maintenance == 0"
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].AlertGuard' Error:Field validation for 'AlertGuard' failed on the 'prom_alert_guard' tag",
+		},
+
+		"SLO transition previous objective should be valid.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Transition = &prometheus.SLOTransition{PreviousObjective: 100}
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Transition.PreviousObjective' Error:Field validation for 'PreviousObjective' failed on the 'lt' tag",
+		},
+
+		"SLO transition previous time window should be at least 24h.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Transition = &prometheus.SLOTransition{PreviousObjective: 99, PreviousTimeWindow: time.Hour}
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Transition.PreviousTimeWindow' Error:Field validation for 'PreviousTimeWindow' failed on the 'gte' tag",
+		},
+
+		"SLO transition change date should be valid.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Transition = &prometheus.SLOTransition{PreviousObjective: 99, ChangedAt: "30/06/2021"}
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Transition.ChangedAt' Error:Field validation for 'ChangedAt' failed on the 'datetime' tag",
+		},
+
+		"SLO timeslice window should be at least 1m.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Timeslice = &prometheus.SLOTimeslice{Window: 30 * time.Second}
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Timeslice.Window' Error:Field validation for 'Window' failed on the 'gte' tag",
+		},
+
+		"SLO timeslice error ratio threshold should be a ratio.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Timeslice = &prometheus.SLOTimeslice{Window: time.Minute, ErrorRatioThreshold: 1}
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Timeslice.ErrorRatioThreshold' Error:Field validation for 'ErrorRatioThreshold' failed on the 'lt' tag",
+		},
+
+		"SLO Annotations should be valid prometheus keys.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].Annotations = map[string]string{".something": "annotation key is wrong"}
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].Annotations[.something]' Error:Field validation for 'Annotations[.something]' failed on the 'prom_annot_key' tag",
+		},
+
 		"SLO page alert name is required.": {
 			slo: func() prometheus.SLOGroup {
 				s := getGoodSLOGroup()
@@ -353,6 +555,15 @@ func TestModelValidationSpec(t *testing.T) {
 			expErrMessage: "Key: 'SLOGroup.SLOs[0].PageAlertMeta.Labels[.something]' Error:Field validation for 'Labels[.something]' failed on the 'prom_label_key' tag",
 		},
 
+		"SLO page alert labels shouldn't use Sloth reserved labels.": {
+			slo: func() prometheus.SLOGroup {
+				s := getGoodSLOGroup()
+				s.SLOs[0].PageAlertMeta.Labels["sloth_severity"] = "critical"
+				return s
+			},
+			expErrMessage: "Key: 'SLOGroup.SLOs[0].PageAlertMeta.Labels[sloth_severity]' Error:Field validation for 'Labels[sloth_severity]' failed on the 'non_reserved_label' tag",
+		},
+
 		"SLO page alert labels should have prometheus values.": {
 			slo: func() prometheus.SLOGroup {
 				s := getGoodSLOGroup()
@@ -451,3 +662,49 @@ func TestModelValidationSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestSLORulesValidation(t *testing.T) {
+	tests := map[string]struct {
+		rules  prometheus.SLORules
+		expErr bool
+	}{
+		"Rules with small labels and annotations should not fail.": {
+			rules: prometheus.SLORules{
+				SLIErrorRecRules: []rulefmt.Rule{{Record: "test:record", Labels: map[string]string{"k1": "v1"}}},
+				AlertRules:       []rulefmt.Rule{{Alert: "TestAlert", Annotations: map[string]string{"k1": "v1"}}},
+			},
+		},
+
+		"Recording rules with too big labels should fail.": {
+			rules: prometheus.SLORules{
+				SLIErrorRecRules: []rulefmt.Rule{{Record: "test:record", Labels: map[string]string{"k1": strings.Repeat("a", 17*1024)}}},
+			},
+			expErr: true,
+		},
+
+		"Alert rules with too big labels and annotations should fail.": {
+			rules: prometheus.SLORules{
+				AlertRules: []rulefmt.Rule{{
+					Alert:       "TestAlert",
+					Labels:      map[string]string{"k1": strings.Repeat("a", 10*1024)},
+					Annotations: map[string]string{"k1": strings.Repeat("a", 10*1024)},
+				}},
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := test.rules.Validate()
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/util/teststorage"
+)
+
+// promEvalSample is a static series value of an evaluated PromQL expression.
+type promEvalSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// promEvalTime is the time of the static series samples and the expressions evaluation.
+var promEvalTime = time.Unix(0, 0)
+
+// promExprEvaluator evaluates PromQL expressions with the Prometheus engine using static series
+// values instead of a Prometheus, used to smoke test the SLI queries logic.
+//
+// The series only have a sample, so the range vector functions (e.g `rate`, `increase` or
+// `avg_over_time`) are replaced by their series, these are the already calculated values of the
+// functions on the window (e.g the requests per second).
+type promExprEvaluator struct {
+	series []promEvalSample
+}
+
+// eval evaluates the expression and returns its result, a scalar or an instant vector.
+func (p promExprEvaluator) eval(ctx context.Context, expr promqlparser.Expr) (promqlparser.Value, error) {
+	storage, err := newPromEvalStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer storage.Close()
+
+	app := storage.Appender(ctx)
+	for _, s := range p.series {
+		_, err := app.Append(0, labels.FromMap(s.labels), promEvalTime.UnixNano()/int64(time.Millisecond), s.value)
+		if err != nil {
+			_ = app.Rollback()
+			return nil, fmt.Errorf("could not add %s series: %w", labels.FromMap(s.labels), err)
+		}
+	}
+	err = app.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("could not add series: %w", err)
+	}
+
+	// Copy the expression before replacing its range vector functions.
+	expr, err = parsePromExpr(expr.String())
+	if err != nil {
+		return nil, err
+	}
+
+	engine := promql.NewEngine(promql.EngineOpts{
+		MaxSamples:               50000000,
+		Timeout:                  time.Minute,
+		NoStepSubqueryIntervalFn: func(int64) int64 { return time.Minute.Milliseconds() },
+	})
+	query, err := engine.NewInstantQuery(storage, replacePromEvalRangeFuncs(expr).String(), promEvalTime)
+	if err != nil {
+		return nil, err
+	}
+	defer query.Close()
+
+	res := query.Exec(ctx)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	return res.Value, nil
+}
+
+// promEvalRangeFuncs are the range vector functions, these are replaced by their series.
+var promEvalRangeFuncs = map[string]bool{
+	"rate":           true,
+	"irate":          true,
+	"increase":       true,
+	"delta":          true,
+	"idelta":         true,
+	"deriv":          true,
+	"avg_over_time":  true,
+	"min_over_time":  true,
+	"max_over_time":  true,
+	"sum_over_time":  true,
+	"last_over_time": true,
+}
+
+// replacePromEvalRangeFuncs replaces the range vector functions of the expression by the series
+// selectors (or subquery expressions) of their range.
+func replacePromEvalRangeFuncs(expr promqlparser.Expr) promqlparser.Expr {
+	switch e := expr.(type) {
+	case *promqlparser.Call:
+		if promEvalRangeFuncs[e.Func.Name] && len(e.Args) == 1 {
+			switch a := e.Args[0].(type) {
+			case *promqlparser.MatrixSelector:
+				return a.VectorSelector
+			case *promqlparser.SubqueryExpr:
+				return replacePromEvalRangeFuncs(a.Expr)
+			}
+		}
+		for i, a := range e.Args {
+			e.Args[i] = replacePromEvalRangeFuncs(a)
+		}
+
+	case *promqlparser.ParenExpr:
+		e.Expr = replacePromEvalRangeFuncs(e.Expr)
+
+	case *promqlparser.UnaryExpr:
+		e.Expr = replacePromEvalRangeFuncs(e.Expr)
+
+	case *promqlparser.AggregateExpr:
+		e.Expr = replacePromEvalRangeFuncs(e.Expr)
+
+	case *promqlparser.BinaryExpr:
+		e.LHS = replacePromEvalRangeFuncs(e.LHS)
+		e.RHS = replacePromEvalRangeFuncs(e.RHS)
+
+	case *promqlparser.SubqueryExpr:
+		e.Expr = replacePromEvalRangeFuncs(e.Expr)
+	}
+
+	return expr
+}
+
+// promEvalStorageError is a Prometheus evaluation storage creation error.
+type promEvalStorageError string
+
+// promEvalStorageFailer handles the Prometheus evaluation storage creation failures, panicking
+// with the failure so it can be recovered as an error.
+type promEvalStorageFailer struct{}
+
+func (promEvalStorageFailer) Fatal(args ...interface{}) {
+	panic(promEvalStorageError(fmt.Sprint(args...)))
+}
+
+func (promEvalStorageFailer) Fatalf(format string, args ...interface{}) {
+	panic(promEvalStorageError(fmt.Sprintf(format, args...)))
+}
+
+// newPromEvalStorage returns a temporary Prometheus storage for the evaluated series, it needs
+// to be closed to remove its data.
+func newPromEvalStorage() (storage *teststorage.TestStorage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg, ok := r.(promEvalStorageError)
+			if !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("could not create evaluation storage: %s", msg)
+		}
+	}()
+
+	return teststorage.New(promEvalStorageFailer{}), nil
+}
@@ -0,0 +1,134 @@
+package prometheus
+
+import (
+	"sort"
+
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
+)
+
+// promExprLabels are the labels of the series returned by a PromQL expression.
+type promExprLabels map[string]struct{}
+
+func (p promExprLabels) sorted() []string {
+	ls := make([]string, 0, len(p))
+	for l := range p {
+		ls = append(ls, l)
+	}
+	sort.Strings(ls)
+	return ls
+}
+
+func (p promExprLabels) equal(other promExprLabels) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for l := range p {
+		if _, ok := other[l]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// getPromExprLabels returns the labels of the series returned by a PromQL expression,
+// only when these can be known statically (e.g `sum by (code) (...)`). If the labels
+// depend on the queried series (e.g `rate(http_requests_total[5m])`) or the expression
+// returns a scalar, it will return false.
+func getPromExprLabels(expr promqlparser.Expr) (promExprLabels, bool) {
+	if expr.Type() != promqlparser.ValueTypeVector {
+		return nil, false
+	}
+
+	switch e := expr.(type) {
+	case *promqlparser.ParenExpr:
+		return getPromExprLabels(e.Expr)
+
+	case *promqlparser.UnaryExpr:
+		return getPromExprLabels(e.Expr)
+
+	case *promqlparser.AggregateExpr:
+		switch e.Op {
+		// These aggregations return the input series.
+		case promqlparser.TOPK, promqlparser.BOTTOMK:
+			return getPromExprLabels(e.Expr)
+		// The labels depend on the values.
+		case promqlparser.COUNT_VALUES:
+			return nil, false
+		}
+
+		if e.Without {
+			labels, ok := getPromExprLabels(e.Expr)
+			if !ok {
+				return nil, false
+			}
+			for _, l := range e.Grouping {
+				delete(labels, l)
+			}
+			return labels, true
+		}
+
+		labels := promExprLabels{}
+		for _, l := range e.Grouping {
+			labels[l] = struct{}{}
+		}
+		return labels, true
+
+	case *promqlparser.Call:
+		switch e.Func.Name {
+		case "vector":
+			return promExprLabels{}, true
+		case "absent", "absent_over_time":
+			return nil, false
+		case "label_replace", "label_join":
+			labels, ok := getPromExprLabels(e.Args[0])
+			if !ok {
+				return nil, false
+			}
+			if dst, ok := e.Args[1].(*promqlparser.StringLiteral); ok {
+				labels[dst.Val] = struct{}{}
+			}
+			return labels, true
+		case "histogram_quantile":
+			labels, ok := getPromExprLabels(e.Args[1])
+			if !ok {
+				return nil, false
+			}
+			delete(labels, "le")
+			return labels, true
+		}
+
+		// The rest of the functions keep the labels of their vector argument.
+		for _, arg := range e.Args {
+			if arg.Type() == promqlparser.ValueTypeVector {
+				return getPromExprLabels(arg)
+			}
+		}
+		return nil, false
+
+	case *promqlparser.BinaryExpr:
+		lhsLabels, lhsOK := getPromExprLabels(e.LHS)
+		rhsLabels, rhsOK := getPromExprLabels(e.RHS)
+
+		// Vector and scalar operations keep the vector labels.
+		if e.LHS.Type() != promqlparser.ValueTypeVector {
+			return rhsLabels, rhsOK
+		}
+		if e.RHS.Type() != promqlparser.ValueTypeVector {
+			return lhsLabels, lhsOK
+		}
+
+		// Only one-to-one matching without `on`/`ignoring` is known, the result has
+		// the left hand side labels.
+		vm := e.VectorMatching
+		if vm != nil && (vm.Card != promqlparser.CardOneToOne || vm.On || len(vm.MatchingLabels) > 0) {
+			return nil, false
+		}
+		if !lhsOK || !rhsOK {
+			return nil, false
+		}
+		return lhsLabels, true
+	}
+
+	// Selectors, subqueries... have the labels of the queried series.
+	return nil, false
+}
@@ -0,0 +1,181 @@
+package prometheus
+
+import (
+	"strings"
+
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
+)
+
+// promNativeFunc is a PromQL function newer than our PromQL parser. These are parsed as a
+// placeholder function with the same argument and return types, and replaced after parsing.
+type promNativeFunc struct {
+	fn          *promqlparser.Function
+	placeholder string
+	// argsOrder are the indexes of the function arguments in the placeholder arguments.
+	argsOrder []int
+}
+
+// promNativeFuncs are the Prometheus native histograms functions.
+var promNativeFuncs = map[string]promNativeFunc{
+	"histogram_count": {
+		fn: &promqlparser.Function{
+			Name:       "histogram_count",
+			ArgTypes:   []promqlparser.ValueType{promqlparser.ValueTypeVector},
+			ReturnType: promqlparser.ValueTypeVector,
+		},
+		placeholder: "abs",
+		argsOrder:   []int{0},
+	},
+	"histogram_fraction": {
+		fn: &promqlparser.Function{
+			Name:       "histogram_fraction",
+			ArgTypes:   []promqlparser.ValueType{promqlparser.ValueTypeScalar, promqlparser.ValueTypeScalar, promqlparser.ValueTypeVector},
+			ReturnType: promqlparser.ValueTypeVector,
+		},
+		placeholder: "clamp",
+		argsOrder:   []int{2, 0, 1},
+	},
+}
+
+// parsePromExpr parses a Prometheus expression, supporting the native histograms functions
+// without registering them on the PromQL parser functions.
+func parsePromExpr(expr string) (promqlparser.Expr, error) {
+	r := newPromNativeFuncsRewriter(expr)
+	if r == nil {
+		return promqlparser.ParseExpr(expr)
+	}
+
+	ast, err := promqlparser.ParseExpr(r.rewrite(0, len(r.items)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the placeholder calls with the native functions.
+	promqlparser.Inspect(ast, func(node promqlparser.Node, _ []promqlparser.Node) error {
+		call, ok := node.(*promqlparser.Call)
+		if !ok {
+			return nil
+		}
+		nf, ok := r.calls[call.PosRange.Start]
+		if !ok || call.Func.Name != nf.placeholder {
+			return nil
+		}
+
+		args := make(promqlparser.Expressions, len(call.Args))
+		for i, a := range call.Args {
+			args[nf.argsOrder[i]] = a
+		}
+		call.Func = nf.fn
+		call.Args = args
+
+		return nil
+	})
+
+	return ast, nil
+}
+
+// promNativeFuncsRewriter rewrites the native functions calls of an expression with their
+// placeholder calls, tracking the position of the placeholder calls on the rewritten expression.
+type promNativeFuncsRewriter struct {
+	input string
+	items []promqlparser.Item
+	b     strings.Builder
+	calls map[promqlparser.Pos]promNativeFunc
+}
+
+// newPromNativeFuncsRewriter returns a rewriter for the expression, or nil if the expression
+// doesn't have native functions calls or can't be lexed (the parser will report the error).
+func newPromNativeFuncsRewriter(expr string) *promNativeFuncsRewriter {
+	items := []promqlparser.Item{}
+	native := false
+	l := promqlparser.Lex(expr)
+	for {
+		var it promqlparser.Item
+		l.NextItem(&it)
+		switch it.Typ {
+		case promqlparser.EOF:
+			if !native {
+				return nil
+			}
+			return &promNativeFuncsRewriter{
+				input: expr,
+				items: items,
+				calls: map[promqlparser.Pos]promNativeFunc{},
+			}
+		case promqlparser.ERROR:
+			return nil
+		case promqlparser.IDENTIFIER:
+			if _, ok := promNativeFuncs[it.Val]; ok {
+				native = true
+			}
+		}
+		items = append(items, it)
+	}
+}
+
+// rewrite rewrites the items in the [start, end) range and returns the rewritten expression.
+func (r *promNativeFuncsRewriter) rewrite(start, end int) string {
+	for i := start; i < end; i++ {
+		it := r.items[i]
+		nf, ok := promNativeFuncs[it.Val]
+		if ok && it.Typ == promqlparser.IDENTIFIER && i+1 < end && r.items[i+1].Typ == promqlparser.LEFT_PAREN {
+			args, closeIdx, ok := r.callArgs(i+1, end)
+			if ok && len(args) == len(nf.argsOrder) {
+				r.calls[promqlparser.Pos(r.b.Len())] = nf
+				r.b.WriteString(nf.placeholder + "(")
+				for j, argIdx := range nf.argsOrder {
+					if j > 0 {
+						r.b.WriteString(", ")
+					}
+					r.rewrite(args[argIdx][0], args[argIdx][1])
+				}
+				r.b.WriteString(r.text(closeIdx, closeIdx+1))
+				i = closeIdx
+				continue
+			}
+		}
+
+		r.b.WriteString(r.text(i, i+1))
+	}
+
+	return r.b.String()
+}
+
+// callArgs returns the items range of the call arguments, starting on the call left parenthesis,
+// and the index of the call right parenthesis.
+func (r *promNativeFuncsRewriter) callArgs(leftParen, end int) (args [][2]int, rightParen int, ok bool) {
+	depth := 0
+	argStart := leftParen + 1
+	for i := leftParen; i < end; i++ {
+		switch r.items[i].Typ {
+		case promqlparser.LEFT_PAREN:
+			depth++
+		case promqlparser.RIGHT_PAREN:
+			depth--
+			if depth == 0 {
+				if i > argStart {
+					args = append(args, [2]int{argStart, i})
+				}
+				return args, i, true
+			}
+		case promqlparser.COMMA:
+			if depth == 1 {
+				args = append(args, [2]int{argStart, i})
+				argStart = i + 1
+			}
+		}
+	}
+
+	return nil, 0, false
+}
+
+// text returns the input text of the items in the [start, end) range, including the spaces
+// after the last item.
+func (r *promNativeFuncsRewriter) text(start, end int) string {
+	to := len(r.input)
+	if end < len(r.items) {
+		to = int(r.items[end].Pos)
+	}
+
+	return r.input[r.items[start].Pos:to]
+}
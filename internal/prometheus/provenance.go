@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+var (
+	// ErrUnmanagedOutput will be used when an output doesn't have the Sloth generated stamp
+	// (e.g hand-written rule files).
+	ErrUnmanagedOutput = fmt.Errorf("output is not generated by Sloth")
+	// ErrEditedOutput will be used when an output has the Sloth generated stamp, but the
+	// content doesn't match the stamp checksum (e.g hand-edited generated rule files).
+	ErrEditedOutput = fmt.Errorf("output generated by Sloth has been edited")
+)
+
+// generatedStampPrefix is the prefix of the generated outputs stamp line, followed by the
+// checksum of the output content after the stamp line. It's a YAML comment so the outputs
+// are still valid.
+const generatedStampPrefix = "# generatedBy: sloth sha256:"
+
+// StampGenerated stamps a generated output with the checksum of its content, so it can be
+// verified that the output has been generated by Sloth and not edited afterwards.
+func StampGenerated(data []byte) []byte {
+	stamp := fmt.Sprintf("%s%x\n", generatedStampPrefix, sha256.Sum256(data))
+	return append([]byte(stamp), data...)
+}
+
+// VerifyGeneratedStamp verifies that an output has been stamped by Sloth and its content
+// matches the stamp checksum.
+func VerifyGeneratedStamp(data []byte) error {
+	stamp := data
+	content := []byte{}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		stamp, content = data[:i], data[i+1:]
+	}
+
+	if !bytes.HasPrefix(stamp, []byte(generatedStampPrefix)) {
+		return ErrUnmanagedOutput
+	}
+
+	checksum := string(bytes.TrimPrefix(stamp, []byte(generatedStampPrefix)))
+	if checksum != fmt.Sprintf("%x", sha256.Sum256(content)) {
+		return ErrEditedOutput
+	}
+
+	return nil
+}
+
+// TrimGeneratedStamp returns the output content without the Sloth generated stamp line, the
+// outputs without stamp are returned as they are.
+func TrimGeneratedStamp(data []byte) []byte {
+	if !bytes.HasPrefix(data, []byte(generatedStampPrefix)) {
+		return data
+	}
+
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return []byte{}
+	}
+
+	return data[i+1:]
+}
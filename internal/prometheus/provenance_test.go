@@ -0,0 +1,94 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestVerifyGeneratedStamp(t *testing.T) {
+	const rules = `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+groups:
+- name: sloth-slo-sli-recordings-svc01-slo1
+  rules: []
+`
+
+	tests := map[string]struct {
+		data   []byte
+		expErr error
+	}{
+		"Stamped outputs should be valid.": {
+			data: prometheus.StampGenerated([]byte(rules)),
+		},
+
+		"Stamped empty outputs should be valid.": {
+			data: prometheus.StampGenerated([]byte{}),
+		},
+
+		"Outputs without stamp should fail as unmanaged.": {
+			data:   []byte(rules),
+			expErr: prometheus.ErrUnmanagedOutput,
+		},
+
+		"Empty outputs should fail as unmanaged.": {
+			data:   []byte{},
+			expErr: prometheus.ErrUnmanagedOutput,
+		},
+
+		"Stamped outputs edited afterwards should fail as edited.": {
+			data:   append(prometheus.StampGenerated([]byte(rules)), []byte("- name: hand-written\n")...),
+			expErr: prometheus.ErrEditedOutput,
+		},
+
+		"Outputs with an invalid stamp checksum should fail as edited.": {
+			data:   append([]byte("# generatedBy: sloth sha256:1234\n"), []byte(rules)...),
+			expErr: prometheus.ErrEditedOutput,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := prometheus.VerifyGeneratedStamp(test.data)
+			assert.Equal(t, test.expErr, err)
+		})
+	}
+}
+
+func TestTrimGeneratedStamp(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		expData []byte
+	}{
+		"Stamped outputs should return the content without the stamp.": {
+			data:    prometheus.StampGenerated([]byte("groups: []\n")),
+			expData: []byte("groups: []\n"),
+		},
+
+		"Stamped outputs edited afterwards should return the edited content without the stamp.": {
+			data:    append(prometheus.StampGenerated([]byte("groups: []\n")), []byte("# Edited.\n")...),
+			expData: []byte("groups: []\n# Edited.\n"),
+		},
+
+		"Stamp only outputs should return an empty content.": {
+			data:    []byte("# generatedBy: sloth sha256:1234"),
+			expData: []byte{},
+		},
+
+		"Outputs without stamp should be returned as they are.": {
+			data:    []byte("groups: []\n"),
+			expData: []byte("groups: []\n"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expData, prometheus.TrimGeneratedStamp(test.data))
+		})
+	}
+}
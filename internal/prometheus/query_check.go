@@ -0,0 +1,206 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"text/template"
+	"time"
+
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
+)
+
+// SeriesQuerier knows how to execute Prometheus queries, returning the number of series
+// of the result.
+type SeriesQuerier interface {
+	QuerySeries(ctx context.Context, query string, ts time.Time) (int, error)
+	QueryRangeSeries(ctx context.Context, query string, start, end time.Time, step time.Duration) (int, error)
+}
+
+//go:generate mockery --case underscore --output prometheusmock --outpkg prometheusmock --name SeriesQuerier
+
+// SLIQueryCheckStatus is the status of an SLI query check.
+type SLIQueryCheckStatus string
+
+const (
+	// SLIQueryCheckStatusOK is the status of the queries that return data.
+	SLIQueryCheckStatusOK SLIQueryCheckStatus = "ok"
+	// SLIQueryCheckStatusEmpty is the status of the queries that don't return data, normally
+	// expected on error queries without errors.
+	SLIQueryCheckStatusEmpty SLIQueryCheckStatus = "empty"
+	// SLIQueryCheckStatusMissingMetrics is the status of the queries with metrics that don't
+	// exist on Prometheus.
+	SLIQueryCheckStatusMissingMetrics SLIQueryCheckStatus = "missing-metrics"
+	// SLIQueryCheckStatusError is the status of the queries that Prometheus can't execute.
+	SLIQueryCheckStatusError SLIQueryCheckStatus = "error"
+)
+
+// SLIQueryCheckConfig is the configuration of the SLI queries check.
+type SLIQueryCheckConfig struct {
+	// Window is the window used to render the SLI queries.
+	Window time.Duration
+	// Time is the time of the instant queries and the end of the range queries.
+	Time time.Time
+	// Range is the time range of the range queries.
+	Range time.Duration
+	// Step is the resolution step of the range queries.
+	Step time.Duration
+}
+
+func (c *SLIQueryCheckConfig) defaults() {
+	if c.Window == 0 {
+		c.Window = 5 * time.Minute
+	}
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	if c.Range == 0 {
+		c.Range = time.Hour
+	}
+	if c.Step == 0 {
+		c.Step = time.Minute
+	}
+}
+
+// SLIQueryCheck is the result of executing an SLO SLI query against Prometheus.
+type SLIQueryCheck struct {
+	SLOID string
+	// Query is the SLI query (e.g `error ratio`, `error`, `total`).
+	Query string
+	// Expr is the rendered query expression.
+	Expr           string
+	Status         SLIQueryCheckStatus
+	InstantSeries  int
+	RangeSeries    int
+	MissingMetrics []string
+	Err            error
+}
+
+// Failed returns true if the query is wrong (can't be executed or uses missing metrics),
+// empty queries are not failures.
+func (s SLIQueryCheck) Failed() bool {
+	return s.Status == SLIQueryCheckStatusError || s.Status == SLIQueryCheckStatusMissingMetrics
+}
+
+// CheckSLIQueries executes the SLOs SLI queries (as instant and range queries) against
+// Prometheus, to verify the SLIs against the real metrics, not only their syntax. The queries
+// without data are checked for the metrics that don't exist on the query range.
+func CheckSLIQueries(ctx context.Context, querier SeriesQuerier, slos []SLO, config SLIQueryCheckConfig) []SLIQueryCheck {
+	config.defaults()
+	start := config.Time.Add(-config.Range)
+
+	checks := []SLIQueryCheck{}
+	for _, slo := range slos {
+		type sliQuery struct {
+			name  string
+			query string
+		}
+
+		var queries []sliQuery
+		switch {
+		case slo.SLI.Raw != nil:
+			queries = []sliQuery{{name: "error ratio", query: slo.SLI.Raw.ErrorRatioQuery}}
+		case slo.SLI.Events != nil:
+			queries = []sliQuery{
+				{name: "error", query: slo.SLI.Events.ErrorQuery},
+				{name: "total", query: slo.SLI.Events.TotalQuery},
+			}
+		}
+
+		for _, q := range queries {
+			check := SLIQueryCheck{SLOID: slo.ID, Query: q.name}
+			checkErr := func(err error) {
+				check.Status = SLIQueryCheckStatusError
+				check.Err = err
+				checks = append(checks, check)
+			}
+
+			expr, err := renderSLIQuery(q.query, config.Window)
+			if err != nil {
+				checkErr(fmt.Errorf("could not render query: %w", err))
+				continue
+			}
+			check.Expr = expr
+
+			check.InstantSeries, err = querier.QuerySeries(ctx, expr, config.Time)
+			if err != nil {
+				checkErr(fmt.Errorf("instant query failed: %w", err))
+				continue
+			}
+			check.RangeSeries, err = querier.QueryRangeSeries(ctx, expr, start, config.Time, config.Step)
+			if err != nil {
+				checkErr(fmt.Errorf("range query failed: %w", err))
+				continue
+			}
+
+			check.Status = SLIQueryCheckStatusOK
+			if check.InstantSeries == 0 || check.RangeSeries == 0 {
+				check.Status = SLIQueryCheckStatusEmpty
+			}
+
+			// Empty queries could be using metrics that don't exist.
+			if check.RangeSeries == 0 {
+				for _, metric := range getPromExprMetrics(expr) {
+					series, err := querier.QueryRangeSeries(ctx, fmt.Sprintf("count(%s)", metric), start, config.Time, config.Step)
+					if err != nil {
+						checkErr(fmt.Errorf("%q metric query failed: %w", metric, err))
+						break
+					}
+					if series == 0 {
+						check.MissingMetrics = append(check.MissingMetrics, metric)
+					}
+				}
+				if check.Err != nil {
+					continue
+				}
+				if len(check.MissingMetrics) > 0 {
+					check.Status = SLIQueryCheckStatusMissingMetrics
+				}
+			}
+
+			checks = append(checks, check)
+		}
+	}
+
+	return checks
+}
+
+func renderSLIQuery(query string, window time.Duration) (string, error) {
+	tpl, err := template.New("sliExpr").Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	err = tpl.Execute(&b, map[string]string{tplKeyWindow: timeDurationToPromStr(window)})
+	if err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// getPromExprMetrics returns the sorted metric names used by a PromQL expression.
+func getPromExprMetrics(expr string) []string {
+	ast, err := parsePromExpr(expr)
+	if err != nil {
+		return nil
+	}
+
+	metrics := map[string]struct{}{}
+	promqlparser.Inspect(ast, func(node promqlparser.Node, path []promqlparser.Node) error {
+		if vs, ok := node.(*promqlparser.VectorSelector); ok && vs.Name != "" {
+			metrics[vs.Name] = struct{}{}
+		}
+		return nil
+	})
+
+	res := make([]string, 0, len(metrics))
+	for m := range metrics {
+		res = append(res, m)
+	}
+	sort.Strings(res)
+
+	return res
+}
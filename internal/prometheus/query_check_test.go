@@ -0,0 +1,123 @@
+package prometheus_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/internal/prometheus/prometheusmock"
+)
+
+func TestCheckSLIQueries(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-1 * time.Hour)
+
+	eventsSLO := prometheus.SLO{
+		ID: "test1",
+		SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+			ErrorQuery: `sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))`,
+			TotalQuery: `sum(rate(http_requests_total[{{.window}}]))`,
+		}},
+	}
+	errorExpr := `sum(rate(http_requests_total{code=~"5.."}[5m]))`
+	totalExpr := `sum(rate(http_requests_total[5m]))`
+
+	tests := map[string]struct {
+		slos      []prometheus.SLO
+		mock      func(m *prometheusmock.SeriesQuerier)
+		expChecks []prometheus.SLIQueryCheck
+	}{
+		"Queries with data should be ok.": {
+			slos: []prometheus.SLO{eventsSLO},
+			mock: func(m *prometheusmock.SeriesQuerier) {
+				m.On("QuerySeries", mock.Anything, errorExpr, now).Once().Return(1, nil)
+				m.On("QueryRangeSeries", mock.Anything, errorExpr, start, now, time.Minute).Once().Return(1, nil)
+				m.On("QuerySeries", mock.Anything, totalExpr, now).Once().Return(1, nil)
+				m.On("QueryRangeSeries", mock.Anything, totalExpr, start, now, time.Minute).Once().Return(1, nil)
+			},
+			expChecks: []prometheus.SLIQueryCheck{
+				{SLOID: "test1", Query: "error", Expr: errorExpr, Status: prometheus.SLIQueryCheckStatusOK, InstantSeries: 1, RangeSeries: 1},
+				{SLOID: "test1", Query: "total", Expr: totalExpr, Status: prometheus.SLIQueryCheckStatusOK, InstantSeries: 1, RangeSeries: 1},
+			},
+		},
+
+		"Queries without data should be empty or have missing metrics.": {
+			slos: []prometheus.SLO{
+				eventsSLO,
+				{
+					ID:  "test2",
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: `sum(rate(errors_total[{{.window}}])) / sum(rate(requests_total[{{.window}}]))`}},
+				},
+			},
+			mock: func(m *prometheusmock.SeriesQuerier) {
+				m.On("QuerySeries", mock.Anything, errorExpr, now).Once().Return(0, nil)
+				m.On("QueryRangeSeries", mock.Anything, errorExpr, start, now, time.Minute).Once().Return(0, nil)
+				m.On("QuerySeries", mock.Anything, totalExpr, now).Once().Return(0, nil)
+				m.On("QueryRangeSeries", mock.Anything, totalExpr, start, now, time.Minute).Once().Return(1, nil)
+				m.On("QueryRangeSeries", mock.Anything, `count(http_requests_total)`, start, now, time.Minute).Once().Return(1, nil)
+
+				expr := `sum(rate(errors_total[5m])) / sum(rate(requests_total[5m]))`
+				m.On("QuerySeries", mock.Anything, expr, now).Once().Return(0, nil)
+				m.On("QueryRangeSeries", mock.Anything, expr, start, now, time.Minute).Once().Return(0, nil)
+				m.On("QueryRangeSeries", mock.Anything, `count(errors_total)`, start, now, time.Minute).Once().Return(0, nil)
+				m.On("QueryRangeSeries", mock.Anything, `count(requests_total)`, start, now, time.Minute).Once().Return(1, nil)
+			},
+			expChecks: []prometheus.SLIQueryCheck{
+				{SLOID: "test1", Query: "error", Expr: errorExpr, Status: prometheus.SLIQueryCheckStatusEmpty},
+				{SLOID: "test1", Query: "total", Expr: totalExpr, Status: prometheus.SLIQueryCheckStatusEmpty, RangeSeries: 1},
+				{
+					SLOID:          "test2",
+					Query:          "error ratio",
+					Expr:           `sum(rate(errors_total[5m])) / sum(rate(requests_total[5m]))`,
+					Status:         prometheus.SLIQueryCheckStatusMissingMetrics,
+					MissingMetrics: []string{"errors_total"},
+				},
+			},
+		},
+
+		"Queries that fail should have errors.": {
+			slos: []prometheus.SLO{
+				eventsSLO,
+				{
+					ID:  "test2",
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: `rate(errors_total[{{.windou}}])`}},
+				},
+			},
+			mock: func(m *prometheusmock.SeriesQuerier) {
+				m.On("QuerySeries", mock.Anything, errorExpr, now).Once().Return(0, fmt.Errorf("something"))
+				m.On("QuerySeries", mock.Anything, totalExpr, now).Once().Return(1, nil)
+				m.On("QueryRangeSeries", mock.Anything, totalExpr, start, now, time.Minute).Once().Return(0, fmt.Errorf("something"))
+			},
+			expChecks: []prometheus.SLIQueryCheck{
+				{SLOID: "test1", Query: "error", Expr: errorExpr, Status: prometheus.SLIQueryCheckStatusError, Err: fmt.Errorf("instant query failed: something")},
+				{SLOID: "test1", Query: "total", Expr: totalExpr, Status: prometheus.SLIQueryCheckStatusError, InstantSeries: 1, Err: fmt.Errorf("range query failed: something")},
+				{SLOID: "test2", Query: "error ratio", Status: prometheus.SLIQueryCheckStatusError, Err: fmt.Errorf(`could not render query: template: sliExpr:1:20: executing "sliExpr" at <.windou>: map has no entry for key "windou"`)},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			m := &prometheusmock.SeriesQuerier{}
+			test.mock(m)
+
+			gotChecks := prometheus.CheckSLIQueries(context.TODO(), m, test.slos, prometheus.SLIQueryCheckConfig{Time: now})
+
+			// Compare the errors by their message.
+			for i := range gotChecks {
+				if gotChecks[i].Err != nil {
+					gotChecks[i].Err = fmt.Errorf("%s", gotChecks[i].Err)
+				}
+			}
+			assert.Equal(test.expChecks, gotChecks)
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"text/template"
 	"time"
 
@@ -17,17 +18,52 @@ import (
 type sliRulesgenFunc func(slo SLO, window time.Duration, alerts alert.MWMBAlertGroup) (*rulefmt.Rule, error)
 
 type sliRecordingRulesGenerator struct {
-	genFunc sliRulesgenFunc
+	genFunc          sliRulesgenFunc
+	alertWindowsOnly bool
 }
 
 // SLIRecordingRulesGenerator knows how to generate the SLI prometheus recording rules
 // form an SLO. Normally these rules are used by the SLO alerts.
-var SLIRecordingRulesGenerator = sliRecordingRulesGenerator{genFunc: factorySLIRecordGenerator}
+var SLIRecordingRulesGenerator = sliRecordingRulesGenerator{genFunc: factorySLIRecordGenerator(eventsSLIRecordGenerator)}
+
+// WithAlertWindowsOnly returns a copy of the generator that only generates the SLI
+// recording rules of the windows required by the SLO enabled alerts, without the SLO
+// time windows ones used by the metadata recording rules.
+func (s sliRecordingRulesGenerator) WithAlertWindowsOnly() sliRecordingRulesGenerator {
+	s.alertWindowsOnly = true
+	return s
+}
+
+// WithZeroTotalGuard returns a copy of the generator that guards the events SLI expressions
+// of every window against zero totals: the windows without events (idle periods) have a 0
+// error ratio instead of a NaN (0/0) series that breaks the error budget calculations.
+func (s sliRecordingRulesGenerator) WithZeroTotalGuard() sliRecordingRulesGenerator {
+	s.genFunc = factorySLIRecordGenerator(zeroTotalGuardEventsSLIRecordGenerator)
+	return s
+}
 
 func (s sliRecordingRulesGenerator) GenerateSLIRecordingRules(ctx context.Context, slo SLO, alerts alert.MWMBAlertGroup) ([]rulefmt.Rule, error) {
 	// Get the windows we need the recording rules.
-	windows := getAlertGroupWindows(alerts)
-	windows = append(windows, slo.TimeWindow) // Add the total time window as a handy helper.
+	var windows []time.Duration
+	if s.alertWindowsOnly {
+		windows = getEnabledAlertsWindows(slo, alerts)
+	} else {
+		windows = getAlertGroupWindows(alerts)
+		windows = append(windows, slo.TimeWindow) // Add the total time window as a handy helper.
+		if slo.Transition != nil && slo.Transition.PreviousTimeWindow != slo.TimeWindow {
+			windows = append(windows, slo.Transition.PreviousTimeWindow) // Required by the transitional rules.
+		}
+		for _, w := range slo.getReportingWindows() {
+			if !containsWindow(windows, w) {
+				windows = append(windows, w) // Required by the reporting rules.
+			}
+		}
+	}
+
+	// Timeslice SLOs windows SLI error ratios are obtained from the time slices.
+	if slo.Timeslice != nil {
+		return s.generateTimesliceSLIRecordingRules(slo, windows, alerts)
+	}
 
 	// Generate the rules
 	rules := make([]rulefmt.Rule, 0, len(windows))
@@ -42,24 +78,62 @@ func (s sliRecordingRulesGenerator) GenerateSLIRecordingRules(ctx context.Contex
 	return rules, nil
 }
 
+// generateTimesliceSLIRecordingRules generates the time slices recording rule, that is 1 when
+// the SLI error ratio of the time slice is greater than the threshold (bad time slice) and 0
+// otherwise, and the SLI error recording rules of the windows as the ratio of bad time slices
+// on the window. The time slices without SLI data (e.g no events) are good ones.
+func (s sliRecordingRulesGenerator) generateTimesliceSLIRecordingRules(slo SLO, windows []time.Duration, alerts alert.MWMBAlertGroup) ([]rulefmt.Rule, error) {
+	sliceRule, err := s.genFunc(slo, slo.Timeslice.Window, alerts)
+	if err != nil {
+		return nil, fmt.Errorf("could not create %q SLO time slice rule: %w", slo.ID, err)
+	}
+	sliceRule.Record = sliTimesliceMetric
+	sliceRule.Expr = fmt.Sprintf("(%s)\n> bool %g\n", strings.TrimSpace(sliceRule.Expr), slo.Timeslice.ErrorRatioThreshold)
+
+	rules := make([]rulefmt.Rule, 0, len(windows)+1)
+	rules = append(rules, *sliceRule)
+	filter := labelsToPromFilter(slo.GetSLOIDPromLabels())
+	for _, window := range windows {
+		strWindow := timeDurationToPromStr(window)
+		rules = append(rules, rulefmt.Rule{
+			Record: slo.GetSLIErrorMetric(window),
+			Expr:   fmt.Sprintf("avg_over_time(%s%s[%s])\n", sliTimesliceMetric, filter, strWindow),
+			// The SLO labels will be obtained from the time slices recording rule.
+			// We only need to set the window.
+			Labels: map[string]string{
+				sloWindowLabelName: strWindow,
+			},
+		})
+	}
+
+	return rules, nil
+}
+
 const (
 	tplKeyWindow = "window"
 )
 
-func factorySLIRecordGenerator(slo SLO, window time.Duration, alerts alert.MWMBAlertGroup) (*rulefmt.Rule, error) {
-	switch {
-	// Optimize the rules that are for the total period time window.
-	case window == slo.TimeWindow:
-		return optimizedSLIRecordGenerator(slo, window, alerts.PageQuick.ShortWindow)
-	// Event based SLI.
-	case slo.SLI.Events != nil:
-		return eventsSLIRecordGenerator(slo, window, alerts)
-	// Raw based SLI.
-	case slo.SLI.Raw != nil:
-		return rawSLIRecordGenerator(slo, window, alerts)
-	}
+// factorySLIRecordGenerator returns the SLI recording rule generator that selects the generator
+// of every window and SLI type, using eventsGen for the events SLIs.
+func factorySLIRecordGenerator(eventsGen sliRulesgenFunc) sliRulesgenFunc {
+	return func(slo SLO, window time.Duration, alerts alert.MWMBAlertGroup) (*rulefmt.Rule, error) {
+		switch {
+		// Optimize the rules that are for the total period time windows.
+		case window == slo.TimeWindow || (slo.Transition != nil && window == slo.Transition.PreviousTimeWindow):
+			return optimizedSLIRecordGenerator(slo, window, alerts.PageQuick.ShortWindow)
+		// Optimize the reporting windows rules, unless the alerts also use the window.
+		case containsWindow(slo.getReportingWindows(), window) && !containsWindow(getAlertGroupWindows(alerts), window):
+			return optimizedSLIRecordGenerator(slo, window, alerts.PageQuick.ShortWindow)
+		// Event based SLI.
+		case slo.SLI.Events != nil:
+			return eventsGen(slo, window, alerts)
+		// Raw based SLI.
+		case slo.SLI.Raw != nil:
+			return rawSLIRecordGenerator(slo, window, alerts)
+		}
 
-	return nil, fmt.Errorf("invalid SLI type")
+		return nil, fmt.Errorf("invalid SLI type")
+	}
 }
 
 func rawSLIRecordGenerator(slo SLO, window time.Duration, alerts alert.MWMBAlertGroup) (*rulefmt.Rule, error) {
@@ -79,9 +153,14 @@ func rawSLIRecordGenerator(slo SLO, window time.Duration, alerts alert.MWMBAlert
 		return nil, fmt.Errorf("could not render SLI expression template: %w", err)
 	}
 
+	expr, err := applyPromExprOffset(b.String(), slo.SLI.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("could not apply SLI offset: %w", err)
+	}
+
 	return &rulefmt.Rule{
 		Record: slo.GetSLIErrorMetric(window),
-		Expr:   b.String(),
+		Expr:   expr,
 		Labels: mergeLabels(
 			slo.GetSLOIDPromLabels(),
 			map[string]string{
@@ -93,10 +172,32 @@ func rawSLIRecordGenerator(slo SLO, window time.Duration, alerts alert.MWMBAlert
 }
 
 func eventsSLIRecordGenerator(slo SLO, window time.Duration, alerts alert.MWMBAlertGroup) (*rulefmt.Rule, error) {
-	const sliExprTplFmt = `(%s)
+	const sliExprTplFmt = `(%[1]s)
 /
-(%s)
+(%[2]s)
+`
+	return eventsTplSLIRecordGenerator(sliExprTplFmt, slo, window)
+}
+
+// zeroTotalGuardEventsSLIRecordGenerator generates the events SLI recording rule guarded
+// against zero totals. The ratio is only calculated when the total is greater than 0, otherwise
+// (idle windows or missing error series) the error ratio is 0. The series are kept with the
+// total expression labels, and if the total has no data, the rule doesn't have data either.
+func zeroTotalGuardEventsSLIRecordGenerator(slo SLO, window time.Duration, alerts alert.MWMBAlertGroup) (*rulefmt.Rule, error) {
+	const sliExprTplFmt = `(
+  (%[1]s)
+  /
+  ((%[2]s) > 0)
+)
+or
+((%[2]s) * 0)
 `
+	return eventsTplSLIRecordGenerator(sliExprTplFmt, slo, window)
+}
+
+// eventsTplSLIRecordGenerator generates the events SLI recording rule using the SLI expression
+// format, the error expression is the first argument and the total expression the second one.
+func eventsTplSLIRecordGenerator(sliExprTplFmt string, slo SLO, window time.Duration) (*rulefmt.Rule, error) {
 	// Generate our first level of template by assembling the error and total expressions.
 	sliExprTpl := fmt.Sprintf(sliExprTplFmt, slo.SLI.Events.ErrorQuery, slo.SLI.Events.TotalQuery)
 
@@ -115,9 +216,14 @@ func eventsSLIRecordGenerator(slo SLO, window time.Duration, alerts alert.MWMBAl
 		return nil, fmt.Errorf("could not render SLI expression template: %w", err)
 	}
 
+	expr, err := applyPromExprOffset(b.String(), slo.SLI.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("could not apply SLI offset: %w", err)
+	}
+
 	return &rulefmt.Rule{
 		Record: slo.GetSLIErrorMetric(window),
-		Expr:   b.String(),
+		Expr:   expr,
 		Labels: mergeLabels(
 			slo.GetSLOIDPromLabels(),
 			map[string]string{
@@ -181,26 +287,25 @@ count_over_time({{.metric}}{{.filter}}[{{.window}}])
 	}, nil
 }
 
-type metadataRecordingRulesGenerator bool
+type metadataRecordingRulesGenerator struct {
+	errorBudgetForecast bool
+}
 
 // MetadataRecordingRulesGenerator knows how to generate the metadata prometheus recording rules
 // from an SLO.
-const MetadataRecordingRulesGenerator = metadataRecordingRulesGenerator(false)
+var MetadataRecordingRulesGenerator = metadataRecordingRulesGenerator{}
+
+// WithErrorBudgetForecast returns a copy of the generator that also generates the error budget
+// exhaustion forecast recording rule: the seconds left until the period error budget is
+// exhausted, predicted with the period burn rate trend of the page quick alert long window.
+func (m metadataRecordingRulesGenerator) WithErrorBudgetForecast() metadataRecordingRulesGenerator {
+	m.errorBudgetForecast = true
+	return m
+}
 
 func (m metadataRecordingRulesGenerator) GenerateMetadataRecordingRules(ctx context.Context, info info.Info, slo SLO, alerts alert.MWMBAlertGroup) ([]rulefmt.Rule, error) {
 	labels := mergeLabels(slo.GetSLOIDPromLabels(), slo.Labels)
 
-	// Metatada Recordings.
-	const (
-		metricSLOObjectiveRatio                  = "slo:objective:ratio"
-		metricSLOErrorBudgetRatio                = "slo:error_budget:ratio"
-		metricSLOTimePeriodDays                  = "slo:time_period:days"
-		metricSLOCurrentBurnRateRatio            = "slo:current_burn_rate:ratio"
-		metricSLOPeriodBurnRateRatio             = "slo:period_burn_rate:ratio"
-		metricSLOPeriodErrorBudgetRemainingRatio = "slo:period_error_budget_remaining:ratio"
-		metricSLOInfo                            = "sloth_slo_info"
-	)
-
 	sloObjectiveRatio := slo.Objective / 100
 
 	sloFilter := labelsToPromFilter(slo.GetSLOIDPromLabels())
@@ -286,10 +391,196 @@ func (m metadataRecordingRulesGenerator) GenerateMetadataRecordingRules(ctx cont
 		},
 	}
 
+	// Error budget exhaustion forecast.
+	if m.errorBudgetForecast {
+		var exhaustionExpr bytes.Buffer
+		err = errorBudgetExhaustionRecordingExprTpl.Execute(&exhaustionExpr, map[string]string{
+			"ErrorBudgetRemainingMetric": metricSLOPeriodErrorBudgetRemainingRatio,
+			"BurnRateMetric":             metricSLOPeriodBurnRateRatio,
+			"MetricFilter":               sloFilter,
+			"Window":                     timeDurationToPromStr(alerts.PageQuick.LongWindow),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not render error budget exhaustion prometheus metadata recording rule expression: %w", err)
+		}
+
+		rules = append(rules, rulefmt.Rule{
+			Record: metricSLOErrorBudgetExhaustionSeconds,
+			Expr:   exhaustionExpr.String(),
+			Labels: labels,
+		})
+	}
+
+	// Transitional rules with the previous SLO objective and time window.
+	if slo.Transition != nil {
+		transitionRules, err := m.generateTransitionRecordingRules(slo, labels)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, transitionRules...)
+	}
+
+	// Reporting rules of the SLO extra reporting windows.
+	for _, window := range slo.getReportingWindows() {
+		reportingRules, err := m.generateReportingRecordingRules(slo, window, labels)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, reportingRules...)
+	}
+
 	return rules, nil
 }
 
+func (m metadataRecordingRulesGenerator) generateReportingRecordingRules(slo SLO, window time.Duration, labels map[string]string) ([]rulefmt.Rule, error) {
+	const (
+		metricSLOReportingTimePeriodDays                  = "slo:reporting_time_period:days"
+		metricSLOReportingPeriodBurnRateRatio             = "slo:reporting_period_burn_rate:ratio"
+		metricSLOReportingPeriodErrorBudgetRemainingRatio = "slo:reporting_period_error_budget_remaining:ratio"
+	)
+
+	// The reporting rules of every window are identified by the window label.
+	reportingLabels := mergeLabels(labels, map[string]string{
+		sloWindowLabelName: timeDurationToPromStr(window),
+	})
+	sloFilter := labelsToPromFilter(slo.GetSLOIDPromLabels())
+	reportingFilter := labelsToPromFilter(mergeLabels(slo.GetSLOIDPromLabels(), map[string]string{
+		sloWindowLabelName: timeDurationToPromStr(window),
+	}))
+
+	var periodBurnRateExpr bytes.Buffer
+	err := burnRateRecordingExprTpl.Execute(&periodBurnRateExpr, map[string]string{
+		"SLIErrorMetric":         slo.GetSLIErrorMetric(window),
+		"MetricFilter":           sloFilter,
+		"SLOIDName":              sloIDLabelName,
+		"SLOLabelName":           sloNameLabelName,
+		"SLOServiceName":         sloServiceLabelName,
+		"ErrorBudgetRatioMetric": metricSLOErrorBudgetRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not render %s reporting period burn rate prometheus metadata recording rule expression: %w", window, err)
+	}
+
+	return []rulefmt.Rule{
+		// Reporting total period.
+		{
+			Record: metricSLOReportingTimePeriodDays,
+			Expr:   fmt.Sprintf(`vector(%g)`, window.Hours()/24),
+			Labels: reportingLabels,
+		},
+
+		// Reporting total period burn rate.
+		{
+			Record: metricSLOReportingPeriodBurnRateRatio,
+			Expr:   periodBurnRateExpr.String(),
+			Labels: reportingLabels,
+		},
+
+		// Reporting total error budget remaining period.
+		{
+			Record: metricSLOReportingPeriodErrorBudgetRemainingRatio,
+			Expr:   fmt.Sprintf(`1 - %s%s`, metricSLOReportingPeriodBurnRateRatio, reportingFilter),
+			Labels: reportingLabels,
+		},
+	}, nil
+}
+
+func (m metadataRecordingRulesGenerator) generateTransitionRecordingRules(slo SLO, labels map[string]string) ([]rulefmt.Rule, error) {
+	const (
+		metricSLOPreviousObjectiveRatio                  = "slo:previous_objective:ratio"
+		metricSLOPreviousErrorBudgetRatio                = "slo:previous_error_budget:ratio"
+		metricSLOPreviousTimePeriodDays                  = "slo:previous_time_period:days"
+		metricSLOPreviousPeriodBurnRateRatio             = "slo:previous_period_burn_rate:ratio"
+		metricSLOPreviousPeriodErrorBudgetRemainingRatio = "slo:previous_period_error_budget_remaining:ratio"
+		metricSLOTransitionInfo                          = "sloth_slo_transition_info"
+	)
+
+	previousObjectiveRatio := slo.Transition.PreviousObjective / 100
+	sloFilter := labelsToPromFilter(slo.GetSLOIDPromLabels())
+
+	var periodBurnRateExpr bytes.Buffer
+	err := burnRateRecordingExprTpl.Execute(&periodBurnRateExpr, map[string]string{
+		"SLIErrorMetric":         slo.GetSLIErrorMetric(slo.Transition.PreviousTimeWindow),
+		"MetricFilter":           sloFilter,
+		"SLOIDName":              sloIDLabelName,
+		"SLOLabelName":           sloNameLabelName,
+		"SLOServiceName":         sloServiceLabelName,
+		"ErrorBudgetRatioMetric": metricSLOPreviousErrorBudgetRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not render previous period burn rate prometheus metadata recording rule expression: %w", err)
+	}
+
+	transitionLabels := map[string]string{
+		sloPreviousObjectiveLabelName:  fmt.Sprintf("%g", slo.Transition.PreviousObjective),
+		sloPreviousTimeWindowLabelName: timeDurationToPromStr(slo.Transition.PreviousTimeWindow),
+	}
+	if slo.Transition.ChangedAt != "" {
+		transitionLabels[sloChangedAtLabelName] = slo.Transition.ChangedAt
+	}
+
+	return []rulefmt.Rule{
+		// Previous SLO Objective.
+		{
+			Record: metricSLOPreviousObjectiveRatio,
+			Expr:   fmt.Sprintf(`vector(%g)`, previousObjectiveRatio),
+			Labels: labels,
+		},
+
+		// Previous error budget.
+		{
+			Record: metricSLOPreviousErrorBudgetRatio,
+			Expr:   fmt.Sprintf(`vector(1-%g)`, previousObjectiveRatio),
+			Labels: labels,
+		},
+
+		// Previous total period.
+		{
+			Record: metricSLOPreviousTimePeriodDays,
+			Expr:   fmt.Sprintf(`vector(%g)`, slo.Transition.PreviousTimeWindow.Hours()/24),
+			Labels: labels,
+		},
+
+		// Previous total period burn rate.
+		{
+			Record: metricSLOPreviousPeriodBurnRateRatio,
+			Expr:   periodBurnRateExpr.String(),
+			Labels: labels,
+		},
+
+		// Previous total error budget remaining period.
+		{
+			Record: metricSLOPreviousPeriodErrorBudgetRemainingRatio,
+			Expr:   fmt.Sprintf(`1 - %s%s`, metricSLOPreviousPeriodBurnRateRatio, sloFilter),
+			Labels: labels,
+		},
+
+		// Transition info.
+		{
+			Record: metricSLOTransitionInfo,
+			Expr:   `vector(1)`,
+			Labels: mergeLabels(labels, transitionLabels),
+		},
+	}, nil
+}
+
 var burnRateRecordingExprTpl = template.Must(template.New("burnRateExpr").Option("missingkey=error").Parse(`{{ .SLIErrorMetric }}{{ .MetricFilter }}
 / on({{ .SLOIDName }}, {{ .SLOLabelName }}, {{ .SLOServiceName }}) group_left
 {{ .ErrorBudgetRatioMetric }}{{ .MetricFilter }}
 `))
+
+// errorBudgetExhaustionRecordingExprTpl divides the remaining error budget by the period burn
+// rate increase per second, predicted with `predict_linear` (the prediction of one second
+// ahead minus the current one). The SLOs that are not increasing their period burn rate
+// will not exhaust the error budget, so they don't have forecast. Both sides are matched on all
+// their labels, so the SLOs with multiple SLI series (e.g per cluster) have a forecast per series.
+var errorBudgetExhaustionRecordingExprTpl = template.Must(template.New("errorBudgetExhaustionExpr").Option("missingkey=error").Parse(`clamp_min({{ .ErrorBudgetRemainingMetric }}{{ .MetricFilter }}, 0)
+/
+(
+  (
+    predict_linear({{ .BurnRateMetric }}{{ .MetricFilter }}[{{ .Window }}], 1)
+    -
+    predict_linear({{ .BurnRateMetric }}{{ .MetricFilter }}[{{ .Window }}], 0)
+  ) > 0
+)
+`))
@@ -2,11 +2,14 @@ package prometheus_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/slok/sloth/internal/alert"
 	"github.com/slok/sloth/internal/info"
@@ -290,6 +293,111 @@ func TestGenerateSLIRecordingRules(t *testing.T) {
 			},
 		},
 
+		"Having an SLO with SLI offset should create the recording rules with the offset on all the windows.": {
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				SLI: prometheus.SLI{
+					Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `sum(rate(my_metric{code=~"5.."}[{{.window}}])) / sum(rate(my_metric[{{.window}}]))`,
+					},
+					Offset: 5 * time.Minute,
+				},
+				Labels: map[string]string{
+					"kind": "test",
+				},
+			},
+			alertGroup: getAlertGroup(),
+			expRules: []rulefmt.Rule{
+				{
+					Record: "slo:sli_error:ratio_rate5m",
+					Expr:   `(sum(rate(my_metric{code=~"5.."}[5m] offset 5m)) / sum(rate(my_metric[5m] offset 5m)))`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "5m",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate30m",
+					Expr:   `(sum(rate(my_metric{code=~"5.."}[30m] offset 5m)) / sum(rate(my_metric[30m] offset 5m)))`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "30m",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate1h",
+					Expr:   `(sum(rate(my_metric{code=~"5.."}[1h] offset 5m)) / sum(rate(my_metric[1h] offset 5m)))`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate2h",
+					Expr:   `(sum(rate(my_metric{code=~"5.."}[2h] offset 5m)) / sum(rate(my_metric[2h] offset 5m)))`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "2h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate6h",
+					Expr:   `(sum(rate(my_metric{code=~"5.."}[6h] offset 5m)) / sum(rate(my_metric[6h] offset 5m)))`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "6h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate1d",
+					Expr:   `(sum(rate(my_metric{code=~"5.."}[1d] offset 5m)) / sum(rate(my_metric[1d] offset 5m)))`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1d",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate3d",
+					Expr:   `(sum(rate(my_metric{code=~"5.."}[3d] offset 5m)) / sum(rate(my_metric[3d] offset 5m)))`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "3d",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate30d",
+					Expr:   "sum_over_time(slo:sli_error:ratio_rate5m{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n/ ignoring (sloth_window)\ncount_over_time(slo:sli_error:ratio_rate5m{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n",
+					Labels: map[string]string{
+						"sloth_window": "30d",
+					},
+				},
+			},
+		},
+
 		"An SLO alert with duplicated time windows should appear once and sorted.": {
 			slo: prometheus.SLO{
 				ID:         "test",
@@ -306,92 +414,653 @@ func TestGenerateSLIRecordingRules(t *testing.T) {
 					"kind": "test",
 				},
 			},
-			alertGroup: alert.MWMBAlertGroup{
-				PageQuick:   alert.MWMBAlert{ShortWindow: 3 * time.Hour, LongWindow: 2 * time.Hour},
-				PageSlow:    alert.MWMBAlert{ShortWindow: 3 * time.Hour, LongWindow: 1 * time.Hour},
-				TicketQuick: alert.MWMBAlert{ShortWindow: 1 * time.Hour, LongWindow: 2 * time.Hour},
-				TicketSlow:  alert.MWMBAlert{ShortWindow: 2 * time.Hour, LongWindow: 1 * time.Hour},
-			},
+			alertGroup: alert.MWMBAlertGroup{
+				PageQuick:   alert.MWMBAlert{ShortWindow: 3 * time.Hour, LongWindow: 2 * time.Hour},
+				PageSlow:    alert.MWMBAlert{ShortWindow: 3 * time.Hour, LongWindow: 1 * time.Hour},
+				TicketQuick: alert.MWMBAlert{ShortWindow: 1 * time.Hour, LongWindow: 2 * time.Hour},
+				TicketSlow:  alert.MWMBAlert{ShortWindow: 2 * time.Hour, LongWindow: 1 * time.Hour},
+			},
+			expRules: []rulefmt.Rule{
+				{
+					Record: "slo:sli_error:ratio_rate1h",
+					Expr:   "(rate(my_metric[1h]{error=\"true\"}))\n/\n(rate(my_metric[1h]))\n",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate2h",
+					Expr:   "(rate(my_metric[2h]{error=\"true\"}))\n/\n(rate(my_metric[2h]))\n",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "2h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate3h",
+					Expr:   "(rate(my_metric[3h]{error=\"true\"}))\n/\n(rate(my_metric[3h]))\n",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "3h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate30d",
+					Expr:   "sum_over_time(slo:sli_error:ratio_rate3h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n/ ignoring (sloth_window)\ncount_over_time(slo:sli_error:ratio_rate3h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n",
+					Labels: map[string]string{
+						"sloth_window": "30d",
+					},
+				},
+			},
+		},
+
+		"An SLO with reporting windows should create the optimized reporting windows recording rules once.": {
+			slo: prometheus.SLO{
+				ID:               "test",
+				Name:             "test-name",
+				Service:          "test-svc",
+				TimeWindow:       30 * 24 * time.Hour,
+				ReportingWindows: []time.Duration{7 * 24 * time.Hour, 30 * 24 * time.Hour, 7 * 24 * time.Hour, 3 * time.Hour},
+				SLI: prometheus.SLI{
+					Events: &prometheus.SLIEvents{
+						ErrorQuery: `rate(my_metric[{{.window}}]{error="true"})`,
+						TotalQuery: `rate(my_metric[{{.window}}])`,
+					},
+				},
+			},
+			alertGroup: alert.MWMBAlertGroup{
+				PageQuick:   alert.MWMBAlert{ShortWindow: 1 * time.Hour, LongWindow: 3 * time.Hour},
+				PageSlow:    alert.MWMBAlert{ShortWindow: 1 * time.Hour, LongWindow: 3 * time.Hour},
+				TicketQuick: alert.MWMBAlert{ShortWindow: 1 * time.Hour, LongWindow: 3 * time.Hour},
+				TicketSlow:  alert.MWMBAlert{ShortWindow: 1 * time.Hour, LongWindow: 3 * time.Hour},
+			},
+			expRules: []rulefmt.Rule{
+				{
+					Record: "slo:sli_error:ratio_rate1h",
+					Expr:   "(rate(my_metric[1h]{error=\"true\"}))\n/\n(rate(my_metric[1h]))\n",
+					Labels: map[string]string{
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate3h",
+					Expr:   "(rate(my_metric[3h]{error=\"true\"}))\n/\n(rate(my_metric[3h]))\n",
+					Labels: map[string]string{
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "3h",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate30d",
+					Expr:   "sum_over_time(slo:sli_error:ratio_rate1h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n/ ignoring (sloth_window)\ncount_over_time(slo:sli_error:ratio_rate1h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n",
+					Labels: map[string]string{
+						"sloth_window": "30d",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate1w",
+					Expr:   "sum_over_time(slo:sli_error:ratio_rate1h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[1w])\n/ ignoring (sloth_window)\ncount_over_time(slo:sli_error:ratio_rate1h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[1w])\n",
+					Labels: map[string]string{
+						"sloth_window": "1w",
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotRules, err := prometheus.SLIRecordingRulesGenerator.GenerateSLIRecordingRules(context.TODO(), test.slo, test.alertGroup)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expRules, gotRules)
+			}
+		})
+	}
+}
+
+func TestGenerateSLIRecordingRulesAlertWindowsOnly(t *testing.T) {
+	tests := map[string]struct {
+		slo        prometheus.SLO
+		expRecWins []string
+	}{
+		"Having all the alerts enabled should only create the alerts windows recording rules.": {
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				SLI:        prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: `rate(my_metric[{{.window}}])`}},
+			},
+			expRecWins: []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"},
+		},
+
+		"Having the ticket alert disabled should only create the page alert windows recording rules.": {
+			slo: prometheus.SLO{
+				ID:              "test",
+				Name:            "test-name",
+				Service:         "test-svc",
+				TimeWindow:      30 * 24 * time.Hour,
+				SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: `rate(my_metric[{{.window}}])`}},
+				TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+			},
+			expRecWins: []string{"5m", "30m", "1h", "6h"},
+		},
+
+		"Having the SLO transition should not create the previous time window recording rule.": {
+			slo: prometheus.SLO{
+				ID:              "test",
+				Name:            "test-name",
+				Service:         "test-svc",
+				TimeWindow:      30 * 24 * time.Hour,
+				SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: `rate(my_metric[{{.window}}])`}},
+				TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				Transition:      &prometheus.SLOTransition{PreviousObjective: 99, PreviousTimeWindow: 28 * 24 * time.Hour},
+			},
+			expRecWins: []string{"5m", "30m", "1h", "6h"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gen := prometheus.SLIRecordingRulesGenerator.WithAlertWindowsOnly()
+			gotRules, err := gen.GenerateSLIRecordingRules(context.TODO(), test.slo, getAlertGroup())
+			if assert.NoError(err) {
+				gotRecWins := []string{}
+				for _, r := range gotRules {
+					gotRecWins = append(gotRecWins, r.Labels["sloth_window"])
+				}
+				assert.Equal(test.expRecWins, gotRecWins)
+			}
+		})
+	}
+}
+
+func TestGenerateSLIRecordingRulesZeroTotalGuard(t *testing.T) {
+	tests := map[string]struct {
+		slo      prometheus.SLO
+		expExprs map[string]string
+	}{
+		"Events SLIs should guard the windows expressions against zero totals.": {
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+					ErrorQuery: `sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))`,
+					TotalQuery: `sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))`,
+				}},
+			},
+			expExprs: map[string]string{
+				"5m": `(
+  (sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[5m])))
+  /
+  ((sum(rate(http_request_duration_seconds_count{job="myservice"}[5m]))) > 0)
+)
+or
+((sum(rate(http_request_duration_seconds_count{job="myservice"}[5m]))) * 0)
+`,
+				"3d": `(
+  (sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[3d])))
+  /
+  ((sum(rate(http_request_duration_seconds_count{job="myservice"}[3d]))) > 0)
+)
+or
+((sum(rate(http_request_duration_seconds_count{job="myservice"}[3d]))) * 0)
+`,
+				"30d": `sum_over_time(slo:sli_error:ratio_rate5m{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[30d])
+/ ignoring (sloth_window)
+count_over_time(slo:sli_error:ratio_rate5m{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[30d])
+`,
+			},
+		},
+
+		"Raw SLIs should not be guarded.": {
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				SLI:        prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: `rate(my_metric[{{.window}}])`}},
+			},
+			expExprs: map[string]string{
+				"5m": `(rate(my_metric[5m]))`,
+				"3d": `(rate(my_metric[3d]))`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gen := prometheus.SLIRecordingRulesGenerator.WithZeroTotalGuard()
+			gotRules, err := gen.GenerateSLIRecordingRules(context.TODO(), test.slo, getAlertGroup())
+			if assert.NoError(err) {
+				gotExprs := map[string]string{}
+				for _, r := range gotRules {
+					if _, ok := test.expExprs[r.Labels["sloth_window"]]; ok {
+						gotExprs[r.Labels["sloth_window"]] = r.Expr
+					}
+				}
+				assert.Equal(test.expExprs, gotExprs)
+			}
+		})
+	}
+}
+
+func TestGenerateSLIRecordingRulesTimeslice(t *testing.T) {
+	tests := map[string]struct {
+		slo      prometheus.SLO
+		expRules []rulefmt.Rule
+	}{
+		"Timeslice SLOs should generate the time slices rule and the windows bad time slices ratio rules.": {
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				TimeWindow: 30 * 24 * time.Hour,
+				SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+					ErrorQuery: `sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))`,
+					TotalQuery: `sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))`,
+				}},
+				Labels:    map[string]string{"kind": "test"},
+				Timeslice: &prometheus.SLOTimeslice{Window: time.Minute, ErrorRatioThreshold: 0.05},
+			},
+			expRules: []rulefmt.Rule{
+				{
+					Record: "slo:sli_timeslice_error:bool",
+					Expr: `((sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[1m])))
+/
+(sum(rate(http_request_duration_seconds_count{job="myservice"}[1m]))))
+> bool 0.05
+`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1m",
+					},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate5m",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[5m])
+`,
+					Labels: map[string]string{"sloth_window": "5m"},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate30m",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[30m])
+`,
+					Labels: map[string]string{"sloth_window": "30m"},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate1h",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[1h])
+`,
+					Labels: map[string]string{"sloth_window": "1h"},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate2h",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[2h])
+`,
+					Labels: map[string]string{"sloth_window": "2h"},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate6h",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[6h])
+`,
+					Labels: map[string]string{"sloth_window": "6h"},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate1d",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[1d])
+`,
+					Labels: map[string]string{"sloth_window": "1d"},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate3d",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[3d])
+`,
+					Labels: map[string]string{"sloth_window": "3d"},
+				},
+				{
+					Record: "slo:sli_error:ratio_rate30d",
+					Expr: `avg_over_time(slo:sli_timeslice_error:bool{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[30d])
+`,
+					Labels: map[string]string{"sloth_window": "30d"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotRules, err := prometheus.SLIRecordingRulesGenerator.GenerateSLIRecordingRules(context.TODO(), test.slo, getAlertGroup())
+			if assert.NoError(err) {
+				assert.Equal(test.expRules, gotRules)
+			}
+		})
+	}
+}
+
+func TestGenerateMetaRecordingRules(t *testing.T) {
+	tests := map[string]struct {
+		info       info.Info
+		slo        prometheus.SLO
+		alertGroup alert.MWMBAlertGroup
+		expRules   []rulefmt.Rule
+		expErr     bool
+	}{
+		"Having and SLO an its mwmb alerts should create the metadata recording rules.": {
+			info: info.Info{
+				Version: "test-ver",
+				Mode:    info.ModeTest,
+				Spec:    "test/v1",
+			},
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				Objective:  99.9,
+				TimeWindow: 30 * 24 * time.Hour,
+				Labels: map[string]string{
+					"kind": "test",
+				},
+			},
+			alertGroup: getAlertGroup(),
+			expRules: []rulefmt.Rule{
+				{
+					Record: "slo:objective:ratio",
+					Expr:   "vector(0.9990000000000001)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:error_budget:ratio",
+					Expr:   "vector(1-0.9990000000000001)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:time_period:days",
+					Expr:   "vector(30)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:current_burn_rate:ratio",
+					Expr: `slo:sli_error:ratio_rate5m{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+/ on(sloth_id, sloth_slo, sloth_service) group_left
+slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:period_burn_rate:ratio",
+					Expr: `slo:sli_error:ratio_rate30d{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+/ on(sloth_id, sloth_slo, sloth_service) group_left
+slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:period_error_budget_remaining:ratio",
+					Expr:   `1 - slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "sloth_slo_info",
+					Expr:   `vector(1)`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_version": "test-ver",
+						"sloth_mode":    "test",
+						"sloth_spec":    "test/v1",
+					},
+				},
+			},
+		},
+
+		"Having and SLO with a transition should create the metadata and transitional recording rules.": {
+			info: info.Info{
+				Version: "test-ver",
+				Mode:    info.ModeTest,
+				Spec:    "test/v1",
+			},
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				Objective:  99.9,
+				TimeWindow: 30 * 24 * time.Hour,
+				Labels: map[string]string{
+					"kind": "test",
+				},
+				Transition: &prometheus.SLOTransition{
+					PreviousObjective:  99.5,
+					PreviousTimeWindow: 28 * 24 * time.Hour,
+					ChangedAt:          "2021-06-30",
+				},
+			},
+			alertGroup: getAlertGroup(),
 			expRules: []rulefmt.Rule{
 				{
-					Record: "slo:sli_error:ratio_rate1h",
-					Expr:   "(rate(my_metric[1h]{error=\"true\"}))\n/\n(rate(my_metric[1h]))\n",
+					Record: "slo:objective:ratio",
+					Expr:   "vector(0.9990000000000001)",
 					Labels: map[string]string{
 						"kind":          "test",
 						"sloth_service": "test-svc",
 						"sloth_slo":     "test-name",
 						"sloth_id":      "test",
-						"sloth_window":  "1h",
 					},
 				},
 				{
-					Record: "slo:sli_error:ratio_rate2h",
-					Expr:   "(rate(my_metric[2h]{error=\"true\"}))\n/\n(rate(my_metric[2h]))\n",
+					Record: "slo:error_budget:ratio",
+					Expr:   "vector(1-0.9990000000000001)",
 					Labels: map[string]string{
 						"kind":          "test",
 						"sloth_service": "test-svc",
 						"sloth_slo":     "test-name",
 						"sloth_id":      "test",
-						"sloth_window":  "2h",
 					},
 				},
 				{
-					Record: "slo:sli_error:ratio_rate3h",
-					Expr:   "(rate(my_metric[3h]{error=\"true\"}))\n/\n(rate(my_metric[3h]))\n",
+					Record: "slo:time_period:days",
+					Expr:   "vector(30)",
 					Labels: map[string]string{
 						"kind":          "test",
 						"sloth_service": "test-svc",
 						"sloth_slo":     "test-name",
 						"sloth_id":      "test",
-						"sloth_window":  "3h",
 					},
 				},
 				{
-					Record: "slo:sli_error:ratio_rate30d",
-					Expr:   "sum_over_time(slo:sli_error:ratio_rate3h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n/ ignoring (sloth_window)\ncount_over_time(slo:sli_error:ratio_rate3h{sloth_id=\"test\", sloth_service=\"test-svc\", sloth_slo=\"test-name\"}[30d])\n",
+					Record: "slo:current_burn_rate:ratio",
+					Expr: `slo:sli_error:ratio_rate5m{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+/ on(sloth_id, sloth_slo, sloth_service) group_left
+slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+`,
 					Labels: map[string]string{
-						"sloth_window": "30d",
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:period_burn_rate:ratio",
+					Expr: `slo:sli_error:ratio_rate30d{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+/ on(sloth_id, sloth_slo, sloth_service) group_left
+slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:period_error_budget_remaining:ratio",
+					Expr:   `1 - slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "sloth_slo_info",
+					Expr:   `vector(1)`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_version": "test-ver",
+						"sloth_mode":    "test",
+						"sloth_spec":    "test/v1",
+					},
+				},
+				{
+					Record: "slo:previous_objective:ratio",
+					Expr:   "vector(0.995)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:previous_error_budget:ratio",
+					Expr:   "vector(1-0.995)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:previous_time_period:days",
+					Expr:   "vector(28)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:previous_period_burn_rate:ratio",
+					Expr: `slo:sli_error:ratio_rate4w{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+/ on(sloth_id, sloth_slo, sloth_service) group_left
+slo:previous_error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "slo:previous_period_error_budget_remaining:ratio",
+					Expr:   `1 - slo:previous_period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+					},
+				},
+				{
+					Record: "sloth_slo_transition_info",
+					Expr:   `vector(1)`,
+					Labels: map[string]string{
+						"kind":                       "test",
+						"sloth_service":              "test-svc",
+						"sloth_slo":                  "test-name",
+						"sloth_id":                   "test",
+						"sloth_previous_objective":   "99.5",
+						"sloth_previous_time_window": "4w",
+						"sloth_changed_at":           "2021-06-30",
 					},
 				},
 			},
 		},
-	}
-
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
-
-			gotRules, err := prometheus.SLIRecordingRulesGenerator.GenerateSLIRecordingRules(context.TODO(), test.slo, test.alertGroup)
 
-			if test.expErr {
-				assert.Error(err)
-			} else if assert.NoError(err) {
-				assert.Equal(test.expRules, gotRules)
-			}
-		})
-	}
-}
-
-func TestGenerateMetaRecordingRules(t *testing.T) {
-	tests := map[string]struct {
-		info       info.Info
-		slo        prometheus.SLO
-		alertGroup alert.MWMBAlertGroup
-		expRules   []rulefmt.Rule
-		expErr     bool
-	}{
-		"Having and SLO an its mwmb alerts should create the metadata recording rules.": {
+		"Having and SLO with reporting windows should create the metadata and reporting recording rules of every window.": {
 			info: info.Info{
 				Version: "test-ver",
 				Mode:    info.ModeTest,
 				Spec:    "test/v1",
 			},
 			slo: prometheus.SLO{
-				ID:         "test",
-				Name:       "test-name",
-				Service:    "test-svc",
-				Objective:  99.9,
-				TimeWindow: 30 * 24 * time.Hour,
+				ID:               "test",
+				Name:             "test-name",
+				Service:          "test-svc",
+				Objective:        99.9,
+				TimeWindow:       30 * 24 * time.Hour,
+				ReportingWindows: []time.Duration{90 * 24 * time.Hour, 30 * 24 * time.Hour, 7 * 24 * time.Hour},
 				Labels: map[string]string{
 					"kind": "test",
 				},
@@ -477,6 +1146,78 @@ slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="tes
 						"sloth_spec":    "test/v1",
 					},
 				},
+				{
+					Record: "slo:reporting_time_period:days",
+					Expr:   "vector(7)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1w",
+					},
+				},
+				{
+					Record: "slo:reporting_period_burn_rate:ratio",
+					Expr: `slo:sli_error:ratio_rate1w{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+/ on(sloth_id, sloth_slo, sloth_service) group_left
+slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1w",
+					},
+				},
+				{
+					Record: "slo:reporting_period_error_budget_remaining:ratio",
+					Expr:   `1 - slo:reporting_period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", sloth_window="1w"}`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "1w",
+					},
+				},
+				{
+					Record: "slo:reporting_time_period:days",
+					Expr:   "vector(90)",
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "90d",
+					},
+				},
+				{
+					Record: "slo:reporting_period_burn_rate:ratio",
+					Expr: `slo:sli_error:ratio_rate90d{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+/ on(sloth_id, sloth_slo, sloth_service) group_left
+slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}
+`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "90d",
+					},
+				},
+				{
+					Record: "slo:reporting_period_error_budget_remaining:ratio",
+					Expr:   `1 - slo:reporting_period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", sloth_window="90d"}`,
+					Labels: map[string]string{
+						"kind":          "test",
+						"sloth_service": "test-svc",
+						"sloth_slo":     "test-name",
+						"sloth_id":      "test",
+						"sloth_window":  "90d",
+					},
+				},
 			},
 		},
 	}
@@ -495,3 +1236,118 @@ slo:error_budget:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="tes
 		})
 	}
 }
+
+func TestGenerateMetaRecordingRulesErrorBudgetForecast(t *testing.T) {
+	tests := map[string]struct {
+		slo     prometheus.SLO
+		expRule *rulefmt.Rule
+	}{
+		"Having an SLO should create the error budget exhaustion forecast recording rule.": {
+			slo: prometheus.SLO{
+				ID:         "test",
+				Name:       "test-name",
+				Service:    "test-svc",
+				Objective:  99.9,
+				TimeWindow: 30 * 24 * time.Hour,
+				Labels: map[string]string{
+					"kind": "test",
+				},
+			},
+			expRule: &rulefmt.Rule{
+				Record: "slo:error_budget:exhaustion_seconds",
+				Expr: `clamp_min(slo:period_error_budget_remaining:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}, 0)
+/
+(
+  (
+    predict_linear(slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[1h], 1)
+    -
+    predict_linear(slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name"}[1h], 0)
+  ) > 0
+)
+`,
+				Labels: map[string]string{
+					"kind":          "test",
+					"sloth_service": "test-svc",
+					"sloth_slo":     "test-name",
+					"sloth_id":      "test",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gen := prometheus.MetadataRecordingRulesGenerator.WithErrorBudgetForecast()
+			gotRules, err := gen.GenerateMetadataRecordingRules(context.TODO(), info.Info{}, test.slo, getAlertGroup())
+			if assert.NoError(err) {
+				var gotRule *rulefmt.Rule
+				for i, r := range gotRules {
+					if r.Record == test.expRule.Record {
+						gotRule = &gotRules[i]
+					}
+				}
+				assert.Equal(test.expRule, gotRule)
+			}
+		})
+	}
+}
+
+func TestGenerateMetaRecordingRulesErrorBudgetForecastEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		series    string
+		expResult string
+	}{
+		"Having an SLO with multiple SLI series, should forecast the error budget exhaustion of every series.": {
+			series: `
+  slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="a"} 0.1+0.01x10
+  slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="b"} 0.2+0.02x10
+  slo:period_error_budget_remaining:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="a"} 0.9-0.01x10
+  slo:period_error_budget_remaining:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="b"} 0.8-0.02x10
+`,
+			expResult: `
+  {sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="a"} 4800
+  {sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="b"} 1800
+`,
+		},
+
+		"Having an SLO series that is not increasing its burn rate, shouldn't forecast its error budget exhaustion.": {
+			series: `
+  slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="a"} 0.1+0.01x10
+  slo:period_burn_rate:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="b"} 0.5+0x10
+  slo:period_error_budget_remaining:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="a"} 0.9-0.01x10
+  slo:period_error_budget_remaining:ratio{sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="b"} 0.5+0x10
+`,
+			expResult: `
+  {sloth_id="test", sloth_service="test-svc", sloth_slo="test-name", cluster="a"} 4800
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			slo := prometheus.SLO{ID: "test", Name: "test-name", Service: "test-svc", Objective: 99.9, TimeWindow: 30 * 24 * time.Hour}
+			gen := prometheus.MetadataRecordingRulesGenerator.WithErrorBudgetForecast()
+			gotRules, err := gen.GenerateMetadataRecordingRules(context.TODO(), info.Info{}, slo, getAlertGroup())
+			require.NoError(err)
+
+			var expr string
+			for _, r := range gotRules {
+				if r.Record == "slo:error_budget:exhaustion_seconds" {
+					expr = strings.Join(strings.Fields(r.Expr), " ")
+				}
+			}
+			require.NotEmpty(expr)
+
+			// Evaluate the rule expression with the Prometheus engine.
+			promTest, err := promql.NewTest(t, "load 1m"+test.series+"\neval instant at 10m "+expr+test.expResult)
+			require.NoError(err)
+			defer promTest.Close()
+
+			require.NoError(promTest.Run())
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package prometheus
+
+import (
+	"fmt"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+)
+
+// RetentionExceeded is an SLO with a time window that exceeds the metrics retention, the
+// error budget calculations of this window degrade silently because Prometheus doesn't
+// have all the required data.
+type RetentionExceeded struct {
+	SLOID      string
+	TimeWindow time.Duration
+	Retention  time.Duration
+}
+
+// String returns the retention exceeded description.
+func (r RetentionExceeded) String() string {
+	return fmt.Sprintf("%q SLO %s time window exceeds the %s metrics retention, the error budget calculations will not be correct",
+		r.SLOID, prommodel.Duration(r.TimeWindow), prommodel.Duration(r.Retention))
+}
+
+// FindRetentionExceeded returns the SLOs with time windows that exceed the metrics
+// retention. A 0 retention doesn't check anything.
+func FindRetentionExceeded(slos []SLO, retention time.Duration) []RetentionExceeded {
+	res := []RetentionExceeded{}
+	if retention == 0 {
+		return res
+	}
+
+	for _, slo := range slos {
+		if slo.TimeWindow > retention {
+			res = append(res, RetentionExceeded{SLOID: slo.ID, TimeWindow: slo.TimeWindow, Retention: retention})
+		}
+	}
+
+	return res
+}
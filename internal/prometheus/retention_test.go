@@ -0,0 +1,75 @@
+package prometheus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestFindRetentionExceeded(t *testing.T) {
+	slos := []prometheus.SLO{
+		{ID: "svc-slo-7d", TimeWindow: 7 * 24 * time.Hour},
+		{ID: "svc-slo-15d", TimeWindow: 15 * 24 * time.Hour},
+		{ID: "svc-slo-30d", TimeWindow: 30 * 24 * time.Hour},
+	}
+
+	tests := map[string]struct {
+		retention  time.Duration
+		expResults []prometheus.RetentionExceeded
+		expStrs    []string
+	}{
+		"Without retention it shouldn't check anything.": {
+			retention:  0,
+			expResults: []prometheus.RetentionExceeded{},
+			expStrs:    []string{},
+		},
+
+		"A retention bigger than all the windows shouldn't return anything.": {
+			retention:  90 * 24 * time.Hour,
+			expResults: []prometheus.RetentionExceeded{},
+			expStrs:    []string{},
+		},
+
+		"A retention equal to a window shouldn't return that SLO.": {
+			retention: 15 * 24 * time.Hour,
+			expResults: []prometheus.RetentionExceeded{
+				{SLOID: "svc-slo-30d", TimeWindow: 30 * 24 * time.Hour, Retention: 15 * 24 * time.Hour},
+			},
+			expStrs: []string{
+				`"svc-slo-30d" SLO 30d time window exceeds the 15d metrics retention, the error budget calculations will not be correct`,
+			},
+		},
+
+		"A retention smaller than the windows should return the SLOs in order.": {
+			retention: 24 * time.Hour,
+			expResults: []prometheus.RetentionExceeded{
+				{SLOID: "svc-slo-7d", TimeWindow: 7 * 24 * time.Hour, Retention: 24 * time.Hour},
+				{SLOID: "svc-slo-15d", TimeWindow: 15 * 24 * time.Hour, Retention: 24 * time.Hour},
+				{SLOID: "svc-slo-30d", TimeWindow: 30 * 24 * time.Hour, Retention: 24 * time.Hour},
+			},
+			expStrs: []string{
+				`"svc-slo-7d" SLO 1w time window exceeds the 1d metrics retention, the error budget calculations will not be correct`,
+				`"svc-slo-15d" SLO 15d time window exceeds the 1d metrics retention, the error budget calculations will not be correct`,
+				`"svc-slo-30d" SLO 30d time window exceeds the 1d metrics retention, the error budget calculations will not be correct`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotResults := prometheus.FindRetentionExceeded(slos, test.retention)
+			assert.Equal(test.expResults, gotResults)
+
+			gotStrs := []string{}
+			for _, r := range gotResults {
+				gotStrs = append(gotStrs, r.String())
+			}
+			assert.Equal(test.expStrs, gotStrs)
+		})
+	}
+}
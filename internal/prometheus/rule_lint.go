@@ -0,0 +1,156 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+)
+
+// promTemplateFuncs are the Prometheus alert template functions, only used to parse the
+// templates, never executed.
+var promTemplateFuncs = func() template.FuncMap {
+	noop := func(...interface{}) interface{} { return nil }
+	funcs := template.FuncMap{}
+	for _, name := range []string{
+		"query", "first", "label", "value", "strvalue", "args", "reReplaceAll", "safeHtml",
+		"match", "title", "toUpper", "toLower", "graphLink", "tableLink", "sortByLabel",
+		"humanize", "humanize1024", "humanizeDuration", "humanizePercentage", "humanizeTimestamp",
+		"pathPrefix", "externalURL", "parseDuration", "toTime",
+	} {
+		funcs[name] = noop
+	}
+	return funcs
+}()
+
+// promTemplateDefs are the variables defined by Prometheus on the alert templates.
+const promTemplateDefs = "{{$labels := .Labels}}{{$externalLabels := .ExternalLabels}}{{$externalURL := .ExternalURL}}{{$value := .Value}}"
+
+// LintRules checks the SLOs generated Prometheus rules the same way Prometheus (and promtool)
+// does when loading the rule files: the rule groups names are unique, the rules are valid
+// recording or alerting rules, the expressions are valid PromQL, the labels and annotations
+// are valid and the templates can be parsed. It also detects the duplicated rules (same name
+// and labels) of a group.
+func LintRules(slos []StorageSLO) []error {
+	errs := []error{}
+	groupNames := map[string]struct{}{}
+	for _, slo := range slos {
+		groups := []struct {
+			name  string
+			rules []rulefmt.Rule
+		}{
+			{name: fmt.Sprintf(sliRecordingsGroupNameFmt, slo.SLO.ID), rules: slo.Rules.SLIErrorRecRules},
+			{name: fmt.Sprintf(metaRecordingsGroupNameFmt, slo.SLO.ID), rules: slo.Rules.MetadataRecRules},
+			{name: fmt.Sprintf(alertsGroupNameFmt, slo.SLO.ID), rules: slo.Rules.AlertRules},
+		}
+
+		for _, g := range groups {
+			if len(g.rules) == 0 {
+				continue
+			}
+
+			if _, ok := groupNames[g.name]; ok {
+				errs = append(errs, fmt.Errorf("%q rule group is repeated", g.name))
+				continue
+			}
+			groupNames[g.name] = struct{}{}
+
+			ruleIDs := map[string]struct{}{}
+			for i, rule := range g.rules {
+				for _, err := range lintRule(rule) {
+					errs = append(errs, fmt.Errorf("%q rule group %d rule (%s): %w", g.name, i, ruleName(rule), err))
+				}
+
+				id := ruleName(rule) + ruleLabelsID(rule.Labels)
+				if _, ok := ruleIDs[id]; ok {
+					errs = append(errs, fmt.Errorf("%q rule group %d rule (%s): duplicated rule with the same name and labels", g.name, i, ruleName(rule)))
+				}
+				ruleIDs[id] = struct{}{}
+			}
+		}
+	}
+
+	return errs
+}
+
+func ruleName(rule rulefmt.Rule) string {
+	if rule.Record != "" {
+		return rule.Record
+	}
+
+	return rule.Alert
+}
+
+// ruleLabelsID returns a deterministic ID of the rule labels.
+func ruleLabelsID(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func lintRule(rule rulefmt.Rule) []error {
+	errs := []error{}
+
+	switch {
+	case rule.Record != "" && rule.Alert != "":
+		errs = append(errs, fmt.Errorf("only one of record and alert can be set"))
+	case rule.Record == "" && rule.Alert == "":
+		errs = append(errs, fmt.Errorf("one of record or alert must be set"))
+	case rule.Record != "":
+		if !prommodel.IsValidMetricName(prommodel.LabelValue(rule.Record)) {
+			errs = append(errs, fmt.Errorf("invalid recording rule name"))
+		}
+		if len(rule.Annotations) > 0 {
+			errs = append(errs, fmt.Errorf("recording rules can't have annotations"))
+		}
+		if rule.For != 0 {
+			errs = append(errs, fmt.Errorf("recording rules can't have for"))
+		}
+	}
+
+	if rule.Expr == "" {
+		errs = append(errs, fmt.Errorf("expression is required"))
+	} else if _, err := parsePromExpr(rule.Expr); err != nil {
+		errs = append(errs, fmt.Errorf("invalid expression: %w", err))
+	}
+
+	for k, v := range rule.Labels {
+		if !prommodel.LabelName(k).IsValid() || k == prommodel.MetricNameLabel {
+			errs = append(errs, fmt.Errorf("invalid %q label name", k))
+		}
+		if !utf8.ValidString(v) {
+			errs = append(errs, fmt.Errorf("invalid %q label value", k))
+		}
+	}
+
+	for k := range rule.Annotations {
+		if !prommodel.LabelName(k).IsValid() {
+			errs = append(errs, fmt.Errorf("invalid %q annotation name", k))
+		}
+	}
+
+	// Only alert templates are expanded by Prometheus.
+	if rule.Alert != "" {
+		for _, kv := range []map[string]string{rule.Labels, rule.Annotations} {
+			for k, v := range kv {
+				_, err := template.New(k).Funcs(promTemplateFuncs).Parse(promTemplateDefs + v)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("invalid %q template: %w", k, err))
+				}
+			}
+		}
+	}
+
+	// Sort errors so the results are deterministic.
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return errs
+}
@@ -0,0 +1,104 @@
+package prometheus_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestLintRules(t *testing.T) {
+	tests := map[string]struct {
+		slos    []prometheus.StorageSLO
+		expErrs []string
+	}{
+		"Valid rules should not have errors.": {
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{Record: "slo:sli_error:ratio_rate5m", Expr: `rate(errors[5m])`, Labels: map[string]string{"sloth_window": "5m"}},
+							{Record: "slo:sli_error:ratio_rate30m", Expr: `rate(errors[30m])`, Labels: map[string]string{"sloth_window": "30m"}},
+						},
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert:       "TestAlert",
+								Expr:        `slo:sli_error:ratio_rate5m > 0.1`,
+								Labels:      map[string]string{"sloth_severity": "page"},
+								Annotations: map[string]string{"summary": "{{$labels.sloth_service}} error budget burn rate is {{ $value | humanize }}"},
+							},
+						},
+					},
+				},
+			},
+			expErrs: []string{},
+		},
+
+		"Invalid rules should have errors.": {
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{Record: "slo:sli_error:ratio_rate5m", Expr: `rate(errors[5m]`},
+							{Record: "slo-sli", Expr: `rate(errors[5m])`, Annotations: map[string]string{"a": "b"}},
+							{Expr: `rate(errors[5m])`},
+						},
+						MetadataRecRules: []rulefmt.Rule{
+							{Record: "slo:objective:ratio", Expr: `vector(0.99)`, Labels: map[string]string{"sloth-id": "test1"}},
+							{Record: "slo:objective:ratio", Expr: `vector(0.99)`, Labels: map[string]string{"sloth-id": "test1"}},
+						},
+						AlertRules: []rulefmt.Rule{
+							{Alert: "TestAlert", Expr: `vector(1)`, Annotations: map[string]string{"summary": "{{$labelz.sloth_service}}"}},
+						},
+					},
+				},
+			},
+			expErrs: []string{
+				`"sloth-slo-sli-recordings-test1" rule group 0 rule (slo:sli_error:ratio_rate5m): invalid expression: `,
+				`"sloth-slo-sli-recordings-test1" rule group 1 rule (slo-sli): invalid recording rule name`,
+				`"sloth-slo-sli-recordings-test1" rule group 1 rule (slo-sli): recording rules can't have annotations`,
+				`"sloth-slo-sli-recordings-test1" rule group 2 rule (): one of record or alert must be set`,
+				`"sloth-slo-meta-recordings-test1" rule group 0 rule (slo:objective:ratio): invalid "sloth-id" label name`,
+				`"sloth-slo-meta-recordings-test1" rule group 1 rule (slo:objective:ratio): invalid "sloth-id" label name`,
+				`"sloth-slo-meta-recordings-test1" rule group 1 rule (slo:objective:ratio): duplicated rule with the same name and labels`,
+				`"sloth-slo-alerts-test1" rule group 0 rule (TestAlert): invalid "summary" template: template: summary:1: undefined variable "$labelz"`,
+			},
+		},
+
+		"Repeated rule groups should have errors.": {
+			slos: []prometheus.StorageSLO{
+				{
+					SLO:   prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{SLIErrorRecRules: []rulefmt.Rule{{Record: "slo:sli_error:ratio_rate5m", Expr: `rate(errors[5m])`}}},
+				},
+				{
+					SLO:   prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{SLIErrorRecRules: []rulefmt.Rule{{Record: "slo:sli_error:ratio_rate5m", Expr: `rate(errors[5m])`}}},
+				},
+			},
+			expErrs: []string{
+				`"sloth-slo-sli-recordings-test1" rule group is repeated`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotErrs := prometheus.LintRules(test.slos)
+
+			// The PromQL parser error messages are not checked, only the error prefix.
+			if assert.Len(gotErrs, len(test.expErrs)) {
+				for i, err := range gotErrs {
+					assert.True(strings.HasPrefix(err.Error(), test.expErrs[i]), "%q should start with %q", err, test.expErrs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v2"
+
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+// RulesChangeKind is the kind of a generated rules change.
+type RulesChangeKind string
+
+const (
+	// RulesChangeKindCosmetic is the kind of the changes that don't change the rules
+	// evaluation (e.g annotations, Sloth version labels, expressions format).
+	RulesChangeKindCosmetic RulesChangeKind = "cosmetic"
+	// RulesChangeKindThreshold is the kind of the changes of the expressions numbers and
+	// the alerts `for` durations, the rules evaluate the same series with other thresholds.
+	RulesChangeKindThreshold RulesChangeKind = "threshold"
+	// RulesChangeKindStructural is the kind of the changes of the rule groups, the rules,
+	// their labels or their expressions, the dashboards and alerts that use the rules
+	// could break.
+	RulesChangeKindStructural RulesChangeKind = "structural"
+)
+
+// RulesChangeKinds are all the rules change kinds, sorted by severity.
+var RulesChangeKinds = []RulesChangeKind{
+	RulesChangeKindCosmetic,
+	RulesChangeKindThreshold,
+	RulesChangeKindStructural,
+}
+
+// cosmeticLabelNames are the Sloth labels that don't identify the generated series.
+var cosmeticLabelNames = map[string]struct{}{
+	sloVersionLabelName: {},
+	sloModeLabelName:    {},
+	sloSpecLabelName:    {},
+}
+
+// RulesChange is a change between two generated rules outputs.
+type RulesChange struct {
+	Kind  RulesChangeKind
+	Group string
+	// Rule is the rule name and labels, empty on group changes.
+	Rule    string
+	Message string
+}
+
+// String returns the change description.
+func (r RulesChange) String() string {
+	if r.Rule == "" {
+		return fmt.Sprintf("[%s] %q rule group: %s", r.Kind, r.Group, r.Message)
+	}
+
+	return fmt.Sprintf("[%s] %q rule group %s rule: %s", r.Kind, r.Group, r.Rule, r.Message)
+}
+
+// CompareRules compares the rules of two generated outputs (e.g generated by different
+// Sloth versions) and classifies the changes by kind, sorted by group and rule. The outputs
+// can be Prometheus rules or Kubernetes rule objects YAML, with multiple YAML documents.
+func CompareRules(oldData, newData []byte) ([]RulesChange, error) {
+	oldGroups, err := loadCompatRuleGroups(oldData)
+	if err != nil {
+		return nil, fmt.Errorf("could not load old rules: %w", err)
+	}
+	newGroups, err := loadCompatRuleGroups(newData)
+	if err != nil {
+		return nil, fmt.Errorf("could not load new rules: %w", err)
+	}
+
+	names := map[string]struct{}{}
+	for name := range oldGroups {
+		names[name] = struct{}{}
+	}
+	for name := range newGroups {
+		names[name] = struct{}{}
+	}
+
+	changes := []RulesChange{}
+	for _, name := range sortedSet(names) {
+		oldGroup, okOld := oldGroups[name]
+		newGroup, okNew := newGroups[name]
+		switch {
+		case !okNew:
+			changes = append(changes, RulesChange{Kind: RulesChangeKindStructural, Group: name, Message: "removed"})
+			continue
+		case !okOld:
+			changes = append(changes, RulesChange{Kind: RulesChangeKindStructural, Group: name, Message: "added"})
+			continue
+		}
+
+		if oldGroup.Interval != newGroup.Interval {
+			changes = append(changes, RulesChange{Kind: RulesChangeKindThreshold, Group: name, Message: fmt.Sprintf("interval changed from %s to %s", oldGroup.Interval, newGroup.Interval)})
+		}
+		if oldGroup.PartialResponseStrategy != newGroup.PartialResponseStrategy {
+			changes = append(changes, RulesChange{Kind: RulesChangeKindStructural, Group: name, Message: fmt.Sprintf("partial response strategy changed from %q to %q", oldGroup.PartialResponseStrategy, newGroup.PartialResponseStrategy)})
+		}
+		if !compatMapsEqual(oldGroup.Labels, newGroup.Labels) {
+			changes = append(changes, RulesChange{Kind: RulesChangeKindStructural, Group: name, Message: "labels changed"})
+		}
+
+		oldRules, newRules := compatRulesByID(oldGroup.Rules), compatRulesByID(newGroup.Rules)
+		ids := map[string]struct{}{}
+		for id := range oldRules {
+			ids[id] = struct{}{}
+		}
+		for id := range newRules {
+			ids[id] = struct{}{}
+		}
+		for _, id := range sortedSet(ids) {
+			oldRule, okOld := oldRules[id]
+			newRule, okNew := newRules[id]
+			switch {
+			case !okNew:
+				changes = append(changes, RulesChange{Kind: RulesChangeKindStructural, Group: name, Rule: id, Message: "removed"})
+			case !okOld:
+				changes = append(changes, RulesChange{Kind: RulesChangeKindStructural, Group: name, Rule: id, Message: "added"})
+			default:
+				for _, c := range compareRule(oldRule, newRule) {
+					c.Group = name
+					c.Rule = id
+					changes = append(changes, c)
+				}
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// compareRule compares two rules with the same name and identifying labels.
+func compareRule(oldRule, newRule rulefmt.Rule) []RulesChange {
+	changes := []RulesChange{}
+	if oldRule.Expr != newRule.Expr {
+		kind := compareRuleExpr(oldRule.Expr, newRule.Expr)
+		changes = append(changes, RulesChange{Kind: kind, Message: fmt.Sprintf("expression changed from %q to %q", oldRule.Expr, newRule.Expr)})
+	}
+	if oldRule.For != newRule.For {
+		changes = append(changes, RulesChange{Kind: RulesChangeKindThreshold, Message: fmt.Sprintf("for changed from %s to %s", oldRule.For, newRule.For)})
+	}
+	if !compatMapsEqual(oldRule.Labels, newRule.Labels) {
+		changes = append(changes, RulesChange{Kind: RulesChangeKindCosmetic, Message: "Sloth info labels changed"})
+	}
+	if !compatMapsEqual(oldRule.Annotations, newRule.Annotations) {
+		changes = append(changes, RulesChange{Kind: RulesChangeKindCosmetic, Message: "annotations changed"})
+	}
+
+	return changes
+}
+
+// compareRuleExpr returns the kind of an expression change: cosmetic if only the format
+// changed, threshold if only the numbers changed and structural otherwise.
+func compareRuleExpr(oldExpr, newExpr string) RulesChangeKind {
+	oldAST, err := parsePromExpr(oldExpr)
+	if err != nil {
+		return RulesChangeKindStructural
+	}
+	newAST, err := parsePromExpr(newExpr)
+	if err != nil {
+		return RulesChangeKindStructural
+	}
+
+	if oldAST.String() == newAST.String() {
+		return RulesChangeKindCosmetic
+	}
+
+	// Ignore the numbers to check if only the thresholds changed.
+	for _, ast := range []promqlparser.Expr{oldAST, newAST} {
+		promqlparser.Inspect(ast, func(node promqlparser.Node, _ []promqlparser.Node) error {
+			if n, ok := node.(*promqlparser.NumberLiteral); ok {
+				n.Val = 0
+			}
+			return nil
+		})
+	}
+	if oldAST.String() == newAST.String() {
+		return RulesChangeKindThreshold
+	}
+
+	return RulesChangeKindStructural
+}
+
+// loadCompatRuleGroups loads the rule groups by name of Prometheus rules or Kubernetes rule
+// objects YAML documents.
+func loadCompatRuleGroups(data []byte) (map[string]ruleGroupYAMLv2, error) {
+	docs, err := specloader.ReadAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]ruleGroupYAMLv2{}
+	for _, doc := range docs {
+		rules := struct {
+			Groups []ruleGroupYAMLv2 `yaml:"groups"`
+			Spec   struct {
+				Groups []ruleGroupYAMLv2 `yaml:"groups"`
+			} `yaml:"spec"`
+		}{}
+		err := yaml.Unmarshal(doc, &rules)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal YAML rules: %w", err)
+		}
+
+		for _, g := range append(rules.Groups, rules.Spec.Groups...) {
+			if _, ok := groups[g.Name]; ok {
+				return nil, fmt.Errorf("%q rule group is repeated", g.Name)
+			}
+			groups[g.Name] = g
+		}
+	}
+
+	return groups, nil
+}
+
+// compatRulesByID returns the rules by their name and identifying labels.
+func compatRulesByID(rules []rulefmt.Rule) map[string]rulefmt.Rule {
+	res := map[string]rulefmt.Rule{}
+	for _, r := range rules {
+		labels := map[string]string{}
+		for k, v := range r.Labels {
+			if _, ok := cosmeticLabelNames[k]; !ok {
+				labels[k] = v
+			}
+		}
+		res[ruleName(r)+ruleLabelsID(labels)] = r
+	}
+
+	return res
+}
+
+func compatMapsEqual(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func sortedSet(set map[string]struct{}) []string {
+	res := make([]string, 0, len(set))
+	for k := range set {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
@@ -0,0 +1,194 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestCompareRules(t *testing.T) {
+	oldRules := `
+---
+# Code generated by Sloth (v0.1.0): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+groups:
+- name: sloth-slo-sli-recordings-svc-slo1
+  rules:
+  - record: slo:sli_error:ratio_rate5m
+    expr: |
+      (sum(rate(http_request_errors_total[5m])))
+      /
+      (sum(rate(http_requests_total[5m])))
+    labels:
+      sloth_id: svc-slo1
+      sloth_window: 5m
+- name: sloth-slo-meta-recordings-svc-slo1
+  rules:
+  - record: slo:objective:ratio
+    expr: vector(0.999)
+    labels:
+      sloth_id: svc-slo1
+  - record: sloth_slo_info
+    expr: vector(1)
+    labels:
+      sloth_id: svc-slo1
+      sloth_version: v0.1.0
+- name: sloth-slo-alerts-svc-slo1
+  rules:
+  - alert: SLOAlert
+    expr: slo:sli_error:ratio_rate5m{sloth_id="svc-slo1"} > (14.4 * 0.001)
+    for: 2m
+    labels:
+      sloth_severity: page
+    annotations:
+      summary: test
+`
+
+	tests := map[string]struct {
+		oldRules   string
+		newRules   string
+		expChanges []prometheus.RulesChange
+		expErr     bool
+	}{
+		"Invalid rules should fail.": {
+			oldRules: oldRules,
+			newRules: `groups: {`,
+			expErr:   true,
+		},
+
+		"The same rules should not have changes.": {
+			oldRules:   oldRules,
+			newRules:   oldRules,
+			expChanges: []prometheus.RulesChange{},
+		},
+
+		"Kubernetes rule objects should be compared with the Prometheus rules.": {
+			oldRules: oldRules,
+			newRules: `
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  name: test
+spec:
+  groups:
+  - name: sloth-slo-sli-recordings-svc-slo1
+    rules:
+    - record: slo:sli_error:ratio_rate5m
+      expr: |
+        (sum(rate(http_request_errors_total[5m])))
+        /
+        (sum(rate(http_requests_total[5m])))
+      labels:
+        sloth_id: svc-slo1
+        sloth_window: 5m
+  - name: sloth-slo-meta-recordings-svc-slo1
+    rules:
+    - record: slo:objective:ratio
+      expr: vector(0.999)
+      labels:
+        sloth_id: svc-slo1
+    - record: sloth_slo_info
+      expr: vector(1)
+      labels:
+        sloth_id: svc-slo1
+        sloth_version: v0.1.0
+  - name: sloth-slo-alerts-svc-slo1
+    rules:
+    - alert: SLOAlert
+      expr: slo:sli_error:ratio_rate5m{sloth_id="svc-slo1"} > (14.4 * 0.001)
+      for: 2m
+      labels:
+        sloth_severity: page
+      annotations:
+        summary: test
+`,
+			expChanges: []prometheus.RulesChange{},
+		},
+
+		"Changed rules should be classified by their change kind.": {
+			oldRules: oldRules,
+			newRules: `
+groups:
+- name: sloth-slo-sli-recordings-svc-slo1
+  rules:
+  - record: slo:sli_error:ratio_rate5m
+    expr: (sum(rate(http_request_errors_total[5m]))) / (sum(rate(http_requests_total[5m])))
+    labels:
+      sloth_id: svc-slo1
+      sloth_window: 5m
+  - record: slo:sli_error:ratio_rate30m
+    expr: sum(rate(http_request_errors_total[30m])) / sum(rate(http_requests_total[30m]))
+    labels:
+      sloth_id: svc-slo1
+      sloth_window: 30m
+- name: sloth-slo-meta-recordings-svc-slo1
+  rules:
+  - record: slo:objective:ratio
+    expr: vector(0.99)
+    labels:
+      sloth_id: svc-slo1
+  - record: sloth_slo_info
+    expr: vector(1)
+    labels:
+      sloth_id: svc-slo1
+      sloth_version: v0.2.0
+- name: sloth-slo-alerts-svc-slo1
+  rules:
+  - alert: SLOAlert
+    expr: slo:sli_error:ratio_rate5m{sloth_id="svc-slo1"} > (14.4 * 0.001) and on() up == 1
+    for: 5m
+    labels:
+      sloth_severity: page
+    annotations:
+      summary: other
+`,
+			expChanges: []prometheus.RulesChange{
+				{Kind: prometheus.RulesChangeKindStructural, Group: "sloth-slo-alerts-svc-slo1", Rule: "SLOAlert{sloth_severity=page}", Message: `expression changed from "slo:sli_error:ratio_rate5m{sloth_id=\"svc-slo1\"} > (14.4 * 0.001)" to "slo:sli_error:ratio_rate5m{sloth_id=\"svc-slo1\"} > (14.4 * 0.001) and on() up == 1"`},
+				{Kind: prometheus.RulesChangeKindThreshold, Group: "sloth-slo-alerts-svc-slo1", Rule: "SLOAlert{sloth_severity=page}", Message: "for changed from 2m to 5m"},
+				{Kind: prometheus.RulesChangeKindCosmetic, Group: "sloth-slo-alerts-svc-slo1", Rule: "SLOAlert{sloth_severity=page}", Message: "annotations changed"},
+				{Kind: prometheus.RulesChangeKindThreshold, Group: "sloth-slo-meta-recordings-svc-slo1", Rule: "slo:objective:ratio{sloth_id=svc-slo1}", Message: `expression changed from "vector(0.999)" to "vector(0.99)"`},
+				{Kind: prometheus.RulesChangeKindCosmetic, Group: "sloth-slo-meta-recordings-svc-slo1", Rule: "sloth_slo_info{sloth_id=svc-slo1}", Message: "Sloth info labels changed"},
+				{Kind: prometheus.RulesChangeKindStructural, Group: "sloth-slo-sli-recordings-svc-slo1", Rule: "slo:sli_error:ratio_rate30m{sloth_id=svc-slo1,sloth_window=30m}", Message: "added"},
+				{Kind: prometheus.RulesChangeKindCosmetic, Group: "sloth-slo-sli-recordings-svc-slo1", Rule: "slo:sli_error:ratio_rate5m{sloth_id=svc-slo1,sloth_window=5m}", Message: "expression changed from \"(sum(rate(http_request_errors_total[5m])))\\n/\\n(sum(rate(http_requests_total[5m])))\\n\" to \"(sum(rate(http_request_errors_total[5m]))) / (sum(rate(http_requests_total[5m])))\""},
+			},
+		},
+
+		"Removed rule groups should be structural changes.": {
+			oldRules: oldRules,
+			newRules: `
+groups:
+- name: sloth-slo-sli-recordings-svc-slo1
+  rules:
+  - record: slo:sli_error:ratio_rate5m
+    expr: |
+      (sum(rate(http_request_errors_total[5m])))
+      /
+      (sum(rate(http_requests_total[5m])))
+    labels:
+      sloth_id: svc-slo1
+      sloth_window: 5m
+`,
+			expChanges: []prometheus.RulesChange{
+				{Kind: prometheus.RulesChangeKindStructural, Group: "sloth-slo-alerts-svc-slo1", Message: "removed"},
+				{Kind: prometheus.RulesChangeKindStructural, Group: "sloth-slo-meta-recordings-svc-slo1", Message: "removed"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotChanges, err := prometheus.CompareRules([]byte(test.oldRules), []byte(test.newRules))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expChanges, gotChanges)
+			}
+		})
+	}
+}
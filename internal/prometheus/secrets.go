@@ -0,0 +1,193 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretRule is a rule that detects probable secrets on the SLOs content.
+type secretRule struct {
+	name  string
+	regex *regexp.Regexp
+}
+
+var secretRules = []secretRule{
+	{name: "private key", regex: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{name: "AWS access key", regex: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "GitHub token", regex: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{name: "Slack token", regex: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{name: "JWT", regex: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{name: "URL credentials", regex: regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@"]+:[^/\s@"]+@`)},
+	{name: "credential", regex: regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token|api_?key|access_?key)\b\s*(?:=|:|=~)\s*["']?[^\s"',}]{8,}`)},
+	{name: "internal hostname", regex: regexp.MustCompile(`(?i)\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:internal|corp|intranet|lan|local)\b`)},
+}
+
+var (
+	secretEntropyTokenRegexp = regexp.MustCompile(`[A-Za-z0-9+/=_-]{24,}`)
+	digitRegexp              = regexp.MustCompile(`[0-9]`)
+	letterRegexp             = regexp.MustCompile(`[A-Za-z]`)
+)
+
+// secretEntropyThreshold is the minimum Shannon entropy (bits per character) of a
+// token to be considered a probable secret (e.g random API tokens).
+const secretEntropyThreshold = 3.5
+
+// SecretFinding is a probable secret found on an SLO.
+type SecretFinding struct {
+	SLOID string
+	// Field is the SLO spec field of the finding (e.g `sli.events.error_query`).
+	Field string
+	// Rule is the rule that detected the finding.
+	Rule string
+	// Match is the matched content.
+	Match string
+}
+
+// String returns the finding description with the matched content redacted.
+func (s SecretFinding) String() string {
+	return fmt.Sprintf("%q SLO %s has a probable %s (%s)", s.SLOID, s.Field, s.Rule, redactSecret(s.Match))
+}
+
+func redactSecret(s string) string {
+	const visible = 4
+	if len(s) <= visible {
+		return strings.Repeat("*", len(s))
+	}
+
+	return s[:visible] + strings.Repeat("*", len(s)-visible)
+}
+
+// SecretsScanner scans the SLOs content (SLI queries, labels and annotations) for
+// probable credentials and internal hostnames, because the SLO specs content ends
+// on the generated rules and dashboards.
+type SecretsScanner struct {
+	allowlist []*regexp.Regexp
+}
+
+// NewSecretsScanner returns a new secrets scanner. The findings that match any of
+// the allowlist regexes are ignored.
+func NewSecretsScanner(allowlist []string) (*SecretsScanner, error) {
+	s := &SecretsScanner{}
+	for _, a := range allowlist {
+		r, err := regexp.Compile(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q allowlist regex: %w", a, err)
+		}
+		s.allowlist = append(s.allowlist, r)
+	}
+
+	return s, nil
+}
+
+// Scan returns the probable secrets of the SLOs. A nil scanner doesn't scan anything.
+func (s *SecretsScanner) Scan(slos []SLO) []SecretFinding {
+	if s == nil {
+		return nil
+	}
+
+	findings := []SecretFinding{}
+	for _, slo := range slos {
+		for _, f := range getSLOScannableFields(slo) {
+			for _, m := range s.scanValue(f[1]) {
+				findings = append(findings, SecretFinding{SLOID: slo.ID, Field: f[0], Rule: m[0], Match: m[1]})
+			}
+		}
+	}
+
+	return findings
+}
+
+// scanValue returns the rule and match pairs of the probable secrets on a value.
+func (s *SecretsScanner) scanValue(value string) [][2]string {
+	matches := [][2]string{}
+	for _, r := range secretRules {
+		for _, m := range r.regex.FindAllString(value, -1) {
+			if !s.allowed(m) {
+				matches = append(matches, [2]string{r.name, m})
+			}
+		}
+	}
+
+	for _, token := range secretEntropyTokenRegexp.FindAllString(value, -1) {
+		if digitRegexp.MatchString(token) && letterRegexp.MatchString(token) &&
+			shannonEntropy(token) >= secretEntropyThreshold && !s.allowed(token) {
+			matches = append(matches, [2]string{"high entropy token", token})
+		}
+	}
+
+	return matches
+}
+
+func (s *SecretsScanner) allowed(match string) bool {
+	for _, r := range s.allowlist {
+		if r.MatchString(match) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSLOScannableFields returns the field and value pairs of the SLO user content.
+func getSLOScannableFields(slo SLO) [][2]string {
+	fields := [][2]string{}
+	if slo.SLI.Raw != nil {
+		fields = append(fields, [2]string{"sli.raw.error_ratio_query", slo.SLI.Raw.ErrorRatioQuery})
+	}
+	if slo.SLI.Events != nil {
+		fields = append(fields,
+			[2]string{"sli.events.error_query", slo.SLI.Events.ErrorQuery},
+			[2]string{"sli.events.total_query", slo.SLI.Events.TotalQuery},
+		)
+	}
+	if slo.AlertGuard != "" {
+		fields = append(fields, [2]string{"alerting.guard", slo.AlertGuard})
+	}
+
+	for _, m := range []struct {
+		field string
+		kvs   map[string]string
+	}{
+		{"labels", slo.Labels},
+		{"annotations", slo.Annotations},
+		{"cost.labels", slo.CostLabels},
+		{"group_labels", slo.GroupLabels},
+		{"alerting.page_alert.labels", slo.PageAlertMeta.Labels},
+		{"alerting.page_alert.annotations", slo.PageAlertMeta.Annotations},
+		{"alerting.ticket_alert.labels", slo.TicketAlertMeta.Labels},
+		{"alerting.ticket_alert.annotations", slo.TicketAlertMeta.Annotations},
+	} {
+		// Sort for deterministic findings.
+		keys := make([]string, 0, len(m.kvs))
+		for k := range m.kvs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fields = append(fields, [2]string{fmt.Sprintf("%s.%s", m.field, k), m.kvs[k]})
+		}
+	}
+
+	return fields
+}
+
+// shannonEntropy returns the Shannon entropy of a string in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+
+	entropy := 0.0
+	total := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / total
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
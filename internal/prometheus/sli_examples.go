@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
+)
+
+// sliExampleErrorRatioTolerance is the error ratio difference tolerated between the evaluated
+// and the expected SLI error ratios, because of the floating point operations.
+const sliExampleErrorRatioTolerance = 1e-9
+
+// ValidateSLIExamples evaluates the SLO SLI queries with the values of every SLI example series
+// and returns an error for each example that doesn't result in the expected SLI error ratio.
+//
+// The examples are evaluated with the Prometheus engine without a Prometheus, the series values are the values of the
+// range vector functions on the SLI window (e.g the `rate` of a counter is the value of the
+// counter series), so the examples are the same for all the SLO windows.
+func ValidateSLIExamples(slo SLO) []error {
+	if len(slo.SLI.Examples) == 0 {
+		return nil
+	}
+
+	var sliExpr string
+	switch {
+	case slo.SLI.Events != nil:
+		sliExpr = fmt.Sprintf("(%s)\n/\n(%s)", slo.SLI.Events.ErrorQuery, slo.SLI.Events.TotalQuery)
+	case slo.SLI.Raw != nil:
+		sliExpr = slo.SLI.Raw.ErrorRatioQuery
+	default:
+		return []error{fmt.Errorf("%q SLO examples: invalid SLI type", slo.ID)}
+	}
+
+	expr, err := parseTplPromExpression(sliExpr)
+	if err != nil {
+		return []error{fmt.Errorf("%q SLO examples: invalid SLI expression: %w", slo.ID, err)}
+	}
+
+	var errs []error
+	for i, example := range slo.SLI.Examples {
+		name := example.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+
+		errorRatio, err := evalSLIExample(expr, example)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q SLO %q example: %w", slo.ID, name, err))
+			continue
+		}
+
+		if math.IsNaN(errorRatio) || math.Abs(errorRatio-example.ErrorRatio) > sliExampleErrorRatioTolerance {
+			errs = append(errs, fmt.Errorf("%q SLO %q example: expected %g error ratio, got %g", slo.ID, name, example.ErrorRatio, errorRatio))
+		}
+	}
+
+	return errs
+}
+
+func evalSLIExample(expr promqlparser.Expr, example SLIExample) (float64, error) {
+	// Sort the series so the evaluation is deterministic.
+	selectors := make([]string, 0, len(example.Series))
+	for s := range example.Series {
+		selectors = append(selectors, s)
+	}
+	sort.Strings(selectors)
+
+	series := make([]promEvalSample, 0, len(selectors))
+	for _, s := range selectors {
+		ls, err := parsePromSeries(s)
+		if err != nil {
+			return 0, err
+		}
+		series = append(series, promEvalSample{labels: ls, value: example.Series[s]})
+	}
+
+	res, err := promExprEvaluator{series: series}.eval(context.Background(), expr)
+	if err != nil {
+		return 0, fmt.Errorf("could not evaluate SLI expression: %w", err)
+	}
+
+	switch v := res.(type) {
+	case promql.Scalar:
+		return v.V, nil
+	case promql.Vector:
+		switch {
+		case len(v) == 0:
+			return 0, fmt.Errorf("SLI expression returned no series")
+		case len(v) > 1:
+			return 0, fmt.Errorf("SLI expression returned %d series, expected 1", len(v))
+		}
+		return v[0].V, nil
+	}
+
+	return 0, fmt.Errorf("SLI expression returned a %s, expected a scalar or an instant vector", res.Type())
+}
+
+// parsePromSeries parses a series in the Prometheus selector form (e.g `http_requests_total{code="500"}`)
+// and returns its labels, including the metric name label.
+func parsePromSeries(s string) (map[string]string, error) {
+	expr, err := promqlparser.ParseExpr(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid series %q: %w", s, err)
+	}
+
+	vs, ok := expr.(*promqlparser.VectorSelector)
+	if !ok {
+		return nil, fmt.Errorf("invalid series %q, must be a metric with labels", s)
+	}
+
+	ls := map[string]string{}
+	for _, m := range vs.LabelMatchers {
+		if m.Type != labels.MatchEqual {
+			return nil, fmt.Errorf("invalid series %q, only equal label matchers can be used", s)
+		}
+		ls[m.Name] = m.Value
+	}
+
+	return ls, nil
+}
@@ -0,0 +1,156 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestValidateSLIExamples(t *testing.T) {
+	eventsSLI := func(examples ...prometheus.SLIExample) prometheus.SLI {
+		return prometheus.SLI{
+			Events: &prometheus.SLIEvents{
+				ErrorQuery: `sum(rate(http_requests_total{job="api",code=~"(5..|429)"}[{{.window}}]))`,
+				TotalQuery: `sum(rate(http_requests_total{job="api"}[{{.window}}]))`,
+			},
+			Examples: examples,
+		}
+	}
+
+	tests := map[string]struct {
+		sli        prometheus.SLI
+		expErrsLen int
+	}{
+		"An SLI without examples should not fail.": {
+			sli: eventsSLI(),
+		},
+
+		"An events SLI with the expected error ratio should not fail.": {
+			sli: eventsSLI(prometheus.SLIExample{
+				Name: "10% of errors",
+				Series: map[string]float64{
+					`http_requests_total{job="api", code="500"}`:   5,
+					`http_requests_total{job="api", code="429"}`:   5,
+					`http_requests_total{job="api", code="200"}`:   90,
+					`http_requests_total{job="other", code="500"}`: 100,
+				},
+				ErrorRatio: 0.1,
+			}),
+		},
+
+		"An events SLI without the expected error ratio should fail.": {
+			sli: eventsSLI(prometheus.SLIExample{
+				Name: "404 are errors",
+				Series: map[string]float64{
+					`http_requests_total{job="api", code="404"}`: 10,
+					`http_requests_total{job="api", code="200"}`: 90,
+				},
+				ErrorRatio: 0.1,
+			}),
+			expErrsLen: 1,
+		},
+
+		"An SLI that doesn't return series should fail.": {
+			sli: eventsSLI(prometheus.SLIExample{
+				Series:     map[string]float64{`http_requests_total{job="other"}`: 10},
+				ErrorRatio: 0,
+			}),
+			expErrsLen: 1,
+		},
+
+		"Invalid example series should fail.": {
+			sli: eventsSLI(prometheus.SLIExample{
+				Series:     map[string]float64{`http_requests_total{job=~"api"}`: 10},
+				ErrorRatio: 0,
+			}),
+			expErrsLen: 1,
+		},
+
+		"Every failed example should fail.": {
+			sli: eventsSLI(
+				prometheus.SLIExample{Series: map[string]float64{`http_requests_total{job="api", code="500"}`: 10, `http_requests_total{job="api", code="200"}`: 10}, ErrorRatio: 0.5},
+				prometheus.SLIExample{Series: map[string]float64{`http_requests_total{job="api", code="500"}`: 10}, ErrorRatio: 0},
+				prometheus.SLIExample{Series: map[string]float64{`http_requests_total{job="api", code="200"}`: 10}, ErrorRatio: 0},
+			),
+			expErrsLen: 2,
+		},
+
+		"A raw SLI with vector matching and scalar operations should be evaluated.": {
+			sli: prometheus.SLI{
+				Raw: &prometheus.SLIRaw{
+					ErrorRatioQuery: `1 - (
+  sum by (job) (rate(http_requests_total{code!~"5.."}[{{.window}}]))
+  / on (job) group_left
+  sum by (job) (rate(http_requests_total[{{.window}}]))
+)`,
+				},
+				Examples: []prometheus.SLIExample{{
+					Series: map[string]float64{
+						`http_requests_total{job="api", code="500"}`: 25,
+						`http_requests_total{job="api", code="200"}`: 75,
+					},
+					ErrorRatio: 0.25,
+				}},
+			},
+		},
+
+		"A raw SLI with histogram functions should be evaluated.": {
+			sli: prometheus.SLI{
+				Raw: &prometheus.SLIRaw{
+					ErrorRatioQuery: `histogram_quantile(0.5, sum by (le) (rate(http_request_duration_seconds_bucket[{{.window}}])))`,
+				},
+				Examples: []prometheus.SLIExample{{
+					Series: map[string]float64{
+						`http_request_duration_seconds_bucket{le="0.5"}`:  50,
+						`http_request_duration_seconds_bucket{le="1"}`:    100,
+						`http_request_duration_seconds_bucket{le="+Inf"}`: 100,
+					},
+					ErrorRatio: 0.5,
+				}},
+			},
+		},
+
+		"A raw SLI with subqueries should be evaluated.": {
+			sli: prometheus.SLI{
+				Raw: &prometheus.SLIRaw{
+					ErrorRatioQuery: `max_over_time((sum(rate(http_requests_total{code="500"}[5m])) / sum(rate(http_requests_total[5m])))[{{.window}}:])`,
+				},
+				Examples: []prometheus.SLIExample{{
+					Series: map[string]float64{
+						`http_requests_total{code="500"}`: 20,
+						`http_requests_total{code="200"}`: 80,
+					},
+					ErrorRatio: 0.2,
+				}},
+			},
+		},
+
+		"A raw SLI with expression evaluation errors should fail.": {
+			sli: prometheus.SLI{
+				Raw: &prometheus.SLIRaw{
+					ErrorRatioQuery: `rate(http_requests_errors_total[{{.window}}]) / on() rate(http_requests_total[{{.window}}])`,
+				},
+				Examples: []prometheus.SLIExample{{
+					Series: map[string]float64{
+						`http_requests_errors_total{job="api"}`: 1,
+						`http_requests_total{job="api"}`:        10,
+						`http_requests_total{job="other"}`:      10,
+					},
+					ErrorRatio: 0.1,
+				}},
+			},
+			expErrsLen: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			errs := prometheus.ValidateSLIExamples(prometheus.SLO{ID: "test", SLI: test.sli})
+			assert.Len(errs, test.expErrsLen)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+)
+
+// LatencySLIEvents returns the events SLI of a latency SLI that uses a Prometheus
+// histogram in seconds. The error events are the histogram observations slower than the
+// threshold and the total events all the histogram observations.
+//
+// The histogram metric is the histogram base name (e.g `http_request_duration_seconds`)
+// and the selector the histogram series label matchers (e.g `job="api", code!~"5.."`).
+// Classic histograms use the `_bucket` and `_count` series, so the threshold must be one
+// of the histogram buckets (`le` label). Native histograms use the `histogram_count` and
+// `histogram_fraction` functions, and the threshold doesn't need to be a bucket boundary.
+func LatencySLIEvents(histogramMetric string, threshold time.Duration, selector string, nativeHistogram bool) (*SLIEvents, error) {
+	histogramMetric = strings.TrimSuffix(histogramMetric, "_bucket")
+	if !prommodel.IsValidMetricName(prommodel.LabelValue(histogramMetric)) {
+		return nil, fmt.Errorf("invalid histogram metric %q", histogramMetric)
+	}
+
+	if threshold <= 0 {
+		return nil, fmt.Errorf("latency threshold must be greater than 0")
+	}
+
+	// Use the same `le` format as the Prometheus exposition format.
+	le := strconv.FormatFloat(threshold.Seconds(), 'g', -1, 64)
+	selector = strings.TrimSpace(selector)
+
+	if nativeHistogram {
+		rate := fmt.Sprintf(`sum(rate(%s{%s}[{{.window}}]))`, histogramMetric, selector)
+		totalQuery := fmt.Sprintf(`histogram_count(%s)`, rate)
+
+		return &SLIEvents{
+			ErrorQuery: fmt.Sprintf("%s\n*\n(1 - histogram_fraction(0, %s, %s))", totalQuery, le, rate),
+			TotalQuery: totalQuery,
+		}, nil
+	}
+
+	// Integer bucket boundaries are exposed as `1` or `1.0` depending on the client library. The
+	// regex matcher value is escaped (with the backslashes escaped on the PromQL string).
+	bucketSelector := fmt.Sprintf(`le="%s"`, le)
+	if !strings.ContainsAny(le, ".e") {
+		bucketSelector = fmt.Sprintf(`le=~"%s(\\.0)?"`, strings.ReplaceAll(regexp.QuoteMeta(le), `\`, `\\`))
+	}
+	if selector != "" {
+		bucketSelector = selector + ", " + bucketSelector
+	}
+
+	totalQuery := fmt.Sprintf(`sum(rate(%s_count{%s}[{{.window}}]))`, histogramMetric, selector)
+	goodQuery := fmt.Sprintf(`sum(rate(%s_bucket{%s}[{{.window}}]))`, histogramMetric, bucketSelector)
+
+	return &SLIEvents{
+		ErrorQuery: fmt.Sprintf("(%s)\n-\n(%s)", totalQuery, goodQuery),
+		TotalQuery: totalQuery,
+	}, nil
+}
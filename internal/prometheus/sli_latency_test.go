@@ -0,0 +1,147 @@
+package prometheus_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestLatencySLIEvents(t *testing.T) {
+	tests := map[string]struct {
+		histogramMetric string
+		threshold       time.Duration
+		selector        string
+		nativeHistogram bool
+		expEvents       *prometheus.SLIEvents
+		expErr          bool
+	}{
+		"An invalid histogram metric should fail.": {
+			histogramMetric: "http-request-duration",
+			threshold:       300 * time.Millisecond,
+			expErr:          true,
+		},
+
+		"A missing threshold should fail.": {
+			histogramMetric: "http_request_duration_seconds",
+			expErr:          true,
+		},
+
+		"A latency SLI should generate the histogram events queries.": {
+			histogramMetric: "http_request_duration_seconds",
+			threshold:       300 * time.Millisecond,
+			selector:        `job="api", code!~"5.."`,
+			expEvents: &prometheus.SLIEvents{
+				ErrorQuery: `(sum(rate(http_request_duration_seconds_count{job="api", code!~"5.."}[{{.window}}])))
+-
+(sum(rate(http_request_duration_seconds_bucket{job="api", code!~"5..", le="0.3"}[{{.window}}])))`,
+				TotalQuery: `sum(rate(http_request_duration_seconds_count{job="api", code!~"5.."}[{{.window}}]))`,
+			},
+		},
+
+		"A latency SLI without selector and with the histogram bucket metric should generate the histogram events queries.": {
+			histogramMetric: "http_request_duration_seconds_bucket",
+			threshold:       2 * time.Second,
+			expEvents: &prometheus.SLIEvents{
+				ErrorQuery: `(sum(rate(http_request_duration_seconds_count{}[{{.window}}])))
+-
+(sum(rate(http_request_duration_seconds_bucket{le=~"2(\\.0)?"}[{{.window}}])))`,
+				TotalQuery: `sum(rate(http_request_duration_seconds_count{}[{{.window}}]))`,
+			},
+		},
+
+		"A native histogram latency SLI should generate the native histogram events queries.": {
+			histogramMetric: "http_request_duration_seconds",
+			threshold:       250 * time.Millisecond,
+			selector:        `job="api"`,
+			nativeHistogram: true,
+			expEvents: &prometheus.SLIEvents{
+				ErrorQuery: `histogram_count(sum(rate(http_request_duration_seconds{job="api"}[{{.window}}])))
+*
+(1 - histogram_fraction(0, 0.25, sum(rate(http_request_duration_seconds{job="api"}[{{.window}}]))))`,
+				TotalQuery: `histogram_count(sum(rate(http_request_duration_seconds{job="api"}[{{.window}}])))`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotEvents, err := prometheus.LatencySLIEvents(test.histogramMetric, test.threshold, test.selector, test.nativeHistogram)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expEvents, gotEvents)
+			}
+		})
+	}
+}
+
+func TestLatencySLIEventsEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		threshold time.Duration
+		series    string
+		expResult string
+	}{
+		"An integer threshold should match the buckets without decimals.": {
+			threshold: time.Second,
+			series: `
+  http_request_duration_seconds_count{job="api"} 0+100x10
+  http_request_duration_seconds_bucket{job="api", le="0.5"} 0+60x10
+  http_request_duration_seconds_bucket{job="api", le="1"} 0+90x10
+`,
+			expResult: `
+  {} 0.16666666666666666
+`,
+		},
+
+		"An integer threshold should match the buckets with decimals.": {
+			threshold: time.Second,
+			series: `
+  http_request_duration_seconds_count{job="api"} 0+100x10
+  http_request_duration_seconds_bucket{job="api", le="0.5"} 0+60x10
+  http_request_duration_seconds_bucket{job="api", le="1.0"} 0+90x10
+`,
+			expResult: `
+  {} 0.16666666666666666
+`,
+		},
+
+		"A decimal threshold should match its bucket.": {
+			threshold: 500 * time.Millisecond,
+			series: `
+  http_request_duration_seconds_count{job="api"} 0+100x10
+  http_request_duration_seconds_bucket{job="api", le="0.5"} 0+60x10
+  http_request_duration_seconds_bucket{job="api", le="1"} 0+90x10
+`,
+			expResult: `
+  {} 0.6666666666666666
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			events, err := prometheus.LatencySLIEvents("http_request_duration_seconds", test.threshold, `job="api"`, false)
+			require.NoError(err)
+
+			// Evaluate the error events rate with the Prometheus engine.
+			expr := strings.Join(strings.Fields(events.ErrorQuery), " ")
+			expr = strings.ReplaceAll(expr, "{{.window}}", "5m")
+			promTest, err := promql.NewTest(t, "load 1m"+test.series+"\neval instant at 10m "+expr+test.expResult)
+			require.NoError(err)
+			defer promTest.Close()
+
+			require.NoError(promTest.Run())
+		})
+	}
+}
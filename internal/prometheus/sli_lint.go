@@ -0,0 +1,220 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	promqlparser "github.com/prometheus/prometheus/promql/parser"
+)
+
+// SLI lint rule IDs.
+const (
+	// SLILintRuleRateNonCounter is the rule of the counter functions (`rate`, `irate`, `increase`)
+	// over metrics that don't look like counters.
+	SLILintRuleRateNonCounter = "rate-non-counter"
+	// SLILintRuleMissingSum is the rule of the counter functions that are not aggregated, the
+	// SLI would be a ratio per series instead of a single ratio.
+	SLILintRuleMissingSum = "missing-sum"
+	// SLILintRuleUnguardedDivision is the rule of the divisions by totals that can be zero, a
+	// 0/0 division returns NaN and poisons the SLO period `sum_over_time` ratios.
+	SLILintRuleUnguardedDivision = "unguarded-division"
+	// SLILintRuleIrateLongWindow is the rule of the `irate` over long windows, `irate` only
+	// uses the last two samples of the window.
+	SLILintRuleIrateLongWindow = "irate-long-window"
+)
+
+// SLILintRules are all the SLI lint rule IDs.
+var SLILintRules = []string{
+	SLILintRuleRateNonCounter,
+	SLILintRuleMissingSum,
+	SLILintRuleUnguardedDivision,
+	SLILintRuleIrateLongWindow,
+}
+
+// irateMaxWindow is the maximum `irate` window that is not considered long.
+const irateMaxWindow = 5 * time.Minute
+
+// counterSuffixes are the suffixes of the counter metrics by the Prometheus naming conventions.
+var counterSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+// SLILintFinding is an opinionated finding (anti-pattern) of an SLO SLI query.
+type SLILintFinding struct {
+	SLOID string
+	// Query is the SLI query with the finding (e.g `error ratio`, `error`, `total`).
+	Query   string
+	RuleID  string
+	Message string
+}
+
+// String returns the finding description.
+func (s SLILintFinding) String() string {
+	return fmt.Sprintf("%q SLO SLI %s query [%s]: %s", s.SLOID, s.Query, s.RuleID, s.Message)
+}
+
+// LintSLIs returns the anti-patterns of the SLOs SLI queries, the rules of the disabled
+// rule IDs are not checked. The queries are rendered with the SLO time window, so the
+// window dependent rules check the longest window.
+//
+// These are heuristics, not errors, the queries that can't be rendered or parsed are ignored
+// (the SLO validation already fails on them).
+func LintSLIs(slos []SLO, disabledRules []string) []SLILintFinding {
+	disabled := map[string]bool{}
+	for _, r := range disabledRules {
+		disabled[r] = true
+	}
+
+	findings := []SLILintFinding{}
+	for _, slo := range slos {
+		type sliQuery struct {
+			name  string
+			query string
+			// ratio is true when the query is the SLI ratio (the division is made by the user).
+			ratio bool
+		}
+
+		var queries []sliQuery
+		switch {
+		case slo.SLI.Raw != nil:
+			queries = []sliQuery{{name: "error ratio", query: slo.SLI.Raw.ErrorRatioQuery, ratio: true}}
+		case slo.SLI.Events != nil:
+			queries = []sliQuery{
+				{name: "error", query: slo.SLI.Events.ErrorQuery},
+				{name: "total", query: slo.SLI.Events.TotalQuery},
+			}
+		}
+
+		for _, q := range queries {
+			expr, err := renderSLILintQuery(q.query, slo.TimeWindow)
+			if err != nil {
+				continue
+			}
+
+			for _, f := range lintSLIExpr(expr, q.ratio) {
+				if disabled[f.RuleID] {
+					continue
+				}
+				f.SLOID = slo.ID
+				f.Query = q.name
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	return findings
+}
+
+func renderSLILintQuery(query string, window time.Duration) (promqlparser.Expr, error) {
+	expr, err := renderSLIQuery(query, window)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePromExpr(expr)
+}
+
+func lintSLIExpr(expr promqlparser.Expr, ratio bool) []SLILintFinding {
+	findings := []SLILintFinding{}
+	seen := map[string]bool{}
+	add := func(ruleID, msg string) {
+		if seen[ruleID+msg] {
+			return
+		}
+		seen[ruleID+msg] = true
+		findings = append(findings, SLILintFinding{RuleID: ruleID, Message: msg})
+	}
+
+	promqlparser.Inspect(expr, func(node promqlparser.Node, path []promqlparser.Node) error {
+		switch n := node.(type) {
+		case *promqlparser.Call:
+			if !isCounterFunc(n.Func.Name) || len(n.Args) == 0 {
+				return nil
+			}
+
+			if !isAggregated(path) {
+				add(SLILintRuleMissingSum, fmt.Sprintf("%s is not aggregated (e.g with sum), the SLI will be a ratio per series instead of a single ratio", n.Func.Name))
+			}
+
+			ms, ok := n.Args[0].(*promqlparser.MatrixSelector)
+			if !ok {
+				return nil
+			}
+			if vs, ok := ms.VectorSelector.(*promqlparser.VectorSelector); ok && vs.Name != "" && !isCounterName(vs.Name) {
+				add(SLILintRuleRateNonCounter, fmt.Sprintf("%s over %q metric that doesn't look like a counter (%s suffix)", n.Func.Name, vs.Name, strings.Join(counterSuffixes, ", ")))
+			}
+			if n.Func.Name == "irate" && ms.Range > irateMaxWindow {
+				add(SLILintRuleIrateLongWindow, fmt.Sprintf("irate over %s window only uses the last two samples, use rate instead", timeDurationToPromStr(ms.Range)))
+			}
+
+		case *promqlparser.BinaryExpr:
+			if n.Op == promqlparser.DIV && !isGuardedDivision(n, path) {
+				msg := "division by a total that can be zero without a guard (e.g clamp_min, > 0 or an or fallback), 0/0 returns NaN"
+				if ratio {
+					msg += " and poisons the SLO period ratios"
+				}
+				add(SLILintRuleUnguardedDivision, msg)
+			}
+		}
+
+		return nil
+	})
+
+	return findings
+}
+
+func isCounterFunc(name string) bool {
+	return name == "rate" || name == "irate" || name == "increase"
+}
+
+func isCounterName(name string) bool {
+	for _, s := range counterSuffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isAggregated returns true if any of the node parents aggregates the series.
+func isAggregated(path []promqlparser.Node) bool {
+	for _, p := range path {
+		if a, ok := p.(*promqlparser.AggregateExpr); ok && a.Op != promqlparser.TOPK && a.Op != promqlparser.BOTTOMK {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isGuardedDivision returns true if the division denominator can't be zero or the division
+// has an `or` fallback.
+func isGuardedDivision(div *promqlparser.BinaryExpr, path []promqlparser.Node) bool {
+	for _, p := range path {
+		if b, ok := p.(*promqlparser.BinaryExpr); ok && b.Op == promqlparser.LOR {
+			return true
+		}
+	}
+
+	rhs := div.RHS
+	for {
+		p, ok := rhs.(*promqlparser.ParenExpr)
+		if !ok {
+			break
+		}
+		rhs = p.Expr
+	}
+
+	switch r := rhs.(type) {
+	// Scalar denominators (e.g `/ 100`) are constants.
+	case *promqlparser.NumberLiteral:
+		return r.Val != 0
+	case *promqlparser.Call:
+		return r.Func.Name == "clamp_min" || r.Func.Name == "clamp"
+	// Filtered denominators (e.g `(total > 0)`).
+	case *promqlparser.BinaryExpr:
+		return r.Op.IsComparisonOperator() && !r.ReturnBool
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package prometheus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestLintSLIs(t *testing.T) {
+	tests := map[string]struct {
+		slos          []prometheus.SLO
+		disabledRules []string
+		expFindings   []string
+	}{
+		"Good SLIs should not have findings.": {
+			slos: []prometheus.SLO{
+				{
+					ID:         "test1",
+					TimeWindow: 30 * 24 * time.Hour,
+					SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+						ErrorQuery: `sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))`,
+						TotalQuery: `sum(rate(http_requests_total[{{.window}}]))`,
+					}},
+				},
+				{
+					ID:         "test2",
+					TimeWindow: 30 * 24 * time.Hour,
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `sum(rate(errors_total[{{.window}}])) / clamp_min(sum(rate(requests_total[{{.window}}])), 1)`,
+					}},
+				},
+				{
+					ID:         "test3",
+					TimeWindow: 30 * 24 * time.Hour,
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `(sum(rate(errors_total[{{.window}}])) / sum(rate(requests_total[{{.window}}]))) or vector(0)`,
+					}},
+				},
+				{
+					ID:         "test4",
+					TimeWindow: 30 * 24 * time.Hour,
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `1 - (avg_over_time(up[{{.window}}]) / 1)`,
+					}},
+				},
+			},
+			expFindings: []string{},
+		},
+
+		"SLIs with anti-patterns should have findings.": {
+			slos: []prometheus.SLO{
+				{
+					ID:         "test1",
+					TimeWindow: 30 * 24 * time.Hour,
+					SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+						ErrorQuery: `rate(http_requests_total{code=~"5.."}[{{.window}}])`,
+						TotalQuery: `sum(irate(http_requests[{{.window}}]))`,
+					}},
+				},
+				{
+					ID:         "test2",
+					TimeWindow: 7 * 24 * time.Hour,
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `sum(rate(errors_total[{{.window}}])) / sum(rate(requests_total[{{.window}}]))`,
+					}},
+				},
+			},
+			expFindings: []string{
+				`"test1" SLO SLI error query [missing-sum]: rate is not aggregated (e.g with sum), the SLI will be a ratio per series instead of a single ratio`,
+				`"test1" SLO SLI total query [rate-non-counter]: irate over "http_requests" metric that doesn't look like a counter (_total, _count, _sum, _bucket suffix)`,
+				`"test1" SLO SLI total query [irate-long-window]: irate over 30d window only uses the last two samples, use rate instead`,
+				`"test2" SLO SLI error ratio query [unguarded-division]: division by a total that can be zero without a guard (e.g clamp_min, > 0 or an or fallback), 0/0 returns NaN and poisons the SLO period ratios`,
+			},
+		},
+
+		"Disabled rules should not have findings.": {
+			slos: []prometheus.SLO{
+				{
+					ID:         "test1",
+					TimeWindow: 30 * 24 * time.Hour,
+					SLI: prometheus.SLI{Events: &prometheus.SLIEvents{
+						ErrorQuery: `rate(http_requests_total{code=~"5.."}[{{.window}}])`,
+						TotalQuery: `sum(irate(http_requests[{{.window}}]))`,
+					}},
+				},
+			},
+			disabledRules: []string{"missing-sum", "irate-long-window"},
+			expFindings: []string{
+				`"test1" SLO SLI total query [rate-non-counter]: irate over "http_requests" metric that doesn't look like a counter (_total, _count, _sum, _bucket suffix)`,
+			},
+		},
+
+		"Invalid SLI queries should be ignored.": {
+			slos: []prometheus.SLO{
+				{
+					ID:         "test1",
+					TimeWindow: 30 * 24 * time.Hour,
+					SLI: prometheus.SLI{Raw: &prometheus.SLIRaw{
+						ErrorRatioQuery: `rate(errors[{{.windou}}])`,
+					}},
+				},
+			},
+			expFindings: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotFindings := []string{}
+			for _, f := range prometheus.LintSLIs(test.slos, test.disabledRules) {
+				gotFindings = append(gotFindings, f.String())
+			}
+
+			assert.Equal(test.expFindings, gotFindings)
+		})
+	}
+}
@@ -2,12 +2,20 @@ package prometheus
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/traefik/yaegi/interp"
 	"github.com/traefik/yaegi/stdlib"
@@ -61,10 +69,41 @@ type SLIPlugin struct {
 	Func plugin.SLIPlugin
 }
 
+// DefaultSLIPluginAllowedImports are the Go standard library packages that the SLI plugins
+// can import by default.
+var DefaultSLIPluginAllowedImports = []string{
+	"bytes",
+	"context",
+	"encoding/json",
+	"errors",
+	"fmt",
+	"math",
+	"regexp",
+	"sort",
+	"strconv",
+	"strings",
+	"text/template",
+	"time",
+	"unicode",
+	"unicode/utf8",
+}
+
 type FileSLIPluginRepoConfig struct {
 	FileManager FileManager
 	Paths       []string
-	Logger      log.Logger
+	// PluginTimeout is the maximum time that a plugin can take to be loaded or executed.
+	// If 0, it will not have timeout.
+	PluginTimeout time.Duration
+	// PluginAllowedImports are the Go standard library packages that the plugins can import.
+	// If nil, it will use DefaultSLIPluginAllowedImports.
+	PluginAllowedImports []string
+	// PluginLoadConcurrency is the maximum number of plugins loaded at the same time.
+	// If 0, it will use the number of CPUs.
+	PluginLoadConcurrency int
+	// PluginAllowExecutables enables the compiled plugins, these are executed outside the
+	// Go plugins sandbox. If false, the discovered compiled plugins are ignored.
+	PluginAllowExecutables bool
+	Logger                 log.Logger
 }
 
 func (c *FileSLIPluginRepoConfig) defaults() error {
@@ -72,6 +111,22 @@ func (c *FileSLIPluginRepoConfig) defaults() error {
 		c.FileManager = fileManager{}
 	}
 
+	if c.PluginTimeout < 0 {
+		return fmt.Errorf("plugin timeout can't be negative")
+	}
+
+	if c.PluginAllowedImports == nil {
+		c.PluginAllowedImports = DefaultSLIPluginAllowedImports
+	}
+
+	if c.PluginLoadConcurrency < 0 {
+		return fmt.Errorf("plugin load concurrency can't be negative")
+	}
+
+	if c.PluginLoadConcurrency == 0 {
+		c.PluginLoadConcurrency = runtime.NumCPU()
+	}
+
 	if c.Logger == nil {
 		c.Logger = log.Noop
 	}
@@ -86,11 +141,25 @@ func NewFileSLIPluginRepo(config FileSLIPluginRepoConfig) (*FileSLIPluginRepo, e
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	allowedImports := map[string]struct{}{}
+	for _, imp := range config.PluginAllowedImports {
+		allowedImports[imp] = struct{}{}
+	}
+
 	f := &FileSLIPluginRepo{
-		fileManager:  config.FileManager,
-		pluginLoader: sliPluginLoader{},
-		paths:        config.Paths,
-		logger:       config.Logger,
+		fileManager: config.FileManager,
+		pluginLoader: sliPluginLoader{
+			timeout:        config.PluginTimeout,
+			allowedImports: allowedImports,
+			symbols:        allowedSymbols(allowedImports),
+		},
+		binaryPluginLoader: sliPluginBinaryLoader{
+			timeout: config.PluginTimeout,
+		},
+		allowExecutables: config.PluginAllowExecutables,
+		paths:            config.Paths,
+		loadConcurrency:  config.PluginLoadConcurrency,
+		logger:           config.Logger,
 	}
 
 	err = f.Reload(context.Background())
@@ -107,8 +176,17 @@ func NewFileSLIPluginRepo(config FileSLIPluginRepoConfig) (*FileSLIPluginRepo, e
 //
 // - The plugin must be in a `plugin.go` file inside a directory.
 // - All the plugin must be in the `plugin.go` file.
-// - The plugin can't import anything apart from the Go standard library.
-// - `reflect` and `unsafe` packages can't be used.
+// - The plugin can't import anything apart from the allowed Go standard library packages.
+// - By default `reflect`, `unsafe`, `os`, `net`... packages can't be used.
+// - The plugin load and execution can have a timeout.
+//
+// The plugins are loaded concurrently, and the Go plugins already loaded from the same
+// source code are reused on reloads instead of evaluating them again.
+//
+// Compiled plugins are also supported when the executables are allowed, these are
+// executables named `sloth-sli-plugin` inside a directory that are executed as a subprocess
+// using the binary SLI plugin protocol (JSON requests and responses over stdin and stdout,
+// with a protocol version handshake). These run outside the Go plugins sandbox.
 //
 // These rules provide multiple things:
 // - Easy discovery of plugins without the need to provide extra data (import paths, path sanitization...).
@@ -116,15 +194,21 @@ func NewFileSLIPluginRepo(config FileSLIPluginRepoConfig) (*FileSLIPluginRepo, e
 // - Force keeping the plugins simple, small and without smart code.
 // - Force avoiding DRY in small plugins and embrace WET to have independent plugins.
 type FileSLIPluginRepo struct {
-	pluginLoader sliPluginLoader
-	fileManager  FileManager
-	paths        []string
-	plugins      map[string]SLIPlugin
-	mu           sync.RWMutex
-	logger       log.Logger
+	pluginLoader       sliPluginLoader
+	binaryPluginLoader sliPluginBinaryLoader
+	allowExecutables   bool
+	fileManager        FileManager
+	paths              []string
+	loadConcurrency    int
+	plugins            map[string]SLIPlugin
+	// srcPlugins are the loaded Go plugins by their source code checksum.
+	srcPlugins map[string]SLIPlugin
+	mu         sync.RWMutex
+	logger     log.Logger
 }
 
-var sliPluginNameRegex = regexp.MustCompile("plugin.go$")
+// sliPluginNameRegex matches the plugin files by their base name.
+var sliPluginNameRegex = regexp.MustCompile(`(^|[/\\])(plugin\.go|` + sliPluginBinaryName + `)$`)
 
 // Reload will reload all the plugins again from the paths.
 func (f *FileSLIPluginRepo) Reload(ctx context.Context) error {
@@ -136,37 +220,67 @@ func (f *FileSLIPluginRepo) Reload(ctx context.Context) error {
 			return fmt.Errorf("could not discover SLI plugins: %w", err)
 		}
 		for _, dPath := range discoveredPaths {
+			if filepath.Base(dPath) == sliPluginBinaryName && !f.allowExecutables {
+				f.logger.WithValues(log.Kv{"plugin-path": dPath}).Warningf("Compiled SLI plugin ignored, executable plugins are not allowed")
+				continue
+			}
 			paths[dPath] = struct{}{}
 		}
 	}
 
-	// Load the plugins.
-	plugins := map[string]SLIPlugin{}
+	sortedPaths := make([]string, 0, len(paths))
 	for path := range paths {
-		pluginData, err := f.fileManager.ReadFile(ctx, path)
-		if err != nil {
-			return fmt.Errorf("could not read %q plugin data: %w", path, err)
-		}
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
 
-		// Create the plugin.
-		plugin, err := f.pluginLoader.LoadRawSLIPlugin(ctx, string(pluginData))
-		if err != nil {
-			return fmt.Errorf("could not load %q plugin: %w", path, err)
+	// Load the plugins concurrently.
+	f.mu.RLock()
+	cachedSrcPlugins := f.srcPlugins
+	f.mu.RUnlock()
+
+	results := make([]loadedSLIPlugin, len(sortedPaths))
+	sem := make(chan struct{}, f.loadConcurrency)
+	var wg sync.WaitGroup
+	for i, path := range sortedPaths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = f.loadPlugin(ctx, path, cachedSrcPlugins)
+		}(i, path)
+	}
+	wg.Wait()
+
+	// Check the loaded plugins in order so the errors are deterministic.
+	plugins := map[string]SLIPlugin{}
+	srcPlugins := map[string]SLIPlugin{}
+	for i, res := range results {
+		path := sortedPaths[i]
+		if res.err != nil {
+			return fmt.Errorf("could not load %q plugin: %w", path, res.err)
 		}
 
 		// Check collision.
-		_, ok := plugins[plugin.ID]
+		_, ok := plugins[res.plugin.ID]
 		if ok {
-			return fmt.Errorf("2 or more plugins with the same %q ID have been loaded", plugin.ID)
+			return fmt.Errorf("2 or more plugins with the same %q ID have been loaded", res.plugin.ID)
 		}
 
-		plugins[plugin.ID] = *plugin
-		f.logger.WithValues(log.Kv{"plugin-id": plugin.ID, "plugin-path": path}).Debugf("SLI plugin loaded")
+		plugins[res.plugin.ID] = *res.plugin
+		if res.srcChecksum != "" {
+			srcPlugins[res.srcChecksum] = *res.plugin
+		}
+		f.logger.WithValues(log.Kv{"plugin-id": res.plugin.ID, "plugin-path": path, "cached": res.cached}).Debugf("SLI plugin loaded")
 	}
 
 	// Set loaded plugins.
 	f.mu.Lock()
 	f.plugins = plugins
+	f.srcPlugins = srcPlugins
 	f.mu.Unlock()
 
 	f.logger.WithValues(log.Kv{"plugins": len(plugins)}).Infof("SLI plugins loaded")
@@ -174,6 +288,37 @@ func (f *FileSLIPluginRepo) Reload(ctx context.Context) error {
 	return nil
 }
 
+type loadedSLIPlugin struct {
+	plugin *SLIPlugin
+	// srcChecksum is the source code checksum of the Go plugins.
+	srcChecksum string
+	cached      bool
+	err         error
+}
+
+func (f *FileSLIPluginRepo) loadPlugin(ctx context.Context, path string, cachedSrcPlugins map[string]SLIPlugin) loadedSLIPlugin {
+	// Compiled plugins.
+	if filepath.Base(path) == sliPluginBinaryName {
+		plugin, err := f.binaryPluginLoader.LoadBinarySLIPlugin(ctx, path)
+		return loadedSLIPlugin{plugin: plugin, err: err}
+	}
+
+	pluginData, err := f.fileManager.ReadFile(ctx, path)
+	if err != nil {
+		return loadedSLIPlugin{err: fmt.Errorf("could not read plugin data: %w", err)}
+	}
+
+	// Reuse the plugin if we already loaded the same source code, the plugin is
+	// independent of its path.
+	checksum := fmt.Sprintf("%x", sha256.Sum256(pluginData))
+	if plugin, ok := cachedSrcPlugins[checksum]; ok {
+		return loadedSLIPlugin{plugin: &plugin, srcChecksum: checksum, cached: true}
+	}
+
+	plugin, err := f.pluginLoader.LoadRawSLIPlugin(ctx, string(pluginData))
+	return loadedSLIPlugin{plugin: plugin, srcChecksum: checksum, err: err}
+}
+
 func (f *FileSLIPluginRepo) ListSLIPlugins(ctx context.Context) (map[string]SLIPlugin, error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -194,7 +339,12 @@ func (f *FileSLIPluginRepo) GetSLIPlugin(ctx context.Context, id string) (*SLIPl
 }
 
 // sliPluginLoader knows how to load Go SLI plugins using Yaegi.
-type sliPluginLoader struct{}
+type sliPluginLoader struct {
+	timeout        time.Duration
+	allowedImports map[string]struct{}
+	// symbols are the allowed standard library symbols, shared by all the interpreters.
+	symbols interp.Exports
+}
 
 var packageRegexp = regexp.MustCompile(`(?m)^package +([^\s]+) *$`)
 
@@ -207,70 +357,197 @@ var packageRegexp = regexp.MustCompile(`(?m)^package +([^\s]+) *$`)
 // - A constant called `SLIPluginID` to obtain the plugin ID.
 // - A constant called `SLIPluginVersion` to obtain the plugin version.
 func (s sliPluginLoader) LoadRawSLIPlugin(ctx context.Context, src string) (*SLIPlugin, error) {
+	// Check the plugin only uses the allowed imports.
+	err := s.checkImports(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	_, pluginID, pluginFunc, err := s.evalSLIPlugin(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.timeout > 0 {
+		pluginFunc = s.timeoutSLIPlugin(src)
+	}
+
+	return &SLIPlugin{
+		ID:   pluginID,
+		Func: pluginFunc,
+	}, nil
+}
+
+// evalSLIPlugin evaluates the plugin source code in a new interpreter and returns the
+// interpreter with the plugin ID and func.
+func (s sliPluginLoader) evalSLIPlugin(ctx context.Context, src string) (*interp.Interpreter, pluginv1.SLIPluginID, pluginv1.SLIPlugin, error) {
 	// Load the plugin in a new interpreter.
 	// For each plugin we need to use an independent interpreter to avoid name collisions.
 	yaegiInterp, err := s.newYaeginInterpreter()
 	if err != nil {
-		return nil, fmt.Errorf("could not create a new Yaegi interpreter: %w", err)
+		return nil, "", nil, fmt.Errorf("could not create a new Yaegi interpreter: %w", err)
 	}
 
 	_, err = yaegiInterp.EvalWithContext(ctx, src)
 	if err != nil {
-		return nil, fmt.Errorf("could not evaluate plugin source code: %w", err)
+		return nil, "", nil, fmt.Errorf("could not evaluate plugin source code: %w", err)
 	}
 
 	// Discover package name.
 	packageMatch := packageRegexp.FindStringSubmatch(src)
 	if len(packageMatch) != 2 {
-		return nil, fmt.Errorf("invalid plugin source code, could not get package name")
+		return nil, "", nil, fmt.Errorf("invalid plugin source code, could not get package name")
 	}
 	packageName := packageMatch[1]
 
 	// Get plugin version and check if is a known one.
 	pluginVerTmp, err := yaegiInterp.EvalWithContext(ctx, fmt.Sprintf("%s.SLIPluginVersion", packageName))
 	if err != nil {
-		return nil, fmt.Errorf("could not get plugin version: %w", err)
+		return nil, "", nil, fmt.Errorf("could not get plugin version: %w", err)
 	}
 
 	pluginVer, ok := pluginVerTmp.Interface().(pluginv1.SLIPluginVersion)
 	if !ok || (pluginVer != pluginv1.Version) {
-		return nil, fmt.Errorf("unsuported plugin version: %s", pluginVer)
+		return nil, "", nil, fmt.Errorf("unsuported plugin version: %s", pluginVer)
 	}
 
 	// Get plugin ID.
 	pluginIDTmp, err := yaegiInterp.EvalWithContext(ctx, fmt.Sprintf("%s.SLIPluginID", packageName))
 	if err != nil {
-		return nil, fmt.Errorf("could not get plugin ID: %w", err)
+		return nil, "", nil, fmt.Errorf("could not get plugin ID: %w", err)
 	}
 
 	pluginID, ok := pluginIDTmp.Interface().(pluginv1.SLIPluginID)
 	if !ok {
-		return nil, fmt.Errorf("invalid SLI plugin ID type")
+		return nil, "", nil, fmt.Errorf("invalid SLI plugin ID type")
 	}
 
 	// Get plugin logic.
 	pluginFuncTmp, err := yaegiInterp.EvalWithContext(ctx, fmt.Sprintf("%s.SLIPlugin", packageName))
 	if err != nil {
-		return nil, fmt.Errorf("could not get plugin: %w", err)
+		return nil, "", nil, fmt.Errorf("could not get plugin: %w", err)
 	}
 
 	pluginFunc, ok := pluginFuncTmp.Interface().(pluginv1.SLIPlugin)
 	if !ok {
-		return nil, fmt.Errorf("invalid SLI plugin type")
+		return nil, "", nil, fmt.Errorf("invalid SLI plugin type")
 	}
 
-	return &SLIPlugin{
-		ID:   pluginID,
-		Func: pluginFunc,
-	}, nil
+	return yaegiInterp, pluginID, pluginFunc, nil
+}
+
+// checkImports checks the plugin source code only imports allowed packages.
+func (s sliPluginLoader) checkImports(src string) error {
+	f, err := parser.ParseFile(token.NewFileSet(), "plugin.go", src, parser.ImportsOnly)
+	if err != nil {
+		return fmt.Errorf("could not parse plugin source code: %w", err)
+	}
+
+	for _, imp := range f.Imports {
+		impPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return fmt.Errorf("invalid plugin import %s: %w", imp.Path.Value, err)
+		}
+
+		if _, ok := s.allowedImports[impPath]; !ok {
+			return fmt.Errorf("plugin imports %q package and is not allowed", impPath)
+		}
+	}
+
+	return nil
+}
+
+// timeoutSLIPlugin returns a plugin that executes the plugin source code with a timeout. Every
+// execution uses a new interpreter that is stopped when the execution context ends, so the
+// interpreted plugin code stops running after the timeout (the plugin calls to the standard
+// library can't be stopped, these should use the context).
+func (s sliPluginLoader) timeoutSLIPlugin(src string) pluginv1.SLIPlugin {
+	return func(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+
+		// Use a new interpreter so stopping it doesn't affect the other executions.
+		yaegiInterp, _, plugin, err := s.evalSLIPlugin(ctx, src)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("plugin execution timeout: %w", ctx.Err())
+			}
+			return "", err
+		}
+
+		// Stop the interpreter running code when the context ends, the interpreter evaluation
+		// blocks until then.
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			_, _ = yaegiInterp.EvalWithContext(ctx, "select {}")
+		}()
+		defer func() {
+			cancel()
+			<-stopped
+		}()
+
+		type result struct {
+			query string
+			err   error
+		}
+		resC := make(chan result, 1)
+		go func() {
+			query, err := plugin(ctx, meta, labels, options)
+			resC <- result{query: query, err: err}
+		}()
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("plugin execution timeout: %w", ctx.Err())
+		case res := <-resC:
+			return res.query, res.err
+		}
+	}
 }
 
 func (s sliPluginLoader) newYaeginInterpreter() (*interp.Interpreter, error) {
+	symbols := s.symbols
+	if symbols == nil {
+		symbols = allowedSymbols(s.allowedImports)
+	}
+
 	i := interp.New(interp.Options{})
-	err := i.Use(stdlib.Symbols)
+	err := i.Use(symbols)
 	if err != nil {
 		return nil, fmt.Errorf("could not use stdlib symbols: %w", err)
 	}
 
 	return i, nil
 }
+
+// allowedSymbols returns the Yaegi standard library symbols of the allowed imports.
+func allowedSymbols(allowedImports map[string]struct{}) interp.Exports {
+	// Only expose the allowed standard library packages.
+	symbols := interp.Exports{}
+	for key, pkgSymbols := range stdlib.Symbols {
+		if _, ok := allowedImports[symbolsImportPath(key)]; ok {
+			symbols[key] = pkgSymbols
+		}
+	}
+
+	return symbols
+}
+
+// symbolsImportPath returns the import path of a Yaegi symbols key, these keys are
+// in `{import path}/{package name}` form (e.g `text/template/template`).
+func symbolsImportPath(key string) string {
+	dir, name := path.Split(key)
+	dir = path.Clean(dir)
+	if dir != "." && path.Base(dir) == name {
+		return dir
+	}
+
+	return key
+}
@@ -0,0 +1,150 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	pluginv1 "github.com/slok/sloth/pkg/prometheus/plugin/v1"
+)
+
+// sliPluginBinaryName is the file name of the compiled SLI plugins, these are executed
+// as a subprocess for every request using the binary SLI plugin protocol, so they can be
+// versioned, distributed as binaries and written in any language.
+const sliPluginBinaryName = "sloth-sli-plugin"
+
+// Binary SLI plugin protocol commands.
+const (
+	sliPluginBinaryCmdHandshake = "handshake"
+	sliPluginBinaryCmdSLI       = "sli"
+)
+
+// sliPluginBinaryProtocolVersions are the binary SLI plugin protocol versions supported by
+// Sloth, the plugins select one of them on the handshake.
+var sliPluginBinaryProtocolVersions = []int{1}
+
+// sliPluginBinaryRequest is the binary SLI plugin protocol request, written as JSON on the
+// plugin stdin.
+type sliPluginBinaryRequest struct {
+	Command string `json:"command"`
+	// ProtocolVersions are the protocol versions supported by Sloth, only set on the handshake.
+	ProtocolVersions []int `json:"protocol_versions,omitempty"`
+	// ProtocolVersion is the handshake negotiated protocol version.
+	ProtocolVersion int               `json:"protocol_version,omitempty"`
+	Meta            map[string]string `json:"meta,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Options         map[string]string `json:"options,omitempty"`
+}
+
+// sliPluginBinaryResponse is the binary SLI plugin protocol response, read as JSON from the
+// plugin stdout.
+type sliPluginBinaryResponse struct {
+	// ProtocolVersion is the protocol version selected by the plugin, only on the handshake.
+	ProtocolVersion int `json:"protocol_version,omitempty"`
+	// PluginID is the ID of the plugin, only on the handshake.
+	PluginID string `json:"plugin_id,omitempty"`
+	// PluginVersion is the SLI plugin API version (e.g `prometheus/v1`), only on the handshake.
+	PluginVersion string `json:"plugin_version,omitempty"`
+	Query         string `json:"query,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// sliPluginBinaryLoader knows how to load compiled SLI plugins.
+type sliPluginBinaryLoader struct {
+	timeout time.Duration
+}
+
+// LoadBinarySLIPlugin loads a compiled SLI plugin making the protocol handshake, where the
+// plugin selects one of the supported protocol versions and returns its ID and SLI plugin
+// API version.
+func (s sliPluginBinaryLoader) LoadBinarySLIPlugin(ctx context.Context, path string) (*SLIPlugin, error) {
+	res, err := s.exec(ctx, path, sliPluginBinaryRequest{
+		Command:          sliPluginBinaryCmdHandshake,
+		ProtocolVersions: sliPluginBinaryProtocolVersions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("plugin handshake failed: %w", err)
+	}
+
+	supported := false
+	for _, v := range sliPluginBinaryProtocolVersions {
+		if v == res.ProtocolVersion {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, fmt.Errorf("unsupported plugin protocol version: %d", res.ProtocolVersion)
+	}
+
+	if res.PluginVersion != pluginv1.Version {
+		return nil, fmt.Errorf("unsuported plugin version: %s", res.PluginVersion)
+	}
+
+	if res.PluginID == "" {
+		return nil, fmt.Errorf("plugin ID is required")
+	}
+
+	protocolVersion := res.ProtocolVersion
+	return &SLIPlugin{
+		ID: res.PluginID,
+		Func: func(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+			res, err := s.exec(ctx, path, sliPluginBinaryRequest{
+				Command:         sliPluginBinaryCmdSLI,
+				ProtocolVersion: protocolVersion,
+				Meta:            meta,
+				Labels:          labels,
+				Options:         options,
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return res.Query, nil
+		},
+	}, nil
+}
+
+// exec executes the plugin binary with a protocol request.
+func (s sliPluginBinaryLoader) exec(ctx context.Context, path string, req sliPluginBinaryRequest) (*sliPluginBinaryResponse, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(reqData)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("plugin execution timeout: %w", ctx.Err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugin execution failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	res := &sliPluginBinaryResponse{}
+	err = json.Unmarshal(stdout.Bytes(), res)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin response: %w", err)
+	}
+
+	if res.Error != "" {
+		return nil, fmt.Errorf("plugin error: %s", res.Error)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,204 @@
+package prometheus_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestBinarySLIPluginLoader(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("binary SLI plugin tests use shell scripts")
+	}
+
+	tests := map[string]struct {
+		pluginScript string
+		timeout      time.Duration
+		options      map[string]string
+		expPluginID  string
+		expSLIQuery  string
+		expErrLoad   bool
+		expErr       bool
+	}{
+		"A plugin that fails should fail.": {
+			pluginScript: `echo "something" >&2; exit 1`,
+			expErrLoad:   true,
+		},
+
+		"A plugin with an invalid response should fail.": {
+			pluginScript: `echo "{"`,
+			expErrLoad:   true,
+		},
+
+		"A plugin with an unsupported protocol version should fail.": {
+			pluginScript: `echo '{"protocol_version": 2, "plugin_id": "test_plugin", "plugin_version": "prometheus/v1"}'`,
+			expErrLoad:   true,
+		},
+
+		"A plugin with an unsupported plugin version should fail.": {
+			pluginScript: `echo '{"protocol_version": 1, "plugin_id": "test_plugin", "plugin_version": "prometheus/v2"}'`,
+			expErrLoad:   true,
+		},
+
+		"A plugin without ID should fail.": {
+			pluginScript: `echo '{"protocol_version": 1, "plugin_version": "prometheus/v1"}'`,
+			expErrLoad:   true,
+		},
+
+		"A plugin that exceeds the timeout should fail.": {
+			pluginScript: `exec sleep 2`,
+			timeout:      50 * time.Millisecond,
+			expErrLoad:   true,
+		},
+
+		"A plugin that returns an error should fail.": {
+			pluginScript: `
+case "$req" in
+  *handshake*) echo '{"protocol_version": 1, "plugin_id": "test_plugin", "plugin_version": "prometheus/v1"}' ;;
+  *) echo '{"error": "something"}' ;;
+esac`,
+			expPluginID: "test_plugin",
+			expErr:      true,
+		},
+
+		"A plugin should return the SLI query.": {
+			pluginScript: `
+case "$req" in
+  *handshake*) echo '{"protocol_version": 1, "plugin_id": "test_plugin", "plugin_version": "prometheus/v1"}' ;;
+  *'"protocol_version":1'*'"options":{"job":"svc1"}'*) echo '{"query": "rate(errors{job=\"svc1\"}[{{.window}}])"}' ;;
+  *) echo '{"error": "unexpected request"}' ;;
+esac`,
+			options:     map[string]string{"job": "svc1"},
+			expPluginID: "test_plugin",
+			expSLIQuery: `rate(errors{job="svc1"}[{{.window}}])`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			// Create the plugin binary.
+			dir := t.TempDir()
+			pluginDir := filepath.Join(dir, "testplugin")
+			require.NoError(os.MkdirAll(pluginDir, 0755))
+			script := "#!/bin/sh\nreq=$(cat)\n" + test.pluginScript + "\n"
+			require.NoError(os.WriteFile(filepath.Join(pluginDir, "sloth-sli-plugin"), []byte(script), 0755))
+
+			// Create repository and load plugins.
+			config := prometheus.FileSLIPluginRepoConfig{
+				Paths:                  []string{dir},
+				PluginTimeout:          test.timeout,
+				PluginAllowExecutables: true,
+			}
+			repo, err := prometheus.NewFileSLIPluginRepo(config)
+			if test.expErrLoad {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+
+			// Get plugin.
+			plugin, err := repo.GetSLIPlugin(context.TODO(), test.expPluginID)
+			require.NoError(err)
+			assert.Equal(test.expPluginID, plugin.ID)
+
+			gotSLIQuery, err := plugin.Func(context.TODO(), map[string]string{}, map[string]string{}, test.options)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expSLIQuery, gotSLIQuery)
+			}
+		})
+	}
+}
+
+func TestFileSLIPluginRepoDiscovery(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("binary SLI plugin tests use shell scripts")
+	}
+
+	goPlugin := func(id string) string {
+		return `package testplugin
+
+import "context"
+
+const (
+	SLIPluginID      = "` + id + `"
+	SLIPluginVersion = "prometheus/v1"
+)
+
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	return "", nil
+}
+`
+	}
+	binaryPlugin := func(id string) string {
+		return `#!/bin/sh
+echo '{"protocol_version": 1, "plugin_id": "` + id + `", "plugin_version": "prometheus/v1"}'
+`
+	}
+
+	tests := map[string]struct {
+		files            map[string]string
+		allowExecutables bool
+		expPluginIDs     []string
+	}{
+		"Only the files named as plugins should be loaded.": {
+			files: map[string]string{
+				"p1/plugin.go":             goPlugin("test_plugin1"),
+				"p2/myplugin.go":           goPlugin("test_plugin2"),
+				"p3/sloth-sli-plugin":      binaryPlugin("test_plugin3"),
+				"p4/foo-sloth-sli-plugin":  binaryPlugin("test_plugin4"),
+				"p5/sloth-sli-plugin.orig": binaryPlugin("test_plugin5"),
+			},
+			allowExecutables: true,
+			expPluginIDs:     []string{"test_plugin1", "test_plugin3"},
+		},
+
+		"Compiled plugins should be ignored if the executables are not allowed.": {
+			files: map[string]string{
+				"p1/plugin.go":        goPlugin("test_plugin1"),
+				"p3/sloth-sli-plugin": binaryPlugin("test_plugin3"),
+			},
+			expPluginIDs: []string{"test_plugin1"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			dir := t.TempDir()
+			for path, data := range test.files {
+				path = filepath.Join(dir, filepath.FromSlash(path))
+				require.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(os.WriteFile(path, []byte(data), 0755))
+			}
+
+			repo, err := prometheus.NewFileSLIPluginRepo(prometheus.FileSLIPluginRepoConfig{
+				Paths:                  []string{dir},
+				PluginAllowExecutables: test.allowExecutables,
+			})
+			require.NoError(err)
+
+			plugins, err := repo.ListSLIPlugins(context.TODO())
+			require.NoError(err)
+			gotPluginIDs := []string{}
+			for id := range plugins {
+				gotPluginIDs = append(gotPluginIDs, id)
+			}
+			assert.ElementsMatch(test.expPluginIDs, gotPluginIDs)
+		})
+	}
+}
@@ -2,7 +2,9 @@ package prometheus_test
 
 import (
 	"context"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -14,15 +16,17 @@ import (
 
 func TestSLIPluginLoader(t *testing.T) {
 	tests := map[string]struct {
-		pluginSrc   string
-		pluginID    string
-		meta        map[string]string
-		labels      map[string]string
-		options     map[string]string
-		expPluginID string
-		expSLIQuery string
-		expErrLoad  bool
-		expErr      bool
+		pluginSrc      string
+		timeout        time.Duration
+		allowedImports []string
+		pluginID       string
+		meta           map[string]string
+		labels         map[string]string
+		options        map[string]string
+		expPluginID    string
+		expSLIQuery    string
+		expErrLoad     bool
+		expErr         bool
 	}{
 		"Plugin without version should fail on load.": {
 			pluginSrc: `
@@ -39,6 +43,96 @@ func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (st
 			expErrLoad: true,
 		},
 
+		"Plugin with a not allowed import should fail on load.": {
+			pluginSrc: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+const (
+	SLIPluginID      = "test_plugin"
+	SLIPluginVersion = "prometheus/v1"
+)
+
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	return os.Getenv("SECRET"), nil
+}
+`,
+			expErrLoad: true,
+		},
+
+		"Plugin with a custom allowed import should load and return a correct SLI.": {
+			pluginSrc: `
+package testplugin
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	SLIPluginID      = "test_plugin"
+	SLIPluginVersion = "prometheus/v1"
+)
+
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	return strings.ToLower("TEST_QUERY{}"), nil
+}
+`,
+			allowedImports: []string{"context", "strings"},
+			expPluginID:    "test_plugin",
+			expSLIQuery:    "test_query{}",
+		},
+
+		"Plugin with an import not present on the custom allowed imports should fail on load.": {
+			pluginSrc: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	SLIPluginID      = "test_plugin"
+	SLIPluginVersion = "prometheus/v1"
+)
+
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	return fmt.Sprintf("test_query{}"), nil
+}
+`,
+			allowedImports: []string{"context"},
+			expErrLoad:     true,
+		},
+
+		"Plugin that exceeds the execution timeout should return errors.": {
+			pluginSrc: `
+package testplugin
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	SLIPluginID      = "test_plugin"
+	SLIPluginVersion = "prometheus/v1"
+)
+
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	time.Sleep(5 * time.Second)
+	return "test_query{}", nil
+}
+`,
+			timeout:     50 * time.Millisecond,
+			expPluginID: "test_plugin",
+			expErr:      true,
+		},
+
 		"Basic plugin should load and return a correct SLI.": {
 			pluginSrc: `
 package testplugin
@@ -120,8 +214,10 @@ func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (st
 
 			// Create repository and load plugins.
 			config := prometheus.FileSLIPluginRepoConfig{
-				FileManager: mfm,
-				Paths:       []string{"./"},
+				FileManager:          mfm,
+				Paths:                []string{"./"},
+				PluginTimeout:        test.timeout,
+				PluginAllowedImports: test.allowedImports,
 			}
 			repo, err := prometheus.NewFileSLIPluginRepo(config)
 			if test.expErrLoad {
@@ -146,3 +242,160 @@ func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (st
 		})
 	}
 }
+
+func TestSLIPluginLoaderTimeoutStopsPlugin(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	pluginSrc := `
+package testplugin
+
+import "context"
+
+const (
+	SLIPluginID      = "test_plugin"
+	SLIPluginVersion = "prometheus/v1"
+)
+
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	for {
+	}
+	return "test_query{}", nil
+}
+`
+
+	mfm := &prometheusmock.FileManager{}
+	mfm.On("FindFiles", mock.Anything, "./", mock.Anything).Once().Return([]string{"testplugin/test.go"}, nil)
+	mfm.On("ReadFile", mock.Anything, "testplugin/test.go").Once().Return([]byte(pluginSrc), nil)
+	repo, err := prometheus.NewFileSLIPluginRepo(prometheus.FileSLIPluginRepoConfig{
+		FileManager:   mfm,
+		Paths:         []string{"./"},
+		PluginTimeout: 50 * time.Millisecond,
+	})
+	require.NoError(err)
+	plugin, err := repo.GetSLIPlugin(context.TODO(), "test_plugin")
+	require.NoError(err)
+
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 5; i++ {
+		_, err = plugin.Func(context.TODO(), nil, nil, nil)
+		assert.Error(err)
+	}
+
+	// The plugin executions should be stopped after the timeout, not leaked.
+	assert.Eventually(func() bool { return runtime.NumGoroutine() <= goroutines }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFileSLIPluginRepoReload(t *testing.T) {
+	pluginSrc := func(id string) []byte {
+		return []byte(`
+package testplugin
+
+import "context"
+
+const (
+	SLIPluginID      = "` + id + `"
+	SLIPluginVersion = "prometheus/v1"
+)
+
+func SLIPlugin(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+	return "` + id + `_query{}", nil
+}
+`)
+	}
+
+	tests := map[string]struct {
+		mock         func(m *prometheusmock.FileManager)
+		expPluginIDs []string
+		expErrLoad   bool
+		expErrReload bool
+	}{
+		"Multiple plugins should be loaded concurrently.": {
+			mock: func(m *prometheusmock.FileManager) {
+				m.On("FindFiles", mock.Anything, "./", mock.Anything).Return([]string{"p1/plugin.go", "p2/plugin.go", "p3/plugin.go"}, nil)
+				m.On("ReadFile", mock.Anything, "p1/plugin.go").Return(pluginSrc("test_plugin1"), nil)
+				m.On("ReadFile", mock.Anything, "p2/plugin.go").Return(pluginSrc("test_plugin2"), nil)
+				m.On("ReadFile", mock.Anything, "p3/plugin.go").Return(pluginSrc("test_plugin3"), nil)
+			},
+			expPluginIDs: []string{"test_plugin1", "test_plugin2", "test_plugin3"},
+		},
+
+		"Plugins with the same ID should fail.": {
+			mock: func(m *prometheusmock.FileManager) {
+				m.On("FindFiles", mock.Anything, "./", mock.Anything).Return([]string{"p1/plugin.go", "p2/plugin.go"}, nil)
+				m.On("ReadFile", mock.Anything, "p1/plugin.go").Return(pluginSrc("test_plugin1"), nil)
+				m.On("ReadFile", mock.Anything, "p2/plugin.go").Return(pluginSrc("test_plugin1"), nil)
+			},
+			expErrLoad: true,
+		},
+
+		"Reloading the same plugins should reuse the loaded plugins.": {
+			mock: func(m *prometheusmock.FileManager) {
+				m.On("FindFiles", mock.Anything, "./", mock.Anything).Return([]string{"p1/plugin.go", "p2/plugin.go"}, nil)
+				m.On("ReadFile", mock.Anything, "p1/plugin.go").Return(pluginSrc("test_plugin1"), nil)
+				m.On("ReadFile", mock.Anything, "p2/plugin.go").Return(pluginSrc("test_plugin2"), nil)
+			},
+			expPluginIDs: []string{"test_plugin1", "test_plugin2"},
+		},
+
+		"Reloading changed plugins should load the new plugins.": {
+			mock: func(m *prometheusmock.FileManager) {
+				m.On("FindFiles", mock.Anything, "./", mock.Anything).Return([]string{"p1/plugin.go"}, nil)
+				m.On("ReadFile", mock.Anything, "p1/plugin.go").Once().Return(pluginSrc("test_plugin1"), nil)
+				m.On("ReadFile", mock.Anything, "p1/plugin.go").Once().Return(pluginSrc("test_plugin2"), nil)
+			},
+			expPluginIDs: []string{"test_plugin2"},
+		},
+
+		"Reloading invalid plugins should fail and keep the loaded plugins.": {
+			mock: func(m *prometheusmock.FileManager) {
+				m.On("FindFiles", mock.Anything, "./", mock.Anything).Return([]string{"p1/plugin.go"}, nil)
+				m.On("ReadFile", mock.Anything, "p1/plugin.go").Once().Return(pluginSrc("test_plugin1"), nil)
+				m.On("ReadFile", mock.Anything, "p1/plugin.go").Once().Return([]byte("package testplugin\n\nfunc"), nil)
+			},
+			expPluginIDs: []string{"test_plugin1"},
+			expErrReload: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			mfm := &prometheusmock.FileManager{}
+			test.mock(mfm)
+
+			config := prometheus.FileSLIPluginRepoConfig{
+				FileManager:           mfm,
+				Paths:                 []string{"./"},
+				PluginLoadConcurrency: 2,
+			}
+			repo, err := prometheus.NewFileSLIPluginRepo(config)
+			if test.expErrLoad {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+
+			err = repo.Reload(context.TODO())
+			if test.expErrReload {
+				assert.Error(err)
+			} else {
+				require.NoError(err)
+			}
+
+			// Check.
+			plugins, err := repo.ListSLIPlugins(context.TODO())
+			require.NoError(err)
+			gotPluginIDs := []string{}
+			for id, p := range plugins {
+				gotSLIQuery, err := p.Func(context.TODO(), nil, nil, nil)
+				require.NoError(err)
+				assert.Equal(id+"_query{}", gotSLIQuery)
+				gotPluginIDs = append(gotPluginIDs, id)
+			}
+			assert.ElementsMatch(test.expPluginIDs, gotPluginIDs)
+		})
+	}
+}
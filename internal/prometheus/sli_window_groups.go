@@ -0,0 +1,157 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+)
+
+// SLIWindowGroups partitions the SLI recording rules of all the SLOs in rule groups shared
+// per SLI window (e.g all the `5m` rules together and all the `30d` rules together), so the
+// long windows rules can have a slower evaluation interval, spreading the evaluation load of
+// large SLO sets.
+type SLIWindowGroups struct {
+	// Enabled enables the SLI recording rules partitioning by window.
+	Enabled bool
+	// Intervals are the evaluation intervals by SLI window, the windows without interval
+	// use the SLI recordings rule group interval.
+	Intervals map[time.Duration]time.Duration
+}
+
+// SLIRecordingsRuleGroup is a rule group of SLI recording rules.
+type SLIRecordingsRuleGroup struct {
+	Name     string
+	Interval time.Duration
+	Labels   map[string]string
+	Rules    []rulefmt.Rule
+}
+
+// SLOSLIRecordings are the SLI recording rules of an SLO and its rule group settings.
+type SLOSLIRecordings struct {
+	SLOID string
+	Rules []rulefmt.Rule
+	// Interval is the SLO SLI recordings rule group interval.
+	Interval time.Duration
+	// Labels are the SLO rule group labels.
+	Labels map[string]string
+}
+
+// SLORuleGroup returns the SLO SLI recordings rule group, with all the SLO SLI recording rules,
+// or if the partitioning is enabled, only the rules without window (e.g the timeslice rules).
+// Returns nil if the SLO rule group doesn't have rules.
+func (s SLIWindowGroups) SLORuleGroup(slo SLOSLIRecordings) *SLIRecordingsRuleGroup {
+	rules := slo.Rules
+	if s.Enabled {
+		rules = nil
+		for _, r := range slo.Rules {
+			if _, ok := r.Labels[sloWindowLabelName]; !ok {
+				rules = append(rules, r)
+			}
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return &SLIRecordingsRuleGroup{
+		Name:     fmt.Sprintf(sliRecordingsGroupNameFmt, slo.SLOID),
+		Interval: slo.Interval,
+		Labels:   slo.Labels,
+		Rules:    rules,
+	}
+}
+
+// WindowRuleGroups returns the SLI recording rule groups shared by the SLOs per SLI window,
+// sorted by window, if the partitioning is enabled. The SLOs with a different rule group
+// interval or labels for the same window are on a different rule group of the window.
+func (s SLIWindowGroups) WindowRuleGroups(slos []SLOSLIRecordings) ([]SLIRecordingsRuleGroup, error) {
+	if !s.Enabled {
+		return nil, nil
+	}
+
+	type windowGroups struct {
+		name   string
+		keys   []string
+		groups map[string]*SLIRecordingsRuleGroup
+	}
+
+	windows := map[time.Duration]*windowGroups{}
+	for _, slo := range slos {
+		for _, r := range slo.Rules {
+			w, ok := r.Labels[sloWindowLabelName]
+			if !ok {
+				continue
+			}
+
+			pw, err := prommodel.ParseDuration(w)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q SLO %q SLI recording rule window %q: %w", slo.SLOID, r.Record, w, err)
+			}
+			window := time.Duration(pw)
+
+			interval, ok := s.Intervals[window]
+			if !ok {
+				interval = slo.Interval
+			}
+
+			wgs, ok := windows[window]
+			if !ok {
+				wgs = &windowGroups{name: w, groups: map[string]*SLIRecordingsRuleGroup{}}
+				windows[window] = wgs
+			}
+
+			key := sliWindowGroupKey(interval, slo.Labels)
+			g, ok := wgs.groups[key]
+			if !ok {
+				g = &SLIRecordingsRuleGroup{Interval: interval, Labels: slo.Labels}
+				wgs.groups[key] = g
+				wgs.keys = append(wgs.keys, key)
+			}
+			g.Rules = append(g.Rules, r)
+		}
+	}
+
+	sortedWindows := make([]time.Duration, 0, len(windows))
+	for w := range windows {
+		sortedWindows = append(sortedWindows, w)
+	}
+	sort.Slice(sortedWindows, func(i, j int) bool { return sortedWindows[i] < sortedWindows[j] })
+
+	groups := []SLIRecordingsRuleGroup{}
+	for _, w := range sortedWindows {
+		wgs := windows[w]
+		for i, key := range wgs.keys {
+			g := wgs.groups[key]
+			g.Name = fmt.Sprintf(sliWindowRecordingsGroupNameFmt, wgs.name)
+			if i > 0 {
+				g.Name = fmt.Sprintf("%s-%d", g.Name, i+1)
+			}
+			groups = append(groups, *g)
+		}
+	}
+
+	return groups, nil
+}
+
+// sliWindowGroupKey returns the key of the SLI window rule group of the SLOs with the same rule
+// group interval and labels.
+func sliWindowGroupKey(interval time.Duration, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(interval.String())
+	for _, k := range keys {
+		fmt.Fprintf(&b, ",%q=%q", k, labels[k])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package prometheus
+
+import (
+	"sort"
+)
+
+// DefaultSLOInventoryTeamLabel is the SLO label that has the SLO owner team by default.
+const DefaultSLOInventoryTeamLabel = "team"
+
+// SLOInventoryFilter filters the SLOs of an inventory, the unset fields don't filter.
+type SLOInventoryFilter struct {
+	Service string
+	Team    string
+	// TeamLabel is the SLO label that has the SLO owner team. If empty, it will use
+	// DefaultSLOInventoryTeamLabel.
+	TeamLabel    string
+	MinObjective *float64
+	MaxObjective *float64
+}
+
+// Match returns true if the SLO matches the filter.
+func (f SLOInventoryFilter) Match(slo SLO) bool {
+	if f.Service != "" && slo.Service != f.Service {
+		return false
+	}
+
+	if f.Team != "" {
+		teamLabel := f.TeamLabel
+		if teamLabel == "" {
+			teamLabel = DefaultSLOInventoryTeamLabel
+		}
+		if slo.Labels[teamLabel] != f.Team {
+			return false
+		}
+	}
+
+	if f.MinObjective != nil && slo.Objective < *f.MinObjective {
+		return false
+	}
+
+	if f.MaxObjective != nil && slo.Objective > *f.MaxObjective {
+		return false
+	}
+
+	return true
+}
+
+// ListSLOInventory returns a page of the SLOs that match the filter sorted by ID, and the
+// total number of SLOs that match the filter. If limit is 0, it will not limit the page.
+func ListSLOInventory(slos []StorageSLO, filter SLOInventoryFilter, offset, limit int) (page []StorageSLO, total int) {
+	matched := []StorageSLO{}
+	for _, s := range slos {
+		if filter.Match(s.SLO) {
+			matched = append(matched, s)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].SLO.ID < matched[j].SLO.ID })
+
+	total = len(matched)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	return matched[offset:end], total
+}
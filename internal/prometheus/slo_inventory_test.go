@@ -0,0 +1,86 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestListSLOInventory(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	slos := []prometheus.StorageSLO{
+		{Source: "svc2.yaml", SLO: prometheus.SLO{ID: "svc2-slo1", Service: "svc2", Objective: 99, Labels: map[string]string{"team": "team-b", "owner": "team-a"}}},
+		{Source: "svc1.yaml", SLO: prometheus.SLO{ID: "svc1-slo2", Service: "svc1", Objective: 99.99, Labels: map[string]string{"team": "team-a"}}},
+		{Source: "svc1.yaml", SLO: prometheus.SLO{ID: "svc1-slo1", Service: "svc1", Objective: 99.9, Labels: map[string]string{"team": "team-a"}}},
+		{Source: "svc3.yaml", SLO: prometheus.SLO{ID: "svc3-slo1", Service: "svc3", Objective: 95}},
+	}
+
+	tests := map[string]struct {
+		filter   prometheus.SLOInventoryFilter
+		offset   int
+		limit    int
+		expIDs   []string
+		expTotal int
+	}{
+		"Without filter should return all the SLOs sorted by ID.": {
+			expIDs:   []string{"svc1-slo1", "svc1-slo2", "svc2-slo1", "svc3-slo1"},
+			expTotal: 4,
+		},
+
+		"Filtering by service should return the service SLOs.": {
+			filter:   prometheus.SLOInventoryFilter{Service: "svc1"},
+			expIDs:   []string{"svc1-slo1", "svc1-slo2"},
+			expTotal: 2,
+		},
+
+		"Filtering by team should return the team SLOs.": {
+			filter:   prometheus.SLOInventoryFilter{Team: "team-b"},
+			expIDs:   []string{"svc2-slo1"},
+			expTotal: 1,
+		},
+
+		"Filtering by team with a custom team label should use the label.": {
+			filter:   prometheus.SLOInventoryFilter{Team: "team-a", TeamLabel: "owner"},
+			expIDs:   []string{"svc2-slo1"},
+			expTotal: 1,
+		},
+
+		"Filtering by objective range should return the SLOs inside the range.": {
+			filter:   prometheus.SLOInventoryFilter{MinObjective: f(99), MaxObjective: f(99.9)},
+			expIDs:   []string{"svc1-slo1", "svc2-slo1"},
+			expTotal: 2,
+		},
+
+		"Paginating should return the page and the total SLOs.": {
+			offset:   1,
+			limit:    2,
+			expIDs:   []string{"svc1-slo2", "svc2-slo1"},
+			expTotal: 4,
+		},
+
+		"Paginating after the last SLO should return an empty page.": {
+			offset:   10,
+			limit:    2,
+			expIDs:   []string{},
+			expTotal: 4,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotPage, gotTotal := prometheus.ListSLOInventory(slos, test.filter, test.offset, test.limit)
+
+			gotIDs := []string{}
+			for _, s := range gotPage {
+				gotIDs = append(gotIDs, s.SLO.ID)
+			}
+			assert.Equal(test.expIDs, gotIDs)
+			assert.Equal(test.expTotal, gotTotal)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SLOOverlap are SLOs of the same service that measure the same SLI on the same time
+// window, only differing on the objective (or alerting). These SLOs could be consolidated
+// in a single SLO, to avoid redundant recording rules series and conflicting pages.
+type SLOOverlap struct {
+	Service    string
+	TimeWindow time.Duration
+	SLOIDs     []string
+	Objectives []float64
+}
+
+// String returns the overlap description.
+func (s SLOOverlap) String() string {
+	ids := make([]string, 0, len(s.SLOIDs))
+	for _, id := range s.SLOIDs {
+		ids = append(ids, fmt.Sprintf("%q", id))
+	}
+
+	return fmt.Sprintf("%s SLOs of %q service measure the same SLI on the same time window with %v objectives, consider consolidating them in a single SLO",
+		strings.Join(ids, ", "), s.Service, s.Objectives)
+}
+
+// FindOverlappingSLOs returns the overlapping SLOs, the SLI expressions are compared after
+// being rendered and parsed, so formatting differences are ignored.
+func FindOverlappingSLOs(slos []SLO) []SLOOverlap {
+	type overlapKey struct {
+		service    string
+		timeWindow time.Duration
+		sli        string
+	}
+
+	keys := []overlapKey{}
+	overlaps := map[overlapKey]*SLOOverlap{}
+	for _, slo := range slos {
+		sli, ok := getSLIKey(slo.SLI)
+		if !ok {
+			continue
+		}
+
+		key := overlapKey{service: slo.Service, timeWindow: slo.TimeWindow, sli: sli}
+		overlap, ok := overlaps[key]
+		if !ok {
+			overlap = &SLOOverlap{Service: slo.Service, TimeWindow: slo.TimeWindow}
+			overlaps[key] = overlap
+			keys = append(keys, key)
+		}
+		overlap.SLOIDs = append(overlap.SLOIDs, slo.ID)
+		overlap.Objectives = append(overlap.Objectives, slo.Objective)
+	}
+
+	// Keep the SLOs order for deterministic overlaps.
+	res := []SLOOverlap{}
+	for _, k := range keys {
+		if o := overlaps[k]; len(o.SLOIDs) > 1 {
+			res = append(res, *o)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Service < res[j].Service })
+
+	return res
+}
+
+// getSLIKey returns a key that identifies the SLI measurement.
+func getSLIKey(sli SLI) (string, bool) {
+	switch {
+	case sli.Raw != nil:
+		return fmt.Sprintf("raw|%s|%s", sli.Offset, normalizePromExpr(sli.Raw.ErrorRatioQuery)), true
+	case sli.Events != nil:
+		return fmt.Sprintf("events|%s|%s|%s", sli.Offset, normalizePromExpr(sli.Events.ErrorQuery), normalizePromExpr(sli.Events.TotalQuery)), true
+	}
+
+	return "", false
+}
+
+// normalizePromExpr returns the canonical form of a templated Prometheus expression, if
+// the expression can't be parsed it will fallback to the expression without whitespace.
+func normalizePromExpr(expr string) string {
+	e, err := parseTplPromExpression(expr)
+	if err == nil {
+		return e.String()
+	}
+
+	return strings.Join(strings.Fields(expr), "")
+}
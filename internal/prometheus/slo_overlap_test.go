@@ -0,0 +1,88 @@
+package prometheus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestFindOverlappingSLOs(t *testing.T) {
+	eventsSLI := func() prometheus.SLI {
+		return prometheus.SLI{Events: &prometheus.SLIEvents{
+			ErrorQuery: `sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))`,
+			TotalQuery: `sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))`,
+		}}
+	}
+	rawSLI := func() prometheus.SLI {
+		return prometheus.SLI{Raw: &prometheus.SLIRaw{
+			ErrorRatioQuery: `sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}])) / sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))`,
+		}}
+	}
+
+	tests := map[string]struct {
+		slos        []prometheus.SLO
+		expOverlaps []prometheus.SLOOverlap
+	}{
+		"Having SLOs with different SLIs should not overlap.": {
+			slos: []prometheus.SLO{
+				{ID: "slo1", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99.9, SLI: eventsSLI()},
+				{ID: "slo2", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99, SLI: rawSLI()},
+			},
+			expOverlaps: []prometheus.SLOOverlap{},
+		},
+
+		"Having SLOs with the same SLI on different time windows should not overlap.": {
+			slos: []prometheus.SLO{
+				{ID: "slo1", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99.9, SLI: eventsSLI()},
+				{ID: "slo2", Service: "svc1", TimeWindow: 7 * 24 * time.Hour, Objective: 99.9, SLI: eventsSLI()},
+			},
+			expOverlaps: []prometheus.SLOOverlap{},
+		},
+
+		"Having SLOs with the same SLI on different services should not overlap.": {
+			slos: []prometheus.SLO{
+				{ID: "slo1", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99.9, SLI: eventsSLI()},
+				{ID: "slo2", Service: "svc2", TimeWindow: 30 * 24 * time.Hour, Objective: 99, SLI: eventsSLI()},
+			},
+			expOverlaps: []prometheus.SLOOverlap{},
+		},
+
+		"Having SLOs with the same SLI and different offsets should not overlap.": {
+			slos: func() []prometheus.SLO {
+				sli := eventsSLI()
+				sli.Offset = 5 * time.Minute
+				return []prometheus.SLO{
+					{ID: "slo1", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99.9, SLI: eventsSLI()},
+					{ID: "slo2", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99, SLI: sli},
+				}
+			}(),
+			expOverlaps: []prometheus.SLOOverlap{},
+		},
+
+		"Having SLOs with the same SLI on the same service and time window should overlap.": {
+			slos: []prometheus.SLO{
+				{ID: "slo1", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99.9, SLI: eventsSLI()},
+				{ID: "slo2", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99, SLI: rawSLI()},
+				{ID: "slo3", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 99, SLI: eventsSLI()},
+				{ID: "slo4", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 95, SLI: rawSLI()},
+				{ID: "slo5", Service: "svc1", TimeWindow: 30 * 24 * time.Hour, Objective: 90, SLI: eventsSLI()},
+			},
+			expOverlaps: []prometheus.SLOOverlap{
+				{Service: "svc1", TimeWindow: 30 * 24 * time.Hour, SLOIDs: []string{"slo1", "slo3", "slo5"}, Objectives: []float64{99.9, 99, 90}},
+				{Service: "svc1", TimeWindow: 30 * 24 * time.Hour, SLOIDs: []string{"slo2", "slo4"}, Objectives: []float64{99, 95}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotOverlaps := prometheus.FindOverlappingSLOs(test.slos)
+			assert.Equal(test.expOverlaps, gotOverlaps)
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SLOQuery is a query that can be evaluated over the SLOs. The queries are simple
+// expressions in `{field} {operator} {value}` form that can be combined with `and`
+// and `or` (`and` has precedence), e.g:
+//
+//	labels.owner == "team-x" and page_alert.disabled == true or objective < 99.9
+//
+// Supported fields:
+// - `id`, `name`, `service`, `description`: String fields.
+// - `objective`: Number field.
+// - `time_window`: Duration field (e.g `30d`).
+// - `labels.{key}`, `annotations.{key}`: String fields (empty if missing).
+// - `page_alert.name`, `ticket_alert.name`: String fields.
+// - `page_alert.disabled`, `ticket_alert.disabled`: Boolean fields.
+// - `sli.type`: String field (`raw` or `events`).
+//
+// Supported operators:
+// - `==`, `!=`: All fields.
+// - `<`, `<=`, `>`, `>=`: Number and duration fields.
+// - `=~`, `!~`: Regex match on string fields, the regexes are anchored like the
+// Prometheus ones (e.g `team-.*`).
+type SLOQuery struct {
+	// The query is in disjunctive normal form: OR of ANDs.
+	conds [][]sloQueryCond
+}
+
+// Match returns true if the SLO matches the query.
+func (s SLOQuery) Match(slo SLO) bool {
+	for _, andConds := range s.conds {
+		match := true
+		for _, c := range andConds {
+			if !c.match(slo) {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseSLOQuery parses an SLO query.
+func ParseSLOQuery(query string) (*SLOQuery, error) {
+	tokens, err := tokenizeSLOQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("query is empty")
+	}
+
+	q := &SLOQuery{}
+	andConds := []sloQueryCond{}
+	for len(tokens) > 0 {
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("invalid query, expected `{field} {operator} {value}` condition at %q", strings.Join(tokens, " "))
+		}
+
+		cond, err := newSLOQueryCond(tokens[0], tokens[1], tokens[2])
+		if err != nil {
+			return nil, err
+		}
+		andConds = append(andConds, *cond)
+		tokens = tokens[3:]
+
+		if len(tokens) == 0 {
+			break
+		}
+
+		switch strings.ToLower(tokens[0]) {
+		case "and", "&&":
+		case "or", "||":
+			q.conds = append(q.conds, andConds)
+			andConds = []sloQueryCond{}
+		default:
+			return nil, fmt.Errorf("invalid query, expected `and` or `or`, got %q", tokens[0])
+		}
+		tokens = tokens[1:]
+
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("invalid query, missing condition at the end")
+		}
+	}
+	q.conds = append(q.conds, andConds)
+
+	return q, nil
+}
+
+var sloQueryOperators = []string{"==", "!=", "<=", ">=", "=~", "!~", "<", ">", "&&", "||"}
+
+// tokenizeSLOQuery splits the query in words, quoted strings and operators.
+func tokenizeSLOQuery(query string) ([]string, error) {
+	tokens := []string{}
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(rs) && rs[end] != r {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("invalid query, unterminated quoted string")
+			}
+			tokens = append(tokens, string(rs[i:end+1]))
+			i = end + 1
+
+		default:
+			// Operators.
+			isOp := false
+			for _, op := range sloQueryOperators {
+				if strings.HasPrefix(string(rs[i:]), op) {
+					tokens = append(tokens, op)
+					i += len([]rune(op))
+					isOp = true
+					break
+				}
+			}
+			if isOp {
+				continue
+			}
+
+			// Words.
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) && !strings.ContainsRune(`"'=!<>&|`, rs[end]) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("invalid query, unexpected %q character", r)
+			}
+			tokens = append(tokens, string(rs[i:end]))
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type sloQueryFieldKind int
+
+const (
+	sloQueryFieldString sloQueryFieldKind = iota
+	sloQueryFieldNumber
+	sloQueryFieldDuration
+	sloQueryFieldBool
+)
+
+type sloQueryCond struct {
+	kind     sloQueryFieldKind
+	get      func(SLO) interface{}
+	operator string
+	value    interface{}
+}
+
+func newSLOQueryCond(field, operator, rawValue string) (*sloQueryCond, error) {
+	kind, get, err := getSLOQueryField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check operator.
+	allowedOps := map[sloQueryFieldKind][]string{
+		sloQueryFieldString:   {"==", "!=", "=~", "!~"},
+		sloQueryFieldNumber:   {"==", "!=", "<", "<=", ">", ">="},
+		sloQueryFieldDuration: {"==", "!=", "<", "<=", ">", ">="},
+		sloQueryFieldBool:     {"==", "!="},
+	}[kind]
+	validOp := false
+	for _, op := range allowedOps {
+		if op == operator {
+			validOp = true
+			break
+		}
+	}
+	if !validOp {
+		return nil, fmt.Errorf("invalid %q operator for %q field, supported: %s", operator, field, strings.Join(allowedOps, ", "))
+	}
+
+	// Parse value.
+	v := rawValue
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+		v = v[1 : len(v)-1]
+	}
+
+	var value interface{}
+	switch kind {
+	case sloQueryFieldString:
+		if operator == "=~" || operator == "!~" {
+			r, err := regexp.Compile("^(?:" + v + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q regex: %w", v, err)
+			}
+			value = r
+		} else {
+			value = v
+		}
+	case sloQueryFieldNumber:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q number for %q field", v, field)
+		}
+		value = f
+	case sloQueryFieldDuration:
+		d, err := ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q duration for %q field", v, field)
+		}
+		value = d
+	case sloQueryFieldBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q boolean for %q field", v, field)
+		}
+		value = b
+	}
+
+	return &sloQueryCond{
+		kind:     kind,
+		get:      get,
+		operator: operator,
+		value:    value,
+	}, nil
+}
+
+func getSLOQueryField(field string) (sloQueryFieldKind, func(SLO) interface{}, error) {
+	switch {
+	case field == "id":
+		return sloQueryFieldString, func(s SLO) interface{} { return s.ID }, nil
+	case field == "name":
+		return sloQueryFieldString, func(s SLO) interface{} { return s.Name }, nil
+	case field == "service":
+		return sloQueryFieldString, func(s SLO) interface{} { return s.Service }, nil
+	case field == "description":
+		return sloQueryFieldString, func(s SLO) interface{} { return s.Description }, nil
+	case field == "objective":
+		return sloQueryFieldNumber, func(s SLO) interface{} { return s.Objective }, nil
+	case field == "time_window":
+		return sloQueryFieldDuration, func(s SLO) interface{} { return s.TimeWindow }, nil
+	case field == "page_alert.name":
+		return sloQueryFieldString, func(s SLO) interface{} { return s.PageAlertMeta.Name }, nil
+	case field == "page_alert.disabled":
+		return sloQueryFieldBool, func(s SLO) interface{} { return s.PageAlertMeta.Disable }, nil
+	case field == "ticket_alert.name":
+		return sloQueryFieldString, func(s SLO) interface{} { return s.TicketAlertMeta.Name }, nil
+	case field == "ticket_alert.disabled":
+		return sloQueryFieldBool, func(s SLO) interface{} { return s.TicketAlertMeta.Disable }, nil
+	case field == "sli.type":
+		return sloQueryFieldString, func(s SLO) interface{} {
+			switch {
+			case s.SLI.Raw != nil:
+				return "raw"
+			case s.SLI.Events != nil:
+				return "events"
+			}
+			return ""
+		}, nil
+	case strings.HasPrefix(field, "labels.") && len(field) > len("labels."):
+		key := strings.TrimPrefix(field, "labels.")
+		return sloQueryFieldString, func(s SLO) interface{} { return s.Labels[key] }, nil
+	case strings.HasPrefix(field, "annotations.") && len(field) > len("annotations."):
+		key := strings.TrimPrefix(field, "annotations.")
+		return sloQueryFieldString, func(s SLO) interface{} { return s.Annotations[key] }, nil
+	}
+
+	return 0, nil, fmt.Errorf("unknown %q query field", field)
+}
+
+func (c sloQueryCond) match(slo SLO) bool {
+	got := c.get(slo)
+
+	switch c.kind {
+	case sloQueryFieldString:
+		g := got.(string)
+		switch c.operator {
+		case "==":
+			return g == c.value.(string)
+		case "!=":
+			return g != c.value.(string)
+		case "=~":
+			return c.value.(*regexp.Regexp).MatchString(g)
+		case "!~":
+			return !c.value.(*regexp.Regexp).MatchString(g)
+		}
+
+	case sloQueryFieldNumber:
+		return compareSLOQueryNumbers(c.operator, got.(float64), c.value.(float64))
+
+	case sloQueryFieldDuration:
+		return compareSLOQueryNumbers(c.operator, float64(got.(time.Duration)), float64(c.value.(time.Duration)))
+
+	case sloQueryFieldBool:
+		if c.operator == "==" {
+			return got.(bool) == c.value.(bool)
+		}
+		return got.(bool) != c.value.(bool)
+	}
+
+	return false
+}
+
+func compareSLOQueryNumbers(operator string, got, exp float64) bool {
+	switch operator {
+	case "==":
+		return got == exp
+	case "!=":
+		return got != exp
+	case "<":
+		return got < exp
+	case "<=":
+		return got <= exp
+	case ">":
+		return got > exp
+	case ">=":
+		return got >= exp
+	}
+
+	return false
+}
@@ -0,0 +1,160 @@
+package prometheus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func getQuerySLO() prometheus.SLO {
+	return prometheus.SLO{
+		ID:          "svc01-slo1",
+		Name:        "slo1",
+		Service:     "svc01",
+		Description: "Some description.",
+		TimeWindow:  30 * 24 * time.Hour,
+		Objective:   99.9,
+		SLI: prometheus.SLI{
+			Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+		},
+		Labels:          map[string]string{"owner": "team-x", "tier": "1"},
+		Annotations:     map[string]string{"runbook": "http://runbook.com"},
+		PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+		TicketAlertMeta: prometheus.AlertMeta{Name: "TicketAlert"},
+	}
+}
+
+func TestSLOQuery(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		expMatch bool
+		expErr   bool
+	}{
+		"An empty query should fail.": {
+			query:  "  ",
+			expErr: true,
+		},
+
+		"An unknown field should fail.": {
+			query:  "team == x",
+			expErr: true,
+		},
+
+		"An incomplete condition should fail.": {
+			query:  "objective <",
+			expErr: true,
+		},
+
+		"A missing condition after a logical operator should fail.": {
+			query:  "objective < 99.9 and",
+			expErr: true,
+		},
+
+		"An unterminated quoted string should fail.": {
+			query:  `labels.owner == "team-x`,
+			expErr: true,
+		},
+
+		"An invalid operator for the field should fail.": {
+			query:  "name < slo1",
+			expErr: true,
+		},
+
+		"An invalid number should fail.": {
+			query:  "objective < high",
+			expErr: true,
+		},
+
+		"An invalid regex should fail.": {
+			query:  "name =~ '('",
+			expErr: true,
+		},
+
+		"A matching number condition should match.": {
+			query:    "objective < 99.99",
+			expMatch: true,
+		},
+
+		"A not matching number condition should not match.": {
+			query:    "objective >= 99.99",
+			expMatch: false,
+		},
+
+		"A matching duration condition should match.": {
+			query:    "time_window == 30d",
+			expMatch: true,
+		},
+
+		"A matching label condition should match.": {
+			query:    `labels.owner == "team-x"`,
+			expMatch: true,
+		},
+
+		"A missing label should be empty.": {
+			query:    `labels.missing == ""`,
+			expMatch: true,
+		},
+
+		"A matching regex condition should match.": {
+			query:    `annotations.runbook =~ 'http://.*'`,
+			expMatch: true,
+		},
+
+		"A regex condition should match the whole value.": {
+			query:    `annotations.runbook =~ 'http://'`,
+			expMatch: false,
+		},
+
+		"A matching negated regex condition should match.": {
+			query:    `service !~ svc02`,
+			expMatch: true,
+		},
+
+		"A matching bool condition should match.": {
+			query:    `page_alert.disabled == true`,
+			expMatch: true,
+		},
+
+		"A matching SLI type condition should match.": {
+			query:    `sli.type == raw`,
+			expMatch: true,
+		},
+
+		"Multiple conditions with and should match if all match.": {
+			query:    `labels.owner == team-x and page_alert.disabled == true && objective<99.99`,
+			expMatch: true,
+		},
+
+		"Multiple conditions with and should not match if any doesn't match.": {
+			query:    `labels.owner == team-x and ticket_alert.disabled == true`,
+			expMatch: false,
+		},
+
+		"Multiple conditions with or should match if any matches.": {
+			query:    `labels.owner == team-y or ticket_alert.name == TicketAlert`,
+			expMatch: true,
+		},
+
+		"And should have precedence over or.": {
+			query:    `labels.owner == team-x or name == slo2 and objective > 99.99`,
+			expMatch: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			q, err := prometheus.ParseSLOQuery(test.query)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expMatch, q.Match(getQuerySLO()))
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ValueQuerier knows how to execute Prometheus instant queries that return a single value.
+type ValueQuerier interface {
+	// QueryValue returns the value of the query single series result, false if the
+	// result doesn't have series.
+	QueryValue(ctx context.Context, query string, ts time.Time) (float64, bool, error)
+}
+
+//go:generate mockery --case underscore --output prometheusmock --outpkg prometheusmock --name ValueQuerier
+
+// SLOReport is the current state of an SLO, based on the Sloth metadata recording rules
+// evaluated by Prometheus. The values without data are nil.
+type SLOReport struct {
+	SLOID   string
+	Service string
+	SLOName string
+	// Objective is the SLO objective ratio (0-1) of the generated rules.
+	Objective *float64
+	// SLI is the SLI ratio (0-1) of the SLO period.
+	SLI *float64
+	// ErrorBudgetRemaining is the remaining error budget ratio of the SLO period, negative
+	// when the error budget has been exhausted.
+	ErrorBudgetRemaining *float64
+	// BurnRate is the current error budget burn rate.
+	BurnRate *float64
+}
+
+// ReportSLOs queries the Sloth metadata recording rules of the SLOs to report their current
+// state. The metadata recording rules are required (not generated on minimal mode).
+//
+// The SLOs with multiple SLI series (e.g an SLI per cluster) report the worst series state:
+// the lowest SLI and remaining error budget, and the highest burn rate.
+func ReportSLOs(ctx context.Context, querier ValueQuerier, slos []SLO, ts time.Time) ([]SLOReport, error) {
+	reports := make([]SLOReport, 0, len(slos))
+	for _, slo := range slos {
+		filter := labelsToPromFilter(slo.GetSLOIDPromLabels())
+		report := SLOReport{
+			SLOID:   slo.ID,
+			Service: slo.Service,
+			SLOName: slo.Name,
+		}
+
+		queries := []struct {
+			query string
+			value **float64
+		}{
+			{query: fmt.Sprintf("max(%s%s)", metricSLOObjectiveRatio, filter), value: &report.Objective},
+			// The period burn rate is the SLO period error ratio divided by the error budget.
+			{query: fmt.Sprintf("min(1 - (%s%s * %s%s))", metricSLOPeriodBurnRateRatio, filter, metricSLOErrorBudgetRatio, filter), value: &report.SLI},
+			{query: fmt.Sprintf("min(%s%s)", metricSLOPeriodErrorBudgetRemainingRatio, filter), value: &report.ErrorBudgetRemaining},
+			{query: fmt.Sprintf("max(%s%s)", metricSLOCurrentBurnRateRatio, filter), value: &report.BurnRate},
+		}
+		for _, q := range queries {
+			v, ok, err := querier.QueryValue(ctx, q.query, ts)
+			if err != nil {
+				return nil, fmt.Errorf("could not query %q SLO: %w", slo.ID, err)
+			}
+			if ok {
+				*q.value = &v
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,160 @@
+package prometheus_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/prometheus"
+	"github.com/slok/sloth/internal/prometheus/prometheusmock"
+)
+
+func TestReportSLOs(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	f := func(v float64) *float64 { return &v }
+
+	slo := prometheus.SLO{ID: "svc1-slo1", Service: "svc1", Name: "slo1"}
+	filter := `{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1"}`
+	objectiveQuery := `max(slo:objective:ratio` + filter + `)`
+	sliQuery := `min(1 - (slo:period_burn_rate:ratio` + filter + ` * slo:error_budget:ratio` + filter + `))`
+	remainingQuery := `min(slo:period_error_budget_remaining:ratio` + filter + `)`
+	burnRateQuery := `max(slo:current_burn_rate:ratio` + filter + `)`
+
+	tests := map[string]struct {
+		slos       []prometheus.SLO
+		mock       func(m *prometheusmock.ValueQuerier)
+		expReports []prometheus.SLOReport
+		expErr     bool
+	}{
+		"SLOs with data should report their state.": {
+			slos: []prometheus.SLO{slo},
+			mock: func(m *prometheusmock.ValueQuerier) {
+				m.On("QueryValue", mock.Anything, objectiveQuery, now).Once().Return(0.999, true, nil)
+				m.On("QueryValue", mock.Anything, sliQuery, now).Once().Return(0.9995, true, nil)
+				m.On("QueryValue", mock.Anything, remainingQuery, now).Once().Return(0.5, true, nil)
+				m.On("QueryValue", mock.Anything, burnRateQuery, now).Once().Return(1.5, true, nil)
+			},
+			expReports: []prometheus.SLOReport{
+				{SLOID: "svc1-slo1", Service: "svc1", SLOName: "slo1", Objective: f(0.999), SLI: f(0.9995), ErrorBudgetRemaining: f(0.5), BurnRate: f(1.5)},
+			},
+		},
+
+		"SLOs without data should not have values.": {
+			slos: []prometheus.SLO{slo},
+			mock: func(m *prometheusmock.ValueQuerier) {
+				m.On("QueryValue", mock.Anything, objectiveQuery, now).Once().Return(0.999, true, nil)
+				m.On("QueryValue", mock.Anything, sliQuery, now).Once().Return(0.0, false, nil)
+				m.On("QueryValue", mock.Anything, remainingQuery, now).Once().Return(0.0, false, nil)
+				m.On("QueryValue", mock.Anything, burnRateQuery, now).Once().Return(0.0, false, nil)
+			},
+			expReports: []prometheus.SLOReport{
+				{SLOID: "svc1-slo1", Service: "svc1", SLOName: "slo1", Objective: f(0.999)},
+			},
+		},
+
+		"A failed query should fail.": {
+			slos: []prometheus.SLO{slo},
+			mock: func(m *prometheusmock.ValueQuerier) {
+				m.On("QueryValue", mock.Anything, objectiveQuery, now).Once().Return(0.0, false, fmt.Errorf("something"))
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			m := &prometheusmock.ValueQuerier{}
+			test.mock(m)
+
+			gotReports, err := prometheus.ReportSLOs(context.TODO(), m, test.slos, now)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expReports, gotReports)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+// promTestValueQuerier executes the queries with the Prometheus engine of a PromQL test.
+type promTestValueQuerier struct {
+	test *promql.Test
+}
+
+func (p promTestValueQuerier) QueryValue(ctx context.Context, query string, ts time.Time) (float64, bool, error) {
+	q, err := p.test.QueryEngine().NewInstantQuery(p.test.Queryable(), query, ts)
+	if err != nil {
+		return 0, false, err
+	}
+	defer q.Close()
+
+	res := q.Exec(ctx)
+	if res.Err != nil {
+		return 0, false, res.Err
+	}
+
+	v, err := res.Vector()
+	if err != nil {
+		return 0, false, err
+	}
+	switch len(v) {
+	case 0:
+		return 0, false, nil
+	case 1:
+		return v[0].V, true, nil
+	}
+
+	return 0, false, fmt.Errorf("query returned %d series instead of one", len(v))
+}
+
+func TestReportSLOsEvaluation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := func(v float64) *float64 { return &v }
+
+	// An SLO with an SLI per cluster.
+	promTest, err := promql.NewTest(t, `
+load 1m
+  slo:objective:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="a"} 0.99
+  slo:objective:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="b"} 0.99
+  slo:error_budget:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="a"} 0.01
+  slo:error_budget:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="b"} 0.01
+  slo:period_burn_rate:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="a"} 0.5
+  slo:period_burn_rate:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="b"} 0.25
+  slo:period_error_budget_remaining:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="a"} 0.5
+  slo:period_error_budget_remaining:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="b"} 0.75
+  slo:current_burn_rate:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="a"} 1
+  slo:current_burn_rate:ratio{sloth_id="svc1-slo1", sloth_service="svc1", sloth_slo="slo1", cluster="b"} 2
+`)
+	require.NoError(err)
+	defer promTest.Close()
+	require.NoError(promTest.Run())
+
+	slos := []prometheus.SLO{{ID: "svc1-slo1", Service: "svc1", Name: "slo1"}}
+	gotReports, err := prometheus.ReportSLOs(context.TODO(), promTestValueQuerier{test: promTest}, slos, time.Unix(0, 0))
+	require.NoError(err)
+
+	expReports := []prometheus.SLOReport{
+		{
+			SLOID:                "svc1-slo1",
+			Service:              "svc1",
+			SLOName:              "slo1",
+			Objective:            f(0.99),
+			SLI:                  f(0.995),
+			ErrorBudgetRemaining: f(0.5),
+			BurnRate:             f(2),
+		},
+	}
+	assert.Equal(expReports, gotReports)
+}
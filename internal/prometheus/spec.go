@@ -5,50 +5,166 @@ import (
 	"fmt"
 	"time"
 
-	"gopkg.in/yaml.v2"
-
+	"github.com/slok/sloth/internal/alert"
 	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
 	prometheuspluginv1 "github.com/slok/sloth/pkg/prometheus/plugin/v1"
+	"github.com/slok/sloth/pkg/specloader"
 )
 
 type SLIPluginRepo interface {
 	GetSLIPlugin(ctx context.Context, id string) (*SLIPlugin, error)
 }
 
+// SLIPluginExecution is an SLI plugin execution with its options.
+type SLIPluginExecution struct {
+	ID      string
+	Options map[string]string
+}
+
+// ExecuteSLIPluginChain executes the SLI plugins in order and returns the SLI query of the
+// last plugin. Every chained plugin receives the SLI query of the previous plugin on the
+// query metadata key.
+func ExecuteSLIPluginChain(ctx context.Context, repo SLIPluginRepo, meta, labels map[string]string, chain []SLIPluginExecution) (string, error) {
+	query := ""
+	for i, e := range chain {
+		plugin, err := repo.GetSLIPlugin(ctx, e.ID)
+		if err != nil {
+			return "", fmt.Errorf("could not get plugin: %w", err)
+		}
+
+		pluginMeta := mergeLabels(meta)
+		if i > 0 {
+			pluginMeta[prometheuspluginv1.SLIPluginMetaQuery] = query
+		}
+
+		query, err = plugin.Func(ctx, pluginMeta, labels, e.Options)
+		if err != nil {
+			return "", fmt.Errorf("plugin %q execution error: %w", e.ID, err)
+		}
+	}
+
+	return query, nil
+}
+
+// DefaultSLOPeriod is the SLOs time window used when the specs don't set one.
+const DefaultSLOPeriod = 30 * 24 * time.Hour
+
+// GetSLOPeriod returns the SLOs time window of a spec SLO period, if the spec doesn't
+// set it, it will return the default SLO period.
+func GetSLOPeriod(specSLOPeriod string, defaultSLOPeriod time.Duration) (time.Duration, error) {
+	if specSLOPeriod == "" {
+		return defaultSLOPeriod, nil
+	}
+
+	period, err := ParseDuration(specSLOPeriod)
+	if err != nil {
+		return 0, fmt.Errorf("invalid SLO period %q: %w", specSLOPeriod, err)
+	}
+
+	return period, nil
+}
+
+// GetRuleGroupIntervals returns the rule group intervals of the spec intervals, the rule
+// groups that don't set their own interval use the default interval.
+func GetRuleGroupIntervals(defaultInterval, sliRecordings, metaRecordings, alerts string) (RuleGroupIntervals, error) {
+	var def time.Duration
+	var res RuleGroupIntervals
+	for _, i := range []struct {
+		name     string
+		interval string
+		dst      *time.Duration
+	}{
+		{name: "default", interval: defaultInterval, dst: &def},
+		{name: "SLI recordings", interval: sliRecordings, dst: &res.SLIRecordings},
+		{name: "metadata recordings", interval: metaRecordings, dst: &res.MetaRecordings},
+		{name: "alerts", interval: alerts, dst: &res.Alerts},
+	} {
+		if i.interval == "" {
+			continue
+		}
+
+		d, err := ParseDuration(i.interval)
+		if err != nil {
+			return RuleGroupIntervals{}, fmt.Errorf("invalid %s rule group interval %q: %w", i.name, i.interval, err)
+		}
+		*i.dst = d
+	}
+
+	return res.WithDefaults(RuleGroupIntervals{SLIRecordings: def, MetaRecordings: def, Alerts: def}), nil
+}
+
+// GetAlertWindows returns the alert windows of an SLO, the alert windows catalog windows
+// selected by the spec, or if not selected, the catalog windows of the SLO labels service
+// tier or the catalog windows of the SLO period. If there are none, it will return nil so
+// the SLO uses the default alert windows.
+func GetAlertWindows(catalog *alert.WindowsCatalog, specWindows string, labels map[string]string, sloPeriod time.Duration) (*alert.Windows, error) {
+	if specWindows != "" {
+		return catalog.Get(specWindows)
+	}
+
+	if w := catalog.GetForLabels(labels); w != nil {
+		return w, nil
+	}
+
+	return catalog.GetForSLOPeriod(sloPeriod), nil
+}
+
 // YAMLSpecLoader knows how to load YAML specs and converts them to a model.
 type YAMLSpecLoader struct {
-	pluginsRepo SLIPluginRepo
+	pluginsRepo      SLIPluginRepo
+	defaultSLOPeriod time.Duration
+	alertWindows     *alert.WindowsCatalog
+	strict           bool
+	environment      string
 }
 
 // NewYAMLSpecLoader returns a YAML spec loader.
 func NewYAMLSpecLoader(pluginsRepo SLIPluginRepo) YAMLSpecLoader {
 	return YAMLSpecLoader{
-		pluginsRepo: pluginsRepo,
+		pluginsRepo:      pluginsRepo,
+		defaultSLOPeriod: DefaultSLOPeriod,
 	}
 }
 
-func (y YAMLSpecLoader) LoadSpec(ctx context.Context, data []byte) (*SLOGroup, error) {
-	if len(data) == 0 {
-		return nil, fmt.Errorf("spec is required")
-	}
+// WithDefaultSLOPeriod returns a copy of the loader that uses the SLO period as the
+// SLOs time window of the specs without SLO period.
+func (y YAMLSpecLoader) WithDefaultSLOPeriod(period time.Duration) YAMLSpecLoader {
+	y.defaultSLOPeriod = period
+	return y
+}
 
-	s := prometheusv1.Spec{}
-	err := yaml.Unmarshal(data, &s)
-	if err != nil {
-		return nil, fmt.Errorf("could not unmarshall YAML spec correctly: %w", err)
-	}
+// WithAlertWindows returns a copy of the loader that selects the SLOs alert windows from
+// the alert windows catalog.
+func (y YAMLSpecLoader) WithAlertWindows(catalog *alert.WindowsCatalog) YAMLSpecLoader {
+	y.alertWindows = catalog
+	return y
+}
 
-	// Check version.
-	if s.Version != prometheusv1.Version {
-		return nil, fmt.Errorf("invalid spec version, should be %q", prometheusv1.Version)
-	}
+// WithStrict returns a copy of the loader that fails on the spec fields that are unknown
+// (e.g a typo'd `objetive`), instead of ignoring them.
+func (y YAMLSpecLoader) WithStrict(strict bool) YAMLSpecLoader {
+	y.strict = strict
+	return y
+}
+
+// WithEnvironment returns a copy of the loader that applies the SLO overrides of the
+// environment.
+func (y YAMLSpecLoader) WithEnvironment(env string) YAMLSpecLoader {
+	y.environment = env
+	return y
+}
 
-	// Check at least we have one SLO.
-	if len(s.SLOs) == 0 {
-		return nil, fmt.Errorf("at least one SLO is required")
+func (y YAMLSpecLoader) LoadSpec(ctx context.Context, data []byte) (*SLOGroup, error) {
+	load := specloader.LoadPrometheusV1
+	if y.strict {
+		load = specloader.LoadPrometheusV1Strict
+	}
+	s, err := load(data)
+	if err != nil {
+		return nil, err
 	}
 
-	m, err := y.mapSpecToModel(ctx, s)
+	m, err := y.mapSpecToModel(ctx, *s)
 	if err != nil {
 		return nil, fmt.Errorf("could not map to model: %w", err)
 	}
@@ -57,21 +173,76 @@ func (y YAMLSpecLoader) LoadSpec(ctx context.Context, data []byte) (*SLOGroup, e
 }
 
 func (y YAMLSpecLoader) mapSpecToModel(ctx context.Context, spec prometheusv1.Spec) (*SLOGroup, error) {
+	timeWindow, err := GetSLOPeriod(spec.SLOPeriod, y.defaultSLOPeriod)
+	if err != nil {
+		return nil, err
+	}
+
 	models := make([]SLO, 0, len(spec.SLOs))
 	for _, specSLO := range spec.SLOs {
+		var costLabels map[string]string
+		if spec.Cost != nil {
+			costLabels = spec.Cost.Labels
+		}
+		var defaultAnnotations map[string]string
+		if spec.AlertingDefaults != nil {
+			defaultAnnotations = spec.AlertingDefaults.Annotations
+		}
+
+		// Set environment overrides.
+		objective, sloTimeWindow := specSLO.Objective, timeWindow
+		if env, ok := specSLO.Environments[y.environment]; ok && y.environment != "" {
+			if env.Objective != 0 {
+				objective = env.Objective
+			}
+			sloTimeWindow, err = GetSLOPeriod(env.SLOPeriod, timeWindow)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q environment: %w", y.environment, err)
+			}
+		}
+
 		slo := SLO{
 			ID:              fmt.Sprintf("%s-%s", spec.Service, specSLO.Name),
 			Name:            specSLO.Name,
 			Description:     specSLO.Description,
 			Service:         spec.Service,
-			TimeWindow:      30 * 24 * time.Hour, // Default and for now the only one supported.
-			Objective:       specSLO.Objective,
-			Labels:          mergeLabels(spec.Labels, specSLO.Labels),
+			TimeWindow:      sloTimeWindow,
+			Objective:       objective,
+			Labels:          mergeLabels(spec.Labels, specSLO.Labels, costLabels),
+			Annotations:     mergeLabels(defaultAnnotations, spec.Annotations, specSLO.Annotations),
+			CostLabels:      costLabels,
+			GroupLabels:     specSLO.GroupLabels,
 			PageAlertMeta:   AlertMeta{Disable: true},
 			TicketAlertMeta: AlertMeta{Disable: true},
+			DependsOn:       specSLO.Alerting.DependsOn,
+			AlertGuard:      specSLO.Alerting.Guard,
+		}
+
+		// Set rule group intervals.
+		if i := specSLO.RuleGroupIntervals; i != nil {
+			intervals, err := GetRuleGroupIntervals(i.Default, i.SLIRecordings, i.MetaRecordings, i.Alerts)
+			if err != nil {
+				return nil, err
+			}
+			slo.RuleGroupIntervals = intervals
+		}
+
+		// Set alert windows.
+		alertWindows, err := GetAlertWindows(y.alertWindows, specSLO.Alerting.Windows, slo.Labels, sloTimeWindow)
+		if err != nil {
+			return nil, err
 		}
+		slo.AlertWindows = alertWindows
 
 		// Set SLIs.
+		if specSLO.SLI.Offset != "" {
+			offset, err := ParseDuration(specSLO.SLI.Offset)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SLI offset %q: %w", specSLO.SLI.Offset, err)
+			}
+			slo.SLI.Offset = offset
+		}
+
 		if specSLO.SLI.Events != nil {
 			slo.SLI.Events = &SLIEvents{
 				ErrorQuery: specSLO.SLI.Events.ErrorQuery,
@@ -85,21 +256,36 @@ func (y YAMLSpecLoader) mapSpecToModel(ctx context.Context, spec prometheusv1.Sp
 			}
 		}
 
-		if specSLO.SLI.Plugin != nil {
-			plugin, err := y.pluginsRepo.GetSLIPlugin(ctx, specSLO.SLI.Plugin.ID)
+		if specSLO.SLI.Latency != nil {
+			if slo.SLI.Events != nil {
+				return nil, fmt.Errorf("only one SLI type can be set")
+			}
+			threshold, err := ParseDuration(specSLO.SLI.Latency.Threshold)
 			if err != nil {
-				return nil, fmt.Errorf("could not get plugin: %w", err)
+				return nil, fmt.Errorf("invalid latency SLI threshold %q: %w", specSLO.SLI.Latency.Threshold, err)
 			}
+			events, err := LatencySLIEvents(specSLO.SLI.Latency.HistogramMetric, threshold, specSLO.SLI.Latency.Selector, specSLO.SLI.Latency.NativeHistogram)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency SLI: %w", err)
+			}
+			slo.SLI.Events = events
+		}
 
+		if specSLO.SLI.Plugin != nil {
 			meta := map[string]string{
 				prometheuspluginv1.SLIPluginMetaService:   spec.Service,
 				prometheuspluginv1.SLIPluginMetaSLO:       specSLO.Name,
-				prometheuspluginv1.SLIPluginMetaObjective: fmt.Sprintf("%f", specSLO.Objective),
+				prometheuspluginv1.SLIPluginMetaObjective: fmt.Sprintf("%f", objective),
+			}
+
+			chain := []SLIPluginExecution{{ID: specSLO.SLI.Plugin.ID, Options: specSLO.SLI.Plugin.Options}}
+			for _, p := range specSLO.SLI.Plugin.Chain {
+				chain = append(chain, SLIPluginExecution{ID: p.ID, Options: p.Options})
 			}
 
-			rawQuery, err := plugin.Func(ctx, meta, spec.Labels, specSLO.SLI.Plugin.Options)
+			rawQuery, err := ExecuteSLIPluginChain(ctx, y.pluginsRepo, meta, spec.Labels, chain)
 			if err != nil {
-				return nil, fmt.Errorf("plugin %q execution error: %w", specSLO.SLI.Plugin.ID, err)
+				return nil, err
 			}
 
 			slo.SLI.Raw = &SLIRaw{
@@ -107,6 +293,14 @@ func (y YAMLSpecLoader) mapSpecToModel(ctx context.Context, spec prometheusv1.Sp
 			}
 		}
 
+		for _, e := range specSLO.SLI.Examples {
+			slo.SLI.Examples = append(slo.SLI.Examples, SLIExample{
+				Name:       e.Name,
+				Series:     e.Series,
+				ErrorRatio: e.ErrorRatio,
+			})
+		}
+
 		// Set alerts.
 		if !specSLO.Alerting.PageAlert.Disable {
 			slo.PageAlertMeta = AlertMeta{
@@ -114,6 +308,13 @@ func (y YAMLSpecLoader) mapSpecToModel(ctx context.Context, spec prometheusv1.Sp
 				Labels:      mergeLabels(specSLO.Alerting.Labels, specSLO.Alerting.PageAlert.Labels),
 				Annotations: mergeLabels(specSLO.Alerting.Annotations, specSLO.Alerting.PageAlert.Annotations),
 			}
+			if specSLO.Alerting.PageAlert.For != "" {
+				alertFor, err := ParseDuration(specSLO.Alerting.PageAlert.For)
+				if err != nil {
+					return nil, fmt.Errorf("invalid page alert for %q: %w", specSLO.Alerting.PageAlert.For, err)
+				}
+				slo.PageAlertMeta.For = alertFor
+			}
 		}
 
 		if !specSLO.Alerting.TicketAlert.Disable {
@@ -122,6 +323,65 @@ func (y YAMLSpecLoader) mapSpecToModel(ctx context.Context, spec prometheusv1.Sp
 				Labels:      mergeLabels(specSLO.Alerting.Labels, specSLO.Alerting.TicketAlert.Labels),
 				Annotations: mergeLabels(specSLO.Alerting.Annotations, specSLO.Alerting.TicketAlert.Annotations),
 			}
+			if specSLO.Alerting.TicketAlert.For != "" {
+				alertFor, err := ParseDuration(specSLO.Alerting.TicketAlert.For)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ticket alert for %q: %w", specSLO.Alerting.TicketAlert.For, err)
+				}
+				slo.TicketAlertMeta.For = alertFor
+			}
+		}
+
+		// Set transition.
+		if specSLO.Transition != nil {
+			slo.Transition = &SLOTransition{
+				PreviousObjective:  slo.Objective,
+				PreviousTimeWindow: slo.TimeWindow,
+				ChangedAt:          specSLO.Transition.ChangedAt,
+			}
+			if specSLO.Transition.PreviousObjective != 0 {
+				slo.Transition.PreviousObjective = specSLO.Transition.PreviousObjective
+			}
+			if specSLO.Transition.PreviousTimeWindow != "" {
+				window, err := ParseDuration(specSLO.Transition.PreviousTimeWindow)
+				if err != nil {
+					return nil, fmt.Errorf("invalid transition previous time window %q: %w", specSLO.Transition.PreviousTimeWindow, err)
+				}
+				slo.Transition.PreviousTimeWindow = window
+			}
+		}
+
+		// Set reporting windows.
+		for _, w := range specSLO.ReportingWindows {
+			window, err := ParseDuration(w)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reporting window %q: %w", w, err)
+			}
+			slo.ReportingWindows = append(slo.ReportingWindows, window)
+		}
+
+		// Set timeslice.
+		if specSLO.Timeslice != nil {
+			window, err := ParseDuration(specSLO.Timeslice.Window)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeslice window %q: %w", specSLO.Timeslice.Window, err)
+			}
+			slo.Timeslice = &SLOTimeslice{
+				Window:              window,
+				ErrorRatioThreshold: specSLO.Timeslice.ErrorRatioThreshold,
+			}
+		}
+
+		// Set maintenance windows.
+		for _, w := range specSLO.Alerting.MaintenanceWindows {
+			slo.MaintenanceWindows = append(slo.MaintenanceWindows, MaintenanceWindow{
+				Name:        w.Name,
+				Times:       w.Times,
+				Weekdays:    w.Weekdays,
+				DaysOfMonth: w.DaysOfMonth,
+				Months:      w.Months,
+				Location:    w.Location,
+			})
 		}
 
 		models = append(models, slo)
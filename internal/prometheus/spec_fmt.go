@@ -0,0 +1,270 @@
+package prometheus
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// SpecFmtChange is a human-friendly SLO spec value that has been canonicalized.
+type SpecFmtChange struct {
+	// Line is the file line number of the value.
+	Line int
+	// Path is the key path of the value (e.g `slos.-.objective`, where `-` is a list item).
+	Path     string
+	OldValue string
+	NewValue string
+}
+
+type specFmtValueKind int
+
+const (
+	specFmtValueDuration specFmtValueKind = iota
+	specFmtValueObjective
+)
+
+// prometheusV1SpecFmtValues are the `prometheus/v1` spec key paths that accept human-friendly values,
+// `*` matches any map key.
+var prometheusV1SpecFmtValues = map[string]specFmtValueKind{
+	"slo_period":                                  specFmtValueDuration,
+	"slos.-.objective":                            specFmtValueObjective,
+	"slos.-.rule_group_intervals.default":         specFmtValueDuration,
+	"slos.-.rule_group_intervals.sli_recordings":  specFmtValueDuration,
+	"slos.-.rule_group_intervals.meta_recordings": specFmtValueDuration,
+	"slos.-.rule_group_intervals.alerts":          specFmtValueDuration,
+	"slos.-.sli.offset":                           specFmtValueDuration,
+	"slos.-.sli.latency.threshold":                specFmtValueDuration,
+	"slos.-.alerting.page_alert.for":              specFmtValueDuration,
+	"slos.-.alerting.ticket_alert.for":            specFmtValueDuration,
+	"slos.-.transition.previous_objective":        specFmtValueObjective,
+	"slos.-.transition.previous_time_window":      specFmtValueDuration,
+	"slos.-.timeslice.window":                     specFmtValueDuration,
+	"slos.-.environments.*.objective":             specFmtValueObjective,
+	"slos.-.environments.*.slo_period":            specFmtValueDuration,
+}
+
+// k8sPrometheusServiceLevelFmtValues are the Kubernetes `PrometheusServiceLevel` spec key paths that accept
+// human-friendly values, `*` matches any map key.
+var k8sPrometheusServiceLevelFmtValues = map[string]specFmtValueKind{
+	"spec.sloPeriod":                                specFmtValueDuration,
+	"spec.slos.-.ruleGroupIntervals.default":        specFmtValueDuration,
+	"spec.slos.-.ruleGroupIntervals.sliRecordings":  specFmtValueDuration,
+	"spec.slos.-.ruleGroupIntervals.metaRecordings": specFmtValueDuration,
+	"spec.slos.-.ruleGroupIntervals.alerts":         specFmtValueDuration,
+	"spec.slos.-.sli.offset":                        specFmtValueDuration,
+	"spec.slos.-.sli.latency.threshold":             specFmtValueDuration,
+	"spec.slos.-.alerting.pageAlert.for":            specFmtValueDuration,
+	"spec.slos.-.alerting.ticketAlert.for":          specFmtValueDuration,
+	"spec.slos.-.transition.previousTimeWindow":     specFmtValueDuration,
+	"spec.slos.-.timeslice.window":                  specFmtValueDuration,
+	"spec.slos.-.environments.*.sloPeriod":          specFmtValueDuration,
+}
+
+// FmtSpecYAML canonicalizes the human-friendly values of the `prometheus/v1` and Kubernetes
+// `PrometheusServiceLevel` specs of YAML (multi-document) data, the durations (e.g `30 days`
+// to `30d`) and the objectives (e.g `three nines` or `99.9%` to `99.9`). Like the rename, the
+// values are replaced on the YAML lines in place, so the rest of the file (comments, format...)
+// is kept. The invalid values are not changed, these are reported by the validation.
+func FmtSpecYAML(data []byte) ([]byte, []SpecFmtChange, error) {
+	lines := strings.Split(string(data), "\n")
+	changes := []SpecFmtChange{}
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !yamlDocSeparatorRegexp.MatchString(strings.TrimPrefix(lines[i], string(utf8BOM))) {
+			continue
+		}
+
+		docChanges, err := fmtSpecYAMLDoc(lines[start:i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not format YAML document at line %d: %w", start+1, err)
+		}
+		for _, c := range docChanges {
+			c.Line += start
+			changes = append(changes, c)
+		}
+		start = i + 1
+	}
+
+	return []byte(strings.Join(lines, "\n")), changes, nil
+}
+
+// fmtSpecYAMLDoc canonicalizes the lines of a single YAML document in place.
+func fmtSpecYAMLDoc(lines []string) ([]SpecFmtChange, error) {
+	var doc map[interface{}]interface{}
+	err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal YAML: %w", err)
+	}
+
+	var values map[string]specFmtValueKind
+	switch {
+	case doc["version"] == prometheusv1.Version:
+		values = prometheusV1SpecFmtValues
+	case doc["kind"] == k8sPrometheusServiceLevelKind:
+		values = k8sPrometheusServiceLevelFmtValues
+	default:
+		return nil, nil
+	}
+
+	// Canonicalize the expected document so we can check the result of the lines canonicalization.
+	err = fmtYAMLNode(doc, "", values)
+	if err != nil {
+		return nil, err
+	}
+
+	// Canonicalize the lines.
+	original := make([]string, len(lines))
+	copy(original, lines)
+	changes := []SpecFmtChange{}
+	walkYAMLKeyLines(lines, func(path, key, value string) (string, string, bool) {
+		kind, ok := matchSpecFmtValue(values, path)
+		if !ok {
+			return "", "", false
+		}
+
+		quote, scalar, rest, ok := splitYAMLScalar(value)
+		if !ok {
+			return "", "", false
+		}
+		newScalar, newQuote, ok := fmtSpecValue(kind, scalar, quote)
+		if !ok {
+			return "", "", false
+		}
+
+		changes = append(changes, SpecFmtChange{Path: path, OldValue: scalar, NewValue: newScalar})
+		return key, newQuote + newScalar + newQuote + rest, true
+	})
+
+	// The lines are walked in order, so the changed lines match the changes order.
+	c := 0
+	for i := range lines {
+		if lines[i] != original[i] {
+			changes[c].Line = i + 1
+			c++
+		}
+	}
+
+	// Check the lines canonicalization result is the expected one.
+	var got map[interface{}]interface{}
+	err = yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &got)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal formatted YAML: %w", err)
+	}
+	if !reflect.DeepEqual(doc, got) {
+		return nil, fmt.Errorf("could not format safely, the YAML format is not supported (e.g flow style)")
+	}
+
+	return changes, nil
+}
+
+// fmtYAMLNode canonicalizes in place the values of an unmarshaled YAML node that are on the
+// value key paths.
+func fmtYAMLNode(node interface{}, path string, values map[string]specFmtValueKind) error {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range n {
+			key, ok := k.(string)
+			if !ok {
+				continue
+			}
+			p := key
+			if path != "" {
+				p = path + "." + key
+			}
+
+			kind, ok := matchSpecFmtValue(values, p)
+			s, isString := v.(string)
+			if !ok || !isString {
+				err := fmtYAMLNode(v, p, values)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			newScalar, newQuote, ok := fmtSpecValue(kind, s, `"`)
+			if !ok {
+				continue
+			}
+			var newValue interface{}
+			err := yaml.Unmarshal([]byte(newQuote+newScalar+newQuote), &newValue)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal %q formatted value: %w", p, err)
+			}
+			n[k] = newValue
+		}
+
+	case []interface{}:
+		for _, v := range n {
+			err := fmtYAMLNode(v, path+".-", values)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchSpecFmtValue returns the kind of the value on the key path.
+func matchSpecFmtValue(values map[string]specFmtValueKind, path string) (specFmtValueKind, bool) {
+	if kind, ok := values[path]; ok {
+		return kind, true
+	}
+
+	pathParts := strings.Split(path, ".")
+	for pattern, kind := range values {
+		patternParts := strings.Split(pattern, ".")
+		if len(patternParts) != len(pathParts) {
+			continue
+		}
+
+		match := true
+		for i := range patternParts {
+			if patternParts[i] != "*" && patternParts[i] != pathParts[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return kind, true
+		}
+	}
+
+	return 0, false
+}
+
+// fmtSpecValue returns the canonical scalar of a human-friendly spec value and its quote, if
+// the value is not canonical already. The quoted values are strings on YAML, so these
+// are not canonical objectives.
+func fmtSpecValue(kind specFmtValueKind, scalar, quote string) (newScalar, newQuote string, ok bool) {
+	switch kind {
+	case specFmtValueDuration:
+		if _, err := prommodel.ParseDuration(scalar); err == nil {
+			return "", "", false
+		}
+		d, err := ParseDuration(scalar)
+		if err != nil {
+			return "", "", false
+		}
+		return prommodel.Duration(d).String(), quote, true
+
+	case specFmtValueObjective:
+		if _, err := strconv.ParseFloat(scalar, 64); err == nil && quote == "" {
+			return "", "", false
+		}
+		objective, err := prometheusv1.ParseObjective(scalar)
+		if err != nil {
+			return "", "", false
+		}
+		return strconv.FormatFloat(objective, 'f', -1, 64), "", true
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,194 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestFmtSpecYAML(t *testing.T) {
+	tests := map[string]struct {
+		data       string
+		expData    string
+		expChanges []prometheus.SpecFmtChange
+		expErr     bool
+	}{
+		"Having canonical values should not change anything.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+slo_period: 30d
+slos:
+  - name: "slo1"
+    objective: 99.9
+    sli:
+      offset: "5m"
+`,
+			expData: `
+version: "prometheus/v1"
+service: "svc01"
+slo_period: 30d
+slos:
+  - name: "slo1"
+    objective: 99.9
+    sli:
+      offset: "5m"
+`,
+			expChanges: []prometheus.SpecFmtChange{},
+		},
+
+		"Having human-friendly values should canonicalize them keeping the rest of the file.": {
+			data: `# The service SLOs.
+version: "prometheus/v1"
+service: "svc01"
+slo_period: 30 days # Monthly.
+labels:
+  offset: 5 minutes
+slos:
+  - name: "slo1"
+    objective: "three nines"
+    sli:
+      offset: "5 minutes"
+    alerting:
+      page_alert:
+        for: 1 hour 30 minutes
+    environments:
+      staging:
+        objective: 99.5%
+  - name: "slo2"
+    objective: '99.9'
+    transition:
+      previous_objective: 2 nines
+      previous_time_window: 4 weeks
+`,
+			expData: `# The service SLOs.
+version: "prometheus/v1"
+service: "svc01"
+slo_period: 30d # Monthly.
+labels:
+  offset: 5 minutes
+slos:
+  - name: "slo1"
+    objective: 99.9
+    sli:
+      offset: "5m"
+    alerting:
+      page_alert:
+        for: 1h30m
+    environments:
+      staging:
+        objective: 99.5
+  - name: "slo2"
+    objective: 99.9
+    transition:
+      previous_objective: 99
+      previous_time_window: 4w
+`,
+			expChanges: []prometheus.SpecFmtChange{
+				{Line: 4, Path: "slo_period", OldValue: "30 days", NewValue: "30d"},
+				{Line: 9, Path: "slos.-.objective", OldValue: "three nines", NewValue: "99.9"},
+				{Line: 11, Path: "slos.-.sli.offset", OldValue: "5 minutes", NewValue: "5m"},
+				{Line: 14, Path: "slos.-.alerting.page_alert.for", OldValue: "1 hour 30 minutes", NewValue: "1h30m"},
+				{Line: 17, Path: "slos.-.environments.staging.objective", OldValue: "99.5%", NewValue: "99.5"},
+				{Line: 19, Path: "slos.-.objective", OldValue: "99.9", NewValue: "99.9"},
+				{Line: 21, Path: "slos.-.transition.previous_objective", OldValue: "2 nines", NewValue: "99"},
+				{Line: 22, Path: "slos.-.transition.previous_time_window", OldValue: "4 weeks", NewValue: "4w"},
+			},
+		},
+
+		"Having human-friendly values on multiple documents should canonicalize the specs only.": {
+			data: `apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+spec:
+  service: "svc01"
+  sloPeriod: "7 days"
+  slos:
+    - name: "slo1"
+      objective: 99.9
+      sli:
+        latency:
+          threshold: 2 seconds
+---
+apiVersion: v1
+kind: ConfigMap
+data:
+  sloPeriod: "7 days"
+`,
+			expData: `apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+spec:
+  service: "svc01"
+  sloPeriod: "1w"
+  slos:
+    - name: "slo1"
+      objective: 99.9
+      sli:
+        latency:
+          threshold: 2s
+---
+apiVersion: v1
+kind: ConfigMap
+data:
+  sloPeriod: "7 days"
+`,
+			expChanges: []prometheus.SpecFmtChange{
+				{Line: 5, Path: "spec.sloPeriod", OldValue: "7 days", NewValue: "1w"},
+				{Line: 11, Path: "spec.slos.-.sli.latency.threshold", OldValue: "2 seconds", NewValue: "2s"},
+			},
+		},
+
+		"Having invalid values should not change them.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+slo_period: 30 potatoes
+slos:
+  - name: "slo1"
+    objective: "lots of nines"
+`,
+			expData: `
+version: "prometheus/v1"
+service: "svc01"
+slo_period: 30 potatoes
+slos:
+  - name: "slo1"
+    objective: "lots of nines"
+`,
+			expChanges: []prometheus.SpecFmtChange{},
+		},
+
+		"Having a not supported YAML format should fail.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+slos: [{name: "slo1", objective: "three nines"}]
+`,
+			expErr: true,
+		},
+
+		"Having an invalid YAML should fail.": {
+			data: `
+version: "prometheus/v1"
+service: [
+`,
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotData, gotChanges, err := prometheus.FmtSpecYAML([]byte(test.data))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expData, string(gotData))
+				assert.Equal(test.expChanges, gotChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package prometheus
+
+import (
+	"fmt"
+	"reflect"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// MergeSpecs merges multiple Prometheus specs of the same service into a single
+// canonical spec.
+//
+// The service labels and annotations shared by all the specs are kept at service
+// level, the rest are moved to the SLOs of the spec that declared them, this way
+// the merged spec generates the same rules. An SLO declared multiple times is
+// merged if all the declarations are the same, otherwise it's a conflict.
+func MergeSpecs(specs []prometheusv1.Spec) (*prometheusv1.Spec, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("at least one spec is required")
+	}
+
+	// Check we can merge the specs.
+	service := specs[0].Service
+	allLabels := make([]map[string]string, 0, len(specs))
+	allAnnotations := make([]map[string]string, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Version != prometheusv1.Version {
+			return nil, fmt.Errorf("invalid spec version, should be %q", prometheusv1.Version)
+		}
+
+		if spec.Service != service {
+			return nil, fmt.Errorf("specs from different services can't be merged: %q and %q", service, spec.Service)
+		}
+
+		if spec.SLOPeriod != specs[0].SLOPeriod {
+			return nil, fmt.Errorf("specs with different SLO periods can't be merged: %q and %q", specs[0].SLOPeriod, spec.SLOPeriod)
+		}
+
+		if !reflect.DeepEqual(spec.Cost, specs[0].Cost) {
+			return nil, fmt.Errorf("specs with different cost attribution can't be merged")
+		}
+
+		if !reflect.DeepEqual(spec.AlertingDefaults, specs[0].AlertingDefaults) {
+			return nil, fmt.Errorf("specs with different alerting defaults can't be merged")
+		}
+
+		allLabels = append(allLabels, spec.Labels)
+		allAnnotations = append(allAnnotations, spec.Annotations)
+	}
+
+	merged := &prometheusv1.Spec{
+		Version:          prometheusv1.Version,
+		Service:          service,
+		SLOPeriod:        specs[0].SLOPeriod,
+		Labels:           commonMapEntries(allLabels),
+		Annotations:      commonMapEntries(allAnnotations),
+		Cost:             specs[0].Cost,
+		AlertingDefaults: specs[0].AlertingDefaults,
+	}
+
+	// Merge SLOs.
+	mergedSLOs := map[string]prometheusv1.SLO{}
+	for _, spec := range specs {
+		for _, slo := range spec.SLOs {
+			// Move the service level data that is not common to the SLO level.
+			slo.Labels = emptyMapToNil(mergeLabels(mapEntriesDiff(spec.Labels, merged.Labels), slo.Labels))
+			slo.Annotations = emptyMapToNil(mergeLabels(mapEntriesDiff(spec.Annotations, merged.Annotations), slo.Annotations))
+
+			current, ok := mergedSLOs[slo.Name]
+			if !ok {
+				mergedSLOs[slo.Name] = slo
+				merged.SLOs = append(merged.SLOs, slo)
+				continue
+			}
+
+			if current.Objective != slo.Objective {
+				return nil, fmt.Errorf("%q SLO is declared with conflicting objectives: %v and %v", slo.Name, current.Objective, slo.Objective)
+			}
+
+			if !reflect.DeepEqual(current, slo) {
+				return nil, fmt.Errorf("%q SLO is declared multiple times with conflicting definitions", slo.Name)
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// commonMapEntries returns the entries that are present with the same value on all the maps.
+func commonMapEntries(ms []map[string]string) map[string]string {
+	if len(ms) == 0 {
+		return nil
+	}
+
+	res := map[string]string{}
+	for k, v := range ms[0] {
+		common := true
+		for _, m := range ms[1:] {
+			if mv, ok := m[k]; !ok || mv != v {
+				common = false
+				break
+			}
+		}
+
+		if common {
+			res[k] = v
+		}
+	}
+
+	return emptyMapToNil(res)
+}
+
+// mapEntriesDiff returns the entries of `m` that are not in `exclude`.
+func mapEntriesDiff(m, exclude map[string]string) map[string]string {
+	res := map[string]string{}
+	for k, v := range m {
+		if _, ok := exclude[k]; !ok {
+			res[k] = v
+		}
+	}
+
+	return res
+}
+
+func emptyMapToNil(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	return m
+}
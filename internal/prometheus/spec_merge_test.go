@@ -0,0 +1,189 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+func getMergeSpecSLO(name string, objective float64) prometheusv1.SLO {
+	return prometheusv1.SLO{
+		Name:      name,
+		Objective: objective,
+		SLI: prometheusv1.SLI{
+			Raw: &prometheusv1.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+		},
+		Alerting: prometheusv1.Alerting{
+			PageAlert:   prometheusv1.Alert{Disable: true},
+			TicketAlert: prometheusv1.Alert{Disable: true},
+		},
+	}
+}
+
+func TestMergeSpecs(t *testing.T) {
+	tests := map[string]struct {
+		specs   func() []prometheusv1.Spec
+		expSpec func() *prometheusv1.Spec
+		expErr  bool
+	}{
+		"Having no specs should fail.": {
+			specs:  func() []prometheusv1.Spec { return nil },
+			expErr: true,
+		},
+
+		"Having specs with an invalid version should fail.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: "prometheus/v0", Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+				}
+			},
+			expErr: true,
+		},
+
+		"Having specs of different services should fail.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc2", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo2", 99)}},
+				}
+			},
+			expErr: true,
+		},
+
+		"Having specs with different SLO periods should fail.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", SLOPeriod: "7d", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo2", 99)}},
+				}
+			},
+			expErr: true,
+		},
+
+		"Having specs with the same SLO period should keep the SLO period.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", SLOPeriod: "7d", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc1", SLOPeriod: "7d", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo2", 99)}},
+				}
+			},
+			expSpec: func() *prometheusv1.Spec {
+				return &prometheusv1.Spec{
+					Version:   prometheusv1.Version,
+					Service:   "svc1",
+					SLOPeriod: "7d",
+					SLOs:      []prometheusv1.SLO{getMergeSpecSLO("slo1", 99), getMergeSpecSLO("slo2", 99)},
+				}
+			},
+		},
+
+		"Having specs with different cost attribution should fail.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", Cost: &prometheusv1.Cost{Labels: map[string]string{"cost_center": "cc-1"}}, SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo2", 99)}},
+				}
+			},
+			expErr: true,
+		},
+
+		"Having specs with different alerting defaults should fail.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", AlertingDefaults: &prometheusv1.AlertingDefaults{Annotations: map[string]string{"runbook": "r1"}}, SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo2", 99)}},
+				}
+			},
+			expErr: true,
+		},
+
+		"Having the same SLO with different objectives should fail.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99.9)}},
+				}
+			},
+			expErr: true,
+		},
+
+		"Having the same SLO with different definitions should fail.": {
+			specs: func() []prometheusv1.Spec {
+				slo := getMergeSpecSLO("slo1", 99)
+				slo.Description = "Something."
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{slo}},
+				}
+			},
+			expErr: true,
+		},
+
+		"Having the same SLO with the same definition should merge them.": {
+			specs: func() []prometheusv1.Spec {
+				return []prometheusv1.Spec{
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)}},
+					{Version: prometheusv1.Version, Service: "svc1", SLOs: []prometheusv1.SLO{getMergeSpecSLO("slo1", 99), getMergeSpecSLO("slo2", 99.9)}},
+				}
+			},
+			expSpec: func() *prometheusv1.Spec {
+				return &prometheusv1.Spec{
+					Version: prometheusv1.Version,
+					Service: "svc1",
+					SLOs:    []prometheusv1.SLO{getMergeSpecSLO("slo1", 99), getMergeSpecSLO("slo2", 99.9)},
+				}
+			},
+		},
+
+		"Having specs with service labels and annotations, should keep the common ones on the service and move the rest to the SLOs.": {
+			specs: func() []prometheusv1.Spec {
+				slo2 := getMergeSpecSLO("slo2", 99.9)
+				slo2.Labels = map[string]string{"tier": "2"}
+				return []prometheusv1.Spec{
+					{
+						Version:     prometheusv1.Version,
+						Service:     "svc1",
+						Labels:      map[string]string{"owner": "team1", "tier": "1"},
+						Annotations: map[string]string{"runbook": "http://runbook.com"},
+						SLOs:        []prometheusv1.SLO{getMergeSpecSLO("slo1", 99)},
+					},
+					{
+						Version: prometheusv1.Version,
+						Service: "svc1",
+						Labels:  map[string]string{"owner": "team1", "tier": "1"},
+						SLOs:    []prometheusv1.SLO{slo2},
+					},
+				}
+			},
+			expSpec: func() *prometheusv1.Spec {
+				slo1 := getMergeSpecSLO("slo1", 99)
+				slo1.Annotations = map[string]string{"runbook": "http://runbook.com"}
+				slo2 := getMergeSpecSLO("slo2", 99.9)
+				slo2.Labels = map[string]string{"tier": "2"}
+				return &prometheusv1.Spec{
+					Version: prometheusv1.Version,
+					Service: "svc1",
+					Labels:  map[string]string{"owner": "team1", "tier": "1"},
+					SLOs:    []prometheusv1.SLO{slo1, slo2},
+				}
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotSpec, err := prometheus.MergeSpecs(test.specs())
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expSpec(), gotSpec)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package prometheus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"gopkg.in/yaml.v2"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// SpecRelabel is the rename of a label of the SLO specs (e.g `team` to `owner`).
+type SpecRelabel struct {
+	// Label is the current label name.
+	Label string
+	// NewLabel is the new label name.
+	NewLabel string
+}
+
+// Validate validates the relabel.
+func (s SpecRelabel) Validate() error {
+	if s.Label == "" || s.NewLabel == "" {
+		return fmt.Errorf("label and new label names are required")
+	}
+
+	if s.Label == s.NewLabel {
+		return fmt.Errorf("label and new label names can't be the same")
+	}
+
+	for _, name := range []string{s.Label, s.NewLabel} {
+		if !prommodel.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if _, ok := reservedLabelNames[name]; ok {
+			return fmt.Errorf("%q label is reserved by Sloth", name)
+		}
+	}
+
+	return nil
+}
+
+// RelabeledSLO is an SLO affected by a label rename.
+type RelabeledSLO struct {
+	Service string
+	Name    string
+	// SeriesLabel is true when the label is set on the SLO recording rules series (spec,
+	// SLO and cost labels).
+	SeriesLabel bool
+	// AlertLabel is true when the label is set on the SLO alerting labels.
+	AlertLabel bool
+}
+
+// ID returns the SLO ID.
+func (r RelabeledSLO) ID() string { return fmt.Sprintf("%s-%s", r.Service, r.Name) }
+
+// relabelSpecPaths are the spec label maps that are relabeled, relative to the spec root.
+var relabelSpecPaths = struct {
+	series []string
+	slos   []string
+}{
+	series: []string{"labels", "cost.labels"},
+	slos: []string{
+		"labels",
+		"alerting.labels",
+		"alerting.page_alert.labels",
+		"alerting.ticket_alert.labels",
+		"alerting.pageAlert.labels",
+		"alerting.ticketAlert.labels",
+	},
+}
+
+// RelabelSpecYAML renames a label of the `prometheus/v1` and Kubernetes `PrometheusServiceLevel`
+// specs of YAML (multi-document) data, on the spec, cost, SLO and alerting labels. Like
+// RenameSpecYAML, the rename is made on the YAML lines in place, so the rest of the file
+// (comments, format...) is kept.
+func RelabelSpecYAML(data []byte, relabel SpecRelabel) ([]byte, []RelabeledSLO, error) {
+	err := relabel.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid relabel: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	relabeled := []RelabeledSLO{}
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !yamlDocSeparatorRegexp.MatchString(strings.TrimPrefix(lines[i], string(utf8BOM))) {
+			continue
+		}
+
+		docRelabeled, err := relabelSpecYAMLDoc(lines[start:i], relabel)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not relabel YAML document at line %d: %w", start+1, err)
+		}
+		relabeled = append(relabeled, docRelabeled...)
+		start = i + 1
+	}
+
+	return []byte(strings.Join(lines, "\n")), relabeled, nil
+}
+
+// relabelSpecYAMLDoc relabels the lines of a single YAML document in place.
+func relabelSpecYAMLDoc(lines []string, relabel SpecRelabel) ([]RelabeledSLO, error) {
+	original := strings.Join(lines, "\n")
+	var doc map[interface{}]interface{}
+	err := yaml.Unmarshal([]byte(original), &doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal YAML: %w", err)
+	}
+
+	// Get the spec root based on the spec type.
+	var root map[interface{}]interface{}
+	pathPrefix := ""
+	switch {
+	case doc["version"] == prometheusv1.Version:
+		root = doc
+	case doc["kind"] == k8sPrometheusServiceLevelKind:
+		root, _ = doc["spec"].(map[interface{}]interface{})
+		pathPrefix = "spec."
+	}
+	if root == nil {
+		return nil, nil
+	}
+
+	// Relabel the expected document so we can check the result of the lines relabel.
+	specLabel := false
+	for _, path := range relabelSpecPaths.series {
+		ok, err := relabelYAMLMap(root, path, relabel)
+		if err != nil {
+			return nil, err
+		}
+		specLabel = specLabel || ok
+	}
+
+	service, _ := root["service"].(string)
+	relabeled := []RelabeledSLO{}
+	slos, _ := root["slos"].([]interface{})
+	for _, s := range slos {
+		slo, ok := s.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		r := RelabeledSLO{Service: service, SeriesLabel: specLabel}
+		r.Name, _ = slo["name"].(string)
+		for _, path := range relabelSpecPaths.slos {
+			ok, err := relabelYAMLMap(slo, path, relabel)
+			if err != nil {
+				return nil, fmt.Errorf("%q SLO: %w", r.ID(), err)
+			}
+			switch {
+			case ok && path == "labels":
+				r.SeriesLabel = true
+			case ok:
+				r.AlertLabel = true
+			}
+		}
+
+		if r.SeriesLabel || r.AlertLabel {
+			relabeled = append(relabeled, r)
+		}
+	}
+	if len(relabeled) == 0 {
+		return nil, nil
+	}
+
+	// Relabel the lines.
+	paths := map[string]struct{}{}
+	for _, path := range relabelSpecPaths.series {
+		paths[pathPrefix+path+"."+relabel.Label] = struct{}{}
+	}
+	for _, path := range relabelSpecPaths.slos {
+		paths[pathPrefix+"slos.-."+path+"."+relabel.Label] = struct{}{}
+	}
+	walkYAMLKeyLines(lines, func(path, key, value string) (string, string, bool) {
+		if _, ok := paths[path]; !ok {
+			return "", "", false
+		}
+		return relabel.NewLabel, value, true
+	})
+
+	// Check the lines relabel result is the expected one.
+	var got map[interface{}]interface{}
+	err = yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &got)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal relabeled YAML: %w", err)
+	}
+	if !reflect.DeepEqual(doc, got) {
+		return nil, fmt.Errorf("could not relabel safely, the YAML format is not supported (e.g flow style)")
+	}
+
+	return relabeled, nil
+}
+
+// relabelYAMLMap renames the label key of the YAML labels map on the path (e.g `cost.labels`),
+// it returns true if the label has been renamed.
+func relabelYAMLMap(m map[interface{}]interface{}, path string, relabel SpecRelabel) (bool, error) {
+	for _, key := range strings.Split(path, ".") {
+		m, _ = m[key].(map[interface{}]interface{})
+		if m == nil {
+			return false, nil
+		}
+	}
+
+	value, ok := m[relabel.Label]
+	if !ok {
+		return false, nil
+	}
+	if _, ok := m[relabel.NewLabel]; ok {
+		return false, fmt.Errorf("%s has both %q and %q labels", path, relabel.Label, relabel.NewLabel)
+	}
+
+	delete(m, relabel.Label)
+	m[relabel.NewLabel] = value
+
+	return true, nil
+}
+
+// GenerateRelabelBridgeRules generates the recording rules that keep recording the relabeled
+// SLO series with the old label instead of the new one, based on the relabeled SLO recording
+// rules. This way the dashboards and alert routes that use the old label keep working during
+// the migration. The bridge series don't have the SLO ID label, so they are distinct series
+// that the SLO rules and alerts (selected by the SLO ID) don't use.
+func GenerateRelabelBridgeRules(relabel SpecRelabel, sloID string, rules []rulefmt.Rule) []rulefmt.Rule {
+	bridgeRules := make([]rulefmt.Rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Record == "" {
+			continue
+		}
+
+		// Only select the new labeled series, the bridge series don't have the SLO ID and new labels.
+		expr := fmt.Sprintf(`%s{%s=%q, %s!=""}`, r.Record, sloIDLabelName, sloID, relabel.NewLabel)
+		expr = fmt.Sprintf(`max without (%s, %s) (label_replace(%s, %q, "$1", %q, "(.*)"))`, sloIDLabelName, relabel.NewLabel, expr, relabel.Label, relabel.NewLabel)
+
+		bridgeRules = append(bridgeRules, rulefmt.Rule{
+			Record: r.Record,
+			Expr:   expr,
+		})
+	}
+
+	return bridgeRules
+}
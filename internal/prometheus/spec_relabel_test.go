@@ -0,0 +1,233 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestRelabelSpecYAML(t *testing.T) {
+	tests := map[string]struct {
+		data         string
+		relabel      prometheus.SpecRelabel
+		expData      string
+		expRelabeled []prometheus.RelabeledSLO
+		expErr       bool
+	}{
+		"Having an invalid relabel should fail.": {
+			data:    `version: "prometheus/v1"`,
+			relabel: prometheus.SpecRelabel{Label: "team"},
+			expErr:  true,
+		},
+
+		"Having a relabel to a reserved label should fail.": {
+			data:    `version: "prometheus/v1"`,
+			relabel: prometheus.SpecRelabel{Label: "team", NewLabel: "sloth_service"},
+			expErr:  true,
+		},
+
+		"Having specs without the label should not relabel anything.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+labels:
+  owner: team-a
+slos:
+  - name: "slo1"
+`,
+			relabel: prometheus.SpecRelabel{Label: "team", NewLabel: "owner"},
+			expData: `
+version: "prometheus/v1"
+service: "svc01"
+labels:
+  owner: team-a
+slos:
+  - name: "slo1"
+`,
+			expRelabeled: []prometheus.RelabeledSLO{},
+		},
+
+		"Having specs with the label should relabel the labels keeping the rest of the file.": {
+			data: `# The service SLOs.
+version: "prometheus/v1"
+service: svc01
+labels:
+  team: team-a # The owner team.
+  tier: "1"
+slos:
+  - name: "slo1"
+    labels:
+      category: availability
+  - name: slo2
+    alerting:
+      name: Slo2
+      labels:
+        team: team-b
+      page_alert:
+        labels:
+          team: team-c
+---
+version: "prometheus/v1"
+service: "svc02"
+slos:
+  - name: "slo1"
+    labels:
+      team: team-a
+`,
+			relabel: prometheus.SpecRelabel{Label: "team", NewLabel: "owner"},
+			expData: `# The service SLOs.
+version: "prometheus/v1"
+service: svc01
+labels:
+  owner: team-a # The owner team.
+  tier: "1"
+slos:
+  - name: "slo1"
+    labels:
+      category: availability
+  - name: slo2
+    alerting:
+      name: Slo2
+      labels:
+        owner: team-b
+      page_alert:
+        labels:
+          owner: team-c
+---
+version: "prometheus/v1"
+service: "svc02"
+slos:
+  - name: "slo1"
+    labels:
+      owner: team-a
+`,
+			expRelabeled: []prometheus.RelabeledSLO{
+				{Service: "svc01", Name: "slo1", SeriesLabel: true},
+				{Service: "svc01", Name: "slo2", SeriesLabel: true, AlertLabel: true},
+				{Service: "svc02", Name: "slo1", SeriesLabel: true},
+			},
+		},
+
+		"Having a Kubernetes spec should relabel the labels.": {
+			data: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: svc01
+  labels:
+    team: team-a
+spec:
+  service: svc01
+  slos:
+    - name: slo1
+      alerting:
+        name: Slo1
+        ticketAlert:
+          labels:
+            team: team-a
+`,
+			relabel: prometheus.SpecRelabel{Label: "team", NewLabel: "owner"},
+			expData: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: svc01
+  labels:
+    team: team-a
+spec:
+  service: svc01
+  slos:
+    - name: slo1
+      alerting:
+        name: Slo1
+        ticketAlert:
+          labels:
+            owner: team-a
+`,
+			expRelabeled: []prometheus.RelabeledSLO{
+				{Service: "svc01", Name: "slo1", AlertLabel: true},
+			},
+		},
+
+		"Having both labels on the same labels should fail.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+labels:
+  team: team-a
+  owner: team-b
+slos:
+  - name: "slo1"
+`,
+			relabel: prometheus.SpecRelabel{Label: "team", NewLabel: "owner"},
+			expErr:  true,
+		},
+
+		"Having a not supported YAML format should fail.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+labels: {team: team-a}
+slos:
+  - name: "slo1"
+`,
+			relabel: prometheus.SpecRelabel{Label: "team", NewLabel: "owner"},
+			expErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotData, gotRelabeled, err := prometheus.RelabelSpecYAML([]byte(test.data), test.relabel)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expData, string(gotData))
+				assert.Equal(test.expRelabeled, gotRelabeled)
+			}
+		})
+	}
+}
+
+func TestGenerateRelabelBridgeRules(t *testing.T) {
+	relabel := prometheus.SpecRelabel{Label: "team", NewLabel: "owner"}
+	sliRules := []rulefmt.Rule{
+		{Record: "slo:sli_error:ratio_rate5m", Expr: "vector(1)"},
+		{Record: "slo:sli_error:ratio_rate30d", Expr: "vector(1)"},
+	}
+
+	expRules := []rulefmt.Rule{
+		{
+			Record: "slo:sli_error:ratio_rate5m",
+			Expr:   `max without (sloth_id, owner) (label_replace(slo:sli_error:ratio_rate5m{sloth_id="svc01-slo1", owner!=""}, "team", "$1", "owner", "(.*)"))`,
+		},
+		{
+			Record: "slo:sli_error:ratio_rate30d",
+			Expr:   `max without (sloth_id, owner) (label_replace(slo:sli_error:ratio_rate30d{sloth_id="svc01-slo1", owner!=""}, "team", "$1", "owner", "(.*)"))`,
+		},
+	}
+
+	gotRules := prometheus.GenerateRelabelBridgeRules(relabel, "svc01-slo1", sliRules)
+	assert.Equal(t, expRules, gotRules)
+
+	// The bridge series shouldn't have the SLO ID, so they are not selected by the SLO rules.
+	promTest, err := promql.NewTest(t, `
+load 1m
+  slo:sli_error:ratio_rate5m{sloth_id="svc01-slo1", sloth_service="svc01", sloth_slo="slo1", owner="a-team"} 0.1
+  slo:sli_error:ratio_rate5m{sloth_id="svc02-slo1", sloth_service="svc02", sloth_slo="slo1", owner="a-team"} 0.2
+
+eval instant at 1m `+gotRules[0].Expr+`
+  {sloth_service="svc01", sloth_slo="slo1", team="a-team"} 0.1
+`)
+	require.NoError(t, err)
+	defer promTest.Close()
+	require.NoError(t, promTest.Run())
+}
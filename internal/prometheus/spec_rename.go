@@ -0,0 +1,334 @@
+package prometheus
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"gopkg.in/yaml.v2"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// SpecRename is the rename of a service and/or one of its SLOs.
+type SpecRename struct {
+	// Service is the current service name.
+	Service string
+	// NewService is the new service name, if empty the service will not be renamed.
+	NewService string
+	// SLO is the current SLO name, if empty the SLOs will not be renamed.
+	SLO string
+	// NewSLO is the new SLO name.
+	NewSLO string
+}
+
+// Validate validates the rename.
+func (s SpecRename) Validate() error {
+	if s.Service == "" {
+		return fmt.Errorf("service is required")
+	}
+
+	if s.NewService == "" && s.NewSLO == "" {
+		return fmt.Errorf("a new service or SLO name is required")
+	}
+
+	if (s.SLO == "") != (s.NewSLO == "") {
+		return fmt.Errorf("SLO and new SLO names are required to rename an SLO")
+	}
+
+	for _, name := range []string{s.NewService, s.NewSLO} {
+		if name != "" && !nameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid name %q", name)
+		}
+	}
+
+	return nil
+}
+
+// RenamedSLO is an SLO affected by a rename.
+type RenamedSLO struct {
+	OldService string
+	OldName    string
+	NewService string
+	NewName    string
+}
+
+// OldID returns the SLO ID before the rename.
+func (r RenamedSLO) OldID() string { return fmt.Sprintf("%s-%s", r.OldService, r.OldName) }
+
+// NewID returns the SLO ID after the rename.
+func (r RenamedSLO) NewID() string { return fmt.Sprintf("%s-%s", r.NewService, r.NewName) }
+
+// Changes returns the generated series labels and rule group names that change with the
+// rename, as old and new pairs.
+func (r RenamedSLO) Changes() [][2]string {
+	changes := [][2]string{
+		{fmt.Sprintf("%s=%q", sloIDLabelName, r.OldID()), fmt.Sprintf("%s=%q", sloIDLabelName, r.NewID())},
+	}
+	if r.OldService != r.NewService {
+		changes = append(changes, [2]string{fmt.Sprintf("%s=%q", sloServiceLabelName, r.OldService), fmt.Sprintf("%s=%q", sloServiceLabelName, r.NewService)})
+	}
+	if r.OldName != r.NewName {
+		changes = append(changes, [2]string{fmt.Sprintf("%s=%q", sloNameLabelName, r.OldName), fmt.Sprintf("%s=%q", sloNameLabelName, r.NewName)})
+	}
+	for _, groupFmt := range []string{sliRecordingsGroupNameFmt, metaRecordingsGroupNameFmt, alertsGroupNameFmt} {
+		changes = append(changes, [2]string{fmt.Sprintf(groupFmt, r.OldID()), fmt.Sprintf(groupFmt, r.NewID())})
+	}
+
+	return changes
+}
+
+const k8sPrometheusServiceLevelKind = "PrometheusServiceLevel"
+
+var (
+	yamlKeyLineRegexp      = regexp.MustCompile(`^( *)(- +)?([A-Za-z0-9_.-]+):( *)(.*)$`)
+	yamlDocSeparatorRegexp = regexp.MustCompile(`^---( |$)`)
+)
+
+// RenameSpecYAML renames the service and SLOs of the `prometheus/v1` and Kubernetes
+// `PrometheusServiceLevel` specs of YAML (multi-document) data. The rename is made on
+// the YAML lines in place, so the rest of the file (comments, format...) is kept. The
+// documents that are not affected by the rename are not changed.
+func RenameSpecYAML(data []byte, rename SpecRename) ([]byte, []RenamedSLO, error) {
+	err := rename.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid rename: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	renamed := []RenamedSLO{}
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !yamlDocSeparatorRegexp.MatchString(strings.TrimPrefix(lines[i], string(utf8BOM))) {
+			continue
+		}
+
+		docRenamed, err := renameSpecYAMLDoc(lines[start:i], rename)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not rename YAML document at line %d: %w", start+1, err)
+		}
+		renamed = append(renamed, docRenamed...)
+		start = i + 1
+	}
+
+	return []byte(strings.Join(lines, "\n")), renamed, nil
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// renameSpecYAMLDoc renames the lines of a single YAML document in place.
+func renameSpecYAMLDoc(lines []string, rename SpecRename) ([]RenamedSLO, error) {
+	original := strings.Join(lines, "\n")
+	var doc map[interface{}]interface{}
+	err := yaml.Unmarshal([]byte(original), &doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal YAML: %w", err)
+	}
+
+	// Get the spec root based on the spec type.
+	var root map[interface{}]interface{}
+	pathPrefix := ""
+	switch {
+	case doc["version"] == prometheusv1.Version:
+		root = doc
+	case doc["kind"] == k8sPrometheusServiceLevelKind:
+		root, _ = doc["spec"].(map[interface{}]interface{})
+		pathPrefix = "spec."
+	}
+	if root == nil || root["service"] != rename.Service {
+		return nil, nil
+	}
+
+	// Rename the expected document so we can check the result of the lines rename.
+	newService := rename.Service
+	if rename.NewService != "" {
+		newService = rename.NewService
+		root["service"] = newService
+	}
+	renamed := []RenamedSLO{}
+	slos, _ := root["slos"].([]interface{})
+	for _, s := range slos {
+		slo, ok := s.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := slo["name"].(string)
+		newName := name
+		if rename.SLO != "" {
+			if name != rename.SLO {
+				continue
+			}
+			newName = rename.NewSLO
+			slo["name"] = newName
+		}
+
+		renamed = append(renamed, RenamedSLO{
+			OldService: rename.Service,
+			OldName:    name,
+			NewService: newService,
+			NewName:    newName,
+		})
+	}
+	if len(renamed) == 0 {
+		return nil, nil
+	}
+
+	// Rename the lines.
+	replacements := map[string][2]string{}
+	if rename.NewService != "" {
+		replacements[pathPrefix+"service"] = [2]string{rename.Service, rename.NewService}
+	}
+	if rename.SLO != "" {
+		replacements[pathPrefix+"slos.-.name"] = [2]string{rename.SLO, rename.NewSLO}
+	}
+	renameYAMLLines(lines, replacements)
+
+	// Check the lines rename result is the expected one.
+	var got map[interface{}]interface{}
+	err = yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &got)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal renamed YAML: %w", err)
+	}
+	if !reflect.DeepEqual(doc, got) {
+		return nil, fmt.Errorf("could not rename safely, the YAML format is not supported (e.g flow style)")
+	}
+
+	return renamed, nil
+}
+
+type yamlKeyPath struct {
+	indent int
+	key    string
+}
+
+// renameYAMLLines replaces the scalar values of the block style YAML keys on the key
+// paths (e.g `spec.slos.-.name`, where `-` is a list item) that match the old value.
+func renameYAMLLines(lines []string, replacements map[string][2]string) {
+	walkYAMLKeyLines(lines, func(path, key, value string) (string, string, bool) {
+		r, ok := replacements[path]
+		if !ok {
+			return "", "", false
+		}
+
+		newValue, ok := replaceYAMLScalar(value, r[0], r[1])
+		return key, newValue, ok
+	})
+}
+
+// walkYAMLKeyLines calls the function with the key path (e.g `spec.slos.-.name`, where `-`
+// is a list item), the key and the value of every block style YAML key line. If the function
+// returns true, the line key and value are replaced in place.
+func walkYAMLKeyLines(lines []string, fn func(path, key, value string) (newKey, newValue string, ok bool)) {
+	stack := []yamlKeyPath{}
+	push := func(indent int, key string) {
+		// List items can be on the same indentation as their parent key.
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.indent < indent || (key == "-" && top.indent == indent && top.key != "-") {
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, yamlKeyPath{indent: indent, key: key})
+	}
+
+	for i, line := range lines {
+		bom := ""
+		if strings.HasPrefix(line, string(utf8BOM)) {
+			bom, line = string(utf8BOM), line[len(utf8BOM):]
+		}
+		line, cr := strings.TrimSuffix(line, "\r"), strings.HasSuffix(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		m := yamlKeyLineRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		indent := len(m[1])
+		if m[2] != "" {
+			push(indent, "-")
+			indent += len(m[2])
+		}
+		push(indent, m[3])
+
+		keys := make([]string, 0, len(stack))
+		for _, k := range stack {
+			keys = append(keys, k.key)
+		}
+
+		newKey, newValue, ok := fn(strings.Join(keys, "."), m[3], m[5])
+		if !ok {
+			continue
+		}
+		lines[i] = bom + m[1] + m[2] + newKey + ":" + m[4] + newValue
+		if cr {
+			lines[i] += "\r"
+		}
+	}
+}
+
+// replaceYAMLScalar replaces a plain or quoted YAML scalar value (keeping the quotes and
+// the trailing comments) if it's the old value.
+func replaceYAMLScalar(value, oldValue, newValue string) (string, bool) {
+	quote, scalar, rest, ok := splitYAMLScalar(value)
+	if !ok || scalar != oldValue {
+		return "", false
+	}
+
+	return quote + newValue + quote + rest, true
+}
+
+// splitYAMLScalar splits a plain or quoted YAML scalar value in its quote, the scalar and
+// the rest of the value (e.g trailing comments).
+func splitYAMLScalar(value string) (quote, scalar, rest string, ok bool) {
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, `'`) {
+		end := strings.Index(value[1:], value[:1])
+		if end < 0 {
+			return "", "", "", false
+		}
+		return value[:1], value[1 : end+1], value[end+2:], true
+	}
+
+	scalar = value
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		scalar, rest = value[:idx], value[idx:]
+	}
+	trimmed := strings.TrimRight(scalar, " \t")
+
+	return "", trimmed, scalar[len(trimmed):] + rest, true
+}
+
+// GenerateRenameBridgeRules generates the recording rules that keep recording the renamed
+// SLO SLI error series with the old SLO labels, based on the renamed SLO SLI recording
+// rules. This way the queries, dashboards and error budget calculations that use the old
+// labels have continuity while the new series don't have enough historical data (an SLO
+// time window).
+func GenerateRenameBridgeRules(renamed RenamedSLO, sliRules []rulefmt.Rule) []rulefmt.Rule {
+	rules := make([]rulefmt.Rule, 0, len(sliRules))
+	for _, r := range sliRules {
+		if r.Record == "" {
+			continue
+		}
+
+		expr := fmt.Sprintf(`%s{%s=%q}`, r.Record, sloIDLabelName, renamed.NewID())
+		for _, l := range [][2]string{
+			{sloIDLabelName, renamed.OldID()},
+			{sloServiceLabelName, renamed.OldService},
+			{sloNameLabelName, renamed.OldName},
+		} {
+			expr = fmt.Sprintf(`label_replace(%s, %q, %q, "", "")`, expr, l[0], l[1])
+		}
+
+		rules = append(rules, rulefmt.Rule{
+			Record: r.Record,
+			Expr:   expr,
+		})
+	}
+
+	return rules
+}
@@ -0,0 +1,202 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestRenameSpecYAML(t *testing.T) {
+	tests := map[string]struct {
+		data       string
+		rename     prometheus.SpecRename
+		expData    string
+		expRenamed []prometheus.RenamedSLO
+		expErr     bool
+	}{
+		"Having an invalid rename should fail.": {
+			data:   `version: "prometheus/v1"`,
+			rename: prometheus.SpecRename{Service: "svc01"},
+			expErr: true,
+		},
+
+		"Having a rename to an invalid name should fail.": {
+			data:   `version: "prometheus/v1"`,
+			rename: prometheus.SpecRename{Service: "svc01", NewService: "svc 02"},
+			expErr: true,
+		},
+
+		"Having specs of other services should not rename anything.": {
+			data: `
+version: "prometheus/v1"
+service: "svc02"
+slos:
+  - name: "slo1"
+`,
+			rename: prometheus.SpecRename{Service: "svc01", NewService: "svc03"},
+			expData: `
+version: "prometheus/v1"
+service: "svc02"
+slos:
+  - name: "slo1"
+`,
+			expRenamed: []prometheus.RenamedSLO{},
+		},
+
+		"Having a service rename should rename the service of the affected documents keeping the rest of the file.": {
+			data: `# The service SLOs.
+version: "prometheus/v1"
+service: svc01 # Owned by team-a.
+labels:
+  service: svc01
+slos:
+  - name: "slo1"
+  - name: slo2
+---
+version: "prometheus/v1"
+service: "svc02"
+slos:
+  - name: "slo1"
+`,
+			rename: prometheus.SpecRename{Service: "svc01", NewService: "svc03"},
+			expData: `# The service SLOs.
+version: "prometheus/v1"
+service: svc03 # Owned by team-a.
+labels:
+  service: svc01
+slos:
+  - name: "slo1"
+  - name: slo2
+---
+version: "prometheus/v1"
+service: "svc02"
+slos:
+  - name: "slo1"
+`,
+			expRenamed: []prometheus.RenamedSLO{
+				{OldService: "svc01", OldName: "slo1", NewService: "svc03", NewName: "slo1"},
+				{OldService: "svc01", OldName: "slo2", NewService: "svc03", NewName: "slo2"},
+			},
+		},
+
+		"Having an SLO rename should rename only the SLO.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+slos:
+  - name: 'slo1'
+    objective: 99
+    description: "slo1"
+  - name: "slo2"
+`,
+			rename: prometheus.SpecRename{Service: "svc01", SLO: "slo1", NewSLO: "slo3"},
+			expData: `
+version: "prometheus/v1"
+service: "svc01"
+slos:
+  - name: 'slo3'
+    objective: 99
+    description: "slo1"
+  - name: "slo2"
+`,
+			expRenamed: []prometheus.RenamedSLO{
+				{OldService: "svc01", OldName: "slo1", NewService: "svc01", NewName: "slo3"},
+			},
+		},
+
+		"Having a Kubernetes spec should rename the service and the SLO.": {
+			data: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: svc01
+spec:
+  service: "svc01"
+  slos:
+  - name: "slo1"
+    objective: 99
+  - name: "slo2"
+`,
+			rename: prometheus.SpecRename{Service: "svc01", NewService: "svc03", SLO: "slo2", NewSLO: "slo3"},
+			expData: `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: svc01
+spec:
+  service: "svc03"
+  slos:
+  - name: "slo1"
+    objective: 99
+  - name: "slo3"
+`,
+			expRenamed: []prometheus.RenamedSLO{
+				{OldService: "svc01", OldName: "slo2", NewService: "svc03", NewName: "slo3"},
+			},
+		},
+
+		"Having a not supported YAML format should fail.": {
+			data: `
+version: "prometheus/v1"
+service: "svc01"
+slos: [{name: "slo1"}]
+`,
+			rename: prometheus.SpecRename{Service: "svc01", SLO: "slo1", NewSLO: "slo3"},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotData, gotRenamed, err := prometheus.RenameSpecYAML([]byte(test.data), test.rename)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expData, string(gotData))
+				assert.Equal(test.expRenamed, gotRenamed)
+			}
+		})
+	}
+}
+
+func TestGenerateRenameBridgeRules(t *testing.T) {
+	renamed := prometheus.RenamedSLO{OldService: "svc01", OldName: "slo1", NewService: "svc02", NewName: "slo1"}
+	sliRules := []rulefmt.Rule{
+		{Record: "slo:sli_error:ratio_rate5m", Expr: "vector(1)"},
+		{Record: "slo:sli_error:ratio_rate30d", Expr: "vector(1)"},
+	}
+
+	expRules := []rulefmt.Rule{
+		{
+			Record: "slo:sli_error:ratio_rate5m",
+			Expr:   `label_replace(label_replace(label_replace(slo:sli_error:ratio_rate5m{sloth_id="svc02-slo1"}, "sloth_id", "svc01-slo1", "", ""), "sloth_service", "svc01", "", ""), "sloth_slo", "slo1", "", "")`,
+		},
+		{
+			Record: "slo:sli_error:ratio_rate30d",
+			Expr:   `label_replace(label_replace(label_replace(slo:sli_error:ratio_rate30d{sloth_id="svc02-slo1"}, "sloth_id", "svc01-slo1", "", ""), "sloth_service", "svc01", "", ""), "sloth_slo", "slo1", "", "")`,
+		},
+	}
+
+	gotRules := prometheus.GenerateRenameBridgeRules(renamed, sliRules)
+	assert.Equal(t, expRules, gotRules)
+}
+
+func TestRenamedSLOChanges(t *testing.T) {
+	renamed := prometheus.RenamedSLO{OldService: "svc01", OldName: "slo1", NewService: "svc02", NewName: "slo1"}
+
+	expChanges := [][2]string{
+		{`sloth_id="svc01-slo1"`, `sloth_id="svc02-slo1"`},
+		{`sloth_service="svc01"`, `sloth_service="svc02"`},
+		{"sloth-slo-sli-recordings-svc01-slo1", "sloth-slo-sli-recordings-svc02-slo1"},
+		{"sloth-slo-meta-recordings-svc01-slo1", "sloth-slo-meta-recordings-svc02-slo1"},
+		{"sloth-slo-alerts-svc01-slo1", "sloth-slo-alerts-svc02-slo1"},
+	}
+
+	assert.Equal(t, expChanges, renamed.Changes())
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/slok/sloth/internal/alert"
 	"github.com/slok/sloth/internal/prometheus"
 )
 
@@ -22,11 +24,27 @@ func (t testMemPluginsRepo) GetSLIPlugin(ctx context.Context, id string) (*prome
 }
 
 func TestYAMLoadSpec(t *testing.T) {
+	testWindows := func(name string, period time.Duration) alert.Windows {
+		w := alert.DefaultWindows
+		w.Name = name
+		w.SLOPeriod = period
+		return w
+	}
+	alertWindows, err := alert.NewWindowsCatalog(testWindows("test-28d", 28*24*time.Hour), testWindows("test-90d", 90*24*time.Hour))
+	require.NoError(t, err)
+	tieredAlertWindows, err := alert.NewTieredWindowsCatalog(
+		alert.WindowsTiers{Label: "tier", Windows: map[string]string{"tier1": "test-tier1-28d"}},
+		testWindows("test-28d", 28*24*time.Hour), testWindows("test-tier1-28d", 28*24*time.Hour))
+	require.NoError(t, err)
+
 	tests := map[string]struct {
-		specYaml string
-		plugins  map[string]prometheus.SLIPlugin
-		expModel *prometheus.SLOGroup
-		expErr   bool
+		specYaml         string
+		plugins          map[string]prometheus.SLIPlugin
+		defaultSLOPeriod time.Duration
+		alertWindows     *alert.WindowsCatalog
+		environment      string
+		expModel         *prometheus.SLOGroup
+		expErr           bool
 	}{
 		"Empty spec should fail.": {
 			specYaml: ``,
@@ -38,127 +56,1449 @@ func TestYAMLoadSpec(t *testing.T) {
 			expErr:   true,
 		},
 
-		"Spec without version should fail.": {
+		"Spec without version should fail.": {
+			specYaml: `
+service: test-svc
+slos:
+- name: something
+`,
+			expErr: true,
+		},
+
+		"Spec with invalid version should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v2"
+slos:
+- name: something
+`,
+			expErr: true,
+		},
+
+		"Spec without SLOs should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos: []
+`,
+			expErr: true,
+		},
+
+		"Spec without unknown SLI plugin should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo"
+    objective: 99
+    sli:
+      plugin:
+        id: unknown_plugin
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with SLI plugin that returns an error should use the plugin correctly and fail.": {
+			plugins: map[string]prometheus.SLIPlugin{
+				"test_plugin": {
+					ID: "test_plugin",
+					Func: func(ctx context.Context, meta map[string]string, labels map[string]string, options map[string]string) (string, error) {
+						return "", fmt.Errorf("something")
+					},
+				},
+			},
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo"
+    objective: 99
+    sli:
+      plugin:
+        id: test_plugin
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with SLI plugin should use the plugin correctly.": {
+			plugins: map[string]prometheus.SLIPlugin{
+				"test_plugin": {
+					ID: "test_plugin",
+					Func: func(ctx context.Context, meta map[string]string, labels map[string]string, options map[string]string) (string, error) {
+						return fmt.Sprintf(`plugin_raw_expr{service="%s",slo="%s",objective="%s",gk1="%s",k1="%s",k2="%s"}`,
+							meta["service"],
+							meta["slo"],
+							meta["objective"],
+							labels["gk1"],
+							options["k1"],
+							options["k2"]), nil
+					},
+				},
+			},
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+labels:
+  gk1: gv1
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      plugin:
+        id: test_plugin
+        options:
+          k1: v1
+          k2: true
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{"gk1": "gv1"},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{
+							ErrorRatioQuery: `plugin_raw_expr{service="test-svc",slo="slo-test",objective="99.000000",gk1="gv1",k1="v1",k2="true"}`,
+						},
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with chained SLI plugins should use the plugins in order correctly.": {
+			plugins: map[string]prometheus.SLIPlugin{
+				"test_plugin": {
+					ID: "test_plugin",
+					Func: func(ctx context.Context, meta map[string]string, labels map[string]string, options map[string]string) (string, error) {
+						return fmt.Sprintf(`plugin_raw_expr{service="%s",k1="%s"}`, meta["service"], options["k1"]), nil
+					},
+				},
+				"test_filter_plugin": {
+					ID: "test_filter_plugin",
+					Func: func(ctx context.Context, meta map[string]string, labels map[string]string, options map[string]string) (string, error) {
+						return fmt.Sprintf(`(%s) and on() %s`, meta["query"], options["filter"]), nil
+					},
+				},
+			},
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      plugin:
+        id: test_plugin
+        options:
+          k1: v1
+        chain:
+          - id: test_filter_plugin
+            options:
+              filter: f1
+          - id: test_filter_plugin
+            options:
+              filter: f2
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{
+							ErrorRatioQuery: `((plugin_raw_expr{service="test-svc",k1="v1"}) and on() f1) and on() f2`,
+						},
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with an unknown chained SLI plugin should fail.": {
+			plugins: map[string]prometheus.SLIPlugin{
+				"test_plugin": {
+					ID: "test_plugin",
+					Func: func(ctx context.Context, meta map[string]string, labels map[string]string, options map[string]string) (string, error) {
+						return "plugin_raw_expr", nil
+					},
+				},
+			},
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      plugin:
+        id: test_plugin
+        chain:
+          - id: unknown_plugin
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with an invalid objective should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: "lots of nines"
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with objectives in nines and percent notation should load the objectives correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo1"
+    objective: "three nines"
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+  - name: "slo2"
+    objective: "99.5%"
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo1",
+					Name:            "slo1",
+					Service:         "test-svc",
+					TimeWindow:      30 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99.9,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+				{
+					ID:              "test-svc-slo2",
+					Name:            "slo2",
+					Service:         "test-svc",
+					TimeWindow:      30 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99.5,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with an invalid SLI offset should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      offset: "five minutes"
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with SLI offset should load the offset correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      offset: 5m
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw:    &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+						Offset: 5 * time.Minute,
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with SLI examples should load the examples correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      events:
+        error_query: sum(rate(http_requests_total{code="500"}[{{.window}}]))
+        total_query: sum(rate(http_requests_total[{{.window}}]))
+      examples:
+        - name: half-errors
+          series:
+            'http_requests_total{code="200"}': 1
+            'http_requests_total{code="500"}': 1
+          error_ratio: 0.5
+        - series:
+            'http_requests_total{code="500"}': 3
+          error_ratio: 1
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Events: &prometheus.SLIEvents{
+							ErrorQuery: `sum(rate(http_requests_total{code="500"}[{{.window}}]))`,
+							TotalQuery: `sum(rate(http_requests_total[{{.window}}]))`,
+						},
+						Examples: []prometheus.SLIExample{
+							{
+								Name: "half-errors",
+								Series: map[string]float64{
+									`http_requests_total{code="200"}`: 1,
+									`http_requests_total{code="500"}`: 1,
+								},
+								ErrorRatio: 0.5,
+							},
+							{
+								Series:     map[string]float64{`http_requests_total{code="500"}`: 3},
+								ErrorRatio: 1,
+							},
+						},
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with a latency SLI and an invalid threshold should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      latency:
+        histogram_metric: http_request_duration_seconds
+        threshold: fast
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with latency and events SLIs should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      events:
+        error_query: test_expr_error
+        total_query: test_expr_total
+      latency:
+        histogram_metric: http_request_duration_seconds
+        threshold: 300ms
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with a latency SLI should load the histogram events SLI correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      latency:
+        histogram_metric: http_request_duration_seconds
+        threshold: 300ms
+        selector: job="api"
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Events: &prometheus.SLIEvents{
+							ErrorQuery: "(sum(rate(http_request_duration_seconds_count{job=\"api\"}[{{.window}}])))\n-\n(sum(rate(http_request_duration_seconds_bucket{job=\"api\", le=\"0.3\"}[{{.window}}])))",
+							TotalQuery: `sum(rate(http_request_duration_seconds_count{job="api"}[{{.window}}]))`,
+						},
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with a native histogram latency SLI should load the native histogram events SLI correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      latency:
+        histogram_metric: http_request_duration_seconds
+        threshold: 300ms
+        native_histogram: true
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Events: &prometheus.SLIEvents{
+							ErrorQuery: "histogram_count(sum(rate(http_request_duration_seconds{}[{{.window}}])))\n*\n(1 - histogram_fraction(0, 0.3, sum(rate(http_request_duration_seconds{}[{{.window}}]))))",
+							TotalQuery: `histogram_count(sum(rate(http_request_duration_seconds{}[{{.window}}])))`,
+						},
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with an invalid SLO period should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: "a week"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with SLO period should load the SLOs time window correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: 7d
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			defaultSLOPeriod: 28 * 24 * time.Hour,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      7 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec without SLO period should use the default SLO period as the SLOs time window.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			defaultSLOPeriod: 28 * 24 * time.Hour,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      28 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with unknown alert windows should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      windows: test-7d
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			alertWindows: alertWindows,
+			expErr:       true,
+		},
+
+		"Spec with alert windows should select the alert windows of the catalog.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      windows: test-90d
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			alertWindows: alertWindows,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      30 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					AlertWindows:    func() *alert.Windows { w := testWindows("test-90d", 90*24*time.Hour); return &w }(),
+				},
+			}},
+		},
+
+		"Spec without alert windows should select the alert windows of the catalog SLO period.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: 28d
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			alertWindows: alertWindows,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      28 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					AlertWindows:    func() *alert.Windows { w := testWindows("test-28d", 28*24*time.Hour); return &w }(),
+				},
+			}},
+		},
+
+		"Spec without alert windows should select the alert windows of the catalog service tier.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: 28d
+labels:
+  tier: tier1
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			alertWindows: tieredAlertWindows,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      28 * 24 * time.Hour,
+					Labels:          map[string]string{"tier": "tier1"},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					AlertWindows:    func() *alert.Windows { w := testWindows("test-tier1-28d", 28*24*time.Hour); return &w }(),
+				},
+			}},
+		},
+
+		"Spec without alert windows and a service tier without alert windows should select the alert windows of the catalog SLO period.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: 28d
+labels:
+  tier: tier2
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			alertWindows: tieredAlertWindows,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      28 * 24 * time.Hour,
+					Labels:          map[string]string{"tier": "tier2"},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					AlertWindows:    func() *alert.Windows { w := testWindows("test-28d", 28*24*time.Hour); return &w }(),
+				},
+			}},
+		},
+
+		"Spec with environments should apply the selected environment overrides.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: 28d
+slos:
+  - name: "slo-test"
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    environments:
+      staging:
+        objective: 99
+        slo_period: 90d
+      dev:
+        objective: 95
+`,
+			alertWindows: alertWindows,
+			environment:  "staging",
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      90 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					AlertWindows:    func() *alert.Windows { w := testWindows("test-90d", 90*24*time.Hour); return &w }(),
+				},
+			}},
+		},
+
+		"Spec with environments should use the SLO values on the unset environment overrides.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: 28d
+slos:
+  - name: "slo-test"
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    environments:
+      staging:
+        objective: 99
+        slo_period: 90d
+      dev:
+        objective: 95
+`,
+			environment: "dev",
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      28 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       95,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with environments should use the SLO values on environments without overrides.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slo_period: 28d
+slos:
+  - name: "slo-test"
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    environments:
+      staging:
+        objective: 99
+        slo_period: 90d
+      dev:
+        objective: 95
+`,
+			environment: "production",
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      28 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:       99.9,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
+		},
+
+		"Spec with an invalid environment SLO period should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    environments:
+      staging:
+        slo_period: 1x
+`,
+			environment: "staging",
+			expErr:      true,
+		},
+
+		"Spec with alert guard should load the alert guard correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      guard: cluster_maintenance == 0
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					AlertGuard:      "cluster_maintenance == 0",
+				},
+			}},
+		},
+
+		"Spec with an invalid alert for duration should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      name: testAlert
+      page_alert:
+        for: "a while"
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
+		"Spec with alert for durations should load the alerts for durations correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      name: testAlert
+      page_alert:
+        for: 5m
+      ticket_alert:
+        for: 1 hour
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+					},
+					Objective: 99,
+					PageAlertMeta: prometheus.AlertMeta{
+						Name:        "testAlert",
+						Labels:      map[string]string{},
+						Annotations: map[string]string{},
+						For:         5 * time.Minute,
+					},
+					TicketAlertMeta: prometheus.AlertMeta{
+						Name:        "testAlert",
+						Labels:      map[string]string{},
+						Annotations: map[string]string{},
+						For:         time.Hour,
+					},
+				},
+			}},
+		},
+
+		"Spec with an invalid transition previous time window should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    transition:
+      previous_time_window: "four weeks"
+`,
+			expErr: true,
+		},
+
+		"Spec with transition should load the transition correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    transition:
+      previous_objective: two nines
+      previous_time_window: 28 days
+      changed_at: "2021-06-30"
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+					},
+					Objective:       99.9,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					Transition: &prometheus.SLOTransition{
+						PreviousObjective:  99,
+						PreviousTimeWindow: 28 * 24 * time.Hour,
+						ChangedAt:          "2021-06-30",
+					},
+				},
+			}},
+		},
+
+		"Spec with transition without previous values should use the current ones.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    transition:
+      changed_at: "2021-06-30"
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+					},
+					Objective:       99.9,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					Transition: &prometheus.SLOTransition{
+						PreviousObjective:  99.9,
+						PreviousTimeWindow: 30 * 24 * time.Hour,
+						ChangedAt:          "2021-06-30",
+					},
+				},
+			}},
+		},
+
+		"Spec with an invalid reporting window should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    reporting_windows: ["one week"]
+`,
+			expErr: true,
+		},
+
+		"Spec with reporting windows should load the reporting windows correctly.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    reporting_windows: ["7d", 90 days]
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+					},
+					Objective:        99.9,
+					PageAlertMeta:    prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta:  prometheus.AlertMeta{Disable: true},
+					ReportingWindows: []time.Duration{7 * 24 * time.Hour, 90 * 24 * time.Hour},
+				},
+			}},
+		},
+
+		"Spec with an invalid timeslice window should fail.": {
 			specYaml: `
 service: test-svc
+version: "prometheus/v1"
 slos:
-- name: something
+  - name: "slo-test"
+    objective: 99
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    timeslice:
+      window: "one minute"
 `,
 			expErr: true,
 		},
 
-		"Spec with invalid version should fail.": {
+		"Spec with timeslice should load the timeslice correctly.": {
 			specYaml: `
 service: test-svc
-version: "prometheus/v2"
+version: "prometheus/v1"
 slos:
-- name: something
+  - name: "slo-test"
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+    timeslice:
+      window: 1m
+      error_ratio_threshold: 0.05
 `,
-			expErr: true,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+					},
+					Objective:       99.9,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					Timeslice:       &prometheus.SLOTimeslice{Window: time.Minute, ErrorRatioThreshold: 0.05},
+				},
+			}},
 		},
 
-		"Spec without SLOs should fail.": {
+		"Spec with human-friendly SLI offset should load the offset correctly.": {
 			specYaml: `
 service: test-svc
 version: "prometheus/v1"
-slos: []
+slos:
+  - name: "slo-test"
+    objective: 99.5%
+    sli:
+      offset: 1 minute 30 seconds
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
 `,
-			expErr: true,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
+					SLI: prometheus.SLI{
+						Raw:    &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+						Offset: 90 * time.Second,
+					},
+					Objective:       99.5,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+				},
+			}},
 		},
 
-		"Spec without unknown SLI plugin should fail.": {
+		"Spec with cost attribution should set the cost labels on the SLOs with preference.": {
 			specYaml: `
 service: test-svc
 version: "prometheus/v1"
+labels:
+  owner: team-a
+  product: other
+cost:
+  labels:
+    cost_center: cc-1
+    product: checkout
 slos:
-  - name: "slo"
+  - name: "slo-test"
     objective: 99
     sli:
-      plugin:
-        id: unknown_plugin
+      raw:
+        error_ratio_query: test_expr_ratio
     alerting:
       page_alert:
         disable: true
       ticket_alert:
         disable: true
 `,
-			expErr: true,
-		},
-
-		"Spec with SLI plugin that returns an error should use the plugin correctly and fail.": {
-			plugins: map[string]prometheus.SLIPlugin{
-				"test_plugin": {
-					ID: "test_plugin",
-					Func: func(ctx context.Context, meta map[string]string, labels map[string]string, options map[string]string) (string, error) {
-						return "", fmt.Errorf("something")
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{"owner": "team-a", "cost_center": "cc-1", "product": "checkout"},
+					Annotations: map[string]string{},
+					CostLabels:  map[string]string{"cost_center": "cc-1", "product": "checkout"},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
 					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
 				},
-			},
+			}},
+		},
+
+		"Spec with alerting defaults should set the default annotations on the SLOs without preference.": {
 			specYaml: `
 service: test-svc
 version: "prometheus/v1"
+annotations:
+  dashboard: https://grafana.test/d/svc
+alerting_defaults:
+  annotations:
+    runbook: https://runbooks.test/slo
+    dashboard: https://grafana.test/d/default
 slos:
-  - name: "slo"
+  - name: "slo-test"
     objective: 99
     sli:
-      plugin:
-        id: test_plugin
+      raw:
+        error_ratio_query: test_expr_ratio
     alerting:
       page_alert:
         disable: true
       ticket_alert:
         disable: true
 `,
-			expErr: true,
-		},
-
-		"Spec with SLI plugin should use the plugin correctly.": {
-			plugins: map[string]prometheus.SLIPlugin{
-				"test_plugin": {
-					ID: "test_plugin",
-					Func: func(ctx context.Context, meta map[string]string, labels map[string]string, options map[string]string) (string, error) {
-						return fmt.Sprintf(`plugin_raw_expr{service="%s",slo="%s",objective="%s",gk1="%s",k1="%s",k2="%s"}`,
-							meta["service"],
-							meta["slo"],
-							meta["objective"],
-							labels["gk1"],
-							options["k1"],
-							options["k2"]), nil
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{"runbook": "https://runbooks.test/slo", "dashboard": "https://grafana.test/d/svc"},
+					SLI: prometheus.SLI{
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
 					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
 				},
-			},
+			}},
+		},
+
+		"Spec with maintenance windows should set the maintenance windows on the SLOs.": {
 			specYaml: `
 service: test-svc
 version: "prometheus/v1"
-labels:
-  gk1: gv1
 slos:
   - name: "slo-test"
     objective: 99
     sli:
-      plugin:
-        id: test_plugin
-        options:
-          k1: v1
-          k2: true
+      raw:
+        error_ratio_query: test_expr_ratio
     alerting:
       page_alert:
         disable: true
       ticket_alert:
         disable: true
+      maintenance_windows:
+        - name: weekly-maintenance
+          times: ["02:00-04:00"]
+          weekdays: ["sunday"]
+          days_of_month: ["1:7"]
+          months: ["january"]
+          location: Europe/Madrid
 `,
 			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
 				{
-					ID:         "test-svc-slo-test",
-					Name:       "slo-test",
-					Service:    "test-svc",
-					TimeWindow: 30 * 24 * time.Hour,
-					Labels:     map[string]string{"gk1": "gv1"},
+					ID:          "test-svc-slo-test",
+					Name:        "slo-test",
+					Service:     "test-svc",
+					TimeWindow:  30 * 24 * time.Hour,
+					Labels:      map[string]string{},
+					Annotations: map[string]string{},
 					SLI: prometheus.SLI{
-						Raw: &prometheus.SLIRaw{
-							ErrorRatioQuery: `plugin_raw_expr{service="test-svc",slo="slo-test",objective="99.000000",gk1="gv1",k1="v1",k2="true"}`,
+						Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"},
+					},
+					Objective:       99,
+					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
+					MaintenanceWindows: []prometheus.MaintenanceWindow{
+						{
+							Name:        "weekly-maintenance",
+							Times:       []string{"02:00-04:00"},
+							Weekdays:    []string{"sunday"},
+							DaysOfMonth: []string{"1:7"},
+							Months:      []string{"january"},
+							Location:    "Europe/Madrid",
 						},
 					},
+				},
+			}},
+		},
+
+		"Spec with group labels should set the group labels on the SLOs.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    group_labels:
+      tenant: team-a
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:              "test-svc-slo-test",
+					Name:            "slo-test",
+					Service:         "test-svc",
+					TimeWindow:      30 * 24 * time.Hour,
+					Labels:          map[string]string{},
+					Annotations:     map[string]string{},
+					GroupLabels:     map[string]string{"tenant": "team-a"},
+					SLI:             prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
 					Objective:       99,
 					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
 					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
@@ -166,6 +1506,63 @@ slos:
 			}},
 		},
 
+		"Spec with rule group intervals should set the rule group intervals on the SLOs.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    rule_group_intervals:
+      default: 2m
+      alerts: 1m
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expModel: &prometheus.SLOGroup{SLOs: []prometheus.SLO{
+				{
+					ID:                 "test-svc-slo-test",
+					Name:               "slo-test",
+					Service:            "test-svc",
+					TimeWindow:         30 * 24 * time.Hour,
+					Labels:             map[string]string{},
+					Annotations:        map[string]string{},
+					SLI:                prometheus.SLI{Raw: &prometheus.SLIRaw{ErrorRatioQuery: "test_expr_ratio"}},
+					Objective:          99,
+					PageAlertMeta:      prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta:    prometheus.AlertMeta{Disable: true},
+					RuleGroupIntervals: prometheus.RuleGroupIntervals{SLIRecordings: 2 * time.Minute, MetaRecordings: 2 * time.Minute, Alerts: time.Minute},
+				},
+			}},
+		},
+
+		"Spec with an invalid rule group interval should fail.": {
+			specYaml: `
+service: test-svc
+version: "prometheus/v1"
+slos:
+  - name: "slo-test"
+    objective: 99
+    rule_group_intervals:
+      sli_recordings: 2 lightyears
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			expErr: true,
+		},
+
 		"Correct spec should return the models correctly.": {
 
 			specYaml: `
@@ -173,10 +1570,14 @@ version: "prometheus/v1"
 service: "test-svc"
 labels:
   owner: "myteam"
+annotations:
+  team: "My team"
 slos:
   - name: "slo1"
     labels:
       category: test
+    annotations:
+      dashboard: http://dashboard.com
     objective: 99.99
     description: "This is a test."
     sli:
@@ -232,6 +1633,10 @@ slos:
 						"owner":    "myteam",
 						"category": "test",
 					},
+					Annotations: map[string]string{
+						"team":      "My team",
+						"dashboard": "http://dashboard.com",
+					},
 					PageAlertMeta: prometheus.AlertMeta{
 						Disable: false,
 						Name:    "testAlert",
@@ -274,6 +1679,9 @@ slos:
 						"owner":    "myteam",
 						"category": "test2",
 					},
+					Annotations: map[string]string{
+						"team": "My team",
+					},
 					PageAlertMeta:   prometheus.AlertMeta{Disable: true},
 					TicketAlertMeta: prometheus.AlertMeta{Disable: true},
 				},
@@ -286,6 +1694,10 @@ slos:
 			assert := assert.New(t)
 
 			loader := prometheus.NewYAMLSpecLoader(testMemPluginsRepo(test.plugins))
+			if test.defaultSLOPeriod != 0 {
+				loader = loader.WithDefaultSLOPeriod(test.defaultSLOPeriod)
+			}
+			loader = loader.WithAlertWindows(test.alertWindows).WithEnvironment(test.environment)
 			gotModel, err := loader.LoadSpec(context.TODO(), []byte(test.specYaml))
 
 			if test.expErr {
@@ -296,3 +1708,82 @@ slos:
 		})
 	}
 }
+
+func TestYAMLoadSpecStrict(t *testing.T) {
+	tests := map[string]struct {
+		specYaml      string
+		strict        bool
+		expErrMessage string
+	}{
+		"A spec with unknown fields should load in lenient mode.": {
+			specYaml: `
+version: "prometheus/v1"
+service: "test-svc"
+owner: "myteam"
+slos:
+  - name: "slo1"
+    objetive: 99.9
+    sli:
+      raw:
+        error_ratio_query: test_expr_ratio
+`,
+		},
+
+		"A spec with unknown fields should fail in strict mode with the unknown fields path.": {
+			specYaml: `
+version: "prometheus/v1"
+service: "test-svc"
+owner: "myteam"
+slos:
+  - name: "slo1"
+    objective: 99.9
+  - name: "slo2"
+    objetive: 99.9
+    sli:
+      raw:
+        error_ratio_querry: test_expr_ratio
+`,
+			strict:        true,
+			expErrMessage: "unknown spec fields: $.owner, $.slos[1].objetive, $.slos[1].sli.raw.error_ratio_querry",
+		},
+
+		"A spec without unknown fields should load in strict mode.": {
+			specYaml: `
+version: "prometheus/v1"
+service: "test-svc"
+labels:
+  owner: "myteam"
+slos:
+  - name: "slo1"
+    objective: 99.9
+    labels:
+      category: test
+    sli:
+      events:
+        error_query: test_expr_error
+        total_query: test_expr_total
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+			strict: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			loader := prometheus.NewYAMLSpecLoader(testMemPluginsRepo(nil)).WithStrict(test.strict)
+			_, err := loader.LoadSpec(context.TODO(), []byte(test.specYaml))
+
+			if test.expErrMessage != "" {
+				assert.EqualError(err, test.expErrMessage)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package prometheus
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	prommodel "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/rulefmt"
 	"gopkg.in/yaml.v2"
 
+	"github.com/slok/sloth/internal/alert"
 	"github.com/slok/sloth/internal/info"
 	"github.com/slok/sloth/internal/log"
 )
@@ -19,23 +28,51 @@ var (
 	ErrNoSLORules = fmt.Errorf("0 SLO Prometheus rules generated")
 )
 
-func NewIOWriterGroupedRulesYAMLRepo(writer io.Writer, logger log.Logger) IOWriterGroupedRulesYAMLRepo {
+// RuleGroupsMeta is the metadata that will be set on the generated rule groups.
+type RuleGroupsMeta struct {
+	// PartialResponseStrategy is the Thanos ruler partial response strategy (`warn` or `abort`)
+	// of the rule groups, if empty it will not be set (e.g Prometheus).
+	PartialResponseStrategy string
+	// Labels are the labels of all the rule groups (supported by rulers like Mimir and Loki),
+	// these have priority over the SLO group labels.
+	Labels map[string]string
+	// Intervals are the evaluation intervals of the rule groups, the SLO rule group
+	// intervals have priority over these.
+	Intervals RuleGroupIntervals
+	// SLIWindowGroups partitions the SLI recording rules rule groups by SLI window.
+	SLIWindowGroups SLIWindowGroups
+}
+
+func NewIOWriterGroupedRulesYAMLRepo(writer io.Writer, meta RuleGroupsMeta, logger log.Logger) IOWriterGroupedRulesYAMLRepo {
 	return IOWriterGroupedRulesYAMLRepo{
 		writer: writer,
+		meta:   meta,
 		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "yaml"}),
 	}
 }
 
 // IOWriterGroupedRulesYAMLRepo knows to store all the SLO rules (recordings and alerts)
-// grouped in an IOWriter in YAML format, that is compatible with Prometheus.
+// grouped in an IOWriter in YAML format, that is compatible with Prometheus and Thanos ruler.
 type IOWriterGroupedRulesYAMLRepo struct {
 	writer io.Writer
+	meta   RuleGroupsMeta
 	logger log.Logger
 }
 
 type StorageSLO struct {
 	SLO   SLO
 	Rules SLORules
+	// Alerts are the SLO multiwindow multi-burn alerts, used by the storage backends
+	// that don't use the Prometheus alert rules.
+	Alerts alert.MWMBAlertGroup
+	// Source is the SLO spec source (e.g the spec file path), used by the rules index.
+	Source string
+}
+
+// SLOsStorer knows how to store the generated SLOs on a storage backend (e.g Prometheus
+// rules, Grafana alert rules...).
+type SLOsStorer interface {
+	StoreSLOs(ctx context.Context, slos []StorageSLO) error
 }
 
 // StoreSLOs will store the recording and alert prometheus rules, if grouped is false it will
@@ -47,29 +84,68 @@ func (i IOWriterGroupedRulesYAMLRepo) StoreSLOs(ctx context.Context, slos []Stor
 	}
 
 	ruleGroups := ruleGroupsYAMLv2{}
+	sliRecordings := make([]SLOSLIRecordings, 0, len(slos))
 	for _, slo := range slos {
-		if len(slo.Rules.SLIErrorRecRules) > 0 {
+		var groupLabels map[string]string
+		if len(slo.SLO.GroupLabels) > 0 || len(i.meta.Labels) > 0 {
+			groupLabels = mergeLabels(slo.SLO.GroupLabels, i.meta.Labels)
+		}
+		intervals := slo.SLO.RuleGroupIntervals.WithDefaults(i.meta.Intervals)
+
+		sloSLIRecordings := SLOSLIRecordings{
+			SLOID:    slo.SLO.ID,
+			Rules:    slo.Rules.SLIErrorRecRules,
+			Interval: intervals.SLIRecordings,
+			Labels:   groupLabels,
+		}
+		sliRecordings = append(sliRecordings, sloSLIRecordings)
+
+		if g := i.meta.SLIWindowGroups.SLORuleGroup(sloSLIRecordings); g != nil {
 			ruleGroups.Groups = append(ruleGroups.Groups, ruleGroupYAMLv2{
-				Name:  fmt.Sprintf("sloth-slo-sli-recordings-%s", slo.SLO.ID),
-				Rules: slo.Rules.SLIErrorRecRules,
+				Name:                    g.Name,
+				Interval:                prommodel.Duration(g.Interval),
+				Rules:                   g.Rules,
+				PartialResponseStrategy: i.meta.PartialResponseStrategy,
+				Labels:                  g.Labels,
 			})
 		}
 
 		if len(slo.Rules.MetadataRecRules) > 0 {
 			ruleGroups.Groups = append(ruleGroups.Groups, ruleGroupYAMLv2{
-				Name:  fmt.Sprintf("sloth-slo-meta-recordings-%s", slo.SLO.ID),
-				Rules: slo.Rules.MetadataRecRules,
+				Name:                    fmt.Sprintf(metaRecordingsGroupNameFmt, slo.SLO.ID),
+				Interval:                prommodel.Duration(intervals.MetaRecordings),
+				Rules:                   slo.Rules.MetadataRecRules,
+				PartialResponseStrategy: i.meta.PartialResponseStrategy,
+				Labels:                  groupLabels,
 			})
 		}
 
 		if len(slo.Rules.AlertRules) > 0 {
 			ruleGroups.Groups = append(ruleGroups.Groups, ruleGroupYAMLv2{
-				Name:  fmt.Sprintf("sloth-slo-alerts-%s", slo.SLO.ID),
-				Rules: slo.Rules.AlertRules,
+				Name:                    fmt.Sprintf(alertsGroupNameFmt, slo.SLO.ID),
+				Interval:                prommodel.Duration(intervals.Alerts),
+				Rules:                   slo.Rules.AlertRules,
+				PartialResponseStrategy: i.meta.PartialResponseStrategy,
+				Labels:                  groupLabels,
 			})
 		}
 	}
 
+	// The SLI recording rules of the SLOs shared per window rule groups.
+	sliWindowGroups, err := i.meta.SLIWindowGroups.WindowRuleGroups(sliRecordings)
+	if err != nil {
+		return fmt.Errorf("could not group the SLI recording rules by window: %w", err)
+	}
+	for _, g := range sliWindowGroups {
+		ruleGroups.Groups = append(ruleGroups.Groups, ruleGroupYAMLv2{
+			Name:                    g.Name,
+			Interval:                prommodel.Duration(g.Interval),
+			Rules:                   g.Rules,
+			PartialResponseStrategy: i.meta.PartialResponseStrategy,
+			Labels:                  g.Labels,
+		})
+	}
+
 	// If we don't have anything to store, error so we can increase the reliability
 	// because maybe this was due to an unintended error (typos, misconfig, too many disable...).
 	if len(ruleGroups.Groups) == 0 {
@@ -94,6 +170,453 @@ func (i IOWriterGroupedRulesYAMLRepo) StoreSLOs(ctx context.Context, slos []Stor
 	return nil
 }
 
+func NewIOWriterInhibitRulesYAMLRepo(writer io.Writer, logger log.Logger) IOWriterInhibitRulesYAMLRepo {
+	return IOWriterInhibitRulesYAMLRepo{
+		writer: writer,
+		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "alertmanager-yaml"}),
+	}
+}
+
+// IOWriterInhibitRulesYAMLRepo knows to store Alertmanager inhibition rules in an IOWriter
+// as an Alertmanager configuration YAML fragment.
+type IOWriterInhibitRulesYAMLRepo struct {
+	writer io.Writer
+	logger log.Logger
+}
+
+func (i IOWriterInhibitRulesYAMLRepo) StoreInhibitRules(ctx context.Context, rules []InhibitRule) error {
+	data, err := yaml.Marshal(inhibitRulesYAMLv2{InhibitRules: rules})
+	if err != nil {
+		return fmt.Errorf("could not format inhibit rules: %w", err)
+	}
+
+	data = writeTopDisclaimer(data)
+	_, err = i.writer.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write inhibit rules: %w", err)
+	}
+
+	logger := i.logger.WithCtxValues(ctx)
+	logger.WithValues(log.Kv{"inhibit-rules": len(rules)}).Infof("Alertmanager inhibit rules written")
+
+	return nil
+}
+
+func NewIOWriterTimeIntervalsYAMLRepo(writer io.Writer, logger log.Logger) IOWriterTimeIntervalsYAMLRepo {
+	return IOWriterTimeIntervalsYAMLRepo{
+		writer: writer,
+		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "alertmanager-yaml"}),
+	}
+}
+
+// IOWriterTimeIntervalsYAMLRepo knows to store Alertmanager time intervals in an IOWriter as an
+// Alertmanager configuration YAML fragment. The SLO alerts to mute are stored as a reference
+// next to the time intervals, to be set on the user routes, not as routes without receiver.
+type IOWriterTimeIntervalsYAMLRepo struct {
+	writer io.Writer
+	logger log.Logger
+}
+
+func (i IOWriterTimeIntervalsYAMLRepo) StoreTimeIntervals(ctx context.Context, intervals AlertmanagerTimeIntervals) error {
+	data, err := yaml.Marshal(timeIntervalsYAMLv2{
+		TimeIntervals: intervals.TimeIntervals,
+		SLOMutes:      intervals.SLOMutes,
+	})
+	if err != nil {
+		return fmt.Errorf("could not format time intervals: %w", err)
+	}
+
+	data = append([]byte(timeIntervalsUsageComment), data...)
+	data = writeTopDisclaimer(data)
+	_, err = i.writer.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write time intervals: %w", err)
+	}
+
+	logger := i.logger.WithCtxValues(ctx)
+	logger.WithValues(log.Kv{"time-intervals": len(intervals.TimeIntervals), "slo-mutes": len(intervals.SLOMutes)}).Infof("Alertmanager time intervals written")
+
+	return nil
+}
+
+func NewIOWriterRulesIndexJSONRepo(writer io.Writer, logger log.Logger) IOWriterRulesIndexJSONRepo {
+	return IOWriterRulesIndexJSONRepo{
+		writer: writer,
+		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "index-json"}),
+	}
+}
+
+// IOWriterRulesIndexJSONRepo knows to store a reverse lookup index of the generated
+// rules in an IOWriter in JSON format. The index maps every generated rule (recording
+// and alert) back to the SLO and spec source that generated it, so the rules can be
+// resolved to its owners (e.g a firing alert on an incident).
+type IOWriterRulesIndexJSONRepo struct {
+	writer io.Writer
+	logger log.Logger
+}
+
+// RulesIndex is the reverse lookup index of the generated rules.
+type RulesIndex struct {
+	Version string           `json:"version"`
+	Rules   []RulesIndexRule `json:"rules"`
+}
+
+// RulesIndexRule is a generated rule on the reverse lookup index. The rules can
+// be identified by the name and the `sloth_id` label (SLOID).
+type RulesIndexRule struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Source  string `json:"source"`
+	Service string `json:"service"`
+	SLO     string `json:"slo"`
+	SLOID   string `json:"slo_id"`
+}
+
+const (
+	rulesIndexTypeRecording = "recording"
+	rulesIndexTypeAlert     = "alert"
+)
+
+// StoreRulesIndex stores the index of the SLOs rules generated from the source, the
+// SLOs with their own source will use it instead.
+func (i IOWriterRulesIndexJSONRepo) StoreRulesIndex(ctx context.Context, source string, slos []StorageSLO) error {
+	index := RulesIndex{
+		Version: info.Version,
+		Rules:   []RulesIndexRule{},
+	}
+
+	for _, slo := range slos {
+		sloSource := source
+		if slo.Source != "" {
+			sloSource = slo.Source
+		}
+
+		rules := [][]rulefmt.Rule{slo.Rules.SLIErrorRecRules, slo.Rules.MetadataRecRules, slo.Rules.AlertRules}
+		for _, rs := range rules {
+			for _, r := range rs {
+				rule := RulesIndexRule{
+					Name:    r.Record,
+					Type:    rulesIndexTypeRecording,
+					Source:  sloSource,
+					Service: slo.SLO.Service,
+					SLO:     slo.SLO.Name,
+					SLOID:   slo.SLO.ID,
+				}
+				if r.Alert != "" {
+					rule.Name = r.Alert
+					rule.Type = rulesIndexTypeAlert
+				}
+				index.Rules = append(index.Rules, rule)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not format rules index: %w", err)
+	}
+
+	_, err = i.writer.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("could not write rules index: %w", err)
+	}
+
+	logger := i.logger.WithCtxValues(ctx)
+	logger.WithValues(log.Kv{"rules": len(index.Rules)}).Infof("Rules index written")
+
+	return nil
+}
+
+// GrafanaAlertRulesMeta is the Grafana specific data required to generate the Grafana managed
+// alert rules.
+type GrafanaAlertRulesMeta struct {
+	// DatasourceUID is the UID of the Prometheus datasource that will evaluate the alerts.
+	DatasourceUID string
+	// Folder is the Grafana folder where the alert rules will be stored.
+	Folder string
+	// OrgID is the Grafana organization ID of the alert rules.
+	OrgID int64
+	// Interval is the evaluation interval of the alert rule groups.
+	Interval time.Duration
+}
+
+func (g *GrafanaAlertRulesMeta) defaults() error {
+	if g.DatasourceUID == "" {
+		return fmt.Errorf("grafana datasource UID is required")
+	}
+
+	if g.Folder == "" {
+		g.Folder = "SLOs"
+	}
+
+	if g.OrgID == 0 {
+		g.OrgID = 1
+	}
+
+	if g.Interval == 0 {
+		g.Interval = time.Minute
+	}
+
+	return nil
+}
+
+func NewIOWriterGrafanaAlertRulesYAMLRepo(writer io.Writer, meta GrafanaAlertRulesMeta, logger log.Logger) (*IOWriterGrafanaAlertRulesYAMLRepo, error) {
+	err := meta.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Grafana alert rules metadata: %w", err)
+	}
+
+	return &IOWriterGrafanaAlertRulesYAMLRepo{
+		writer: writer,
+		meta:   meta,
+		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "grafana-yaml"}),
+	}, nil
+}
+
+// IOWriterGrafanaAlertRulesYAMLRepo knows to store the SLO alert rules in an IOWriter as Grafana
+// managed alert rules (unified alerting) provisioning YAML. The alerts are evaluated by Grafana
+// using a Prometheus datasource, so the SLI recording rules still need to be on Prometheus.
+type IOWriterGrafanaAlertRulesYAMLRepo struct {
+	writer io.Writer
+	meta   GrafanaAlertRulesMeta
+	logger log.Logger
+}
+
+func (i IOWriterGrafanaAlertRulesYAMLRepo) StoreSLOs(ctx context.Context, slos []StorageSLO) error {
+	rules := grafanaAlertRulesYAMLv2{APIVersion: 1}
+	for _, slo := range slos {
+		if len(slo.Rules.AlertRules) == 0 {
+			continue
+		}
+
+		group := grafanaAlertRuleGroupYAMLv2{
+			OrgID:    i.meta.OrgID,
+			Name:     fmt.Sprintf(alertsGroupNameFmt, slo.SLO.ID),
+			Folder:   i.meta.Folder,
+			Interval: prommodel.Duration(i.meta.Interval),
+		}
+
+		for _, r := range slo.Rules.AlertRules {
+			severity := r.Labels[sloSeverityLabelName]
+			// Grafana requires unique rule titles in a folder and a stable UID (max 40 chars).
+			id := fmt.Sprintf("%s/%s/%s", slo.SLO.ID, r.Alert, severity)
+			group.Rules = append(group.Rules, grafanaAlertRuleYAMLv2{
+				UID:          fmt.Sprintf("%x", sha1.Sum([]byte(id))),
+				Title:        fmt.Sprintf("%s (%s %s)", r.Alert, slo.SLO.ID, severity),
+				Condition:    grafanaAlertConditionRefID,
+				Data:         i.alertRuleData(r.Expr),
+				NoDataState:  "OK",
+				ExecErrState: "Error",
+				For:          r.For,
+				Labels:       r.Labels,
+				Annotations:  r.Annotations,
+			})
+		}
+
+		rules.Groups = append(rules.Groups, group)
+	}
+
+	// If we don't have anything to store, error so we can increase the reliability
+	// because maybe this was due to an unintended error (typos, misconfig, too many disable...).
+	if len(rules.Groups) == 0 {
+		return ErrNoSLORules
+	}
+
+	data, err := yaml.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("could not format Grafana alert rules: %w", err)
+	}
+
+	data = writeTopDisclaimer(data)
+	_, err = i.writer.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write Grafana alert rules: %w", err)
+	}
+
+	logger := i.logger.WithCtxValues(ctx)
+	logger.WithValues(log.Kv{"groups": len(rules.Groups)}).Infof("Grafana alert rules written")
+
+	return nil
+}
+
+const (
+	grafanaAlertQueryRefID     = "A"
+	grafanaAlertReduceRefID    = "B"
+	grafanaAlertConditionRefID = "C"
+	grafanaExpressionsUID      = "__expr__"
+)
+
+// alertRuleData returns the Grafana alert rule queries and expressions of a Prometheus alert
+// expression. Prometheus fires an alert for each series of the expression independent of its
+// value, so the expression series are reduced to their number of samples, and the condition
+// is a threshold on them, firing for each series of the expression (as Prometheus does).
+func (i IOWriterGrafanaAlertRulesYAMLRepo) alertRuleData(expr string) []grafanaAlertQueryYAMLv2 {
+	return []grafanaAlertQueryYAMLv2{
+		{
+			RefID:             grafanaAlertQueryRefID,
+			RelativeTimeRange: grafanaRelativeTimeRangeYAMLv2{From: 600},
+			DatasourceUID:     i.meta.DatasourceUID,
+			Model: grafanaAlertQueryModelYAMLv2{
+				RefID:   grafanaAlertQueryRefID,
+				Expr:    expr,
+				Instant: true,
+			},
+		},
+		{
+			RefID:         grafanaAlertReduceRefID,
+			DatasourceUID: grafanaExpressionsUID,
+			Model: grafanaAlertQueryModelYAMLv2{
+				RefID:      grafanaAlertReduceRefID,
+				Type:       "reduce",
+				Expression: grafanaAlertQueryRefID,
+				Reducer:    "count",
+			},
+		},
+		{
+			RefID:         grafanaAlertConditionRefID,
+			DatasourceUID: grafanaExpressionsUID,
+			Model: grafanaAlertQueryModelYAMLv2{
+				RefID:      grafanaAlertConditionRefID,
+				Type:       "threshold",
+				Expression: grafanaAlertReduceRefID,
+				Conditions: []grafanaAlertConditionYAMLv2{
+					{Evaluator: grafanaAlertEvaluatorYAMLv2{Type: "gt", Params: []float64{0}}},
+				},
+			},
+		},
+	}
+}
+
+// GrafanaDashboardMeta is the Grafana specific data required to generate the Grafana SLOs
+// dashboard.
+type GrafanaDashboardMeta struct {
+	// Title is the dashboard title, the dashboard UID is based on it.
+	Title string
+	// Tags are the dashboard tags.
+	Tags []string
+}
+
+func NewIOWriterGrafanaDashboardJSONRepo(writer io.Writer, meta GrafanaDashboardMeta, logger log.Logger) (*IOWriterGrafanaDashboardJSONRepo, error) {
+	if meta.Title == "" {
+		return nil, fmt.Errorf("invalid Grafana dashboard metadata: title is required")
+	}
+
+	return &IOWriterGrafanaDashboardJSONRepo{
+		writer: writer,
+		meta:   meta,
+		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "grafana-json"}),
+	}, nil
+}
+
+// IOWriterGrafanaDashboardJSONRepo knows to store the SLOs in an IOWriter as a Grafana dashboard
+// JSON model, with a row of SLI, error budget and burn rate panels per SLO. The panels query the
+// Sloth recording rules using a Prometheus datasource dashboard variable.
+type IOWriterGrafanaDashboardJSONRepo struct {
+	writer io.Writer
+	meta   GrafanaDashboardMeta
+	logger log.Logger
+}
+
+func (i IOWriterGrafanaDashboardJSONRepo) StoreSLOs(ctx context.Context, slos []StorageSLO) error {
+	if len(slos) == 0 {
+		return fmt.Errorf("slos required")
+	}
+
+	const (
+		datasource = "${datasource}"
+		rowHeight  = 8
+	)
+
+	// Grafana requires a stable UID (max 40 chars).
+	dashboard := grafanaDashboardJSON{
+		UID:           fmt.Sprintf("%x", sha1.Sum([]byte(i.meta.Title))),
+		Title:         i.meta.Title,
+		Tags:          i.meta.Tags,
+		Editable:      true,
+		SchemaVersion: 30,
+		Time:          grafanaDashboardTimeJSON{From: "now-7d", To: "now"},
+		Templating: grafanaDashboardTemplatingJSON{List: []grafanaDashboardVariableJSON{
+			{Name: "datasource", Label: "Datasource", Type: "datasource", Query: "prometheus"},
+		}},
+		Panels: []grafanaDashboardPanelJSON{},
+	}
+	if dashboard.Tags == nil {
+		dashboard.Tags = []string{}
+	}
+
+	id := 0
+	y := 0
+	newPanel := func(panelType, title, unit string, x, w int, targets ...grafanaDashboardTargetJSON) grafanaDashboardPanelJSON {
+		id++
+		return grafanaDashboardPanelJSON{
+			ID:         id,
+			Type:       panelType,
+			Title:      title,
+			Datasource: datasource,
+			GridPos:    grafanaDashboardGridPosJSON{X: x, Y: y, W: w, H: rowHeight},
+			FieldConfig: &grafanaDashboardFieldConfigJSON{
+				Defaults: grafanaDashboardFieldDefaultsJSON{Unit: unit},
+			},
+			Targets: targets,
+		}
+	}
+
+	for _, slo := range slos {
+		s := slo.SLO
+		filter := labelsToPromFilter(s.GetSLOIDPromLabels())
+
+		id++
+		dashboard.Panels = append(dashboard.Panels, grafanaDashboardPanelJSON{
+			ID:      id,
+			Type:    "row",
+			Title:   fmt.Sprintf("%s / %s (%g%% in %s)", s.Service, s.Name, s.Objective, timeDurationToPromStr(s.TimeWindow)),
+			GridPos: grafanaDashboardGridPosJSON{X: 0, Y: y, W: 24, H: 1},
+		})
+		y++
+
+		dashboard.Panels = append(dashboard.Panels,
+			newPanel("stat", "SLI", "percentunit", 0, 4, grafanaDashboardTargetJSON{
+				RefID: "A",
+				Expr:  fmt.Sprintf("1 - %s%s", s.GetSLIErrorMetric(s.TimeWindow), filter),
+			}),
+			newPanel("stat", "Error budget remaining", "percentunit", 4, 4, grafanaDashboardTargetJSON{
+				RefID: "A",
+				Expr:  fmt.Sprintf("%s%s", metricSLOPeriodErrorBudgetRemainingRatio, filter),
+			}),
+			newPanel("timeseries", "Error budget remaining", "percentunit", 8, 8, grafanaDashboardTargetJSON{
+				RefID:        "A",
+				Expr:         fmt.Sprintf("%s%s", metricSLOPeriodErrorBudgetRemainingRatio, filter),
+				LegendFormat: "Remaining",
+			}),
+			newPanel("timeseries", "Burn rate", "x", 16, 8,
+				grafanaDashboardTargetJSON{
+					RefID:        "A",
+					Expr:         fmt.Sprintf("%s%s", metricSLOCurrentBurnRateRatio, filter),
+					LegendFormat: "Current",
+				},
+				grafanaDashboardTargetJSON{
+					RefID:        "B",
+					Expr:         fmt.Sprintf("%s%s", metricSLOPeriodBurnRateRatio, filter),
+					LegendFormat: "Period",
+				},
+			),
+		)
+		y += rowHeight
+	}
+
+	enc := json.NewEncoder(i.writer)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(dashboard)
+	if err != nil {
+		return fmt.Errorf("could not write Grafana dashboard: %w", err)
+	}
+
+	logger := i.logger.WithCtxValues(ctx)
+	logger.WithValues(log.Kv{"slos": len(slos)}).Infof("Grafana dashboard written")
+
+	return nil
+}
+
 var disclaimer = fmt.Sprintf(`
 ---
 # Code generated by Sloth (%s): https://github.com/slok/sloth.
@@ -101,6 +624,138 @@ var disclaimer = fmt.Sprintf(`
 
 `, info.Version)
 
+const timeIntervalsUsageComment = `# Merge the time_intervals on the Alertmanager configuration, and add the SLO alerts
+# mute_time_intervals of sloth_slo_mute_time_intervals on your own routes that match
+# them, Alertmanager doesn't support the sloth_slo_mute_time_intervals key.
+`
+
+func NewIOWriterSLOMetricsOpenMetricsRepo(writer io.Writer, logger log.Logger) IOWriterSLOMetricsOpenMetricsRepo {
+	return IOWriterSLOMetricsOpenMetricsRepo{
+		writer: writer,
+		logger: logger.WithValues(log.Kv{"svc": "storage.IOWriter", "format": "openmetrics"}),
+	}
+}
+
+// IOWriterSLOMetricsOpenMetricsRepo knows to store the SLOs definitions (objectives, windows,
+// alerts and ownership labels) in an IOWriter as OpenMetrics text metrics, so external
+// inventory collectors can ingest the SLOs without parsing the specs.
+type IOWriterSLOMetricsOpenMetricsRepo struct {
+	writer io.Writer
+	logger log.Logger
+}
+
+func (i IOWriterSLOMetricsOpenMetricsRepo) StoreSLOs(ctx context.Context, slos []StorageSLO) error {
+	if len(slos) == 0 {
+		return fmt.Errorf("slos required")
+	}
+
+	type sample struct {
+		labels map[string]string
+		value  float64
+	}
+	type family struct {
+		name    string
+		typ     string
+		help    string
+		samples []sample
+	}
+
+	definition := family{name: "sloth_slo_definition", typ: "info", help: "The SLO definition, with the SLO labels."}
+	objective := family{name: "sloth_slo_objective_ratio", typ: "gauge", help: "The SLO objective ratio."}
+	timeWindow := family{name: "sloth_slo_time_window_seconds", typ: "gauge", help: "The SLO time window."}
+	reportingWindow := family{name: "sloth_slo_reporting_window_seconds", typ: "gauge", help: "The SLO extra reporting time windows."}
+	alertEnabled := family{name: "sloth_slo_alert_enabled", typ: "gauge", help: "If the SLO alert of the severity is enabled."}
+
+	for _, s := range slos {
+		slo := s.SLO
+		idLabels := slo.GetSLOIDPromLabels()
+
+		definition.samples = append(definition.samples, sample{
+			labels: mergeLabels(slo.Labels, idLabels, map[string]string{
+				"sloth_description": slo.Description,
+				"sloth_source":      s.Source,
+			}),
+			value: 1,
+		})
+		// Round to avoid float precision errors on the percent to ratio conversion (e.g 99.9).
+		objective.samples = append(objective.samples, sample{labels: idLabels, value: math.Round(slo.Objective*1e6) / 1e8})
+		timeWindow.samples = append(timeWindow.samples, sample{labels: idLabels, value: slo.TimeWindow.Seconds()})
+		for _, w := range slo.getReportingWindows() {
+			reportingWindow.samples = append(reportingWindow.samples, sample{
+				labels: mergeLabels(idLabels, map[string]string{sloWindowLabelName: timeDurationToPromStr(w)}),
+				value:  w.Seconds(),
+			})
+		}
+		for _, a := range []struct {
+			severity alert.Severity
+			meta     AlertMeta
+		}{
+			{severity: alert.PageAlertSeverity, meta: slo.PageAlertMeta},
+			{severity: alert.TicketAlertSeverity, meta: slo.TicketAlertMeta},
+		} {
+			enabled := 1.0
+			if a.meta.Disable {
+				enabled = 0
+			}
+			alertEnabled.samples = append(alertEnabled.samples, sample{
+				labels: mergeLabels(idLabels, map[string]string{sloSeverityLabelName: a.severity.String()}),
+				value:  enabled,
+			})
+		}
+	}
+
+	var b bytes.Buffer
+	for _, f := range []family{definition, objective, timeWindow, reportingWindow, alertEnabled} {
+		if len(f.samples) == 0 {
+			continue
+		}
+
+		// OpenMetrics info metric samples have the `_info` suffix.
+		sampleName := f.name
+		if f.typ == "info" {
+			sampleName += "_info"
+		}
+
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		for _, s := range f.samples {
+			fmt.Fprintf(&b, "%s%s %s\n", sampleName, openMetricsLabels(s.labels), strconv.FormatFloat(s.value, 'f', -1, 64))
+		}
+	}
+	b.WriteString("# EOF\n")
+
+	_, err := i.writer.Write(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("could not write OpenMetrics: %w", err)
+	}
+
+	logger := i.logger.WithCtxValues(ctx)
+	logger.WithValues(log.Kv{"slos": len(slos)}).Infof("SLO OpenMetrics written")
+
+	return nil
+}
+
+// openMetricsLabels returns the OpenMetrics text format of the labels, sorted by name.
+func openMetricsLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(names))
+	for _, k := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, escaper.Replace(labels[k])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
 func writeTopDisclaimer(bs []byte) []byte {
 	return append([]byte(disclaimer), bs...)
 }
@@ -111,8 +766,133 @@ type ruleGroupsYAMLv2 struct {
 	Groups []ruleGroupYAMLv2 `yaml:"groups"`
 }
 
+type inhibitRulesYAMLv2 struct {
+	InhibitRules []InhibitRule `yaml:"inhibit_rules"`
+}
+
+type timeIntervalsYAMLv2 struct {
+	TimeIntervals []TimeInterval         `yaml:"time_intervals"`
+	SLOMutes      []SLOMuteTimeIntervals `yaml:"sloth_slo_mute_time_intervals"`
+}
+
 type ruleGroupYAMLv2 struct {
-	Name     string             `yaml:"name"`
-	Interval prommodel.Duration `yaml:"interval,omitempty"`
-	Rules    []rulefmt.Rule     `yaml:"rules"`
+	Name                    string             `yaml:"name"`
+	Interval                prommodel.Duration `yaml:"interval,omitempty"`
+	PartialResponseStrategy string             `yaml:"partial_response_strategy,omitempty"`
+	Labels                  map[string]string  `yaml:"labels,omitempty"`
+	Rules                   []rulefmt.Rule     `yaml:"rules"`
+}
+
+type grafanaAlertRulesYAMLv2 struct {
+	APIVersion int                           `yaml:"apiVersion"`
+	Groups     []grafanaAlertRuleGroupYAMLv2 `yaml:"groups"`
+}
+
+type grafanaAlertRuleGroupYAMLv2 struct {
+	OrgID    int64                    `yaml:"orgId"`
+	Name     string                   `yaml:"name"`
+	Folder   string                   `yaml:"folder"`
+	Interval prommodel.Duration       `yaml:"interval"`
+	Rules    []grafanaAlertRuleYAMLv2 `yaml:"rules"`
+}
+
+type grafanaAlertRuleYAMLv2 struct {
+	UID          string                    `yaml:"uid"`
+	Title        string                    `yaml:"title"`
+	Condition    string                    `yaml:"condition"`
+	Data         []grafanaAlertQueryYAMLv2 `yaml:"data"`
+	NoDataState  string                    `yaml:"noDataState"`
+	ExecErrState string                    `yaml:"execErrState"`
+	For          prommodel.Duration        `yaml:"for,omitempty"`
+	Labels       map[string]string         `yaml:"labels,omitempty"`
+	Annotations  map[string]string         `yaml:"annotations,omitempty"`
+}
+
+type grafanaAlertQueryYAMLv2 struct {
+	RefID             string                         `yaml:"refId"`
+	RelativeTimeRange grafanaRelativeTimeRangeYAMLv2 `yaml:"relativeTimeRange"`
+	DatasourceUID     string                         `yaml:"datasourceUid"`
+	Model             grafanaAlertQueryModelYAMLv2   `yaml:"model"`
+}
+
+type grafanaRelativeTimeRangeYAMLv2 struct {
+	From int64 `yaml:"from"`
+	To   int64 `yaml:"to"`
+}
+
+type grafanaAlertQueryModelYAMLv2 struct {
+	RefID      string                        `yaml:"refId"`
+	Expr       string                        `yaml:"expr,omitempty"`
+	Instant    bool                          `yaml:"instant,omitempty"`
+	Type       string                        `yaml:"type,omitempty"`
+	Expression string                        `yaml:"expression,omitempty"`
+	Reducer    string                        `yaml:"reducer,omitempty"`
+	Conditions []grafanaAlertConditionYAMLv2 `yaml:"conditions,omitempty"`
+}
+
+type grafanaAlertConditionYAMLv2 struct {
+	Evaluator grafanaAlertEvaluatorYAMLv2 `yaml:"evaluator"`
+}
+
+type grafanaAlertEvaluatorYAMLv2 struct {
+	Type   string    `yaml:"type"`
+	Params []float64 `yaml:"params"`
+}
+
+type grafanaDashboardJSON struct {
+	UID           string                         `json:"uid"`
+	Title         string                         `json:"title"`
+	Tags          []string                       `json:"tags"`
+	Editable      bool                           `json:"editable"`
+	SchemaVersion int                            `json:"schemaVersion"`
+	Time          grafanaDashboardTimeJSON       `json:"time"`
+	Templating    grafanaDashboardTemplatingJSON `json:"templating"`
+	Panels        []grafanaDashboardPanelJSON    `json:"panels"`
+}
+
+type grafanaDashboardTimeJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type grafanaDashboardTemplatingJSON struct {
+	List []grafanaDashboardVariableJSON `json:"list"`
+}
+
+type grafanaDashboardVariableJSON struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+type grafanaDashboardPanelJSON struct {
+	ID          int                              `json:"id"`
+	Type        string                           `json:"type"`
+	Title       string                           `json:"title"`
+	Datasource  string                           `json:"datasource,omitempty"`
+	GridPos     grafanaDashboardGridPosJSON      `json:"gridPos"`
+	FieldConfig *grafanaDashboardFieldConfigJSON `json:"fieldConfig,omitempty"`
+	Targets     []grafanaDashboardTargetJSON     `json:"targets,omitempty"`
+}
+
+type grafanaDashboardGridPosJSON struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type grafanaDashboardFieldConfigJSON struct {
+	Defaults grafanaDashboardFieldDefaultsJSON `json:"defaults"`
+}
+
+type grafanaDashboardFieldDefaultsJSON struct {
+	Unit string `json:"unit"`
+}
+
+type grafanaDashboardTargetJSON struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
 }
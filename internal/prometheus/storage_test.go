@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/prometheus/prometheus/pkg/rulefmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/slok/sloth/internal/log"
 	"github.com/slok/sloth/internal/prometheus"
@@ -14,6 +16,7 @@ import (
 
 func TestIOWriterGroupedRulesYAMLRepoStore(t *testing.T) {
 	tests := map[string]struct {
+		meta    prometheus.RuleGroupsMeta
 		slos    []prometheus.StorageSLO
 		expYAML string
 		expErr  bool
@@ -121,6 +124,279 @@ groups:
 `,
 		},
 
+		"Having a partial response strategy should render the Thanos ruler rule groups correctly.": {
+			meta: prometheus.RuleGroupsMeta{PartialResponseStrategy: "warn"},
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   "test-expr",
+							},
+						},
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert: "testAlert",
+								Expr:  "test-expr",
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+groups:
+- name: sloth-slo-sli-recordings-test1
+  partial_response_strategy: warn
+  rules:
+  - record: test:record
+    expr: test-expr
+- name: sloth-slo-alerts-test1
+  partial_response_strategy: warn
+  rules:
+  - alert: testAlert
+    expr: test-expr
+`,
+		},
+
+		"Having group labels should render the rule groups with the labels, the global ones with preference.": {
+			meta: prometheus.RuleGroupsMeta{Labels: map[string]string{"tenant": "global", "team": "sre"}},
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1", GroupLabels: map[string]string{"tenant": "slo", "env": "prod"}},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   "test-expr",
+							},
+						},
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert: "testAlert",
+								Expr:  "test-expr",
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+groups:
+- name: sloth-slo-sli-recordings-test1
+  labels:
+    env: prod
+    team: sre
+    tenant: global
+  rules:
+  - record: test:record
+    expr: test-expr
+- name: sloth-slo-alerts-test1
+  labels:
+    env: prod
+    team: sre
+    tenant: global
+  rules:
+  - alert: testAlert
+    expr: test-expr
+`,
+		},
+
+		"Having rule group intervals should render the rule groups with the intervals, the SLO ones with preference.": {
+			meta: prometheus.RuleGroupsMeta{Intervals: prometheus.RuleGroupIntervals{SLIRecordings: time.Minute, MetaRecordings: 5 * time.Minute, Alerts: time.Minute}},
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1", RuleGroupIntervals: prometheus.RuleGroupIntervals{SLIRecordings: 2 * time.Minute}},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   "test-expr",
+							},
+						},
+						MetadataRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record2",
+								Expr:   "test-expr2",
+							},
+						},
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert: "testAlert",
+								Expr:  "test-expr",
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+groups:
+- name: sloth-slo-sli-recordings-test1
+  interval: 2m
+  rules:
+  - record: test:record
+    expr: test-expr
+- name: sloth-slo-meta-recordings-test1
+  interval: 5m
+  rules:
+  - record: test:record2
+    expr: test-expr2
+- name: sloth-slo-alerts-test1
+  interval: 1m
+  rules:
+  - alert: testAlert
+    expr: test-expr
+`,
+		},
+
+		"Having SLI window groups should render the SLI recording rules of all the SLOs grouped by window with the window intervals.": {
+			meta: prometheus.RuleGroupsMeta{
+				Intervals: prometheus.RuleGroupIntervals{SLIRecordings: time.Minute},
+				SLIWindowGroups: prometheus.SLIWindowGroups{
+					Enabled:   true,
+					Intervals: map[time.Duration]time.Duration{30 * 24 * time.Hour: 5 * time.Minute},
+				},
+			},
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record30d",
+								Expr:   "test1-expr30d",
+								Labels: map[string]string{"sloth_window": "30d"},
+							},
+							{
+								Record: "test:record5m",
+								Expr:   "test1-expr5m",
+								Labels: map[string]string{"sloth_window": "5m"},
+							},
+							{
+								Record: "test:record1h",
+								Expr:   "test1-expr1h",
+								Labels: map[string]string{"sloth_window": "1h"},
+							},
+							{
+								Record: "test:record",
+								Expr:   "test1-expr",
+							},
+						},
+					},
+				},
+				{
+					SLO: prometheus.SLO{ID: "test2-5m"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record5m",
+								Expr:   "test2-expr5m",
+								Labels: map[string]string{"sloth_window": "5m"},
+							},
+							{
+								Record: "test:record30d",
+								Expr:   "test2-expr30d",
+								Labels: map[string]string{"sloth_window": "30d"},
+							},
+						},
+					},
+				},
+				{
+					SLO: prometheus.SLO{ID: "test3", GroupLabels: map[string]string{"team": "a"}},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record5m",
+								Expr:   "test3-expr5m",
+								Labels: map[string]string{"sloth_window": "5m"},
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+groups:
+- name: sloth-slo-sli-recordings-test1
+  interval: 1m
+  rules:
+  - record: test:record
+    expr: test1-expr
+- name: sloth-slo-sli-window-recordings-5m
+  interval: 1m
+  rules:
+  - record: test:record5m
+    expr: test1-expr5m
+    labels:
+      sloth_window: 5m
+  - record: test:record5m
+    expr: test2-expr5m
+    labels:
+      sloth_window: 5m
+- name: sloth-slo-sli-window-recordings-5m-2
+  interval: 1m
+  labels:
+    team: a
+  rules:
+  - record: test:record5m
+    expr: test3-expr5m
+    labels:
+      sloth_window: 5m
+- name: sloth-slo-sli-window-recordings-1h
+  interval: 1m
+  rules:
+  - record: test:record1h
+    expr: test1-expr1h
+    labels:
+      sloth_window: 1h
+- name: sloth-slo-sli-window-recordings-30d
+  interval: 5m
+  rules:
+  - record: test:record30d
+    expr: test1-expr30d
+    labels:
+      sloth_window: 30d
+  - record: test:record30d
+    expr: test2-expr30d
+    labels:
+      sloth_window: 30d
+`,
+		},
+
+		"Having SLI window groups with an invalid SLI rule window should fail.": {
+			meta: prometheus.RuleGroupsMeta{SLIWindowGroups: prometheus.SLIWindowGroups{Enabled: true}},
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{
+							{
+								Record: "test:record",
+								Expr:   "test-expr",
+								Labels: map[string]string{"sloth_window": "5 minutes"},
+							},
+						},
+					},
+				},
+			},
+			expErr: true,
+		},
+
 		"Having a multiple SLO alert and recording rules should render correctly.": {
 			slos: []prometheus.StorageSLO{
 				{
@@ -263,12 +539,639 @@ groups:
 			assert := assert.New(t)
 
 			var gotYAML bytes.Buffer
-			repo := prometheus.NewIOWriterGroupedRulesYAMLRepo(&gotYAML, log.Noop)
+			repo := prometheus.NewIOWriterGroupedRulesYAMLRepo(&gotYAML, test.meta, log.Noop)
+			err := repo.StoreSLOs(context.TODO(), test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expYAML, gotYAML.String())
+			}
+		})
+	}
+}
+
+func TestIOWriterRulesIndexJSONRepoStore(t *testing.T) {
+	tests := map[string]struct {
+		source  string
+		slos    []prometheus.StorageSLO
+		expJSON string
+		expErr  bool
+	}{
+		"Having 0 SLO rules should store an empty index.": {
+			source: "test.yml",
+			slos:   []prometheus.StorageSLO{},
+			expJSON: `{
+  "version": "dev",
+  "rules": []
+}
+`,
+		},
+
+		"Having multiple SLOs with rules should index all the rules.": {
+			source: "slos/test.yml",
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "svc-testa", Name: "testa", Service: "svc"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{{Record: "test:record-a1", Expr: "test-expr-a1"}},
+						MetadataRecRules: []rulefmt.Rule{{Record: "test:record-a2", Expr: "test-expr-a2"}},
+						AlertRules:       []rulefmt.Rule{{Alert: "testAlertA1", Expr: "test-expr-a1"}},
+					},
+				},
+				{
+					SLO: prometheus.SLO{ID: "svc-testb", Name: "testb", Service: "svc"},
+					Rules: prometheus.SLORules{
+						AlertRules: []rulefmt.Rule{{Alert: "testAlertB1", Expr: "test-expr-b1"}},
+					},
+				},
+			},
+			expJSON: `{
+  "version": "dev",
+  "rules": [
+    {
+      "name": "test:record-a1",
+      "type": "recording",
+      "source": "slos/test.yml",
+      "service": "svc",
+      "slo": "testa",
+      "slo_id": "svc-testa"
+    },
+    {
+      "name": "test:record-a2",
+      "type": "recording",
+      "source": "slos/test.yml",
+      "service": "svc",
+      "slo": "testa",
+      "slo_id": "svc-testa"
+    },
+    {
+      "name": "testAlertA1",
+      "type": "alert",
+      "source": "slos/test.yml",
+      "service": "svc",
+      "slo": "testa",
+      "slo_id": "svc-testa"
+    },
+    {
+      "name": "testAlertB1",
+      "type": "alert",
+      "source": "slos/test.yml",
+      "service": "svc",
+      "slo": "testb",
+      "slo_id": "svc-testb"
+    }
+  ]
+}
+`,
+		},
+
+		"Having SLOs with their own source should index the rules with the SLO source.": {
+			source: "slos",
+			slos: []prometheus.StorageSLO{
+				{
+					SLO:    prometheus.SLO{ID: "svc-testa", Name: "testa", Service: "svc"},
+					Source: "slos/a.yml",
+					Rules: prometheus.SLORules{
+						AlertRules: []rulefmt.Rule{{Alert: "testAlertA1", Expr: "test-expr-a1"}},
+					},
+				},
+				{
+					SLO: prometheus.SLO{ID: "svc-testb", Name: "testb", Service: "svc"},
+					Rules: prometheus.SLORules{
+						AlertRules: []rulefmt.Rule{{Alert: "testAlertB1", Expr: "test-expr-b1"}},
+					},
+				},
+			},
+			expJSON: `{
+  "version": "dev",
+  "rules": [
+    {
+      "name": "testAlertA1",
+      "type": "alert",
+      "source": "slos/a.yml",
+      "service": "svc",
+      "slo": "testa",
+      "slo_id": "svc-testa"
+    },
+    {
+      "name": "testAlertB1",
+      "type": "alert",
+      "source": "slos",
+      "service": "svc",
+      "slo": "testb",
+      "slo_id": "svc-testb"
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var gotJSON bytes.Buffer
+			repo := prometheus.NewIOWriterRulesIndexJSONRepo(&gotJSON, log.Noop)
+			err := repo.StoreRulesIndex(context.TODO(), test.source, test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expJSON, gotJSON.String())
+			}
+		})
+	}
+}
+
+func TestIOWriterGrafanaAlertRulesYAMLRepoStore(t *testing.T) {
+	tests := map[string]struct {
+		meta    prometheus.GrafanaAlertRulesMeta
+		slos    []prometheus.StorageSLO
+		expYAML string
+		expErr  bool
+	}{
+		"Having 0 SLO alert rules should fail.": {
+			meta: prometheus.GrafanaAlertRulesMeta{DatasourceUID: "test-ds"},
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "test1"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{{Record: "test:record", Expr: "test-expr"}},
+					},
+				},
+			},
+			expErr: true,
+		},
+
+		"Having multiple SLOs with alert rules should render the Grafana alert rules correctly.": {
+			meta: prometheus.GrafanaAlertRulesMeta{DatasourceUID: "test-ds", Folder: "test-folder"},
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{ID: "testa"},
+					Rules: prometheus.SLORules{
+						SLIErrorRecRules: []rulefmt.Rule{{Record: "test:record-a1", Expr: "test-expr-a1"}},
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert:       "testAlertA1",
+								Expr:        "test-expr-a1",
+								Labels:      map[string]string{"sloth_severity": "page"},
+								Annotations: map[string]string{"test-annot": "a-1"},
+							},
+						},
+					},
+				},
+				{
+					SLO: prometheus.SLO{ID: "testb"},
+					Rules: prometheus.SLORules{
+						AlertRules: []rulefmt.Rule{
+							{
+								Alert:  "testAlertB1",
+								Expr:   "test-expr-b1",
+								Labels: map[string]string{"sloth_severity": "ticket"},
+							},
+						},
+					},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+apiVersion: 1
+groups:
+- orgId: 1
+  name: sloth-slo-alerts-testa
+  folder: test-folder
+  interval: 1m
+  rules:
+  - uid: 09c5161557bbbd9a5930d450400c3e2d9aeba863
+    title: testAlertA1 (testa page)
+    condition: C
+    data:
+    - refId: A
+      relativeTimeRange:
+        from: 600
+        to: 0
+      datasourceUid: test-ds
+      model:
+        refId: A
+        expr: test-expr-a1
+        instant: true
+    - refId: B
+      relativeTimeRange:
+        from: 0
+        to: 0
+      datasourceUid: __expr__
+      model:
+        refId: B
+        type: reduce
+        expression: A
+        reducer: count
+    - refId: C
+      relativeTimeRange:
+        from: 0
+        to: 0
+      datasourceUid: __expr__
+      model:
+        refId: C
+        type: threshold
+        expression: B
+        conditions:
+        - evaluator:
+            type: gt
+            params:
+            - 0
+    noDataState: OK
+    execErrState: Error
+    labels:
+      sloth_severity: page
+    annotations:
+      test-annot: a-1
+- orgId: 1
+  name: sloth-slo-alerts-testb
+  folder: test-folder
+  interval: 1m
+  rules:
+  - uid: 89eedecf8e6563c3a7a265c2c21555183f766855
+    title: testAlertB1 (testb ticket)
+    condition: C
+    data:
+    - refId: A
+      relativeTimeRange:
+        from: 600
+        to: 0
+      datasourceUid: test-ds
+      model:
+        refId: A
+        expr: test-expr-b1
+        instant: true
+    - refId: B
+      relativeTimeRange:
+        from: 0
+        to: 0
+      datasourceUid: __expr__
+      model:
+        refId: B
+        type: reduce
+        expression: A
+        reducer: count
+    - refId: C
+      relativeTimeRange:
+        from: 0
+        to: 0
+      datasourceUid: __expr__
+      model:
+        refId: C
+        type: threshold
+        expression: B
+        conditions:
+        - evaluator:
+            type: gt
+            params:
+            - 0
+    noDataState: OK
+    execErrState: Error
+    labels:
+      sloth_severity: ticket
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var gotYAML bytes.Buffer
+			repo, err := prometheus.NewIOWriterGrafanaAlertRulesYAMLRepo(&gotYAML, test.meta, log.Noop)
+			require.NoError(err)
+			err = repo.StoreSLOs(context.TODO(), test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expYAML, gotYAML.String())
+			}
+		})
+	}
+}
+
+func TestIOWriterGrafanaDashboardJSONRepoStore(t *testing.T) {
+	tests := map[string]struct {
+		meta    prometheus.GrafanaDashboardMeta
+		slos    []prometheus.StorageSLO
+		expJSON string
+		expErr  bool
+	}{
+		"Having 0 SLOs should fail.": {
+			meta:   prometheus.GrafanaDashboardMeta{Title: "test"},
+			slos:   []prometheus.StorageSLO{},
+			expErr: true,
+		},
+
+		"Having SLOs should render the Grafana dashboard correctly.": {
+			meta: prometheus.GrafanaDashboardMeta{Title: "SLOs / svc01", Tags: []string{"sloth"}},
+			slos: []prometheus.StorageSLO{
+				{SLO: prometheus.SLO{ID: "svc01-slo1", Name: "slo1", Service: "svc01", Objective: 99.9, TimeWindow: 30 * 24 * time.Hour}},
+			},
+			expJSON: `{
+  "uid": "42083d63f5ce6d6aa0de358eebc6dd1effcece6b",
+  "title": "SLOs / svc01",
+  "tags": [
+    "sloth"
+  ],
+  "editable": true,
+  "schemaVersion": 30,
+  "time": {
+    "from": "now-7d",
+    "to": "now"
+  },
+  "templating": {
+    "list": [
+      {
+        "name": "datasource",
+        "label": "Datasource",
+        "type": "datasource",
+        "query": "prometheus"
+      }
+    ]
+  },
+  "panels": [
+    {
+      "id": 1,
+      "type": "row",
+      "title": "svc01 / slo1 (99.9% in 30d)",
+      "gridPos": {
+        "x": 0,
+        "y": 0,
+        "w": 24,
+        "h": 1
+      }
+    },
+    {
+      "id": 2,
+      "type": "stat",
+      "title": "SLI",
+      "datasource": "${datasource}",
+      "gridPos": {
+        "x": 0,
+        "y": 1,
+        "w": 4,
+        "h": 8
+      },
+      "fieldConfig": {
+        "defaults": {
+          "unit": "percentunit"
+        }
+      },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "1 - slo:sli_error:ratio_rate30d{sloth_id=\"svc01-slo1\", sloth_service=\"svc01\", sloth_slo=\"slo1\"}"
+        }
+      ]
+    },
+    {
+      "id": 3,
+      "type": "stat",
+      "title": "Error budget remaining",
+      "datasource": "${datasource}",
+      "gridPos": {
+        "x": 4,
+        "y": 1,
+        "w": 4,
+        "h": 8
+      },
+      "fieldConfig": {
+        "defaults": {
+          "unit": "percentunit"
+        }
+      },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "slo:period_error_budget_remaining:ratio{sloth_id=\"svc01-slo1\", sloth_service=\"svc01\", sloth_slo=\"slo1\"}"
+        }
+      ]
+    },
+    {
+      "id": 4,
+      "type": "timeseries",
+      "title": "Error budget remaining",
+      "datasource": "${datasource}",
+      "gridPos": {
+        "x": 8,
+        "y": 1,
+        "w": 8,
+        "h": 8
+      },
+      "fieldConfig": {
+        "defaults": {
+          "unit": "percentunit"
+        }
+      },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "slo:period_error_budget_remaining:ratio{sloth_id=\"svc01-slo1\", sloth_service=\"svc01\", sloth_slo=\"slo1\"}",
+          "legendFormat": "Remaining"
+        }
+      ]
+    },
+    {
+      "id": 5,
+      "type": "timeseries",
+      "title": "Burn rate",
+      "datasource": "${datasource}",
+      "gridPos": {
+        "x": 16,
+        "y": 1,
+        "w": 8,
+        "h": 8
+      },
+      "fieldConfig": {
+        "defaults": {
+          "unit": "x"
+        }
+      },
+      "targets": [
+        {
+          "refId": "A",
+          "expr": "slo:current_burn_rate:ratio{sloth_id=\"svc01-slo1\", sloth_service=\"svc01\", sloth_slo=\"slo1\"}",
+          "legendFormat": "Current"
+        },
+        {
+          "refId": "B",
+          "expr": "slo:period_burn_rate:ratio{sloth_id=\"svc01-slo1\", sloth_service=\"svc01\", sloth_slo=\"slo1\"}",
+          "legendFormat": "Period"
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var gotJSON bytes.Buffer
+			repo, err := prometheus.NewIOWriterGrafanaDashboardJSONRepo(&gotJSON, test.meta, log.Noop)
+			require.NoError(err)
+			err = repo.StoreSLOs(context.TODO(), test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expJSON, gotJSON.String())
+			}
+		})
+	}
+}
+
+func TestIOWriterSLOMetricsOpenMetricsRepoStore(t *testing.T) {
+	tests := map[string]struct {
+		slos       []prometheus.StorageSLO
+		expMetrics string
+		expErr     bool
+	}{
+		"Having 0 SLOs should fail.": {
+			slos:   []prometheus.StorageSLO{},
+			expErr: true,
+		},
+
+		"Having SLOs should render the SLO metrics correctly.": {
+			slos: []prometheus.StorageSLO{
+				{
+					SLO: prometheus.SLO{
+						ID:               "svc01-slo1",
+						Name:             "slo1",
+						Service:          "svc01",
+						Description:      `Some "slo" description.`,
+						Objective:        99.9,
+						TimeWindow:       30 * 24 * time.Hour,
+						ReportingWindows: []time.Duration{7 * 24 * time.Hour},
+						Labels:           map[string]string{"owner": "team-a"},
+						TicketAlertMeta:  prometheus.AlertMeta{Disable: true},
+					},
+					Source: "slos/svc01.yml",
+				},
+				{
+					SLO: prometheus.SLO{
+						ID:         "svc02-slo1",
+						Name:       "slo1",
+						Service:    "svc02",
+						Objective:  95,
+						TimeWindow: 28 * 24 * time.Hour,
+					},
+					Source: "slos/svc02.yml",
+				},
+			},
+			expMetrics: `# TYPE sloth_slo_definition info
+# HELP sloth_slo_definition The SLO definition, with the SLO labels.
+sloth_slo_definition_info{owner="team-a",sloth_description="Some \"slo\" description.",sloth_id="svc01-slo1",sloth_service="svc01",sloth_slo="slo1",sloth_source="slos/svc01.yml"} 1
+sloth_slo_definition_info{sloth_description="",sloth_id="svc02-slo1",sloth_service="svc02",sloth_slo="slo1",sloth_source="slos/svc02.yml"} 1
+# TYPE sloth_slo_objective_ratio gauge
+# HELP sloth_slo_objective_ratio The SLO objective ratio.
+sloth_slo_objective_ratio{sloth_id="svc01-slo1",sloth_service="svc01",sloth_slo="slo1"} 0.999
+sloth_slo_objective_ratio{sloth_id="svc02-slo1",sloth_service="svc02",sloth_slo="slo1"} 0.95
+# TYPE sloth_slo_time_window_seconds gauge
+# HELP sloth_slo_time_window_seconds The SLO time window.
+sloth_slo_time_window_seconds{sloth_id="svc01-slo1",sloth_service="svc01",sloth_slo="slo1"} 2592000
+sloth_slo_time_window_seconds{sloth_id="svc02-slo1",sloth_service="svc02",sloth_slo="slo1"} 2419200
+# TYPE sloth_slo_reporting_window_seconds gauge
+# HELP sloth_slo_reporting_window_seconds The SLO extra reporting time windows.
+sloth_slo_reporting_window_seconds{sloth_id="svc01-slo1",sloth_service="svc01",sloth_slo="slo1",sloth_window="1w"} 604800
+# TYPE sloth_slo_alert_enabled gauge
+# HELP sloth_slo_alert_enabled If the SLO alert of the severity is enabled.
+sloth_slo_alert_enabled{sloth_id="svc01-slo1",sloth_service="svc01",sloth_severity="page",sloth_slo="slo1"} 1
+sloth_slo_alert_enabled{sloth_id="svc01-slo1",sloth_service="svc01",sloth_severity="ticket",sloth_slo="slo1"} 0
+sloth_slo_alert_enabled{sloth_id="svc02-slo1",sloth_service="svc02",sloth_severity="page",sloth_slo="slo1"} 1
+sloth_slo_alert_enabled{sloth_id="svc02-slo1",sloth_service="svc02",sloth_severity="ticket",sloth_slo="slo1"} 1
+# EOF
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var gotMetrics bytes.Buffer
+			repo := prometheus.NewIOWriterSLOMetricsOpenMetricsRepo(&gotMetrics, log.Noop)
 			err := repo.StoreSLOs(context.TODO(), test.slos)
 
 			if test.expErr {
 				assert.Error(err)
 			} else if assert.NoError(err) {
+				assert.Equal(test.expMetrics, gotMetrics.String())
+			}
+		})
+	}
+}
+
+func TestIOWriterTimeIntervalsYAMLRepoStore(t *testing.T) {
+	tests := map[string]struct {
+		intervals prometheus.AlertmanagerTimeIntervals
+		expYAML   string
+	}{
+		"Having time intervals should render the time intervals and the SLO mute time intervals, without routes.": {
+			intervals: prometheus.AlertmanagerTimeIntervals{
+				TimeIntervals: []prometheus.TimeInterval{
+					{
+						Name: "weekly-maintenance",
+						TimeIntervals: []prometheus.TimeIntervalSpec{{
+							Times:    []prometheus.TimeRange{{StartTime: "02:00", EndTime: "04:00"}},
+							Weekdays: []string{"sunday"},
+							Location: "Europe/Madrid",
+						}},
+					},
+				},
+				SLOMutes: []prometheus.SLOMuteTimeIntervals{
+					{Matchers: []string{`sloth_id="svc-slo1"`}, MuteTimeIntervals: []string{"weekly-maintenance"}},
+				},
+			},
+			expYAML: `
+---
+# Code generated by Sloth (dev): https://github.com/slok/sloth.
+# DO NOT EDIT.
+
+# Merge the time_intervals on the Alertmanager configuration, and add the SLO alerts
+# mute_time_intervals of sloth_slo_mute_time_intervals on your own routes that match
+# them, Alertmanager doesn't support the sloth_slo_mute_time_intervals key.
+time_intervals:
+- name: weekly-maintenance
+  time_intervals:
+  - times:
+    - start_time: "02:00"
+      end_time: "04:00"
+    weekdays:
+    - sunday
+    location: Europe/Madrid
+sloth_slo_mute_time_intervals:
+- matchers:
+  - sloth_id="svc-slo1"
+  mute_time_intervals:
+  - weekly-maintenance
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var gotYAML bytes.Buffer
+			repo := prometheus.NewIOWriterTimeIntervalsYAMLRepo(&gotYAML, log.Noop)
+			err := repo.StoreTimeIntervals(context.TODO(), test.intervals)
+
+			if assert.NoError(err) {
 				assert.Equal(test.expYAML, gotYAML.String())
 			}
 		})
@@ -0,0 +1,267 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a recurring time window (e.g a maintenance window or the out of
+// business hours) where the SLO alerts are muted by Alertmanager.
+type MaintenanceWindow struct {
+	Name        string
+	Times       []string
+	Weekdays    []string
+	DaysOfMonth []string
+	Months      []string
+	Location    string
+}
+
+// TimeInterval is an Alertmanager named time interval.
+type TimeInterval struct {
+	Name          string             `yaml:"name"`
+	TimeIntervals []TimeIntervalSpec `yaml:"time_intervals"`
+}
+
+// TimeIntervalSpec is the specification of an Alertmanager time interval.
+type TimeIntervalSpec struct {
+	Times       []TimeRange `yaml:"times,omitempty"`
+	Weekdays    []string    `yaml:"weekdays,omitempty"`
+	DaysOfMonth []string    `yaml:"days_of_month,omitempty"`
+	Months      []string    `yaml:"months,omitempty"`
+	Location    string      `yaml:"location,omitempty"`
+}
+
+// TimeRange is an Alertmanager time interval range of the day.
+type TimeRange struct {
+	StartTime string `yaml:"start_time"`
+	EndTime   string `yaml:"end_time"`
+}
+
+// SLOMuteTimeIntervals are the matchers of an SLO alerts and the time intervals that should
+// mute them. Sloth doesn't know the Alertmanager routing tree, so these are not routes, they
+// should be set as `mute_time_intervals` on the Alertmanager routes that match the SLO alerts.
+type SLOMuteTimeIntervals struct {
+	Matchers          []string `yaml:"matchers"`
+	MuteTimeIntervals []string `yaml:"mute_time_intervals"`
+}
+
+// AlertmanagerTimeIntervals are the Alertmanager time intervals and the SLO alerts that
+// should be muted on them.
+type AlertmanagerTimeIntervals struct {
+	TimeIntervals []TimeInterval
+	SLOMutes      []SLOMuteTimeIntervals
+}
+
+type timeIntervalsGenerator bool
+
+// TimeIntervalsGenerator knows how to generate the Alertmanager time intervals from the SLOs
+// maintenance windows:
+// - A time interval for every maintenance window name, shared by the SLOs that use it.
+// - The alert matchers and the time intervals to mute for every SLO with maintenance windows.
+const TimeIntervalsGenerator = timeIntervalsGenerator(false)
+
+func (t timeIntervalsGenerator) GenerateTimeIntervals(ctx context.Context, slos []SLO) (*AlertmanagerTimeIntervals, error) {
+	windows := map[string]MaintenanceWindow{}
+	mutes := []SLOMuteTimeIntervals{}
+	for _, slo := range slos {
+		if len(slo.MaintenanceWindows) == 0 || (slo.PageAlertMeta.Disable && slo.TicketAlertMeta.Disable) {
+			continue
+		}
+
+		names := make([]string, 0, len(slo.MaintenanceWindows))
+		for _, w := range slo.MaintenanceWindows {
+			if w.Name == "" {
+				return nil, fmt.Errorf("%q SLO maintenance window name is required", slo.ID)
+			}
+
+			current, ok := windows[w.Name]
+			if ok && !reflect.DeepEqual(current, w) {
+				return nil, fmt.Errorf("%q maintenance window is declared with conflicting time intervals", w.Name)
+			}
+			windows[w.Name] = w
+			names = append(names, w.Name)
+		}
+
+		mutes = append(mutes, SLOMuteTimeIntervals{
+			Matchers:          []string{fmt.Sprintf("%s=%q", sloIDLabelName, slo.ID)},
+			MuteTimeIntervals: names,
+		})
+	}
+
+	// Sort for deterministic output.
+	names := make([]string, 0, len(windows))
+	for name := range windows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	intervals := make([]TimeInterval, 0, len(names))
+	for _, name := range names {
+		spec, err := mapMaintenanceWindow(windows[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q maintenance window: %w", name, err)
+		}
+		intervals = append(intervals, TimeInterval{Name: name, TimeIntervals: []TimeIntervalSpec{*spec}})
+	}
+
+	return &AlertmanagerTimeIntervals{
+		TimeIntervals: intervals,
+		SLOMutes:      mutes,
+	}, nil
+}
+
+var (
+	timeRangeRegexp = regexp.MustCompile(`^(\d{2}:\d{2})-(\d{2}:\d{2})$`)
+	weekdays        = map[string]bool{"sunday": true, "monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true, "saturday": true}
+	months          = map[string]int{"january": 1, "february": 2, "march": 3, "april": 4, "may": 5, "june": 6, "july": 7, "august": 8, "september": 9, "october": 10, "november": 11, "december": 12}
+)
+
+func mapMaintenanceWindow(w MaintenanceWindow) (*TimeIntervalSpec, error) {
+	spec := &TimeIntervalSpec{
+		Location: w.Location,
+	}
+
+	for _, t := range w.Times {
+		match := timeRangeRegexp.FindStringSubmatch(t)
+		if match == nil {
+			return nil, fmt.Errorf("invalid %q time range, it should be 'HH:MM-HH:MM'", t)
+		}
+
+		start, err := parseDayMinutes(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q time range start: %w", t, err)
+		}
+		end, err := parseDayMinutes(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q time range end: %w", t, err)
+		}
+		if start >= end {
+			return nil, fmt.Errorf("invalid %q time range, the start should be before the end", t)
+		}
+
+		spec.Times = append(spec.Times, TimeRange{StartTime: match[1], EndTime: match[2]})
+	}
+
+	for _, wd := range w.Weekdays {
+		for _, d := range strings.Split(strings.ToLower(wd), ":") {
+			if !weekdays[d] {
+				return nil, fmt.Errorf("invalid %q weekday", wd)
+			}
+		}
+		spec.Weekdays = append(spec.Weekdays, strings.ToLower(wd))
+	}
+
+	for _, dom := range w.DaysOfMonth {
+		err := validateDaysOfMonthRange(dom)
+		if err != nil {
+			return nil, err
+		}
+		spec.DaysOfMonth = append(spec.DaysOfMonth, dom)
+	}
+
+	for _, m := range w.Months {
+		err := validateMonthsRange(strings.ToLower(m))
+		if err != nil {
+			return nil, err
+		}
+		spec.Months = append(spec.Months, strings.ToLower(m))
+	}
+
+	if w.Location != "" {
+		_, err := time.LoadLocation(w.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q location: %w", w.Location, err)
+		}
+	}
+
+	return spec, nil
+}
+
+// parseDayMinutes parses an `HH:MM` time of the day into minutes, `24:00` is the end of the day.
+func parseDayMinutes(s string) (int, error) {
+	var h, m int
+	_, err := fmt.Sscanf(s, "%d:%d", &h, &m)
+	if err != nil {
+		return 0, err
+	}
+
+	mins := h*60 + m
+	if m > 59 || mins > 24*60 {
+		return 0, fmt.Errorf("invalid %q time", s)
+	}
+
+	return mins, nil
+}
+
+// validateDaysOfMonthRange validates an Alertmanager days of month range (e.g `1`, `1:5`, `-3:-1`),
+// the negative days are counted from the end of the month.
+func validateDaysOfMonthRange(r string) error {
+	start, end, err := parseIntRange(r, func(s string) (int, error) {
+		d, err := strconv.Atoi(s)
+		if err != nil || d == 0 || d < -31 || d > 31 {
+			return 0, fmt.Errorf("invalid %q day of month, it should be between 1 and 31 or -31 and -1", s)
+		}
+		return d, nil
+	})
+	if err != nil {
+		return fmt.Errorf("invalid %q days of month: %w", r, err)
+	}
+
+	// Ranges with mixed signs depend on the month length, Alertmanager allows them.
+	if (start > 0) == (end > 0) && start > end {
+		return fmt.Errorf("invalid %q days of month, the start should be before the end", r)
+	}
+
+	return nil
+}
+
+// validateMonthsRange validates an Alertmanager months range (e.g `january`, `1:3`).
+func validateMonthsRange(r string) error {
+	start, end, err := parseIntRange(r, func(s string) (int, error) {
+		if m, ok := months[s]; ok {
+			return m, nil
+		}
+		m, err := strconv.Atoi(s)
+		if err != nil || m < 1 || m > 12 {
+			return 0, fmt.Errorf("invalid %q month, it should be a month name or between 1 and 12", s)
+		}
+		return m, nil
+	})
+	if err != nil {
+		return fmt.Errorf("invalid %q months: %w", r, err)
+	}
+
+	if start > end {
+		return fmt.Errorf("invalid %q months, the start should be before the end", r)
+	}
+
+	return nil
+}
+
+// parseIntRange parses a single value or a `start:end` range using the value parser.
+func parseIntRange(r string, parse func(string) (int, error)) (start, end int, err error) {
+	parts := strings.Split(r, ":")
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("invalid range, it should be 'start:end'")
+	}
+
+	start, err = parse(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	end = start
+	if len(parts) == 2 {
+		end, err = parse(parts[1])
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return start, end, nil
+}
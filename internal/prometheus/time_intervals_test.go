@@ -0,0 +1,187 @@
+package prometheus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/internal/prometheus"
+)
+
+func TestGenerateTimeIntervals(t *testing.T) {
+	weekly := prometheus.MaintenanceWindow{Name: "weekly-maintenance", Times: []string{"02:00-04:00"}, Weekdays: []string{"Sunday"}, Location: "Europe/Madrid"}
+	offHours := prometheus.MaintenanceWindow{Name: "off-hours", Times: []string{"00:00-08:00", "20:00-24:00"}, Weekdays: []string{"monday:friday"}}
+
+	tests := map[string]struct {
+		slos         []prometheus.SLO
+		expIntervals *prometheus.AlertmanagerTimeIntervals
+		expErr       bool
+	}{
+		"Having SLOs without maintenance windows, shouldn't generate time intervals.": {
+			slos: []prometheus.SLO{{ID: "svc-slo1"}},
+			expIntervals: &prometheus.AlertmanagerTimeIntervals{
+				TimeIntervals: []prometheus.TimeInterval{},
+				SLOMutes:      []prometheus.SLOMuteTimeIntervals{},
+			},
+		},
+
+		"Having SLOs with maintenance windows, should generate the shared time intervals and the mute time intervals per SLO.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{weekly, offHours}},
+				{ID: "svc-slo2", MaintenanceWindows: []prometheus.MaintenanceWindow{weekly}},
+				{ID: "svc-slo3"},
+			},
+			expIntervals: &prometheus.AlertmanagerTimeIntervals{
+				TimeIntervals: []prometheus.TimeInterval{
+					{
+						Name: "off-hours",
+						TimeIntervals: []prometheus.TimeIntervalSpec{{
+							Times:    []prometheus.TimeRange{{StartTime: "00:00", EndTime: "08:00"}, {StartTime: "20:00", EndTime: "24:00"}},
+							Weekdays: []string{"monday:friday"},
+						}},
+					},
+					{
+						Name: "weekly-maintenance",
+						TimeIntervals: []prometheus.TimeIntervalSpec{{
+							Times:    []prometheus.TimeRange{{StartTime: "02:00", EndTime: "04:00"}},
+							Weekdays: []string{"sunday"},
+							Location: "Europe/Madrid",
+						}},
+					},
+				},
+				SLOMutes: []prometheus.SLOMuteTimeIntervals{
+					{Matchers: []string{`sloth_id="svc-slo1"`}, MuteTimeIntervals: []string{"weekly-maintenance", "off-hours"}},
+					{Matchers: []string{`sloth_id="svc-slo2"`}, MuteTimeIntervals: []string{"weekly-maintenance"}},
+				},
+			},
+		},
+
+		"Having an SLO with the alerts disabled, shouldn't generate its mute time intervals.": {
+			slos: []prometheus.SLO{
+				{
+					ID:                 "svc-slo1",
+					PageAlertMeta:      prometheus.AlertMeta{Disable: true},
+					TicketAlertMeta:    prometheus.AlertMeta{Disable: true},
+					MaintenanceWindows: []prometheus.MaintenanceWindow{weekly},
+				},
+			},
+			expIntervals: &prometheus.AlertmanagerTimeIntervals{
+				TimeIntervals: []prometheus.TimeInterval{},
+				SLOMutes:      []prometheus.SLOMuteTimeIntervals{},
+			},
+		},
+
+		"Having maintenance windows with the same name and different time intervals should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{weekly}},
+				{ID: "svc-slo2", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "weekly-maintenance", Weekdays: []string{"saturday"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window without name should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Weekdays: []string{"saturday"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with an invalid time range should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", Times: []string{"04:00-02:00"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with an invalid weekday should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", Weekdays: []string{"monday:funday"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with days of month and months, should generate them.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", DaysOfMonth: []string{"1", "1:5", "-3:-1", "25:-1"}, Months: []string{"January", "1:3", "june:august"}}}},
+			},
+			expIntervals: &prometheus.AlertmanagerTimeIntervals{
+				TimeIntervals: []prometheus.TimeInterval{
+					{
+						Name: "w",
+						TimeIntervals: []prometheus.TimeIntervalSpec{{
+							DaysOfMonth: []string{"1", "1:5", "-3:-1", "25:-1"},
+							Months:      []string{"january", "1:3", "june:august"},
+						}},
+					},
+				},
+				SLOMutes: []prometheus.SLOMuteTimeIntervals{
+					{Matchers: []string{`sloth_id="svc-slo1"`}, MuteTimeIntervals: []string{"w"}},
+				},
+			},
+		},
+
+		"Having a maintenance window with an out of range day of month should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", DaysOfMonth: []string{"32"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with a zero day of month should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", DaysOfMonth: []string{"0:5"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with an inverted days of month range should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", DaysOfMonth: []string{"10:5"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with an out of range month should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", Months: []string{"13"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with an invalid month name should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", Months: []string{"smarch"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with an inverted months range should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", Months: []string{"december:january"}}}},
+			},
+			expErr: true,
+		},
+
+		"Having a maintenance window with an invalid location should fail.": {
+			slos: []prometheus.SLO{
+				{ID: "svc-slo1", MaintenanceWindows: []prometheus.MaintenanceWindow{{Name: "w", Location: "Mars/Olympus"}}},
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotIntervals, err := prometheus.TimeIntervalsGenerator.GenerateTimeIntervals(context.TODO(), test.slos)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expIntervals, gotIntervals)
+			}
+		})
+	}
+}
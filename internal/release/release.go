@@ -0,0 +1,200 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const versionLinePrefix = "# version:"
+
+const (
+	// ChecksumsFileName is the release manifest file name, the release artifacts checksums
+	// (`sha256sum` format) with a `# version: <version>` line of the release version, this
+	// way the signature also covers the version and old releases can't be replayed as new ones.
+	ChecksumsFileName = "checksums.txt"
+	// ChecksumsSignatureFileName is the release checksums file Ed25519 signature file name.
+	ChecksumsSignatureFileName = "checksums.txt.sig"
+)
+
+// ArtifactName returns the release binary artifact name of a platform, these are
+// the names used by the release build scripts.
+func ArtifactName(goos, goarch string) (string, error) {
+	switch goos + "/" + goarch {
+	case "linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64":
+		return fmt.Sprintf("sloth-%s-%s", goos, goarch), nil
+	case "linux/arm":
+		return "sloth-linux-arm-v7", nil
+	case "windows/amd64":
+		return "sloth-windows-amd64.exe", nil
+	}
+
+	return "", fmt.Errorf("%s/%s platform doesn't have release artifacts", goos, goarch)
+}
+
+// ParsePublicKey parses a PEM encoded (PKIX) Ed25519 public key.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM data")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse public key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an Ed25519 key")
+	}
+
+	return edKey, nil
+}
+
+// Verifier knows how to verify the release artifacts using the signed release checksums.
+type Verifier struct {
+	version   string
+	checksums map[string]string
+}
+
+// NewVerifier returns a new release artifacts verifier, the checksums file signature
+// is verified with the public key, so it can be trusted to verify the artifacts.
+func NewVerifier(publicKey ed25519.PublicKey, checksums, signature []byte) (*Verifier, error) {
+	if !ed25519.Verify(publicKey, checksums, signature) {
+		return nil, fmt.Errorf("invalid checksums signature")
+	}
+
+	v := &Verifier{checksums: map[string]string{}}
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, versionLinePrefix) {
+			if v.version != "" {
+				return nil, fmt.Errorf("checksums have multiple versions")
+			}
+			v.version = strings.TrimSpace(strings.TrimPrefix(line, versionLinePrefix))
+			continue
+		}
+
+		// `sha256sum` format: `{checksum}  {file}` (binary mode files are prefixed with `*`).
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid checksums line: %q", line)
+		}
+		v.checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read checksums: %w", err)
+	}
+
+	if v.version == "" {
+		return nil, fmt.Errorf("checksums are missing the release version")
+	}
+
+	return v, nil
+}
+
+// Version returns the signed release version.
+func (v Verifier) Version() string { return v.version }
+
+// Verify verifies the artifact data matches its release checksum and returns the checksum.
+func (v Verifier) Verify(name string, data []byte) (string, error) {
+	expChecksum, ok := v.checksums[name]
+	if !ok {
+		return "", fmt.Errorf("%q artifact is missing on the release checksums", name)
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if checksum != expChecksum {
+		return "", fmt.Errorf("%q artifact checksum %s doesn't match the release checksum %s", name, checksum, expChecksum)
+	}
+
+	return checksum, nil
+}
+
+var versionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?$`)
+
+// CompareVersions compares two semantic versions (e.g `v0.7.0`, `v0.7.0-rc.1`), it returns
+// -1 if `a` is older than `b`, 0 if they are the same version and 1 if `a` is newer than `b`.
+// The versions with a pre-release (e.g `git describe` versions) are older than the release.
+func CompareVersions(a, b string) (int, error) {
+	ma := versionRegexp.FindStringSubmatch(a)
+	if ma == nil {
+		return 0, fmt.Errorf("invalid %q version", a)
+	}
+	mb := versionRegexp.FindStringSubmatch(b)
+	if mb == nil {
+		return 0, fmt.Errorf("invalid %q version", b)
+	}
+
+	for i := 1; i <= 3; i++ {
+		na, _ := strconv.Atoi(ma[i])
+		nb, _ := strconv.Atoi(mb[i])
+		switch {
+		case na < nb:
+			return -1, nil
+		case na > nb:
+			return 1, nil
+		}
+	}
+
+	switch preA, preB := ma[4], mb[4]; {
+	case preA == preB:
+		return 0, nil
+	case preA == "":
+		return 1, nil
+	case preB == "":
+		return -1, nil
+	default:
+		return comparePreReleases(preA, preB), nil
+	}
+}
+
+// comparePreReleases compares the pre-release dot separated identifiers, numeric identifiers
+// are compared numerically and have lower precedence than the alphanumeric ones.
+func comparePreReleases(a, b string) int {
+	idsA, idsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(idsA) && i < len(idsB); i++ {
+		na, errA := strconv.Atoi(idsA[i])
+		nb, errB := strconv.Atoi(idsB[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case errA == nil && errB != nil:
+			return -1
+		case errA != nil && errB == nil:
+			return 1
+		case idsA[i] != idsB[i]:
+			if idsA[i] < idsB[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(idsA) < len(idsB):
+		return -1
+	case len(idsA) > len(idsB):
+		return 1
+	}
+
+	return 0
+}
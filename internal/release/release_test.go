@@ -0,0 +1,237 @@
+package release_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/release"
+)
+
+func TestArtifactName(t *testing.T) {
+	tests := map[string]struct {
+		goos    string
+		goarch  string
+		expName string
+		expErr  bool
+	}{
+		"Linux amd64 should return the artifact name.": {
+			goos:    "linux",
+			goarch:  "amd64",
+			expName: "sloth-linux-amd64",
+		},
+
+		"Linux arm should return the ARM v7 artifact name.": {
+			goos:    "linux",
+			goarch:  "arm",
+			expName: "sloth-linux-arm-v7",
+		},
+
+		"Windows should return the artifact name with the extension.": {
+			goos:    "windows",
+			goarch:  "amd64",
+			expName: "sloth-windows-amd64.exe",
+		},
+
+		"A platform without release artifacts should fail.": {
+			goos:   "plan9",
+			goarch: "386",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotName, err := release.ArtifactName(test.goos, test.goarch)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expName, gotName)
+			}
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	privKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pubKeyData, err := x509.MarshalPKIXPublicKey(privKey.Public())
+	require.NoError(err)
+
+	gotKey, err := release.ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKeyData}))
+	require.NoError(err)
+	assert.Equal(privKey.Public(), gotKey)
+
+	_, err = release.ParsePublicKey([]byte("wrong"))
+	assert.Error(err)
+}
+
+func TestVerifier(t *testing.T) {
+	privKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pubKey := privKey.Public().(ed25519.PublicKey)
+	otherPrivKey := ed25519.NewKeyFromSeed(append(make([]byte, ed25519.SeedSize-1), 1))
+
+	artifact := []byte("sloth binary")
+	sum := sha256.Sum256(artifact)
+	checksum := hex.EncodeToString(sum[:])
+	checksums := []byte(fmt.Sprintf("# version: v0.7.0\n%s  sloth-linux-amd64\n%s  sloth-darwin-amd64\n", checksum, checksum))
+	unversionedChecksums := []byte(fmt.Sprintf("%s  sloth-linux-amd64\n", checksum))
+
+	tests := map[string]struct {
+		checksums   []byte
+		signature   []byte
+		artifact    string
+		data        []byte
+		expChecksum string
+		expVersion  string
+		expErr      bool
+	}{
+		"Checksums without the release version should fail.": {
+			checksums: unversionedChecksums,
+			signature: ed25519.Sign(privKey, unversionedChecksums),
+			artifact:  "sloth-linux-amd64",
+			data:      artifact,
+			expErr:    true,
+		},
+
+		"Checksums with a modified release version should fail.": {
+			checksums: append([]byte("# version: v9.9.9\n"), unversionedChecksums...),
+			signature: ed25519.Sign(privKey, checksums),
+			artifact:  "sloth-linux-amd64",
+			data:      artifact,
+			expErr:    true,
+		},
+
+		"Checksums signed with other key should fail.": {
+			checksums: checksums,
+			signature: ed25519.Sign(otherPrivKey, checksums),
+			artifact:  "sloth-linux-amd64",
+			data:      artifact,
+			expErr:    true,
+		},
+
+		"Modified checksums should fail.": {
+			checksums: append([]byte("0000  sloth-windows-amd64.exe\n"), checksums...),
+			signature: ed25519.Sign(privKey, checksums),
+			artifact:  "sloth-linux-amd64",
+			data:      artifact,
+			expErr:    true,
+		},
+
+		"An artifact missing on the checksums should fail.": {
+			checksums: checksums,
+			signature: ed25519.Sign(privKey, checksums),
+			artifact:  "sloth-linux-arm64",
+			data:      artifact,
+			expErr:    true,
+		},
+
+		"A modified artifact should fail.": {
+			checksums: checksums,
+			signature: ed25519.Sign(privKey, checksums),
+			artifact:  "sloth-linux-amd64",
+			data:      []byte("modified sloth binary"),
+			expErr:    true,
+		},
+
+		"A valid artifact should be verified.": {
+			checksums:   checksums,
+			signature:   ed25519.Sign(privKey, checksums),
+			artifact:    "sloth-linux-amd64",
+			data:        artifact,
+			expChecksum: checksum,
+			expVersion:  "v0.7.0",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var gotChecksum, gotVersion string
+			verifier, err := release.NewVerifier(pubKey, test.checksums, test.signature)
+			if err == nil {
+				gotVersion = verifier.Version()
+				gotChecksum, err = verifier.Verify(test.artifact, test.data)
+			}
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expChecksum, gotChecksum)
+				assert.Equal(test.expVersion, gotVersion)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := map[string]struct {
+		a      string
+		b      string
+		expCmp int
+		expErr bool
+	}{
+		"Invalid versions should fail.": {
+			a:      "dev",
+			b:      "v0.7.0",
+			expErr: true,
+		},
+
+		"The same versions should be equal.": {
+			a:      "v0.7.0",
+			b:      "0.7.0",
+			expCmp: 0,
+		},
+
+		"Older versions should be lower.": {
+			a:      "v0.7.0",
+			b:      "v0.10.0",
+			expCmp: -1,
+		},
+
+		"Newer versions should be greater.": {
+			a:      "v1.0.0",
+			b:      "v0.10.3",
+			expCmp: 1,
+		},
+
+		"Pre-release versions should be lower than the release.": {
+			a:      "v0.7.0-rc.1",
+			b:      "v0.7.0",
+			expCmp: -1,
+		},
+
+		"Pre-release versions numeric identifiers should be compared numerically.": {
+			a:      "v0.7.0-rc.10",
+			b:      "v0.7.0-rc.2",
+			expCmp: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotCmp, err := release.CompareVersions(test.a, test.b)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCmp, gotCmp)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package usage has the anonymous aggregated usage stats of the Sloth runs, so platform
+// teams can collect them internally to know the SLO spec features adoption across their
+// repositories. The stats are only counts, they don't have any SLO spec data (e.g the
+// services, SLI queries or labels).
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/slok/sloth/internal/info"
+)
+
+// SLI types of the SLO specs.
+const (
+	SLITypeEvents  = "events"
+	SLITypeRaw     = "raw"
+	SLITypeLatency = "latency"
+	SLITypePlugin  = "plugin"
+)
+
+// SLO is the usage of an SLO spec SLO.
+type SLO struct {
+	// SLIType is the SLI type of the SLO (e.g `events`), empty if the SLO doesn't have SLI.
+	SLIType string
+	// SLIPlugins are the SLI plugin IDs used by the SLO, including the chained plugins.
+	SLIPlugins []string
+	// Features are the optional SLO spec features used by the SLO (e.g `timeslice`).
+	Features []string
+}
+
+// Report is the anonymous aggregated usage of a run.
+type Report struct {
+	Time       time.Time      `json:"time"`
+	Version    string         `json:"version"`
+	Command    string         `json:"command"`
+	Specs      map[string]int `json:"specs"`
+	SLOs       int            `json:"slos"`
+	SLITypes   map[string]int `json:"sli_types"`
+	SLIPlugins map[string]int `json:"sli_plugins"`
+	Features   map[string]int `json:"features"`
+}
+
+// WriteJSONLine writes the report as a single JSON line, so the reports of multiple runs
+// can be appended to the same file.
+func (r Report) WriteJSONLine(w io.Writer) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("could not format usage report: %w", err)
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("could not write usage report: %w", err)
+	}
+
+	return nil
+}
+
+// Recorder records the usage of the SLO specs of a run.
+type Recorder struct {
+	report Report
+}
+
+// NewRecorder returns a new usage recorder for a command run.
+func NewRecorder(command string) *Recorder {
+	return &Recorder{
+		report: Report{
+			Version:    info.Version,
+			Command:    command,
+			Specs:      map[string]int{},
+			SLITypes:   map[string]int{},
+			SLIPlugins: map[string]int{},
+			Features:   map[string]int{},
+		},
+	}
+}
+
+// AddSpec records the usage of an SLO spec of a spec format (e.g `prometheus/v1`).
+func (r *Recorder) AddSpec(format string, slos []SLO) {
+	r.report.Specs[format]++
+	r.report.SLOs += len(slos)
+
+	for _, slo := range slos {
+		if slo.SLIType != "" {
+			r.report.SLITypes[slo.SLIType]++
+		}
+
+		for _, p := range slo.SLIPlugins {
+			r.report.SLIPlugins[p]++
+		}
+
+		for _, f := range slo.Features {
+			r.report.Features[f]++
+		}
+	}
+}
+
+// Report returns the usage report of the recorded specs at a time.
+func (r *Recorder) Report(t time.Time) Report {
+	report := r.report
+	report.Time = t.UTC()
+	return report
+}
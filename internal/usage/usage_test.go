@@ -0,0 +1,102 @@
+package usage_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/internal/usage"
+)
+
+func TestRecorder(t *testing.T) {
+	testTime := time.Date(2021, 6, 30, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		specs     map[string][][]usage.SLO
+		expReport usage.Report
+	}{
+		"Without specs the report should be empty.": {
+			expReport: usage.Report{
+				Time:       testTime,
+				Version:    "dev",
+				Command:    "generate",
+				Specs:      map[string]int{},
+				SLITypes:   map[string]int{},
+				SLIPlugins: map[string]int{},
+				Features:   map[string]int{},
+			},
+		},
+
+		"Multiple specs should aggregate the SLOs usage.": {
+			specs: map[string][][]usage.SLO{
+				"prometheus/v1": {
+					{
+						{SLIType: usage.SLITypeEvents, Features: []string{"timeslice"}},
+						{SLIType: usage.SLITypePlugin, SLIPlugins: []string{"test/plugin1", "test/plugin2"}},
+					},
+					{
+						{SLIType: usage.SLITypeLatency, Features: []string{"timeslice", "environments"}},
+					},
+				},
+				"openslo/v1": {
+					{
+						{SLIType: usage.SLITypeRaw},
+						{},
+					},
+				},
+				"k8s/v1": {
+					{
+						{SLIType: usage.SLITypePlugin, SLIPlugins: []string{"test/plugin1"}},
+					},
+				},
+			},
+			expReport: usage.Report{
+				Time:    testTime,
+				Version: "dev",
+				Command: "generate",
+				Specs:   map[string]int{"prometheus/v1": 2, "openslo/v1": 1, "k8s/v1": 1},
+				SLOs:    6,
+				SLITypes: map[string]int{
+					usage.SLITypeEvents:  1,
+					usage.SLITypeRaw:     1,
+					usage.SLITypeLatency: 1,
+					usage.SLITypePlugin:  2,
+				},
+				SLIPlugins: map[string]int{"test/plugin1": 2, "test/plugin2": 1},
+				Features:   map[string]int{"timeslice": 2, "environments": 1},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			r := usage.NewRecorder("generate")
+			for format, specs := range test.specs {
+				for _, slos := range specs {
+					r.AddSpec(format, slos)
+				}
+			}
+
+			assert.Equal(test.expReport, r.Report(testTime))
+		})
+	}
+}
+
+func TestReportWriteJSONLine(t *testing.T) {
+	r := usage.NewRecorder("generate")
+	r.AddSpec("prometheus/v1", []usage.SLO{{SLIType: usage.SLITypeEvents, Features: []string{"timeslice"}}})
+	report := r.Report(time.Date(2021, 6, 30, 10, 0, 0, 0, time.UTC))
+
+	// Multiple runs should be appended as JSON lines.
+	var b bytes.Buffer
+	require.NoError(t, report.WriteJSONLine(&b))
+	require.NoError(t, report.WriteJSONLine(&b))
+
+	expLine := `{"time":"2021-06-30T10:00:00Z","version":"dev","command":"generate","specs":{"prometheus/v1":1},"slos":1,"sli_types":{"events":1},"sli_plugins":{},"features":{"timeslice":1}}` + "\n"
+	assert.Equal(t, expLine+expLine, b.String())
+}
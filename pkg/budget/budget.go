@@ -0,0 +1,76 @@
+// Package budget has the SLO error budget and burn rate math that Sloth uses to generate
+// the SLO rules and alerts, so other tools (e.g dashboards, chat bots) can use the same math.
+//
+// The SLO objectives are percents (e.g `99.9`) like on the SLO specs, and the error budget
+// fractions are ratios (0-1) of the SLO period error budget.
+package budget
+
+import (
+	"math"
+	"time"
+)
+
+// ErrorBudgetRatio returns the ratio of the events that can fail of an SLO objective
+// (e.g `0.001` for `99.9`).
+func ErrorBudgetRatio(objective float64) float64 {
+	return (100 - objective) / 100
+}
+
+// AllowedDowntime returns the downtime allowed by an SLO objective on a time window
+// (e.g `43m12s` for `99.9` on `30d`).
+func AllowedDowntime(objective float64, window time.Duration) time.Duration {
+	return time.Duration(float64(window) * ErrorBudgetRatio(objective))
+}
+
+// BurnRate returns the error budget burn rate (speed) of an SLI error ratio, 1 means that the
+// error budget would be consumed exactly at the end of the SLO period.
+func BurnRate(errorRatio, objective float64) float64 {
+	return errorRatio / ErrorBudgetRatio(objective)
+}
+
+// BurnFactor returns the burn rate factor (speed) required to consume the budget fraction
+// of the SLO period error budget in the window (e.g `14.4` for `0.02` of `30d` in `1h`).
+func BurnFactor(sloPeriod, window time.Duration, budgetFraction float64) float64 {
+	// First get the total hours required to consume the fraction of the error budget in the SLO period.
+	hoursRequiredConsumption := budgetFraction * sloPeriod.Hours()
+
+	// Now calculate how much is the factor required for the hours consumption, in case we would need to use
+	// a different time window (e.g: hours required: 36h, if we want to do it in 6h: would be `x6`).
+	speed := hoursRequiredConsumption / window.Hours()
+
+	// Round to remove the floating point precision noise of the scaled windows (e.g 14.399999999999999).
+	return math.Round(speed*10000) / 10000
+}
+
+// BudgetConsumed returns the fraction of the SLO period error budget consumed burning the
+// error budget at the burn rate during the window.
+func BudgetConsumed(burnRate float64, sloPeriod, window time.Duration) float64 {
+	return burnRate * float64(window) / float64(sloPeriod)
+}
+
+// TimeToExhaustion returns the time until the remaining fraction of the SLO period error budget
+// is exhausted at the burn rate, 0 if the error budget is already exhausted or it's not being burned.
+func TimeToExhaustion(sloPeriod time.Duration, remainingFraction, burnRate float64) time.Duration {
+	if remainingFraction <= 0 || burnRate <= 0 {
+		return 0
+	}
+
+	// A burn rate of 1 consumes the whole error budget in the SLO period.
+	return time.Duration(remainingFraction * float64(sloPeriod) / burnRate)
+}
+
+// ScaleWindow scales a window of an SLO period to the same proportion of other SLO period, so
+// it consumes the same fraction of the error budget. The scaled window is rounded to minutes,
+// with a minimum of 1 minute.
+func ScaleWindow(window, fromSLOPeriod, toSLOPeriod time.Duration) time.Duration {
+	if fromSLOPeriod == toSLOPeriod {
+		return window
+	}
+
+	scaled := time.Duration(float64(window) * float64(toSLOPeriod) / float64(fromSLOPeriod)).Round(time.Minute)
+	if scaled < time.Minute {
+		return time.Minute
+	}
+
+	return scaled
+}
@@ -0,0 +1,268 @@
+package budget_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/pkg/budget"
+)
+
+func TestErrorBudgetRatio(t *testing.T) {
+	tests := map[string]struct {
+		objective float64
+		expRatio  float64
+	}{
+		"A 99.9 objective should have a 0.001 error budget ratio.": {
+			objective: 99.9,
+			expRatio:  0.001,
+		},
+
+		"A 95 objective should have a 0.05 error budget ratio.": {
+			objective: 95,
+			expRatio:  0.05,
+		},
+
+		"A 100 objective should not have error budget.": {
+			objective: 100,
+			expRatio:  0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, test.expRatio, budget.ErrorBudgetRatio(test.objective), 1e-12)
+		})
+	}
+}
+
+func TestAllowedDowntime(t *testing.T) {
+	tests := map[string]struct {
+		objective   float64
+		window      time.Duration
+		expDowntime time.Duration
+	}{
+		"A 99.9 objective on 30 days should allow 43m12s of downtime.": {
+			objective:   99.9,
+			window:      30 * 24 * time.Hour,
+			expDowntime: 43*time.Minute + 12*time.Second,
+		},
+
+		"A 99 objective on 7 days should allow 1h40m48s of downtime.": {
+			objective:   99,
+			window:      7 * 24 * time.Hour,
+			expDowntime: 1*time.Hour + 40*time.Minute + 48*time.Second,
+		},
+
+		"A 100 objective should not allow downtime.": {
+			objective:   100,
+			window:      30 * 24 * time.Hour,
+			expDowntime: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotDowntime := budget.AllowedDowntime(test.objective, test.window)
+			assert.Equal(t, test.expDowntime, gotDowntime.Round(time.Millisecond))
+		})
+	}
+}
+
+func TestBurnRate(t *testing.T) {
+	tests := map[string]struct {
+		errorRatio  float64
+		objective   float64
+		expBurnRate float64
+	}{
+		"An error ratio equal to the error budget should burn at 1x.": {
+			errorRatio:  0.001,
+			objective:   99.9,
+			expBurnRate: 1,
+		},
+
+		"An error ratio of 1.44% on a 99.9 objective should burn at 14.4x.": {
+			errorRatio:  0.0144,
+			objective:   99.9,
+			expBurnRate: 14.4,
+		},
+
+		"Without errors it should not burn.": {
+			errorRatio:  0,
+			objective:   99.9,
+			expBurnRate: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, test.expBurnRate, budget.BurnRate(test.errorRatio, test.objective), 1e-9)
+		})
+	}
+}
+
+func TestBurnFactor(t *testing.T) {
+	tests := map[string]struct {
+		sloPeriod      time.Duration
+		window         time.Duration
+		budgetFraction float64
+		expBurnFactor  float64
+	}{
+		"Consuming 2% of 30 days error budget in 1 hour should have a 14.4 burn factor.": {
+			sloPeriod:      30 * 24 * time.Hour,
+			window:         1 * time.Hour,
+			budgetFraction: 0.02,
+			expBurnFactor:  14.4,
+		},
+
+		"Consuming 5% of 30 days error budget in 6 hours should have a 6 burn factor.": {
+			sloPeriod:      30 * 24 * time.Hour,
+			window:         6 * time.Hour,
+			budgetFraction: 0.05,
+			expBurnFactor:  6,
+		},
+
+		"Consuming 10% of 30 days error budget in 3 days should have a 1 burn factor.": {
+			sloPeriod:      30 * 24 * time.Hour,
+			window:         3 * 24 * time.Hour,
+			budgetFraction: 0.1,
+			expBurnFactor:  1,
+		},
+
+		"Consuming 2% of 28 days error budget in 56 minutes should have a 14.4 burn factor.": {
+			sloPeriod:      28 * 24 * time.Hour,
+			window:         56 * time.Minute,
+			budgetFraction: 0.02,
+			expBurnFactor:  14.4,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expBurnFactor, budget.BurnFactor(test.sloPeriod, test.window, test.budgetFraction))
+		})
+	}
+}
+
+func TestBudgetConsumed(t *testing.T) {
+	tests := map[string]struct {
+		burnRate    float64
+		sloPeriod   time.Duration
+		window      time.Duration
+		expConsumed float64
+	}{
+		"Burning at 14.4x during 1 hour should consume 2% of 30 days error budget.": {
+			burnRate:    14.4,
+			sloPeriod:   30 * 24 * time.Hour,
+			window:      1 * time.Hour,
+			expConsumed: 0.02,
+		},
+
+		"Burning at 1x during the whole SLO period should consume all the error budget.": {
+			burnRate:    1,
+			sloPeriod:   30 * 24 * time.Hour,
+			window:      30 * 24 * time.Hour,
+			expConsumed: 1,
+		},
+
+		"Burning at 2x during the whole SLO period should consume twice the error budget.": {
+			burnRate:    2,
+			sloPeriod:   7 * 24 * time.Hour,
+			window:      7 * 24 * time.Hour,
+			expConsumed: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, test.expConsumed, budget.BudgetConsumed(test.burnRate, test.sloPeriod, test.window), 1e-9)
+		})
+	}
+}
+
+func TestTimeToExhaustion(t *testing.T) {
+	tests := map[string]struct {
+		sloPeriod         time.Duration
+		remainingFraction float64
+		burnRate          float64
+		expExhaustion     time.Duration
+	}{
+		"Burning all the error budget at 1x should be exhausted at the end of the SLO period.": {
+			sloPeriod:         30 * 24 * time.Hour,
+			remainingFraction: 1,
+			burnRate:          1,
+			expExhaustion:     30 * 24 * time.Hour,
+		},
+
+		"Burning half the error budget at 10x should be exhausted in 1/20 of the SLO period.": {
+			sloPeriod:         30 * 24 * time.Hour,
+			remainingFraction: 0.5,
+			burnRate:          10,
+			expExhaustion:     36 * time.Hour,
+		},
+
+		"An exhausted error budget should not have time to exhaustion.": {
+			sloPeriod:         30 * 24 * time.Hour,
+			remainingFraction: -0.2,
+			burnRate:          10,
+			expExhaustion:     0,
+		},
+
+		"An error budget that is not being burned should not have time to exhaustion.": {
+			sloPeriod:         30 * 24 * time.Hour,
+			remainingFraction: 0.5,
+			burnRate:          0,
+			expExhaustion:     0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expExhaustion, budget.TimeToExhaustion(test.sloPeriod, test.remainingFraction, test.burnRate))
+		})
+	}
+}
+
+func TestScaleWindow(t *testing.T) {
+	tests := map[string]struct {
+		window        time.Duration
+		fromSLOPeriod time.Duration
+		toSLOPeriod   time.Duration
+		expWindow     time.Duration
+	}{
+		"Scaling to the same SLO period should not change the window.": {
+			window:        5 * time.Minute,
+			fromSLOPeriod: 30 * 24 * time.Hour,
+			toSLOPeriod:   30 * 24 * time.Hour,
+			expWindow:     5 * time.Minute,
+		},
+
+		"Scaling to a shorter SLO period should scale down the window rounded to minutes.": {
+			window:        1 * time.Hour,
+			fromSLOPeriod: 30 * 24 * time.Hour,
+			toSLOPeriod:   7 * 24 * time.Hour,
+			expWindow:     14 * time.Minute,
+		},
+
+		"Scaling to a longer SLO period should scale up the window.": {
+			window:        6 * time.Hour,
+			fromSLOPeriod: 30 * 24 * time.Hour,
+			toSLOPeriod:   90 * 24 * time.Hour,
+			expWindow:     18 * time.Hour,
+		},
+
+		"Scaled windows should have a minimum of 1 minute.": {
+			window:        5 * time.Minute,
+			fromSLOPeriod: 30 * 24 * time.Hour,
+			toSLOPeriod:   24 * time.Hour,
+			expWindow:     1 * time.Minute,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expWindow, budget.ScaleWindow(test.window, test.fromSLOPeriod, test.toSLOPeriod))
+		})
+	}
+}
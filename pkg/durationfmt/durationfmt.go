@@ -0,0 +1,160 @@
+// Package durationfmt formats durations in a human-readable and locale-aware form
+// (e.g `1 hour and 30 minutes`, `1 hora y 30 minutos`), so the SLO time windows and
+// error budgets can be used on reports for humans.
+package durationfmt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale is a duration formatting locale, an ISO 639-1 language code.
+type Locale string
+
+// The supported locales.
+const (
+	LocaleEnglish    Locale = "en"
+	LocaleSpanish    Locale = "es"
+	LocaleFrench     Locale = "fr"
+	LocaleGerman     Locale = "de"
+	LocalePortuguese Locale = "pt"
+)
+
+type unitNames struct {
+	singular string
+	plural   string
+}
+
+type localeData struct {
+	days    unitNames
+	hours   unitNames
+	minutes unitNames
+	seconds unitNames
+	and     string
+}
+
+var locales = map[Locale]localeData{
+	LocaleEnglish: {
+		days:    unitNames{"day", "days"},
+		hours:   unitNames{"hour", "hours"},
+		minutes: unitNames{"minute", "minutes"},
+		seconds: unitNames{"second", "seconds"},
+		and:     "and",
+	},
+	LocaleSpanish: {
+		days:    unitNames{"día", "días"},
+		hours:   unitNames{"hora", "horas"},
+		minutes: unitNames{"minuto", "minutos"},
+		seconds: unitNames{"segundo", "segundos"},
+		and:     "y",
+	},
+	LocaleFrench: {
+		days:    unitNames{"jour", "jours"},
+		hours:   unitNames{"heure", "heures"},
+		minutes: unitNames{"minute", "minutes"},
+		seconds: unitNames{"seconde", "secondes"},
+		and:     "et",
+	},
+	LocaleGerman: {
+		days:    unitNames{"Tag", "Tage"},
+		hours:   unitNames{"Stunde", "Stunden"},
+		minutes: unitNames{"Minute", "Minuten"},
+		seconds: unitNames{"Sekunde", "Sekunden"},
+		and:     "und",
+	},
+	LocalePortuguese: {
+		days:    unitNames{"dia", "dias"},
+		hours:   unitNames{"hora", "horas"},
+		minutes: unitNames{"minuto", "minutos"},
+		seconds: unitNames{"segundo", "segundos"},
+		and:     "e",
+	},
+}
+
+// Locales returns the supported locales.
+func Locales() []Locale {
+	ls := make([]Locale, 0, len(locales))
+	for l := range locales {
+		ls = append(ls, l)
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i] < ls[j] })
+
+	return ls
+}
+
+// ParseLocale parses a locale, it accepts the POSIX and BCP 47 locale forms (e.g `es`,
+// `es-ES`, `es_ES.UTF-8`) and uses only the language.
+func ParseLocale(s string) (Locale, error) {
+	lang := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(lang, "-_."); i >= 0 {
+		lang = lang[:i]
+	}
+
+	l := Locale(lang)
+	if _, ok := locales[l]; !ok {
+		return "", fmt.Errorf("unsupported %q locale, supported locales: %v", s, Locales())
+	}
+
+	return l, nil
+}
+
+// Formatter formats durations for a locale.
+type Formatter struct {
+	data localeData
+}
+
+// NewFormatter returns a new duration formatter for the locale.
+func NewFormatter(locale Locale) (*Formatter, error) {
+	data, ok := locales[locale]
+	if !ok {
+		return nil, fmt.Errorf("unsupported %q locale, supported locales: %v", locale, Locales())
+	}
+
+	return &Formatter{data: data}, nil
+}
+
+// Format formats a duration with second precision (e.g `30 days`, `1 hour and 30 minutes`
+// or `1 day, 2 hours and 3 minutes`), negative durations are formatted as positive.
+func (f Formatter) Format(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	d = d.Round(time.Second)
+
+	parts := []string{}
+	for _, u := range []struct {
+		unit  time.Duration
+		names unitNames
+	}{
+		{24 * time.Hour, f.data.days},
+		{time.Hour, f.data.hours},
+		{time.Minute, f.data.minutes},
+		{time.Second, f.data.seconds},
+	} {
+		n := d / u.unit
+		d -= n * u.unit
+		if n == 0 {
+			continue
+		}
+		parts = append(parts, formatUnit(int64(n), u.names))
+	}
+
+	switch len(parts) {
+	case 0:
+		return formatUnit(0, f.data.seconds)
+	case 1:
+		return parts[0]
+	}
+
+	return strings.Join(parts[:len(parts)-1], ", ") + " " + f.data.and + " " + parts[len(parts)-1]
+}
+
+func formatUnit(n int64, names unitNames) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, names.singular)
+	}
+
+	return fmt.Sprintf("%d %s", n, names.plural)
+}
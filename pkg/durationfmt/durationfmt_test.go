@@ -0,0 +1,162 @@
+package durationfmt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/pkg/durationfmt"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := map[string]struct {
+		locale    string
+		expLocale durationfmt.Locale
+		expErr    bool
+	}{
+		"A language should be parsed.": {
+			locale:    "es",
+			expLocale: durationfmt.LocaleSpanish,
+		},
+
+		"A BCP 47 locale should use only the language.": {
+			locale:    "pt-BR",
+			expLocale: durationfmt.LocalePortuguese,
+		},
+
+		"A POSIX locale should use only the language.": {
+			locale:    " de_DE.UTF-8 ",
+			expLocale: durationfmt.LocaleGerman,
+		},
+
+		"The language should be case insensitive.": {
+			locale:    "FR",
+			expLocale: durationfmt.LocaleFrench,
+		},
+
+		"An unsupported language should fail.": {
+			locale: "ja-JP",
+			expErr: true,
+		},
+
+		"An empty locale should fail.": {
+			locale: "",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotLocale, err := durationfmt.ParseLocale(test.locale)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expLocale, gotLocale)
+			}
+		})
+	}
+}
+
+func TestLocales(t *testing.T) {
+	exp := []durationfmt.Locale{"de", "en", "es", "fr", "pt"}
+	assert.Equal(t, exp, durationfmt.Locales())
+}
+
+func TestNewFormatterUnsupportedLocale(t *testing.T) {
+	_, err := durationfmt.NewFormatter("ja")
+	assert.Error(t, err)
+}
+
+func TestFormatterFormat(t *testing.T) {
+	tests := map[string]struct {
+		locale   durationfmt.Locale
+		duration time.Duration
+		expStr   string
+	}{
+		"A zero duration should be formatted in seconds.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: 0,
+			expStr:   "0 seconds",
+		},
+
+		"A single unit should use the singular.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: time.Hour,
+			expStr:   "1 hour",
+		},
+
+		"A single unit should use the plural.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: 30 * 24 * time.Hour,
+			expStr:   "30 days",
+		},
+
+		"Two units should be joined with the conjunction.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: 90 * time.Minute,
+			expStr:   "1 hour and 30 minutes",
+		},
+
+		"Multiple units should be joined with commas and the conjunction.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: 26*time.Hour + 3*time.Minute + 4*time.Second,
+			expStr:   "1 day, 2 hours, 3 minutes and 4 seconds",
+		},
+
+		"The zero units should be omitted.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: 24*time.Hour + 5*time.Second,
+			expStr:   "1 day and 5 seconds",
+		},
+
+		"Sub-second durations should be rounded to seconds.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: 1500 * time.Millisecond,
+			expStr:   "2 seconds",
+		},
+
+		"Negative durations should be formatted as positive.": {
+			locale:   durationfmt.LocaleEnglish,
+			duration: -2 * time.Minute,
+			expStr:   "2 minutes",
+		},
+
+		"Spanish locale should be used.": {
+			locale:   durationfmt.LocaleSpanish,
+			duration: 25*time.Hour + 30*time.Minute,
+			expStr:   "1 día, 1 hora y 30 minutos",
+		},
+
+		"French locale should be used.": {
+			locale:   durationfmt.LocaleFrench,
+			duration: 2*time.Hour + time.Second,
+			expStr:   "2 heures et 1 seconde",
+		},
+
+		"German locale should be used.": {
+			locale:   durationfmt.LocaleGerman,
+			duration: 48*time.Hour + time.Minute,
+			expStr:   "2 Tage und 1 Minute",
+		},
+
+		"Portuguese locale should be used.": {
+			locale:   durationfmt.LocalePortuguese,
+			duration: 3 * time.Hour,
+			expStr:   "3 horas",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := durationfmt.NewFormatter(test.locale)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expStr, f.Format(test.duration))
+		})
+	}
+}
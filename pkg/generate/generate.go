@@ -0,0 +1,392 @@
+// Package generate is the Sloth SLO rules generation as a Go library, so platform teams
+// can embed the Sloth generation on their own operators and CLIs without running the Sloth
+// binary. The generation has 3 steps, load the SLO specs, generate the SLO results (rules
+// and alerts) and store them, every step can be replaced with a custom implementation.
+//
+// Example generating the Prometheus rules of an SLO spec:
+//
+//	gen, err := generate.NewGenerator(generate.Config{Out: os.Stdout})
+//	if err != nil {
+//		return err
+//	}
+//
+//	slos, err := gen.LoadSpec(ctx, specData)
+//	if err != nil {
+//		return err
+//	}
+//
+//	results, err := gen.Generate(ctx, *slos)
+//	if err != nil {
+//		return err
+//	}
+//
+//	return gen.Store(ctx, results)
+package generate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+
+	"github.com/slok/sloth/internal/alert"
+	appgenerate "github.com/slok/sloth/internal/app/generate"
+	"github.com/slok/sloth/internal/info"
+	"github.com/slok/sloth/internal/k8sprometheus"
+	"github.com/slok/sloth/internal/log"
+	"github.com/slok/sloth/internal/openslo"
+	"github.com/slok/sloth/internal/prometheus"
+	kubernetesv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// SLO is an SLO loaded from an SLO spec. The SLOs of all the spec formats are loaded as
+// this model, with the complete SLO model of the spec (SLI, alerts...) used by the generation.
+type SLO struct {
+	// ID is the SLO unique ID.
+	ID string
+	// Name is the SLO name.
+	Name string
+	// Service is the service of the SLO.
+	Service string
+	// Description is the SLO description.
+	Description string
+	// Objective is the SLO objective percent (e.g 99.9).
+	Objective float64
+	// TimeWindow is the SLO period.
+	TimeWindow time.Duration
+	// Labels are the SLO labels, added to all the generated rules of the SLO.
+	Labels map[string]string
+
+	model prometheus.SLO
+}
+
+func newSLO(slo prometheus.SLO) SLO {
+	labels := make(map[string]string, len(slo.Labels))
+	for k, v := range slo.Labels {
+		labels[k] = v
+	}
+
+	return SLO{
+		ID:          slo.ID,
+		Name:        slo.Name,
+		Service:     slo.Service,
+		Description: slo.Description,
+		Objective:   slo.Objective,
+		TimeWindow:  slo.TimeWindow,
+		Labels:      labels,
+		model:       slo,
+	}
+}
+
+// toModel returns the SLO model with the SLO fields, so the changes of the loaded SLOs are
+// generated.
+func (s SLO) toModel() prometheus.SLO {
+	slo := s.model
+	slo.ID = s.ID
+	slo.Name = s.Name
+	slo.Service = s.Service
+	slo.Description = s.Description
+	slo.Objective = s.Objective
+	slo.TimeWindow = s.TimeWindow
+	slo.Labels = s.Labels
+
+	return slo
+}
+
+// Result is the generated result of an SLO, with the SLO and its Prometheus rules.
+type Result struct {
+	SLO SLO
+	// SLIRecordingRules are the SLI error ratio recording rules of the SLO windows.
+	SLIRecordingRules []rulefmt.Rule
+	// MetadataRecordingRules are the SLO metadata recording rules (objective, error budget...).
+	MetadataRecordingRules []rulefmt.Rule
+	// AlertRules are the SLO multiwindow multi-burn rate alert rules.
+	AlertRules []rulefmt.Rule
+
+	alerts alert.MWMBAlertGroup
+}
+
+// SLOs are the SLOs loaded from an SLO spec.
+type SLOs struct {
+	// Spec is the SLO spec version of the SLOs (e.g `prometheus/v1`), set on the SLO
+	// info metadata recording rules.
+	Spec string
+	SLOs []SLO
+}
+
+func newSLOs(spec string, group prometheus.SLOGroup) *SLOs {
+	slos := make([]SLO, 0, len(group.SLOs))
+	for _, slo := range group.SLOs {
+		slos = append(slos, newSLO(slo))
+	}
+
+	return &SLOs{Spec: spec, SLOs: slos}
+}
+
+// SpecLoader knows how to load the SLOs of an SLO spec. The SLOs can only be loaded from the
+// Sloth supported spec formats, so the custom spec loaders wrap the default spec loader (e.g
+// to load the specs from an API or to modify the loaded SLOs).
+type SpecLoader interface {
+	LoadSpec(ctx context.Context, data []byte) (*SLOs, error)
+}
+
+// Storer knows how to store the generated SLO results.
+type Storer interface {
+	Store(ctx context.Context, results []Result) error
+}
+
+// Config is the generator configuration.
+type Config struct {
+	// SpecLoader loads the SLO specs, by default it loads all the Sloth supported spec
+	// formats without SLI plugins.
+	SpecLoader SpecLoader
+	// Storer stores the generated results, by default it stores them as Prometheus rules
+	// YAML on Out.
+	Storer Storer
+	// Out is the output of the default storer, required if the storer is not set.
+	Out io.Writer
+	// ExtraLabels are the extra labels added to all the generated rules.
+	ExtraLabels map[string]string
+	// DisableRecordings disables the recording rules generation.
+	DisableRecordings bool
+	// DisableAlerts disables the alert rules generation.
+	DisableAlerts bool
+}
+
+func (c *Config) defaults() error {
+	if c.SpecLoader == nil {
+		loader, err := NewSpecLoader(SpecLoaderConfig{})
+		if err != nil {
+			return err
+		}
+		c.SpecLoader = loader
+	}
+
+	if c.Storer == nil {
+		if c.Out == nil {
+			return fmt.Errorf("out is required without storer")
+		}
+		c.Storer = NewPrometheusRulesStorer(c.Out)
+	}
+
+	return nil
+}
+
+// Generator generates the Sloth SLO rules.
+type Generator struct {
+	loader      SpecLoader
+	storer      Storer
+	service     *appgenerate.Service
+	extraLabels map[string]string
+}
+
+// NewGenerator returns a new SLO rules generator.
+func NewGenerator(config Config) (*Generator, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Disable recording and alert rules if required.
+	var sliRuleGen appgenerate.SLIRecordingRulesGenerator = appgenerate.NoopSLIRecordingRulesGenerator
+	var metaRuleGen appgenerate.MetadataRecordingRulesGenerator = appgenerate.NoopMetadataRecordingRulesGenerator
+	if !config.DisableRecordings {
+		sliRuleGen = prometheus.SLIRecordingRulesGenerator
+		metaRuleGen = prometheus.MetadataRecordingRulesGenerator
+	}
+	var alertRuleGen appgenerate.SLOAlertRulesGenerator = appgenerate.NoopSLOAlertRulesGenerator
+	if !config.DisableAlerts {
+		alertRuleGen = prometheus.SLOAlertRulesGenerator
+	}
+
+	service, err := appgenerate.NewService(appgenerate.ServiceConfig{
+		AlertGenerator:              alert.AlertGenerator,
+		SLIRecordingRulesGenerator:  sliRuleGen,
+		MetaRecordingRulesGenerator: metaRuleGen,
+		SLOAlertRulesGenerator:      alertRuleGen,
+		Logger:                      log.Noop,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create generate service: %w", err)
+	}
+
+	return &Generator{
+		loader:      config.SpecLoader,
+		storer:      config.Storer,
+		service:     service,
+		extraLabels: config.ExtraLabels,
+	}, nil
+}
+
+// LoadSpec loads the SLOs of an SLO spec.
+func (g Generator) LoadSpec(ctx context.Context, data []byte) (*SLOs, error) {
+	slos, err := g.loader.LoadSpec(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("could not load SLO spec: %w", err)
+	}
+
+	return slos, nil
+}
+
+// Generate validates the SLOs and generates their results.
+func (g Generator) Generate(ctx context.Context, slos SLOs) ([]Result, error) {
+	group := prometheus.SLOGroup{SLOs: make([]prometheus.SLO, 0, len(slos.SLOs))}
+	for _, slo := range slos.SLOs {
+		group.SLOs = append(group.SLOs, slo.toModel())
+	}
+
+	res, err := g.service.Generate(ctx, appgenerate.Request{
+		Info: info.Info{
+			Version: info.Version,
+			Mode:    info.ModeLibGenPrometheus,
+			Spec:    slos.Spec,
+		},
+		ExtraLabels: g.extraLabels,
+		SLOGroup:    group,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not generate SLO rules: %w", err)
+	}
+
+	results := make([]Result, 0, len(res.PrometheusSLOs))
+	for _, r := range res.PrometheusSLOs {
+		results = append(results, Result{
+			SLO:                    newSLO(r.SLO),
+			SLIRecordingRules:      r.SLORules.SLIErrorRecRules,
+			MetadataRecordingRules: r.SLORules.MetadataRecRules,
+			AlertRules:             r.SLORules.AlertRules,
+			alerts:                 r.Alerts,
+		})
+	}
+
+	return results, nil
+}
+
+// Store stores the generated results.
+func (g Generator) Store(ctx context.Context, results []Result) error {
+	err := g.storer.Store(ctx, results)
+	if err != nil {
+		return fmt.Errorf("could not store SLO rules: %w", err)
+	}
+
+	return nil
+}
+
+// SpecLoaderConfig is the default spec loader configuration.
+type SpecLoaderConfig struct {
+	// SLIPluginsPaths are the local paths of the SLI plugins, if not set the SLI plugins
+	// are not supported.
+	SLIPluginsPaths []string
+	// SLIPluginsAllowExecutables enables the compiled SLI plugins (`sloth-sli-plugin`
+	// executables) of the SLI plugins paths, these run outside the Go plugins sandbox.
+	SLIPluginsAllowExecutables bool
+	// SLIPluginsTimeout is the maximum duration of an SLI plugin load or execution, if 0
+	// it will not have timeout.
+	SLIPluginsTimeout time.Duration
+	// SLIPluginsAllowedImports are the Go standard library packages that the SLI plugins
+	// can import, if not set it will use a safe default set.
+	SLIPluginsAllowedImports []string
+	// DefaultSLOPeriod is the SLO period of the specs that don't set one, by default 30d.
+	DefaultSLOPeriod time.Duration
+	// Environment is the environment (e.g `staging`) of the SLO specs environment overrides,
+	// if not set the SLOs don't use the overrides.
+	Environment string
+	// AlertWindowsCatalog are the YAML files data of the alert windows catalog, the SLOs
+	// select the alert windows by name, by service tier or by SLO period. If not set the
+	// SLOs use the Google SRE workbook alert windows.
+	AlertWindowsCatalog [][]byte
+	// Strict fails on the SLO spec fields that are unknown to the spec (e.g a typo in a
+	// field name), instead of ignoring them. OpenSLO specs are not checked.
+	Strict bool
+}
+
+type specLoader struct {
+	promLoader    prometheus.YAMLSpecLoader
+	kubeLoader    k8sprometheus.YAMLSpecLoader
+	openSLOLoader openslo.YAMLSpecLoader
+}
+
+// NewSpecLoader returns a spec loader that loads all the Sloth supported spec formats
+// (`prometheus/v1`, Kubernetes `sloth.slok.dev/v1` and OpenSLO `openslo/v1`).
+func NewSpecLoader(config SpecLoaderConfig) (SpecLoader, error) {
+	if config.DefaultSLOPeriod == 0 {
+		config.DefaultSLOPeriod = prometheus.DefaultSLOPeriod
+	}
+
+	pluginRepo, err := prometheus.NewFileSLIPluginRepo(prometheus.FileSLIPluginRepoConfig{
+		Paths:                  config.SLIPluginsPaths,
+		PluginAllowExecutables: config.SLIPluginsAllowExecutables,
+		PluginTimeout:          config.SLIPluginsTimeout,
+		PluginAllowedImports:   config.SLIPluginsAllowedImports,
+		Logger:                 log.Noop,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create SLI plugins repository: %w", err)
+	}
+
+	var alertWindows *alert.WindowsCatalog
+	if len(config.AlertWindowsCatalog) > 0 {
+		alertWindows, err = alert.NewWindowsCatalogFromYAML(config.AlertWindowsCatalog...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert windows catalog: %w", err)
+		}
+	}
+
+	return specLoader{
+		promLoader:    prometheus.NewYAMLSpecLoader(pluginRepo).WithDefaultSLOPeriod(config.DefaultSLOPeriod).WithAlertWindows(alertWindows).WithStrict(config.Strict).WithEnvironment(config.Environment),
+		kubeLoader:    k8sprometheus.NewYAMLSpecLoader(pluginRepo).WithDefaultSLOPeriod(config.DefaultSLOPeriod).WithAlertWindows(alertWindows).WithStrict(config.Strict).WithEnvironment(config.Environment),
+		openSLOLoader: openslo.NewYAMLSpecLoader().WithDefaultSLOPeriod(config.DefaultSLOPeriod).WithAlertWindows(alertWindows),
+	}, nil
+}
+
+func (s specLoader) LoadSpec(ctx context.Context, data []byte) (*SLOs, error) {
+	promSLOs, promErr := s.promLoader.LoadSpec(ctx, data)
+	if promErr == nil {
+		return newSLOs(prometheusv1.Version, *promSLOs), nil
+	}
+
+	kubeSLOs, kubeErr := s.kubeLoader.LoadSpec(ctx, data)
+	if kubeErr == nil {
+		spec := fmt.Sprintf("%s/%s", kubernetesv1.SchemeGroupVersion.Group, kubernetesv1.SchemeGroupVersion.Version)
+		return newSLOs(spec, kubeSLOs.SLOGroup), nil
+	}
+
+	openSLOSLOs, openSLOErr := s.openSLOLoader.LoadSpec(ctx, data)
+	if openSLOErr == nil {
+		return newSLOs(openslo.APIVersion, *openSLOSLOs), nil
+	}
+
+	return nil, fmt.Errorf("unsupported spec format: prometheus: %s, kubernetes: %s, openslo: %s", promErr, kubeErr, openSLOErr)
+}
+
+type prometheusRulesStorer struct {
+	repo prometheus.IOWriterGroupedRulesYAMLRepo
+}
+
+// NewPrometheusRulesStorer returns a storer that stores the generated results as Prometheus
+// rules YAML (the same as the Sloth CLI generation) on the output.
+func NewPrometheusRulesStorer(out io.Writer) Storer {
+	return prometheusRulesStorer{
+		repo: prometheus.NewIOWriterGroupedRulesYAMLRepo(out, prometheus.RuleGroupsMeta{}, log.Noop),
+	}
+}
+
+func (p prometheusRulesStorer) Store(ctx context.Context, results []Result) error {
+	slos := make([]prometheus.StorageSLO, 0, len(results))
+	for _, r := range results {
+		slos = append(slos, prometheus.StorageSLO{
+			SLO: r.SLO.toModel(),
+			Rules: prometheus.SLORules{
+				SLIErrorRecRules: r.SLIRecordingRules,
+				MetadataRecRules: r.MetadataRecordingRules,
+				AlertRules:       r.AlertRules,
+			},
+			Alerts: r.alerts,
+		})
+	}
+
+	return p.repo.StoreSLOs(ctx, slos)
+}
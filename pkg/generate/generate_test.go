@@ -0,0 +1,219 @@
+package generate_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/pkg/generate"
+)
+
+const testSpec = `
+version: "prometheus/v1"
+service: "myservice"
+slos:
+  - name: "requests-availability"
+    objective: 99.9
+    sli:
+      events:
+        error_query: sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))
+        total_query: sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))
+    alerting:
+      name: MyServiceHighErrorRate
+      page_alert:
+        labels:
+          severity: pageteam
+      ticket_alert:
+        labels:
+          severity: slack
+`
+
+const testOpenSLOSpec = `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: requests-availability
+spec:
+  service: myservice
+  indicator:
+    spec:
+      ratioMetric:
+        bad:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))'}}
+        total:
+          metricSource: {type: Prometheus, spec: {query: 'sum(rate(http_requests_total[{{.window}}]))'}}
+  timeWindow:
+    - duration: 30d
+      isRolling: true
+  objectives:
+    - target: 0.999
+`
+
+const testAlertWindowsCatalog = `
+alert_windows:
+  - name: slow-30d
+    slo_period: 30d
+    page:
+      quick: {error_budget_percent: 2, short_window: 10m, long_window: 2h}
+      slow: {error_budget_percent: 5, short_window: 30m, long_window: 6h}
+    ticket:
+      quick: {error_budget_percent: 10, short_window: 2h, long_window: 1d}
+      slow: {error_budget_percent: 10, short_window: 6h, long_window: 3d}
+`
+
+func TestGenerator(t *testing.T) {
+	tests := map[string]struct {
+		config       generate.Config
+		loaderConfig *generate.SpecLoaderConfig
+		spec         string
+		modifySLOs   func(slos *generate.SLOs)
+		expSpec      string
+		expSLOIDs    []string
+		expRecs      bool
+		expAlerts    bool
+		expOutGroups []string
+		expOut       []string
+		expLoadErr   bool
+	}{
+		"A Prometheus spec should generate and store the SLO rules.": {
+			config:    generate.Config{},
+			spec:      testSpec,
+			expSpec:   "prometheus/v1",
+			expSLOIDs: []string{"myservice-requests-availability"},
+			expRecs:   true,
+			expAlerts: true,
+			expOutGroups: []string{
+				"sloth-slo-sli-recordings-myservice-requests-availability",
+				"sloth-slo-meta-recordings-myservice-requests-availability",
+				"sloth-slo-alerts-myservice-requests-availability",
+			},
+		},
+
+		"An OpenSLO spec should report the OpenSLO spec version.": {
+			config:    generate.Config{},
+			spec:      testOpenSLOSpec,
+			expSpec:   "openslo/v1",
+			expSLOIDs: []string{"myservice-requests-availability"},
+			expRecs:   true,
+			expOut:    []string{`sloth_spec: openslo/v1`},
+		},
+
+		"Disabling the alerts should only generate and store the recording rules.": {
+			config:    generate.Config{DisableAlerts: true},
+			spec:      testSpec,
+			expSpec:   "prometheus/v1",
+			expSLOIDs: []string{"myservice-requests-availability"},
+			expRecs:   true,
+			expOutGroups: []string{
+				"sloth-slo-sli-recordings-myservice-requests-availability",
+				"sloth-slo-meta-recordings-myservice-requests-availability",
+			},
+		},
+
+		"Disabling the recordings should only generate and store the alert rules.": {
+			config:    generate.Config{DisableRecordings: true},
+			spec:      testSpec,
+			expSpec:   "prometheus/v1",
+			expSLOIDs: []string{"myservice-requests-availability"},
+			expAlerts: true,
+			expOutGroups: []string{
+				"sloth-slo-alerts-myservice-requests-availability",
+			},
+		},
+
+		"The changes of the loaded SLOs should be generated.": {
+			config:  generate.Config{},
+			spec:    testSpec,
+			expSpec: "prometheus/v1",
+			modifySLOs: func(slos *generate.SLOs) {
+				slos.SLOs[0].Objective = 99
+				slos.SLOs[0].Labels["team"] = "a-team"
+			},
+			expSLOIDs: []string{"myservice-requests-availability"},
+			expRecs:   true,
+			expAlerts: true,
+			expOut:    []string{`record: slo:objective:ratio`, `expr: vector(0.99)`, `team: a-team`},
+		},
+
+		"An alert windows catalog should be used by the SLOs.": {
+			config:       generate.Config{},
+			loaderConfig: &generate.SpecLoaderConfig{AlertWindowsCatalog: [][]byte{[]byte(testAlertWindowsCatalog)}},
+			spec:         testSpec,
+			expSpec:      "prometheus/v1",
+			expSLOIDs:    []string{"myservice-requests-availability"},
+			expRecs:      true,
+			expAlerts:    true,
+			expOut:       []string{`record: slo:sli_error:ratio_rate10m`},
+		},
+
+		"A spec with unknown fields should fail loading in strict mode.": {
+			config:       generate.Config{},
+			loaderConfig: &generate.SpecLoaderConfig{Strict: true},
+			spec:         testSpec + "unknown: true\n",
+			expLoadErr:   true,
+		},
+
+		"An unsupported spec should fail loading.": {
+			config:     generate.Config{},
+			spec:       `version: "unknown/v1"`,
+			expLoadErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var out bytes.Buffer
+			test.config.Out = &out
+			if test.loaderConfig != nil {
+				loader, err := generate.NewSpecLoader(*test.loaderConfig)
+				require.NoError(err)
+				test.config.SpecLoader = loader
+			}
+			gen, err := generate.NewGenerator(test.config)
+			require.NoError(err)
+
+			slos, err := gen.LoadSpec(context.TODO(), []byte(test.spec))
+			if test.expLoadErr {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+			assert.Equal(test.expSpec, slos.Spec)
+			if test.modifySLOs != nil {
+				test.modifySLOs(slos)
+			}
+
+			results, err := gen.Generate(context.TODO(), *slos)
+			require.NoError(err)
+
+			gotSLOIDs := []string{}
+			for _, r := range results {
+				gotSLOIDs = append(gotSLOIDs, r.SLO.ID)
+				assert.Equal(test.expRecs, len(r.SLIRecordingRules) > 0)
+				assert.Equal(test.expRecs, len(r.MetadataRecordingRules) > 0)
+				assert.Equal(test.expAlerts, len(r.AlertRules) > 0)
+			}
+			assert.Equal(test.expSLOIDs, gotSLOIDs)
+
+			err = gen.Store(context.TODO(), results)
+			require.NoError(err)
+			for _, g := range test.expOutGroups {
+				assert.Contains(out.String(), "- name: "+g+"\n")
+			}
+			for _, o := range test.expOut {
+				assert.Contains(out.String(), o)
+			}
+		})
+	}
+}
+
+func TestNewGeneratorWithoutOutput(t *testing.T) {
+	_, err := generate.NewGenerator(generate.Config{})
+	assert.Error(t, err)
+}
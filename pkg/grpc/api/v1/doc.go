@@ -0,0 +1,9 @@
+// Package v1 has the Sloth serve mode gRPC API (`sloth.v1.SlothService`) messages, client and
+// server, generated from the published sloth.proto. The typed clients of other languages can
+// be generated from the same proto file.
+//
+// The API serves the SLOs inventory of the served SLO specs, the SLO specs validation and the
+// Prometheus rules generation, streamed in chunks for the large rule outputs.
+package v1
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. sloth.proto
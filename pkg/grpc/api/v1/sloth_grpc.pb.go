@@ -0,0 +1,221 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: sloth.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SlothService_ListSLOs_FullMethodName      = "/sloth.v1.SlothService/ListSLOs"
+	SlothService_ValidateSpec_FullMethodName  = "/sloth.v1.SlothService/ValidateSpec"
+	SlothService_GenerateRules_FullMethodName = "/sloth.v1.SlothService/GenerateRules"
+)
+
+// SlothServiceClient is the client API for SlothService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SlothServiceClient interface {
+	// ListSLOs lists the SLOs of the served SLO specs.
+	ListSLOs(ctx context.Context, in *ListSLOsRequest, opts ...grpc.CallOption) (*ListSLOsResponse, error)
+	// ValidateSpec validates an SLO spec (`prometheus/v1`, Kubernetes `sloth.slok.dev/v1` or
+	// OpenSLO `openslo/v1`).
+	ValidateSpec(ctx context.Context, in *ValidateSpecRequest, opts ...grpc.CallOption) (*ValidateSpecResponse, error)
+	// GenerateRules generates the Prometheus rules YAML of an SLO spec, streamed in chunks
+	// so the large rule outputs don't hit the messages size limits.
+	GenerateRules(ctx context.Context, in *GenerateRulesRequest, opts ...grpc.CallOption) (SlothService_GenerateRulesClient, error)
+}
+
+type slothServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSlothServiceClient(cc grpc.ClientConnInterface) SlothServiceClient {
+	return &slothServiceClient{cc}
+}
+
+func (c *slothServiceClient) ListSLOs(ctx context.Context, in *ListSLOsRequest, opts ...grpc.CallOption) (*ListSLOsResponse, error) {
+	out := new(ListSLOsResponse)
+	err := c.cc.Invoke(ctx, SlothService_ListSLOs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slothServiceClient) ValidateSpec(ctx context.Context, in *ValidateSpecRequest, opts ...grpc.CallOption) (*ValidateSpecResponse, error) {
+	out := new(ValidateSpecResponse)
+	err := c.cc.Invoke(ctx, SlothService_ValidateSpec_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slothServiceClient) GenerateRules(ctx context.Context, in *GenerateRulesRequest, opts ...grpc.CallOption) (SlothService_GenerateRulesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SlothService_ServiceDesc.Streams[0], SlothService_GenerateRules_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &slothServiceGenerateRulesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SlothService_GenerateRulesClient interface {
+	Recv() (*GenerateRulesResponse, error)
+	grpc.ClientStream
+}
+
+type slothServiceGenerateRulesClient struct {
+	grpc.ClientStream
+}
+
+func (x *slothServiceGenerateRulesClient) Recv() (*GenerateRulesResponse, error) {
+	m := new(GenerateRulesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SlothServiceServer is the server API for SlothService service.
+// All implementations must embed UnimplementedSlothServiceServer
+// for forward compatibility
+type SlothServiceServer interface {
+	// ListSLOs lists the SLOs of the served SLO specs.
+	ListSLOs(context.Context, *ListSLOsRequest) (*ListSLOsResponse, error)
+	// ValidateSpec validates an SLO spec (`prometheus/v1`, Kubernetes `sloth.slok.dev/v1` or
+	// OpenSLO `openslo/v1`).
+	ValidateSpec(context.Context, *ValidateSpecRequest) (*ValidateSpecResponse, error)
+	// GenerateRules generates the Prometheus rules YAML of an SLO spec, streamed in chunks
+	// so the large rule outputs don't hit the messages size limits.
+	GenerateRules(*GenerateRulesRequest, SlothService_GenerateRulesServer) error
+	mustEmbedUnimplementedSlothServiceServer()
+}
+
+// UnimplementedSlothServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSlothServiceServer struct {
+}
+
+func (UnimplementedSlothServiceServer) ListSLOs(context.Context, *ListSLOsRequest) (*ListSLOsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSLOs not implemented")
+}
+func (UnimplementedSlothServiceServer) ValidateSpec(context.Context, *ValidateSpecRequest) (*ValidateSpecResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateSpec not implemented")
+}
+func (UnimplementedSlothServiceServer) GenerateRules(*GenerateRulesRequest, SlothService_GenerateRulesServer) error {
+	return status.Errorf(codes.Unimplemented, "method GenerateRules not implemented")
+}
+func (UnimplementedSlothServiceServer) mustEmbedUnimplementedSlothServiceServer() {}
+
+// UnsafeSlothServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SlothServiceServer will
+// result in compilation errors.
+type UnsafeSlothServiceServer interface {
+	mustEmbedUnimplementedSlothServiceServer()
+}
+
+func RegisterSlothServiceServer(s grpc.ServiceRegistrar, srv SlothServiceServer) {
+	s.RegisterService(&SlothService_ServiceDesc, srv)
+}
+
+func _SlothService_ListSLOs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSLOsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlothServiceServer).ListSLOs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SlothService_ListSLOs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlothServiceServer).ListSLOs(ctx, req.(*ListSLOsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlothService_ValidateSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateSpecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlothServiceServer).ValidateSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SlothService_ValidateSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlothServiceServer).ValidateSpec(ctx, req.(*ValidateSpecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlothService_GenerateRules_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateRulesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SlothServiceServer).GenerateRules(m, &slothServiceGenerateRulesServer{stream})
+}
+
+type SlothService_GenerateRulesServer interface {
+	Send(*GenerateRulesResponse) error
+	grpc.ServerStream
+}
+
+type slothServiceGenerateRulesServer struct {
+	grpc.ServerStream
+}
+
+func (x *slothServiceGenerateRulesServer) Send(m *GenerateRulesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// SlothService_ServiceDesc is the grpc.ServiceDesc for SlothService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SlothService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sloth.v1.SlothService",
+	HandlerType: (*SlothServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSLOs",
+			Handler:    _SlothService_ListSLOs_Handler,
+		},
+		{
+			MethodName: "ValidateSpec",
+			Handler:    _SlothService_ValidateSpec_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GenerateRules",
+			Handler:       _SlothService_GenerateRules_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sloth.proto",
+}
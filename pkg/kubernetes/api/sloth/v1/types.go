@@ -34,16 +34,62 @@ type PrometheusServiceLevelSpec struct {
 	// Service is the application of the SLOs.
 	Service string `json:"service"`
 
+	// SLOPeriod is the time window of the service SLOs (e.g `7d`, `28d`, `30d`, `90d`),
+	// the alert windows are scaled to it. By default `30d`.
+	// +optional
+	SLOPeriod string `json:"sloPeriod,omitempty"`
+
 	// Labels are the Prometheus labels that will have all the recording
-	// and alerting rules generated for the service SLOs.
+	// and alerting rules generated for the service SLOs. Sloth reserved
+	// labels (e.g `sloth_id`) can't be used.
 	Labels map[string]string `json:"labels,omitempty"`
 
+	// Annotations are the Prometheus annotations that will have all the alerting
+	// rules generated for the service SLOs (recording rules don't support annotations).
+	// Annotations at any level can use Sloth templates with `[[ ]]` delimiters that are
+	// rendered on generation with the SLO (`.SLO.ID`, `.SLO.Name`, `.SLO.Service`,
+	// `.SLO.Description`, `.SLO.Objective`, `.SLO.ErrorBudget`, `.SLO.TimeWindow` and
+	// `.SLO.Labels`) and alert (`.Alert.Name`, `.Alert.Severity`, `.Alert.Labels` and the
+	// `.Alert.Quick` and `.Alert.Slow` `ShortWindow`, `LongWindow` and `BurnRateFactor`)
+	// data (e.g `[[ .SLO.Objective ]]% of [[ .SLO.Labels.team ]] SLO`). Missing keys fail
+	// on validation. Prometheus `{{ }}` templates are kept as they are.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Cost is the cost attribution of the service SLOs.
+	// +optional
+	Cost *Cost `json:"cost,omitempty"`
+
+	// AlertingDefaults are the default alerting settings of the service SLOs alerts.
+	// +optional
+	AlertingDefaults *AlertingDefaults `json:"alertingDefaults,omitempty"`
+
 	// +kubebuilder:validation:MinItems=1
 	//
 	// SLOs are the SLOs of the service.
 	SLOs []SLO `json:"slos,omitempty"`
 }
 
+// Cost is the cost attribution (e.g cost center, product...) of the SLOs, used
+// to attribute the reliability spend using the generated rules series.
+type Cost struct {
+	// Labels are the cost attribution Prometheus labels (e.g `cost_center`) that will
+	// have all the recording rules generated for the service SLOs. These labels are
+	// merged with the SLO labels and have preference over them.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// AlertingDefaults are the default alerting settings of the SLOs, so the common
+// alerts settings (e.g runbook and dashboard links) are not repeated on every SLO.
+type AlertingDefaults struct {
+	// Annotations are the default Prometheus annotations of all the alerting rules
+	// generated for the service SLOs. The service, SLO and alerting annotations have
+	// preference over them.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
 // SLO is the configuration/declaration of the service level objective of
 // a service.
 type SLO struct {
@@ -58,8 +104,12 @@ type SLO struct {
 	Description string `json:"description,omitempty"`
 
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:ExclusiveMaximum=true
 	//
-	// Objective is target of the SLO the percentage (0, 100] (e.g 99.9).
+	// Objective is target of the SLO the percentage (0, 100) (e.g 99.9).
 	Objective float64 `json:"objective"`
 
 	// Labels are the Prometheus labels that will have all the recording and
@@ -68,6 +118,12 @@ type SLO struct {
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
 
+	// Annotations are the Prometheus annotations that will have all the alerting
+	// rules for this specific SLO. These annotations are merged with the previous
+	// level annotations.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
 	// +kubebuilder:validation:Required
 	//
 	// SLI is the indicator (service level indicator) for this specific SLO.
@@ -78,6 +134,100 @@ type SLO struct {
 	// Alerting is the configuration with all the things related with the SLO
 	// alerts.
 	Alerting Alerting `json:"alerting"`
+
+	// RuleGroupIntervals are the evaluation intervals of the rule groups generated for
+	// this specific SLO (e.g a slower `2m` interval for the rules of a 30d SLO), if not
+	// set the rule groups use the ruler global evaluation interval.
+	// +optional
+	RuleGroupIntervals *RuleGroupIntervals `json:"ruleGroupIntervals,omitempty"`
+
+	// Transition is the previous objective and time window of an SLO that has been
+	// changed. When set, Sloth will generate transitional recording rules with the
+	// previous values and the change metadata, so dashboards can distinguish the error
+	// budgets before and after the change instead of having a series discontinuity.
+	// +optional
+	Transition *SLOTransition `json:"transition,omitempty"`
+
+	// ReportingWindows are extra time windows (e.g `7d`) used only to report the
+	// SLO, Sloth will generate the reporting recording rules for each of them. The
+	// alerts use the SLO period.
+	// +optional
+	ReportingWindows []string `json:"reportingWindows,omitempty"`
+
+	// Timeslice makes the SLO a timeslice (windows-based) SLO, the SLO compliance is
+	// measured as the ratio of good time slices (e.g `1m` slices with an SLI error
+	// ratio under a threshold) instead of the events ratio.
+	// +optional
+	Timeslice *SLOTimeslice `json:"timeslice,omitempty"`
+
+	// Environments are the SLO overrides by environment name (e.g `staging`), applied
+	// when the SLOs are generated for the environment (e.g `--env staging`), so the
+	// environments can have their own objectives from the same spec.
+	// +optional
+	Environments map[string]SLOEnvironment `json:"environments,omitempty"`
+}
+
+// SLOEnvironment is the override of an SLO for an environment, the unset fields
+// use the SLO values.
+type SLOEnvironment struct {
+	// Objective is the SLO objective on the environment.
+	// +optional
+	Objective float64 `json:"objective,omitempty"`
+
+	// SLOPeriod is the SLO time window on the environment (e.g `7d`), the alert
+	// windows are scaled to it.
+	// +optional
+	SLOPeriod string `json:"sloPeriod,omitempty"`
+}
+
+// SLOTransition is the previous state of a changed SLO.
+type SLOTransition struct {
+	// PreviousObjective is the SLO objective before the change, by default the
+	// current objective.
+	// +optional
+	PreviousObjective float64 `json:"previousObjective,omitempty"`
+
+	// PreviousTimeWindow is the SLO time window before the change (e.g `28d`), by
+	// default the current time window.
+	// +optional
+	PreviousTimeWindow string `json:"previousTimeWindow,omitempty"`
+
+	// ChangedAt is the date of the change (e.g `2021-06-30`).
+	// +optional
+	ChangedAt string `json:"changedAt,omitempty"`
+}
+
+// RuleGroupIntervals are the evaluation intervals of the rule groups of an SLO, the SLI
+// recordings, metadata recordings and alerts are generated on different rule groups.
+type RuleGroupIntervals struct {
+	// Default is the interval of the rule groups that don't set their own interval (e.g `1m`).
+	// +optional
+	Default string `json:"default,omitempty"`
+
+	// SLIRecordings is the interval of the SLI recording rules group.
+	// +optional
+	SLIRecordings string `json:"sliRecordings,omitempty"`
+
+	// MetaRecordings is the interval of the metadata recording rules group.
+	// +optional
+	MetaRecordings string `json:"metaRecordings,omitempty"`
+
+	// Alerts is the interval of the alert rules group.
+	// +optional
+	Alerts string `json:"alerts,omitempty"`
+}
+
+// SLOTimeslice are the time slices of a timeslice SLO. A time slice is bad when
+// its SLI error ratio is greater than the error ratio threshold, and the SLO error
+// ratio of a window is the ratio of bad time slices on the window.
+type SLOTimeslice struct {
+	// Window is the duration of the time slices (e.g `1m`).
+	Window string `json:"window"`
+
+	// ErrorRatioThreshold is the maximum SLI error ratio (0-1) of a good time slice
+	// (e.g `0.01`).
+	// +optional
+	ErrorRatioThreshold float64 `json:"errorRatioThreshold,omitempty"`
 }
 
 // SLI will tell what is good or bad for the SLO.
@@ -97,6 +247,37 @@ type SLI struct {
 	// Plugin is the pluggable SLI type.
 	// +optional
 	Plugin *SLIPlugin `json:"plugin,omitempty"`
+
+	// Latency is the latency SLI type, generated from a histogram.
+	// +optional
+	Latency *SLILatency `json:"latency,omitempty"`
+
+	// Offset is a Prometheus (e.g `5m`) or human-friendly (e.g `5 minutes`) duration
+	// that will be applied to all the SLI expression selectors, used to tolerate late
+	// data (e.g delayed remote write).
+	// +optional
+	Offset string `json:"offset,omitempty"`
+
+	// Examples are example values of the SLI series with the SLI error ratio expected
+	// from them, `sloth validate --examples` evaluates the SLI with them to test the
+	// SLI logic without a Prometheus.
+	// +optional
+	Examples []SLIExample `json:"examples,omitempty"`
+}
+
+// SLIExample is an example of the SLI series values and the SLI error ratio expected
+// from them. The series values are the values of the SLI range functions (e.g the `rate`
+// of a counter), so the example is the same for all the SLI windows.
+type SLIExample struct {
+	// Name is the name of the example.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Series are the values of the SLI series, indexed by series (e.g `http_requests_total{code="500"}`).
+	Series map[string]float64 `json:"series"`
+
+	// ErrorRatio is the SLI error ratio (0-1) expected from the series values.
+	ErrorRatio float64 `json:"errorRatio"`
 }
 
 // SLIRaw is a error ratio SLI already calculated. Normally this will be used when the SLI
@@ -106,6 +287,27 @@ type SLIRaw struct {
 	ErrorRatioQuery string `json:"errorRatioQuery"`
 }
 
+// SLILatency is a latency SLI of a Prometheus histogram in seconds, the bad events are the
+// histogram observations slower than the threshold. Sloth will generate the events queries
+// using the histogram buckets.
+type SLILatency struct {
+	// HistogramMetric is the histogram metric base name (e.g `http_request_duration_seconds`).
+	HistogramMetric string `json:"histogramMetric"`
+
+	// Threshold is the latency duration (e.g `300ms`) of the good events, on classic
+	// histograms it must be one of the histogram buckets.
+	Threshold string `json:"threshold"`
+
+	// Selector are the histogram series label matchers (e.g `job="api", code!~"5.."`).
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// NativeHistogram makes the SLI use a Prometheus native histogram instead of the
+	// classic histogram `_bucket` and `_count` series.
+	// +optional
+	NativeHistogram bool `json:"nativeHistogram,omitempty"`
+}
+
 // SLIEvents is an SLI that is calculated as the division of bad events and total events, giving
 // a ratio SLI. Normally this is the most common ratio type.
 type SLIEvents struct {
@@ -128,6 +330,23 @@ type SLIPlugin struct {
 	// Options are the options used for the plugin.
 	// +optional
 	Options map[string]string `json:"options,omitempty"`
+
+	// Chain are the plugins that will be executed in order after this plugin, every
+	// plugin receives the SLI query of the previous plugin on the `query` metadata key
+	// (e.g a filter plugin that excludes the canary traffic of a base availability plugin
+	// query). The SLI query is the one returned by the last plugin.
+	// +optional
+	Chain []ChainedSLIPlugin `json:"chain,omitempty"`
+}
+
+// ChainedSLIPlugin is an SLI plugin executed after another SLI plugin.
+type ChainedSLIPlugin struct {
+	// ID is the ID of the plugin that needs to load.
+	ID string `json:"id"`
+
+	// Options are the options used for the plugin.
+	// +optional
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // Alerting wraps all the configuration required by the SLO alerts.
@@ -150,6 +369,60 @@ type Alerting struct {
 
 	// TicketAlert alert refers to the warning alert (check multiwindow-multiburn alerts).
 	TicketAlert Alert `json:"ticketAlert,omitempty"`
+
+	// DependsOn are the IDs (`{service}-{slo}`) of the SLOs this SLO depends on. When
+	// Alertmanager inhibition rules are generated, the page alert of these SLOs will
+	// inhibit the page alert of this SLO.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Guard is a Prometheus expression that will be added with an `and on()` to all the
+	// alerts generated by this SLO (e.g `cluster_maintenance == 0`), the alerts will only
+	// fire when the guard returns any series. This can be used to integrate the alerts
+	// with existing signals like maintenance modes.
+	// +optional
+	Guard string `json:"guard,omitempty"`
+
+	// Windows is the name of the alert windows catalog profile used by the SLO alerts, by
+	// default it will use the catalog profile of the SLO period, or the Google SRE workbook
+	// recommended alert windows if there is none.
+	// +optional
+	Windows string `json:"windows,omitempty"`
+
+	// MaintenanceWindows are the recurring time windows where the alerts of this SLO are
+	// muted, used to generate the Alertmanager time intervals.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// MaintenanceWindow is a recurring time window (e.g a maintenance window or the out of
+// business hours) where the SLO alerts are muted by Alertmanager.
+type MaintenanceWindow struct {
+	// Name is the name of the window, used as the Alertmanager time interval name. The
+	// windows with the same name must be the same on all the SLOs.
+	Name string `json:"name"`
+
+	// Times are the time ranges of the day (e.g `02:00-04:00`), by default all the day.
+	// +optional
+	Times []string `json:"times,omitempty"`
+
+	// Weekdays are the days of the week or ranges (e.g `saturday`, `monday:friday`), by
+	// default all the days.
+	// +optional
+	Weekdays []string `json:"weekdays,omitempty"`
+
+	// DaysOfMonth are the days of the month or ranges (e.g `1`, `1:5`, `-1`), by default
+	// all the days.
+	// +optional
+	DaysOfMonth []string `json:"daysOfMonth,omitempty"`
+
+	// Months are the months or ranges (e.g `january`, `1:3`), by default all the months.
+	// +optional
+	Months []string `json:"months,omitempty"`
+
+	// Location is the time zone of the window (e.g `Europe/Madrid`), by default `UTC`.
+	// +optional
+	Location string `json:"location,omitempty"`
 }
 
 // Alert configures specific SLO alert.
@@ -163,9 +436,15 @@ type Alert struct {
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
 
-	// Annotations are the Prometheus annotations for the specific alert.
+	// Annotations are the Prometheus annotations for the specific alert, these can
+	// use Sloth `[[ ]]` templates.
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// For is the duration the alert conditions must be met before firing (e.g `5m`),
+	// it overrides the alert windows `for` duration.
+	// +optional
+	For string `json:"for,omitempty"`
 }
 
 type PrometheusServiceLevelStatus struct {
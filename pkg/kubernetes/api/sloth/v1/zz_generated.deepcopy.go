@@ -73,6 +73,18 @@ func (in *Alerting) DeepCopyInto(out *Alerting) {
 	}
 	in.PageAlert.DeepCopyInto(&out.PageAlert)
 	in.TicketAlert.DeepCopyInto(&out.TicketAlert)
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -86,6 +98,111 @@ func (in *Alerting) DeepCopy() *Alerting {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertingDefaults) DeepCopyInto(out *AlertingDefaults) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertingDefaults.
+func (in *AlertingDefaults) DeepCopy() *AlertingDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertingDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChainedSLIPlugin) DeepCopyInto(out *ChainedSLIPlugin) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChainedSLIPlugin.
+func (in *ChainedSLIPlugin) DeepCopy() *ChainedSLIPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(ChainedSLIPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cost) DeepCopyInto(out *Cost) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cost.
+func (in *Cost) DeepCopy() *Cost {
+	if in == nil {
+		return nil
+	}
+	out := new(Cost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Times != nil {
+		in, out := &in.Times, &out.Times
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Weekdays != nil {
+		in, out := &in.Weekdays, &out.Weekdays
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DaysOfMonth != nil {
+		in, out := &in.DaysOfMonth, &out.DaysOfMonth
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Months != nil {
+		in, out := &in.Months, &out.Months
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusServiceLevel) DeepCopyInto(out *PrometheusServiceLevel) {
 	*out = *in
@@ -150,6 +267,23 @@ func (in *PrometheusServiceLevelList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusServiceLevelSpec) DeepCopyInto(out *PrometheusServiceLevelSpec) {
 	*out = *in
+	if in.AlertingDefaults != nil {
+		in, out := &in.AlertingDefaults, &out.AlertingDefaults
+		*out = new(AlertingDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Cost != nil {
+		in, out := &in.Cost, &out.Cost
+		*out = new(Cost)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
@@ -197,6 +331,21 @@ func (in *PrometheusServiceLevelStatus) DeepCopy() *PrometheusServiceLevelStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleGroupIntervals) DeepCopyInto(out *RuleGroupIntervals) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleGroupIntervals.
+func (in *RuleGroupIntervals) DeepCopy() *RuleGroupIntervals {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleGroupIntervals)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SLI) DeepCopyInto(out *SLI) {
 	*out = *in
@@ -215,6 +364,18 @@ func (in *SLI) DeepCopyInto(out *SLI) {
 		*out = new(SLIPlugin)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(SLILatency)
+		**out = **in
+	}
+	if in.Examples != nil {
+		in, out := &in.Examples, &out.Examples
+		*out = make([]SLIExample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -228,6 +389,29 @@ func (in *SLI) DeepCopy() *SLI {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLIExample) DeepCopyInto(out *SLIExample) {
+	*out = *in
+	if in.Series != nil {
+		in, out := &in.Series, &out.Series
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLIExample.
+func (in *SLIExample) DeepCopy() *SLIExample {
+	if in == nil {
+		return nil
+	}
+	out := new(SLIExample)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SLIEvents) DeepCopyInto(out *SLIEvents) {
 	*out = *in
@@ -244,6 +428,22 @@ func (in *SLIEvents) DeepCopy() *SLIEvents {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLILatency) DeepCopyInto(out *SLILatency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLILatency.
+func (in *SLILatency) DeepCopy() *SLILatency {
+	if in == nil {
+		return nil
+	}
+	out := new(SLILatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SLIPlugin) DeepCopyInto(out *SLIPlugin) {
 	*out = *in
@@ -254,6 +454,13 @@ func (in *SLIPlugin) DeepCopyInto(out *SLIPlugin) {
 			(*out)[key] = val
 		}
 	}
+	if in.Chain != nil {
+		in, out := &in.Chain, &out.Chain
+		*out = make([]ChainedSLIPlugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -286,6 +493,13 @@ func (in *SLIRaw) DeepCopy() *SLIRaw {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SLO) DeepCopyInto(out *SLO) {
 	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
@@ -295,6 +509,33 @@ func (in *SLO) DeepCopyInto(out *SLO) {
 	}
 	in.SLI.DeepCopyInto(&out.SLI)
 	in.Alerting.DeepCopyInto(&out.Alerting)
+	if in.RuleGroupIntervals != nil {
+		in, out := &in.RuleGroupIntervals, &out.RuleGroupIntervals
+		*out = new(RuleGroupIntervals)
+		**out = **in
+	}
+	if in.Transition != nil {
+		in, out := &in.Transition, &out.Transition
+		*out = new(SLOTransition)
+		**out = **in
+	}
+	if in.ReportingWindows != nil {
+		in, out := &in.ReportingWindows, &out.ReportingWindows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeslice != nil {
+		in, out := &in.Timeslice, &out.Timeslice
+		*out = new(SLOTimeslice)
+		**out = **in
+	}
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make(map[string]SLOEnvironment, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -307,3 +548,51 @@ func (in *SLO) DeepCopy() *SLO {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLOEnvironment) DeepCopyInto(out *SLOEnvironment) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLOEnvironment.
+func (in *SLOEnvironment) DeepCopy() *SLOEnvironment {
+	if in == nil {
+		return nil
+	}
+	out := new(SLOEnvironment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLOTimeslice) DeepCopyInto(out *SLOTimeslice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLOTimeslice.
+func (in *SLOTimeslice) DeepCopy() *SLOTimeslice {
+	if in == nil {
+		return nil
+	}
+	out := new(SLOTimeslice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLOTransition) DeepCopyInto(out *SLOTransition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLOTransition.
+func (in *SLOTransition) DeepCopy() *SLOTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(SLOTransition)
+	in.DeepCopyInto(out)
+	return out
+}
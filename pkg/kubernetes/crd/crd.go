@@ -0,0 +1,15 @@
+// Package crd has the Sloth Kubernetes CRD manifests, so they can be installed
+// from Go (e.g on test environments).
+//
+// The manifests are copies of the generated CRD manifests (`pkg/kubernetes/gen/crd`),
+// updated by the Kubernetes code generation script.
+package crd
+
+import (
+	_ "embed" // Required by the go:embed directives.
+)
+
+// PrometheusServiceLevel is the `PrometheusServiceLevel` CRD YAML manifest.
+//
+//go:embed sloth.slok.dev_prometheusservicelevels.yaml
+var PrometheusServiceLevel []byte
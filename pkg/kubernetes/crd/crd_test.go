@@ -0,0 +1,32 @@
+package crd_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/pkg/kubernetes/crd"
+)
+
+func TestCRDsAreTheGeneratedOnes(t *testing.T) {
+	tests := map[string]struct {
+		crd     []byte
+		genPath string
+	}{
+		"The PrometheusServiceLevel CRD should be the generated one.": {
+			crd:     crd.PrometheusServiceLevel,
+			genPath: "../gen/crd/sloth.slok.dev_prometheusservicelevels.yaml",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			genCRD, err := os.ReadFile(test.genPath)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(genCRD), string(test.crd))
+		})
+	}
+}
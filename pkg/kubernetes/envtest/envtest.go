@@ -0,0 +1,420 @@
+// Package envtest has a test environment to run integration tests of the Kubernetes
+// controllers and reconcilers that embed Sloth, against a real Kubernetes API server.
+//
+// The environment runs `etcd` and `kube-apiserver` local binaries (the same binary assets
+// used by controller-runtime envtest, e.g installed with `setup-envtest`) with the Sloth
+// CRDs installed, e.g:
+//
+//	func TestMyReconciler(t *testing.T) {
+//		env := envtest.NewTestEnvironment(t, envtest.Config{CRDs: [][]byte{promOperatorCRDs}})
+//		ns := envtest.NewTestNamespace(t, env)
+//
+//		slos := envtest.NewPrometheusServiceLevel(ns, "test")
+//		_, err := env.Clients().Sloth.SlothV1().PrometheusServiceLevels(ns).Create(ctx, slos, metav1.CreateOptions{})
+//		...
+//	}
+package envtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	monitoringclientset "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubeyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/slok/sloth/pkg/kubernetes/crd"
+	slothclientset "github.com/slok/sloth/pkg/kubernetes/gen/clientset/versioned"
+)
+
+// EnvBinaryAssetsDirectory is the env var used by default to get the binary assets
+// directory, the same one used by controller-runtime envtest.
+const EnvBinaryAssetsDirectory = "KUBEBUILDER_ASSETS"
+
+// ErrBinaryAssetsMissing is returned when the environment binary assets can't be found.
+var ErrBinaryAssetsMissing = errors.New("etcd and kube-apiserver binary assets missing")
+
+// Config is the test environment configuration.
+type Config struct {
+	// BinaryAssetsDirectory is the directory with the `etcd` and `kube-apiserver` binaries.
+	// By default it will use `KUBEBUILDER_ASSETS` env var.
+	BinaryAssetsDirectory string
+	// CRDs are extra CRDs YAML manifests (can be multi document) that will be installed
+	// with the Sloth CRDs (e.g prometheus-operator `PrometheusRule` CRD).
+	CRDs [][]byte
+	// StartTimeout is the maximum duration to wait for the environment to be ready.
+	StartTimeout time.Duration
+	// Output is where the etcd and kube-apiserver output will be written, by default
+	// it will be discarded.
+	Output io.Writer
+}
+
+func (c *Config) defaults() error {
+	if c.BinaryAssetsDirectory == "" {
+		c.BinaryAssetsDirectory = os.Getenv(EnvBinaryAssetsDirectory)
+	}
+
+	for _, bin := range []string{"etcd", "kube-apiserver"} {
+		_, err := os.Stat(filepath.Join(c.BinaryAssetsDirectory, bin))
+		if err != nil {
+			return fmt.Errorf("%w: %q binary not found on %q", ErrBinaryAssetsMissing, bin, c.BinaryAssetsDirectory)
+		}
+	}
+
+	if c.StartTimeout == 0 {
+		c.StartTimeout = time.Minute
+	}
+
+	if c.Output == nil {
+		c.Output = io.Discard
+	}
+
+	return nil
+}
+
+// Clients are the Kubernetes clients of the environment.
+type Clients struct {
+	Std        kubernetes.Interface
+	Sloth      slothclientset.Interface
+	Monitoring monitoringclientset.Interface
+	Dynamic    dynamic.Interface
+}
+
+// Environment is a Kubernetes test environment.
+type Environment struct {
+	cfg        Config
+	dir        string
+	etcdURL    string
+	etcd       *exec.Cmd
+	apiserver  *exec.Cmd
+	restConfig *rest.Config
+	clients    *Clients
+}
+
+// NewEnvironment returns a new test environment, it needs to be started.
+func NewEnvironment(cfg Config) (*Environment, error) {
+	err := cfg.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &Environment{cfg: cfg}, nil
+}
+
+// Start starts the environment and installs the CRDs, on errors the environment is stopped.
+func (e *Environment) Start(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			_ = e.Stop()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.StartTimeout)
+	defer cancel()
+
+	e.dir, err = os.MkdirTemp("", "sloth-envtest-")
+	if err != nil {
+		return fmt.Errorf("could not create environment directory: %w", err)
+	}
+
+	err = e.startEtcd()
+	if err != nil {
+		return fmt.Errorf("could not start etcd: %w", err)
+	}
+
+	err = e.startAPIServer()
+	if err != nil {
+		return fmt.Errorf("could not start kube-apiserver: %w", err)
+	}
+
+	err = e.newClients()
+	if err != nil {
+		return err
+	}
+
+	err = e.waitAPIServerReady(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = e.installCRDs(ctx, append([][]byte{crd.PrometheusServiceLevel}, e.cfg.CRDs...))
+	if err != nil {
+		return fmt.Errorf("could not install CRDs: %w", err)
+	}
+
+	return nil
+}
+
+// Stop stops the environment and removes its data.
+func (e *Environment) Stop() error {
+	// Stop in reverse order.
+	for _, cmd := range []*exec.Cmd{e.apiserver, e.etcd} {
+		if cmd == nil || cmd.Process == nil {
+			continue
+		}
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}
+	e.apiserver, e.etcd = nil, nil
+
+	if e.dir != "" {
+		err := os.RemoveAll(e.dir)
+		if err != nil {
+			return fmt.Errorf("could not remove environment directory: %w", err)
+		}
+		e.dir = ""
+	}
+
+	return nil
+}
+
+// RESTConfig returns the Kubernetes REST client configuration of the environment
+// API server, with admin permissions.
+func (e *Environment) RESTConfig() *rest.Config { return rest.CopyConfig(e.restConfig) }
+
+// Clients returns the Kubernetes clients of the environment.
+func (e *Environment) Clients() *Clients { return e.clients }
+
+// CreateNamespace creates a new random namespace. The environment doesn't run the
+// Kubernetes controllers, so namespaces are not deleted, tests should use a new one
+// for isolation instead.
+func (e *Environment) CreateNamespace(ctx context.Context) (string, error) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "sloth-test-"}}
+	ns, err := e.clients.Std.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not create namespace: %w", err)
+	}
+
+	return ns.Name, nil
+}
+
+func (e *Environment) startEtcd() error {
+	clientPort, err := freePort()
+	if err != nil {
+		return err
+	}
+	peerPort, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	e.etcdURL = localURL("http", clientPort)
+	e.etcd = exec.Command(filepath.Join(e.cfg.BinaryAssetsDirectory, "etcd"),
+		"--data-dir", filepath.Join(e.dir, "etcd"),
+		"--listen-client-urls", e.etcdURL,
+		"--advertise-client-urls", e.etcdURL,
+		"--listen-peer-urls", localURL("http", peerPort),
+		"--initial-advertise-peer-urls", localURL("http", peerPort),
+		"--initial-cluster", "default="+localURL("http", peerPort),
+	)
+	e.etcd.Stdout = e.cfg.Output
+	e.etcd.Stderr = e.cfg.Output
+
+	return e.etcd.Start()
+}
+
+func (e *Environment) startAPIServer() error {
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	// Service accounts key.
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return fmt.Errorf("could not generate service accounts key: %w", err)
+	}
+	saKeyPath := filepath.Join(e.dir, "sa.key")
+	saKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	err = os.WriteFile(saKeyPath, saKey, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write service accounts key: %w", err)
+	}
+
+	// Admin user token.
+	tokenB := make([]byte, 16)
+	_, err = rand.Read(tokenB)
+	if err != nil {
+		return fmt.Errorf("could not generate admin token: %w", err)
+	}
+	token := hex.EncodeToString(tokenB)
+	tokensPath := filepath.Join(e.dir, "tokens.csv")
+	err = os.WriteFile(tokensPath, []byte(fmt.Sprintf("%s,sloth-envtest-admin,sloth-envtest-admin,system:masters\n", token)), 0600)
+	if err != nil {
+		return fmt.Errorf("could not write admin token: %w", err)
+	}
+
+	e.apiserver = exec.Command(filepath.Join(e.cfg.BinaryAssetsDirectory, "kube-apiserver"),
+		"--etcd-servers", e.etcdURL,
+		"--cert-dir", filepath.Join(e.dir, "apiserver"),
+		"--bind-address", "127.0.0.1",
+		"--advertise-address", "127.0.0.1",
+		"--secure-port", strconv.Itoa(port),
+		"--service-cluster-ip-range", "10.0.0.0/24",
+		"--service-account-issuer", "https://sloth-envtest.local",
+		"--service-account-key-file", saKeyPath,
+		"--service-account-signing-key-file", saKeyPath,
+		"--token-auth-file", tokensPath,
+		"--authorization-mode", "RBAC",
+		"--disable-admission-plugins", "ServiceAccount",
+	)
+	e.apiserver.Stdout = e.cfg.Output
+	e.apiserver.Stderr = e.cfg.Output
+
+	e.restConfig = &rest.Config{
+		Host:            localURL("https", port),
+		BearerToken:     token,
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true}, // Self signed certificate.
+	}
+
+	return e.apiserver.Start()
+}
+
+func (e *Environment) newClients() error {
+	stdCli, err := kubernetes.NewForConfig(e.restConfig)
+	if err != nil {
+		return fmt.Errorf("could not create Kubernetes client: %w", err)
+	}
+
+	slothCli, err := slothclientset.NewForConfig(e.restConfig)
+	if err != nil {
+		return fmt.Errorf("could not create Kubernetes sloth client: %w", err)
+	}
+
+	monitoringCli, err := monitoringclientset.NewForConfig(e.restConfig)
+	if err != nil {
+		return fmt.Errorf("could not create Kubernetes monitoring (prometheus-operator) client: %w", err)
+	}
+
+	dynamicCli, err := dynamic.NewForConfig(e.restConfig)
+	if err != nil {
+		return fmt.Errorf("could not create Kubernetes dynamic client: %w", err)
+	}
+
+	e.clients = &Clients{
+		Std:        stdCli,
+		Sloth:      slothCli,
+		Monitoring: monitoringCli,
+		Dynamic:    dynamicCli,
+	}
+
+	return nil
+}
+
+func (e *Environment) waitAPIServerReady(ctx context.Context) error {
+	return poll(ctx, func() (bool, error) {
+		_, err := e.clients.Std.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+		return err == nil, nil
+	}, "kube-apiserver ready")
+}
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+func (e *Environment) installCRDs(ctx context.Context, manifests [][]byte) error {
+	crdCli := e.clients.Dynamic.Resource(crdGVR)
+
+	names := []string{}
+	for _, manifest := range manifests {
+		dec := kubeyaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			err := dec.Decode(&obj.Object)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("could not decode CRD manifest: %w", err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+
+			_, err = crdCli.Create(ctx, obj, metav1.CreateOptions{})
+			if err != nil && !kubeerrors.IsAlreadyExists(err) {
+				return fmt.Errorf("could not create %q CRD: %w", obj.GetName(), err)
+			}
+			names = append(names, obj.GetName())
+		}
+	}
+
+	// Wait for the CRDs to be served.
+	for _, name := range names {
+		err := poll(ctx, func() (bool, error) {
+			obj, err := crdCli.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+
+			conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			for _, c := range conditions {
+				cond, _ := c.(map[string]interface{})
+				if cond["type"] == "Established" && cond["status"] == "True" {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, fmt.Sprintf("%q CRD established", name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// poll polls the condition until is true or the context is done.
+func poll(ctx context.Context, condition func() (bool, error), desc string) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		ok, err := condition()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("context done while waiting for %s: %w", desc, ctx.Err())
+		}
+	}
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("could not get a free port: %w", err)
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func localURL(scheme string, port int) string {
+	return fmt.Sprintf("%s://127.0.0.1:%d", scheme, port)
+}
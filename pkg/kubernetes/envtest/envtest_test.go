@@ -0,0 +1,31 @@
+package envtest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/slok/sloth/pkg/kubernetes/envtest"
+)
+
+func TestIntegrationEnvironment(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Skipped without the environment binary assets (`KUBEBUILDER_ASSETS`).
+	env := envtest.NewTestEnvironment(t, envtest.Config{})
+	ns := envtest.NewTestNamespace(t, env)
+
+	// The Sloth CRDs should be installed.
+	ctx := context.Background()
+	slos := envtest.NewPrometheusServiceLevel(ns, "test")
+	_, err := env.Clients().Sloth.SlothV1().PrometheusServiceLevels(ns).Create(ctx, slos, metav1.CreateOptions{})
+	require.NoError(err)
+
+	gotSLOs, err := env.Clients().Sloth.SlothV1().PrometheusServiceLevels(ns).Get(ctx, "test", metav1.GetOptions{})
+	require.NoError(err)
+	assert.Equal(slos.Spec, gotSLOs.Spec)
+}
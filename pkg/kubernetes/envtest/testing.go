@@ -0,0 +1,93 @@
+package envtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slothv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+)
+
+// NewTestEnvironment starts a test environment that will be stopped when the test
+// finishes. If the binary assets are missing the test will be skipped.
+func NewTestEnvironment(t testing.TB, cfg Config) *Environment {
+	t.Helper()
+
+	env, err := NewEnvironment(cfg)
+	if errors.Is(err, ErrBinaryAssetsMissing) {
+		t.Skipf("Skipping due to missing test environment: %s", err)
+	}
+	if err != nil {
+		t.Fatalf("could not create test environment: %s", err)
+	}
+
+	err = env.Start(context.Background())
+	if err != nil {
+		t.Fatalf("could not start test environment: %s", err)
+	}
+	t.Cleanup(func() {
+		err := env.Stop()
+		if err != nil {
+			t.Errorf("could not stop test environment: %s", err)
+		}
+	})
+
+	return env
+}
+
+// NewTestNamespace creates a new random namespace on the test environment.
+func NewTestNamespace(t testing.TB, env *Environment) string {
+	t.Helper()
+
+	ns, err := env.CreateNamespace(context.Background())
+	if err != nil {
+		t.Fatalf("could not create test namespace: %s", err)
+	}
+
+	return ns
+}
+
+// NewPrometheusServiceLevel returns a valid `PrometheusServiceLevel` fixture with
+// an events and a raw SLI SLOs.
+func NewPrometheusServiceLevel(ns, name string) *slothv1.PrometheusServiceLevel {
+	return &slothv1.PrometheusServiceLevel{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: slothv1.PrometheusServiceLevelSpec{
+			Service: "svc01",
+			SLOs: []slothv1.SLO{
+				{
+					Name:      "slo01",
+					Objective: 99.9,
+					SLI: slothv1.SLI{Events: &slothv1.SLIEvents{
+						ErrorQuery: `sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))`,
+						TotalQuery: `sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))`,
+					}},
+					Alerting: slothv1.Alerting{
+						Name: "MyServiceHighErrorRate",
+					},
+				},
+				{
+					Name:      "slo02",
+					Objective: 99.99,
+					SLI: slothv1.SLI{Raw: &slothv1.SLIRaw{
+						ErrorRatioQuery: `sum(rate(http_request_duration_seconds_count{job="myservice2",code=~"(5..|429)"}[{{.window}}]))
+/
+sum(rate(http_request_duration_seconds_count{job="myservice2"}[{{.window}}]))
+`,
+					}},
+					Alerting: slothv1.Alerting{
+						Name: "MyService2HighErrorRate",
+						TicketAlert: slothv1.Alert{
+							Disable: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,56 @@
+// Package specloader has the canonical Sloth Kubernetes SLO spec loaders, so external
+// tools can read the specs the same way Sloth does.
+package specloader
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	k8sprometheusv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	"github.com/slok/sloth/pkg/kubernetes/gen/clientset/versioned/scheme"
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+var kubernetesDecoder runtime.Decoder = scheme.Codecs.UniversalDeserializer()
+
+// LoadPrometheusServiceLevelV1 loads a `PrometheusServiceLevel` Kubernetes YAML spec document.
+func LoadPrometheusServiceLevelV1(data []byte) (*k8sprometheusv1.PrometheusServiceLevel, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("spec is required")
+	}
+
+	obj, _, err := kubernetesDecoder.Decode(data, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode kubernetes object %w", err)
+	}
+
+	kslo, ok := obj.(*k8sprometheusv1.PrometheusServiceLevel)
+	if !ok {
+		return nil, fmt.Errorf("can't type assert runtime.Object to v1.PrometheusServiceLevel")
+	}
+
+	// Check at least we have one SLO.
+	if len(kslo.Spec.SLOs) == 0 {
+		return nil, fmt.Errorf("at least one SLO is required")
+	}
+
+	return kslo, nil
+}
+
+// LoadPrometheusServiceLevelV1Strict loads a `PrometheusServiceLevel` Kubernetes YAML spec
+// document like LoadPrometheusServiceLevelV1, but it fails on the fields that are unknown
+// to the spec (e.g a typo'd `objetive`), instead of ignoring them.
+func LoadPrometheusServiceLevelV1Strict(data []byte) (*k8sprometheusv1.PrometheusServiceLevel, error) {
+	kslo, err := LoadPrometheusServiceLevelV1(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = specloader.CheckUnknownFields(data, k8sprometheusv1.PrometheusServiceLevel{}, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	return kslo, nil
+}
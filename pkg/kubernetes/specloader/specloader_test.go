@@ -0,0 +1,114 @@
+package specloader_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	k8sprometheusv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	"github.com/slok/sloth/pkg/kubernetes/specloader"
+)
+
+const testPrometheusServiceLevelV1Spec = `
+apiVersion: sloth.slok.dev/v1
+kind: PrometheusServiceLevel
+metadata:
+  name: test-name
+  namespace: test-ns
+spec:
+  service: test-svc
+  slos:
+    - name: slo1
+      objective: 99.9
+      sli:
+        raw:
+          errorRatioQuery: ratio[{{.window}}]
+      alerting:
+        name: TestAlert
+`
+
+func TestLoadPrometheusServiceLevelV1(t *testing.T) {
+	expPSL := &k8sprometheusv1.PrometheusServiceLevel{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "sloth.slok.dev/v1", Kind: "PrometheusServiceLevel"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-name", Namespace: "test-ns"},
+		Spec: k8sprometheusv1.PrometheusServiceLevelSpec{
+			Service: "test-svc",
+			SLOs: []k8sprometheusv1.SLO{{
+				Name:      "slo1",
+				Objective: 99.9,
+				SLI:       k8sprometheusv1.SLI{Raw: &k8sprometheusv1.SLIRaw{ErrorRatioQuery: "ratio[{{.window}}]"}},
+				Alerting:  k8sprometheusv1.Alerting{Name: "TestAlert"},
+			}},
+		},
+	}
+
+	tests := map[string]struct {
+		specYaml string
+		strict   bool
+		expPSL   *k8sprometheusv1.PrometheusServiceLevel
+		expErr   string
+	}{
+		"An empty spec should fail.": {
+			specYaml: "",
+			expErr:   "spec is required",
+		},
+
+		"An invalid YAML spec should fail.": {
+			specYaml: ":",
+			expErr:   "could not decode kubernetes object",
+		},
+
+		"An unknown Kubernetes kind should fail.": {
+			specYaml: "apiVersion: sloth.slok.dev/v1\nkind: Unknown\nmetadata:\n  name: test\n",
+			expErr:   "could not decode kubernetes object",
+		},
+
+		"A spec without SLOs should fail.": {
+			specYaml: "apiVersion: sloth.slok.dev/v1\nkind: PrometheusServiceLevel\nmetadata:\n  name: test\nspec:\n  service: test-svc\n",
+			expErr:   "at least one SLO is required",
+		},
+
+		"A valid spec should be loaded.": {
+			specYaml: testPrometheusServiceLevelV1Spec,
+			expPSL:   expPSL,
+		},
+
+		"A spec with unknown fields should be loaded.": {
+			specYaml: testPrometheusServiceLevelV1Spec + "      objetive: 99\n",
+			expPSL:   expPSL,
+		},
+
+		"A strict spec loading should load a valid spec.": {
+			specYaml: testPrometheusServiceLevelV1Spec,
+			strict:   true,
+			expPSL:   expPSL,
+		},
+
+		"A strict spec loading with unknown fields should fail with the fields path.": {
+			specYaml: testPrometheusServiceLevelV1Spec + "      objetive: 99\n  owner: myteam\n",
+			strict:   true,
+			expErr:   "unknown spec fields: $.spec.owner, $.spec.slos[0].objetive",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			load := specloader.LoadPrometheusServiceLevelV1
+			if test.strict {
+				load = specloader.LoadPrometheusServiceLevelV1Strict
+			}
+			gotPSL, err := load([]byte(test.specYaml))
+
+			if test.expErr != "" {
+				if assert.Error(err) {
+					assert.Contains(err.Error(), test.expErr)
+				}
+			} else if assert.NoError(err) {
+				assert.Equal(test.expPSL, gotPSL)
+			}
+		})
+	}
+}
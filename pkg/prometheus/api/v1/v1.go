@@ -54,6 +54,13 @@
 //              disable: true
 package v1
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 const Version = "prometheus/v1"
 
 //go:generate gomarkdoc -o ./README.md ./
@@ -64,13 +71,49 @@ type Spec struct {
 	Version string `yaml:"version"`
 	// Service is the application of the SLOs.
 	Service string `yaml:"service"`
+	// SLOPeriod is the time window of the service SLOs (e.g `7d`, `28d`, `30d`, `90d`),
+	// the alert windows are scaled to it. By default `30d`.
+	SLOPeriod string `yaml:"slo_period,omitempty"`
 	// Labels are the Prometheus labels that will have all the recording
-	// and alerting rules generated for the service SLOs.
+	// and alerting rules generated for the service SLOs. Sloth reserved
+	// labels (e.g `sloth_id`) can't be used.
 	Labels map[string]string `yaml:"labels,omitempty"`
+	// Annotations are the Prometheus annotations that will have all the alerting
+	// rules generated for the service SLOs (recording rules don't support annotations).
+	// Annotations at any level can use Sloth templates with `[[ ]]` delimiters that are
+	// rendered on generation with the SLO (`.SLO.ID`, `.SLO.Name`, `.SLO.Service`,
+	// `.SLO.Description`, `.SLO.Objective`, `.SLO.ErrorBudget`, `.SLO.TimeWindow` and
+	// `.SLO.Labels`) and alert (`.Alert.Name`, `.Alert.Severity`, `.Alert.Labels` and the
+	// `.Alert.Quick` and `.Alert.Slow` `ShortWindow`, `LongWindow` and `BurnRateFactor`)
+	// data (e.g `[[ .SLO.Objective ]]% of [[ .SLO.Labels.team ]] SLO`). Missing keys fail
+	// on validation. Prometheus `{{ }}` templates are kept as they are.
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	// Cost is the cost attribution of the service SLOs.
+	Cost *Cost `yaml:"cost,omitempty"`
+	// AlertingDefaults are the default alerting settings of the service SLOs alerts.
+	AlertingDefaults *AlertingDefaults `yaml:"alerting_defaults,omitempty"`
 	// SLOs are the SLOs of the service.
 	SLOs []SLO `yaml:"slos,omitempty"`
 }
 
+// Cost is the cost attribution (e.g cost center, product...) of the SLOs, used
+// to attribute the reliability spend using the generated rules series.
+type Cost struct {
+	// Labels are the cost attribution Prometheus labels (e.g `cost_center`) that will
+	// have all the recording rules generated for the service SLOs. These labels are
+	// merged with the SLO labels and have preference over them.
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// AlertingDefaults are the default alerting settings of the SLOs, so the common
+// alerts settings (e.g runbook and dashboard links) are not repeated on every SLO.
+type AlertingDefaults struct {
+	// Annotations are the default Prometheus annotations of all the alerting rules
+	// generated for the service SLOs. The service, SLO and alerting annotations have
+	// preference over them.
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
 // SLO is the configuration/declaration of the service level objective of
 // a service.
 type SLO struct {
@@ -78,17 +121,49 @@ type SLO struct {
 	Name string `yaml:"name"`
 	// Description is the description of the SLO.
 	Description string `yaml:"description,omitempty"`
-	// Objective is target of the SLO the percentage (0, 100] (e.g 99.9).
+	// Objective is target of the SLO the percentage (0, 100) (e.g 99.9).
+	// The specs loaded with `pkg/specloader` can also set it as a percentage
+	// (e.g `99.9%`) or using the nines notation (e.g `three nines`), check
+	// ParseObjective.
 	Objective float64 `yaml:"objective"`
 	// Labels are the Prometheus labels that will have all the recording and
 	// alerting rules for this specific SLO. These labels are merged with the
 	// previous level labels.
 	Labels map[string]string `yaml:"labels,omitempty"`
+	// Annotations are the Prometheus annotations that will have all the alerting
+	// rules for this specific SLO. These annotations are merged with the previous
+	// level annotations.
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	// GroupLabels are the labels of the rule groups generated for this specific SLO
+	// (e.g `tenant`), instead of repeating them on every rule. These are supported by
+	// rulers like Mimir and Loki.
+	GroupLabels map[string]string `yaml:"group_labels,omitempty"`
+	// RuleGroupIntervals are the evaluation intervals of the rule groups generated for
+	// this specific SLO (e.g a slower `2m` interval for the rules of a 30d SLO), if not
+	// set the rule groups use the ruler global evaluation interval.
+	RuleGroupIntervals *RuleGroupIntervals `yaml:"rule_group_intervals,omitempty"`
 	// SLI is the indicator (service level indicator) for this specific SLO.
 	SLI SLI `yaml:"sli"`
 	// Alerting is the configuration with all the things related with the SLO
 	// alerts.
 	Alerting Alerting `yaml:"alerting"`
+	// Transition is the previous objective and time window of an SLO that has been
+	// changed. When set, Sloth will generate transitional recording rules with the
+	// previous values and the change metadata, so dashboards can distinguish the error
+	// budgets before and after the change instead of having a series discontinuity.
+	Transition *SLOTransition `yaml:"transition,omitempty"`
+	// ReportingWindows are extra time windows (e.g `7d`) used only to report the SLO,
+	// Sloth will generate the reporting recording rules for each of them. The alerts
+	// use the SLO period.
+	ReportingWindows []string `yaml:"reporting_windows,omitempty"`
+	// Timeslice makes the SLO a timeslice (windows-based) SLO, the SLO compliance is
+	// measured as the ratio of good time slices (e.g `1m` slices with an SLI error ratio
+	// under a threshold) instead of the events ratio.
+	Timeslice *SLOTimeslice `yaml:"timeslice,omitempty"`
+	// Environments are the SLO overrides by environment name (e.g `staging`), applied
+	// when the SLOs are generated for the environment (e.g `--env staging`), so the
+	// environments can have their own objectives from the same spec.
+	Environments map[string]SLOEnvironment `yaml:"environments,omitempty"`
 }
 
 // SLI will tell what is good or bad for the SLO.
@@ -103,6 +178,28 @@ type SLI struct {
 	Events *SLIEvents `yaml:"events,omitempty"`
 	// Plugin is the pluggable SLI type.
 	Plugin *SLIPlugin `yaml:"plugin,omitempty"`
+	// Latency is the latency SLI type, generated from a histogram.
+	Latency *SLILatency `yaml:"latency,omitempty"`
+	// Offset is a Prometheus (e.g `5m`) or human-friendly (e.g `5 minutes`) duration
+	// that will be applied to all the SLI expression selectors, used to tolerate late
+	// data (e.g delayed remote write).
+	Offset string `yaml:"offset,omitempty"`
+	// Examples are example values of the SLI series with the SLI error ratio expected
+	// from them, `sloth validate --examples` evaluates the SLI with them to test the
+	// SLI logic without a Prometheus.
+	Examples []SLIExample `yaml:"examples,omitempty"`
+}
+
+// SLIExample is an example of the SLI series values and the SLI error ratio expected
+// from them. The series values are the values of the SLI range functions (e.g the `rate`
+// of a counter), so the example is the same for all the SLI windows.
+type SLIExample struct {
+	// Name is the name of the example.
+	Name string `yaml:"name,omitempty"`
+	// Series are the values of the SLI series, indexed by series (e.g `http_requests_total{code="500"}`).
+	Series map[string]float64 `yaml:"series"`
+	// ErrorRatio is the SLI error ratio (0-1) expected from the series values.
+	ErrorRatio float64 `yaml:"error_ratio"`
 }
 
 // SLIRaw is a error ratio SLI already calculated. Normally this will be used when the SLI
@@ -125,12 +222,41 @@ type SLIEvents struct {
 	TotalQuery string `yaml:"total_query"`
 }
 
+// SLILatency is a latency SLI of a Prometheus histogram in seconds, the bad events are the
+// histogram observations slower than the threshold. Sloth will generate the events queries
+// using the histogram buckets.
+type SLILatency struct {
+	// HistogramMetric is the histogram metric base name (e.g `http_request_duration_seconds`).
+	HistogramMetric string `yaml:"histogram_metric"`
+	// Threshold is the latency duration (e.g `300ms`) of the good events, on classic
+	// histograms it must be one of the histogram buckets.
+	Threshold string `yaml:"threshold"`
+	// Selector are the histogram series label matchers (e.g `job="api", code!~"5.."`).
+	Selector string `yaml:"selector,omitempty"`
+	// NativeHistogram makes the SLI use a Prometheus native histogram instead of the
+	// classic histogram `_bucket` and `_count` series.
+	NativeHistogram bool `yaml:"native_histogram,omitempty"`
+}
+
 // SLIPlugin will use the SLI returned by the SLI plugin selected along with the options.
 type SLIPlugin struct {
 	// Name is the name of the plugin that needs to load.
 	ID string `yaml:"id"`
 	// Options are the options used for the plugin.
 	Options map[string]string `yaml:"options"`
+	// Chain are the plugins that will be executed in order after this plugin, every
+	// plugin receives the SLI query of the previous plugin on the `query` metadata key
+	// (e.g a filter plugin that excludes the canary traffic of a base availability plugin
+	// query). The SLI query is the one returned by the last plugin.
+	Chain []ChainedSLIPlugin `yaml:"chain,omitempty"`
+}
+
+// ChainedSLIPlugin is an SLI plugin executed after another SLI plugin.
+type ChainedSLIPlugin struct {
+	// ID is the ID of the plugin that needs to load.
+	ID string `yaml:"id"`
+	// Options are the options used for the plugin.
+	Options map[string]string `yaml:"options,omitempty"`
 }
 
 // Alerting wraps all the configuration required by the SLO alerts.
@@ -146,6 +272,42 @@ type Alerting struct {
 	PageAlert Alert `yaml:"page_alert,omitempty"`
 	// TicketAlert alert refers to the warning alert (check multiwindow-multiburn alerts).
 	TicketAlert Alert `yaml:"ticket_alert,omitempty"`
+	// DependsOn are the IDs (`{service}-{slo}`) of the SLOs this SLO depends on. When
+	// Alertmanager inhibition rules are generated, the page alert of these SLOs will
+	// inhibit the page alert of this SLO.
+	DependsOn []string `yaml:"depends_on,omitempty"`
+	// Guard is a Prometheus expression that will be added with an `and on()` to all the
+	// alerts generated by this SLO (e.g `cluster_maintenance == 0`), the alerts will only
+	// fire when the guard returns any series. This can be used to integrate the alerts
+	// with existing signals like maintenance modes.
+	Guard string `yaml:"guard,omitempty"`
+	// Windows is the name of the alert windows catalog profile used by the SLO alerts, by
+	// default it will use the catalog profile of the SLO period, or the Google SRE workbook
+	// recommended alert windows if there is none.
+	Windows string `yaml:"windows,omitempty"`
+	// MaintenanceWindows are the recurring time windows where the alerts of this SLO are
+	// muted, used to generate the Alertmanager time intervals.
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance_windows,omitempty"`
+}
+
+// MaintenanceWindow is a recurring time window (e.g a maintenance window or the out of
+// business hours) where the SLO alerts are muted by Alertmanager.
+type MaintenanceWindow struct {
+	// Name is the name of the window, used as the Alertmanager time interval name. The
+	// windows with the same name must be the same on all the SLOs.
+	Name string `yaml:"name"`
+	// Times are the time ranges of the day (e.g `02:00-04:00`), by default all the day.
+	Times []string `yaml:"times,omitempty"`
+	// Weekdays are the days of the week or ranges (e.g `saturday`, `monday:friday`), by
+	// default all the days.
+	Weekdays []string `yaml:"weekdays,omitempty"`
+	// DaysOfMonth are the days of the month or ranges (e.g `1`, `1:5`, `-1`), by default
+	// all the days.
+	DaysOfMonth []string `yaml:"days_of_month,omitempty"`
+	// Months are the months or ranges (e.g `january`, `1:3`), by default all the months.
+	Months []string `yaml:"months,omitempty"`
+	// Location is the time zone of the window (e.g `Europe/Madrid`), by default `UTC`.
+	Location string `yaml:"location,omitempty"`
 }
 
 // Alert configures specific SLO alert.
@@ -156,6 +318,100 @@ type Alert struct {
 	// Labels are the Prometheus labels for the specific alert. For example can be
 	// useful to route the Page alert to specific Slack channel.
 	Labels map[string]string `yaml:"labels,omitempty"`
-	// Annotations are the Prometheus annotations for the specific alert.
+	// Annotations are the Prometheus annotations for the specific alert, these can
+	// use Sloth `[[ ]]` templates.
 	Annotations map[string]string `yaml:"annotations,omitempty"`
+	// For is the duration the alert conditions must be met before firing (e.g `5m`),
+	// it overrides the alert windows `for` duration.
+	For string `yaml:"for,omitempty"`
+}
+
+// SLOEnvironment is the override of an SLO for an environment, the unset fields
+// use the SLO values.
+type SLOEnvironment struct {
+	// Objective is the SLO objective on the environment.
+	Objective float64 `yaml:"objective,omitempty"`
+	// SLOPeriod is the SLO time window on the environment (e.g `7d`), the alert
+	// windows are scaled to it.
+	SLOPeriod string `yaml:"slo_period,omitempty"`
+}
+
+// SLOTransition is the previous state of a changed SLO.
+type SLOTransition struct {
+	// PreviousObjective is the SLO objective before the change, by default the
+	// current objective.
+	PreviousObjective float64 `yaml:"previous_objective,omitempty"`
+	// PreviousTimeWindow is the SLO time window before the change (e.g `28d`), by
+	// default the current time window.
+	PreviousTimeWindow string `yaml:"previous_time_window,omitempty"`
+	// ChangedAt is the date of the change (e.g `2021-06-30`).
+	ChangedAt string `yaml:"changed_at,omitempty"`
+}
+
+// RuleGroupIntervals are the evaluation intervals of the rule groups of an SLO, the SLI
+// recordings, metadata recordings and alerts are generated on different rule groups.
+type RuleGroupIntervals struct {
+	// Default is the interval of the rule groups that don't set their own interval (e.g `1m`).
+	Default string `yaml:"default,omitempty"`
+	// SLIRecordings is the interval of the SLI recording rules group.
+	SLIRecordings string `yaml:"sli_recordings,omitempty"`
+	// MetaRecordings is the interval of the metadata recording rules group.
+	MetaRecordings string `yaml:"meta_recordings,omitempty"`
+	// Alerts is the interval of the alert rules group.
+	Alerts string `yaml:"alerts,omitempty"`
+}
+
+// SLOTimeslice are the time slices of a timeslice SLO. A time slice is bad when
+// its SLI error ratio is greater than the error ratio threshold, and the SLO error
+// ratio of a window is the ratio of bad time slices on the window.
+type SLOTimeslice struct {
+	// Window is the duration of the time slices (e.g `1m`).
+	Window string `yaml:"window"`
+	// ErrorRatioThreshold is the maximum SLI error ratio (0-1) of a good time slice
+	// (e.g `0.01`).
+	ErrorRatioThreshold float64 `yaml:"error_ratio_threshold"`
+}
+
+var ninesWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9,
+}
+
+var ninesRegexp = regexp.MustCompile(`^([a-z]+|[1-9]) +nines?$`)
+
+// ParseObjective parses an SLO objective percentage (e.g `99.9` or `99.9%`) or
+// nines notation (e.g `three nines` or `3 nines`).
+func ParseObjective(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	// Nines notation.
+	if m := ninesRegexp.FindStringSubmatch(s); m != nil {
+		nines, ok := ninesWords[m[1]]
+		if !ok {
+			nines, _ = strconv.Atoi(m[1])
+		}
+		if nines == 0 {
+			return 0, fmt.Errorf("invalid objective %q nines", s)
+		}
+
+		// Create the literal so we don't have float operation precision errors.
+		var objective string
+		switch nines {
+		case 1:
+			objective = "90"
+		case 2:
+			objective = "99"
+		default:
+			objective = "99." + strings.Repeat("9", nines-2)
+		}
+		return strconv.ParseFloat(objective, 64)
+	}
+
+	// Percentage notation.
+	f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid objective %q, should be a percentage (e.g 99.9) or nines (e.g three nines)", s)
+	}
+
+	return f, nil
 }
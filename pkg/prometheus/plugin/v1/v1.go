@@ -23,6 +23,8 @@ const (
 	SLIPluginMetaService   = "service"
 	SLIPluginMetaSLO       = "slo"
 	SLIPluginMetaObjective = "objective"
+	// SLIPluginMetaQuery is the SLI query of the previous plugin, only set on chained plugins.
+	SLIPluginMetaQuery = "query"
 )
 
 // SLIPlugin knows how to generate SLIs based on data options.
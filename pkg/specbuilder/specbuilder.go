@@ -0,0 +1,478 @@
+// Package specbuilder has fluent builders to create Sloth SLO specs programmatically,
+// instead of crafting YAML strings (e.g platforms that create SLOs for all their services).
+//
+// Example:
+//
+//	spec, err := specbuilder.New("myservice").
+//	    WithLabels(map[string]string{"owner": "myteam"}).
+//	    WithSLO(specbuilder.NewSLO("requests-availability", 99.9).
+//	        WithEventsSLI(
+//	            `sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))`,
+//	            `sum(rate(http_request_duration_seconds_count{job="myservice"}[{{.window}}]))`,
+//	        ).
+//	        WithAlerting("MyServiceHighErrorRate").
+//	        DisableTicketAlert()).
+//	    PrometheusV1()
+package specbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	k8sprometheusv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// Builder builds the SLO specs of a service.
+type Builder struct {
+	service     string
+	labels      map[string]string
+	annotations map[string]string
+	costLabels  map[string]string
+	slos        []*SLOBuilder
+}
+
+// New returns a new spec builder for a service.
+func New(service string) *Builder {
+	return &Builder{service: service}
+}
+
+// WithLabels adds Prometheus labels to all the rules of the service SLOs.
+func (b *Builder) WithLabels(labels map[string]string) *Builder {
+	b.labels = mergeMaps(b.labels, labels)
+	return b
+}
+
+// WithAnnotations adds Prometheus annotations to all the alerts of the service SLOs.
+func (b *Builder) WithAnnotations(annotations map[string]string) *Builder {
+	b.annotations = mergeMaps(b.annotations, annotations)
+	return b
+}
+
+// WithCostLabels adds cost attribution Prometheus labels to the service SLOs.
+func (b *Builder) WithCostLabels(labels map[string]string) *Builder {
+	b.costLabels = mergeMaps(b.costLabels, labels)
+	return b
+}
+
+// WithSLO adds an SLO to the service.
+func (b *Builder) WithSLO(slo *SLOBuilder) *Builder {
+	b.slos = append(b.slos, slo)
+	return b
+}
+
+// PrometheusV1 validates and returns the `prometheus/v1` spec.
+func (b *Builder) PrometheusV1() (*prometheusv1.Spec, error) {
+	err := b.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &prometheusv1.Spec{
+		Version:     prometheusv1.Version,
+		Service:     b.service,
+		Labels:      b.labels,
+		Annotations: b.annotations,
+	}
+	if len(b.costLabels) > 0 {
+		spec.Cost = &prometheusv1.Cost{Labels: b.costLabels}
+	}
+
+	for _, s := range b.slos {
+		slo := prometheusv1.SLO{
+			Name:        s.name,
+			Description: s.description,
+			Objective:   s.objective,
+			Labels:      s.labels,
+			Annotations: s.annotations,
+			SLI:         prometheusv1.SLI{Offset: s.sliOffset},
+			Alerting: prometheusv1.Alerting{
+				Name:        s.alertName,
+				Labels:      s.alertLabels,
+				Annotations: s.alertAnnotations,
+				PageAlert: prometheusv1.Alert{
+					Disable:     s.pageAlert.disable,
+					Labels:      s.pageAlert.labels,
+					Annotations: s.pageAlert.annotations,
+					For:         s.pageAlert.forDuration,
+				},
+				TicketAlert: prometheusv1.Alert{
+					Disable:     s.ticketAlert.disable,
+					Labels:      s.ticketAlert.labels,
+					Annotations: s.ticketAlert.annotations,
+					For:         s.ticketAlert.forDuration,
+				},
+				DependsOn: s.dependsOn,
+				Guard:     s.alertGuard,
+			},
+		}
+
+		switch {
+		case s.sliEvents != nil:
+			slo.SLI.Events = &prometheusv1.SLIEvents{ErrorQuery: s.sliEvents.errorQuery, TotalQuery: s.sliEvents.totalQuery}
+		case s.sliRaw != nil:
+			slo.SLI.Raw = &prometheusv1.SLIRaw{ErrorRatioQuery: *s.sliRaw}
+		case s.sliPlugin != nil:
+			slo.SLI.Plugin = &prometheusv1.SLIPlugin{ID: s.sliPlugin.id, Options: s.sliPlugin.options}
+		case s.sliLatency != nil:
+			slo.SLI.Latency = &prometheusv1.SLILatency{HistogramMetric: s.sliLatency.histogramMetric, Threshold: s.sliLatency.threshold, Selector: s.sliLatency.selector, NativeHistogram: s.sliLatency.nativeHistogram}
+		}
+
+		spec.SLOs = append(spec.SLOs, slo)
+	}
+
+	return spec, nil
+}
+
+// KubernetesV1 validates and returns the `PrometheusServiceLevel` Kubernetes object.
+func (b *Builder) KubernetesV1(namespace, name string) (*k8sprometheusv1.PrometheusServiceLevel, error) {
+	if name == "" {
+		return nil, fmt.Errorf("kubernetes object name is required")
+	}
+
+	spec, err := b.PrometheusV1()
+	if err != nil {
+		return nil, err
+	}
+
+	psl := &k8sprometheusv1.PrometheusServiceLevel{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: k8sprometheusv1.SchemeGroupVersion.String(),
+			Kind:       "PrometheusServiceLevel",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: k8sprometheusv1.PrometheusServiceLevelSpec{
+			Service:     spec.Service,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+	}
+	if spec.Cost != nil {
+		psl.Spec.Cost = &k8sprometheusv1.Cost{Labels: spec.Cost.Labels}
+	}
+
+	for _, s := range spec.SLOs {
+		slo := k8sprometheusv1.SLO{
+			Name:        s.Name,
+			Description: s.Description,
+			Objective:   s.Objective,
+			Labels:      s.Labels,
+			Annotations: s.Annotations,
+			SLI:         k8sprometheusv1.SLI{Offset: s.SLI.Offset},
+			Alerting: k8sprometheusv1.Alerting{
+				Name:        s.Alerting.Name,
+				Labels:      s.Alerting.Labels,
+				Annotations: s.Alerting.Annotations,
+				PageAlert: k8sprometheusv1.Alert{
+					Disable:     s.Alerting.PageAlert.Disable,
+					Labels:      s.Alerting.PageAlert.Labels,
+					Annotations: s.Alerting.PageAlert.Annotations,
+					For:         s.Alerting.PageAlert.For,
+				},
+				TicketAlert: k8sprometheusv1.Alert{
+					Disable:     s.Alerting.TicketAlert.Disable,
+					Labels:      s.Alerting.TicketAlert.Labels,
+					Annotations: s.Alerting.TicketAlert.Annotations,
+					For:         s.Alerting.TicketAlert.For,
+				},
+				DependsOn: s.Alerting.DependsOn,
+				Guard:     s.Alerting.Guard,
+			},
+		}
+
+		if s.SLI.Events != nil {
+			slo.SLI.Events = &k8sprometheusv1.SLIEvents{ErrorQuery: s.SLI.Events.ErrorQuery, TotalQuery: s.SLI.Events.TotalQuery}
+		}
+		if s.SLI.Raw != nil {
+			slo.SLI.Raw = &k8sprometheusv1.SLIRaw{ErrorRatioQuery: s.SLI.Raw.ErrorRatioQuery}
+		}
+		if s.SLI.Plugin != nil {
+			slo.SLI.Plugin = &k8sprometheusv1.SLIPlugin{ID: s.SLI.Plugin.ID, Options: s.SLI.Plugin.Options}
+		}
+		if s.SLI.Latency != nil {
+			slo.SLI.Latency = &k8sprometheusv1.SLILatency{HistogramMetric: s.SLI.Latency.HistogramMetric, Threshold: s.SLI.Latency.Threshold, Selector: s.SLI.Latency.Selector, NativeHistogram: s.SLI.Latency.NativeHistogram}
+		}
+
+		psl.Spec.SLOs = append(psl.Spec.SLOs, slo)
+	}
+
+	return psl, nil
+}
+
+// validate validates the spec early, so the most common errors are catched on the
+// builder usage instead of the Sloth generation. Sloth will validate the spec again
+// when it's loaded.
+func (b *Builder) validate() error {
+	if b.service == "" {
+		return fmt.Errorf("service is required")
+	}
+
+	if len(b.slos) == 0 {
+		return fmt.Errorf("at least one SLO is required")
+	}
+
+	names := map[string]bool{}
+	for _, s := range b.slos {
+		if s == nil {
+			return fmt.Errorf("SLO is nil")
+		}
+
+		err := s.validate()
+		if err != nil {
+			return fmt.Errorf("invalid %q SLO: %w", s.name, err)
+		}
+
+		if names[s.name] {
+			return fmt.Errorf("SLO %q is repeated", s.name)
+		}
+		names[s.name] = true
+	}
+
+	return nil
+}
+
+// SLOBuilder builds an SLO of a service spec.
+type SLOBuilder struct {
+	name             string
+	description      string
+	objective        float64
+	labels           map[string]string
+	annotations      map[string]string
+	sliEvents        *sliEvents
+	sliRaw           *string
+	sliPlugin        *sliPlugin
+	sliLatency       *sliLatency
+	sliOffset        string
+	alertName        string
+	alertLabels      map[string]string
+	alertAnnotations map[string]string
+	pageAlert        alert
+	ticketAlert      alert
+	dependsOn        []string
+	alertGuard       string
+}
+
+type sliEvents struct {
+	errorQuery string
+	totalQuery string
+}
+
+type sliPlugin struct {
+	id      string
+	options map[string]string
+}
+
+type sliLatency struct {
+	histogramMetric string
+	threshold       string
+	selector        string
+	nativeHistogram bool
+}
+
+type alert struct {
+	disable     bool
+	labels      map[string]string
+	annotations map[string]string
+	forDuration string
+}
+
+// NewSLO returns a new SLO builder with the SLO name and objective percentage (e.g 99.9).
+func NewSLO(name string, objective float64) *SLOBuilder {
+	return &SLOBuilder{name: name, objective: objective}
+}
+
+// WithDescription sets the SLO description.
+func (s *SLOBuilder) WithDescription(description string) *SLOBuilder {
+	s.description = description
+	return s
+}
+
+// WithLabels adds Prometheus labels to all the rules of the SLO.
+func (s *SLOBuilder) WithLabels(labels map[string]string) *SLOBuilder {
+	s.labels = mergeMaps(s.labels, labels)
+	return s
+}
+
+// WithAnnotations adds Prometheus annotations to all the alerts of the SLO.
+func (s *SLOBuilder) WithAnnotations(annotations map[string]string) *SLOBuilder {
+	s.annotations = mergeMaps(s.annotations, annotations)
+	return s
+}
+
+// WithEventsSLI sets the events SLI type, the queries require the `{{.window}}`
+// template variable.
+func (s *SLOBuilder) WithEventsSLI(errorQuery, totalQuery string) *SLOBuilder {
+	s.sliEvents = &sliEvents{errorQuery: errorQuery, totalQuery: totalQuery}
+	return s
+}
+
+// WithRawSLI sets the raw SLI type, the query requires the `{{.window}}` template
+// variable.
+func (s *SLOBuilder) WithRawSLI(errorRatioQuery string) *SLOBuilder {
+	s.sliRaw = &errorRatioQuery
+	return s
+}
+
+// WithPluginSLI sets the plugin SLI type.
+func (s *SLOBuilder) WithPluginSLI(id string, options map[string]string) *SLOBuilder {
+	s.sliPlugin = &sliPlugin{id: id, options: options}
+	return s
+}
+
+// WithLatencySLI sets the latency SLI type, using the histogram in seconds base name
+// (e.g `http_request_duration_seconds`), the latency threshold of the good events (e.g
+// `300ms`) and the histogram series label matchers (e.g `job="api"`).
+func (s *SLOBuilder) WithLatencySLI(histogramMetric, threshold, selector string) *SLOBuilder {
+	s.sliLatency = &sliLatency{histogramMetric: histogramMetric, threshold: threshold, selector: selector}
+	return s
+}
+
+// WithNativeHistogramLatencySLI sets the latency SLI type using a Prometheus native
+// histogram in seconds (e.g `http_request_duration_seconds`), the latency threshold of
+// the good events (e.g `300ms`) and the histogram series label matchers (e.g `job="api"`).
+func (s *SLOBuilder) WithNativeHistogramLatencySLI(histogramMetric, threshold, selector string) *SLOBuilder {
+	s.sliLatency = &sliLatency{histogramMetric: histogramMetric, threshold: threshold, selector: selector, nativeHistogram: true}
+	return s
+}
+
+// WithSLIOffset sets the SLI offset duration (e.g `5m`).
+func (s *SLOBuilder) WithSLIOffset(offset string) *SLOBuilder {
+	s.sliOffset = offset
+	return s
+}
+
+// WithAlerting sets the name of the SLO alerts.
+func (s *SLOBuilder) WithAlerting(name string) *SLOBuilder {
+	s.alertName = name
+	return s
+}
+
+// WithAlertLabels adds Prometheus labels to all the alerts of the SLO.
+func (s *SLOBuilder) WithAlertLabels(labels map[string]string) *SLOBuilder {
+	s.alertLabels = mergeMaps(s.alertLabels, labels)
+	return s
+}
+
+// WithAlertAnnotations adds Prometheus annotations to all the alerts of the SLO.
+func (s *SLOBuilder) WithAlertAnnotations(annotations map[string]string) *SLOBuilder {
+	s.alertAnnotations = mergeMaps(s.alertAnnotations, annotations)
+	return s
+}
+
+// WithPageAlert adds Prometheus labels and annotations to the page alert.
+func (s *SLOBuilder) WithPageAlert(labels, annotations map[string]string) *SLOBuilder {
+	s.pageAlert.labels = mergeMaps(s.pageAlert.labels, labels)
+	s.pageAlert.annotations = mergeMaps(s.pageAlert.annotations, annotations)
+	return s
+}
+
+// WithTicketAlert adds Prometheus labels and annotations to the ticket alert.
+func (s *SLOBuilder) WithTicketAlert(labels, annotations map[string]string) *SLOBuilder {
+	s.ticketAlert.labels = mergeMaps(s.ticketAlert.labels, labels)
+	s.ticketAlert.annotations = mergeMaps(s.ticketAlert.annotations, annotations)
+	return s
+}
+
+// WithPageAlertFor sets the page alert `for` duration (e.g `5m`), overriding the alert
+// windows one.
+func (s *SLOBuilder) WithPageAlertFor(d string) *SLOBuilder {
+	s.pageAlert.forDuration = d
+	return s
+}
+
+// WithTicketAlertFor sets the ticket alert `for` duration (e.g `1h`), overriding the alert
+// windows one.
+func (s *SLOBuilder) WithTicketAlertFor(d string) *SLOBuilder {
+	s.ticketAlert.forDuration = d
+	return s
+}
+
+// DisablePageAlert disables the page alert.
+func (s *SLOBuilder) DisablePageAlert() *SLOBuilder {
+	s.pageAlert.disable = true
+	return s
+}
+
+// DisableTicketAlert disables the ticket alert.
+func (s *SLOBuilder) DisableTicketAlert() *SLOBuilder {
+	s.ticketAlert.disable = true
+	return s
+}
+
+// WithDependsOn adds the IDs (`{service}-{slo}`) of the SLOs this SLO depends on.
+func (s *SLOBuilder) WithDependsOn(ids ...string) *SLOBuilder {
+	s.dependsOn = append(s.dependsOn, ids...)
+	return s
+}
+
+// WithAlertGuard sets the Prometheus expression that guards the SLO alerts with an
+// `and on()` (e.g `cluster_maintenance == 0`).
+func (s *SLOBuilder) WithAlertGuard(expr string) *SLOBuilder {
+	s.alertGuard = expr
+	return s
+}
+
+func (s *SLOBuilder) validate() error {
+	if s.name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if s.objective <= 0 || s.objective >= 100 {
+		return fmt.Errorf("objective should be between 0 and 100 (exclusive)")
+	}
+
+	sliTypes := 0
+	if s.sliEvents != nil {
+		sliTypes++
+		if !hasWindowTplVar(s.sliEvents.errorQuery) || !hasWindowTplVar(s.sliEvents.totalQuery) {
+			return fmt.Errorf("events SLI queries require the `{{.window}}` template variable")
+		}
+	}
+	if s.sliRaw != nil {
+		sliTypes++
+		if !hasWindowTplVar(*s.sliRaw) {
+			return fmt.Errorf("raw SLI query requires the `{{.window}}` template variable")
+		}
+	}
+	if s.sliPlugin != nil {
+		sliTypes++
+		if s.sliPlugin.id == "" {
+			return fmt.Errorf("plugin SLI ID is required")
+		}
+	}
+	if s.sliLatency != nil {
+		sliTypes++
+		if s.sliLatency.histogramMetric == "" || s.sliLatency.threshold == "" {
+			return fmt.Errorf("latency SLI histogram metric and threshold are required")
+		}
+	}
+	if sliTypes != 1 {
+		return fmt.Errorf("one SLI type is required, got %d", sliTypes)
+	}
+
+	if s.alertName == "" && (!s.pageAlert.disable || !s.ticketAlert.disable) {
+		return fmt.Errorf("alert name is required when the alerts are enabled")
+	}
+
+	return nil
+}
+
+func hasWindowTplVar(query string) bool {
+	return strings.Contains(strings.ReplaceAll(query, " ", ""), "{{.window}}")
+}
+
+func mergeMaps(ms ...map[string]string) map[string]string {
+	res := map[string]string{}
+	for _, m := range ms {
+		for k, v := range m {
+			res[k] = v
+		}
+	}
+
+	return res
+}
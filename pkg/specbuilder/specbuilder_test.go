@@ -0,0 +1,290 @@
+package specbuilder_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/slok/sloth/internal/prometheus"
+	k8sprometheusv1 "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	"github.com/slok/sloth/pkg/specbuilder"
+)
+
+type testMemPluginsRepo map[string]prometheus.SLIPlugin
+
+func (t testMemPluginsRepo) GetSLIPlugin(ctx context.Context, id string) (*prometheus.SLIPlugin, error) {
+	p, ok := t[id]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return &p, nil
+}
+
+func TestBuilderPrometheusV1RoundTrip(t *testing.T) {
+	plugins := testMemPluginsRepo{
+		"test-plugin": {
+			ID: "test-plugin",
+			Func: func(ctx context.Context, meta, labels, options map[string]string) (string, error) {
+				return `plugin_error_ratio{svc="` + options["svc"] + `"}[{{.window}}]`, nil
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		builder  *specbuilder.Builder
+		specYaml string
+	}{
+		"An events SLI spec with labels, annotations and alerts should round-trip.": {
+			builder: specbuilder.New("test-svc").
+				WithLabels(map[string]string{"owner": "myteam"}).
+				WithAnnotations(map[string]string{"runbook": "https://runbooks.test/svc"}).
+				WithSLO(specbuilder.NewSLO("requests-availability", 99.9).
+					WithDescription("Availability of the requests.").
+					WithLabels(map[string]string{"tier": "1"}).
+					WithEventsSLI(
+						`sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))`,
+						`sum(rate(http_requests_total[{{.window}}]))`,
+					).
+					WithAlerting("TestSvcHighErrorRate").
+					WithAlertLabels(map[string]string{"category": "availability"}).
+					WithPageAlert(map[string]string{"severity": "page"}, map[string]string{"summary": "High error rate"}).
+					WithPageAlertFor("5m").
+					WithTicketAlert(map[string]string{"severity": "ticket"}, nil).
+					WithTicketAlertFor("1h").
+					WithAlertGuard("cluster_maintenance == 0")),
+			specYaml: `
+version: prometheus/v1
+service: test-svc
+labels:
+  owner: myteam
+annotations:
+  runbook: https://runbooks.test/svc
+slos:
+  - name: requests-availability
+    description: Availability of the requests.
+    objective: 99.9
+    labels:
+      tier: "1"
+    sli:
+      events:
+        error_query: sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))
+        total_query: sum(rate(http_requests_total[{{.window}}]))
+    alerting:
+      name: TestSvcHighErrorRate
+      guard: cluster_maintenance == 0
+      labels:
+        category: availability
+      page_alert:
+        for: 5m
+        labels:
+          severity: page
+        annotations:
+          summary: High error rate
+      ticket_alert:
+        for: 1h
+        labels:
+          severity: ticket
+`,
+		},
+
+		"Raw, plugin and latency SLIs with disabled alerts should round-trip.": {
+			builder: specbuilder.New("test-svc").
+				WithCostLabels(map[string]string{"product": "checkout"}).
+				WithSLO(specbuilder.NewSLO("raw", 99).
+					WithRawSLI(`sum(rate(errors[{{.window}}])) / sum(rate(total[{{.window}}]))`).
+					WithSLIOffset("5m").
+					DisablePageAlert().
+					DisableTicketAlert()).
+				WithSLO(specbuilder.NewSLO("plugin", 99.5).
+					WithPluginSLI("test-plugin", map[string]string{"svc": "test"}).
+					WithDependsOn("test-svc-raw").
+					DisablePageAlert().
+					DisableTicketAlert()).
+				WithSLO(specbuilder.NewSLO("latency", 95).
+					WithLatencySLI("http_request_duration_seconds", "300ms", `job="api"`).
+					DisablePageAlert().
+					DisableTicketAlert()),
+			specYaml: `
+version: prometheus/v1
+service: test-svc
+cost:
+  labels:
+    product: checkout
+slos:
+  - name: raw
+    objective: 99
+    sli:
+      offset: 5m
+      raw:
+        error_ratio_query: sum(rate(errors[{{.window}}])) / sum(rate(total[{{.window}}]))
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+  - name: plugin
+    objective: 99.5
+    sli:
+      plugin:
+        id: test-plugin
+        options:
+          svc: test
+    alerting:
+      depends_on: ["test-svc-raw"]
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+  - name: latency
+    objective: 95
+    sli:
+      latency:
+        histogram_metric: http_request_duration_seconds
+        threshold: 300ms
+        selector: job="api"
+    alerting:
+      page_alert:
+        disable: true
+      ticket_alert:
+        disable: true
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			loader := prometheus.NewYAMLSpecLoader(plugins)
+
+			// Load the builder spec.
+			spec, err := test.builder.PrometheusV1()
+			require.NoError(err)
+			specData, err := yaml.Marshal(spec)
+			require.NoError(err)
+			gotModel, err := loader.LoadSpec(context.TODO(), specData)
+			require.NoError(err)
+
+			// Load the handcrafted equivalent spec.
+			expModel, err := loader.LoadSpec(context.TODO(), []byte(test.specYaml))
+			require.NoError(err)
+
+			assert.Equal(expModel, gotModel)
+		})
+	}
+}
+
+func TestBuilderInvalid(t *testing.T) {
+	events := func(s *specbuilder.SLOBuilder) *specbuilder.SLOBuilder {
+		return s.WithEventsSLI(`errors[{{.window}}]`, `total[{{ .window }}]`).DisablePageAlert().DisableTicketAlert()
+	}
+
+	tests := map[string]*specbuilder.Builder{
+		"Missing service should fail.": specbuilder.New("").
+			WithSLO(events(specbuilder.NewSLO("slo1", 99))),
+
+		"Missing SLOs should fail.": specbuilder.New("test-svc"),
+
+		"A nil SLO should fail.": specbuilder.New("test-svc").
+			WithSLO(nil),
+
+		"Missing SLO name should fail.": specbuilder.New("test-svc").
+			WithSLO(events(specbuilder.NewSLO("", 99))),
+
+		"Repeated SLO names should fail.": specbuilder.New("test-svc").
+			WithSLO(events(specbuilder.NewSLO("slo1", 99))).
+			WithSLO(events(specbuilder.NewSLO("slo1", 99.9))),
+
+		"An objective of 100 should fail.": specbuilder.New("test-svc").
+			WithSLO(events(specbuilder.NewSLO("slo1", 100))),
+
+		"An objective of 0 should fail.": specbuilder.New("test-svc").
+			WithSLO(events(specbuilder.NewSLO("slo1", 0))),
+
+		"Missing SLI should fail.": specbuilder.New("test-svc").
+			WithSLO(specbuilder.NewSLO("slo1", 99).DisablePageAlert().DisableTicketAlert()),
+
+		"Multiple SLI types should fail.": specbuilder.New("test-svc").
+			WithSLO(events(specbuilder.NewSLO("slo1", 99)).WithRawSLI(`ratio[{{.window}}]`)),
+
+		"Events SLI queries without window should fail.": specbuilder.New("test-svc").
+			WithSLO(specbuilder.NewSLO("slo1", 99).WithEventsSLI(`errors[5m]`, `total[{{.window}}]`).DisablePageAlert().DisableTicketAlert()),
+
+		"Raw SLI query without window should fail.": specbuilder.New("test-svc").
+			WithSLO(specbuilder.NewSLO("slo1", 99).WithRawSLI(`ratio[5m]`).DisablePageAlert().DisableTicketAlert()),
+
+		"Plugin SLI without ID should fail.": specbuilder.New("test-svc").
+			WithSLO(specbuilder.NewSLO("slo1", 99).WithPluginSLI("", nil).DisablePageAlert().DisableTicketAlert()),
+
+		"Latency SLI without threshold should fail.": specbuilder.New("test-svc").
+			WithSLO(specbuilder.NewSLO("slo1", 99).WithLatencySLI("http_request_duration_seconds", "", "").DisablePageAlert().DisableTicketAlert()),
+
+		"Enabled alerts without alert name should fail.": specbuilder.New("test-svc").
+			WithSLO(specbuilder.NewSLO("slo1", 99).WithRawSLI(`ratio[{{.window}}]`).DisableTicketAlert()),
+	}
+
+	for name, builder := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := builder.PrometheusV1()
+			assert.Error(t, err)
+
+			_, err = builder.KubernetesV1("test-ns", "test-name")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBuilderKubernetesV1(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	builder := specbuilder.New("test-svc").
+		WithLabels(map[string]string{"owner": "myteam"}).
+		WithCostLabels(map[string]string{"product": "checkout"}).
+		WithSLO(specbuilder.NewSLO("latency", 99.5).
+			WithNativeHistogramLatencySLI("http_request_duration_seconds", "300ms", `job="api"`).
+			WithAlerting("TestSvcHighLatency").
+			WithPageAlertFor("5m").
+			DisableTicketAlert())
+
+	_, err := builder.KubernetesV1("test-ns", "")
+	assert.Error(err, "name should be required")
+
+	gotPSL, err := builder.KubernetesV1("test-ns", "test-name")
+	require.NoError(err)
+
+	expPSL := &k8sprometheusv1.PrometheusServiceLevel{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "sloth.slok.dev/v1", Kind: "PrometheusServiceLevel"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test-name"},
+		Spec: k8sprometheusv1.PrometheusServiceLevelSpec{
+			Service: "test-svc",
+			Labels:  map[string]string{"owner": "myteam"},
+			Cost:    &k8sprometheusv1.Cost{Labels: map[string]string{"product": "checkout"}},
+			SLOs: []k8sprometheusv1.SLO{
+				{
+					Name:      "latency",
+					Objective: 99.5,
+					SLI: k8sprometheusv1.SLI{
+						Latency: &k8sprometheusv1.SLILatency{
+							HistogramMetric: "http_request_duration_seconds",
+							Threshold:       "300ms",
+							Selector:        `job="api"`,
+							NativeHistogram: true,
+						},
+					},
+					Alerting: k8sprometheusv1.Alerting{
+						Name:        "TestSvcHighLatency",
+						PageAlert:   k8sprometheusv1.Alert{For: "5m"},
+						TicketAlert: k8sprometheusv1.Alert{Disable: true},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(expPSL, gotPSL)
+}
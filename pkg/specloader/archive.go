@@ -0,0 +1,174 @@
+package specloader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxArchiveFileSize is the maximum uncompressed size of an SLO specs archive YAML file.
+	MaxArchiveFileSize = 1 << 20 // 1MiB.
+	// MaxArchiveSize is the maximum uncompressed size of all the YAML files of an SLO specs archive.
+	MaxArchiveSize = 16 << 20 // 16MiB.
+)
+
+// ArchiveFile is a YAML file of an SLO specs archive.
+type ArchiveFile struct {
+	// Path is the slash separated path of the file inside the archive.
+	Path string
+	Data []byte
+}
+
+// IsArchive returns true if the path is a supported SLO specs archive (`.tar.gz`, `.tgz`,
+// `.tar` or `.zip`).
+func IsArchive(path string) bool {
+	path = strings.ToLower(path)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadArchive reads in memory the YAML files of an SLO specs archive, a zip archive or a
+// tarball (gzip compressed or not), sorted by path. The archive entries outside the archive
+// root are not allowed, and the files can't be bigger than MaxArchiveFileSize, nor all of them
+// bigger than MaxArchiveSize.
+func ReadArchive(data []byte) ([]ArchiveFile, error) {
+	var files []ArchiveFile
+	var err error
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")) {
+		files, err = readZip(data)
+	} else {
+		files, err = readTar(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return files, nil
+}
+
+func readZip(data []byte) ([]ArchiveFile, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip: %w", err)
+	}
+
+	files := []ArchiveFile{}
+	var size int64
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() || !isYAMLFile(f.Name) {
+			continue
+		}
+
+		name, err := cleanArchivePath(f.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("could not open %q zip file: %w", f.Name, err)
+		}
+		fdata, err := readArchiveFile(rc, &size)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %q zip file: %w", f.Name, err)
+		}
+
+		files = append(files, ArchiveFile{Path: name, Data: fdata})
+	}
+
+	return files, nil
+}
+
+func readTar(r io.Reader) ([]ArchiveFile, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	} else {
+		r = br
+	}
+
+	files := []ArchiveFile{}
+	var size int64
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tarball: %w", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !isYAMLFile(hdr.Name) {
+			continue
+		}
+
+		name, err := cleanArchivePath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		fdata, err := readArchiveFile(tr, &size)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %q tarball file: %w", hdr.Name, err)
+		}
+
+		files = append(files, ArchiveFile{Path: name, Data: fdata})
+	}
+}
+
+// readArchiveFile reads an archive file adding its size to the archive read size, it fails if
+// the file or the archive are bigger than their maximum size.
+func readArchiveFile(r io.Reader, size *int64) ([]byte, error) {
+	// Don't trust the archive headers sizes, read up to the maximum.
+	data, err := io.ReadAll(io.LimitReader(r, MaxArchiveFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxArchiveFileSize {
+		return nil, fmt.Errorf("file is bigger than the maximum %d bytes", MaxArchiveFileSize)
+	}
+
+	*size += int64(len(data))
+	if *size > MaxArchiveSize {
+		return nil, fmt.Errorf("archive files are bigger than the maximum %d bytes", MaxArchiveSize)
+	}
+
+	return data, nil
+}
+
+func isYAMLFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// cleanArchivePath cleans an archive entry path, the entry can't be outside the archive root.
+func cleanArchivePath(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid %q entry outside the archive", name)
+	}
+
+	return clean, nil
+}
@@ -0,0 +1,180 @@
+package specloader_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+func newTestTar(t *testing.T, gzipped bool, files map[string]string) []byte {
+	var b bytes.Buffer
+	var gw *gzip.Writer
+	tw := tar.NewWriter(&b)
+	if gzipped {
+		gw = gzip.NewWriter(&b)
+		tw = tar.NewWriter(gw)
+	}
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "slos/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))}))
+		_, err := tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	if gzipped {
+		require.NoError(t, gw.Close())
+	}
+
+	return b.Bytes()
+}
+
+func newTestZip(t *testing.T, files map[string]string) []byte {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	return b.Bytes()
+}
+
+// newTestArchiveMaxSizeFiles returns archive files of the maximum file size that are bigger
+// than the maximum archive size.
+func newTestArchiveMaxSizeFiles() map[string]string {
+	files := map[string]string{}
+	data := strings.Repeat("#", specloader.MaxArchiveFileSize)
+	for i := 0; i <= specloader.MaxArchiveSize/specloader.MaxArchiveFileSize; i++ {
+		files[fmt.Sprintf("slos/%d.yaml", i)] = data
+	}
+
+	return files
+}
+
+func TestReadArchive(t *testing.T) {
+	files := map[string]string{
+		"slos/b.yaml":         "service: b\n",
+		"slos/team/a.yml":     "service: a\n",
+		"slos/README.md":      "# SLOs\n",
+		"./slos/other/c.YAML": "service: c\n",
+	}
+	expFiles := []specloader.ArchiveFile{
+		{Path: "slos/b.yaml", Data: []byte("service: b\n")},
+		{Path: "slos/other/c.YAML", Data: []byte("service: c\n")},
+		{Path: "slos/team/a.yml", Data: []byte("service: a\n")},
+	}
+
+	tests := map[string]struct {
+		archive  func(t *testing.T) []byte
+		expFiles []specloader.ArchiveFile
+		expErr   bool
+	}{
+		"A gzipped tarball should return its YAML files sorted by path.": {
+			archive:  func(t *testing.T) []byte { return newTestTar(t, true, files) },
+			expFiles: expFiles,
+		},
+
+		"A tarball should return its YAML files sorted by path.": {
+			archive:  func(t *testing.T) []byte { return newTestTar(t, false, files) },
+			expFiles: expFiles,
+		},
+
+		"A zip archive should return its YAML files sorted by path.": {
+			archive:  func(t *testing.T) []byte { return newTestZip(t, files) },
+			expFiles: expFiles,
+		},
+
+		"A tarball with an entry outside the archive should fail.": {
+			archive: func(t *testing.T) []byte {
+				return newTestTar(t, true, map[string]string{"../slos/a.yaml": "service: a\n"})
+			},
+			expErr: true,
+		},
+
+		"A zip archive with an entry outside the archive should fail.": {
+			archive: func(t *testing.T) []byte { return newTestZip(t, map[string]string{"/slos/a.yaml": "service: a\n"}) },
+			expErr:  true,
+		},
+
+		"A tarball with a file bigger than the maximum file size should fail.": {
+			archive: func(t *testing.T) []byte {
+				return newTestTar(t, true, map[string]string{"slos/a.yaml": strings.Repeat("#", specloader.MaxArchiveFileSize+1)})
+			},
+			expErr: true,
+		},
+
+		"A zip archive with a file bigger than the maximum file size should fail.": {
+			archive: func(t *testing.T) []byte {
+				return newTestZip(t, map[string]string{"slos/a.yaml": strings.Repeat("#", specloader.MaxArchiveFileSize+1)})
+			},
+			expErr: true,
+		},
+
+		"A tarball with files of the maximum file size should be read.": {
+			archive: func(t *testing.T) []byte {
+				return newTestTar(t, true, map[string]string{"slos/a.yaml": strings.Repeat("#", specloader.MaxArchiveFileSize)})
+			},
+			expFiles: []specloader.ArchiveFile{
+				{Path: "slos/a.yaml", Data: []byte(strings.Repeat("#", specloader.MaxArchiveFileSize))},
+			},
+		},
+
+		"A tarball with files bigger than the maximum archive size should fail.": {
+			archive: func(t *testing.T) []byte { return newTestTar(t, true, newTestArchiveMaxSizeFiles()) },
+			expErr:  true,
+		},
+
+		"A zip archive with files bigger than the maximum archive size should fail.": {
+			archive: func(t *testing.T) []byte { return newTestZip(t, newTestArchiveMaxSizeFiles()) },
+			expErr:  true,
+		},
+
+		"Invalid archive data should fail.": {
+			archive: func(t *testing.T) []byte { return []byte("service: a\n") },
+			expErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotFiles, err := specloader.ReadArchive(test.archive(t))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expFiles, gotFiles)
+			}
+		})
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	tests := map[string]bool{
+		"slos.tar.gz": true,
+		"slos.TGZ":    true,
+		"slos.tar":    true,
+		"slos.zip":    true,
+		"slos.yaml":   false,
+		"slos":        false,
+		"slos.gz":     false,
+	}
+
+	for path, exp := range tests {
+		assert.Equal(t, exp, specloader.IsArchive(path), path)
+	}
+}
@@ -0,0 +1,106 @@
+package specloader
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v3"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// normalizeObjectives replaces the string objectives of a `prometheus/v1` YAML spec document
+// (e.g `99.9%` or `three nines`) with their percentage, so the document can be unmarshaled
+// on the spec objective fields. Only the objective scalars are replaced, the rest of the
+// document keeps its values, keys order and comments. The document is returned as is if it
+// doesn't have string objectives.
+func normalizeObjectives(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil || len(doc.Content) == 0 {
+		// Let the spec unmarshaling report the error.
+		return data, nil
+	}
+
+	slos := mappingValue(doc.Content[0], "slos")
+	if slos == nil || slos.Kind != yaml.SequenceNode {
+		return data, nil
+	}
+
+	normalized := false
+	for i, slo := range slos.Content {
+		n, err := normalizeObjective(mappingValue(slo, "objective"), fmt.Sprintf("slos[%d].objective", i))
+		if err != nil {
+			return nil, err
+		}
+		normalized = normalized || n
+
+		envs := mappingValue(slo, "environments")
+		if envs != nil && envs.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(envs.Content); j += 2 {
+				name, env := envs.Content[j].Value, envs.Content[j+1]
+				n, err := normalizeObjective(mappingValue(env, "objective"), fmt.Sprintf("slos[%d].environments.%s.objective", i, name))
+				if err != nil {
+					return nil, err
+				}
+				normalized = normalized || n
+			}
+		}
+
+		transition := mappingValue(slo, "transition")
+		n, err = normalizeObjective(mappingValue(transition, "previous_objective"), fmt.Sprintf("slos[%d].transition.previous_objective", i))
+		if err != nil {
+			return nil, err
+		}
+		normalized = normalized || n
+	}
+
+	if !normalized {
+		return data, nil
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	err = enc.Encode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the normalized objectives: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// mappingValue returns the value node of the key on a mapping node, nil if missing.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// normalizeObjective replaces the string objective scalar node with its percentage, returns
+// true if it has been replaced.
+func normalizeObjective(node *yaml.Node, path string) (bool, error) {
+	if node == nil || node.Kind != yaml.ScalarNode || node.ShortTag() != "!!str" {
+		return false, nil
+	}
+
+	objective, err := prometheusv1.ParseObjective(node.Value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	node.Value = strconv.FormatFloat(objective, 'f', -1, 64)
+	node.Tag = "!!float"
+	node.Style = 0
+
+	return true, nil
+}
@@ -0,0 +1,190 @@
+package specloader
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// DocumentReader reads the YAML documents (`---`) of a multi-document YAML stream
+// one by one. The documents are returned as they are written (only the UTF-8 byte
+// order marks are removed and the CRLF line breaks converted into LF), and the
+// documents are found with a real YAML decoder so document separators inside strings
+// are not split. Empty and comment-only documents are ignored.
+type DocumentReader struct {
+	r    io.Reader
+	read bool
+	docs [][]byte
+	err  error
+}
+
+// NewDocumentReader returns a new YAML documents reader.
+func NewDocumentReader(r io.Reader) *DocumentReader {
+	return &DocumentReader{r: r}
+}
+
+// Next returns the next YAML document, when there are no more documents it will
+// return `io.EOF`.
+func (d *DocumentReader) Next() ([]byte, error) {
+	if !d.read {
+		d.read = true
+		data, err := io.ReadAll(&sanitizeReader{r: bufio.NewReader(d.r)})
+		if err != nil {
+			return nil, fmt.Errorf("could not read YAML documents: %w", err)
+		}
+		d.docs, d.err = splitDocuments(data)
+	}
+
+	if len(d.docs) > 0 {
+		doc := d.docs[0]
+		d.docs = d.docs[1:]
+		return doc, nil
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return nil, io.EOF
+}
+
+// ReadAll reads all the YAML documents of a multi-document YAML stream.
+func ReadAll(r io.Reader) ([][]byte, error) {
+	dr := NewDocumentReader(r)
+	docs := [][]byte{}
+	for {
+		doc, err := dr.Next()
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// documentSegment are the lines of a YAML stream between document markers.
+type documentSegment struct {
+	// startLine is the first line (1 based) of the segment, including its start marker.
+	startLine int
+	data      []byte
+}
+
+// splitDocuments splits the YAML stream documents. The YAML decoder gets the documents
+// and their start lines, and the document bytes are the stream lines between the document
+// markers that have the document. It returns the documents before an invalid document and
+// the error of the invalid one.
+func splitDocuments(data []byte) ([][]byte, error) {
+	segments := splitDocumentSegments(data)
+
+	docs := [][]byte{}
+	used := map[int]bool{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for n := 1; ; n++ {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return docs, fmt.Errorf("could not decode YAML document %d: %w", n, err)
+		}
+
+		// Empty, null or comment-only documents.
+		if len(node.Content) == 0 || (node.Content[0].Kind == yaml.ScalarNode && node.Content[0].ShortTag() == "!!null") {
+			continue
+		}
+
+		// Get the segment of the document content.
+		line := node.Content[0].Line
+		i := len(segments) - 1
+		for i > 0 && segments[i].startLine > line {
+			i--
+		}
+		if used[i] {
+			return docs, fmt.Errorf("could not split YAML document %d from the previous document", n)
+		}
+		used[i] = true
+		docs = append(docs, segments[i].data)
+	}
+}
+
+// splitDocumentSegments splits the YAML stream on the document start (`---`) and end (`...`)
+// markers. A marker is always at the start of a line, YAML doesn't allow them inside scalars.
+func splitDocumentSegments(data []byte) []documentSegment {
+	isMarker := func(line []byte, marker string) bool {
+		return bytes.HasPrefix(line, []byte(marker)) && (len(line) == len(marker) || line[len(marker)] == ' ' || line[len(marker)] == '\t')
+	}
+
+	segments := []documentSegment{{startLine: 1}}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, l := range lines {
+		lineNo := i + 1
+		line := bytes.TrimRight(l, "\n")
+		switch {
+		case isMarker(line, "---"):
+			seg := documentSegment{startLine: lineNo}
+			// Keep the markers with document content (e.g `--- |`), not the comments.
+			rest := bytes.TrimSpace(line[3:])
+			if len(rest) > 0 && rest[0] != '#' {
+				seg.data = append(seg.data, l...)
+			}
+			segments = append(segments, seg)
+		case isMarker(line, "..."):
+			segments = append(segments, documentSegment{startLine: lineNo + 1})
+		default:
+			seg := &segments[len(segments)-1]
+			seg.data = append(seg.data, l...)
+		}
+	}
+
+	return segments
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// sanitizeReader removes the UTF-8 byte order marks (concatenated files could have
+// a BOM on every document) and converts CRLF line breaks into LF.
+type sanitizeReader struct {
+	r *bufio.Reader
+}
+
+func (s *sanitizeReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			if n > 0 && errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+
+		switch b {
+		case utf8BOM[0]:
+			if next, _ := s.r.Peek(len(utf8BOM) - 1); string(next) == string(utf8BOM[1:]) {
+				_, _ = s.r.Discard(len(next))
+				continue
+			}
+		case '\r':
+			if next, _ := s.r.Peek(1); len(next) == 1 && next[0] == '\n' {
+				continue
+			}
+		}
+
+		p[n] = b
+		n++
+
+		// Don't block waiting for more data if we already have some.
+		if s.r.Buffered() == 0 {
+			break
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package specloader_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+func TestReadAll(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		expDocs []string
+		expErr  bool
+	}{
+		"An empty stream should return no documents.": {
+			data:    "",
+			expDocs: []string{},
+		},
+
+		"A single document should return the document.": {
+			data:    "service: a\n",
+			expDocs: []string{"service: a\n"},
+		},
+
+		"Multiple documents should be split on the document separators.": {
+			data:    "---\nservice: a\n---\nservice: b\n...\n---\nservice: c\n",
+			expDocs: []string{"service: a\n", "service: b\n", "service: c\n"},
+		},
+
+		"Document separators inside strings shouldn't split the document.": {
+			data:    "service: a\ndescription: |\n  first\n  ---\n  second\n---\nservice: b\n",
+			expDocs: []string{"service: a\ndescription: |\n  first\n  ---\n  second\n", "service: b\n"},
+		},
+
+		"Document separators inside root block scalars shouldn't split the document.": {
+			data:    "service: a\n---\n--- |\n  first\n  ---\n  second\n",
+			expDocs: []string{"service: a\n", "--- |\n  first\n  ---\n  second\n"},
+		},
+
+		"Documents should be returned as they are written.": {
+			data:    "service: a\nenabled: yes\nmode: 0755\nobjective: 99.90\n---\nservice: b\n",
+			expDocs: []string{"service: a\nenabled: yes\nmode: 0755\nobjective: 99.90\n", "service: b\n"},
+		},
+
+		"Comments should be kept and comment-only documents should be skipped.": {
+			data:    "# Header comment.\n---\n# Only a comment.\n---\nservice: a # Inline comment.\n# Trailing comment.\n",
+			expDocs: []string{"service: a # Inline comment.\n# Trailing comment.\n"},
+		},
+
+		"Empty documents should be skipped.": {
+			data:    "---\n---\n\n---\nservice: a\n---\n",
+			expDocs: []string{"service: a\n"},
+		},
+
+		"CRLF line breaks should be supported.": {
+			data:    "service: a\r\nversion: v1\r\n---\r\nservice: b\r\n",
+			expDocs: []string{"service: a\nversion: v1\n", "service: b\n"},
+		},
+
+		"Byte order marks on every concatenated document should be removed.": {
+			data:    "\xef\xbb\xbfservice: a\n---\n\xef\xbb\xbfservice: b\n",
+			expDocs: []string{"service: a\n", "service: b\n"},
+		},
+
+		"An invalid YAML document should fail.": {
+			data:   "service: a\n---\nservice: [b\n",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotDocs, err := specloader.ReadAll(bytes.NewReader([]byte(test.data)))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				gotDocsStr := []string{}
+				for _, d := range gotDocs {
+					gotDocsStr = append(gotDocsStr, string(d))
+				}
+				assert.Equal(test.expDocs, gotDocsStr)
+			}
+		})
+	}
+}
+
+func TestDocumentReaderNext(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := specloader.NewDocumentReader(bytes.NewReader([]byte("service: a\n---\n---\nservice: [b\n")))
+
+	doc, err := r.Next()
+	require.NoError(err)
+	assert.Equal("service: a\n", string(doc))
+
+	// The error should reference the document number, counting the empty ones.
+	_, err = r.Next()
+	require.Error(err)
+	assert.Contains(err.Error(), "document 3")
+
+	_, err = specloader.NewDocumentReader(bytes.NewReader(nil)).Next()
+	assert.True(errors.Is(err, io.EOF))
+}
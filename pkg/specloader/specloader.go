@@ -0,0 +1,58 @@
+// Package specloader has the canonical Sloth SLO spec loaders, so external tools
+// can read the specs the same way Sloth does. The Kubernetes specs loader is on
+// `pkg/kubernetes/specloader` package.
+//
+// Example loading all the `prometheus/v1` specs of a multi-document YAML file:
+//
+//	r := specloader.NewDocumentReader(f)
+//	for {
+//		doc, err := r.Next()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//
+//		spec, err := specloader.LoadPrometheusV1(doc)
+//		...
+//	}
+package specloader
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// LoadPrometheusV1 loads a `prometheus/v1` YAML spec document.
+func LoadPrometheusV1(data []byte) (*prometheusv1.Spec, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("spec is required")
+	}
+
+	data, err := normalizeObjectives(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall YAML spec correctly: %w", err)
+	}
+
+	s := &prometheusv1.Spec{}
+	err = yaml.Unmarshal(data, s)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall YAML spec correctly: %w", err)
+	}
+
+	// Check version.
+	if s.Version != prometheusv1.Version {
+		return nil, fmt.Errorf("invalid spec version, should be %q", prometheusv1.Version)
+	}
+
+	// Check at least we have one SLO.
+	if len(s.SLOs) == 0 {
+		return nil, fmt.Errorf("at least one SLO is required")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,246 @@
+package specloader_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+const testPrometheusV1Spec = `
+version: prometheus/v1
+service: test-svc
+labels:
+  owner: myteam
+slos:
+  - name: slo1
+    objective: 99.9
+    sli:
+      raw:
+        error_ratio_query: ratio[{{.window}}]
+    alerting:
+      name: TestAlert
+`
+
+func TestLoadPrometheusV1(t *testing.T) {
+	tests := map[string]struct {
+		specYaml string
+		strict   bool
+		expSpec  *prometheusv1.Spec
+		expErr   string
+	}{
+		"An empty spec should fail.": {
+			specYaml: "",
+			expErr:   "spec is required",
+		},
+
+		"An invalid YAML spec should fail.": {
+			specYaml: ":",
+			expErr:   "could not unmarshall YAML spec correctly",
+		},
+
+		"A spec with a wrong version should fail.": {
+			specYaml: "version: prometheus/v2\nservice: test-svc\nslos: [{name: slo1}]\n",
+			expErr:   `invalid spec version, should be "prometheus/v1"`,
+		},
+
+		"A spec without SLOs should fail.": {
+			specYaml: "version: prometheus/v1\nservice: test-svc\n",
+			expErr:   "at least one SLO is required",
+		},
+
+		"A valid spec should be loaded.": {
+			specYaml: testPrometheusV1Spec,
+			expSpec: &prometheusv1.Spec{
+				Version: prometheusv1.Version,
+				Service: "test-svc",
+				Labels:  map[string]string{"owner": "myteam"},
+				SLOs: []prometheusv1.SLO{{
+					Name:      "slo1",
+					Objective: 99.9,
+					SLI:       prometheusv1.SLI{Raw: &prometheusv1.SLIRaw{ErrorRatioQuery: "ratio[{{.window}}]"}},
+					Alerting:  prometheusv1.Alerting{Name: "TestAlert"},
+				}},
+			},
+		},
+
+		"A spec with unknown fields should be loaded.": {
+			specYaml: testPrometheusV1Spec + "    objetive: 99\n",
+			expSpec: &prometheusv1.Spec{
+				Version: prometheusv1.Version,
+				Service: "test-svc",
+				Labels:  map[string]string{"owner": "myteam"},
+				SLOs: []prometheusv1.SLO{{
+					Name:      "slo1",
+					Objective: 99.9,
+					SLI:       prometheusv1.SLI{Raw: &prometheusv1.SLIRaw{ErrorRatioQuery: "ratio[{{.window}}]"}},
+					Alerting:  prometheusv1.Alerting{Name: "TestAlert"},
+				}},
+			},
+		},
+
+		"A strict spec loading should load a valid spec.": {
+			specYaml: testPrometheusV1Spec,
+			strict:   true,
+			expSpec: &prometheusv1.Spec{
+				Version: prometheusv1.Version,
+				Service: "test-svc",
+				Labels:  map[string]string{"owner": "myteam"},
+				SLOs: []prometheusv1.SLO{{
+					Name:      "slo1",
+					Objective: 99.9,
+					SLI:       prometheusv1.SLI{Raw: &prometheusv1.SLIRaw{ErrorRatioQuery: "ratio[{{.window}}]"}},
+					Alerting:  prometheusv1.Alerting{Name: "TestAlert"},
+				}},
+			},
+		},
+
+		"A strict spec loading with unknown fields should fail with the fields path.": {
+			specYaml: testPrometheusV1Spec + "    objetive: 99\n  - name: slo2\n    sli:\n      raw:\n        query: ratio[{{.window}}]\nowner: myteam\n",
+			strict:   true,
+			expErr:   "unknown spec fields: $.owner, $.slos[0].objetive, $.slos[1].sli.raw.query",
+		},
+
+		"A spec with nines and percent objectives should be loaded with the objectives percentage.": {
+			specYaml: `
+version: prometheus/v1
+service: test-svc
+slos:
+  - name: slo1
+    objective: three nines
+    environments:
+      staging:
+        objective: "99%"
+    transition:
+      previous_objective: 2 nines
+`,
+			expSpec: &prometheusv1.Spec{
+				Version: prometheusv1.Version,
+				Service: "test-svc",
+				SLOs: []prometheusv1.SLO{{
+					Name:         "slo1",
+					Objective:    99.9,
+					Environments: map[string]prometheusv1.SLOEnvironment{"staging": {Objective: 99}},
+					Transition:   &prometheusv1.SLOTransition{PreviousObjective: 99},
+				}},
+			},
+		},
+
+		"A spec with string objectives should keep the other values as they are written.": {
+			specYaml: `
+version: prometheus/v1
+service: test-svc
+labels:
+  enabled: yes
+  mode: 0755
+slos:
+  - name: slo1
+    objective: 99.9%
+    labels:
+      tier: 010
+`,
+			expSpec: &prometheusv1.Spec{
+				Version: prometheusv1.Version,
+				Service: "test-svc",
+				Labels:  map[string]string{"enabled": "yes", "mode": "0755"},
+				SLOs: []prometheusv1.SLO{{
+					Name:      "slo1",
+					Objective: 99.9,
+					Labels:    map[string]string{"tier": "010"},
+				}},
+			},
+		},
+
+		"A spec with an invalid objective should fail with the objective path.": {
+			specYaml: "version: prometheus/v1\nservice: test-svc\nslos: [{name: slo1, objective: 99.9}, {name: slo2, objective: lots of nines}]\n",
+			expErr:   "invalid slos[1].objective",
+		},
+
+		"A strict spec loading should check the basic spec validations first.": {
+			specYaml: "version: prometheus/v1\nservice: test-svc\nunknown: true\n",
+			strict:   true,
+			expErr:   "at least one SLO is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			load := specloader.LoadPrometheusV1
+			if test.strict {
+				load = specloader.LoadPrometheusV1Strict
+			}
+			gotSpec, err := load([]byte(test.specYaml))
+
+			if test.expErr != "" {
+				if assert.Error(err) {
+					assert.Contains(err.Error(), test.expErr)
+				}
+			} else if assert.NoError(err) {
+				assert.Equal(test.expSpec, gotSpec)
+			}
+		})
+	}
+}
+
+func TestCheckUnknownFields(t *testing.T) {
+	type testInline struct {
+		Inlined string `json:"inlined"`
+	}
+	type testObj struct {
+		testInline `json:",inline"`
+		Name       string            `json:"name"`
+		Labels     map[string]string `json:"labels"`
+		Items      []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+		Ignored string `json:"-"`
+	}
+
+	tests := map[string]struct {
+		data   string
+		expErr string
+	}{
+		"Known fields shouldn't fail.": {
+			data: "name: test\ninlined: test\nlabels: {a: b}\nitems: [{id: a}]\n",
+		},
+
+		"Map keys shouldn't be checked.": {
+			data: "labels: {anything: b}\n",
+		},
+
+		"Ignored fields should be unknown.": {
+			data:   "Ignored: test\n",
+			expErr: "unknown spec fields: $.Ignored",
+		},
+
+		"Unknown fields should fail with the sorted fields path.": {
+			data:   "nme: test\nitems: [{id: a}, {ids: b}]\n",
+			expErr: "unknown spec fields: $.items[1].ids, $.nme",
+		},
+
+		"Invalid YAML should fail.": {
+			data:   "items: [",
+			expErr: "could not unmarshall YAML spec correctly",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := specloader.CheckUnknownFields([]byte(test.data), testObj{}, "json")
+
+			if test.expErr != "" {
+				if assert.Error(err) {
+					assert.Contains(err.Error(), test.expErr)
+				}
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package specloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	prometheusv1 "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// LoadPrometheusV1Strict loads a `prometheus/v1` YAML spec document like LoadPrometheusV1, but
+// it fails on the fields that are unknown to the spec (e.g a typo'd `objetive`), instead
+// of ignoring them.
+func LoadPrometheusV1Strict(data []byte) (*prometheusv1.Spec, error) {
+	s, err := LoadPrometheusV1(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = CheckUnknownFields(data, prometheusv1.Spec{}, "yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+var (
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// CheckUnknownFields checks that a YAML document doesn't have fields unknown to the type
+// of v, using the `tagName` struct field tags (`yaml` or `json`) as the field names. The
+// error has the JSON paths of the unknown fields (e.g `$.slos[0].objetive`).
+//
+// The types with custom unmarshalers are not checked.
+func CheckUnknownFields(data []byte, v interface{}, tagName string) error {
+	var doc interface{}
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return fmt.Errorf("could not unmarshall YAML spec correctly: %w", err)
+	}
+
+	unknown := unknownFields(doc, reflect.TypeOf(v), tagName, "$")
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown spec fields: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+func unknownFields(doc interface{}, t reflect.Type, tagName, path string) []string {
+	if doc == nil || t == nil {
+		return nil
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Custom unmarshaled types can have any form.
+	pt := reflect.PtrTo(t)
+	if pt.Implements(yamlUnmarshalerType) || pt.Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	unknown := []string{}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := doc.(map[interface{}]interface{})
+		if !ok {
+			return nil
+		}
+
+		fields := structFields(t, tagName)
+		for _, k := range sortedKeys(m) {
+			ft, ok := fields[k]
+			if !ok {
+				unknown = append(unknown, path+"."+k)
+				continue
+			}
+			unknown = append(unknown, unknownFields(m[k], ft, tagName, path+"."+k)...)
+		}
+
+	case reflect.Map:
+		m, ok := doc.(map[interface{}]interface{})
+		if !ok {
+			return nil
+		}
+
+		for _, k := range sortedKeys(m) {
+			unknown = append(unknown, unknownFields(m[k], t.Elem(), tagName, path+"."+k)...)
+		}
+
+	case reflect.Slice, reflect.Array:
+		s, ok := doc.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		for i, item := range s {
+			unknown = append(unknown, unknownFields(item, t.Elem(), tagName, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return unknown
+}
+
+// structFields returns the struct field types by their tag name, including the inlined
+// struct fields.
+func structFields(t reflect.Type, tagName string) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get(tagName), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+
+		inline := false
+		for _, opt := range tag[1:] {
+			inline = inline || opt == "inline"
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && (inline || (f.Anonymous && name == "")) {
+			for k, v := range structFields(ft, tagName) {
+				fields[k] = v
+			}
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+			if tagName == "yaml" {
+				name = strings.ToLower(f.Name)
+			}
+		}
+		fields[name] = f.Type
+	}
+
+	return fields
+}
+
+func sortedKeys(m map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, fmt.Sprint(k))
+	}
+	sort.Strings(keys)
+
+	return keys
+}
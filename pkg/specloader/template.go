@@ -0,0 +1,65 @@
+package specloader
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"text/template"
+)
+
+// TemplateData is the data of the SLO spec templates.
+type TemplateData struct {
+	// Env are the environment variables (e.g `{{ .Env.CLUSTER }}`).
+	Env map[string]string
+	// Vars are the user variables (e.g `{{ .Vars.cluster }}`).
+	Vars map[string]string
+}
+
+var (
+	templateActionRegexp    = regexp.MustCompile(`\{\{[^\n]*?\}\}`)
+	templateDataFieldRegexp = regexp.MustCompile(`(^|[^\w.$])\.(Env|Vars)\b`)
+)
+
+// RenderTemplates renders the Go template actions of an SLO spec file that use the template
+// data (e.g `{{ .Env.CLUSTER }}` or `{{ .Vars.cluster | printf "%s-api" }}`), so the same spec
+// can be used on multiple clusters or environments. The missing environment variables and
+// user variables fail.
+//
+// The other template actions (e.g the SLI `{{.window}}` or the alert annotations Prometheus
+// templates) are left as they are. Every action is rendered on its own, so the actions that
+// use the template data can't be control structures (e.g `if` or `range` blocks).
+func RenderTemplates(data []byte, tplData TemplateData) ([]byte, error) {
+	if tplData.Env == nil {
+		tplData.Env = map[string]string{}
+	}
+	if tplData.Vars == nil {
+		tplData.Vars = map[string]string{}
+	}
+
+	var renderErr error
+	res := templateActionRegexp.ReplaceAllFunc(data, func(action []byte) []byte {
+		if renderErr != nil || !templateDataFieldRegexp.Match(action) {
+			return action
+		}
+
+		tpl, err := template.New("spec").Option("missingkey=error").Parse(string(action))
+		if err != nil {
+			renderErr = fmt.Errorf("invalid %q spec template: %w", action, err)
+			return action
+		}
+
+		var b bytes.Buffer
+		err = tpl.Execute(&b, tplData)
+		if err != nil {
+			renderErr = fmt.Errorf("could not render %q spec template: %w", action, err)
+			return action
+		}
+
+		return b.Bytes()
+	})
+	if renderErr != nil {
+		return nil, renderErr
+	}
+
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package specloader_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/sloth/pkg/specloader"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	tests := map[string]struct {
+		spec    string
+		data    specloader.TemplateData
+		expSpec string
+		expErr  bool
+	}{
+		"A spec without templates should not change.": {
+			spec:    "service: myservice\n",
+			expSpec: "service: myservice\n",
+		},
+
+		"The environment variables and user variables should be rendered.": {
+			spec: `service: myservice-{{ .Env.CLUSTER }}
+labels:
+  cluster: {{ .Vars.cluster }}
+  owner: "{{ .Vars.team | printf "%s-oncall" }}"
+`,
+			data: specloader.TemplateData{
+				Env:  map[string]string{"CLUSTER": "eu-west-1"},
+				Vars: map[string]string{"cluster": "eu-1", "team": "myteam"},
+			},
+			expSpec: `service: myservice-eu-west-1
+labels:
+  cluster: eu-1
+  owner: "myteam-oncall"
+`,
+		},
+
+		"The template actions that don't use the template data should not be rendered.": {
+			spec: `sli:
+  events:
+    error_query: sum(rate(http_requests_total{cluster="{{ .Vars.cluster }}",code=~"5.."}[{{.window}}]))
+alerting:
+  annotations:
+    summary: "{{ $labels.sloth_service }} is burning at {{ $value }}"
+`,
+			data: specloader.TemplateData{
+				Vars: map[string]string{"cluster": "eu-1"},
+			},
+			expSpec: `sli:
+  events:
+    error_query: sum(rate(http_requests_total{cluster="eu-1",code=~"5.."}[{{.window}}]))
+alerting:
+  annotations:
+    summary: "{{ $labels.sloth_service }} is burning at {{ $value }}"
+`,
+		},
+
+		"A missing environment variable should fail.": {
+			spec:   "service: myservice-{{ .Env.CLUSTER }}\n",
+			expErr: true,
+		},
+
+		"A missing user variable should fail.": {
+			spec: "service: myservice-{{ .Vars.cluster }}\n",
+			data: specloader.TemplateData{
+				Vars: map[string]string{"team": "myteam"},
+			},
+			expErr: true,
+		},
+
+		"An invalid template should fail.": {
+			spec:   "service: myservice-{{ .Vars.cluster | }}\n",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			gotSpec, err := specloader.RenderTemplates([]byte(test.spec), test.data)
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expSpec, string(gotSpec))
+			}
+		})
+	}
+}
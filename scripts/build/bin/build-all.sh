@@ -10,6 +10,11 @@ do
 	ostype="${ostype}" ./scripts/build/bin/build.sh
 done
 
-# Create checksums.
+# Create checksums, with the release version so the signature also covers it.
 checksums_dir="./bin"
-cd ${checksums_dir} && sha256sum * > ./checksums.txt
+cd ${checksums_dir} && { echo "# version: ${VERSION}"; sha256sum *; } > ./checksums.txt.tmp && mv ./checksums.txt.tmp ./checksums.txt
+
+# Sign checksums (used by `sloth self-update`) if we have an Ed25519 private key.
+if [ -n "${SIGNING_KEY_PATH:-}" ]; then
+	openssl pkeyutl -sign -rawin -inkey "${SIGNING_KEY_PATH}" -in ./checksums.txt -out ./checksums.txt.sig
+fi
@@ -30,3 +30,6 @@ docker run -it --rm \
 	-e CRD_TYPES_PATH=/src/pkg/kubernetes/api \
 	-e CRD_OUT_PATH=/src/pkg/kubernetes/gen/crd \
 	${IMAGE_CRD_GEN} update-crd.sh
+
+echo "Copying Kubernetes CRD manifests..."
+cp ./${GEN_DIRECTORY}/crd/*.yaml ./pkg/kubernetes/crd/
@@ -70,6 +70,16 @@ func TestPrometheusGenerate(t *testing.T) {
 			expOut:     expectLoader.mustLoadExp("./testdata/out-base-no-recordings.yaml.tpl"),
 		},
 
+		"Generate in alerts only mode should generate the correct alert rules for all the SLOs.": {
+			genCmdArgs: "--input ./testdata/in-base.yaml --alerts-only",
+			expOut:     expectLoader.mustLoadExp("./testdata/out-base-no-recordings.yaml.tpl"),
+		},
+
+		"Generate in alerts only mode without SLIs should generate the correct alert rules for all the SLOs.": {
+			genCmdArgs: "--input ./testdata/in-alerts-only.yaml --alerts-only",
+			expOut:     expectLoader.mustLoadExp("./testdata/out-base-no-recordings.yaml.tpl"),
+		},
+
 		"Generate with extra labels should generate the correct rules for all the SLOs.": {
 			genCmdArgs: "--input ./testdata/in-base.yaml -l exk1=exv1 -l exk2=exv2",
 			expOut:     expectLoader.mustLoadExp("./testdata/out-base-extra-labels.yaml.tpl"),
@@ -180,6 +180,7 @@ spec:
     - alert: myServiceAlert
       annotations:
         alert02k1: alert02k2
+        allowed_downtime: 43m12s in 30d
         summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
           burn rate is over expected.'
         title: (page) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
@@ -203,6 +204,7 @@ spec:
     - alert: myServiceAlert
       annotations:
         alert02k1: alert02k2
+        allowed_downtime: 43m12s in 30d
         summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
           burn rate is over expected.'
         title: (ticket) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error
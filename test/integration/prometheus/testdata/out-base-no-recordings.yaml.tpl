@@ -25,6 +25,7 @@ groups:
       sloth_severity: page
     annotations:
       alert02k1: alert02k2
+      allowed_downtime: 43m12s in 30d
       summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget burn
         rate is over expected.'
       title: (page) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
@@ -48,6 +49,7 @@ groups:
       sloth_severity: ticket
     annotations:
       alert02k1: alert02k2
+      allowed_downtime: 43m12s in 30d
       summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget burn
         rate is over expected.'
       title: (ticket) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
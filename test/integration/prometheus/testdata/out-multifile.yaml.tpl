@@ -186,6 +186,7 @@ groups:
       sloth_severity: page
     annotations:
       alert02k1: alert02k2
+      allowed_downtime: 43m12s in 30d
       summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget burn
         rate is over expected.'
       title: (page) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
@@ -209,6 +210,7 @@ groups:
       sloth_severity: ticket
     annotations:
       alert02k1: alert02k2
+      allowed_downtime: 43m12s in 30d
       summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget burn
         rate is over expected.'
       title: (ticket) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
@@ -569,6 +571,7 @@ groups:
       sloth_severity: page
     annotations:
       alert02k1: alert02k2
+      allowed_downtime: 4m19s in 30d
       summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget burn
         rate is over expected.'
       title: (page) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget
@@ -592,6 +595,7 @@ groups:
       sloth_severity: ticket
     annotations:
       alert02k1: alert02k2
+      allowed_downtime: 4m19s in 30d
       summary: '{{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget burn
         rate is over expected.'
       title: (ticket) {{"{{$labels.sloth_service}}"}} {{"{{$labels.sloth_slo}}"}} SLO error budget